@@ -21,10 +21,12 @@ import (
 	"github.com/getarcaneapp/arcane/backend/internal/common"
 	"github.com/getarcaneapp/arcane/backend/internal/config"
 	"github.com/getarcaneapp/arcane/backend/internal/middleware"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/services"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/docker"
 	httputil "github.com/getarcaneapp/arcane/backend/internal/utils/http"
 	ws "github.com/getarcaneapp/arcane/backend/internal/utils/ws"
+	"github.com/getarcaneapp/arcane/types/imagebuild"
 	systemtypes "github.com/getarcaneapp/arcane/types/system"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -36,6 +38,11 @@ import (
 
 const (
 	gpuCacheDuration = 30 * time.Second
+
+	// buildProgressBatchSize and buildProgressFlushInterval bound how many build progress
+	// events are sent per WebSocket message and how long they are held back.
+	buildProgressBatchSize     = 200
+	buildProgressFlushInterval = 100 * time.Millisecond
 )
 
 // amdGPUSysfsPath is the base path for AMD GPU sysfs entries
@@ -52,6 +59,7 @@ type WebSocketMetrics struct {
 	containerStats      atomic.Int64
 	containerExec       atomic.Int64
 	systemStats         atomic.Int64
+	imageBuilds         atomic.Int64
 	seq                 atomic.Uint64
 	mu                  sync.RWMutex
 	connections         map[string]systemtypes.WebSocketConnectionInfo
@@ -72,6 +80,7 @@ func (m *WebSocketMetrics) Snapshot() systemtypes.WebSocketMetricsSnapshot {
 		ContainerStats:      m.containerStats.Load(),
 		ContainerExec:       m.containerExec.Load(),
 		SystemStats:         m.systemStats.Load(),
+		ImageBuilds:         m.imageBuilds.Load(),
 	}
 }
 
@@ -132,6 +141,8 @@ func (m *WebSocketMetrics) applyDelta(kind string, delta int64) {
 		m.containerExec.Add(delta)
 	case systemtypes.WSKindSystemStats:
 		m.systemStats.Add(delta)
+	case systemtypes.WSKindImageBuild:
+		m.imageBuilds.Add(delta)
 	}
 }
 
@@ -152,6 +163,7 @@ type WebSocketHandler struct {
 	projectService    *services.ProjectService
 	containerService  *services.ContainerService
 	systemService     *services.SystemService
+	imageBuildService *services.ImageBuildService
 	wsUpgrader        websocket.Upgrader
 	wsMetrics         *WebSocketMetrics
 	activeConnections sync.Map
@@ -210,6 +222,7 @@ func NewWebSocketHandler(
 	projectService *services.ProjectService,
 	containerService *services.ContainerService,
 	systemService *services.SystemService,
+	imageBuildService *services.ImageBuildService,
 	authMiddleware *middleware.AuthMiddleware,
 	cfg *config.Config,
 ) {
@@ -217,6 +230,7 @@ func NewWebSocketHandler(
 		projectService:       projectService,
 		containerService:     containerService,
 		systemService:        systemService,
+		imageBuildService:    imageBuildService,
 		wsMetrics:            defaultWebSocketMetrics,
 		gpuMonitoringEnabled: cfg.GPUMonitoringEnabled,
 		gpuType:              cfg.GPUType,
@@ -236,6 +250,7 @@ func NewWebSocketHandler(
 		wsGroup.GET("/containers/:containerId/stats", handler.ContainerStats)
 		wsGroup.GET("/containers/:containerId/terminal", handler.ContainerExec)
 		wsGroup.GET("/system/stats", handler.SystemStats)
+		wsGroup.GET("/builds/:buildId/progress", handler.ImageBuildProgress)
 	}
 }
 
@@ -662,6 +677,116 @@ func (h *WebSocketHandler) pipeExecInputInternal(ctx context.Context, cancel con
 	}
 }
 
+// ============================================================================
+// Image Build WebSocket Endpoints
+// ============================================================================
+
+// ImageBuildProgress streams the progress of an image build over WebSocket.
+// Each message is a JSON array of progress events. Clients that attach to a running
+// build first receive its progress so far; for a finished build only its result is sent.
+//
+//	@Summary		Get image build progress via WebSocket
+//	@Description	Stream BuildKit progress of an image build over WebSocket connection
+//	@Tags			WebSocket
+//	@Param			id		path	string	true	"Environment ID"
+//	@Param			buildId	path	string	true	"Image build ID"
+//	@Router			/api/environments/{id}/ws/builds/{buildId}/progress [get]
+func (h *WebSocketHandler) ImageBuildProgress(c *gin.Context) {
+	buildID := c.Param("buildId")
+	if strings.TrimSpace(buildID) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": (&common.ImageBuildIDRequiredError{}).Error()})
+		return
+	}
+
+	backlog, events, unsubscribe, err := h.imageBuildService.Subscribe(c.Request.Context(), buildID)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		c.JSON(apiErr.HTTPStatus(), gin.H{"success": false, "error": (&common.ImageBuildRetrievalError{Err: err}).Error()})
+		return
+	}
+
+	conn, err := h.wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		unsubscribe()
+		return
+	}
+
+	connID := h.wsMetrics.RegisterConnection(buildWSConnectionInfoInternal(c, systemtypes.WSKindImageBuild, buildID))
+	hub, ctx := h.startImageBuildHub(buildID, func() {
+		unsubscribe()
+		h.wsMetrics.UnregisterConnection(connID)
+	})
+	// WebSocket connections use context.Background() because they are long-lived and should not
+	// be tied to the HTTP request context. Cleanup is handled via the hub's OnEmpty callback
+	// which triggers when all clients disconnect.
+	ws.ServeClient(context.Background(), hub, conn)
+
+	// Forward only once the client is registered, so the backlog isn't broadcast to nobody.
+	go forwardBuildProgressInternal(ctx, hub, backlog, events)
+}
+
+func (h *WebSocketHandler) startImageBuildHub(buildID string, onEmptyHook func()) (*ws.Hub, context.Context) {
+	hub := ws.NewHub(64)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	hub.SetOnEmpty(func() {
+		if onEmptyHook != nil {
+			onEmptyHook()
+		}
+		slog.Debug("client disconnected, cleaning up image build hub", "buildID", buildID)
+		cancel()
+	})
+
+	go hub.Run(ctx)
+
+	return hub, ctx
+}
+
+// forwardBuildProgressInternal sends the backlog in batches, then batches live events until
+// the build finishes. The connection stays open until the client closes it.
+func forwardBuildProgressInternal(ctx context.Context, hub *ws.Hub, backlog []imagebuild.ProgressEvent, events <-chan imagebuild.ProgressEvent) {
+	send := func(batch []imagebuild.ProgressEvent) {
+		if b, err := json.Marshal(batch); err == nil {
+			hub.Broadcast(b)
+		}
+	}
+
+	for len(backlog) > 0 {
+		n := min(len(backlog), buildProgressBatchSize)
+		send(backlog[:n])
+		backlog = backlog[n:]
+	}
+
+	ticker := time.NewTicker(buildProgressFlushInterval)
+	defer ticker.Stop()
+
+	var batch []imagebuild.ProgressEvent
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				if len(batch) > 0 {
+					send(batch)
+				}
+				return
+			}
+			batch = append(batch, ev)
+			if len(batch) >= buildProgressBatchSize {
+				send(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				send(batch)
+				batch = nil
+			}
+		}
+	}
+}
+
 // ============================================================================
 // System WebSocket Endpoints
 // ============================================================================
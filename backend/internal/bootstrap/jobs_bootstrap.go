@@ -41,9 +41,17 @@ func registerJobs(appCtx context.Context, newScheduler *pkg_scheduler.JobSchedul
 	gitOpsSyncJob := pkg_scheduler.NewGitOpsSyncJob(appServices.GitOpsSync, appServices.Settings)
 	newScheduler.RegisterJob(gitOpsSyncJob)
 
+	gitOpsDriftJob := pkg_scheduler.NewGitOpsDriftJob(appServices.GitOpsSync, appServices.Settings)
+	newScheduler.RegisterJob(gitOpsDriftJob)
+
 	vulnerabilityScanJob := pkg_scheduler.NewVulnerabilityScanJob(appServices.Vulnerability, appServices.Settings)
 	newScheduler.RegisterJob(vulnerabilityScanJob)
 
+	updateRolloutJob := pkg_scheduler.NewUpdateRolloutJob(appServices.UpdateRollout, appServices.Settings)
+	if !appConfig.AgentMode {
+		newScheduler.RegisterJob(updateRolloutJob)
+	}
+
 	setupJobScheduleCallbacks(
 		appServices,
 		appConfig,
@@ -55,7 +63,9 @@ func registerJobs(appCtx context.Context, newScheduler *pkg_scheduler.JobSchedul
 		eventCleanupJob,
 		scheduledPruneJob,
 		gitOpsSyncJob,
+		gitOpsDriftJob,
 		vulnerabilityScanJob,
+		updateRolloutJob,
 	)
 	setupSettingsCallbacks(appServices, appConfig, newScheduler, imagePollingJob, autoUpdateJob, environmentHealthJob, fsWatcherJob, scheduledPruneJob, vulnerabilityScanJob)
 }
@@ -71,7 +81,9 @@ func setupJobScheduleCallbacks(
 	eventCleanupJob *pkg_scheduler.EventCleanupJob,
 	scheduledPruneJob *pkg_scheduler.ScheduledPruneJob,
 	gitOpsSyncJob *pkg_scheduler.GitOpsSyncJob,
+	gitOpsDriftJob *pkg_scheduler.GitOpsDriftJob,
 	vulnerabilityScanJob *pkg_scheduler.VulnerabilityScanJob,
+	updateRolloutJob *pkg_scheduler.UpdateRolloutJob,
 ) {
 	if appServices.JobSchedule == nil {
 		return
@@ -91,7 +103,9 @@ func setupJobScheduleCallbacks(
 				eventCleanupJob,
 				scheduledPruneJob,
 				gitOpsSyncJob,
+				gitOpsDriftJob,
 				vulnerabilityScanJob,
+				updateRolloutJob,
 			)
 		}
 	}
@@ -109,7 +123,9 @@ func handleJobScheduleChangeInternal(
 	eventCleanupJob *pkg_scheduler.EventCleanupJob,
 	scheduledPruneJob *pkg_scheduler.ScheduledPruneJob,
 	gitOpsSyncJob *pkg_scheduler.GitOpsSyncJob,
+	gitOpsDriftJob *pkg_scheduler.GitOpsDriftJob,
 	vulnerabilityScanJob *pkg_scheduler.VulnerabilityScanJob,
+	updateRolloutJob *pkg_scheduler.UpdateRolloutJob,
 ) {
 	switch key {
 	case "pollingInterval":
@@ -143,10 +159,21 @@ func handleJobScheduleChangeInternal(
 		if err := newScheduler.RescheduleJob(ctx, gitOpsSyncJob); err != nil {
 			slog.WarnContext(ctx, "Failed to reschedule gitops sync job", "error", err)
 		}
+	case "gitopsDriftInterval":
+		if err := newScheduler.RescheduleJob(ctx, gitOpsDriftJob); err != nil {
+			slog.WarnContext(ctx, "Failed to reschedule gitops drift job", "error", err)
+		}
 	case "vulnerabilityScanInterval":
 		if err := newScheduler.RescheduleJob(ctx, vulnerabilityScanJob); err != nil {
 			slog.WarnContext(ctx, "Failed to reschedule vulnerability-scan job", "error", err)
 		}
+	case "updateRolloutInterval":
+		if appConfig.AgentMode {
+			return
+		}
+		if err := newScheduler.RescheduleJob(ctx, updateRolloutJob); err != nil {
+			slog.WarnContext(ctx, "Failed to reschedule update-rollout job", "error", err)
+		}
 	}
 }
 
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"path"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	sloggin "github.com/samber/slog-gin"
@@ -62,8 +63,12 @@ func createAuthValidator(appServices *Services) middleware.AuthValidator {
 	return func(ctx context.Context, c *gin.Context) bool {
 		// Check for API key authentication
 		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
-			user, err := appServices.ApiKey.ValidateApiKey(ctx, apiKey)
-			return err == nil && user != nil
+			user, err := appServices.ApiKey.ValidateApiKey(ctx, apiKey, middleware.ApiKeyAccessFromRequest(c))
+			if err != nil || user == nil {
+				return false
+			}
+			c.Set("currentUser", user)
+			return true
 		}
 
 		// Check for Bearer token authentication
@@ -79,7 +84,28 @@ func createAuthValidator(appServices *Services) middleware.AuthValidator {
 		}
 
 		user, err := appServices.Auth.VerifyToken(ctx, token)
-		return err == nil && user != nil
+		if err != nil || user == nil {
+			return false
+		}
+		c.Set("currentUser", user)
+		return true
+	}
+}
+
+// warnUntrustedForwardingInternal logs once when a request carries forwarding headers while no
+// TRUSTED_PROXIES are configured. Behind a reverse proxy every client then shares the proxy's
+// address, which per-client limits such as pending passkey logins and API key network
+// restrictions see.
+func warnUntrustedForwardingInternal() gin.HandlerFunc {
+	var once sync.Once
+	return func(c *gin.Context) {
+		if c.GetHeader("X-Forwarded-For") != "" || c.GetHeader("X-Real-IP") != "" {
+			once.Do(func() {
+				slog.WarnContext(c.Request.Context(), "Request has forwarding headers but TRUSTED_PROXIES is not set; all clients behind the proxy share its address",
+					"remote_addr", c.Request.RemoteAddr)
+			})
+		}
+		c.Next()
 	}
 }
 
@@ -90,13 +116,22 @@ func setupRouter(ctx context.Context, cfg *config.Config, appServices *Services)
 		gin.SetMode(gin.DebugMode)
 	}
 	router := gin.New()
+	// Forwarding headers are only honored from configured proxies, so clients can't choose the
+	// address that API key network restrictions check.
+	if err := router.SetTrustedProxies(cfg.TrustedProxyList()); err != nil {
+		slog.WarnContext(ctx, "Invalid TRUSTED_PROXIES, ignoring forwarding headers", "error", err)
+		_ = router.SetTrustedProxies(nil)
+	}
+	if len(cfg.TrustedProxyList()) == 0 {
+		router.Use(warnUntrustedForwardingInternal())
+	}
 	router.Use(gin.Recovery())
 
 	router.Use(sloggin.NewWithConfig(slog.Default(), sloggin.Config{
 		Filters: []sloggin.Filter{shouldLogRequest},
 	}))
 
-	authMiddleware := middleware.NewAuthMiddleware(appServices.Auth, cfg).WithApiKeyValidator(appServices.ApiKey)
+	authMiddleware := middleware.NewAuthMiddleware(appServices.Auth, cfg).WithApiKeyValidator(appServices.ApiKey).WithRouteAuthorizer(appServices.Role)
 	corsMiddleware := middleware.NewCORSMiddleware(cfg).Add()
 	router.Use(corsMiddleware)
 
@@ -114,6 +149,7 @@ func setupRouter(ctx context.Context, cfg *config.Config, appServices *Services)
 		},
 		appServices.Environment,
 		createAuthValidator(appServices),
+		appServices.Role,
 	)
 	apiGroup.Use(envMiddleware)
 
@@ -122,6 +158,9 @@ func setupRouter(ctx context.Context, cfg *config.Config, appServices *Services)
 		Auth:              appServices.Auth,
 		Oidc:              appServices.Oidc,
 		ApiKey:            appServices.ApiKey,
+		Role:              appServices.Role,
+		TwoFactor:         appServices.TwoFactor,
+		Passkey:           appServices.Passkey,
 		AppImages:         appServices.AppImages,
 		Font:              appServices.Font,
 		Project:           appServices.Project,
@@ -132,9 +171,11 @@ func setupRouter(ctx context.Context, cfg *config.Config, appServices *Services)
 		JobSchedule:       appServices.JobSchedule,
 		SettingsSearch:    appServices.SettingsSearch,
 		ContainerRegistry: appServices.ContainerRegistry,
+		RegistryMirror:    appServices.RegistryMirror,
 		Template:          appServices.Template,
 		Docker:            appServices.Docker,
 		Image:             appServices.Image,
+		ImageBuild:        appServices.ImageBuild,
 		ImageUpdate:       appServices.ImageUpdate,
 		Volume:            appServices.Volume,
 		Container:         appServices.Container,
@@ -147,6 +188,7 @@ func setupRouter(ctx context.Context, cfg *config.Config, appServices *Services)
 		SystemUpgrade:     appServices.SystemUpgrade,
 		GitRepository:     appServices.GitRepository,
 		GitOpsSync:        appServices.GitOpsSync,
+		UpdateRollout:     appServices.UpdateRollout,
 		Vulnerability:     appServices.Vulnerability,
 		Config:            cfg,
 	})
@@ -154,7 +196,7 @@ func setupRouter(ctx context.Context, cfg *config.Config, appServices *Services)
 	api.RegisterDiagnosticsRoutes(apiGroup, authMiddleware, api.DefaultWebSocketMetrics()) //nolint:contextcheck
 
 	// Remaining Gin handlers (WebSocket/streaming)
-	api.NewWebSocketHandler(apiGroup, appServices.Project, appServices.Container, appServices.System, authMiddleware, cfg) //nolint:contextcheck
+	api.NewWebSocketHandler(apiGroup, appServices.Project, appServices.Container, appServices.System, appServices.ImageBuild, authMiddleware, cfg) //nolint:contextcheck
 
 	// Register edge tunnel endpoint for manager to accept agent connections
 	// This is only registered when NOT in agent mode (i.e., running as manager)
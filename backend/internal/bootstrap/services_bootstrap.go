@@ -30,6 +30,7 @@ type Services struct {
 	Docker            *services.DockerClientService
 	Template          *services.TemplateService
 	ContainerRegistry *services.ContainerRegistryService
+	RegistryMirror    *services.RegistryMirrorService
 	System            *services.SystemService
 	SystemUpgrade     *services.SystemUpgradeService
 	Updater           *services.UpdaterService
@@ -38,8 +39,14 @@ type Services struct {
 	Notification      *services.NotificationService
 	Apprise           *services.AppriseService //nolint:staticcheck // Apprise still functional, deprecated in favor of Shoutrrr
 	ApiKey            *services.ApiKeyService
+	Role              *services.RoleService
+	TwoFactor         *services.TwoFactorService
+	Passkey           *services.PasskeyService
+	Ldap              *services.LdapService
 	GitRepository     *services.GitRepositoryService
+	ImageBuild        *services.ImageBuildService
 	GitOpsSync        *services.GitOpsSyncService
+	UpdateRollout     *services.UpdateRolloutService
 	Font              *services.FontService
 	Vulnerability     *services.VulnerabilityService
 }
@@ -60,27 +67,34 @@ func initializeServices(ctx context.Context, db *database.DB, cfg *config.Config
 	dockerClient := services.NewDockerClientService(db, cfg, svcs.Settings)
 	svcs.Docker = dockerClient
 	svcs.User = services.NewUserService(db)
-	svcs.ContainerRegistry = services.NewContainerRegistryService(db)
+	svcs.ContainerRegistry = services.NewContainerRegistryService(db, svcs.Settings)
+	svcs.RegistryMirror = services.NewRegistryMirrorService(db)
 	svcs.Notification = services.NewNotificationService(db, cfg)
 	svcs.Apprise = services.NewAppriseService(db, cfg)
 	svcs.Vulnerability = services.NewVulnerabilityService(db, svcs.Docker, svcs.Event, svcs.Settings, svcs.Notification)
 	svcs.ImageUpdate = services.NewImageUpdateService(db, svcs.Settings, svcs.ContainerRegistry, svcs.Docker, svcs.Event, svcs.Notification)
-	svcs.Image = services.NewImageService(db, svcs.Docker, svcs.ContainerRegistry, svcs.ImageUpdate, svcs.Vulnerability, svcs.Event)
-	svcs.Project = services.NewProjectService(db, svcs.Settings, svcs.Event, svcs.Image, svcs.Docker)
+	svcs.Image = services.NewImageService(db, svcs.Docker, svcs.ContainerRegistry, svcs.ImageUpdate, svcs.Vulnerability, svcs.Event, svcs.RegistryMirror)
+	svcs.GitRepository = services.NewGitRepositoryService(db, cfg.GitWorkDir, svcs.Event, svcs.Settings)
+	svcs.ImageBuild = services.NewImageBuildService(db, svcs.Docker, svcs.GitRepository, svcs.Settings, svcs.Event)
+	svcs.Project = services.NewProjectService(db, svcs.Settings, svcs.Event, svcs.Image, svcs.Docker, svcs.ImageBuild)
 	svcs.Environment = services.NewEnvironmentService(db, httpClient, svcs.Docker, svcs.Event, svcs.Settings)
 	svcs.Container = services.NewContainerService(db, svcs.Event, svcs.Docker, svcs.Image, svcs.Settings)
 	svcs.Volume = services.NewVolumeService(db, svcs.Docker, svcs.Event, svcs.Settings, svcs.Container, svcs.Image, cfg.BackupVolumeName)
 	svcs.Network = services.NewNetworkService(db, svcs.Docker, svcs.Event)
 	svcs.Template = services.NewTemplateService(ctx, db, httpClient, svcs.Settings)
-	svcs.Auth = services.NewAuthService(svcs.User, svcs.Settings, svcs.Event, cfg.JWTSecret, cfg)
+	svcs.TwoFactor = services.NewTwoFactorService(db, svcs.User, svcs.Settings)
+	svcs.Passkey = services.NewPasskeyService(db, svcs.User, cfg)
+	svcs.Ldap = services.NewLdapService(svcs.Settings)
+	svcs.Auth = services.NewAuthService(svcs.User, svcs.Settings, svcs.Event, svcs.TwoFactor, svcs.Passkey, svcs.Ldap, cfg.JWTSecret, cfg)
 	svcs.Oidc = services.NewOidcService(svcs.Auth, cfg, httpClient)
 	svcs.ApiKey = services.NewApiKeyService(db, svcs.User)
+	svcs.Role = services.NewRoleService(db)
 	svcs.System = services.NewSystemService(db, svcs.Docker, svcs.Container, svcs.Image, svcs.Volume, svcs.Network, svcs.Settings)
 	svcs.Version = services.NewVersionService(httpClient, cfg.UpdateCheckDisabled, config.Version, config.Revision, svcs.ContainerRegistry, svcs.Docker)
 	svcs.SystemUpgrade = services.NewSystemUpgradeService(svcs.Docker, svcs.Version, svcs.Event, svcs.Settings)
-	svcs.Updater = services.NewUpdaterService(db, svcs.Settings, svcs.Docker, svcs.Project, svcs.ImageUpdate, svcs.ContainerRegistry, svcs.Event, svcs.Image, svcs.Notification, svcs.SystemUpgrade)
-	svcs.GitRepository = services.NewGitRepositoryService(db, cfg.GitWorkDir, svcs.Event, svcs.Settings)
-	svcs.GitOpsSync = services.NewGitOpsSyncService(db, svcs.GitRepository, svcs.Project, svcs.Event)
+	svcs.Updater = services.NewUpdaterService(db, svcs.Settings, svcs.Docker, svcs.Container, svcs.Project, svcs.ImageUpdate, svcs.ContainerRegistry, svcs.Event, svcs.Image, svcs.Notification, svcs.SystemUpgrade)
+	svcs.GitOpsSync = services.NewGitOpsSyncService(db, svcs.GitRepository, svcs.Project, svcs.ImageBuild, svcs.Event, svcs.Notification)
+	svcs.UpdateRollout = services.NewUpdateRolloutService(db, svcs.Environment, svcs.Updater, svcs.Event)
 
 	return svcs, dockerClient, nil
 }
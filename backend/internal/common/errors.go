@@ -86,6 +86,100 @@ func (e *PasswordChangeError) Error() string {
 	return "Failed to change password"
 }
 
+type InvalidTwoFactorCodeError struct{}
+
+func (e *InvalidTwoFactorCodeError) Error() string {
+	return "Invalid two-factor code"
+}
+
+type TwoFactorStatusError struct {
+	Err error
+}
+
+func (e *TwoFactorStatusError) Error() string {
+	return "Failed to get two-factor authentication status"
+}
+
+type TwoFactorSetupError struct {
+	Err error
+}
+
+func (e *TwoFactorSetupError) Error() string {
+	return "Failed to set up two-factor authentication"
+}
+
+type TwoFactorDisableError struct {
+	Err error
+}
+
+func (e *TwoFactorDisableError) Error() string {
+	return "Failed to disable two-factor authentication"
+}
+
+type RecoveryCodesError struct {
+	Err error
+}
+
+func (e *RecoveryCodesError) Error() string {
+	return "Failed to generate recovery codes"
+}
+
+type InvalidPasskeyError struct{}
+
+func (e *InvalidPasskeyError) Error() string {
+	return "Passkey verification failed"
+}
+
+type PasskeyNotFoundError struct{}
+
+func (e *PasskeyNotFoundError) Error() string {
+	return "Passkey not found"
+}
+
+type PasskeyListError struct {
+	Err error
+}
+
+func (e *PasskeyListError) Error() string {
+	return "Failed to list passkeys"
+}
+
+type PasskeyRegistrationError struct {
+	Err error
+}
+
+func (e *PasskeyRegistrationError) Error() string {
+	return "Failed to register passkey"
+}
+
+type PasskeyUpdateError struct {
+	Err error
+}
+
+func (e *PasskeyUpdateError) Error() string {
+	return "Failed to update passkey"
+}
+
+type PasskeyDeletionError struct {
+	Err error
+}
+
+func (e *PasskeyDeletionError) Error() string {
+	return "Failed to delete passkey"
+}
+
+type LdapUserConflictError struct{}
+
+func (e *LdapUserConflictError) Error() string {
+	return "A user with this username exists and is not managed by LDAP"
+}
+
+type LdapManagedPasswordError struct{}
+
+func (e *LdapManagedPasswordError) Error() string {
+	return "Password is managed by the LDAP directory"
+}
+
 type ImageRetrievalError struct {
 	Err error
 }
@@ -100,6 +194,12 @@ func (e *ContainerIDRequiredError) Error() string {
 	return "Container ID is required"
 }
 
+type ImageBuildIDRequiredError struct{}
+
+func (e *ImageBuildIDRequiredError) Error() string {
+	return "Image build ID is required"
+}
+
 type ExecCreationError struct {
 	Err error
 }
@@ -260,6 +360,14 @@ func (e *RegistrySyncError) Error() string {
 	return fmt.Sprintf("Failed to sync registries: %v", e.Err)
 }
 
+type RegistryBrowseError struct {
+	Err error
+}
+
+func (e *RegistryBrowseError) Error() string {
+	return fmt.Sprintf("Failed to browse registry: %v", e.Err)
+}
+
 type QueryParameterRequiredError struct{}
 
 func (e *QueryParameterRequiredError) Error() string {
@@ -402,6 +510,22 @@ func (e *ImageRemovalError) Error() string {
 	return fmt.Sprintf("Failed to remove image: %v", e.Err)
 }
 
+type ImageTagError struct {
+	Err error
+}
+
+func (e *ImageTagError) Error() string {
+	return fmt.Sprintf("Failed to tag image: %v", e.Err)
+}
+
+type ImagePushError struct {
+	Err error
+}
+
+func (e *ImagePushError) Error() string {
+	return fmt.Sprintf("Failed to push image: %v", e.Err)
+}
+
 type ImagePruneError struct {
 	Err error
 }
@@ -730,6 +854,22 @@ func (e *ProjectRestartError) Error() string {
 	return fmt.Sprintf("Failed to restart project: %v", e.Err)
 }
 
+type ProjectRollbackError struct {
+	Err error
+}
+
+func (e *ProjectRollbackError) Error() string {
+	return fmt.Sprintf("Failed to roll back project: %v", e.Err)
+}
+
+type ProjectDeploymentListError struct {
+	Err error
+}
+
+func (e *ProjectDeploymentListError) Error() string {
+	return fmt.Sprintf("Failed to list project deployments: %v", e.Err)
+}
+
 type ProjectStatusCountsError struct {
 	Err error
 }
@@ -1295,6 +1435,38 @@ func (e *GitOpsSyncMappingError) Error() string {
 	return "Failed to map GitOps sync"
 }
 
+type GitOpsSyncDiffError struct {
+	Err error
+}
+
+func (e *GitOpsSyncDiffError) Error() string {
+	return fmt.Sprintf("Failed to preview GitOps sync: %v", e.Err)
+}
+
+type GitOpsSyncDriftError struct {
+	Err error
+}
+
+func (e *GitOpsSyncDriftError) Error() string {
+	return fmt.Sprintf("Failed to check GitOps sync for drift: %v", e.Err)
+}
+
+type GitOpsSyncWebhookError struct {
+	Err error
+}
+
+func (e *GitOpsSyncWebhookError) Error() string {
+	return fmt.Sprintf("Failed to update GitOps sync webhook: %v", e.Err)
+}
+
+type GitOpsWebhookDeliveryError struct {
+	Err error
+}
+
+func (e *GitOpsWebhookDeliveryError) Error() string {
+	return fmt.Sprintf("Webhook delivery rejected: %v", e.Err)
+}
+
 type VulnerabilityScanError struct {
 	Err error
 }
@@ -1316,3 +1488,219 @@ type VulnerabilityScanRetrievalError struct {
 func (e *VulnerabilityScanRetrievalError) Error() string {
 	return fmt.Sprintf("Failed to retrieve vulnerability scan: %v", e.Err)
 }
+
+type UpdateRolloutListError struct {
+	Err error
+}
+
+func (e *UpdateRolloutListError) Error() string {
+	return fmt.Sprintf("Failed to list update rollouts: %v", e.Err)
+}
+
+type UpdateRolloutCreationError struct {
+	Err error
+}
+
+func (e *UpdateRolloutCreationError) Error() string {
+	return fmt.Sprintf("Failed to create update rollout: %v", e.Err)
+}
+
+type UpdateRolloutRetrievalError struct {
+	Err error
+}
+
+func (e *UpdateRolloutRetrievalError) Error() string {
+	return fmt.Sprintf("Failed to retrieve update rollout: %v", e.Err)
+}
+
+type UpdateRolloutUpdateError struct {
+	Err error
+}
+
+func (e *UpdateRolloutUpdateError) Error() string {
+	return fmt.Sprintf("Failed to update update rollout: %v", e.Err)
+}
+
+type UpdateRolloutDeletionError struct {
+	Err error
+}
+
+func (e *UpdateRolloutDeletionError) Error() string {
+	return fmt.Sprintf("Failed to delete update rollout: %v", e.Err)
+}
+
+type UpdateRolloutStartError struct {
+	Err error
+}
+
+func (e *UpdateRolloutStartError) Error() string {
+	return fmt.Sprintf("Failed to start update rollout: %v", e.Err)
+}
+
+type UpdateRolloutCancelError struct {
+	Err error
+}
+
+func (e *UpdateRolloutCancelError) Error() string {
+	return fmt.Sprintf("Failed to cancel update rollout: %v", e.Err)
+}
+
+type UpdateRolloutMappingError struct {
+	Err error
+}
+
+func (e *UpdateRolloutMappingError) Error() string {
+	return fmt.Sprintf("Failed to map update rollout: %v", e.Err)
+}
+
+type RegistryMirrorListError struct {
+	Err error
+}
+
+func (e *RegistryMirrorListError) Error() string {
+	return fmt.Sprintf("Failed to list registry mirrors: %v", e.Err)
+}
+
+type RegistryMirrorCreationError struct {
+	Err error
+}
+
+func (e *RegistryMirrorCreationError) Error() string {
+	return fmt.Sprintf("Failed to create registry mirror: %v", e.Err)
+}
+
+type RegistryMirrorRetrievalError struct {
+	Err error
+}
+
+func (e *RegistryMirrorRetrievalError) Error() string {
+	return fmt.Sprintf("Failed to retrieve registry mirror: %v", e.Err)
+}
+
+type RegistryMirrorUpdateError struct {
+	Err error
+}
+
+func (e *RegistryMirrorUpdateError) Error() string {
+	return fmt.Sprintf("Failed to update registry mirror: %v", e.Err)
+}
+
+type RegistryMirrorDeletionError struct {
+	Err error
+}
+
+func (e *RegistryMirrorDeletionError) Error() string {
+	return fmt.Sprintf("Failed to delete registry mirror: %v", e.Err)
+}
+
+type RegistryMirrorMappingError struct {
+	Err error
+}
+
+func (e *RegistryMirrorMappingError) Error() string {
+	return fmt.Sprintf("Failed to map registry mirror: %v", e.Err)
+}
+
+type ImageBuildListError struct {
+	Err error
+}
+
+func (e *ImageBuildListError) Error() string {
+	return fmt.Sprintf("Failed to list image builds: %v", e.Err)
+}
+
+type ImageBuildStartError struct {
+	Err error
+}
+
+func (e *ImageBuildStartError) Error() string {
+	return fmt.Sprintf("Failed to start image build: %v", e.Err)
+}
+
+type ImageBuildRetrievalError struct {
+	Err error
+}
+
+func (e *ImageBuildRetrievalError) Error() string {
+	return fmt.Sprintf("Failed to get image build: %v", e.Err)
+}
+
+type ImageBuildCancelError struct {
+	Err error
+}
+
+func (e *ImageBuildCancelError) Error() string {
+	return fmt.Sprintf("Failed to cancel image build: %v", e.Err)
+}
+
+type ImageBuildMappingError struct {
+	Err error
+}
+
+func (e *ImageBuildMappingError) Error() string {
+	return fmt.Sprintf("Failed to map image build: %v", e.Err)
+}
+
+type RoleListError struct {
+	Err error
+}
+
+func (e *RoleListError) Error() string {
+	return fmt.Sprintf("Failed to list roles: %v", e.Err)
+}
+
+type RoleRetrievalError struct {
+	Err error
+}
+
+func (e *RoleRetrievalError) Error() string {
+	return fmt.Sprintf("Failed to get role: %v", e.Err)
+}
+
+type RoleCreationError struct {
+	Err error
+}
+
+func (e *RoleCreationError) Error() string {
+	return fmt.Sprintf("Failed to create role: %v", e.Err)
+}
+
+type RoleUpdateError struct {
+	Err error
+}
+
+func (e *RoleUpdateError) Error() string {
+	return fmt.Sprintf("Failed to update role: %v", e.Err)
+}
+
+type RoleDeletionError struct {
+	Err error
+}
+
+func (e *RoleDeletionError) Error() string {
+	return fmt.Sprintf("Failed to delete role: %v", e.Err)
+}
+
+type RoleAssignmentListError struct {
+	Err error
+}
+
+func (e *RoleAssignmentListError) Error() string {
+	return fmt.Sprintf("Failed to list role assignments: %v", e.Err)
+}
+
+type RoleAssignmentUpdateError struct {
+	Err error
+}
+
+func (e *RoleAssignmentUpdateError) Error() string {
+	return fmt.Sprintf("Failed to update role assignments: %v", e.Err)
+}
+
+type PermissionsRetrievalError struct {
+	Err error
+}
+
+func (e *PermissionsRetrievalError) Error() string {
+	return fmt.Sprintf("Failed to get permissions: %v", e.Err)
+}
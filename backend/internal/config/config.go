@@ -71,6 +71,7 @@ type Config struct {
 	RegistryTimeout        int    `env:"REGISTRY_TIMEOUT" default:"0"`
 	ProxyRequestTimeout    int    `env:"PROXY_REQUEST_TIMEOUT" default:"0"`
 	BackupVolumeName       string `env:"ARCANE_BACKUP_VOLUME_NAME" default:"arcane-backups"`
+	TrustedProxies         string `env:"TRUSTED_PROXIES" default:""`
 }
 
 func Load() *Config {
@@ -271,6 +272,18 @@ func (c *Config) ListenAddr() string {
 	return net.JoinHostPort(host, port)
 }
 
+// TrustedProxyList returns the addresses or CIDRs of the reverse proxies whose forwarding
+// headers are trusted. Without any, the client is the peer of the connection.
+func (c *Config) TrustedProxyList() []string {
+	var proxies []string
+	for _, p := range strings.Split(c.TrustedProxies, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
+
 // GetManagerBaseURL returns the base URL of the manager application.
 // It strips any trailing slashes or /api suffix from MANAGER_API_URL.
 func (c *Config) GetManagerBaseURL() string {
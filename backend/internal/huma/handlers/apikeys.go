@@ -8,6 +8,7 @@ import (
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/getarcaneapp/arcane/backend/internal/common"
 	humamw "github.com/getarcaneapp/arcane/backend/internal/huma/middleware"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/services"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/pagination"
 	"github.com/getarcaneapp/arcane/types/apikey"
@@ -97,7 +98,7 @@ func RegisterApiKeys(api huma.API, apiKeyService *services.ApiKeyService) {
 		Method:      http.MethodPost,
 		Path:        "/api-keys",
 		Summary:     "Create an API key",
-		Description: "Create a new API key for programmatic access",
+		Description: "Create a new API key for programmatic access, optionally limited to scopes, environments, projects and source networks",
 		Tags:        []string{"API Keys"},
 		Security: []map[string][]string{
 			{"BearerAuth": {}},
@@ -208,7 +209,8 @@ func (h *ApiKeyHandler) CreateApiKey(ctx context.Context, input *CreateApiKeyInp
 
 	apiKey, err := h.apiKeyService.CreateApiKey(ctx, user.ID, input.Body)
 	if err != nil {
-		return nil, huma.Error500InternalServerError((&common.ApiKeyCreationError{Err: err}).Error())
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.ApiKeyCreationError{Err: err}).Error())
 	}
 
 	return &CreateApiKeyOutput{
@@ -259,7 +261,8 @@ func (h *ApiKeyHandler) UpdateApiKey(ctx context.Context, input *UpdateApiKeyInp
 		if errors.Is(err, services.ErrApiKeyNotFound) {
 			return nil, huma.Error404NotFound((&common.ApiKeyNotFoundError{}).Error())
 		}
-		return nil, huma.Error500InternalServerError((&common.ApiKeyUpdateError{Err: err}).Error())
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.ApiKeyUpdateError{Err: err}).Error())
 	}
 
 	return &UpdateApiKeyOutput{
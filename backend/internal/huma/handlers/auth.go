@@ -9,6 +9,7 @@ import (
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/getarcaneapp/arcane/backend/internal/common"
 	humamw "github.com/getarcaneapp/arcane/backend/internal/huma/middleware"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/services"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/cookie"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/mapper"
@@ -18,9 +19,11 @@ import (
 )
 
 type AuthHandler struct {
-	userService *services.UserService
-	authService *services.AuthService
-	oidcService *services.OidcService
+	userService      *services.UserService
+	authService      *services.AuthService
+	oidcService      *services.OidcService
+	twoFactorService *services.TwoFactorService
+	passkeyService   *services.PasskeyService
 }
 
 // --- Huma Input/Output Wrappers ---
@@ -61,12 +64,79 @@ type GetCurrentUserOutput struct {
 	Body base.ApiResponse[user.User]
 }
 
+type VerifyTwoFactorInput struct {
+	Body auth.TwoFactorVerify
+}
+
+type BeginTwoFactorChallengeSetupInput struct {
+	Body auth.TwoFactorChallengeSetup
+}
+
+type TotpSetupOutput struct {
+	Body base.ApiResponse[auth.TotpSetup]
+}
+
+type TwoFactorCodeInput struct {
+	Body auth.TwoFactorCode
+}
+
+type RecoveryCodesOutput struct {
+	Body base.ApiResponse[auth.RecoveryCodes]
+}
+
+type TwoFactorStatusOutput struct {
+	Body base.ApiResponse[auth.TwoFactorStatus]
+}
+
+type DisableTwoFactorOutput struct {
+	Body base.ApiResponse[base.MessageResponse]
+}
+
+type PasskeyLoginOptionsOutput struct {
+	Body base.ApiResponse[auth.PasskeyLoginOptions]
+}
+
+type PasskeyLoginInput struct {
+	Body auth.FinishPasskeyLogin
+}
+
+type ListPasskeysOutput struct {
+	Body base.ApiResponse[[]auth.Passkey]
+}
+
+type PasskeyRegistrationOptionsOutput struct {
+	Body base.ApiResponse[auth.PasskeyRegistrationOptions]
+}
+
+type RegisterPasskeyInput struct {
+	Body auth.FinishPasskeyRegistration
+}
+
+type PasskeyOutput struct {
+	Body base.ApiResponse[auth.Passkey]
+}
+
+type RenamePasskeyInput struct {
+	PasskeyID string `path:"passkeyId" doc:"Passkey ID"`
+	Body      auth.RenamePasskey
+}
+
+type DeletePasskeyInput struct {
+	PasskeyID string `path:"passkeyId" doc:"Passkey ID"`
+}
+
+type DeletePasskeyOutput struct {
+	Body base.ApiResponse[base.MessageResponse]
+}
+
 // RegisterAuth registers authentication routes using Huma.
-func RegisterAuth(api huma.API, userService *services.UserService, authService *services.AuthService, oidcService *services.OidcService) {
+func RegisterAuth(api huma.API, userService *services.UserService, authService *services.AuthService, oidcService *services.OidcService, twoFactorService *services.TwoFactorService, passkeyService *services.PasskeyService) {
 	h := &AuthHandler{
-		userService: userService,
-		authService: authService,
-		oidcService: oidcService,
+		userService:      userService,
+		authService:      authService,
+		oidcService:      oidcService,
+		twoFactorService: twoFactorService,
+		passkeyService:   passkeyService,
 	}
 
 	huma.Register(api, huma.Operation{
@@ -74,10 +144,166 @@ func RegisterAuth(api huma.API, userService *services.UserService, authService *
 		Method:      http.MethodPost,
 		Path:        "/auth/login",
 		Summary:     "Login",
-		Description: "Authenticate a user with username and password",
+		Description: "Authenticate a user with username and password, as a local account or against LDAP when enabled. Local users with two-factor authentication get a challenge to complete with /auth/2fa/verify",
 		Tags:        []string{"Auth"},
 	}, h.Login)
 
+	huma.Register(api, huma.Operation{
+		OperationID: "verifyTwoFactor",
+		Method:      http.MethodPost,
+		Path:        "/auth/2fa/verify",
+		Summary:     "Complete a two-factor login",
+		Description: "Complete a login challenge with an authenticator app code or a recovery code",
+		Tags:        []string{"Auth"},
+	}, h.VerifyTwoFactor)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "beginTwoFactorChallengeSetup",
+		Method:      http.MethodPost,
+		Path:        "/auth/2fa/challenge/setup",
+		Summary:     "Set up two-factor authentication during login",
+		Description: "Start authenticator app setup for a login challenge that requires it",
+		Tags:        []string{"Auth"},
+	}, h.BeginTwoFactorChallengeSetup)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "getTwoFactorStatus",
+		Method:      http.MethodGet,
+		Path:        "/auth/2fa",
+		Summary:     "Get two-factor authentication status",
+		Description: "Get the two-factor authentication status of the current user",
+		Tags:        []string{"Auth"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+		},
+	}, h.GetTwoFactorStatus)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "beginTotpSetup",
+		Method:      http.MethodPost,
+		Path:        "/auth/2fa/totp/setup",
+		Summary:     "Start authenticator app setup",
+		Description: "Generate a TOTP secret and provisioning URI for the current user",
+		Tags:        []string{"Auth"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+		},
+	}, h.BeginTotpSetup)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "enableTotp",
+		Method:      http.MethodPost,
+		Path:        "/auth/2fa/totp/enable",
+		Summary:     "Enable two-factor authentication",
+		Description: "Confirm authenticator app setup with a code and get recovery codes",
+		Tags:        []string{"Auth"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+		},
+	}, h.EnableTotp)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "disableTotp",
+		Method:      http.MethodPost,
+		Path:        "/auth/2fa/totp/disable",
+		Summary:     "Disable two-factor authentication",
+		Description: "Remove the authenticator app and recovery codes of the current user",
+		Tags:        []string{"Auth"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+		},
+	}, h.DisableTotp)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "regenerateRecoveryCodes",
+		Method:      http.MethodPost,
+		Path:        "/auth/2fa/recovery-codes",
+		Summary:     "Regenerate recovery codes",
+		Description: "Replace the recovery codes of the current user",
+		Tags:        []string{"Auth"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+		},
+	}, h.RegenerateRecoveryCodes)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "beginPasskeyLogin",
+		Method:      http.MethodPost,
+		Path:        "/auth/passkeys/login/options",
+		Summary:     "Start a passkey login",
+		Description: "Get the options for navigator.credentials.get to sign in with a passkey",
+		Tags:        []string{"Auth"},
+	}, h.BeginPasskeyLogin)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "passkeyLogin",
+		Method:      http.MethodPost,
+		Path:        "/auth/passkeys/login",
+		Summary:     "Sign in with a passkey",
+		Description: "Complete a passkey login with the credential returned by the browser",
+		Tags:        []string{"Auth"},
+	}, h.PasskeyLogin)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "listPasskeys",
+		Method:      http.MethodGet,
+		Path:        "/auth/passkeys",
+		Summary:     "List passkeys",
+		Description: "List the passkeys of the current user",
+		Tags:        []string{"Auth"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+		},
+	}, h.ListPasskeys)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "beginPasskeyRegistration",
+		Method:      http.MethodPost,
+		Path:        "/auth/passkeys/registration/options",
+		Summary:     "Start a passkey registration",
+		Description: "Get the options for navigator.credentials.create to add a passkey to the current user",
+		Tags:        []string{"Auth"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+		},
+	}, h.BeginPasskeyRegistration)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "registerPasskey",
+		Method:      http.MethodPost,
+		Path:        "/auth/passkeys/registration",
+		Summary:     "Register a passkey",
+		Description: "Store the passkey created by the browser for the current user",
+		Tags:        []string{"Auth"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+		},
+	}, h.RegisterPasskey)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "renamePasskey",
+		Method:      http.MethodPut,
+		Path:        "/auth/passkeys/{passkeyId}",
+		Summary:     "Rename a passkey",
+		Description: "Rename a passkey of the current user",
+		Tags:        []string{"Auth"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+		},
+	}, h.RenamePasskey)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "deletePasskey",
+		Method:      http.MethodDelete,
+		Path:        "/auth/passkeys/{passkeyId}",
+		Summary:     "Delete a passkey",
+		Description: "Remove a passkey of the current user",
+		Tags:        []string{"Auth"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+		},
+	}, h.DeletePasskey)
+
 	huma.Register(api, huma.Operation{
 		OperationID: "logout",
 		Method:      http.MethodPost,
@@ -129,26 +355,145 @@ func (h *AuthHandler) Login(ctx context.Context, input *LoginInput) (*LoginOutpu
 		return nil, huma.Error500InternalServerError("service not available")
 	}
 
-	localAuthEnabled, err := h.authService.IsLocalAuthEnabled(ctx)
+	passwordLoginEnabled, err := h.authService.IsPasswordLoginEnabled(ctx)
 	if err != nil {
 		return nil, huma.Error500InternalServerError((&common.AuthSettingsCheckError{Err: err}).Error())
 	}
-	if !localAuthEnabled {
+	if !passwordLoginEnabled {
 		return nil, huma.Error400BadRequest((&common.LocalAuthDisabledError{}).Error())
 	}
 
-	userModel, tokenPair, err := h.authService.Login(ctx, input.Body.Username, input.Body.Password)
+	userModel, tokenPair, challenge, err := h.authService.Login(ctx, input.Body.Username, input.Body.Password)
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrInvalidCredentials):
 			return nil, huma.Error401Unauthorized((&common.InvalidCredentialsError{}).Error())
 		case errors.Is(err, services.ErrLocalAuthDisabled):
 			return nil, huma.Error400BadRequest((&common.LocalAuthDisabledError{}).Error())
+		case errors.Is(err, services.ErrLdapUserConflict):
+			return nil, huma.Error409Conflict((&common.LdapUserConflictError{}).Error())
+		default:
+			return nil, huma.Error500InternalServerError((&common.AuthFailedError{Err: err}).Error())
+		}
+	}
+
+	if challenge != nil {
+		return &LoginOutput{
+			Body: base.ApiResponse[auth.LoginResponse]{
+				Success: true,
+				Data: auth.LoginResponse{
+					TwoFactor: &auth.TwoFactorChallenge{
+						ChallengeToken: challenge.Token,
+						ExpiresAt:      challenge.ExpiresAt,
+						SetupRequired:  challenge.SetupRequired,
+					},
+				},
+			},
+		}, nil
+	}
+
+	return loginOutputInternal(userModel, tokenPair, nil)
+}
+
+// VerifyTwoFactor completes a login challenge and returns tokens.
+func (h *AuthHandler) VerifyTwoFactor(ctx context.Context, input *VerifyTwoFactorInput) (*LoginOutput, error) {
+	if h.authService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	userModel, tokenPair, recoveryCodes, err := h.authService.VerifyTwoFactorLogin(ctx, input.Body.ChallengeToken, input.Body.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidToken):
+			return nil, huma.Error401Unauthorized((&common.InvalidTokenError{}).Error())
+		case errors.Is(err, services.ErrTwoFactorInvalidCode):
+			return nil, huma.Error401Unauthorized((&common.InvalidTwoFactorCodeError{}).Error())
+		case errors.Is(err, services.ErrTwoFactorLocked):
+			return nil, huma.Error429TooManyRequests(err.Error())
+		case errors.Is(err, services.ErrTwoFactorNotSetUp):
+			return nil, huma.Error400BadRequest(err.Error())
+		default:
+			return nil, huma.Error500InternalServerError((&common.AuthFailedError{Err: err}).Error())
+		}
+	}
+
+	return loginOutputInternal(userModel, tokenPair, recoveryCodes)
+}
+
+// BeginTwoFactorChallengeSetup starts authenticator setup for a login that requires it.
+func (h *AuthHandler) BeginTwoFactorChallengeSetup(ctx context.Context, input *BeginTwoFactorChallengeSetupInput) (*TotpSetupOutput, error) {
+	if h.authService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	setup, err := h.authService.BeginTwoFactorSetup(ctx, input.Body.ChallengeToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidToken) {
+			return nil, huma.Error401Unauthorized((&common.InvalidTokenError{}).Error())
+		}
+		return nil, twoFactorErrorInternal(err, &common.TwoFactorSetupError{Err: err})
+	}
+
+	return &TotpSetupOutput{
+		Body: base.ApiResponse[auth.TotpSetup]{
+			Success: true,
+			Data:    *setup,
+		},
+	}, nil
+}
+
+// BeginPasskeyLogin returns the options for signing in with a passkey.
+func (h *AuthHandler) BeginPasskeyLogin(ctx context.Context, _ *struct{}) (*PasskeyLoginOptionsOutput, error) {
+	if h.authService == nil || h.passkeyService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	localEnabled, err := h.authService.IsLocalAuthEnabled(ctx)
+	if err != nil {
+		return nil, huma.Error500InternalServerError((&common.AuthFailedError{Err: err}).Error())
+	}
+	if !localEnabled {
+		return nil, huma.Error400BadRequest((&common.LocalAuthDisabledError{}).Error())
+	}
+
+	options, err := h.passkeyService.BeginLogin(ctx, humamw.GetClientIPFromContext(ctx))
+	if errors.Is(err, services.ErrPasskeyTooManyPending) {
+		return nil, huma.Error429TooManyRequests(err.Error())
+	}
+	if err != nil {
+		return nil, huma.Error500InternalServerError((&common.AuthFailedError{Err: err}).Error())
+	}
+
+	return &PasskeyLoginOptionsOutput{
+		Body: base.ApiResponse[auth.PasskeyLoginOptions]{
+			Success: true,
+			Data:    *options,
+		},
+	}, nil
+}
+
+// PasskeyLogin completes a passkey login and returns tokens.
+func (h *AuthHandler) PasskeyLogin(ctx context.Context, input *PasskeyLoginInput) (*LoginOutput, error) {
+	if h.authService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	userModel, tokenPair, err := h.authService.LoginWithPasskey(ctx, input.Body)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPasskeyInvalid), errors.Is(err, services.ErrPasskeySessionExpired):
+			return nil, huma.Error401Unauthorized((&common.InvalidPasskeyError{}).Error())
+		case errors.Is(err, services.ErrLocalAuthDisabled):
+			return nil, huma.Error400BadRequest((&common.LocalAuthDisabledError{}).Error())
 		default:
 			return nil, huma.Error500InternalServerError((&common.AuthFailedError{Err: err}).Error())
 		}
 	}
 
+	return loginOutputInternal(userModel, tokenPair, nil)
+}
+
+func loginOutputInternal(userModel *models.User, tokenPair *services.TokenPair, recoveryCodes []string) (*LoginOutput, error) {
 	var userResp user.User
 	if mapErr := mapper.MapStruct(userModel, &userResp); mapErr != nil {
 		return nil, huma.Error500InternalServerError((&common.UserMappingError{Err: mapErr}).Error())
@@ -165,10 +510,11 @@ func (h *AuthHandler) Login(ctx context.Context, input *LoginInput) (*LoginOutpu
 		Body: base.ApiResponse[auth.LoginResponse]{
 			Success: true,
 			Data: auth.LoginResponse{
-				Token:        tokenPair.AccessToken,
-				RefreshToken: tokenPair.RefreshToken,
-				ExpiresAt:    tokenPair.ExpiresAt,
-				User:         userResp,
+				Token:         tokenPair.AccessToken,
+				RefreshToken:  tokenPair.RefreshToken,
+				ExpiresAt:     tokenPair.ExpiresAt,
+				User:          userResp,
+				RecoveryCodes: recoveryCodes,
 			},
 		},
 	}, nil
@@ -271,6 +617,8 @@ func (h *AuthHandler) ChangePassword(ctx context.Context, input *ChangePasswordI
 		switch {
 		case errors.Is(err, services.ErrInvalidCredentials):
 			return nil, huma.Error401Unauthorized((&common.IncorrectPasswordError{}).Error())
+		case errors.Is(err, services.ErrLdapManagedPassword):
+			return nil, huma.Error400BadRequest((&common.LdapManagedPasswordError{}).Error())
 		default:
 			return nil, huma.Error500InternalServerError((&common.PasswordChangeError{Err: err}).Error())
 		}
@@ -285,3 +633,288 @@ func (h *AuthHandler) ChangePassword(ctx context.Context, input *ChangePasswordI
 		},
 	}, nil
 }
+
+// GetTwoFactorStatus returns the two-factor authentication status of the current user.
+func (h *AuthHandler) GetTwoFactorStatus(ctx context.Context, _ *struct{}) (*TwoFactorStatusOutput, error) {
+	if h.twoFactorService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	userModel, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	status, err := h.twoFactorService.GetStatus(ctx, userModel.ID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError((&common.TwoFactorStatusError{Err: err}).Error())
+	}
+
+	return &TwoFactorStatusOutput{
+		Body: base.ApiResponse[auth.TwoFactorStatus]{
+			Success: true,
+			Data:    *status,
+		},
+	}, nil
+}
+
+// BeginTotpSetup generates a TOTP secret for the current user.
+func (h *AuthHandler) BeginTotpSetup(ctx context.Context, _ *struct{}) (*TotpSetupOutput, error) {
+	if h.twoFactorService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	userModel, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	setup, err := h.twoFactorService.BeginTotpSetup(ctx, userModel.ID)
+	if err != nil {
+		return nil, twoFactorErrorInternal(err, &common.TwoFactorSetupError{Err: err})
+	}
+
+	return &TotpSetupOutput{
+		Body: base.ApiResponse[auth.TotpSetup]{
+			Success: true,
+			Data:    *setup,
+		},
+	}, nil
+}
+
+// EnableTotp confirms authenticator app setup and returns recovery codes.
+func (h *AuthHandler) EnableTotp(ctx context.Context, input *TwoFactorCodeInput) (*RecoveryCodesOutput, error) {
+	if h.twoFactorService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	userModel, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	codes, err := h.twoFactorService.EnableTotp(ctx, userModel.ID, input.Body.Code)
+	if err != nil {
+		return nil, twoFactorErrorInternal(err, &common.TwoFactorSetupError{Err: err})
+	}
+
+	return &RecoveryCodesOutput{
+		Body: base.ApiResponse[auth.RecoveryCodes]{
+			Success: true,
+			Data:    auth.RecoveryCodes{Codes: codes},
+		},
+	}, nil
+}
+
+// DisableTotp removes the authenticator app and recovery codes of the current user.
+func (h *AuthHandler) DisableTotp(ctx context.Context, input *TwoFactorCodeInput) (*DisableTwoFactorOutput, error) {
+	if h.twoFactorService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	userModel, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	if h.twoFactorService.IsRequired(ctx) {
+		return nil, huma.Error403Forbidden("Two-factor authentication is required for local accounts")
+	}
+
+	if err := h.twoFactorService.DisableTotp(ctx, userModel.ID, input.Body.Code); err != nil {
+		return nil, twoFactorErrorInternal(err, &common.TwoFactorDisableError{Err: err})
+	}
+
+	return &DisableTwoFactorOutput{
+		Body: base.ApiResponse[base.MessageResponse]{
+			Success: true,
+			Data: base.MessageResponse{
+				Message: "Two-factor authentication disabled",
+			},
+		},
+	}, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the current user.
+func (h *AuthHandler) RegenerateRecoveryCodes(ctx context.Context, input *TwoFactorCodeInput) (*RecoveryCodesOutput, error) {
+	if h.twoFactorService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	userModel, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(ctx, userModel.ID, input.Body.Code)
+	if err != nil {
+		return nil, twoFactorErrorInternal(err, &common.RecoveryCodesError{Err: err})
+	}
+
+	return &RecoveryCodesOutput{
+		Body: base.ApiResponse[auth.RecoveryCodes]{
+			Success: true,
+			Data:    auth.RecoveryCodes{Codes: codes},
+		},
+	}, nil
+}
+
+// twoFactorErrorInternal maps errors of two-factor management to responses. Invalid codes are
+// a bad request here rather than 401, which would end the user's session in the UI.
+func twoFactorErrorInternal(err error, wrapped error) error {
+	switch {
+	case errors.Is(err, services.ErrTwoFactorInvalidCode):
+		return huma.Error400BadRequest((&common.InvalidTwoFactorCodeError{}).Error())
+	case errors.Is(err, services.ErrTwoFactorLocked):
+		return huma.Error429TooManyRequests(err.Error())
+	case errors.Is(err, services.ErrTwoFactorNotEnabled), errors.Is(err, services.ErrTwoFactorNotSetUp):
+		return huma.Error400BadRequest(err.Error())
+	case errors.Is(err, services.ErrPasskeyTooManyPending):
+		return huma.Error429TooManyRequests(err.Error())
+	}
+
+	apiErr := models.ToAPIError(err)
+	if apiErr.HTTPStatus() < http.StatusInternalServerError {
+		return huma.NewError(apiErr.HTTPStatus(), err.Error())
+	}
+	return huma.Error500InternalServerError(wrapped.Error())
+}
+
+// ListPasskeys returns the passkeys of the current user.
+func (h *AuthHandler) ListPasskeys(ctx context.Context, _ *struct{}) (*ListPasskeysOutput, error) {
+	if h.passkeyService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	userModel, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	passkeys, err := h.passkeyService.ListPasskeys(ctx, userModel.ID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError((&common.PasskeyListError{Err: err}).Error())
+	}
+
+	return &ListPasskeysOutput{
+		Body: base.ApiResponse[[]auth.Passkey]{
+			Success: true,
+			Data:    passkeys,
+		},
+	}, nil
+}
+
+// BeginPasskeyRegistration returns the options for adding a passkey to the current user.
+func (h *AuthHandler) BeginPasskeyRegistration(ctx context.Context, _ *struct{}) (*PasskeyRegistrationOptionsOutput, error) {
+	if h.passkeyService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	userModel, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	options, err := h.passkeyService.BeginRegistration(ctx, userModel.ID)
+	if err != nil {
+		return nil, passkeyErrorInternal(err, &common.PasskeyRegistrationError{Err: err})
+	}
+
+	return &PasskeyRegistrationOptionsOutput{
+		Body: base.ApiResponse[auth.PasskeyRegistrationOptions]{
+			Success: true,
+			Data:    *options,
+		},
+	}, nil
+}
+
+// RegisterPasskey stores a passkey created for the current user.
+func (h *AuthHandler) RegisterPasskey(ctx context.Context, input *RegisterPasskeyInput) (*PasskeyOutput, error) {
+	if h.passkeyService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	userModel, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	passkey, err := h.passkeyService.FinishRegistration(ctx, userModel.ID, input.Body)
+	if err != nil {
+		return nil, passkeyErrorInternal(err, &common.PasskeyRegistrationError{Err: err})
+	}
+
+	return &PasskeyOutput{
+		Body: base.ApiResponse[auth.Passkey]{
+			Success: true,
+			Data:    *passkey,
+		},
+	}, nil
+}
+
+// RenamePasskey renames a passkey of the current user.
+func (h *AuthHandler) RenamePasskey(ctx context.Context, input *RenamePasskeyInput) (*PasskeyOutput, error) {
+	if h.passkeyService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	userModel, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	passkey, err := h.passkeyService.RenamePasskey(ctx, userModel.ID, input.PasskeyID, input.Body.Name)
+	if err != nil {
+		return nil, passkeyErrorInternal(err, &common.PasskeyUpdateError{Err: err})
+	}
+
+	return &PasskeyOutput{
+		Body: base.ApiResponse[auth.Passkey]{
+			Success: true,
+			Data:    *passkey,
+		},
+	}, nil
+}
+
+// DeletePasskey removes a passkey of the current user.
+func (h *AuthHandler) DeletePasskey(ctx context.Context, input *DeletePasskeyInput) (*DeletePasskeyOutput, error) {
+	if h.passkeyService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	userModel, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	if err := h.passkeyService.DeletePasskey(ctx, userModel.ID, input.PasskeyID); err != nil {
+		return nil, passkeyErrorInternal(err, &common.PasskeyDeletionError{Err: err})
+	}
+
+	return &DeletePasskeyOutput{
+		Body: base.ApiResponse[base.MessageResponse]{
+			Success: true,
+			Data: base.MessageResponse{
+				Message: "Passkey deleted successfully",
+			},
+		},
+	}, nil
+}
+
+// passkeyErrorInternal maps errors of passkey management to responses.
+func passkeyErrorInternal(err error, wrapped error) error {
+	switch {
+	case errors.Is(err, services.ErrPasskeyNotFound):
+		return huma.Error404NotFound((&common.PasskeyNotFoundError{}).Error())
+	case errors.Is(err, services.ErrPasskeyInvalid), errors.Is(err, services.ErrPasskeySessionExpired):
+		return huma.Error400BadRequest(err.Error())
+	case errors.Is(err, services.ErrPasskeyTooManyPending):
+		return huma.Error429TooManyRequests(err.Error())
+	}
+
+	apiErr := models.ToAPIError(err)
+	if apiErr.HTTPStatus() < http.StatusInternalServerError {
+		return huma.NewError(apiErr.HTTPStatus(), err.Error())
+	}
+	return huma.Error500InternalServerError(wrapped.Error())
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/getarcaneapp/arcane/backend/internal/common"
@@ -92,6 +93,34 @@ type SyncContainerRegistriesOutput struct {
 	Body base.ApiResponse[base.MessageResponse]
 }
 
+type ListRegistryRepositoriesInput struct {
+	ID     string `path:"id" doc:"Registry ID"`
+	Search string `query:"search" doc:"Only return repositories containing this text"`
+}
+
+type ListRegistryRepositoriesOutput struct {
+	Body base.ApiResponse[containerregistry.RepositoryList]
+}
+
+type ListRegistryTagsInput struct {
+	ID         string `path:"id" doc:"Registry ID"`
+	Repository string `query:"repository" required:"true" doc:"Repository name (e.g., org/app)"`
+}
+
+type ListRegistryTagsOutput struct {
+	Body base.ApiResponse[containerregistry.TagList]
+}
+
+type GetRegistryManifestInput struct {
+	ID         string `path:"id" doc:"Registry ID"`
+	Repository string `query:"repository" required:"true" doc:"Repository name (e.g., org/app)"`
+	Reference  string `query:"reference" default:"latest" doc:"Tag or digest"`
+}
+
+type GetRegistryManifestOutput struct {
+	Body base.ApiResponse[containerregistry.Manifest]
+}
+
 // ============================================================================
 // Registration
 // ============================================================================
@@ -190,6 +219,45 @@ func RegisterContainerRegistries(api huma.API, registryService *services.Contain
 			{"ApiKeyAuth": {}},
 		},
 	}, h.TestRegistry)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "listContainerRegistryRepositories",
+		Method:      "GET",
+		Path:        "/container-registries/{id}/repositories",
+		Summary:     "List registry repositories",
+		Description: "List the repositories in a container registry's catalog",
+		Tags:        []string{"Container Registries"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.ListRepositories)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "listContainerRegistryTags",
+		Method:      "GET",
+		Path:        "/container-registries/{id}/tags",
+		Summary:     "List repository tags",
+		Description: "List the tags of a repository in a container registry",
+		Tags:        []string{"Container Registries"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.ListTags)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "getContainerRegistryManifest",
+		Method:      "GET",
+		Path:        "/container-registries/{id}/manifests",
+		Summary:     "Get a tag's manifest",
+		Description: "Get the platforms, sizes, creation dates and labels of the images a tag or digest points to",
+		Tags:        []string{"Container Registries"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.GetManifest)
 }
 
 // ============================================================================
@@ -393,6 +461,96 @@ func (h *ContainerRegistryHandler) SyncRegistries(ctx context.Context, input *Sy
 	}, nil
 }
 
+// ListRepositories lists the repositories of a container registry.
+func (h *ContainerRegistryHandler) ListRepositories(ctx context.Context, input *ListRegistryRepositoriesInput) (*ListRegistryRepositoriesOutput, error) {
+	if h.registryService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	list, err := h.registryService.ListRepositories(ctx, input.ID)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.RegistryBrowseError{Err: err}).Error())
+	}
+
+	if term := strings.ToLower(strings.TrimSpace(input.Search)); term != "" {
+		filtered := make([]string, 0, len(list.Repositories))
+		for _, repo := range list.Repositories {
+			if strings.Contains(strings.ToLower(repo), term) {
+				filtered = append(filtered, repo)
+			}
+		}
+		list.Repositories = filtered
+	}
+
+	return &ListRegistryRepositoriesOutput{
+		Body: base.ApiResponse[containerregistry.RepositoryList]{
+			Success: true,
+			Data:    *list,
+		},
+	}, nil
+}
+
+// ListTags lists the tags of a repository in a container registry.
+func (h *ContainerRegistryHandler) ListTags(ctx context.Context, input *ListRegistryTagsInput) (*ListRegistryTagsOutput, error) {
+	if h.registryService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(input.Repository) == "" {
+		return nil, huma.Error400BadRequest("repository is required")
+	}
+
+	list, err := h.registryService.ListRepositoryTags(ctx, input.ID, strings.TrimSpace(input.Repository))
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.RegistryBrowseError{Err: err}).Error())
+	}
+
+	return &ListRegistryTagsOutput{
+		Body: base.ApiResponse[containerregistry.TagList]{
+			Success: true,
+			Data:    *list,
+		},
+	}, nil
+}
+
+// GetManifest describes the images a tag or digest of a repository points to.
+func (h *ContainerRegistryHandler) GetManifest(ctx context.Context, input *GetRegistryManifestInput) (*GetRegistryManifestOutput, error) {
+	if h.registryService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(input.Repository) == "" {
+		return nil, huma.Error400BadRequest("repository is required")
+	}
+
+	manifest, err := h.registryService.GetRepositoryManifest(ctx, input.ID, strings.TrimSpace(input.Repository), strings.TrimSpace(input.Reference))
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.RegistryBrowseError{Err: err}).Error())
+	}
+
+	return &GetRegistryManifestOutput{
+		Body: base.ApiResponse[containerregistry.Manifest]{
+			Success: true,
+			Data:    *manifest,
+		},
+	}, nil
+}
+
 // ============================================================================
 // Helper Methods
 // ============================================================================
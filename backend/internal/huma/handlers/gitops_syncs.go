@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/getarcaneapp/arcane/backend/internal/common"
 	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/services"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/git"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/mapper"
 	"github.com/getarcaneapp/arcane/types/base"
 	"github.com/getarcaneapp/arcane/types/gitops"
@@ -106,6 +108,24 @@ type BrowseSyncFilesOutput struct {
 	Body base.ApiResponse[gitops.BrowseResponse]
 }
 
+type DiffSyncInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	SyncID        string `path:"syncId" doc:"Sync ID"`
+}
+
+type DiffSyncOutput struct {
+	Body base.ApiResponse[gitops.SyncDiff]
+}
+
+type CheckSyncDriftInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	SyncID        string `path:"syncId" doc:"Sync ID"`
+}
+
+type CheckSyncDriftOutput struct {
+	Body base.ApiResponse[gitops.DriftReport]
+}
+
 type ImportGitOpsSyncsInput struct {
 	EnvironmentID string `path:"id" doc:"Environment ID"`
 	Body          []gitops.ImportGitOpsSyncRequest
@@ -115,6 +135,46 @@ type ImportGitOpsSyncsOutput struct {
 	Body base.ApiResponse[gitops.ImportGitOpsSyncResponse]
 }
 
+type EnableGitOpsSyncWebhookInput struct {
+	EnvironmentID string                       `path:"id" doc:"Environment ID"`
+	SyncID        string                       `path:"syncId" doc:"Sync ID"`
+	Body          *gitops.EnableWebhookRequest `required:"false"`
+}
+
+type EnableGitOpsSyncWebhookOutput struct {
+	Body base.ApiResponse[gitops.WebhookInfo]
+}
+
+type DisableGitOpsSyncWebhookInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	SyncID        string `path:"syncId" doc:"Sync ID"`
+}
+
+type DisableGitOpsSyncWebhookOutput struct {
+	Body base.ApiResponse[base.MessageResponse]
+}
+
+type GitOpsWebhookInput struct {
+	SyncID           string `path:"syncId" doc:"Sync ID"`
+	HubSignature256  string `header:"X-Hub-Signature-256" doc:"GitHub HMAC signature"`
+	GitHubEvent      string `header:"X-GitHub-Event" doc:"GitHub event name"`
+	GiteaSignature   string `header:"X-Gitea-Signature" doc:"Gitea/Forgejo HMAC signature"`
+	GogsSignature    string `header:"X-Gogs-Signature" doc:"Gogs HMAC signature"`
+	GiteaEvent       string `header:"X-Gitea-Event" doc:"Gitea/Forgejo event name"`
+	GogsEvent        string `header:"X-Gogs-Event" doc:"Gogs event name"`
+	GitlabToken      string `header:"X-Gitlab-Token" doc:"GitLab secret token"`
+	GitlabEvent      string `header:"X-Gitlab-Event" doc:"GitLab event name"`
+	WebhookID        string `header:"webhook-id" doc:"GitLab signing token message ID"`
+	WebhookTimestamp string `header:"webhook-timestamp" doc:"GitLab signing token timestamp"`
+	WebhookSignature string `header:"webhook-signature" doc:"GitLab signing token signature"`
+	RawBody          []byte
+}
+
+type GitOpsWebhookOutput struct {
+	Status int
+	Body   base.ApiResponse[gitops.WebhookResult]
+}
+
 // ============================================================================
 // Registration
 // ============================================================================
@@ -239,6 +299,68 @@ func RegisterGitOpsSyncs(api huma.API, syncService *services.GitOpsSyncService)
 			{"ApiKeyAuth": {}},
 		},
 	}, h.BrowseFiles)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "diffGitOpsSync",
+		Method:      "GET",
+		Path:        "/environments/{id}/gitops-syncs/{syncId}/diff",
+		Summary:     "Preview GitOps sync changes",
+		Description: "Show the file diff and service changes a sync would apply, without applying them",
+		Tags:        []string{"GitOps Syncs"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.DiffSync)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "checkGitOpsSyncDrift",
+		Method:      "POST",
+		Path:        "/environments/{id}/gitops-syncs/{syncId}/drift",
+		Summary:     "Check GitOps sync for drift",
+		Description: "Compare the project files and running containers with the last applied sync and record the result",
+		Tags:        []string{"GitOps Syncs"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.CheckDrift)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "enableGitOpsSyncWebhook",
+		Method:      "POST",
+		Path:        "/environments/{id}/gitops-syncs/{syncId}/webhook",
+		Summary:     "Enable GitOps sync webhook",
+		Description: "Enable the push webhook for a GitOps sync and rotate its secret",
+		Tags:        []string{"GitOps Syncs"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.EnableWebhook)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "disableGitOpsSyncWebhook",
+		Method:      "DELETE",
+		Path:        "/environments/{id}/gitops-syncs/{syncId}/webhook",
+		Summary:     "Disable GitOps sync webhook",
+		Description: "Disable the push webhook for a GitOps sync",
+		Tags:        []string{"GitOps Syncs"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.DisableWebhook)
+
+	// Webhook deliveries authenticate with the per-sync secret instead of a user session.
+	huma.Register(api, huma.Operation{
+		OperationID: "receiveGitOpsWebhook",
+		Method:      "POST",
+		Path:        "/webhooks/gitops/{syncId}",
+		Summary:     "Receive GitOps webhook",
+		Description: "Receive a push webhook from GitHub, Gitea or GitLab and trigger the matching sync",
+		Tags:        []string{"GitOps Syncs"},
+	}, h.ReceiveWebhook)
 }
 
 // ============================================================================
@@ -447,3 +569,129 @@ func (h *GitOpsSyncHandler) BrowseFiles(ctx context.Context, input *BrowseSyncFi
 		},
 	}, nil
 }
+
+// DiffSync previews the changes a sync would apply to its project.
+func (h *GitOpsSyncHandler) DiffSync(ctx context.Context, input *DiffSyncInput) (*DiffSyncOutput, error) {
+	if h.syncService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	diff, err := h.syncService.DiffSync(ctx, input.EnvironmentID, input.SyncID)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.GitOpsSyncDiffError{Err: err}).Error())
+	}
+
+	return &DiffSyncOutput{
+		Body: base.ApiResponse[gitops.SyncDiff]{
+			Success: true,
+			Data:    *diff,
+		},
+	}, nil
+}
+
+// CheckDrift checks a sync's project for drift from its git source.
+func (h *GitOpsSyncHandler) CheckDrift(ctx context.Context, input *CheckSyncDriftInput) (*CheckSyncDriftOutput, error) {
+	if h.syncService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	report, err := h.syncService.CheckDrift(ctx, input.EnvironmentID, input.SyncID)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.GitOpsSyncDriftError{Err: err}).Error())
+	}
+
+	return &CheckSyncDriftOutput{
+		Body: base.ApiResponse[gitops.DriftReport]{
+			Success: true,
+			Data:    *report,
+		},
+	}, nil
+}
+
+// EnableWebhook enables the push webhook of a GitOps sync.
+func (h *GitOpsSyncHandler) EnableWebhook(ctx context.Context, input *EnableGitOpsSyncWebhookInput) (*EnableGitOpsSyncWebhookOutput, error) {
+	if h.syncService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	var secret string
+	if input.Body != nil {
+		secret = input.Body.Secret
+	}
+
+	info, err := h.syncService.EnableWebhook(ctx, input.EnvironmentID, input.SyncID, secret)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.GitOpsSyncWebhookError{Err: err}).Error())
+	}
+
+	return &EnableGitOpsSyncWebhookOutput{
+		Body: base.ApiResponse[gitops.WebhookInfo]{
+			Success: true,
+			Data:    *info,
+		},
+	}, nil
+}
+
+// DisableWebhook disables the push webhook of a GitOps sync.
+func (h *GitOpsSyncHandler) DisableWebhook(ctx context.Context, input *DisableGitOpsSyncWebhookInput) (*DisableGitOpsSyncWebhookOutput, error) {
+	if h.syncService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := h.syncService.DisableWebhook(ctx, input.EnvironmentID, input.SyncID); err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.GitOpsSyncWebhookError{Err: err}).Error())
+	}
+
+	return &DisableGitOpsSyncWebhookOutput{
+		Body: base.ApiResponse[base.MessageResponse]{
+			Success: true,
+			Data: base.MessageResponse{
+				Message: "Webhook disabled successfully",
+			},
+		},
+	}, nil
+}
+
+// ReceiveWebhook handles a push webhook delivery from a git host.
+func (h *GitOpsSyncHandler) ReceiveWebhook(ctx context.Context, input *GitOpsWebhookInput) (*GitOpsWebhookOutput, error) {
+	if h.syncService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	headers := git.WebhookHeaders{
+		HubSignature256:  input.HubSignature256,
+		GitHubEvent:      input.GitHubEvent,
+		GiteaSignature:   input.GiteaSignature,
+		GogsSignature:    input.GogsSignature,
+		GiteaEvent:       input.GiteaEvent,
+		GogsEvent:        input.GogsEvent,
+		GitlabToken:      input.GitlabToken,
+		GitlabEvent:      input.GitlabEvent,
+		WebhookID:        input.WebhookID,
+		WebhookTime:      input.WebhookTimestamp,
+		WebhookSignature: input.WebhookSignature,
+	}
+
+	result, err := h.syncService.HandleWebhook(ctx, input.SyncID, headers, input.RawBody)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.GitOpsWebhookDeliveryError{Err: err}).Error())
+	}
+
+	status := http.StatusOK
+	if result.Triggered {
+		status = http.StatusAccepted
+	}
+
+	return &GitOpsWebhookOutput{
+		Status: status,
+		Body: base.ApiResponse[gitops.WebhookResult]{
+			Success: true,
+			Data:    *result,
+		},
+	}, nil
+}
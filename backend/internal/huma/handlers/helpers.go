@@ -4,7 +4,10 @@ import (
 	"context"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/getarcaneapp/arcane/backend/internal/common"
 	humamw "github.com/getarcaneapp/arcane/backend/internal/huma/middleware"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/services"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/pagination"
 )
 
@@ -16,6 +19,24 @@ func checkAdmin(ctx context.Context) error {
 	return nil
 }
 
+// checkPermission checks that the current user holds permission in the environment envID,
+// through a global or an environment-scoped role, and returns a 403 error if not. An empty
+// envID only counts global roles. Without a role service only admins pass.
+func checkPermission(ctx context.Context, roleService *services.RoleService, permission, envID string) error {
+	if roleService == nil {
+		return checkAdmin(ctx)
+	}
+	user, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+	if err := roleService.Authorize(ctx, user, permission, envID, ""); err != nil {
+		apiErr := models.ToAPIError(err)
+		return huma.NewError(apiErr.HTTPStatus(), apiErr.Message)
+	}
+	return nil
+}
+
 // buildPaginationParams converts query parameters to pagination.QueryParams.
 // It supports both the legacy nested style (page/limit) and the standard style (start/limit).
 // A limit of -1 means "show all items" (no pagination).
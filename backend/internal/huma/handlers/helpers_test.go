@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	glsqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	humamw "github.com/getarcaneapp/arcane/backend/internal/huma/middleware"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/services"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/rbac"
+	roletypes "github.com/getarcaneapp/arcane/types/role"
+)
+
+func TestCheckPermission_UsesEnvironmentRoles(t *testing.T) {
+	gdb, err := gorm.Open(glsqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, gdb.AutoMigrate(&models.User{}, &models.Role{}, &models.RoleAssignment{}))
+	roles := services.NewRoleService(&database.DB{DB: gdb})
+
+	user := &models.User{BaseModel: models.BaseModel{ID: "u1"}, Username: "operator", Roles: models.StringSlice{}}
+	require.NoError(t, gdb.Create(user).Error)
+	local, remote := "0", "env-1"
+	_, err = roles.SetUserAssignments(context.Background(), user.ID, []roletypes.Assignment{
+		{Role: rbac.RoleDeployer, EnvironmentID: &local},
+		{Role: rbac.RoleAdmin, EnvironmentID: &remote},
+	})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), humamw.ContextKeyCurrentUser, user)
+
+	// Role grants count even though the user isn't a global admin.
+	require.NoError(t, checkPermission(ctx, roles, rbac.Permission(rbac.ResourceBuilds, rbac.ActionCreate), local))
+	require.NoError(t, checkPermission(ctx, roles, rbac.Permission(rbac.ResourceSettings, rbac.ActionUpdate), remote))
+
+	err = checkPermission(ctx, roles, rbac.Permission(rbac.ResourceSettings, rbac.ActionUpdate), local)
+	var statusErr huma.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusForbidden, statusErr.GetStatus())
+
+	// Routes outside an environment only count global roles.
+	err = checkPermission(ctx, roles, rbac.Permission(rbac.ResourceSettings, rbac.ActionRead), "")
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusForbidden, statusErr.GetStatus())
+}
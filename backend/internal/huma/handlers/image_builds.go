@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/getarcaneapp/arcane/backend/internal/common"
+	humamw "github.com/getarcaneapp/arcane/backend/internal/huma/middleware"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/services"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/mapper"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/rbac"
+	"github.com/getarcaneapp/arcane/types/base"
+	"github.com/getarcaneapp/arcane/types/imagebuild"
+)
+
+// ImageBuildHandler handles image build endpoints.
+type ImageBuildHandler struct {
+	buildService *services.ImageBuildService
+	roleService  *services.RoleService
+}
+
+// ============================================================================
+// Input/Output Types
+// ============================================================================
+
+type ImageBuildPaginatedResponse struct {
+	Success    bool                    `json:"success"`
+	Data       []imagebuild.Build      `json:"data"`
+	Pagination base.PaginationResponse `json:"pagination"`
+}
+
+type ListImageBuildsInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	Search        string `query:"search" doc:"Search query"`
+	Sort          string `query:"sort" doc:"Column to sort by"`
+	Order         string `query:"order" default:"asc" doc:"Sort direction"`
+	Start         int    `query:"start" default:"0" doc:"Start index"`
+	Limit         int    `query:"limit" default:"20" doc:"Items per page"`
+	Status        string `query:"status" doc:"Filter by status (running, succeeded, failed, canceled)"`
+	Trigger       string `query:"trigger" doc:"Filter by trigger (manual, deploy, gitops)"`
+	ProjectID     string `query:"projectId" doc:"Filter by project ID"`
+}
+
+type ListImageBuildsOutput struct {
+	Body ImageBuildPaginatedResponse
+}
+
+type StartImageBuildInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	Body          imagebuild.BuildRequest
+}
+
+type ImageBuildIDInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	BuildID       string `path:"buildId" doc:"Image build ID"`
+}
+
+type ImageBuildOutput struct {
+	Body base.ApiResponse[imagebuild.Build]
+}
+
+type CancelImageBuildOutput struct {
+	Body base.ApiResponse[base.MessageResponse]
+}
+
+// ============================================================================
+// Registration
+// ============================================================================
+
+// RegisterImageBuilds registers the image build endpoints of an environment.
+func RegisterImageBuilds(api huma.API, buildService *services.ImageBuildService, roleService *services.RoleService) {
+	h := &ImageBuildHandler{buildService: buildService, roleService: roleService}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "listImageBuilds",
+		Method:      http.MethodGet,
+		Path:        "/environments/{id}/builds",
+		Summary:     "List image builds",
+		Description: "Get a paginated list of image builds, most recent first",
+		Tags:        []string{"Image Builds"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.ListBuilds)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "startImageBuild",
+		Method:      http.MethodPost,
+		Path:        "/environments/{id}/builds",
+		Summary:     "Start an image build",
+		Description: "Build images from a project or git repository. The build runs in the background; its progress is streamed over the builds WebSocket",
+		Tags:        []string{"Image Builds"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.StartBuild)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "getImageBuild",
+		Method:      http.MethodGet,
+		Path:        "/environments/{id}/builds/{buildId}",
+		Summary:     "Get an image build",
+		Description: "Get an image build by ID, including its status and log",
+		Tags:        []string{"Image Builds"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.GetBuild)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "cancelImageBuild",
+		Method:      http.MethodPost,
+		Path:        "/environments/{id}/builds/{buildId}/cancel",
+		Summary:     "Cancel an image build",
+		Description: "Stop a running image build",
+		Tags:        []string{"Image Builds"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.CancelBuild)
+}
+
+// ============================================================================
+// Handler Methods
+// ============================================================================
+
+// ListBuilds returns a paginated list of image builds.
+func (h *ImageBuildHandler) ListBuilds(ctx context.Context, input *ListImageBuildsInput) (*ListImageBuildsOutput, error) {
+	if h.buildService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	params := buildPaginationParams(0, input.Start, input.Limit, input.Sort, input.Order, input.Search)
+	if input.Status != "" {
+		params.Filters["status"] = input.Status
+	}
+	if input.Trigger != "" {
+		params.Filters["trigger"] = input.Trigger
+	}
+	if input.ProjectID != "" {
+		params.Filters["projectId"] = input.ProjectID
+	}
+
+	builds, paginationResp, err := h.buildService.ListBuildsPaginated(ctx, params)
+	if err != nil {
+		return nil, huma.Error500InternalServerError((&common.ImageBuildListError{Err: err}).Error())
+	}
+
+	return &ListImageBuildsOutput{
+		Body: ImageBuildPaginatedResponse{
+			Success: true,
+			Data:    builds,
+			Pagination: base.PaginationResponse{
+				TotalPages:      paginationResp.TotalPages,
+				TotalItems:      paginationResp.TotalItems,
+				CurrentPage:     paginationResp.CurrentPage,
+				ItemsPerPage:    paginationResp.ItemsPerPage,
+				GrandTotalItems: paginationResp.GrandTotalItems,
+			},
+		},
+	}, nil
+}
+
+// StartBuild starts an image build.
+func (h *ImageBuildHandler) StartBuild(ctx context.Context, input *StartImageBuildInput) (*ImageBuildOutput, error) {
+	if h.buildService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceBuilds, rbac.ActionCreate), input.EnvironmentID); err != nil {
+		return nil, err
+	}
+
+	user, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	b, err := h.buildService.StartBuild(ctx, input.Body, *user)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.ImageBuildStartError{Err: err}).Error())
+	}
+
+	return imageBuildOutputInternal(b)
+}
+
+// GetBuild returns an image build by ID.
+func (h *ImageBuildHandler) GetBuild(ctx context.Context, input *ImageBuildIDInput) (*ImageBuildOutput, error) {
+	if h.buildService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	b, err := h.buildService.GetBuildByID(ctx, input.BuildID)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.ImageBuildRetrievalError{Err: err}).Error())
+	}
+
+	return imageBuildOutputInternal(b)
+}
+
+// CancelBuild stops a running image build.
+func (h *ImageBuildHandler) CancelBuild(ctx context.Context, input *ImageBuildIDInput) (*CancelImageBuildOutput, error) {
+	if h.buildService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceBuilds, "cancel"), input.EnvironmentID); err != nil {
+		return nil, err
+	}
+
+	if err := h.buildService.CancelBuild(ctx, input.BuildID); err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.ImageBuildCancelError{Err: err}).Error())
+	}
+
+	return &CancelImageBuildOutput{
+		Body: base.ApiResponse[base.MessageResponse]{
+			Success: true,
+			Data: base.MessageResponse{
+				Message: "Image build canceled",
+			},
+		},
+	}, nil
+}
+
+func imageBuildOutputInternal(b *models.ImageBuild) (*ImageBuildOutput, error) {
+	out, mapErr := mapper.MapOne[*models.ImageBuild, imagebuild.Build](b)
+	if mapErr != nil {
+		return nil, huma.Error500InternalServerError((&common.ImageBuildMappingError{Err: mapErr}).Error())
+	}
+
+	return &ImageBuildOutput{
+		Body: base.ApiResponse[imagebuild.Build]{
+			Success: true,
+			Data:    out,
+		},
+	}, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/getarcaneapp/arcane/backend/internal/common"
 	humamw "github.com/getarcaneapp/arcane/backend/internal/huma/middleware"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/services"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/pagination"
 	"github.com/getarcaneapp/arcane/types/base"
@@ -73,6 +74,22 @@ type PullImageInput struct {
 	Body          image.PullOptions
 }
 
+type TagImageInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	ImageID       string `path:"imageId" doc:"Image ID or reference"`
+	Body          image.TagRequest
+}
+
+type TagImageOutput struct {
+	Body base.ApiResponse[image.TagResult]
+}
+
+type PushImageInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	ImageID       string `path:"imageId" doc:"Image ID or reference"`
+	Body          *image.PushRequest
+}
+
 type PruneImagesInput struct {
 	EnvironmentID string `path:"id" doc:"Environment ID"`
 	Dangling      bool   `query:"dangling" doc:"Only remove dangling images"`
@@ -182,6 +199,32 @@ func RegisterImages(api huma.API, dockerService *services.DockerClientService, i
 		},
 	}, h.PullImage)
 
+	huma.Register(api, huma.Operation{
+		OperationID: "tag-image",
+		Method:      http.MethodPost,
+		Path:        "/environments/{id}/images/{imageId}/tag",
+		Summary:     "Tag an image",
+		Description: "Add a new reference to a Docker image",
+		Tags:        []string{"Images"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.TagImage)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "push-image",
+		Method:      http.MethodPost,
+		Path:        "/environments/{id}/images/{imageId}/push",
+		Summary:     "Push an image",
+		Description: "Push a Docker image to its registry using the stored registry credentials, with streaming progress output",
+		Tags:        []string{"Images"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.PushImage)
+
 	huma.Register(api, huma.Operation{
 		OperationID: "prune-images",
 		Method:      http.MethodPost,
@@ -365,6 +408,64 @@ func (h *ImageHandler) PullImage(ctx context.Context, input *PullImageInput) (*h
 	}, nil
 }
 
+// TagImage adds a reference to a Docker image.
+func (h *ImageHandler) TagImage(ctx context.Context, input *TagImageInput) (*TagImageOutput, error) {
+	if h.imageService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	user, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	reference, err := h.imageService.TagImage(ctx, input.ImageID, input.Body.Target, *user)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.ImageTagError{Err: err}).Error())
+	}
+
+	return &TagImageOutput{
+		Body: base.ApiResponse[image.TagResult]{
+			Success: true,
+			Data:    image.TagResult{Reference: reference},
+		},
+	}, nil
+}
+
+// PushImage pushes a Docker image to its registry with streaming progress.
+func (h *ImageHandler) PushImage(ctx context.Context, input *PushImageInput) (*huma.StreamResponse, error) {
+	if h.imageService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	user, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	tag := ""
+	if input.Body != nil {
+		tag = input.Body.Tag
+	}
+
+	return &huma.StreamResponse{
+		Body: func(humaCtx huma.Context) { //nolint:contextcheck // context is obtained from humaCtx.Context()
+			humaCtx.SetHeader("Content-Type", "application/x-json-stream")
+			humaCtx.SetHeader("Cache-Control", "no-cache")
+			humaCtx.SetHeader("Connection", "keep-alive")
+			humaCtx.SetHeader("X-Accel-Buffering", "no")
+
+			writer := humaCtx.BodyWriter()
+
+			if _, err := h.imageService.PushImage(humaCtx.Context(), input.ImageID, tag, writer, *user); err != nil {
+				_, _ = fmt.Fprintf(writer, `{"error":%q}`+"\n", (&common.ImagePushError{Err: err}).Error())
+				return
+			}
+		},
+	}, nil
+}
+
 // PruneImages removes unused Docker images.
 func (h *ImageHandler) PruneImages(ctx context.Context, input *PruneImagesInput) (*PruneImagesOutput, error) {
 	if h.imageService == nil {
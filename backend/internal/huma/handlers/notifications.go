@@ -8,6 +8,7 @@ import (
 	"github.com/getarcaneapp/arcane/backend/internal/common"
 	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/services"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/rbac"
 	"github.com/getarcaneapp/arcane/types/base"
 	"github.com/getarcaneapp/arcane/types/notification"
 )
@@ -15,6 +16,7 @@ import (
 type NotificationHandler struct {
 	notificationService *services.NotificationService
 	appriseService      *services.AppriseService //nolint:staticcheck // Apprise still functional, deprecated in favor of Shoutrrr
+	roleService         *services.RoleService
 }
 
 type GetAllNotificationSettingsInput struct {
@@ -90,10 +92,11 @@ type TestAppriseNotificationOutput struct {
 // RegisterNotifications registers notification endpoints.
 //
 //nolint:staticcheck // AppriseService still functional, deprecated in favor of Shoutrrr
-func RegisterNotifications(api huma.API, notificationSvc *services.NotificationService, appriseSvc *services.AppriseService) {
+func RegisterNotifications(api huma.API, notificationSvc *services.NotificationService, appriseSvc *services.AppriseService, roleSvc *services.RoleService) {
 	h := &NotificationHandler{
 		notificationService: notificationSvc,
 		appriseService:      appriseSvc,
+		roleService:         roleSvc,
 	}
 
 	huma.Register(api, huma.Operation{
@@ -170,7 +173,7 @@ func RegisterNotifications(api huma.API, notificationSvc *services.NotificationS
 }
 
 func (h *NotificationHandler) GetAllNotificationSettings(ctx context.Context, input *GetAllNotificationSettingsInput) (*GetAllNotificationSettingsOutput, error) {
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceNotifications, rbac.ActionRead), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 	settings, err := h.notificationService.GetAllSettings(ctx)
@@ -192,7 +195,7 @@ func (h *NotificationHandler) GetAllNotificationSettings(ctx context.Context, in
 }
 
 func (h *NotificationHandler) GetNotificationSettings(ctx context.Context, input *GetNotificationSettingsInput) (*GetNotificationSettingsOutput, error) {
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceNotifications, rbac.ActionRead), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 	provider := models.NotificationProvider(input.Provider)
@@ -213,7 +216,7 @@ func (h *NotificationHandler) GetNotificationSettings(ctx context.Context, input
 }
 
 func (h *NotificationHandler) CreateOrUpdateNotificationSettings(ctx context.Context, input *CreateOrUpdateNotificationSettingsInput) (*CreateOrUpdateNotificationSettingsOutput, error) {
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceNotifications, rbac.ActionUpdate), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 	provider := models.NotificationProvider(input.Body.Provider)
@@ -242,7 +245,7 @@ func (h *NotificationHandler) CreateOrUpdateNotificationSettings(ctx context.Con
 }
 
 func (h *NotificationHandler) DeleteNotificationSettings(ctx context.Context, input *DeleteNotificationSettingsInput) (*DeleteNotificationSettingsOutput, error) {
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceNotifications, rbac.ActionUpdate), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 	provider := models.NotificationProvider(input.Provider)
@@ -260,7 +263,7 @@ func (h *NotificationHandler) DeleteNotificationSettings(ctx context.Context, in
 }
 
 func (h *NotificationHandler) TestNotification(ctx context.Context, input *TestNotificationInput) (*TestNotificationOutput, error) {
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceNotifications, "test"), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 	provider := models.NotificationProvider(input.Provider)
@@ -278,7 +281,7 @@ func (h *NotificationHandler) TestNotification(ctx context.Context, input *TestN
 }
 
 func (h *NotificationHandler) GetAppriseSettings(ctx context.Context, input *GetAppriseSettingsInput) (*GetAppriseSettingsOutput, error) {
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceNotifications, rbac.ActionRead), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 	settings, err := h.appriseService.GetSettings(ctx)
@@ -301,7 +304,7 @@ func (h *NotificationHandler) GetAppriseSettings(ctx context.Context, input *Get
 }
 
 func (h *NotificationHandler) CreateOrUpdateAppriseSettings(ctx context.Context, input *CreateOrUpdateAppriseSettingsInput) (*CreateOrUpdateAppriseSettingsOutput, error) {
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceNotifications, rbac.ActionUpdate), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 	if input.Body.Enabled && input.Body.APIURL == "" {
@@ -331,7 +334,7 @@ func (h *NotificationHandler) CreateOrUpdateAppriseSettings(ctx context.Context,
 }
 
 func (h *NotificationHandler) TestAppriseNotification(ctx context.Context, input *TestAppriseNotificationInput) (*TestAppriseNotificationOutput, error) {
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceNotifications, "test"), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 	if err := h.appriseService.TestNotification(ctx); err != nil {
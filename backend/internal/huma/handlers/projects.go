@@ -140,6 +140,25 @@ type RestartProjectOutput struct {
 	Body base.ApiResponse[base.MessageResponse]
 }
 
+type ListProjectDeploymentsInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	ProjectID     string `path:"projectId" doc:"Project ID"`
+}
+
+type ListProjectDeploymentsOutput struct {
+	Body base.ApiResponse[[]project.Deployment]
+}
+
+type RollbackProjectInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	ProjectID     string `path:"projectId" doc:"Project ID"`
+	Body          *project.Rollback
+}
+
+type RollbackProjectOutput struct {
+	Body base.ApiResponse[project.Deployment]
+}
+
 type PullProjectImagesInput struct {
 	EnvironmentID string `path:"id" doc:"Environment ID"`
 	ProjectID     string `path:"projectId" doc:"Project ID"`
@@ -319,6 +338,32 @@ func RegisterProjects(api huma.API, projectService *services.ProjectService) {
 			{"ApiKeyAuth": {}},
 		},
 	}, h.PullProjectImages)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-project-deployments",
+		Method:      http.MethodGet,
+		Path:        "/environments/{id}/projects/{projectId}/deployments",
+		Summary:     "List project deployments",
+		Description: "Get the recorded deployment history for a project, newest first",
+		Tags:        []string{"Projects"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.ListProjectDeployments)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "rollback-project",
+		Method:      http.MethodPost,
+		Path:        "/environments/{id}/projects/{projectId}/rollback",
+		Summary:     "Roll back a project",
+		Description: "Restore the compose, .env and include files and image digests of a previous deployment and redeploy",
+		Tags:        []string{"Projects"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.RollbackProject)
 }
 
 // ListProjects returns a paginated list of projects.
@@ -713,3 +758,76 @@ func (h *ProjectHandler) PullProjectImages(ctx context.Context, input *PullProje
 		},
 	}, nil
 }
+
+// ListProjectDeployments returns the deployment history of a project.
+func (h *ProjectHandler) ListProjectDeployments(ctx context.Context, input *ListProjectDeploymentsInput) (*ListProjectDeploymentsOutput, error) {
+	if h.projectService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if input.ProjectID == "" {
+		return nil, huma.Error400BadRequest((&common.ProjectIDRequiredError{}).Error())
+	}
+
+	deployments, err := h.projectService.ListProjectDeployments(ctx, input.ProjectID)
+	if err != nil {
+		apiErr := (&common.ProjectDeploymentListError{Err: err}).Error()
+		if errors.Is(err, services.ErrProjectNotFound) {
+			return nil, huma.Error404NotFound(apiErr)
+		}
+		return nil, huma.Error500InternalServerError(apiErr)
+	}
+
+	out := make([]project.Deployment, 0, len(deployments))
+	for i := range deployments {
+		out = append(out, deployments[i].ToDTO())
+	}
+
+	return &ListProjectDeploymentsOutput{
+		Body: base.ApiResponse[[]project.Deployment]{
+			Success: true,
+			Data:    out,
+		},
+	}, nil
+}
+
+// RollbackProject rolls a project back to a previous deployment.
+func (h *ProjectHandler) RollbackProject(ctx context.Context, input *RollbackProjectInput) (*RollbackProjectOutput, error) {
+	if h.projectService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if input.ProjectID == "" {
+		return nil, huma.Error400BadRequest((&common.ProjectIDRequiredError{}).Error())
+	}
+
+	user, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	deploymentID := ""
+	if input.Body != nil {
+		deploymentID = input.Body.DeploymentID
+	}
+
+	restored, err := h.projectService.RollbackProject(ctx, input.ProjectID, deploymentID, *user)
+	if err != nil {
+		apiErr := (&common.ProjectRollbackError{Err: err}).Error()
+		switch {
+		case errors.Is(err, services.ErrProjectNotFound), errors.Is(err, services.ErrProjectDeploymentNotFound):
+			return nil, huma.Error404NotFound(apiErr)
+		case errors.Is(err, services.ErrProjectRollbackUnavailable):
+			return nil, huma.Error400BadRequest(apiErr)
+		default:
+			return nil, huma.Error500InternalServerError(apiErr)
+		}
+	}
+
+	return &RollbackProjectOutput{
+		Body: base.ApiResponse[project.Deployment]{
+			Success: true,
+			Data:    restored.ToDTO(),
+		},
+	}, nil
+}
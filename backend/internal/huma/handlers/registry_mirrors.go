@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/getarcaneapp/arcane/backend/internal/common"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/services"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/mapper"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/rbac"
+	"github.com/getarcaneapp/arcane/types/base"
+	"github.com/getarcaneapp/arcane/types/registrymirror"
+)
+
+// RegistryMirrorHandler handles registry mirror endpoints.
+type RegistryMirrorHandler struct {
+	mirrorService *services.RegistryMirrorService
+	roleService   *services.RoleService
+}
+
+// ============================================================================
+// Input/Output Types
+// ============================================================================
+
+type ListRegistryMirrorsInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+}
+
+type ListRegistryMirrorsOutput struct {
+	Body base.ApiResponse[[]registrymirror.RegistryMirror]
+}
+
+type CreateRegistryMirrorInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	Body          registrymirror.CreateRequest
+}
+
+type RegistryMirrorIDInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	MirrorID      string `path:"mirrorId" doc:"Registry mirror ID"`
+}
+
+type UpdateRegistryMirrorInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	MirrorID      string `path:"mirrorId" doc:"Registry mirror ID"`
+	Body          registrymirror.UpdateRequest
+}
+
+type RegistryMirrorOutput struct {
+	Body base.ApiResponse[registrymirror.RegistryMirror]
+}
+
+type DeleteRegistryMirrorOutput struct {
+	Body base.ApiResponse[base.MessageResponse]
+}
+
+// ============================================================================
+// Registration
+// ============================================================================
+
+// RegisterRegistryMirrors registers the registry mirror endpoints of an environment.
+func RegisterRegistryMirrors(api huma.API, mirrorService *services.RegistryMirrorService, roleService *services.RoleService) {
+	h := &RegistryMirrorHandler{mirrorService: mirrorService, roleService: roleService}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "listRegistryMirrors",
+		Method:      http.MethodGet,
+		Path:        "/environments/{id}/registry-mirrors",
+		Summary:     "List registry mirrors",
+		Description: "Get the registry mirrors image pulls in this environment go through",
+		Tags:        []string{"Registry Mirrors"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.ListMirrors)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "createRegistryMirror",
+		Method:      http.MethodPost,
+		Path:        "/environments/{id}/registry-mirrors",
+		Summary:     "Create a registry mirror",
+		Description: "Add a mirror that is tried before the upstream registry when pulling images",
+		Tags:        []string{"Registry Mirrors"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.CreateMirror)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "getRegistryMirror",
+		Method:      http.MethodGet,
+		Path:        "/environments/{id}/registry-mirrors/{mirrorId}",
+		Summary:     "Get a registry mirror",
+		Description: "Get a registry mirror by ID, including when it was last used and its last error",
+		Tags:        []string{"Registry Mirrors"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.GetMirror)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "updateRegistryMirror",
+		Method:      http.MethodPut,
+		Path:        "/environments/{id}/registry-mirrors/{mirrorId}",
+		Summary:     "Update a registry mirror",
+		Description: "Update the rewrite rule, priority or fallback behaviour of a registry mirror",
+		Tags:        []string{"Registry Mirrors"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.UpdateMirror)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "deleteRegistryMirror",
+		Method:      http.MethodDelete,
+		Path:        "/environments/{id}/registry-mirrors/{mirrorId}",
+		Summary:     "Delete a registry mirror",
+		Description: "Delete a registry mirror by ID",
+		Tags:        []string{"Registry Mirrors"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.DeleteMirror)
+}
+
+// ============================================================================
+// Handler Methods
+// ============================================================================
+
+// ListMirrors returns all registry mirrors of the environment.
+func (h *RegistryMirrorHandler) ListMirrors(ctx context.Context, input *ListRegistryMirrorsInput) (*ListRegistryMirrorsOutput, error) {
+	if h.mirrorService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	mirrors, err := h.mirrorService.ListMirrors(ctx)
+	if err != nil {
+		return nil, huma.Error500InternalServerError((&common.RegistryMirrorListError{Err: err}).Error())
+	}
+
+	out, mapErr := mapper.MapSlice[models.RegistryMirror, registrymirror.RegistryMirror](mirrors)
+	if mapErr != nil {
+		return nil, huma.Error500InternalServerError((&common.RegistryMirrorMappingError{Err: mapErr}).Error())
+	}
+
+	return &ListRegistryMirrorsOutput{
+		Body: base.ApiResponse[[]registrymirror.RegistryMirror]{
+			Success: true,
+			Data:    out,
+		},
+	}, nil
+}
+
+// CreateMirror creates a registry mirror.
+func (h *RegistryMirrorHandler) CreateMirror(ctx context.Context, input *CreateRegistryMirrorInput) (*RegistryMirrorOutput, error) {
+	if h.mirrorService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceRegistries, rbac.ActionCreate), input.EnvironmentID); err != nil {
+		return nil, err
+	}
+
+	m, err := h.mirrorService.CreateMirror(ctx, input.Body)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.RegistryMirrorCreationError{Err: err}).Error())
+	}
+
+	return registryMirrorOutputInternal(m)
+}
+
+// GetMirror returns a registry mirror by ID.
+func (h *RegistryMirrorHandler) GetMirror(ctx context.Context, input *RegistryMirrorIDInput) (*RegistryMirrorOutput, error) {
+	if h.mirrorService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	m, err := h.mirrorService.GetMirrorByID(ctx, input.MirrorID)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.RegistryMirrorRetrievalError{Err: err}).Error())
+	}
+
+	return registryMirrorOutputInternal(m)
+}
+
+// UpdateMirror updates a registry mirror.
+func (h *RegistryMirrorHandler) UpdateMirror(ctx context.Context, input *UpdateRegistryMirrorInput) (*RegistryMirrorOutput, error) {
+	if h.mirrorService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceRegistries, rbac.ActionUpdate), input.EnvironmentID); err != nil {
+		return nil, err
+	}
+
+	m, err := h.mirrorService.UpdateMirror(ctx, input.MirrorID, input.Body)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.RegistryMirrorUpdateError{Err: err}).Error())
+	}
+
+	return registryMirrorOutputInternal(m)
+}
+
+// DeleteMirror deletes a registry mirror.
+func (h *RegistryMirrorHandler) DeleteMirror(ctx context.Context, input *RegistryMirrorIDInput) (*DeleteRegistryMirrorOutput, error) {
+	if h.mirrorService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceRegistries, rbac.ActionDelete), input.EnvironmentID); err != nil {
+		return nil, err
+	}
+
+	if err := h.mirrorService.DeleteMirror(ctx, input.MirrorID); err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.RegistryMirrorDeletionError{Err: err}).Error())
+	}
+
+	return &DeleteRegistryMirrorOutput{
+		Body: base.ApiResponse[base.MessageResponse]{
+			Success: true,
+			Data: base.MessageResponse{
+				Message: "Registry mirror deleted successfully",
+			},
+		},
+	}, nil
+}
+
+func registryMirrorOutputInternal(m *models.RegistryMirror) (*RegistryMirrorOutput, error) {
+	out, mapErr := mapper.MapOne[*models.RegistryMirror, registrymirror.RegistryMirror](m)
+	if mapErr != nil {
+		return nil, huma.Error500InternalServerError((&common.RegistryMirrorMappingError{Err: mapErr}).Error())
+	}
+
+	return &RegistryMirrorOutput{
+		Body: base.ApiResponse[registrymirror.RegistryMirror]{
+			Success: true,
+			Data:    out,
+		},
+	}, nil
+}
@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/getarcaneapp/arcane/backend/internal/common"
+	humamw "github.com/getarcaneapp/arcane/backend/internal/huma/middleware"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/services"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/rbac"
+	"github.com/getarcaneapp/arcane/types/base"
+	"github.com/getarcaneapp/arcane/types/role"
+)
+
+// RoleHandler handles role, role assignment and permission endpoints.
+type RoleHandler struct {
+	roleService *services.RoleService
+}
+
+// ============================================================================
+// Input/Output Types
+// ============================================================================
+
+type ListRolesOutput struct {
+	Body base.ApiResponse[[]role.Role]
+}
+
+type ListPermissionsOutput struct {
+	Body base.ApiResponse[[]role.ResourcePermissions]
+}
+
+type RoleNameInput struct {
+	Name string `path:"name" doc:"Role name"`
+}
+
+type CreateRoleInput struct {
+	Body role.CreateRequest
+}
+
+type UpdateRoleInput struct {
+	Name string `path:"name" doc:"Role name"`
+	Body role.UpdateRequest
+}
+
+type RoleOutput struct {
+	Body base.ApiResponse[role.Role]
+}
+
+type DeleteRoleOutput struct {
+	Body base.ApiResponse[base.MessageResponse]
+}
+
+type UserRoleAssignmentsInput struct {
+	UserID string `path:"userId" doc:"User ID"`
+}
+
+type SetUserRoleAssignmentsInput struct {
+	UserID string `path:"userId" doc:"User ID"`
+	Body   role.SetAssignmentsRequest
+}
+
+type UserRoleAssignmentsOutput struct {
+	Body base.ApiResponse[[]role.Assignment]
+}
+
+type GetEnvironmentPermissionsInput struct {
+	EnvironmentID string `path:"id" doc:"Environment ID"`
+	ProjectID     string `query:"projectId" doc:"Project ID to include project role assignments"`
+}
+
+type GetEnvironmentPermissionsOutput struct {
+	Body base.ApiResponse[role.EffectivePermissions]
+}
+
+// ============================================================================
+// Registration
+// ============================================================================
+
+// RegisterRoles registers the role, role assignment and permission endpoints.
+func RegisterRoles(api huma.API, roleService *services.RoleService) {
+	h := &RoleHandler{roleService: roleService}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "listRoles",
+		Method:      http.MethodGet,
+		Path:        "/roles",
+		Summary:     "List roles",
+		Description: "List the built-in and custom roles with their permissions",
+		Tags:        []string{"Roles"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.ListRoles)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "listPermissions",
+		Method:      http.MethodGet,
+		Path:        "/roles/permissions",
+		Summary:     "List permissions",
+		Description: "List the resources and actions permissions can be granted on",
+		Tags:        []string{"Roles"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.ListPermissions)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "getRole",
+		Method:      http.MethodGet,
+		Path:        "/roles/{name}",
+		Summary:     "Get a role",
+		Description: "Get a role by name",
+		Tags:        []string{"Roles"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.GetRole)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "createRole",
+		Method:      http.MethodPost,
+		Path:        "/roles",
+		Summary:     "Create a role",
+		Description: "Create a custom role",
+		Tags:        []string{"Roles"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.CreateRole)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "updateRole",
+		Method:      http.MethodPut,
+		Path:        "/roles/{name}",
+		Summary:     "Update a role",
+		Description: "Update the description and permissions of a custom role",
+		Tags:        []string{"Roles"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.UpdateRole)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "deleteRole",
+		Method:      http.MethodDelete,
+		Path:        "/roles/{name}",
+		Summary:     "Delete a role",
+		Description: "Delete a custom role and remove it from the users that hold it",
+		Tags:        []string{"Roles"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.DeleteRole)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "listUserRoleAssignments",
+		Method:      http.MethodGet,
+		Path:        "/users/{userId}/role-assignments",
+		Summary:     "List role assignments of a user",
+		Description: "List the roles a user holds in specific environments and projects",
+		Tags:        []string{"Roles"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.ListUserAssignments)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "setUserRoleAssignments",
+		Method:      http.MethodPut,
+		Path:        "/users/{userId}/role-assignments",
+		Summary:     "Set role assignments of a user",
+		Description: "Replace the roles a user holds in specific environments and projects",
+		Tags:        []string{"Roles"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.SetUserAssignments)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "getEnvironmentPermissions",
+		Method:      http.MethodGet,
+		Path:        "/environments/{id}/permissions",
+		Summary:     "Get my permissions",
+		Description: "Get the permissions the current user holds in an environment",
+		Tags:        []string{"Roles"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.GetEnvironmentPermissions)
+}
+
+// ============================================================================
+// Handler Methods
+// ============================================================================
+
+// ListRoles returns the built-in and custom roles.
+func (h *RoleHandler) ListRoles(ctx context.Context, _ *struct{}) (*ListRolesOutput, error) {
+	if h.roleService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	roles, err := h.roleService.ListRoles(ctx)
+	if err != nil {
+		return nil, huma.Error500InternalServerError((&common.RoleListError{Err: err}).Error())
+	}
+
+	return &ListRolesOutput{
+		Body: base.ApiResponse[[]role.Role]{
+			Success: true,
+			Data:    roles,
+		},
+	}, nil
+}
+
+// ListPermissions returns the permission catalog.
+func (h *RoleHandler) ListPermissions(_ context.Context, _ *struct{}) (*ListPermissionsOutput, error) {
+	out := make([]role.ResourcePermissions, 0, len(rbac.Catalog))
+	for _, ra := range rbac.Catalog {
+		out = append(out, role.ResourcePermissions{Resource: ra.Resource, Actions: ra.Actions})
+	}
+
+	return &ListPermissionsOutput{
+		Body: base.ApiResponse[[]role.ResourcePermissions]{
+			Success: true,
+			Data:    out,
+		},
+	}, nil
+}
+
+// GetRole returns a role by name.
+func (h *RoleHandler) GetRole(ctx context.Context, input *RoleNameInput) (*RoleOutput, error) {
+	if h.roleService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	r, err := h.roleService.GetRole(ctx, input.Name)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.RoleRetrievalError{Err: err}).Error())
+	}
+
+	return roleOutputInternal(r), nil
+}
+
+// CreateRole creates a custom role.
+func (h *RoleHandler) CreateRole(ctx context.Context, input *CreateRoleInput) (*RoleOutput, error) {
+	if h.roleService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	r, err := h.roleService.CreateRole(ctx, input.Body)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.RoleCreationError{Err: err}).Error())
+	}
+
+	return roleOutputInternal(r), nil
+}
+
+// UpdateRole updates a custom role.
+func (h *RoleHandler) UpdateRole(ctx context.Context, input *UpdateRoleInput) (*RoleOutput, error) {
+	if h.roleService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	r, err := h.roleService.UpdateRole(ctx, input.Name, input.Body)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.RoleUpdateError{Err: err}).Error())
+	}
+
+	return roleOutputInternal(r), nil
+}
+
+// DeleteRole deletes a custom role.
+func (h *RoleHandler) DeleteRole(ctx context.Context, input *RoleNameInput) (*DeleteRoleOutput, error) {
+	if h.roleService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := h.roleService.DeleteRole(ctx, input.Name); err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.RoleDeletionError{Err: err}).Error())
+	}
+
+	return &DeleteRoleOutput{
+		Body: base.ApiResponse[base.MessageResponse]{
+			Success: true,
+			Data: base.MessageResponse{
+				Message: "Role deleted successfully",
+			},
+		},
+	}, nil
+}
+
+// ListUserAssignments returns the role assignments of a user.
+func (h *RoleHandler) ListUserAssignments(ctx context.Context, input *UserRoleAssignmentsInput) (*UserRoleAssignmentsOutput, error) {
+	if h.roleService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	assignments, err := h.roleService.ListUserAssignments(ctx, input.UserID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError((&common.RoleAssignmentListError{Err: err}).Error())
+	}
+
+	return assignmentsOutputInternal(assignments), nil
+}
+
+// SetUserAssignments replaces the role assignments of a user.
+func (h *RoleHandler) SetUserAssignments(ctx context.Context, input *SetUserRoleAssignmentsInput) (*UserRoleAssignmentsOutput, error) {
+	if h.roleService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	assignments, err := h.roleService.SetUserAssignments(ctx, input.UserID, input.Body.Assignments)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.RoleAssignmentUpdateError{Err: err}).Error())
+	}
+
+	return assignmentsOutputInternal(assignments), nil
+}
+
+// GetEnvironmentPermissions returns the permissions of the current user in an environment.
+func (h *RoleHandler) GetEnvironmentPermissions(ctx context.Context, input *GetEnvironmentPermissionsInput) (*GetEnvironmentPermissionsOutput, error) {
+	if h.roleService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	user, exists := humamw.GetCurrentUserFromContext(ctx)
+	if !exists {
+		return nil, huma.Error401Unauthorized((&common.NotAuthenticatedError{}).Error())
+	}
+
+	perms, admin, err := h.roleService.Permissions(ctx, user, input.EnvironmentID, input.ProjectID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError((&common.PermissionsRetrievalError{Err: err}).Error())
+	}
+	if perms == nil {
+		perms = []string{}
+	}
+
+	return &GetEnvironmentPermissionsOutput{
+		Body: base.ApiResponse[role.EffectivePermissions]{
+			Success: true,
+			Data: role.EffectivePermissions{
+				EnvironmentID: input.EnvironmentID,
+				ProjectID:     input.ProjectID,
+				Admin:         admin,
+				Permissions:   perms,
+			},
+		},
+	}, nil
+}
+
+func roleOutputInternal(r *role.Role) *RoleOutput {
+	return &RoleOutput{
+		Body: base.ApiResponse[role.Role]{
+			Success: true,
+			Data:    *r,
+		},
+	}
+}
+
+func assignmentsOutputInternal(assignments []models.RoleAssignment) *UserRoleAssignmentsOutput {
+	out := make([]role.Assignment, 0, len(assignments))
+	for _, a := range assignments {
+		out = append(out, role.Assignment{
+			ID:            a.ID,
+			Role:          a.Role,
+			EnvironmentID: a.EnvironmentID,
+			ProjectID:     a.ProjectID,
+		})
+	}
+
+	return &UserRoleAssignmentsOutput{
+		Body: base.ApiResponse[[]role.Assignment]{
+			Success: true,
+			Data:    out,
+		},
+	}
+}
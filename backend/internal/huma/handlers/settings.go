@@ -15,6 +15,7 @@ import (
 	"github.com/getarcaneapp/arcane/backend/internal/services"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/mapper"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/pathmapper"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/rbac"
 	"github.com/getarcaneapp/arcane/types/base"
 	"github.com/getarcaneapp/arcane/types/category"
 	"github.com/getarcaneapp/arcane/types/search"
@@ -26,6 +27,7 @@ type SettingsHandler struct {
 	settingsService       *services.SettingsService
 	settingsSearchService *services.SettingsSearchService
 	environmentService    *services.EnvironmentService
+	roleService           *services.RoleService
 	cfg                   *config.Config
 }
 
@@ -95,11 +97,12 @@ func validateProjectsDirectoryValue(path string) error {
 }
 
 // RegisterSettings registers settings management routes using Huma.
-func RegisterSettings(api huma.API, settingsService *services.SettingsService, settingsSearchService *services.SettingsSearchService, environmentService *services.EnvironmentService, cfg *config.Config) {
+func RegisterSettings(api huma.API, settingsService *services.SettingsService, settingsSearchService *services.SettingsSearchService, environmentService *services.EnvironmentService, roleService *services.RoleService, cfg *config.Config) {
 	h := &SettingsHandler{
 		settingsService:       settingsService,
 		settingsSearchService: settingsSearchService,
 		environmentService:    environmentService,
+		roleService:           roleService,
 		cfg:                   cfg,
 	}
 
@@ -284,7 +287,7 @@ func (h *SettingsHandler) UpdateSettings(ctx context.Context, input *UpdateSetti
 		return nil, huma.Error500InternalServerError("service not available")
 	}
 
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceSettings, rbac.ActionUpdate), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 
@@ -309,12 +312,20 @@ func (h *SettingsHandler) UpdateSettings(ctx context.Context, input *UpdateSetti
 		req := input.Body
 		if req.AuthLocalEnabled != nil || req.OidcEnabled != nil ||
 			req.AuthSessionTimeout != nil || req.AuthPasswordPolicy != nil ||
+			req.AuthRequireTwoFactor != nil ||
 			req.AuthOidcConfig != nil || req.OidcClientId != nil ||
 			req.OidcClientSecret != nil || req.OidcIssuerUrl != nil ||
 			req.OidcScopes != nil || req.OidcAdminClaim != nil ||
 			req.OidcAdminValue != nil || req.OidcMergeAccounts != nil ||
 			req.OidcSkipTlsVerify != nil || req.OidcAutoRedirectToProvider != nil ||
-			req.OidcProviderName != nil || req.OidcProviderLogoUrl != nil {
+			req.OidcProviderName != nil || req.OidcProviderLogoUrl != nil ||
+			req.LdapEnabled != nil || req.LdapUrl != nil ||
+			req.LdapStartTls != nil || req.LdapSkipTlsVerify != nil ||
+			req.LdapBindDn != nil || req.LdapBindPassword != nil ||
+			req.LdapBaseDn != nil || req.LdapUserFilter != nil ||
+			req.LdapUsernameAttribute != nil || req.LdapEmailAttribute != nil ||
+			req.LdapDisplayNameAttribute != nil || req.LdapGroupAttribute != nil ||
+			req.LdapGroupFilter != nil || req.LdapGroupRoleMapping != nil {
 			return nil, huma.Error403Forbidden((&common.AuthSettingsUpdateError{}).Error())
 		}
 
@@ -369,7 +380,7 @@ func (h *SettingsHandler) Search(ctx context.Context, input *SearchSettingsInput
 		return nil, huma.Error500InternalServerError("service not available")
 	}
 
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceSettings, rbac.ActionRead), ""); err != nil {
 		return nil, err
 	}
 
@@ -387,7 +398,7 @@ func (h *SettingsHandler) GetCategories(ctx context.Context, input *struct{}) (*
 		return nil, huma.Error500InternalServerError("service not available")
 	}
 
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceSettings, rbac.ActionRead), ""); err != nil {
 		return nil, err
 	}
 
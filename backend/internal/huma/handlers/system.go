@@ -13,6 +13,7 @@ import (
 	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/services"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/docker"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/rbac"
 	"github.com/getarcaneapp/arcane/types/base"
 	containertypes "github.com/getarcaneapp/arcane/types/container"
 	"github.com/getarcaneapp/arcane/types/dockerinfo"
@@ -24,6 +25,7 @@ type SystemHandler struct {
 	dockerService  *services.DockerClientService
 	systemService  *services.SystemService
 	upgradeService *services.SystemUpgradeService
+	roleService    *services.RoleService
 	cfg            *config.Config
 }
 
@@ -112,11 +114,12 @@ type TriggerUpgradeOutput struct {
 
 // RegisterSystem registers system management endpoints using Huma.
 // Note: WebSocket endpoints (stats) remain in the Gin handler.
-func RegisterSystem(api huma.API, dockerService *services.DockerClientService, systemService *services.SystemService, upgradeService *services.SystemUpgradeService, cfg *config.Config) {
+func RegisterSystem(api huma.API, dockerService *services.DockerClientService, systemService *services.SystemService, upgradeService *services.SystemUpgradeService, roleService *services.RoleService, cfg *config.Config) {
 	h := &SystemHandler{
 		dockerService:  dockerService,
 		systemService:  systemService,
 		upgradeService: upgradeService,
+		roleService:    roleService,
 		cfg:            cfg,
 	}
 
@@ -319,7 +322,7 @@ func (h *SystemHandler) PruneAll(ctx context.Context, input *PruneAllInput) (*Pr
 		return nil, huma.Error500InternalServerError("service not available")
 	}
 
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceSystem, "prune"), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 
@@ -358,7 +361,7 @@ func (h *SystemHandler) StartAllContainers(ctx context.Context, input *StartAllC
 		return nil, huma.Error500InternalServerError("service not available")
 	}
 
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceContainers, "start"), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 
@@ -381,7 +384,7 @@ func (h *SystemHandler) StartAllStoppedContainers(ctx context.Context, input *St
 		return nil, huma.Error500InternalServerError("service not available")
 	}
 
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceContainers, "start"), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 
@@ -404,7 +407,7 @@ func (h *SystemHandler) StopAllContainers(ctx context.Context, input *StopAllCon
 		return nil, huma.Error500InternalServerError("service not available")
 	}
 
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceContainers, "stop"), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 
@@ -453,7 +456,7 @@ func (h *SystemHandler) CheckUpgradeAvailable(ctx context.Context, input *CheckU
 		return nil, huma.Error500InternalServerError("service not available")
 	}
 
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceSystem, rbac.ActionRead), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 
@@ -484,7 +487,7 @@ func (h *SystemHandler) TriggerUpgrade(ctx context.Context, input *TriggerUpgrad
 		return nil, huma.Error500InternalServerError("service not available")
 	}
 
-	if err := checkAdmin(ctx); err != nil {
+	if err := checkPermission(ctx, h.roleService, rbac.Permission(rbac.ResourceSystem, "upgrade"), input.EnvironmentID); err != nil {
 		return nil, err
 	}
 
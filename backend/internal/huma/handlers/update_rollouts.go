@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/getarcaneapp/arcane/backend/internal/common"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/services"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/mapper"
+	"github.com/getarcaneapp/arcane/types/base"
+	"github.com/getarcaneapp/arcane/types/rollout"
+)
+
+// UpdateRolloutHandler handles staged update rollout endpoints.
+type UpdateRolloutHandler struct {
+	rolloutService *services.UpdateRolloutService
+}
+
+// ============================================================================
+// Input/Output Types
+// ============================================================================
+
+// UpdateRolloutPaginatedResponse is the paginated response for update rollouts.
+type UpdateRolloutPaginatedResponse struct {
+	Success    bool                    `json:"success"`
+	Data       []rollout.Rollout       `json:"data"`
+	Pagination base.PaginationResponse `json:"pagination"`
+}
+
+type ListUpdateRolloutsInput struct {
+	Search string `query:"search" doc:"Search query"`
+	Sort   string `query:"sort" doc:"Column to sort by"`
+	Order  string `query:"order" default:"asc" doc:"Sort direction"`
+	Start  int    `query:"start" default:"0" doc:"Start index"`
+	Limit  int    `query:"limit" default:"20" doc:"Items per page"`
+	Status string `query:"status" doc:"Filter by rollout status"`
+}
+
+type ListUpdateRolloutsOutput struct {
+	Body UpdateRolloutPaginatedResponse
+}
+
+type CreateUpdateRolloutInput struct {
+	Body rollout.CreateRequest
+}
+
+type UpdateRolloutIDInput struct {
+	ID string `path:"id" doc:"Rollout ID"`
+}
+
+type UpdateUpdateRolloutInput struct {
+	ID   string `path:"id" doc:"Rollout ID"`
+	Body rollout.UpdateRequest
+}
+
+type UpdateRolloutOutput struct {
+	Body base.ApiResponse[rollout.Rollout]
+}
+
+type DeleteUpdateRolloutOutput struct {
+	Body base.ApiResponse[base.MessageResponse]
+}
+
+// ============================================================================
+// Registration
+// ============================================================================
+
+// RegisterUpdateRollouts registers the staged update rollout endpoints.
+func RegisterUpdateRollouts(api huma.API, rolloutService *services.UpdateRolloutService) {
+	h := &UpdateRolloutHandler{rolloutService: rolloutService}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "listUpdateRollouts",
+		Method:      http.MethodGet,
+		Path:        "/update-rollouts",
+		Summary:     "List update rollouts",
+		Description: "Get a paginated list of staged update rollouts",
+		Tags:        []string{"Update Rollouts"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.ListRollouts)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "createUpdateRollout",
+		Method:      http.MethodPost,
+		Path:        "/update-rollouts",
+		Summary:     "Create an update rollout",
+		Description: "Create a rollout that applies image updates to groups of environments in order",
+		Tags:        []string{"Update Rollouts"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.CreateRollout)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "getUpdateRollout",
+		Method:      http.MethodGet,
+		Path:        "/update-rollouts/{id}",
+		Summary:     "Get an update rollout",
+		Description: "Get an update rollout and the results of its current run",
+		Tags:        []string{"Update Rollouts"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.GetRollout)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "updateUpdateRollout",
+		Method:      http.MethodPut,
+		Path:        "/update-rollouts/{id}",
+		Summary:     "Update an update rollout",
+		Description: "Change the name or stages of a rollout that is not in progress",
+		Tags:        []string{"Update Rollouts"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.UpdateRollout)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "deleteUpdateRollout",
+		Method:      http.MethodDelete,
+		Path:        "/update-rollouts/{id}",
+		Summary:     "Delete an update rollout",
+		Description: "Delete an update rollout by ID",
+		Tags:        []string{"Update Rollouts"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.DeleteRollout)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "startUpdateRollout",
+		Method:      http.MethodPost,
+		Path:        "/update-rollouts/{id}/start",
+		Summary:     "Start an update rollout",
+		Description: "Run the updater on the first stage and promote the following stages as they stay healthy",
+		Tags:        []string{"Update Rollouts"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.StartRollout)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "cancelUpdateRollout",
+		Method:      http.MethodPost,
+		Path:        "/update-rollouts/{id}/cancel",
+		Summary:     "Cancel an update rollout",
+		Description: "Stop a rollout before its next stage, updates already applied are kept",
+		Tags:        []string{"Update Rollouts"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.CancelRollout)
+}
+
+// ============================================================================
+// Handler Methods
+// ============================================================================
+
+// ListRollouts returns a paginated list of update rollouts.
+func (h *UpdateRolloutHandler) ListRollouts(ctx context.Context, input *ListUpdateRolloutsInput) (*ListUpdateRolloutsOutput, error) {
+	if h.rolloutService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	params := buildPaginationParams(0, input.Start, input.Limit, input.Sort, input.Order, input.Search)
+	if input.Status != "" {
+		params.Filters["status"] = input.Status
+	}
+
+	rollouts, paginationResp, err := h.rolloutService.GetRolloutsPaginated(ctx, params)
+	if err != nil {
+		return nil, huma.Error500InternalServerError((&common.UpdateRolloutListError{Err: err}).Error())
+	}
+
+	return &ListUpdateRolloutsOutput{
+		Body: UpdateRolloutPaginatedResponse{
+			Success: true,
+			Data:    rollouts,
+			Pagination: base.PaginationResponse{
+				TotalPages:      paginationResp.TotalPages,
+				TotalItems:      paginationResp.TotalItems,
+				CurrentPage:     paginationResp.CurrentPage,
+				ItemsPerPage:    paginationResp.ItemsPerPage,
+				GrandTotalItems: paginationResp.GrandTotalItems,
+			},
+		},
+	}, nil
+}
+
+// CreateRollout creates a new update rollout.
+func (h *UpdateRolloutHandler) CreateRollout(ctx context.Context, input *CreateUpdateRolloutInput) (*UpdateRolloutOutput, error) {
+	if h.rolloutService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	r, err := h.rolloutService.CreateRollout(ctx, input.Body)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.UpdateRolloutCreationError{Err: err}).Error())
+	}
+
+	return rolloutOutputInternal(r)
+}
+
+// GetRollout returns an update rollout by ID.
+func (h *UpdateRolloutHandler) GetRollout(ctx context.Context, input *UpdateRolloutIDInput) (*UpdateRolloutOutput, error) {
+	if h.rolloutService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	r, err := h.rolloutService.GetRolloutByID(ctx, input.ID)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.UpdateRolloutRetrievalError{Err: err}).Error())
+	}
+
+	return rolloutOutputInternal(r)
+}
+
+// UpdateRollout updates the name or stages of an update rollout.
+func (h *UpdateRolloutHandler) UpdateRollout(ctx context.Context, input *UpdateUpdateRolloutInput) (*UpdateRolloutOutput, error) {
+	if h.rolloutService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	r, err := h.rolloutService.UpdateRollout(ctx, input.ID, input.Body)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.UpdateRolloutUpdateError{Err: err}).Error())
+	}
+
+	return rolloutOutputInternal(r)
+}
+
+// DeleteRollout deletes an update rollout.
+func (h *UpdateRolloutHandler) DeleteRollout(ctx context.Context, input *UpdateRolloutIDInput) (*DeleteUpdateRolloutOutput, error) {
+	if h.rolloutService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := h.rolloutService.DeleteRollout(ctx, input.ID); err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.UpdateRolloutDeletionError{Err: err}).Error())
+	}
+
+	return &DeleteUpdateRolloutOutput{
+		Body: base.ApiResponse[base.MessageResponse]{
+			Success: true,
+			Data: base.MessageResponse{
+				Message: "Update rollout deleted successfully",
+			},
+		},
+	}, nil
+}
+
+// StartRollout starts an update rollout from its first stage.
+func (h *UpdateRolloutHandler) StartRollout(ctx context.Context, input *UpdateRolloutIDInput) (*UpdateRolloutOutput, error) {
+	if h.rolloutService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	r, err := h.rolloutService.StartRollout(ctx, input.ID)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.UpdateRolloutStartError{Err: err}).Error())
+	}
+
+	return rolloutOutputInternal(r)
+}
+
+// CancelRollout cancels an update rollout in progress.
+func (h *UpdateRolloutHandler) CancelRollout(ctx context.Context, input *UpdateRolloutIDInput) (*UpdateRolloutOutput, error) {
+	if h.rolloutService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	r, err := h.rolloutService.CancelRollout(ctx, input.ID)
+	if err != nil {
+		apiErr := models.ToAPIError(err)
+		return nil, huma.NewError(apiErr.HTTPStatus(), (&common.UpdateRolloutCancelError{Err: err}).Error())
+	}
+
+	return rolloutOutputInternal(r)
+}
+
+func rolloutOutputInternal(r *models.UpdateRollout) (*UpdateRolloutOutput, error) {
+	out, mapErr := mapper.MapOne[*models.UpdateRollout, rollout.Rollout](r)
+	if mapErr != nil {
+		return nil, huma.Error500InternalServerError((&common.UpdateRolloutMappingError{Err: mapErr}).Error())
+	}
+
+	return &UpdateRolloutOutput{
+		Body: base.ApiResponse[rollout.Rollout]{
+			Success: true,
+			Data:    out,
+		},
+	}, nil
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -119,12 +120,29 @@ func (h *UpdaterHandler) RunUpdater(ctx context.Context, input *RunUpdaterInput)
 		return nil, huma.Error500InternalServerError("service not available")
 	}
 
-	dryRun := false
+	dryRun, background := false, false
+	var images []string
 	if input.Body != nil {
-		dryRun = input.Body.DryRun
+		dryRun, background, images = input.Body.DryRun, input.Body.Background, input.Body.Images
 	}
 
-	out, err := h.updaterService.ApplyPending(ctx, dryRun)
+	if background {
+		runID, err := h.updaterService.StartApplyPending(ctx, dryRun, images)
+		if err != nil {
+			if errors.Is(err, services.ErrUpdaterRunInProgress) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+			return nil, huma.Error500InternalServerError((&common.UpdaterRunError{Err: err}).Error())
+		}
+		return &RunUpdaterOutput{
+			Body: base.ApiResponse[*updater.Result]{
+				Success: true,
+				Data:    &updater.Result{RunID: runID, Items: []updater.ResourceResult{}},
+			},
+		}, nil
+	}
+
+	out, err := h.updaterService.ApplyPending(ctx, dryRun, images)
 	if err != nil {
 		return nil, huma.Error500InternalServerError((&common.UpdaterRunError{Err: err}).Error())
 	}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -9,13 +10,17 @@ import (
 	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/services"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/mapper"
+	"github.com/getarcaneapp/arcane/types/auth"
 	"github.com/getarcaneapp/arcane/types/base"
 	"github.com/getarcaneapp/arcane/types/user"
 )
 
 // UserHandler handles user management endpoints.
 type UserHandler struct {
-	userService *services.UserService
+	userService      *services.UserService
+	roleService      *services.RoleService
+	twoFactorService *services.TwoFactorService
+	passkeyService   *services.PasskeyService
 }
 
 // ============================================================================
@@ -74,13 +79,38 @@ type DeleteUserOutput struct {
 	Body base.ApiResponse[base.MessageResponse]
 }
 
+type ResetUserTwoFactorInput struct {
+	UserID string `path:"userId" doc:"User ID"`
+}
+
+type ResetUserTwoFactorOutput struct {
+	Body base.ApiResponse[base.MessageResponse]
+}
+
+type ListUserPasskeysInput struct {
+	UserID string `path:"userId" doc:"User ID"`
+}
+
+type ListUserPasskeysOutput struct {
+	Body base.ApiResponse[[]auth.Passkey]
+}
+
+type DeleteUserPasskeyInput struct {
+	UserID    string `path:"userId" doc:"User ID"`
+	PasskeyID string `path:"passkeyId" doc:"Passkey ID"`
+}
+
+type DeleteUserPasskeyOutput struct {
+	Body base.ApiResponse[base.MessageResponse]
+}
+
 // ============================================================================
 // Registration
 // ============================================================================
 
 // RegisterUsers registers all user management endpoints.
-func RegisterUsers(api huma.API, userService *services.UserService) {
-	h := &UserHandler{userService: userService}
+func RegisterUsers(api huma.API, userService *services.UserService, roleService *services.RoleService, twoFactorService *services.TwoFactorService, passkeyService *services.PasskeyService) {
+	h := &UserHandler{userService: userService, roleService: roleService, twoFactorService: twoFactorService, passkeyService: passkeyService}
 
 	huma.Register(api, huma.Operation{
 		OperationID: "listUsers",
@@ -146,6 +176,45 @@ func RegisterUsers(api huma.API, userService *services.UserService) {
 			{"ApiKeyAuth": {}},
 		},
 	}, h.DeleteUser)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "resetUserTwoFactor",
+		Method:      "DELETE",
+		Path:        "/users/{userId}/two-factor",
+		Summary:     "Reset two-factor authentication",
+		Description: "Remove the authenticator app and recovery codes of a user who lost access to them",
+		Tags:        []string{"Users"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.ResetUserTwoFactor)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "listUserPasskeys",
+		Method:      "GET",
+		Path:        "/users/{userId}/passkeys",
+		Summary:     "List a user's passkeys",
+		Description: "List the passkeys registered by a user",
+		Tags:        []string{"Users"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.ListUserPasskeys)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "deleteUserPasskey",
+		Method:      "DELETE",
+		Path:        "/users/{userId}/passkeys/{passkeyId}",
+		Summary:     "Delete a user's passkey",
+		Description: "Remove a passkey of a user, e.g. for a lost device",
+		Tags:        []string{"Users"},
+		Security: []map[string][]string{
+			{"BearerAuth": {}},
+			{"ApiKeyAuth": {}},
+		},
+	}, h.DeleteUserPasskey)
 }
 
 // ============================================================================
@@ -214,6 +283,9 @@ func (h *UserHandler) CreateUser(ctx context.Context, input *CreateUserInput) (*
 	if userModel.Roles == nil {
 		userModel.Roles = []string{"user"}
 	}
+	if err := h.validateRolesInternal(ctx, userModel.Roles); err != nil {
+		return nil, err
+	}
 
 	createdUser, err := h.userService.CreateUser(ctx, userModel)
 	if err != nil {
@@ -283,6 +355,9 @@ func (h *UserHandler) UpdateUser(ctx context.Context, input *UpdateUserInput) (*
 		userModel.Email = input.Body.Email
 	}
 	if input.Body.Roles != nil {
+		if err := h.validateRolesInternal(ctx, input.Body.Roles); err != nil {
+			return nil, err
+		}
 		userModel.Roles = input.Body.Roles
 	}
 	if input.Body.Locale != nil {
@@ -341,3 +416,91 @@ func (h *UserHandler) DeleteUser(ctx context.Context, input *DeleteUserInput) (*
 		},
 	}, nil
 }
+
+// ResetUserTwoFactor removes the two-factor authentication of a user.
+func (h *UserHandler) ResetUserTwoFactor(ctx context.Context, input *ResetUserTwoFactorInput) (*ResetUserTwoFactorOutput, error) {
+	if h.twoFactorService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := h.twoFactorService.Reset(ctx, input.UserID); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return nil, huma.Error404NotFound((&common.UserNotFoundError{}).Error())
+		}
+		return nil, huma.Error500InternalServerError((&common.TwoFactorDisableError{Err: err}).Error())
+	}
+
+	return &ResetUserTwoFactorOutput{
+		Body: base.ApiResponse[base.MessageResponse]{
+			Success: true,
+			Data: base.MessageResponse{
+				Message: "Two-factor authentication reset successfully",
+			},
+		},
+	}, nil
+}
+
+// ListUserPasskeys returns the passkeys of a user.
+func (h *UserHandler) ListUserPasskeys(ctx context.Context, input *ListUserPasskeysInput) (*ListUserPasskeysOutput, error) {
+	if h.passkeyService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	passkeys, err := h.passkeyService.ListPasskeys(ctx, input.UserID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError((&common.PasskeyListError{Err: err}).Error())
+	}
+
+	return &ListUserPasskeysOutput{
+		Body: base.ApiResponse[[]auth.Passkey]{
+			Success: true,
+			Data:    passkeys,
+		},
+	}, nil
+}
+
+// DeleteUserPasskey removes a passkey of a user.
+func (h *UserHandler) DeleteUserPasskey(ctx context.Context, input *DeleteUserPasskeyInput) (*DeleteUserPasskeyOutput, error) {
+	if h.passkeyService == nil {
+		return nil, huma.Error500InternalServerError("service not available")
+	}
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := h.passkeyService.DeletePasskey(ctx, input.UserID, input.PasskeyID); err != nil {
+		if errors.Is(err, services.ErrPasskeyNotFound) {
+			return nil, huma.Error404NotFound((&common.PasskeyNotFoundError{}).Error())
+		}
+		return nil, huma.Error500InternalServerError((&common.PasskeyDeletionError{Err: err}).Error())
+	}
+
+	return &DeleteUserPasskeyOutput{
+		Body: base.ApiResponse[base.MessageResponse]{
+			Success: true,
+			Data: base.MessageResponse{
+				Message: "Passkey deleted successfully",
+			},
+		},
+	}, nil
+}
+
+func (h *UserHandler) validateRolesInternal(ctx context.Context, roles []string) error {
+	if h.roleService == nil {
+		return nil
+	}
+	if err := h.roleService.ValidateRoleNames(ctx, roles); err != nil {
+		apiErr := models.ToAPIError(err)
+		return huma.NewError(apiErr.HTTPStatus(), apiErr.Message)
+	}
+	return nil
+}
@@ -118,6 +118,9 @@ type Services struct {
 	Auth              *services.AuthService
 	Oidc              *services.OidcService
 	ApiKey            *services.ApiKeyService
+	Role              *services.RoleService
+	TwoFactor         *services.TwoFactorService
+	Passkey           *services.PasskeyService
 	AppImages         *services.ApplicationImagesService
 	Font              *services.FontService
 	Project           *services.ProjectService
@@ -128,9 +131,11 @@ type Services struct {
 	JobSchedule       *services.JobService
 	SettingsSearch    *services.SettingsSearchService
 	ContainerRegistry *services.ContainerRegistryService
+	RegistryMirror    *services.RegistryMirrorService
 	Template          *services.TemplateService
 	Docker            *services.DockerClientService
 	Image             *services.ImageService
+	ImageBuild        *services.ImageBuildService
 	ImageUpdate       *services.ImageUpdateService
 	Volume            *services.VolumeService
 	Container         *services.ContainerService
@@ -143,6 +148,7 @@ type Services struct {
 	SystemUpgrade     *services.SystemUpgradeService
 	GitRepository     *services.GitRepositoryService
 	GitOpsSync        *services.GitOpsSyncService
+	UpdateRollout     *services.UpdateRolloutService
 	Vulnerability     *services.VulnerabilityService
 	Config            *config.Config
 }
@@ -190,7 +196,7 @@ func SetupAPI(router *gin.Engine, apiGroup *gin.RouterGroup, cfg *config.Config,
 	api := humagin.NewWithGroup(router, apiGroup, humaConfig)
 
 	// Add authentication middleware
-	api.UseMiddleware(middleware.NewAuthBridge(api, svc.Auth, svc.ApiKey, cfg))
+	api.UseMiddleware(middleware.NewAuthBridge(api, svc.Auth, svc.ApiKey, svc.Role, cfg))
 
 	// Register all Huma handlers
 	registerHandlers(api, svc)
@@ -277,6 +283,9 @@ func registerHandlers(api huma.API, svc *Services) {
 	var authSvc *services.AuthService
 	var oidcSvc *services.OidcService
 	var apiKeySvc *services.ApiKeyService
+	var roleSvc *services.RoleService
+	var twoFactorSvc *services.TwoFactorService
+	var passkeySvc *services.PasskeyService
 	var appImagesSvc *services.ApplicationImagesService
 	var fontSvc *services.FontService
 	var projectSvc *services.ProjectService
@@ -287,9 +296,11 @@ func registerHandlers(api huma.API, svc *Services) {
 	var jobScheduleSvc *services.JobService
 	var settingsSearchSvc *services.SettingsSearchService
 	var containerRegistrySvc *services.ContainerRegistryService
+	var registryMirrorSvc *services.RegistryMirrorService
 	var templateSvc *services.TemplateService
 	var dockerSvc *services.DockerClientService
 	var imageSvc *services.ImageService
+	var imageBuildSvc *services.ImageBuildService
 	var imageUpdateSvc *services.ImageUpdateService
 	var volumeSvc *services.VolumeService
 	var containerSvc *services.ContainerService
@@ -302,6 +313,7 @@ func registerHandlers(api huma.API, svc *Services) {
 	var systemUpgradeSvc *services.SystemUpgradeService
 	var gitRepositorySvc *services.GitRepositoryService
 	var gitOpsSyncSvc *services.GitOpsSyncService
+	var updateRolloutSvc *services.UpdateRolloutService
 	var vulnerabilitySvc *services.VulnerabilityService
 	var cfg *config.Config
 
@@ -310,6 +322,9 @@ func registerHandlers(api huma.API, svc *Services) {
 		authSvc = svc.Auth
 		oidcSvc = svc.Oidc
 		apiKeySvc = svc.ApiKey
+		roleSvc = svc.Role
+		twoFactorSvc = svc.TwoFactor
+		passkeySvc = svc.Passkey
 		appImagesSvc = svc.AppImages
 		fontSvc = svc.Font
 		projectSvc = svc.Project
@@ -320,9 +335,11 @@ func registerHandlers(api huma.API, svc *Services) {
 		jobScheduleSvc = svc.JobSchedule
 		settingsSearchSvc = svc.SettingsSearch
 		containerRegistrySvc = svc.ContainerRegistry
+		registryMirrorSvc = svc.RegistryMirror
 		templateSvc = svc.Template
 		dockerSvc = svc.Docker
 		imageSvc = svc.Image
+		imageBuildSvc = svc.ImageBuild
 		imageUpdateSvc = svc.ImageUpdate
 		volumeSvc = svc.Volume
 		containerSvc = svc.Container
@@ -335,34 +352,39 @@ func registerHandlers(api huma.API, svc *Services) {
 		systemUpgradeSvc = svc.SystemUpgrade
 		gitRepositorySvc = svc.GitRepository
 		gitOpsSyncSvc = svc.GitOpsSync
+		updateRolloutSvc = svc.UpdateRollout
 		vulnerabilitySvc = svc.Vulnerability
 		cfg = svc.Config
 	}
 	handlers.RegisterHealth(api)
-	handlers.RegisterAuth(api, userSvc, authSvc, oidcSvc)
+	handlers.RegisterAuth(api, userSvc, authSvc, oidcSvc, twoFactorSvc, passkeySvc)
 	handlers.RegisterApiKeys(api, apiKeySvc)
 	handlers.RegisterAppImages(api, appImagesSvc)
 	handlers.RegisterFonts(api, fontSvc)
 	handlers.RegisterProjects(api, projectSvc)
-	handlers.RegisterUsers(api, userSvc)
+	handlers.RegisterUsers(api, userSvc, roleSvc, twoFactorSvc, passkeySvc)
+	handlers.RegisterRoles(api, roleSvc)
 	handlers.RegisterVersion(api, versionSvc)
 	handlers.RegisterEvents(api, eventSvc)
 	handlers.RegisterOidc(api, authSvc, oidcSvc, cfg)
 	handlers.RegisterEnvironments(api, environmentSvc, settingsSvc, apiKeySvc, eventSvc, cfg)
 	handlers.RegisterContainerRegistries(api, containerRegistrySvc)
+	handlers.RegisterRegistryMirrors(api, registryMirrorSvc, roleSvc)
 	handlers.RegisterTemplates(api, templateSvc)
 	handlers.RegisterImages(api, dockerSvc, imageSvc, imageUpdateSvc, settingsSvc)
+	handlers.RegisterImageBuilds(api, imageBuildSvc, roleSvc)
 	handlers.RegisterImageUpdates(api, imageUpdateSvc)
-	handlers.RegisterSettings(api, settingsSvc, settingsSearchSvc, environmentSvc, cfg)
+	handlers.RegisterSettings(api, settingsSvc, settingsSearchSvc, environmentSvc, roleSvc, cfg)
 	handlers.RegisterJobSchedules(api, jobScheduleSvc, environmentSvc)
 	handlers.RegisterVolumes(api, dockerSvc, volumeSvc)
 	handlers.RegisterContainers(api, containerSvc, dockerSvc)
 	handlers.RegisterNetworks(api, networkSvc, dockerSvc)
-	handlers.RegisterNotifications(api, notificationSvc, appriseSvc)
+	handlers.RegisterNotifications(api, notificationSvc, appriseSvc, roleSvc)
 	handlers.RegisterUpdater(api, updaterSvc)
 	handlers.RegisterCustomize(api, customizeSearchSvc)
-	handlers.RegisterSystem(api, dockerSvc, systemSvc, systemUpgradeSvc, cfg)
+	handlers.RegisterSystem(api, dockerSvc, systemSvc, systemUpgradeSvc, roleSvc, cfg)
 	handlers.RegisterGitRepositories(api, gitRepositorySvc)
 	handlers.RegisterGitOpsSyncs(api, gitOpsSyncSvc)
+	handlers.RegisterUpdateRollouts(api, updateRolloutSvc)
 	handlers.RegisterVulnerability(api, vulnerabilitySvc)
 }
@@ -0,0 +1,70 @@
+package huma
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getarcaneapp/arcane/backend/internal/api"
+	"github.com/getarcaneapp/arcane/backend/internal/config"
+	"github.com/getarcaneapp/arcane/backend/internal/middleware"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/rbac"
+)
+
+// Every environment route must map to a permission, otherwise only admins can call it.
+func TestRegisteredEnvironmentRoutesHavePermissions(t *testing.T) {
+	checked := 0
+	check := func(method, path string) {
+		t.Helper()
+		permission, scoped, err := rbac.RequiredPermission(method, path)
+		require.NoError(t, err, "%s %s has no permission mapped", method, path)
+		if !scoped {
+			return
+		}
+		checked++
+		assert.NotEmpty(t, permission, "%s %s", method, path)
+
+		// Project-scoped assignments only apply when the route names the project.
+		if rest, ok := strings.CutPrefix(path, "/environments/{id}/projects/"); ok && rest != "counts" {
+			assert.True(t, strings.HasPrefix(rest, "{projectId}"), "%s %s must name the project as {projectId}", method, path)
+		}
+	}
+
+	for path, item := range SetupAPIForSpec().OpenAPI().Paths {
+		ops := map[string]*huma.Operation{
+			http.MethodGet:    item.Get,
+			http.MethodPost:   item.Post,
+			http.MethodPut:    item.Put,
+			http.MethodPatch:  item.Patch,
+			http.MethodDelete: item.Delete,
+		}
+		for method, op := range ops {
+			if op == nil {
+				continue
+			}
+			check(method, path)
+		}
+	}
+
+	// WebSocket routes are registered on gin directly.
+	router := gin.New()
+	cfg := &config.Config{}
+	api.NewWebSocketHandler(router.Group("/api"), nil, nil, nil, nil, middleware.NewAuthMiddleware(nil, cfg), cfg)
+	for _, r := range router.Routes() {
+		check(r.Method, r.Path)
+	}
+
+	assert.Positive(t, checked)
+
+	// Verbs that only read must not fall through to the create permission.
+	driftPath := "/environments/{id}/gitops-syncs/{syncId}/drift"
+	require.NotNil(t, SetupAPIForSpec().OpenAPI().Paths[driftPath].Post)
+	permission, _, err := rbac.RequiredPermission(http.MethodPost, driftPath)
+	require.NoError(t, err)
+	assert.Equal(t, rbac.Permission(rbac.ResourceGitOps, rbac.ActionRead), permission)
+}
@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humagin"
 	"github.com/getarcaneapp/arcane/backend/internal/config"
 	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/services"
@@ -28,6 +30,8 @@ const (
 	ContextKeyCurrentUser ContextKey = "currentUser"
 	// ContextKeyUserIsAdmin is the context key for whether the user is an admin.
 	ContextKeyUserIsAdmin ContextKey = "userIsAdmin"
+	// ContextKeyClientIP is the context key for the client IP of the request.
+	ContextKeyClientIP ContextKey = "clientIP"
 )
 
 // GetUserIDFromContext retrieves the user ID from the context.
@@ -48,6 +52,12 @@ func IsAdminFromContext(ctx context.Context) bool {
 	return ok && isAdmin
 }
 
+// GetClientIPFromContext retrieves the client IP of the request from the context.
+func GetClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(ContextKeyClientIP).(string)
+	return ip
+}
+
 // securityRequirements holds parsed security requirements from an operation.
 type securityRequirements struct {
 	isRequired bool
@@ -91,19 +101,39 @@ func tryBearerAuth(ctx huma.Context, authService *services.AuthService) (*models
 	return user, true
 }
 
-// tryApiKeyAuth checks if API key authentication should be allowed through.
-func tryApiKeyAuth(ctx huma.Context, apiKeyService *services.ApiKeyService) (*models.User, bool) {
+// tryApiKeyAuth validates the API key of the request against the key's restrictions.
+func tryApiKeyAuth(ctx huma.Context, apiKeyService *services.ApiKeyService) (*models.User, error) {
 	apiKey := ctx.Header(headerApiKey)
 	if apiKey == "" {
-		return nil, false
+		return nil, services.ErrApiKeyInvalid
 	}
 
-	user, err := apiKeyService.ValidateApiKey(ctx.Context(), apiKey)
-	if err != nil || user == nil {
-		return nil, false
+	user, err := apiKeyService.ValidateApiKey(ctx.Context(), apiKey, apiKeyAccess(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, services.ErrApiKeyInvalid
 	}
 
-	return user, true
+	return user, nil
+}
+
+// apiKeyAccess describes the operation of ctx for API key restriction checks.
+func apiKeyAccess(ctx huma.Context) services.ApiKeyAccess {
+	access := services.ApiKeyAccess{
+		ClientIP: humagin.Unwrap(ctx).ClientIP(),
+		Method:   ctx.Method(),
+	}
+	if op := ctx.Operation(); op != nil {
+		access.Method = op.Method
+		access.Route = op.Path
+		if strings.HasPrefix(op.Path, "/environments/{id}") {
+			access.EnvironmentID = ctx.Param("id")
+			access.ProjectID = ctx.Param("projectId")
+		}
+	}
+	return access
 }
 
 // tryAgentAuth checks if the request is from an authenticated agent.
@@ -145,10 +175,41 @@ func createAgentSudoUser() *models.User {
 	}
 }
 
+// authorizeOperation checks that user holds the permission the operation requires in the
+// environment and project of the request.
+func authorizeOperation(ctx huma.Context, roleService *services.RoleService, user *models.User) (int, error) {
+	if roleService == nil || ctx.Operation() == nil {
+		return 0, nil
+	}
+	op := ctx.Operation()
+	err := roleService.AuthorizeRoute(ctx.Context(), user, op.Method, op.Path, ctx.Param("id"), ctx.Param("projectId"))
+	if err == nil {
+		return 0, nil
+	}
+	var forbiddenErr *models.ForbiddenError
+	if errors.As(err, &forbiddenErr) {
+		return http.StatusForbidden, err
+	}
+	return http.StatusInternalServerError, err
+}
+
 // NewAuthBridge creates a Huma middleware that validates JWT tokens and
-// enforces security requirements defined on operations.
-func NewAuthBridge(api huma.API, authService *services.AuthService, apiKeyService *services.ApiKeyService, cfg *config.Config) func(ctx huma.Context, next func(huma.Context)) {
+// enforces security requirements and role permissions defined on operations.
+func NewAuthBridge(api huma.API, authService *services.AuthService, apiKeyService *services.ApiKeyService, roleService *services.RoleService, cfg *config.Config) func(ctx huma.Context, next func(huma.Context)) {
+	serveAuthorized := func(ctx huma.Context, user *models.User, next func(huma.Context)) {
+		if status, err := authorizeOperation(ctx, roleService, user); err != nil {
+			_ = huma.WriteErr(api, ctx, status, err.Error())
+			return
+		}
+		newCtx := setUserInContext(ctx.Context(), user)
+		next(huma.WithContext(ctx, newCtx))
+	}
+
 	return func(ctx huma.Context, next func(huma.Context)) {
+		// The client IP honors the trusted proxies of the router, so handlers can use it to
+		// bound work done for unauthenticated clients.
+		ctx = huma.WithValue(ctx, ContextKeyClientIP, humagin.Unwrap(ctx).ClientIP())
+
 		if authService == nil {
 			next(ctx)
 			return
@@ -173,10 +234,14 @@ func NewAuthBridge(api huma.API, authService *services.AuthService, apiKeyServic
 		// If API key header is present and API key auth is allowed, prioritize it.
 		// If validation fails, do NOT fall back to Bearer auth.
 		if reqs.apiKeyAuth && ctx.Header(headerApiKey) != "" {
-			if user, ok := tryApiKeyAuth(ctx, apiKeyService); ok {
-				newCtx := setUserInContext(ctx.Context(), user)
-				ctx = huma.WithContext(ctx, newCtx)
-				next(ctx)
+			user, err := tryApiKeyAuth(ctx, apiKeyService)
+			if err == nil {
+				serveAuthorized(ctx, user, next)
+				return
+			}
+			var forbiddenErr *models.ForbiddenError
+			if errors.As(err, &forbiddenErr) {
+				_ = huma.WriteErr(api, ctx, http.StatusForbidden, forbiddenErr.Error())
 				return
 			}
 			// API key was present but invalid. Fail immediately.
@@ -186,9 +251,7 @@ func NewAuthBridge(api huma.API, authService *services.AuthService, apiKeyServic
 
 		if reqs.bearerAuth {
 			if user, ok := tryBearerAuth(ctx, authService); ok {
-				newCtx := setUserInContext(ctx.Context(), user)
-				ctx = huma.WithContext(ctx, newCtx)
-				next(ctx)
+				serveAuthorized(ctx, user, next)
 				return
 			}
 		}
@@ -27,12 +27,19 @@ type AuthOptions struct {
 }
 
 type ApiKeyValidator interface {
-	ValidateApiKey(ctx context.Context, rawKey string) (*models.User, error)
+	ValidateApiKey(ctx context.Context, rawKey string, access services.ApiKeyAccess) (*models.User, error)
+}
+
+// RouteAuthorizer checks that a user holds the permission a route requires in an
+// environment and, optionally, one of its projects.
+type RouteAuthorizer interface {
+	AuthorizeRoute(ctx context.Context, user *models.User, method, route, envID, projectID string) error
 }
 
 type AuthMiddleware struct {
 	authService     *services.AuthService
 	apiKeyValidator ApiKeyValidator
+	routeAuthorizer RouteAuthorizer
 	cfg             *config.Config
 	options         AuthOptions
 }
@@ -51,6 +58,12 @@ func (m *AuthMiddleware) WithApiKeyValidator(validator ApiKeyValidator) *AuthMid
 	return &clone
 }
 
+func (m *AuthMiddleware) WithRouteAuthorizer(authorizer RouteAuthorizer) *AuthMiddleware {
+	clone := *m
+	clone.routeAuthorizer = authorizer
+	return &clone
+}
+
 func (m *AuthMiddleware) WithAdminNotRequired() *AuthMiddleware {
 	clone := *m
 	clone.options.AdminRequired = false
@@ -109,7 +122,7 @@ func (m *AuthMiddleware) agentAuth(ctx context.Context, c *gin.Context) {
 func (m *AuthMiddleware) managerAuth(ctx context.Context, c *gin.Context) {
 	// First, check for API key in X-API-Key header
 	if apiKey := c.GetHeader(headerApiKey); apiKey != "" && m.apiKeyValidator != nil {
-		user, err := m.apiKeyValidator.ValidateApiKey(ctx, apiKey)
+		user, err := m.apiKeyValidator.ValidateApiKey(ctx, apiKey, ApiKeyAccessFromRequest(c))
 		if err == nil && user != nil {
 			isAdmin := userHasRole(user, "admin")
 			if m.options.AdminRequired && !isAdmin {
@@ -120,6 +133,9 @@ func (m *AuthMiddleware) managerAuth(ctx context.Context, c *gin.Context) {
 				c.Abort()
 				return
 			}
+			if !authorizeRoute(ctx, c, m.routeAuthorizer, user) {
+				return
+			}
 			c.Set("userID", user.ID)
 			c.Set("currentUser", user)
 			c.Set("userIsAdmin", isAdmin)
@@ -127,6 +143,15 @@ func (m *AuthMiddleware) managerAuth(ctx context.Context, c *gin.Context) {
 			c.Next()
 			return
 		}
+		var forbiddenErr *models.ForbiddenError
+		if errors.As(err, &forbiddenErr) {
+			c.JSON(http.StatusForbidden, models.APIError{
+				Code:    models.APIErrorCodeForbidden,
+				Message: forbiddenErr.Error(),
+			})
+			c.Abort()
+			return
+		}
 		// If API key validation fails, return unauthorized
 		c.JSON(http.StatusUnauthorized, models.APIError{
 			Code:    models.APIErrorCodeUnauthorized,
@@ -184,12 +209,59 @@ func (m *AuthMiddleware) managerAuth(ctx context.Context, c *gin.Context) {
 		return
 	}
 
+	if !authorizeRoute(ctx, c, m.routeAuthorizer, user) {
+		return
+	}
+
 	c.Set("userID", user.ID)
 	c.Set("currentUser", user)
 	c.Set("userIsAdmin", isAdmin)
 	c.Next()
 }
 
+// authorizeRoute checks the user's role permissions for the matched route and aborts the
+// request when they are missing.
+func authorizeRoute(ctx context.Context, c *gin.Context, authorizer RouteAuthorizer, user *models.User) bool {
+	if authorizer == nil {
+		return true
+	}
+
+	err := authorizer.AuthorizeRoute(ctx, user, c.Request.Method, c.FullPath(), c.Param("id"), c.Param("projectId"))
+	if err == nil {
+		return true
+	}
+
+	var forbiddenErr *models.ForbiddenError
+	if errors.As(err, &forbiddenErr) {
+		c.JSON(http.StatusForbidden, models.APIError{
+			Code:    models.APIErrorCodeForbidden,
+			Message: forbiddenErr.Error(),
+		})
+	} else {
+		slog.ErrorContext(ctx, "Failed to authorize route", "path", c.FullPath(), "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIError{
+			Code:    models.APIErrorCodeInternalServerError,
+			Message: "Failed to check permissions",
+		})
+	}
+	c.Abort()
+	return false
+}
+
+// ApiKeyAccessFromRequest describes the matched route of c for API key restriction checks.
+func ApiKeyAccessFromRequest(c *gin.Context) services.ApiKeyAccess {
+	access := services.ApiKeyAccess{
+		ClientIP: c.ClientIP(),
+		Method:   c.Request.Method,
+		Route:    c.FullPath(),
+	}
+	if strings.Contains(access.Route, "/environments/:id") {
+		access.EnvironmentID = c.Param("id")
+		access.ProjectID = c.Param("projectId")
+	}
+	return access
+}
+
 func isPreflight(c *gin.Context) bool {
 	return c.Request.Method == http.MethodOptions
 }
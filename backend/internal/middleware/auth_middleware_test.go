@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getarcaneapp/arcane/backend/internal/config"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/services"
+)
+
+// cidrApiKeyValidator accepts any key used from inside its network.
+type cidrApiKeyValidator struct {
+	allowed netip.Prefix
+}
+
+func (v cidrApiKeyValidator) ValidateApiKey(_ context.Context, _ string, access services.ApiKeyAccess) (*models.User, error) {
+	addr, err := netip.ParseAddr(access.ClientIP)
+	if err != nil || !v.allowed.Contains(addr) {
+		return nil, &models.ForbiddenError{Message: "API key can't be used from this address"}
+	}
+	return &models.User{BaseModel: models.BaseModel{ID: "u1"}, Roles: models.StringSlice{"admin"}}, nil
+}
+
+func TestApiKeyAuth_IgnoresForwardedForFromUntrustedPeers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(trustedProxies string) *gin.Engine {
+		cfg := &config.Config{TrustedProxies: trustedProxies}
+		router := gin.New()
+		require.NoError(t, router.SetTrustedProxies(cfg.TrustedProxyList()))
+		auth := NewAuthMiddleware(nil, cfg).WithApiKeyValidator(cidrApiKeyValidator{allowed: netip.MustParsePrefix("10.0.0.0/8")})
+		router.GET("/api/things", auth.Add(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		return router
+	}
+	request := func(router *gin.Engine, remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/things", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-API-Key", "arc_key")
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	direct := newRouter("")
+	assert.Equal(t, http.StatusNoContent, request(direct, "10.1.2.3:5000", ""))
+	assert.Equal(t, http.StatusForbidden, request(direct, "203.0.113.7:5000", ""))
+	// A client outside the network can't claim an allowed address.
+	assert.Equal(t, http.StatusForbidden, request(direct, "203.0.113.7:5000", "10.1.2.3"))
+
+	proxied := newRouter("192.0.2.1, 192.0.2.0/28")
+	assert.Equal(t, http.StatusNoContent, request(proxied, "192.0.2.1:5000", "10.1.2.3"))
+	assert.Equal(t, http.StatusForbidden, request(proxied, "192.0.2.1:5000", "203.0.113.7"))
+	assert.Equal(t, http.StatusForbidden, request(proxied, "203.0.113.7:5000", "10.1.2.3"))
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/services"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/edge"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/remenv"
@@ -32,6 +33,7 @@ const (
 	managementEndpointSettings       = "/settings"
 	managementEndpointJobSchedules   = "/job-schedules"
 	managementEndpointJobs           = "/jobs"
+	managementEndpointPermissions    = "/permissions"
 
 	errEnvironmentNotFound      = "Environment not found"
 	errEnvironmentDisabled      = "Environment is disabled"
@@ -58,6 +60,7 @@ type EnvironmentMiddleware struct {
 	paramName     string
 	resolver      EnvResolver
 	authValidator AuthValidator
+	authorizer    RouteAuthorizer
 	envService    *services.EnvironmentService
 	httpClient    *http.Client
 }
@@ -68,12 +71,14 @@ type EnvironmentMiddleware struct {
 // - resolver: function to resolve environment ID to connection details
 // - envService: environment service for additional lookups
 // - authValidator: function to validate authentication before proxying (required for security)
-func NewEnvProxyMiddlewareWithParam(localID, paramName string, resolver EnvResolver, envService *services.EnvironmentService, authValidator AuthValidator) gin.HandlerFunc {
+// - authorizer: checks the role permissions of the user the authValidator stored as "currentUser"
+func NewEnvProxyMiddlewareWithParam(localID, paramName string, resolver EnvResolver, envService *services.EnvironmentService, authValidator AuthValidator, authorizer RouteAuthorizer) gin.HandlerFunc {
 	m := &EnvironmentMiddleware{
 		localID:       localID,
 		paramName:     paramName,
 		resolver:      resolver,
 		authValidator: authValidator,
+		authorizer:    authorizer,
 		envService:    envService,
 		httpClient:    &http.Client{Timeout: proxyTimeout},
 	}
@@ -111,6 +116,22 @@ func (m *EnvironmentMiddleware) Handle(c *gin.Context) {
 		return
 	}
 
+	// The agent treats proxied requests as admin, so role permissions are checked here.
+	if m.authorizer != nil {
+		user, _ := c.Get("currentUser")
+		u, ok := user.(*models.User)
+		if !ok || !authorizeRoute(c.Request.Context(), c, m.authorizer, u) {
+			if !c.IsAborted() {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"success": false,
+					"data":    gin.H{"error": errUnauthorized},
+				})
+				c.Abort()
+			}
+			return
+		}
+	}
+
 	// Resolve remote environment
 	apiURL, accessToken, enabled, err := m.resolver(c.Request.Context(), envID)
 	if err != nil || apiURL == "" {
@@ -194,6 +215,7 @@ func (m *EnvironmentMiddleware) hasResourcePath(c *gin.Context, envID string) bo
 		managementEndpointSettings,
 		managementEndpointJobSchedules,
 		managementEndpointJobs,
+		managementEndpointPermissions,
 	}
 
 	for _, endpoint := range managementEndpoints {
@@ -5,17 +5,26 @@ import (
 )
 
 type ApiKey struct {
-	Name          string     `json:"name" gorm:"column:name;not null" sortable:"true"`
-	Description   *string    `json:"description,omitempty" gorm:"column:description"`
-	KeyHash       string     `json:"-" gorm:"column:key_hash;not null"`
-	KeyPrefix     string     `json:"keyPrefix" gorm:"column:key_prefix;not null"`
-	UserID        string     `json:"userId" gorm:"column:user_id;not null"`
-	EnvironmentID *string    `json:"environmentId,omitempty" gorm:"column:environment_id"`
-	ExpiresAt     *time.Time `json:"expiresAt,omitempty" gorm:"column:expires_at" sortable:"true"`
-	LastUsedAt    *time.Time `json:"lastUsedAt,omitempty" gorm:"column:last_used_at" sortable:"true"`
+	Name                string      `json:"name" gorm:"column:name;not null" sortable:"true"`
+	Description         *string     `json:"description,omitempty" gorm:"column:description"`
+	KeyHash             string      `json:"-" gorm:"column:key_hash;not null"`
+	KeyPrefix           string      `json:"keyPrefix" gorm:"column:key_prefix;not null"`
+	UserID              string      `json:"userId" gorm:"column:user_id;not null"`
+	EnvironmentID       *string     `json:"environmentId,omitempty" gorm:"column:environment_id"`
+	Scopes              StringSlice `json:"scopes,omitempty" gorm:"column:scopes;type:text"`                            // permissions the key is limited to
+	AllowedEnvironments StringSlice `json:"allowedEnvironments,omitempty" gorm:"column:allowed_environments;type:text"` // environments the key can reach
+	AllowedProjects     StringSlice `json:"allowedProjects,omitempty" gorm:"column:allowed_projects;type:text"`         // projects the key can reach
+	AllowedCIDRs        StringSlice `json:"allowedCidrs,omitempty" gorm:"column:allowed_cidrs;type:text"`               // networks requests must come from
+	ExpiresAt           *time.Time  `json:"expiresAt,omitempty" gorm:"column:expires_at" sortable:"true"`
+	LastUsedAt          *time.Time  `json:"lastUsedAt,omitempty" gorm:"column:last_used_at" sortable:"true"`
 	BaseModel
 }
 
+// Restricted reports whether the key is limited to scopes, environments or projects.
+func (k *ApiKey) Restricted() bool {
+	return len(k.Scopes) > 0 || len(k.AllowedEnvironments) > 0 || len(k.AllowedProjects) > 0
+}
+
 func (ApiKey) TableName() string {
 	return "api_keys"
 }
@@ -13,6 +13,9 @@ const (
 	AutoUpdateStatusCompleted AutoUpdateStatus = "completed"
 	AutoUpdateStatusFailed    AutoUpdateStatus = "failed"
 	AutoUpdateStatusSkipped   AutoUpdateStatus = "skipped"
+	// AutoUpdateStatusRolledBack means the updated container failed its health check and
+	// was recreated from the previous image.
+	AutoUpdateStatusRolledBack AutoUpdateStatus = "rolled_back"
 )
 
 type AutoUpdateRecord struct {
@@ -100,6 +100,14 @@ func (e *ConflictError) Error() string {
 	return e.Message
 }
 
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
+
 type DockerAPIError struct {
 	Message    string
 	StatusCode int
@@ -139,6 +147,10 @@ func ToAPIError(err error) *APIError {
 	if errors.As(err, &conflictErr) {
 		return NewConflictError(conflictErr.Message)
 	}
+	var forbiddenErr *ForbiddenError
+	if errors.As(err, &forbiddenErr) {
+		return NewAPIError(forbiddenErr.Message, APIErrorCodeForbidden, http.StatusForbidden)
+	}
 	var validationErr *ValidationError
 	if errors.As(err, &validationErr) {
 		return NewValidationError(validationErr.Message, map[string]string{"field": validationErr.Field})
@@ -24,14 +24,18 @@ const (
 	EventTypeImageScan              EventType = "image.scan"
 	EventTypeImageError             EventType = "image.error"
 	EventTypeImageVulnerabilityScan EventType = "image.vulnerability_scan"
-
-	EventTypeProjectDeploy EventType = "project.deploy"
-	EventTypeProjectDelete EventType = "project.delete"
-	EventTypeProjectStart  EventType = "project.start"
-	EventTypeProjectStop   EventType = "project.stop"
-	EventTypeProjectCreate EventType = "project.create"
-	EventTypeProjectUpdate EventType = "project.update"
-	EventTypeProjectError  EventType = "project.error"
+	EventTypeImageBuild             EventType = "image.build"
+	EventTypeImageTag               EventType = "image.tag"
+	EventTypeImagePush              EventType = "image.push"
+
+	EventTypeProjectDeploy   EventType = "project.deploy"
+	EventTypeProjectDelete   EventType = "project.delete"
+	EventTypeProjectStart    EventType = "project.start"
+	EventTypeProjectStop     EventType = "project.stop"
+	EventTypeProjectCreate   EventType = "project.create"
+	EventTypeProjectUpdate   EventType = "project.update"
+	EventTypeProjectError    EventType = "project.error"
+	EventTypeProjectRollback EventType = "project.rollback"
 
 	EventTypeGitRepositoryCreate EventType = "git.repository.create"
 	EventTypeGitRepositoryUpdate EventType = "git.repository.update"
@@ -44,6 +48,11 @@ const (
 	EventTypeGitSyncDelete EventType = "git.sync.delete"
 	EventTypeGitSyncRun    EventType = "git.sync.run"
 	EventTypeGitSyncError  EventType = "git.sync.error"
+	EventTypeGitSyncDrift  EventType = "git.sync.drift"
+
+	EventTypeUpdateRolloutStage    EventType = "update.rollout.stage"
+	EventTypeUpdateRolloutComplete EventType = "update.rollout.complete"
+	EventTypeUpdateRolloutError    EventType = "update.rollout.error"
 
 	EventTypeVolumeCreate EventType = "volume.create"
 	EventTypeVolumeDelete EventType = "volume.delete"
@@ -5,22 +5,34 @@ import (
 )
 
 type GitOpsSync struct {
-	Name           string         `json:"name" sortable:"true" search:"sync,gitops,automation,deploy,deployment,continuous"`
-	EnvironmentID  string         `json:"environmentId" sortable:"true"`
-	Environment    *Environment   `json:"environment,omitempty" gorm:"foreignKey:EnvironmentID"`
-	RepositoryID   string         `json:"repositoryId" sortable:"true"`
-	Repository     *GitRepository `json:"repository,omitempty" gorm:"foreignKey:RepositoryID"`
-	Branch         string         `json:"branch" sortable:"true" search:"branch,main,master,develop,feature,release"`
-	ComposePath    string         `json:"composePath" sortable:"true" search:"compose,docker-compose,path,file,yaml,yml"`
-	ProjectName    string         `json:"projectName" sortable:"true" search:"project,name,stack,application,service"` // Name of project to create/update
-	ProjectID      *string        `json:"projectId,omitempty" sortable:"true"`                                         // Set after project is created
-	Project        *Project       `json:"project,omitempty" gorm:"foreignKey:ProjectID"`
-	AutoSync       bool           `json:"autoSync" sortable:"true" search:"auto,automatic,sync,continuous,scheduled"`
-	SyncInterval   int            `json:"syncInterval" sortable:"true" search:"interval,frequency,schedule,cron,minutes"` // in minutes
-	LastSyncAt     *time.Time     `json:"lastSyncAt,omitempty" sortable:"true"`
-	LastSyncStatus *string        `json:"lastSyncStatus,omitempty" search:"status,success,failed,pending,error"`
-	LastSyncError  *string        `json:"lastSyncError,omitempty"`
-	LastSyncCommit *string        `json:"lastSyncCommit,omitempty" search:"commit,hash,sha,revision"`
+	Name              string         `json:"name" sortable:"true" search:"sync,gitops,automation,deploy,deployment,continuous"`
+	EnvironmentID     string         `json:"environmentId" sortable:"true"`
+	Environment       *Environment   `json:"environment,omitempty" gorm:"foreignKey:EnvironmentID"`
+	RepositoryID      string         `json:"repositoryId" sortable:"true"`
+	Repository        *GitRepository `json:"repository,omitempty" gorm:"foreignKey:RepositoryID"`
+	Branch            string         `json:"branch" sortable:"true" search:"branch,main,master,develop,feature,release"`
+	RefType           string         `json:"refType" gorm:"default:branch"` // branch, tag, semver or commit
+	Ref               string         `json:"ref"`                           // tag pattern, semver constraint or commit SHA for non-branch ref types
+	ComposePath       string         `json:"composePath" sortable:"true" search:"compose,docker-compose,path,file,yaml,yml"`
+	ProjectName       string         `json:"projectName" sortable:"true" search:"project,name,stack,application,service"` // Name of project to create/update
+	ProjectID         *string        `json:"projectId,omitempty" sortable:"true"`                                         // Set after project is created
+	Project           *Project       `json:"project,omitempty" gorm:"foreignKey:ProjectID"`
+	AutoSync          bool           `json:"autoSync" sortable:"true" search:"auto,automatic,sync,continuous,scheduled"`
+	SyncInterval      int            `json:"syncInterval" sortable:"true" search:"interval,frequency,schedule,cron,minutes"` // in minutes
+	LastSyncAt        *time.Time     `json:"lastSyncAt,omitempty" sortable:"true"`
+	LastSyncStatus    *string        `json:"lastSyncStatus,omitempty" search:"status,success,failed,pending,error"`
+	LastSyncError     *string        `json:"lastSyncError,omitempty"`
+	LastSyncCommit    *string        `json:"lastSyncCommit,omitempty" search:"commit,hash,sha,revision"`
+	LastSyncRef       *string        `json:"lastSyncRef,omitempty"`                  // branch or tag the last sync resolved to
+	LastSyncHash      *string        `json:"lastSyncHash,omitempty"`                 // hash of the files applied by the last sync
+	SyncedFiles       StringSlice    `json:"syncedFiles,omitempty" gorm:"type:text"` // files besides compose/.env written to the project
+	DriftCheckedAt    *time.Time     `json:"driftCheckedAt,omitempty"`
+	DriftReasons      StringSlice    `json:"driftReasons,omitempty" gorm:"type:text"` // why the project no longer matches the sync, set while drifted
+	WebhookEnabled    bool           `json:"webhookEnabled" search:"webhook,push,trigger,hook"`
+	BuildImages       bool           `json:"buildImages" search:"build,dockerfile,buildkit,image"`
+	WebhookSecret     string         `json:"-"`                                            // encrypted
+	SopsAgeKey        string         `json:"-"`                                            // encrypted
+	SopsAgeRecipients StringSlice    `json:"sopsAgeRecipients,omitempty" gorm:"type:text"` // public keys of SopsAgeKey
 	BaseModel
 }
 
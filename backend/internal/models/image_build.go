@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// ImageBuild records an image build started manually, by a deploy or by a GitOps sync.
+type ImageBuild struct {
+	Source       string      `json:"source" sortable:"true"` // project or git
+	ProjectID    *string     `json:"projectId,omitempty" sortable:"true"`
+	RepositoryID *string     `json:"repositoryId,omitempty" sortable:"true"`
+	GitOpsSyncID *string     `json:"gitOpsSyncId,omitempty"`
+	Ref          string      `json:"ref,omitempty"`
+	Commit       *string     `json:"commit,omitempty"`
+	ContextPath  string      `json:"contextPath,omitempty"`
+	Services     StringSlice `json:"services,omitempty" gorm:"type:text"`
+	Tags         StringSlice `json:"tags,omitempty" gorm:"type:text" search:"tag,image,build"`
+	Target       string      `json:"target,omitempty"`
+	Trigger      string      `json:"trigger" sortable:"true"` // manual, deploy or gitops
+	Status       string      `json:"status" sortable:"true" search:"status,running,succeeded,failed,canceled"`
+	Error        *string     `json:"error,omitempty"`
+	Log          string      `json:"log,omitempty"` // plain text output, truncated to its tail
+	UserID       *string     `json:"userId,omitempty"`
+	Username     *string     `json:"username,omitempty"`
+	StartedAt    time.Time   `json:"startedAt" sortable:"true"`
+	FinishedAt   *time.Time  `json:"finishedAt,omitempty" sortable:"true"`
+	BaseModel
+}
+
+func (ImageBuild) TableName() string {
+	return "image_builds"
+}
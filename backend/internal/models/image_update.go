@@ -25,6 +25,13 @@ type ImageUpdateRecord struct {
 
 	NotificationSent bool `json:"notificationSent" gorm:"column:notification_sent;default:false"`
 
+	// Origin of the latest image from its OCI annotations, and the release notes found for it.
+	ReleaseSource   *string `json:"releaseSource,omitempty" gorm:"column:release_source"`
+	ReleaseVersion  *string `json:"releaseVersion,omitempty" gorm:"column:release_version"`
+	ReleaseRevision *string `json:"releaseRevision,omitempty" gorm:"column:release_revision"`
+	ReleaseNotesURL *string `json:"releaseNotesUrl,omitempty" gorm:"column:release_notes_url"`
+	ReleaseNotes    *string `json:"releaseNotes,omitempty" gorm:"column:release_notes"`
+
 	BaseModel
 }
 
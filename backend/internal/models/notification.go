@@ -44,6 +44,7 @@ const (
 	NotificationEventContainerUpdate    NotificationEventType = "container_update"
 	NotificationEventVulnerabilityFound NotificationEventType = "vulnerability_found"
 	NotificationEventPruneReport        NotificationEventType = "prune_report"
+	NotificationEventGitOpsDrift        NotificationEventType = "gitops_drift"
 )
 
 type EmailTLSMode string
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Passkey is a WebAuthn credential a local user signs in with instead of a password.
+type Passkey struct {
+	UserID         string      `json:"userId" gorm:"column:user_id;not null;index"`
+	Name           string      `json:"name" gorm:"column:name;not null"`
+	CredentialID   string      `json:"credentialId" gorm:"column:credential_id;not null;uniqueIndex"` // base64url
+	PublicKey      []byte      `json:"-" gorm:"column:public_key;not null"`                           // COSE_Key
+	SignCount      int64       `json:"signCount" gorm:"column:sign_count;not null;default:0"`
+	Transports     StringSlice `json:"transports,omitempty" gorm:"column:transports;type:text"`
+	AAGUID         string      `json:"aaguid,omitempty" gorm:"column:aaguid"`
+	BackupEligible bool        `json:"backupEligible" gorm:"column:backup_eligible;not null;default:false"`
+	BackedUp       bool        `json:"backedUp" gorm:"column:backed_up;not null;default:false"`
+	LastUsedAt     *time.Time  `json:"lastUsedAt,omitempty" gorm:"column:last_used_at"`
+	BaseModel
+}
+
+func (Passkey) TableName() string {
+	return "user_passkeys"
+}
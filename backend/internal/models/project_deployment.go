@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"github.com/getarcaneapp/arcane/types/project"
+)
+
+type ProjectDeploymentStatus string
+
+const (
+	ProjectDeploymentStatusSucceeded ProjectDeploymentStatus = "succeeded"
+	ProjectDeploymentStatusFailed    ProjectDeploymentStatus = "failed"
+)
+
+const (
+	ProjectDeploymentActionDeploy   = "deploy"
+	ProjectDeploymentActionRollback = "rollback"
+)
+
+// ProjectDeployment is a snapshot of the files and images that were used for a
+// single deploy of a project. Snapshots are what rollbacks restore from.
+type ProjectDeployment struct {
+	ProjectID      string                  `json:"projectId" gorm:"column:project_id;index"`
+	Revision       int                     `json:"revision" gorm:"column:revision"`
+	Action         string                  `json:"action" gorm:"column:action"`
+	Status         ProjectDeploymentStatus `json:"status" gorm:"column:status"`
+	Error          *string                 `json:"error,omitempty" gorm:"column:error"`
+	ComposeContent string                  `json:"composeContent" gorm:"column:compose_content"`
+	EnvContent     *string                 `json:"envContent,omitempty" gorm:"column:env_content"`
+	// IncludeFiles maps include paths (relative to the project) to their content.
+	IncludeFiles JSON `json:"includeFiles,omitempty" gorm:"column:include_files;type:text"`
+	// ImageDigests maps image references from the compose file to the repo digest they resolved to.
+	ImageDigests       JSON    `json:"imageDigests,omitempty" gorm:"column:image_digests;type:text"`
+	SourceDeploymentID *string `json:"sourceDeploymentId,omitempty" gorm:"column:source_deployment_id"`
+	UserID             *string `json:"userId,omitempty" gorm:"column:user_id"`
+	Username           *string `json:"username,omitempty" gorm:"column:username"`
+
+	BaseModel
+}
+
+func (ProjectDeployment) TableName() string {
+	return "project_deployments"
+}
+
+func (d *ProjectDeployment) ToDTO() project.Deployment {
+	return project.Deployment{
+		ID:                 d.ID,
+		ProjectID:          d.ProjectID,
+		Revision:           d.Revision,
+		Action:             d.Action,
+		Status:             string(d.Status),
+		Error:              d.Error,
+		ComposeContent:     d.ComposeContent,
+		EnvContent:         d.EnvContent,
+		IncludeFiles:       stringMapFromJSON(d.IncludeFiles),
+		ImageDigests:       stringMapFromJSON(d.ImageDigests),
+		SourceDeploymentID: d.SourceDeploymentID,
+		Username:           d.Username,
+		CreatedAt:          d.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func stringMapFromJSON(j JSON) map[string]string {
+	if len(j) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(j))
+	for k, v := range j {
+		if str, ok := v.(string); ok {
+			out[k] = str
+		}
+	}
+	return out
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RecoveryCode is a one-time code that replaces a TOTP code when the authenticator is lost.
+type RecoveryCode struct {
+	UserID   string     `json:"userId" gorm:"column:user_id;not null;index"`
+	CodeHash string     `json:"-" gorm:"column:code_hash;not null"`
+	UsedAt   *time.Time `json:"usedAt,omitempty" gorm:"column:used_at"`
+	BaseModel
+}
+
+func (RecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
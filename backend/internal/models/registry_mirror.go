@@ -0,0 +1,85 @@
+package models
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	ref "go.podman.io/image/v5/docker/reference"
+)
+
+// RegistryMirror redirects image pulls for an upstream registry to a mirror or pull-through
+// cache. Mirrors are stored per environment, each environment pulls through its own.
+type RegistryMirror struct {
+	Registry           string     `json:"registry" sortable:"true" search:"registry,mirror,upstream,docker.io,ghcr"`
+	MirrorURL          string     `json:"mirrorUrl" sortable:"true" search:"mirror,proxy,cache,pull-through"`
+	RepositoryPattern  string     `json:"repositoryPattern"` // glob on the upstream repository path, empty matches all
+	Description        *string    `json:"description,omitempty"`
+	Priority           int        `json:"priority" sortable:"true"`
+	Enabled            bool       `json:"enabled" sortable:"true"`
+	FallbackToUpstream bool       `json:"fallbackToUpstream"`
+	LastUsedAt         *time.Time `json:"lastUsedAt,omitempty" sortable:"true"`
+	LastError          *string    `json:"lastError,omitempty"`
+	BaseModel
+}
+
+func (RegistryMirror) TableName() string {
+	return "registry_mirrors"
+}
+
+// NormalizeMirrorRegistry reduces a registry URL to the host used to match image references.
+// All Docker Hub aliases become "docker.io".
+func NormalizeMirrorRegistry(registry string) string {
+	r := strings.ToLower(strings.TrimSpace(registry))
+	r = strings.TrimPrefix(r, "https://")
+	r = strings.TrimPrefix(r, "http://")
+	r, _, _ = strings.Cut(r, "/")
+	switch r {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return "docker.io"
+	}
+	return r
+}
+
+// Rewrite returns the reference imageRef should be pulled from when it goes through this
+// mirror. The upstream repository path is appended to the mirror URL, so a mirror URL with a
+// path acts as a prefix (e.g. harbor.local/dockerhub-proxy). Digest-only references are not
+// rewritten: the image would only be known under the mirror's repository afterwards.
+func (m *RegistryMirror) Rewrite(imageRef string) (string, bool) {
+	if !m.Enabled {
+		return "", false
+	}
+
+	named, err := ref.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return "", false
+	}
+	if ref.Domain(named) != NormalizeMirrorRegistry(m.Registry) {
+		return "", false
+	}
+
+	repoPath := ref.Path(named)
+	if pattern := strings.TrimSpace(m.RepositoryPattern); pattern != "" {
+		if ok, err := path.Match(pattern, repoPath); err != nil || !ok {
+			return "", false
+		}
+	}
+
+	tagged, ok := ref.TagNameOnly(named).(ref.NamedTagged)
+	if !ok {
+		return "", false
+	}
+	if _, pinned := named.(ref.Digested); pinned {
+		return "", false
+	}
+
+	base := strings.TrimSpace(m.MirrorURL)
+	base = strings.TrimPrefix(base, "https://")
+	base = strings.TrimPrefix(base, "http://")
+	base = strings.TrimSuffix(base, "/")
+	if base == "" {
+		return "", false
+	}
+
+	return base + "/" + repoPath + ":" + tagged.Tag(), true
+}
@@ -0,0 +1,27 @@
+package models
+
+// Role is a custom role. The built-in roles are defined in code and have no rows.
+type Role struct {
+	Name        string      `json:"name" sortable:"true" search:"role,name"`
+	Description *string     `json:"description,omitempty"`
+	Permissions StringSlice `json:"permissions" gorm:"type:text"`
+	BaseModel
+}
+
+func (Role) TableName() string {
+	return "roles"
+}
+
+// RoleAssignment grants a role to a user in one environment, or in all environments when
+// EnvironmentID is nil. A ProjectID narrows it to one project of the environment.
+type RoleAssignment struct {
+	UserID        string  `json:"userId" gorm:"column:user_id"`
+	Role          string  `json:"role"`
+	EnvironmentID *string `json:"environmentId,omitempty" gorm:"column:environment_id"`
+	ProjectID     *string `json:"projectId,omitempty" gorm:"column:project_id"`
+	BaseModel
+}
+
+func (RoleAssignment) TableName() string {
+	return "role_assignments"
+}
@@ -56,6 +56,11 @@ type Settings struct {
 	AutoUpdate                   SettingVariable `key:"autoUpdate" meta:"label=Auto Update;type=boolean;keywords=auto,update,automatic,upgrade,refresh,restart,deploy;category=internal;description=Automatically update containers when new images are available"`
 	AutoUpdateInterval           SettingVariable `key:"autoUpdateInterval" meta:"label=Auto Update Interval;type=cron;keywords=auto,update,interval,frequency,schedule,automatic,timing;category=internal;description=How often to check for automatic updates (cron expression)"`
 	AutoUpdateExcludedContainers SettingVariable `key:"autoUpdateExcludedContainers" meta:"label=Excluded Containers;type=text;keywords=exclude,containers,ignore,skip;category=internal;description=Comma-separated list of containers to exclude from auto-update"`
+	AutoUpdateHealthTimeout      SettingVariable `key:"autoUpdateHealthTimeout" meta:"label=Update Health Timeout;type=number;keywords=health,healthcheck,timeout,rollback,revert,seconds;category=internal;description=Seconds to wait for an updated container to become healthy before rolling it back (0 disables rollback)"`
+	AutoUpdateBumpComposeTags    SettingVariable `key:"autoUpdateBumpComposeTags" meta:"label=Update Compose Tags;type=boolean;keywords=compose,tag,version,bump,pin,project,file;category=internal;description=Write new version tags applied by auto-update back into project compose files"`
+	ReleaseNotesGitLabHosts      SettingVariable `key:"releaseNotesGitLabHosts" meta:"label=Release Notes GitLab Hosts;type=text;keywords=release,notes,changelog,gitlab,self-hosted,host;category=internal;description=Comma-separated self-hosted GitLab hosts release notes are looked up on, besides github.com and gitlab.com"`
+	ImageTagUpdatePolicy         SettingVariable `key:"imageTagUpdatePolicy" meta:"label=Tag Update Policy;type=select;keywords=tag,version,semver,patch,minor,major,policy,pinned;category=internal;description=Which newer version tags of pinned images count as updates (none, patch, minor or major)"`
+	AutoUpdateMinUptime          SettingVariable `key:"autoUpdateMinUptime" meta:"label=Update Minimum Uptime;type=number;keywords=uptime,running,rollback,revert,seconds;category=internal;description=Seconds an updated container without a healthcheck must keep running before the update counts as successful"`
 	PollingEnabled               SettingVariable `key:"pollingEnabled" meta:"label=Enable Polling;type=boolean;keywords=polling,check,monitor,watch,scan,detection,automatic;category=internal;description=Enable automatic checking for image updates"`
 	PollingInterval              SettingVariable `key:"pollingInterval" meta:"label=Polling Interval;type=cron;keywords=interval,frequency,schedule,time,minutes,period,delay;category=internal;description=How often to check for image updates (cron expression)"`
 	EventCleanupInterval         SettingVariable `key:"eventCleanupInterval" meta:"label=Event Cleanup Interval;type=cron;keywords=events,cleanup,retention,interval,frequency,schedule,history,logs,jobs;description=How often to delete old events (cron expression)"`
@@ -71,11 +76,13 @@ type Settings struct {
 	ScheduledPruneBuildCache     SettingVariable `key:"scheduledPruneBuildCache" meta:"label=Scheduled Prune Build Cache;type=boolean;keywords=prune,build cache,cleanup,maintenance;category=internal;description=Remove Docker build cache during scheduled prune"`
 	MaxImageUploadSize           SettingVariable `key:"maxImageUploadSize" meta:"label=Max Image Upload Size;type=number;keywords=upload,size,limit,maximum,image,tar,file,megabytes,mb,storage;category=internal;description=Maximum size in MB for image archive uploads (default: 500)"`
 	DockerHost                   SettingVariable `key:"dockerHost,public,envOverride" meta:"label=Docker Host;type=text;keywords=docker,host,daemon,socket,unix,remote;category=internal;description=URI for Docker daemon"`
+	DockerConfigPath             SettingVariable `key:"dockerConfigPath,envOverride" meta:"label=Docker Config Path;type=text;keywords=docker,config,credentials,auth,registry,login,credsStore,credHelpers,helper;category=internal;description=Path to a docker config.json whose auths and credential helpers are used for registry authentication (empty disables)"`
 
 	// Security category
 	AuthLocalEnabled                SettingVariable `key:"authLocalEnabled,public" meta:"label=Local Authentication;type=boolean;keywords=local,auth,authentication,username,password,login,credentials;category=security;description=Enable local username/password authentication" catmeta:"id=security;title=Security;icon=shield;url=/settings/security;description=Manage authentication and security settings"`
 	AuthSessionTimeout              SettingVariable `key:"authSessionTimeout" meta:"label=Session Timeout;type=number;keywords=session,timeout,expire,duration,lifetime,minutes,logout;category=security;description=How long user sessions remain active"`
 	AuthPasswordPolicy              SettingVariable `key:"authPasswordPolicy" meta:"label=Password Policy;type=select;keywords=password,policy,strength,complexity,requirements,security,rules;category=security;description=Set password strength requirements"`
+	AuthRequireTwoFactor            SettingVariable `key:"authRequireTwoFactor,public" meta:"label=Require Two-Factor Authentication;type=boolean;keywords=2fa,mfa,totp,two,factor,authenticator,otp,recovery,security;category=security;description=Require every local account to sign in with an authenticator app code"`
 	VulnerabilityScanEnabled        SettingVariable `key:"vulnerabilityScanEnabled" meta:"label=Scheduled Vulnerability Scan;type=boolean;keywords=vulnerability,scan,security,trivy,schedule,automatic,cve;category=security;description=Enable scheduled vulnerability scanning of all Docker images"`
 	VulnerabilityScanInterval       SettingVariable `key:"vulnerabilityScanInterval" meta:"label=Vulnerability Scan Interval;type=cron;keywords=vulnerability,scan,interval,schedule,frequency,trivy,cve;category=security;description=How often to run scheduled vulnerability scans (cron expression)"`
 	TrivyImage                      SettingVariable `key:"trivyImage,envOverride" meta:"label=Trivy Image;type=text;keywords=trivy,scanner,vulnerability,security,image;category=security;description=Override the Trivy image used for vulnerability scans"`
@@ -99,6 +106,20 @@ type Settings struct {
 	OidcMergeAccounts               SettingVariable `key:"oidcMergeAccounts,public,envOverride" meta:"label=OIDC Account Merging;type=boolean;keywords=oidc,merge,link,accounts,email,match,existing,users,combine;category=security;description=Allow OIDC logins to merge with existing accounts by email"`
 	OidcProviderName                SettingVariable `key:"oidcProviderName,public,envOverride" meta:"label=OIDC Provider Name;type=text;keywords=oidc,provider,name,display,label,sso;category=security;description=Custom name for the OIDC provider (e.g., Authentik, Keycloak)"`
 	OidcProviderLogoUrl             SettingVariable `key:"oidcProviderLogoUrl,public,envOverride" meta:"label=OIDC Provider Logo URL;type=text;keywords=oidc,provider,logo,url,image,icon,sso;category=security;description=Custom logo URL for the OIDC provider"`
+	LdapEnabled                     SettingVariable `key:"ldapEnabled,public,envOverride" meta:"label=LDAP Authentication;type=boolean;keywords=ldap,directory,active,openldap,glauth,bind,external,provider;category=security;description=Enable sign-in with LDAP directory accounts"`
+	LdapUrl                         SettingVariable `key:"ldapUrl,envOverride" meta:"label=LDAP URL;type=text;keywords=ldap,ldaps,url,server,host,directory;category=security;description=LDAP server URL (ldap:// or ldaps://)"`
+	LdapStartTls                    SettingVariable `key:"ldapStartTls,envOverride" meta:"label=LDAP StartTLS;type=boolean;keywords=ldap,starttls,tls,encryption,secure;category=security;description=Upgrade ldap:// connections with StartTLS"`
+	LdapSkipTlsVerify               SettingVariable `key:"ldapSkipTlsVerify,envOverride" meta:"label=LDAP Skip TLS Verify;type=boolean;keywords=ldap,tls,verify,skip,insecure;category=security;description=Skip TLS certificate verification for the LDAP server"`
+	LdapBindDn                      SettingVariable `key:"ldapBindDn,envOverride" meta:"label=LDAP Bind DN;type=text;keywords=ldap,bind,dn,service,account,user;category=security;description=DN of the service account used to search for users"`
+	LdapBindPassword                SettingVariable `key:"ldapBindPassword,sensitive,envOverride" meta:"label=LDAP Bind Password;type=password;keywords=ldap,bind,password,service,account,secret;category=security;description=Password of the LDAP service account"`
+	LdapBaseDn                      SettingVariable `key:"ldapBaseDn,envOverride" meta:"label=LDAP Base DN;type=text;keywords=ldap,base,dn,search,root,tree;category=security;description=Base DN to search for users and groups"`
+	LdapUserFilter                  SettingVariable `key:"ldapUserFilter,envOverride" meta:"label=LDAP User Filter;type=text;keywords=ldap,user,filter,search,query,uid;category=security;description=Filter to find a user, with {username} replaced with the escaped username"`
+	LdapUsernameAttribute           SettingVariable `key:"ldapUsernameAttribute,envOverride" meta:"label=LDAP Username Attribute;type=text;keywords=ldap,username,attribute,uid,samaccountname;category=security;description=Attribute holding the username"`
+	LdapEmailAttribute              SettingVariable `key:"ldapEmailAttribute,envOverride" meta:"label=LDAP Email Attribute;type=text;keywords=ldap,email,mail,attribute;category=security;description=Attribute holding the email address"`
+	LdapDisplayNameAttribute        SettingVariable `key:"ldapDisplayNameAttribute,envOverride" meta:"label=LDAP Display Name Attribute;type=text;keywords=ldap,display,name,cn,attribute;category=security;description=Attribute holding the display name"`
+	LdapGroupAttribute              SettingVariable `key:"ldapGroupAttribute,envOverride" meta:"label=LDAP Group Attribute;type=text;keywords=ldap,group,memberof,attribute,membership;category=security;description=User attribute listing group DNs (e.g., memberOf)"`
+	LdapGroupFilter                 SettingVariable `key:"ldapGroupFilter,envOverride" meta:"label=LDAP Group Filter;type=text;keywords=ldap,group,filter,search,member,uniquemember;category=security;description=Optional filter to find the groups of a user, with {dn} and {username} replaced by escaped values"`
+	LdapGroupRoleMapping            SettingVariable `key:"ldapGroupRoleMapping,envOverride" meta:"label=LDAP Group Role Mapping;type=textarea;keywords=ldap,group,role,mapping,admin,permissions;category=security;description=JSON object mapping group DNs or names to a role or a list of roles"`
 
 	// Appearance category
 	MobileNavigationMode       SettingVariable `key:"mobileNavigationMode,public,local" meta:"label=Mobile Navigation Mode;type=select;keywords=mode,style,type,floating,docked,position,layout,design,appearance,bottom;category=appearance;description=Choose between floating or docked navigation on mobile" catmeta:"id=appearance;title=Appearance;icon=appearance;url=/settings/appearance;description=Customize navigation, theme, and interface behavior"`
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+type UpdateRolloutStatus string
+
+const (
+	UpdateRolloutStatusIdle      UpdateRolloutStatus = "idle"
+	UpdateRolloutStatusRunning   UpdateRolloutStatus = "running"
+	UpdateRolloutStatusSoaking   UpdateRolloutStatus = "soaking"
+	UpdateRolloutStatusCompleted UpdateRolloutStatus = "completed"
+	UpdateRolloutStatusFailed    UpdateRolloutStatus = "failed"
+	UpdateRolloutStatusCancelled UpdateRolloutStatus = "cancelled"
+)
+
+// UpdateRollout promotes image updates through groups of environments, one stage at a time.
+type UpdateRollout struct {
+	Name           string              `json:"name" sortable:"true" search:"rollout,staged,canary,promotion,update"`
+	Stages         RolloutStages       `json:"stages" gorm:"type:text"`
+	Status         UpdateRolloutStatus `json:"status" sortable:"true"`
+	CurrentStage   int                 `json:"currentStage"`
+	NextStageAt    *time.Time          `json:"nextStageAt,omitempty" sortable:"true"` // when the current stage is gated and the next one started
+	StageStartedAt *time.Time          `json:"stageStartedAt,omitempty"`
+	StartedAt      *time.Time          `json:"startedAt,omitempty" sortable:"true"`
+	FinishedAt     *time.Time          `json:"finishedAt,omitempty"`
+	LastError      *string             `json:"lastError,omitempty"`
+	Results        RolloutResults      `json:"results,omitempty" gorm:"type:text"`
+	Images         StringSlice         `json:"images,omitempty" gorm:"type:text"` // image updates applied by the first stage, the only ones later stages apply
+	BaseModel
+}
+
+func (UpdateRollout) TableName() string {
+	return "update_rollouts"
+}
+
+// Active reports whether the rollout is running or waiting for its next stage.
+func (r *UpdateRollout) Active() bool {
+	return r.Status == UpdateRolloutStatusRunning || r.Status == UpdateRolloutStatusSoaking
+}
+
+type RolloutStage struct {
+	Name           string   `json:"name,omitempty"`
+	EnvironmentIDs []string `json:"environmentIds"`
+	SoakMinutes    int      `json:"soakMinutes"`
+}
+
+type RolloutResult struct {
+	Stage         int       `json:"stage"`
+	EnvironmentID string    `json:"environmentId"`
+	Updated       int       `json:"updated"`
+	Failed        int       `json:"failed"`
+	RolledBack    int       `json:"rolledBack"`
+	Error         string    `json:"error,omitempty"`
+	FinishedAt    time.Time `json:"finishedAt"`
+}
+
+// nolint:recvcheck
+type RolloutStages []RolloutStage
+
+func (s RolloutStages) Value() (driver.Value, error) {
+	if s == nil {
+		return json.Marshal([]RolloutStage{})
+	}
+	return json.Marshal(s)
+}
+
+func (s *RolloutStages) Scan(value interface{}) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+	return scanJSONInternal(value, s)
+}
+
+// nolint:recvcheck
+type RolloutResults []RolloutResult
+
+func (r RolloutResults) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+func (r *RolloutResults) Scan(value interface{}) error {
+	if value == nil {
+		*r = nil
+		return nil
+	}
+	return scanJSONInternal(value, r)
+}
+
+func scanJSONInternal(value interface{}, dest any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return json.Unmarshal(nil, dest)
+	}
+}
@@ -11,10 +11,18 @@ type User struct {
 	Email                  *string     `json:"email,omitempty" sortable:"true"`
 	Roles                  StringSlice `json:"roles" gorm:"type:text"`
 	OidcSubjectId          *string     `json:"oidcSubjectId,omitempty" gorm:"column:oidc_subject_id"`
+	LdapDn                 *string     `json:"ldapDn,omitempty" gorm:"column:ldap_dn"`
 	LastLogin              *time.Time  `json:"lastLogin,omitempty" gorm:"column:last_login" sortable:"true"`
 	Locale                 *string     `json:"locale,omitempty" gorm:"column:locale"`
 	RequiresPasswordChange bool        `json:"requiresPasswordChange" gorm:"column:requires_password_change"`
 
+	// TOTP second factor. The secret is encrypted and set before enrollment is confirmed.
+	TotpSecret         *string    `json:"-" gorm:"column:totp_secret;type:text"`
+	TotpEnabled        bool       `json:"totpEnabled" gorm:"column:totp_enabled"`
+	TotpLastStep       int64      `json:"-" gorm:"column:totp_last_step"`
+	TotpFailedAttempts int        `json:"-" gorm:"column:totp_failed_attempts"`
+	TotpLockedUntil    *time.Time `json:"-" gorm:"column:totp_locked_until"`
+
 	// OIDC provider tokens
 	OidcAccessToken          *string    `json:"-" gorm:"type:text"`
 	OidcRefreshToken         *string    `json:"-" gorm:"type:text"`
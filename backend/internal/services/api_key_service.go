@@ -6,12 +6,16 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/getarcaneapp/arcane/backend/internal/database"
 	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/pagination"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/rbac"
 	"github.com/getarcaneapp/arcane/types/apikey"
 	"gorm.io/gorm"
 )
@@ -28,6 +32,18 @@ const (
 	apiKeyPrefixLen = 8
 )
 
+// ApiKeyAccess describes the request an API key is presented for.
+type ApiKeyAccess struct {
+	// ClientIP is the address the request came from.
+	ClientIP string
+	// Method and Route are the HTTP method and the registered route pattern of the request.
+	Method string
+	Route  string
+	// EnvironmentID and ProjectID are the environment and project the request targets, if any.
+	EnvironmentID string
+	ProjectID     string
+}
+
 type ApiKeyService struct {
 	db           *database.DB
 	userService  *UserService
@@ -79,24 +95,17 @@ func (s *ApiKeyService) CreateApiKey(ctx context.Context, userID string, req api
 		UserID:      userID,
 		ExpiresAt:   req.ExpiresAt,
 	}
+	if err := applyApiKeyRestrictionsInternal(ak, req.Restrictions); err != nil {
+		return nil, err
+	}
 
 	if err := s.db.WithContext(ctx).Create(ak).Error; err != nil {
 		return nil, fmt.Errorf("failed to create API key: %w", err)
 	}
 
 	return &apikey.ApiKeyCreatedDto{
-		ApiKey: apikey.ApiKey{
-			ID:          ak.ID,
-			Name:        ak.Name,
-			Description: ak.Description,
-			KeyPrefix:   ak.KeyPrefix,
-			UserID:      ak.UserID,
-			ExpiresAt:   ak.ExpiresAt,
-			LastUsedAt:  ak.LastUsedAt,
-			CreatedAt:   ak.CreatedAt,
-			UpdatedAt:   ak.UpdatedAt,
-		},
-		Key: rawKey,
+		ApiKey: toApiKeyDtoInternal(ak),
+		Key:    rawKey,
 	}, nil
 }
 
@@ -134,18 +143,8 @@ func (s *ApiKeyService) CreateEnvironmentApiKey(ctx context.Context, environment
 	}
 
 	return &apikey.ApiKeyCreatedDto{
-		ApiKey: apikey.ApiKey{
-			ID:          ak.ID,
-			Name:        ak.Name,
-			Description: ak.Description,
-			KeyPrefix:   ak.KeyPrefix,
-			UserID:      ak.UserID,
-			ExpiresAt:   ak.ExpiresAt,
-			LastUsedAt:  ak.LastUsedAt,
-			CreatedAt:   ak.CreatedAt,
-			UpdatedAt:   ak.UpdatedAt,
-		},
-		Key: rawKey,
+		ApiKey: toApiKeyDtoInternal(ak),
+		Key:    rawKey,
 	}, nil
 }
 
@@ -158,17 +157,8 @@ func (s *ApiKeyService) GetApiKey(ctx context.Context, id string) (*apikey.ApiKe
 		return nil, fmt.Errorf("failed to get API key: %w", err)
 	}
 
-	return &apikey.ApiKey{
-		ID:          ak.ID,
-		Name:        ak.Name,
-		Description: ak.Description,
-		KeyPrefix:   ak.KeyPrefix,
-		UserID:      ak.UserID,
-		ExpiresAt:   ak.ExpiresAt,
-		LastUsedAt:  ak.LastUsedAt,
-		CreatedAt:   ak.CreatedAt,
-		UpdatedAt:   ak.UpdatedAt,
-	}, nil
+	dto := toApiKeyDtoInternal(&ak)
+	return &dto, nil
 }
 
 func (s *ApiKeyService) ListApiKeys(ctx context.Context, params pagination.QueryParams) ([]apikey.ApiKey, pagination.Response, error) {
@@ -189,18 +179,8 @@ func (s *ApiKeyService) ListApiKeys(ctx context.Context, params pagination.Query
 	}
 
 	result := make([]apikey.ApiKey, len(apiKeys))
-	for i, ak := range apiKeys {
-		result[i] = apikey.ApiKey{
-			ID:          ak.ID,
-			Name:        ak.Name,
-			Description: ak.Description,
-			KeyPrefix:   ak.KeyPrefix,
-			UserID:      ak.UserID,
-			ExpiresAt:   ak.ExpiresAt,
-			LastUsedAt:  ak.LastUsedAt,
-			CreatedAt:   ak.CreatedAt,
-			UpdatedAt:   ak.UpdatedAt,
-		}
+	for i := range apiKeys {
+		result[i] = toApiKeyDtoInternal(&apiKeys[i])
 	}
 
 	return result, paginationResp, nil
@@ -224,22 +204,16 @@ func (s *ApiKeyService) UpdateApiKey(ctx context.Context, id string, req apikey.
 	if req.ExpiresAt != nil {
 		ak.ExpiresAt = req.ExpiresAt
 	}
+	if err := applyApiKeyRestrictionsInternal(&ak, req.Restrictions); err != nil {
+		return nil, err
+	}
 
 	if err := s.db.WithContext(ctx).Save(&ak).Error; err != nil {
 		return nil, fmt.Errorf("failed to update API key: %w", err)
 	}
 
-	return &apikey.ApiKey{
-		ID:          ak.ID,
-		Name:        ak.Name,
-		Description: ak.Description,
-		KeyPrefix:   ak.KeyPrefix,
-		UserID:      ak.UserID,
-		ExpiresAt:   ak.ExpiresAt,
-		LastUsedAt:  ak.LastUsedAt,
-		CreatedAt:   ak.CreatedAt,
-		UpdatedAt:   ak.UpdatedAt,
-	}, nil
+	dto := toApiKeyDtoInternal(&ak)
+	return &dto, nil
 }
 
 func (s *ApiKeyService) DeleteApiKey(ctx context.Context, id string) error {
@@ -253,7 +227,10 @@ func (s *ApiKeyService) DeleteApiKey(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s *ApiKeyService) ValidateApiKey(ctx context.Context, rawKey string) (*models.User, error) {
+// ValidateApiKey returns the owner of rawKey after checking the key's restrictions against the
+// request described by access. A request the key isn't allowed to make fails with a
+// models.ForbiddenError.
+func (s *ApiKeyService) ValidateApiKey(ctx context.Context, rawKey string, access ApiKeyAccess) (*models.User, error) {
 	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
 		return nil, ErrApiKeyInvalid
 	}
@@ -271,6 +248,10 @@ func (s *ApiKeyService) ValidateApiKey(ctx context.Context, rawKey string) (*mod
 				return nil, ErrApiKeyExpired
 			}
 
+			if err := checkApiKeyAccessInternal(&apiKey, access); err != nil {
+				return nil, err
+			}
+
 			// Update last_used_at asynchronously to avoid blocking auth flow
 			go func(keyID string) {
 				bgCtx := context.WithoutCancel(ctx)
@@ -314,3 +295,143 @@ func (s *ApiKeyService) GetEnvironmentByApiKey(ctx context.Context, rawKey strin
 
 	return nil, ErrApiKeyInvalid
 }
+
+func toApiKeyDtoInternal(ak *models.ApiKey) apikey.ApiKey {
+	return apikey.ApiKey{
+		ID:          ak.ID,
+		Name:        ak.Name,
+		Description: ak.Description,
+		KeyPrefix:   ak.KeyPrefix,
+		UserID:      ak.UserID,
+		ExpiresAt:   ak.ExpiresAt,
+		LastUsedAt:  ak.LastUsedAt,
+		CreatedAt:   ak.CreatedAt,
+		UpdatedAt:   ak.UpdatedAt,
+		Restrictions: apikey.Restrictions{
+			Scopes:              ak.Scopes,
+			AllowedEnvironments: ak.AllowedEnvironments,
+			AllowedProjects:     ak.AllowedProjects,
+			AllowedCIDRs:        ak.AllowedCIDRs,
+		},
+	}
+}
+
+// applyApiKeyRestrictionsInternal validates r and stores it on ak. Nil lists leave the current
+// value untouched.
+func applyApiKeyRestrictionsInternal(ak *models.ApiKey, r apikey.Restrictions) error {
+	if r.Scopes != nil {
+		scopes := make(models.StringSlice, 0, len(r.Scopes))
+		for _, scope := range r.Scopes {
+			scope = strings.ToLower(strings.TrimSpace(scope))
+			if !rbac.ValidatePermission(scope) {
+				return &models.ValidationError{Message: fmt.Sprintf("Unknown scope %q", scope), Field: "scopes"}
+			}
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+		ak.Scopes = scopes
+	}
+	if r.AllowedEnvironments != nil {
+		ak.AllowedEnvironments = compactIDsInternal(r.AllowedEnvironments)
+	}
+	if r.AllowedProjects != nil {
+		ak.AllowedProjects = compactIDsInternal(r.AllowedProjects)
+	}
+	if r.AllowedCIDRs != nil {
+		cidrs := make(models.StringSlice, 0, len(r.AllowedCIDRs))
+		for _, c := range r.AllowedCIDRs {
+			prefix, err := parsePrefixInternal(strings.TrimSpace(c))
+			if err != nil {
+				return &models.ValidationError{Message: fmt.Sprintf("Invalid network %q", c), Field: "allowedCidrs"}
+			}
+			if !slices.Contains(cidrs, prefix.String()) {
+				cidrs = append(cidrs, prefix.String())
+			}
+		}
+		ak.AllowedCIDRs = cidrs
+	}
+	return nil
+}
+
+// checkApiKeyAccessInternal enforces the restrictions of ak on the request described by access.
+// Restricted keys only reach environment routes, and only those their scopes grant.
+func checkApiKeyAccessInternal(ak *models.ApiKey, access ApiKeyAccess) error {
+	if len(ak.AllowedCIDRs) > 0 {
+		addr, err := netip.ParseAddr(access.ClientIP)
+		if err != nil {
+			return &models.ForbiddenError{Message: "API key can't be used from this address"}
+		}
+		addr = addr.Unmap()
+		allowed := false
+		for _, c := range ak.AllowedCIDRs {
+			if prefix, err := netip.ParsePrefix(c); err == nil && prefix.Contains(addr) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return &models.ForbiddenError{Message: "API key can't be used from this address"}
+		}
+	}
+
+	if !ak.Restricted() {
+		return nil
+	}
+
+	if access.EnvironmentID == "" {
+		return &models.ForbiddenError{Message: "API key is restricted to environment resources"}
+	}
+	if len(ak.AllowedEnvironments) > 0 && !slices.Contains(ak.AllowedEnvironments, access.EnvironmentID) {
+		return &models.ForbiddenError{Message: "API key can't access this environment"}
+	}
+	if len(ak.AllowedProjects) > 0 && !slices.Contains(ak.AllowedProjects, access.ProjectID) {
+		return &models.ForbiddenError{Message: "API key can't access this project"}
+	}
+
+	if len(ak.Scopes) > 0 {
+		permission, scoped, err := rbac.RequiredPermission(access.Method, access.Route)
+		if err != nil {
+			return &models.ForbiddenError{Message: "API key scopes don't allow this request"}
+		}
+		if !scoped {
+			// Routes outside the permission model may only be read with a scoped key.
+			if access.Method != http.MethodGet && access.Method != http.MethodHead {
+				return &models.ForbiddenError{Message: "API key scopes don't allow this request"}
+			}
+			return nil
+		}
+		if !rbac.GrantsAny(ak.Scopes, permission) {
+			return &models.ForbiddenError{Message: fmt.Sprintf("API key is missing scope %s", permission)}
+		}
+	}
+
+	return nil
+}
+
+func compactIDsInternal(ids []string) models.StringSlice {
+	out := make(models.StringSlice, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id != "" && !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// parsePrefixInternal parses a CIDR, treating a bare address as a single host network.
+func parsePrefixInternal(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+}
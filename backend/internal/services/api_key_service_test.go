@@ -0,0 +1,81 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/types/apikey"
+)
+
+func TestApplyApiKeyRestrictionsInternal(t *testing.T) {
+	ak := &models.ApiKey{}
+	require.NoError(t, applyApiKeyRestrictionsInternal(ak, apikey.Restrictions{
+		Scopes:              []string{" Projects:Deploy ", "projects:deploy"},
+		AllowedEnvironments: []string{"env-1", " ", "env-1"},
+		AllowedCIDRs:        []string{"10.1.2.3/8", "192.168.1.5"},
+	}))
+	assert.Equal(t, models.StringSlice{"projects:deploy"}, ak.Scopes)
+	assert.Equal(t, models.StringSlice{"env-1"}, ak.AllowedEnvironments)
+	assert.Equal(t, models.StringSlice{"10.0.0.0/8", "192.168.1.5/32"}, ak.AllowedCIDRs)
+	assert.Nil(t, ak.AllowedProjects)
+
+	// Nil lists keep the current value, empty lists clear it.
+	require.NoError(t, applyApiKeyRestrictionsInternal(ak, apikey.Restrictions{AllowedEnvironments: []string{}}))
+	assert.Equal(t, models.StringSlice{"projects:deploy"}, ak.Scopes)
+	assert.Empty(t, ak.AllowedEnvironments)
+
+	var validationErr *models.ValidationError
+	require.ErrorAs(t, applyApiKeyRestrictionsInternal(ak, apikey.Restrictions{Scopes: []string{"projects:fly"}}), &validationErr)
+	assert.Equal(t, "scopes", validationErr.Field)
+	require.ErrorAs(t, applyApiKeyRestrictionsInternal(ak, apikey.Restrictions{AllowedCIDRs: []string{"10.0.0.0/33"}}), &validationErr)
+	assert.Equal(t, "allowedCidrs", validationErr.Field)
+}
+
+func TestCheckApiKeyAccessInternal(t *testing.T) {
+	redeploy := ApiKeyAccess{
+		ClientIP:      "10.0.4.2",
+		Method:        http.MethodPost,
+		Route:         "/environments/{id}/projects/{projectId}/redeploy",
+		EnvironmentID: "env-1",
+		ProjectID:     "p1",
+	}
+
+	unrestricted := &models.ApiKey{}
+	require.NoError(t, checkApiKeyAccessInternal(unrestricted, ApiKeyAccess{Method: http.MethodGet, Route: "/users"}))
+
+	ci := &models.ApiKey{
+		Scopes:              models.StringSlice{"projects:deploy"},
+		AllowedEnvironments: models.StringSlice{"env-1"},
+		AllowedProjects:     models.StringSlice{"p1"},
+		AllowedCIDRs:        models.StringSlice{"10.0.0.0/16"},
+	}
+	require.NoError(t, checkApiKeyAccessInternal(ci, redeploy))
+
+	var forbidden *models.ForbiddenError
+	tests := map[string]func(a *ApiKeyAccess){
+		"other project":     func(a *ApiKeyAccess) { a.ProjectID = "p2" },
+		"other environment": func(a *ApiKeyAccess) { a.EnvironmentID = "env-2" },
+		"other network":     func(a *ApiKeyAccess) { a.ClientIP = "10.1.0.1" },
+		"missing scope": func(a *ApiKeyAccess) {
+			a.Method = http.MethodDelete
+			a.Route = "/environments/{id}/projects/{projectId}/destroy"
+		},
+		"outside environments": func(a *ApiKeyAccess) {
+			a.Method = http.MethodPost
+			a.Route = "/api-keys"
+			a.EnvironmentID = ""
+			a.ProjectID = ""
+		},
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			access := redeploy
+			mutate(&access)
+			require.ErrorAs(t, checkApiKeyAccessInternal(ci, access), &forbidden)
+		})
+	}
+}
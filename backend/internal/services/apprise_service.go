@@ -110,7 +110,7 @@ func (s *AppriseService) SendNotification(ctx context.Context, title, body, form
 	case models.NotificationEventPruneReport:
 		// Handle tags for prune report if needed, or leave empty
 
-	case models.NotificationEventVulnerabilityFound:
+	case models.NotificationEventVulnerabilityFound, models.NotificationEventGitOpsDrift:
 		// No dedicated tag in AppriseSettings; notification is sent without a tag
 	}
 
@@ -165,6 +165,9 @@ func (s *AppriseService) SendImageUpdateNotification(ctx context.Context, imageR
 		updateInfo.CurrentDigest,
 		updateInfo.LatestDigest,
 	)
+	if updateInfo.ReleaseNotesURL != "" {
+		body += fmt.Sprintf("\nRelease Notes: %s", updateInfo.ReleaseNotesURL)
+	}
 	return s.SendNotification(ctx, title, body, "text", models.NotificationEventImageUpdate)
 }
 
@@ -200,12 +203,16 @@ func (s *AppriseService) SendBatchImageUpdateNotification(ctx context.Context, u
 	body := "The following images have updates available:\n\n"
 
 	for imageRef, update := range updatesWithChanges {
-		body += fmt.Sprintf("• %s\n  Type: %s\n  Current: %s\n  Latest: %s\n\n",
+		body += fmt.Sprintf("• %s\n  Type: %s\n  Current: %s\n  Latest: %s\n",
 			imageRef,
 			update.UpdateType,
 			update.CurrentDigest,
 			update.LatestDigest,
 		)
+		if update.ReleaseNotesURL != "" {
+			body += fmt.Sprintf("  Release notes: %s\n", update.ReleaseNotesURL)
+		}
+		body += "\n"
 	}
 
 	return s.SendNotification(ctx, title, body, "text", models.NotificationEventImageUpdate)
@@ -25,6 +25,16 @@ var (
 	ErrTokenVersionMismatch = errors.New("token version mismatch")
 	ErrLocalAuthDisabled    = errors.New("local authentication is disabled")
 	ErrOidcAuthDisabled     = errors.New("OIDC authentication is disabled")
+	ErrLdapManagedPassword  = errors.New("password is managed by the LDAP directory")
+)
+
+// twoFactorChallengeExpiry is how long the second login step may take.
+const twoFactorChallengeExpiry = 5 * time.Minute
+
+// Purposes of a two-factor challenge token.
+const (
+	twoFactorPurposeVerify = "verify"
+	twoFactorPurposeSetup  = "setup"
 )
 
 type TokenPair struct {
@@ -40,6 +50,22 @@ type AuthSettings struct {
 	Oidc             *models.OidcConfig `json:"oidc,omitempty"`
 }
 
+// TwoFactorChallenge is returned by Login instead of tokens when the user has to pass a second
+// factor, or set one up because the admins require it.
+type TwoFactorChallenge struct {
+	Token         string
+	ExpiresAt     time.Time
+	SetupRequired bool
+}
+
+type twoFactorClaims struct {
+	jwt.RegisteredClaims
+	Purpose string `json:"purpose"`
+	// LockedUntil is the user's two-factor lockout when the challenge was issued, so a
+	// lockout ends the challenges issued before it.
+	LockedUntil int64 `json:"lockedUntil,omitempty"`
+}
+
 type UserClaims struct {
 	jwt.RegisteredClaims
 	UserID      string   `json:"user_id"`
@@ -51,22 +77,28 @@ type UserClaims struct {
 }
 
 type AuthService struct {
-	userService     *UserService
-	settingsService *SettingsService
-	eventService    *EventService
-	jwtSecret       []byte
-	refreshExpiry   time.Duration
-	config          *config.Config
+	userService      *UserService
+	settingsService  *SettingsService
+	eventService     *EventService
+	twoFactorService *TwoFactorService
+	passkeyService   *PasskeyService
+	ldapService      *LdapService
+	jwtSecret        []byte
+	refreshExpiry    time.Duration
+	config           *config.Config
 }
 
-func NewAuthService(userService *UserService, settingsService *SettingsService, eventService *EventService, jwtSecret string, cfg *config.Config) *AuthService {
+func NewAuthService(userService *UserService, settingsService *SettingsService, eventService *EventService, twoFactorService *TwoFactorService, passkeyService *PasskeyService, ldapService *LdapService, jwtSecret string, cfg *config.Config) *AuthService {
 	return &AuthService{
-		userService:     userService,
-		settingsService: settingsService,
-		eventService:    eventService,
-		jwtSecret:       crypto.CheckOrGenerateJwtSecret(jwtSecret),
-		refreshExpiry:   7 * 24 * time.Hour,
-		config:          cfg,
+		userService:      userService,
+		settingsService:  settingsService,
+		eventService:     eventService,
+		twoFactorService: twoFactorService,
+		passkeyService:   passkeyService,
+		ldapService:      ldapService,
+		jwtSecret:        crypto.CheckOrGenerateJwtSecret(jwtSecret),
+		refreshExpiry:    7 * 24 * time.Hour,
+		config:           cfg,
 	}
 }
 
@@ -172,6 +204,16 @@ func (s *AuthService) IsLocalAuthEnabled(ctx context.Context) (bool, error) {
 	return settings.AuthLocalEnabled.IsTrue(), nil
 }
 
+// IsPasswordLoginEnabled reports whether users can sign in with a username and password,
+// either as local accounts or against LDAP.
+func (s *AuthService) IsPasswordLoginEnabled(ctx context.Context) (bool, error) {
+	settings, err := s.settingsService.GetSettings(ctx)
+	if err != nil {
+		return true, err
+	}
+	return settings.AuthLocalEnabled.IsTrue() || (s.ldapService != nil && settings.LdapEnabled.IsTrue()), nil
+}
+
 func (s *AuthService) IsOidcEnabled(ctx context.Context) (bool, error) {
 	settings, err := s.settingsService.GetSettings(ctx)
 	if err != nil {
@@ -193,26 +235,41 @@ func (s *AuthService) GetOidcConfig(ctx context.Context) (*models.OidcConfig, er
 	return authSettings.Oidc, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, username, password string) (*models.User, *TokenPair, error) {
-	localEnabled, err := s.IsLocalAuthEnabled(ctx)
+// Login checks a user's password. Local accounts are checked against their password hash, every
+// other username against LDAP when it's enabled. Local users with two-factor authentication, or
+// without it while the admins require it, get a TwoFactorChallenge instead of tokens.
+func (s *AuthService) Login(ctx context.Context, username, password string) (*models.User, *TokenPair, *TwoFactorChallenge, error) {
+	settings, err := s.settingsService.GetSettings(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
+	localEnabled := settings.AuthLocalEnabled.IsTrue()
+	ldapEnabled := s.ldapService != nil && settings.LdapEnabled.IsTrue()
 
-	if !localEnabled {
-		return nil, nil, ErrLocalAuthDisabled
+	if !localEnabled && !ldapEnabled {
+		return nil, nil, nil, ErrLocalAuthDisabled
 	}
 
 	user, err := s.userService.GetUserByUsername(ctx, username)
-	if err != nil {
-		if strings.Contains(err.Error(), "user not found") {
-			return nil, nil, ErrInvalidCredentials
-		}
-		return nil, nil, err
+	if err != nil && !errors.Is(err, ErrUserNotFound) {
+		return nil, nil, nil, err
+	}
+
+	isLocalUser := user != nil && user.LdapDn == nil && user.PasswordHash != ""
+	if !isLocalUser && ldapEnabled {
+		user, tokenPair, err := s.loginWithLdapInternal(ctx, username, password)
+		return user, tokenPair, nil, err
+	}
+
+	if !localEnabled {
+		return nil, nil, nil, ErrLocalAuthDisabled
+	}
+	if user == nil {
+		return nil, nil, nil, ErrInvalidCredentials
 	}
 
 	if err := s.userService.ValidatePassword(user.PasswordHash, password); err != nil {
-		return nil, nil, ErrInvalidCredentials
+		return nil, nil, nil, ErrInvalidCredentials
 	}
 
 	if s.userService.NeedsPasswordUpgrade(user.PasswordHash) {
@@ -225,6 +282,234 @@ func (s *AuthService) Login(ctx context.Context, username, password string) (*mo
 		})
 	}
 
+	if s.twoFactorService != nil {
+		switch {
+		case user.TotpEnabled:
+			challenge, err := s.issueTwoFactorChallenge(user, twoFactorPurposeVerify)
+			return nil, nil, challenge, err
+		case s.twoFactorService.IsRequired(ctx):
+			challenge, err := s.issueTwoFactorChallenge(user, twoFactorPurposeSetup)
+			return nil, nil, challenge, err
+		}
+	}
+
+	tokenPair, err := s.completeLogin(ctx, user, "local", false)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return user, tokenPair, nil, nil
+}
+
+// loginWithLdapInternal checks the password against LDAP and provisions or updates the matching
+// user. The directory is responsible for further factors, so no TwoFactorChallenge is issued.
+func (s *AuthService) loginWithLdapInternal(ctx context.Context, username, password string) (*models.User, *TokenPair, error) {
+	ldapUser, err := s.ldapService.Authenticate(ctx, username, password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.findOrCreateLdapUser(ctx, ldapUser)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokenPair, err := s.completeLogin(ctx, user, "ldap", false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokenPair, nil
+}
+
+// findOrCreateLdapUser finds the user by DN, falling back to the username for users whose entry
+// was moved in the directory, and creates it on first login. Roles managed by the group mapping
+// are synced on every login.
+func (s *AuthService) findOrCreateLdapUser(ctx context.Context, ldapUser *LdapUser) (*models.User, error) {
+	granted, managed := s.ldapService.MappedRoles(ctx, ldapUser.Groups)
+
+	user, err := s.userService.GetUserByLdapDn(ctx, ldapUser.DN)
+	if err != nil && !errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+	if user == nil {
+		user, err = s.userService.GetUserByUsername(ctx, ldapUser.Username)
+		if err != nil && !errors.Is(err, ErrUserNotFound) {
+			return nil, err
+		}
+		// Never take over a local or OIDC account that happens to share the username.
+		if user != nil && user.LdapDn == nil {
+			return nil, ErrLdapUserConflict
+		}
+	}
+
+	if user == nil {
+		displayName := ldapUser.DisplayName
+		if displayName == "" {
+			displayName = ldapUser.Username
+		}
+		user = &models.User{
+			BaseModel:   models.BaseModel{ID: uuid.NewString()},
+			Username:    ldapUser.Username,
+			DisplayName: &displayName,
+			Roles:       applyLdapRoles(models.StringSlice{"user"}, granted, managed),
+			LdapDn:      &ldapUser.DN,
+		}
+		if ldapUser.Email != "" {
+			user.Email = &ldapUser.Email
+		}
+		if _, err := s.userService.CreateUser(ctx, user); err != nil {
+			return nil, err
+		}
+		slog.InfoContext(ctx, "Created user from LDAP", "username", user.Username, "dn", ldapUser.DN)
+		return user, nil
+	}
+
+	// The directory owns the profile of LDAP users.
+	user.LdapDn = &ldapUser.DN
+	if ldapUser.DisplayName != "" {
+		user.DisplayName = &ldapUser.DisplayName
+	}
+	if ldapUser.Email != "" {
+		user.Email = &ldapUser.Email
+	}
+	user.Roles = applyLdapRoles(user.Roles, granted, managed)
+	if _, err := s.userService.UpdateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// VerifyTwoFactorLogin completes a login that returned a TwoFactorChallenge. For challenges
+// that required setup, the code confirms the authenticator started with BeginTwoFactorSetup
+// and the new recovery codes are returned.
+func (s *AuthService) VerifyTwoFactorLogin(ctx context.Context, challengeToken, code string) (*models.User, *TokenPair, []string, error) {
+	if s.twoFactorService == nil {
+		return nil, nil, nil, ErrInvalidToken
+	}
+
+	user, purpose, err := s.parseTwoFactorChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var recoveryCodes []string
+	if purpose == twoFactorPurposeSetup && !user.TotpEnabled {
+		recoveryCodes, err = s.twoFactorService.EnableTotp(ctx, user.ID, code)
+	} else {
+		err = s.twoFactorService.VerifyCode(ctx, user, code)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Reload so the last login update doesn't overwrite the changes made above.
+	user, err = s.userService.GetUserByID(ctx, user.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tokenPair, err := s.completeLogin(ctx, user, "local", true)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return user, tokenPair, recoveryCodes, nil
+}
+
+// BeginTwoFactorSetup starts authenticator setup for a user whose login challenge requires it.
+func (s *AuthService) BeginTwoFactorSetup(ctx context.Context, challengeToken string) (*auth.TotpSetup, error) {
+	if s.twoFactorService == nil {
+		return nil, ErrInvalidToken
+	}
+
+	user, purpose, err := s.parseTwoFactorChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if purpose != twoFactorPurposeSetup || user.TotpEnabled {
+		return nil, ErrInvalidToken
+	}
+	return s.twoFactorService.BeginTotpSetup(ctx, user.ID)
+}
+
+// LoginWithPasskey completes a passkey login started with PasskeyService.BeginLogin. Passkeys
+// require user verification, so no second factor is asked for.
+func (s *AuthService) LoginWithPasskey(ctx context.Context, req auth.FinishPasskeyLogin) (*models.User, *TokenPair, error) {
+	localEnabled, err := s.IsLocalAuthEnabled(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !localEnabled {
+		return nil, nil, ErrLocalAuthDisabled
+	}
+	if s.passkeyService == nil {
+		return nil, nil, ErrPasskeyInvalid
+	}
+
+	user, err := s.passkeyService.FinishLogin(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokenPair, err := s.completeLogin(ctx, user, "passkey", false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokenPair, nil
+}
+
+func (s *AuthService) issueTwoFactorChallenge(user *models.User, purpose string) (*TwoFactorChallenge, error) {
+	expiresAt := time.Now().Add(twoFactorChallengeExpiry)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, twoFactorClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        user.ID,
+			Subject:   "2fa",
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Purpose:     purpose,
+		LockedUntil: twoFactorLockoutStampInternal(user),
+	})
+
+	signed, err := token.SignedString(s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorChallenge{
+		Token:         signed,
+		ExpiresAt:     expiresAt,
+		SetupRequired: purpose == twoFactorPurposeSetup,
+	}, nil
+}
+
+func (s *AuthService) parseTwoFactorChallenge(ctx context.Context, challengeToken string) (*models.User, string, error) {
+	token, err := jwt.ParseWithClaims(challengeToken, &twoFactorClaims{},
+		func(t *jwt.Token) (interface{}, error) {
+			return s.jwtSecret, nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, "", ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*twoFactorClaims)
+	if !ok || claims.Subject != "2fa" || claims.ID == "" {
+		return nil, "", ErrInvalidToken
+	}
+
+	user, err := s.userService.GetUserByID(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, "", ErrInvalidToken
+		}
+		return nil, "", err
+	}
+	if claims.LockedUntil != twoFactorLockoutStampInternal(user) {
+		return nil, "", ErrInvalidToken
+	}
+	return user, claims.Purpose, nil
+}
+
+// completeLogin records the login of a user that passed every check and issues its tokens.
+func (s *AuthService) completeLogin(ctx context.Context, user *models.User, method string, twoFactor bool) (*TokenPair, error) {
 	now := time.Now()
 	user.LastLogin = &now
 
@@ -240,12 +525,15 @@ func (s *AuthService) Login(ctx context.Context, username, password string) (*mo
 
 	tokenPair, err := s.generateTokenPair(ctx, user)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	metadata := models.JSON{
 		"action": "login",
-		"method": "local",
+		"method": method,
+	}
+	if twoFactor {
+		metadata["twoFactor"] = true
 	}
 
 	// Run event logging in background
@@ -255,7 +543,7 @@ func (s *AuthService) Login(ctx context.Context, username, password string) (*mo
 		return s.eventService.LogUserEvent(ctx, models.EventTypeUserLogin, logUserID, logUsername, metadata)
 	})
 
-	return user, tokenPair, nil
+	return tokenPair, nil
 }
 
 func (s *AuthService) OidcLogin(ctx context.Context, userInfo auth.OidcUserInfo, tokenResp *auth.OidcTokenResponse) (*models.User, *TokenPair, error) {
@@ -649,6 +937,10 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID, currentPasswor
 		return err
 	}
 
+	if user.LdapDn != nil {
+		return ErrLdapManagedPassword
+	}
+
 	if user.PasswordHash != "" {
 		if err := s.userService.ValidatePassword(user.PasswordHash, currentPassword); err != nil {
 			return ErrInvalidCredentials
@@ -21,11 +21,13 @@ import (
 	"github.com/getarcaneapp/arcane/backend/internal/utils/registry"
 	"github.com/getarcaneapp/arcane/types/containerregistry"
 	ref "go.podman.io/image/v5/docker/reference"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
 	registryCheckTimeout = 10 * time.Second
 	registryCacheTTL     = 30 * time.Minute
+	dockerConfigCacheTTL = 5 * time.Minute
 )
 
 func getHeaderCaseInsensitive(h http.Header, key string) string {
@@ -38,18 +40,29 @@ func getHeaderCaseInsensitive(h http.Header, key string) string {
 }
 
 type ContainerRegistryService struct {
-	db         *database.DB
-	httpClient *http.Client
-	cache      map[string]*cache.Cache[string] // imageRef -> digest cache
-	cacheMu    sync.RWMutex
+	db              *database.DB
+	settingsService *SettingsService
+	httpClient      *http.Client
+	cache           map[string]*cache.Cache[string] // imageRef -> digest cache
+	cacheMu         sync.RWMutex
+
+	// Credentials resolved from the docker config.json, refreshed every dockerConfigCacheTTL
+	// so credential helpers are not executed on every registry call. Concurrent refreshes share
+	// one load through dockerConfigSF, outside of dockerConfigMu.
+	dockerConfigMu       sync.Mutex
+	dockerConfigPath     string
+	dockerConfigLoadedAt time.Time
+	dockerConfigRegs     []models.ContainerRegistry
+	dockerConfigSF       singleflight.Group
 }
 
-func NewContainerRegistryService(db *database.DB) *ContainerRegistryService {
+func NewContainerRegistryService(db *database.DB, settingsService *SettingsService) *ContainerRegistryService {
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 	transport.Proxy = http.ProxyFromEnvironment
 
 	return &ContainerRegistryService{
-		db: db,
+		db:              db,
+		settingsService: settingsService,
 		httpClient: &http.Client{
 			Timeout:   registryCheckTimeout,
 			Transport: transport,
@@ -181,15 +194,104 @@ func (s *ContainerRegistryService) GetDecryptedToken(ctx context.Context, id str
 	return decryptedToken, nil
 }
 
-// GetEnabledRegistries returns all enabled registries
+// GetEnabledRegistries returns all enabled registries, followed by credentials
+// resolved from the configured docker config.json for hosts not stored in the database.
+// Entries from the docker config have no ID and are never persisted.
 func (s *ContainerRegistryService) GetEnabledRegistries(ctx context.Context) ([]models.ContainerRegistry, error) {
 	var registries []models.ContainerRegistry
 	if err := s.db.WithContext(ctx).Where("enabled = ?", true).Find(&registries).Error; err != nil {
 		return nil, fmt.Errorf("failed to get enabled container registries: %w", err)
 	}
+
+	configRegs := s.getDockerConfigRegistriesInternal(ctx)
+	if len(configRegs) == 0 {
+		return registries, nil
+	}
+
+	known := make(map[string]struct{}, len(registries))
+	for _, reg := range registries {
+		known[registry.NormalizeDockerConfigHost(reg.URL)] = struct{}{}
+	}
+	for _, reg := range configRegs {
+		if _, exists := known[reg.URL]; exists {
+			continue
+		}
+		registries = append(registries, reg)
+	}
+
 	return registries, nil
 }
 
+// getDockerConfigRegistriesInternal loads credentials from the docker config.json named by the
+// dockerConfigPath setting, including its credsStore and credHelpers. Results (and failures) are
+// cached per path for dockerConfigCacheTTL.
+func (s *ContainerRegistryService) getDockerConfigRegistriesInternal(ctx context.Context) []models.ContainerRegistry {
+	if s.settingsService == nil {
+		return nil
+	}
+	path := strings.TrimSpace(s.settingsService.GetStringSetting(ctx, "dockerConfigPath", ""))
+	if path == "" {
+		return nil
+	}
+
+	s.dockerConfigMu.Lock()
+	if path == s.dockerConfigPath && time.Since(s.dockerConfigLoadedAt) < dockerConfigCacheTTL {
+		regs := s.dockerConfigRegs
+		s.dockerConfigMu.Unlock()
+		return regs
+	}
+	s.dockerConfigMu.Unlock()
+
+	// The load is shared with concurrent callers, so one of them going away must not cancel it.
+	// Credential helper calls carry their own timeout.
+	v, _, _ := s.dockerConfigSF.Do(path, func() (any, error) {
+		regs := loadDockerConfigRegistriesInternal(context.WithoutCancel(ctx), path)
+		s.dockerConfigMu.Lock()
+		s.dockerConfigPath = path
+		s.dockerConfigLoadedAt = time.Now()
+		s.dockerConfigRegs = regs
+		s.dockerConfigMu.Unlock()
+		return regs, nil
+	})
+	regs, _ := v.([]models.ContainerRegistry)
+	return regs
+}
+
+// loadDockerConfigRegistriesInternal reads the docker config at path and resolves its credentials
+// into registries. Failures are logged and yield the registries that could be resolved.
+func loadDockerConfigRegistriesInternal(ctx context.Context, path string) []models.ContainerRegistry {
+	cfg, err := registry.LoadDockerConfig(path)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to load docker config for registry credentials", "path", path, "error", err)
+		return nil
+	}
+
+	creds, err := cfg.Credentials(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Some docker config credentials could not be resolved", "path", path, "error", err)
+	}
+
+	description := "Docker config: " + path
+	regs := make([]models.ContainerRegistry, 0, len(creds))
+	for _, cred := range creds {
+		encryptedToken, err := crypto.Encrypt(cred.Secret)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to encrypt docker config credential", "registry", cred.Host, "error", err)
+			continue
+		}
+		regs = append(regs, models.ContainerRegistry{
+			URL:         cred.Host,
+			Username:    cred.Username,
+			Token:       encryptedToken,
+			Description: &description,
+			Enabled:     true,
+		})
+	}
+
+	slog.DebugContext(ctx, "Loaded registry credentials from docker config", "path", path, "count", len(regs))
+	return regs
+}
+
 // GetImageDigest fetches the current digest for an image:tag from the registry
 // This is used for digest-based update detection for non-semver tags
 func (s *ContainerRegistryService) GetImageDigest(ctx context.Context, imageRef string) (string, error) {
@@ -542,3 +644,136 @@ func parseWWWAuth(header string) (realm, service string) {
 	c := registry.NewClient()
 	return c.ParseAuthChallenge(header)
 }
+
+// ListRepositories returns the repositories in the catalog of a configured registry.
+func (s *ContainerRegistryService) ListRepositories(ctx context.Context, id string) (*containerregistry.RepositoryList, error) {
+	host, creds, err := s.browseTargetInternal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := registry.NewClient()
+	token, err := rc.GetBrowseToken(ctx, host, []string{registry.CatalogScope}, creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate with registry: %w", err)
+	}
+
+	repos, err := rc.ListRepositories(ctx, host, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	if repos == nil {
+		repos = []string{}
+	}
+
+	return &containerregistry.RepositoryList{RegistryID: id, Repositories: repos}, nil
+}
+
+// ListRepositoryTags returns the tags of a repository in a configured registry.
+func (s *ContainerRegistryService) ListRepositoryTags(ctx context.Context, id, repository string) (*containerregistry.TagList, error) {
+	host, creds, err := s.browseTargetInternal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := registry.NewClient()
+	token, err := rc.GetBrowseToken(ctx, host, []string{registry.RepositoryScope(host, repository)}, creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate with registry: %w", err)
+	}
+
+	tags, err := rc.ListTags(ctx, host, registry.NormalizeRepository(host, repository), token)
+	incomplete := errors.Is(err, registry.ErrTagListIncomplete)
+	if err != nil && !incomplete {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return &containerregistry.TagList{RegistryID: id, Repository: repository, Tags: tags, Incomplete: incomplete}, nil
+}
+
+// GetRepositoryManifest resolves a tag or digest of a repository in a configured registry and
+// describes the platform images behind it.
+func (s *ContainerRegistryService) GetRepositoryManifest(ctx context.Context, id, repository, reference string) (*containerregistry.Manifest, error) {
+	host, creds, err := s.browseTargetInternal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := registry.NewClient()
+	token, err := rc.GetBrowseToken(ctx, host, []string{registry.RepositoryScope(host, repository)}, creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate with registry: %w", err)
+	}
+
+	details, err := rc.GetManifestDetails(ctx, host, repository, reference, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest: %w", err)
+	}
+
+	platforms := make([]containerregistry.ManifestPlatform, 0, len(details.Platforms))
+	for _, p := range details.Platforms {
+		platforms = append(platforms, containerregistry.ManifestPlatform{
+			Digest:       p.Digest,
+			OS:           p.OS,
+			Architecture: p.Architecture,
+			Variant:      p.Variant,
+			Size:         p.Size,
+			Created:      p.Created,
+			Labels:       p.Labels,
+		})
+	}
+
+	imageRef := registry.NormalizeDockerConfigHost(host) + "/" + repository
+	if strings.HasPrefix(reference, "sha256:") {
+		imageRef += "@" + reference
+	} else {
+		imageRef += ":" + reference
+	}
+
+	return &containerregistry.Manifest{
+		RegistryID: id,
+		Repository: repository,
+		Reference:  reference,
+		ImageRef:   imageRef,
+		Digest:     details.Digest,
+		MediaType:  details.MediaType,
+		Platforms:  platforms,
+	}, nil
+}
+
+// browseTargetInternal resolves the address and stored credentials used to browse a registry.
+// Insecure registries without an explicit scheme are reached over plain HTTP.
+func (s *ContainerRegistryService) browseTargetInternal(ctx context.Context, id string) (string, *registry.Credentials, error) {
+	reg, err := s.GetRegistryByID(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	scheme, host, found := strings.Cut(strings.TrimSpace(reg.URL), "://")
+	if !found {
+		scheme, host = "", scheme
+	}
+	host, _, _ = strings.Cut(host, "/")
+	switch {
+	case registry.NormalizeDockerConfigHost(host) == registry.DefaultRegistryDomain:
+		host = registry.DefaultRegistryDomain
+	case scheme != "":
+		host = scheme + "://" + host
+	case reg.Insecure:
+		host = "http://" + host
+	}
+
+	var creds *registry.Credentials
+	if reg.Username != "" && reg.Token != "" {
+		token, err := crypto.Decrypt(reg.Token)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to decrypt token: %w", err)
+		}
+		creds = &registry.Credentials{Username: reg.Username, Token: token}
+	}
+
+	return host, creds, nil
+}
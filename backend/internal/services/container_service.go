@@ -2,6 +2,7 @@ package services
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -637,6 +638,52 @@ func (s *ContainerService) CreateExec(ctx context.Context, containerID string, c
 	return execResp.ID, nil
 }
 
+// RunExec runs cmd in the container without a terminal, waits for it to exit and returns its
+// combined output and exit code. When ctx ends first, RunExec stops waiting and returns the
+// context error. Docker can't kill an exec, the command keeps running in the container.
+func (s *ContainerService) RunExec(ctx context.Context, containerID string, cmd []string) (string, int, error) {
+	dockerClient, err := s.dockerService.GetClient()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+
+	execResp, err := dockerClient.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	attachResp, err := dockerClient.ContainerExecAttach(ctx, execResp.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to attach to exec: %w", err)
+	}
+	defer attachResp.Close()
+
+	// The client doesn't close the hijacked connection when ctx ends, so reading the output
+	// would block until the command exits.
+	stop := context.AfterFunc(ctx, attachResp.Close)
+	defer stop()
+
+	var output bytes.Buffer
+	_, err = stdcopy.StdCopy(&output, &output, attachResp.Reader)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return output.String(), 0, fmt.Errorf("exec did not finish: %w", ctxErr)
+	}
+	if err != nil {
+		return output.String(), 0, fmt.Errorf("failed to read exec output: %w", err)
+	}
+
+	execInspect, err := dockerClient.ContainerExecInspect(ctx, execResp.ID)
+	if err != nil {
+		return output.String(), 0, fmt.Errorf("failed to inspect exec: %w", err)
+	}
+
+	return output.String(), execInspect.ExitCode, nil
+}
+
 // ExecSession manages the lifecycle of a Docker exec session.
 type ExecSession struct {
 	execID       string
@@ -386,14 +386,18 @@ var eventDefinitions = map[models.EventType]struct {
 	models.EventTypeImageDelete: {"Image deleted: %s", "Image '%s' has been deleted", models.EventSeverityWarning},
 	models.EventTypeImageScan:   {"Image scanned: %s", "Security scan completed for image '%s'", models.EventSeverityInfo},
 	models.EventTypeImageError:  {"Image error: %s", "An error occurred with image '%s'", models.EventSeverityError},
-
-	models.EventTypeProjectDeploy: {"Project deployed: %s", "Project '%s' has been deployed", models.EventSeveritySuccess},
-	models.EventTypeProjectDelete: {"Project deleted: %s", "Project '%s' has been deleted", models.EventSeverityWarning},
-	models.EventTypeProjectStart:  {"Project started: %s", "Project '%s' has been started", models.EventSeveritySuccess},
-	models.EventTypeProjectStop:   {"Project stopped: %s", "Project '%s' has been stopped", models.EventSeverityInfo},
-	models.EventTypeProjectCreate: {"Project created: %s", "Project '%s' has been created", models.EventSeveritySuccess},
-	models.EventTypeProjectUpdate: {"Project updated: %s", "Project '%s' has been updated", models.EventSeverityInfo},
-	models.EventTypeProjectError:  {"Project error: %s", "An error occurred with project '%s'", models.EventSeverityError},
+	models.EventTypeImageBuild:  {"Image built: %s", "Image '%s' has been built", models.EventSeverityInfo},
+	models.EventTypeImageTag:    {"Image tagged: %s", "Image has been tagged as '%s'", models.EventSeverityInfo},
+	models.EventTypeImagePush:   {"Image pushed: %s", "Image '%s' has been pushed", models.EventSeveritySuccess},
+
+	models.EventTypeProjectDeploy:   {"Project deployed: %s", "Project '%s' has been deployed", models.EventSeveritySuccess},
+	models.EventTypeProjectDelete:   {"Project deleted: %s", "Project '%s' has been deleted", models.EventSeverityWarning},
+	models.EventTypeProjectStart:    {"Project started: %s", "Project '%s' has been started", models.EventSeveritySuccess},
+	models.EventTypeProjectStop:     {"Project stopped: %s", "Project '%s' has been stopped", models.EventSeverityInfo},
+	models.EventTypeProjectCreate:   {"Project created: %s", "Project '%s' has been created", models.EventSeveritySuccess},
+	models.EventTypeProjectUpdate:   {"Project updated: %s", "Project '%s' has been updated", models.EventSeverityInfo},
+	models.EventTypeProjectError:    {"Project error: %s", "An error occurred with project '%s'", models.EventSeverityError},
+	models.EventTypeProjectRollback: {"Project rolled back: %s", "Project '%s' has been rolled back to a previous deployment", models.EventSeverityWarning},
 
 	models.EventTypeVolumeCreate:             {"Volume created: %s", "Volume '%s' has been created", models.EventSeveritySuccess},
 	models.EventTypeVolumeDelete:             {"Volume deleted: %s", "Volume '%s' has been deleted", models.EventSeverityWarning},
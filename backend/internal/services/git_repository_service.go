@@ -351,6 +351,7 @@ func (s *GitRepositoryService) ListBranches(ctx context.Context, id string) ([]g
 		result = append(result, gitops.BranchInfo{
 			Name:      branch.Name,
 			IsDefault: branch.IsDefault,
+			IsTag:     branch.IsTag,
 		})
 	}
 
@@ -2,19 +2,34 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"net/http"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"filippo.io/age"
 	"github.com/getarcaneapp/arcane/backend/internal/database"
 	"github.com/getarcaneapp/arcane/backend/internal/models"
 	bootstraputils "github.com/getarcaneapp/arcane/backend/internal/utils"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/crypto"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/git"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/mapper"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/pagination"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/sops"
+	"github.com/getarcaneapp/arcane/backend/pkg/projects"
 	"github.com/getarcaneapp/arcane/types/gitops"
+	"github.com/getarcaneapp/arcane/types/imagebuild"
+	"github.com/pmezard/go-difflib/difflib"
 	"gorm.io/gorm"
 )
 
@@ -22,17 +37,36 @@ type GitOpsSyncService struct {
 	db             *database.DB
 	repoService    *GitRepositoryService
 	projectService *ProjectService
+	buildService   *ImageBuildService
 	eventService   *EventService
+	// notificationService is optional; drift is still recorded without it.
+	notificationService *NotificationService
+
+	// webhookSyncs tracks syncs started by webhook deliveries so that bursts of
+	// pushes don't run the same sync concurrently. The value is set when a push arrived
+	// during the running sync, which then runs once more.
+	webhookMu    sync.Mutex
+	webhookSyncs map[string]bool
 }
 
-const defaultGitSyncTimeout = 5 * time.Minute
+const (
+	defaultGitSyncTimeout = 5 * time.Minute
+	webhookSecretLength   = 32
+	// maxSyncFilesSize caps the files a sync copies from the repository besides the compose file.
+	maxSyncFilesSize = 64 << 20
+	// gitSyncBuildTimeout replaces defaultGitSyncTimeout for syncs that build images.
+	gitSyncBuildTimeout = 60 * time.Minute
+)
 
-func NewGitOpsSyncService(db *database.DB, repoService *GitRepositoryService, projectService *ProjectService, eventService *EventService) *GitOpsSyncService {
+func NewGitOpsSyncService(db *database.DB, repoService *GitRepositoryService, projectService *ProjectService, buildService *ImageBuildService, eventService *EventService, notificationService *NotificationService) *GitOpsSyncService {
 	return &GitOpsSyncService{
-		db:             db,
-		repoService:    repoService,
-		projectService: projectService,
-		eventService:   eventService,
+		db:                  db,
+		repoService:         repoService,
+		projectService:      projectService,
+		buildService:        buildService,
+		eventService:        eventService,
+		notificationService: notificationService,
+		webhookSyncs:        make(map[string]bool),
 	}
 }
 
@@ -142,6 +176,8 @@ func (s *GitOpsSyncService) CreateSync(ctx context.Context, environmentID string
 		EnvironmentID: environmentID,
 		RepositoryID:  req.RepositoryID,
 		Branch:        req.Branch,
+		RefType:       string(git.RefTypeBranch),
+		Ref:           strings.TrimSpace(req.Ref),
 		ComposePath:   req.ComposePath,
 		ProjectName:   projectName,
 		ProjectID:     nil, // Will be set during first sync
@@ -149,12 +185,29 @@ func (s *GitOpsSyncService) CreateSync(ctx context.Context, environmentID string
 		SyncInterval:  60,
 	}
 
+	if req.RefType != "" {
+		sync.RefType = req.RefType
+	}
+	if err := syncRefSelector(&sync).Validate(); err != nil {
+		return nil, &models.ValidationError{Message: err.Error(), Field: "ref"}
+	}
 	if req.AutoSync != nil {
 		sync.AutoSync = *req.AutoSync
 	}
 	if req.SyncInterval != nil {
 		sync.SyncInterval = *req.SyncInterval
 	}
+	if req.BuildImages != nil {
+		sync.BuildImages = *req.BuildImages
+	}
+	if strings.TrimSpace(req.SopsAgeKey) != "" {
+		encrypted, recipients, err := encryptSopsAgeKey(req.SopsAgeKey)
+		if err != nil {
+			return nil, err
+		}
+		sync.SopsAgeKey = encrypted
+		sync.SopsAgeRecipients = recipients
+	}
 
 	if err := s.db.WithContext(ctx).Create(&sync).Error; err != nil {
 		slog.ErrorContext(ctx, "Failed to create GitOps sync in database", "name", req.Name, "repositoryID", req.RepositoryID, "environmentID", environmentID, "error", err)
@@ -206,6 +259,23 @@ func (s *GitOpsSyncService) UpdateSync(ctx context.Context, environmentID, id st
 	if req.Branch != nil {
 		updates["branch"] = *req.Branch
 	}
+	if req.RefType != nil || req.Ref != nil || req.Branch != nil {
+		updated := *sync
+		if req.Branch != nil {
+			updated.Branch = *req.Branch
+		}
+		if req.RefType != nil {
+			updated.RefType = *req.RefType
+			updates["ref_type"] = *req.RefType
+		}
+		if req.Ref != nil {
+			updated.Ref = strings.TrimSpace(*req.Ref)
+			updates["ref"] = updated.Ref
+		}
+		if err := syncRefSelector(&updated).Validate(); err != nil {
+			return nil, &models.ValidationError{Message: err.Error(), Field: "ref"}
+		}
+	}
 	if req.ComposePath != nil {
 		updates["compose_path"] = *req.ComposePath
 	}
@@ -218,6 +288,21 @@ func (s *GitOpsSyncService) UpdateSync(ctx context.Context, environmentID, id st
 	if req.SyncInterval != nil {
 		updates["sync_interval"] = *req.SyncInterval
 	}
+	if req.BuildImages != nil {
+		updates["build_images"] = *req.BuildImages
+	}
+	if req.SopsAgeKey != nil {
+		updates["sops_age_key"] = ""
+		updates["sops_age_recipients"] = models.StringSlice(nil)
+		if strings.TrimSpace(*req.SopsAgeKey) != "" {
+			encrypted, recipients, err := encryptSopsAgeKey(*req.SopsAgeKey)
+			if err != nil {
+				return nil, err
+			}
+			updates["sops_age_key"] = encrypted
+			updates["sops_age_recipients"] = recipients
+		}
+	}
 
 	if len(updates) > 0 {
 		if err := s.db.WithContext(ctx).Model(sync).Updates(updates).Error; err != nil {
@@ -265,6 +350,8 @@ func (s *GitOpsSyncService) DeleteSync(ctx context.Context, environmentID, id st
 		return err
 	}
 
+	s.removeUnusedMirrorInternal(ctx, sync)
+
 	// Log event
 	resourceType := "git_sync"
 	_, _ = s.eventService.CreateEvent(ctx, CreateEventRequest{
@@ -280,15 +367,51 @@ func (s *GitOpsSyncService) DeleteSync(ctx context.Context, environmentID, id st
 	return nil
 }
 
-func (s *GitOpsSyncService) PerformSync(ctx context.Context, environmentID, id string) (*gitops.SyncResult, error) {
-	syncCtx, cancel := context.WithTimeout(ctx, defaultGitSyncTimeout)
-	defer cancel()
+// removeUnusedMirrorInternal deletes the local mirror used by a sync once no other sync
+// of the same repository fetches the same refs.
+func (s *GitOpsSyncService) removeUnusedMirrorInternal(ctx context.Context, sync *models.GitOpsSync) {
+	if sync.Repository == nil {
+		return
+	}
+
+	var others []models.GitOpsSync
+	if err := s.db.WithContext(ctx).Where("repository_id = ?", sync.RepositoryID).Find(&others).Error; err != nil {
+		return
+	}
+	ref := syncRefSelector(sync)
+	for i := range others {
+		if syncRefSelector(&others[i]).SharesMirror(ref) {
+			return
+		}
+	}
 
-	sync, err := s.GetSyncByID(syncCtx, environmentID, id)
+	if err := s.repoService.gitClient.RemoveMirror(ctx, sync.Repository.URL, ref); err != nil {
+		slog.WarnContext(ctx, "Failed to remove repository mirror", "syncId", sync.ID, "error", err)
+	}
+}
+
+// syncRefSelector returns the git ref selector a sync checks out. Syncs created before
+// ref types existed track their branch.
+func syncRefSelector(sync *models.GitOpsSync) git.RefSelector {
+	if sync.RefType == "" {
+		return git.BranchRef(sync.Branch)
+	}
+	return git.RefSelector{Type: git.RefType(sync.RefType), Branch: sync.Branch, Pattern: sync.Ref}
+}
+
+func (s *GitOpsSyncService) PerformSync(ctx context.Context, environmentID, id string) (*gitops.SyncResult, error) {
+	sync, err := s.GetSyncByID(ctx, environmentID, id)
 	if err != nil {
 		return nil, err
 	}
 
+	timeout := defaultGitSyncTimeout
+	if sync.BuildImages && s.buildService != nil {
+		timeout = gitSyncBuildTimeout
+	}
+	syncCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	result := &gitops.SyncResult{
 		Success:  false,
 		SyncedAt: time.Now(),
@@ -305,23 +428,13 @@ func (s *GitOpsSyncService) PerformSync(ctx context.Context, environmentID, id s
 		return result, s.failSync(syncCtx, id, result, sync, "Failed to get authentication config", err.Error())
 	}
 
-	// Clone the repository
-	repoPath, err := s.repoService.gitClient.Clone(syncCtx, repository.URL, sync.Branch, authConfig)
-	if err != nil {
-		return result, s.failSync(syncCtx, id, result, sync, "Failed to clone repository", err.Error())
-	}
-	defer func() {
-		if cleanupErr := s.repoService.gitClient.Cleanup(repoPath); cleanupErr != nil {
-			slog.WarnContext(syncCtx, "Failed to cleanup repository", "path", repoPath, "error", cleanupErr)
-		}
-	}()
-
-	// Get the current commit hash
-	commitHash, err := s.repoService.gitClient.GetCurrentCommit(syncCtx, repoPath)
+	// Fetch the latest changes into the persistent mirror and check out the selected ref
+	checkout, release, err := s.repoService.gitClient.Mirror(syncCtx, repository.URL, syncRefSelector(sync), authConfig)
 	if err != nil {
-		slog.WarnContext(syncCtx, "Failed to get commit hash", "error", err)
-		commitHash = ""
+		return result, s.failSync(syncCtx, id, result, sync, "Failed to fetch repository", err.Error())
 	}
+	defer release()
+	repoPath, commitHash := checkout.Dir, checkout.Commit
 
 	// Check if compose file exists
 	if !s.repoService.gitClient.FileExists(syncCtx, repoPath, sync.ComposePath) {
@@ -329,35 +442,62 @@ func (s *GitOpsSyncService) PerformSync(ctx context.Context, environmentID, id s
 		return result, s.failSync(syncCtx, id, result, sync, fmt.Sprintf("Compose file not found at %s", sync.ComposePath), errMsg)
 	}
 
-	// Read compose file content
-	composeContent, err := s.repoService.gitClient.ReadFile(syncCtx, repoPath, sync.ComposePath)
+	// Read the compose file, its .env and the files it references
+	source, err := s.readSyncSourceInternal(syncCtx, repoPath, sync)
 	if err != nil {
-		return result, s.failSync(syncCtx, id, result, sync, "Failed to read compose file", err.Error())
-	}
-
-	// Try to read .env file from the same directory as the compose file
-	var envContent *string
-	envPath := filepath.Join(filepath.Dir(sync.ComposePath), ".env")
-	if s.repoService.gitClient.FileExists(syncCtx, repoPath, envPath) {
-		content, err := s.repoService.gitClient.ReadFile(syncCtx, repoPath, envPath)
-		if err != nil {
-			slog.WarnContext(syncCtx, "Failed to read .env file", "path", envPath, "error", err)
+		return result, s.failSync(syncCtx, id, result, sync, "Failed to read compose files", err.Error())
+	}
+
+	// Skip the rewrite and redeploy when the files match what was last applied
+	// Syncs that build images also pick up changes to the build contexts, which the hash doesn't cover.
+	contentHash := syncContentHash(source.compose, source.env, source.files, source.modes)
+	commitChanged := sync.LastSyncCommit == nil || *sync.LastSyncCommit != commitHash
+	if s.isSyncUpToDateInternal(sync, contentHash) && !(sync.BuildImages && commitChanged) {
+		s.updateSyncStatus(syncCtx, id, "success", "", checkout, contentHash)
+		result.Success = true
+		if sync.LastSyncCommit != nil && *sync.LastSyncCommit == commitHash {
+			result.Message = "Already up to date"
 		} else {
-			envContent = &content
+			result.Message = fmt.Sprintf("No changes to %s, project %s left untouched", sync.ComposePath, sync.Project.Name)
+		}
+		slog.DebugContext(syncCtx, "GitOps sync skipped, no changes", "syncId", id, "commit", commitHash)
+		return result, nil
+	}
+
+	// Build the images of services with a build section from the checkout before deploying
+	imagesBuilt := false
+	if sync.BuildImages && s.buildService != nil {
+		build, err := s.buildService.BuildComposeFile(syncCtx, filepath.Join(repoPath, sync.ComposePath), sync.ProjectName, ComposeBuildOptions{
+			RepositoryID: sync.RepositoryID,
+			GitOpsSyncID: sync.ID,
+			Ref:          checkout.Ref,
+			Commit:       commitHash,
+			Trigger:      imagebuild.TriggerGitOps,
+		}, systemUser)
+		if err != nil {
+			return result, s.failSync(syncCtx, id, result, sync, "Failed to build images", err.Error())
 		}
+		imagesBuilt = build != nil
 	}
 
 	// Get or create project
-	project, err := s.getOrCreateProjectInternal(syncCtx, sync, id, composeContent, envContent, result)
+	project, err := s.getOrCreateProjectInternal(syncCtx, sync, id, source.compose, source.env, source.files, source.modes, imagesBuilt, result)
 	if err != nil {
 		return result, err
 	}
 
 	// Update sync status
-	s.updateSyncStatus(syncCtx, id, "success", "", commitHash)
+	s.updateSyncStatus(syncCtx, id, "success", "", checkout, contentHash)
+	s.recordSyncedFilesInternal(syncCtx, id, source.files)
 
 	result.Success = true
 	result.Message = fmt.Sprintf("Successfully synced compose file from %s to project %s", sync.ComposePath, project.Name)
+	if sync.RefType != "" && sync.RefType != string(git.RefTypeBranch) {
+		result.Message += fmt.Sprintf(" at %s", checkout.Ref)
+	}
+	if len(source.skipped) > 0 {
+		result.Message += fmt.Sprintf(" (skipped references outside the compose directory: %s)", strings.Join(source.skipped, ", "))
+	}
 
 	// Log success event
 	resourceType := "git_sync"
@@ -378,7 +518,300 @@ func (s *GitOpsSyncService) PerformSync(ctx context.Context, environmentID, id s
 	return result, nil
 }
 
-func (s *GitOpsSyncService) updateSyncStatus(ctx context.Context, id, status, errorMsg, commitHash string) {
+// isSyncUpToDateInternal reports whether the last successful sync applied the same files
+// to a project that still exists.
+func (s *GitOpsSyncService) isSyncUpToDateInternal(sync *models.GitOpsSync, contentHash string) bool {
+	if sync.Project == nil || sync.LastSyncHash == nil || *sync.LastSyncHash != contentHash {
+		return false
+	}
+	return sync.LastSyncStatus != nil && *sync.LastSyncStatus == "success"
+}
+
+// syncContentHash hashes the files a sync writes to its project. Of the file modes only the
+// executable bit is applied, so it is the only one hashed.
+func syncContentHash(composeContent string, envContent *string, files map[string]string, modes map[string]fs.FileMode) string {
+	h := sha256.New()
+	h.Write([]byte(composeContent))
+	if envContent != nil {
+		h.Write([]byte{0})
+		h.Write([]byte(*envContent))
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		fmt.Fprintf(h, "\x00%s\x00%d\x00", p, len(files[p]))
+		h.Write([]byte(files[p]))
+		if modes[p]&0o111 != 0 {
+			h.Write([]byte("\x00x"))
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// syncSource holds the files a sync applies to its project, as read from the repository.
+type syncSource struct {
+	compose string
+	env     *string
+	// files are the other referenced files, keyed by path relative to the compose file.
+	files map[string]string
+	// modes are the permission bits of files.
+	modes map[string]fs.FileMode
+	// skipped are references that point outside the compose directory.
+	skipped []string
+	// composeEncrypted and encrypted (".env" and paths of files) mark what was decrypted
+	// from SOPS, so previews can leave the plaintext out.
+	composeEncrypted bool
+	encrypted        map[string]bool
+}
+
+// readSyncSourceInternal reads the files a sync applies from the repository checkout and
+// decrypts the SOPS encrypted ones, so plaintext secrets never touch the mirror.
+func (s *GitOpsSyncService) readSyncSourceInternal(ctx context.Context, repoPath string, sync *models.GitOpsSync) (*syncSource, error) {
+	composePath := sync.ComposePath
+	composeContent, err := s.repoService.gitClient.ReadFile(ctx, repoPath, composePath)
+	if err != nil {
+		return nil, err
+	}
+	source := &syncSource{compose: composeContent}
+
+	// Try to read .env file from the same directory as the compose file
+	envPath := filepath.Join(filepath.Dir(composePath), ".env")
+	if s.repoService.gitClient.FileExists(ctx, repoPath, envPath) {
+		content, err := s.repoService.gitClient.ReadFile(ctx, repoPath, envPath)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to read .env file", "path", envPath, "error", err)
+		} else {
+			source.env = &content
+		}
+	}
+
+	// Collect the other files the compose file references (includes, env files, bind mounts, ...)
+	source.files, source.modes, source.skipped, err = s.collectSyncFilesInternal(ctx, repoPath, composePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files referenced by compose file: %w", err)
+	}
+
+	if err := decryptSyncSource(sync, source); err != nil {
+		return nil, err
+	}
+
+	return source, nil
+}
+
+// decryptSyncSource replaces SOPS encrypted files in source with their plaintext, using
+// the sync's age key. Files that aren't encrypted are left as they are.
+func decryptSyncSource(sync *models.GitOpsSync, source *syncSource) error {
+	var identities []age.Identity
+	decrypt := func(path, content string) (string, bool, error) {
+		format, ok := sops.FormatForPath(path)
+		if !ok || !sops.IsEncrypted(content, format) {
+			return content, false, nil
+		}
+
+		if identities == nil {
+			if sync.SopsAgeKey == "" {
+				return "", false, fmt.Errorf("%s is encrypted with SOPS but no age key is configured for this sync", path)
+			}
+			key, err := crypto.Decrypt(sync.SopsAgeKey)
+			if err != nil {
+				return "", false, fmt.Errorf("failed to decrypt age key: %w", err)
+			}
+			if identities, _, err = sops.ParseAgeKey(key); err != nil {
+				return "", false, err
+			}
+		}
+
+		plaintext, err := sops.Decrypt(content, format, identities)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to decrypt %s: %w", path, err)
+		}
+		return plaintext, true, nil
+	}
+
+	var err error
+	if source.compose, source.composeEncrypted, err = decrypt(sync.ComposePath, source.compose); err != nil {
+		return err
+	}
+	source.encrypted = map[string]bool{}
+	if source.env != nil {
+		env, encrypted, err := decrypt(".env", *source.env)
+		if err != nil {
+			return err
+		}
+		source.env = &env
+		source.encrypted[".env"] = encrypted
+	}
+	for path, content := range source.files {
+		var encrypted bool
+		if source.files[path], encrypted, err = decrypt(path, content); err != nil {
+			return err
+		}
+		source.encrypted[path] = encrypted
+	}
+	return nil
+}
+
+// encryptSopsAgeKey validates an age key and encrypts it for storage, returning its
+// recipients alongside.
+func encryptSopsAgeKey(key string) (string, models.StringSlice, error) {
+	_, recipients, err := sops.ParseAgeKey(key)
+	if err != nil {
+		return "", nil, &models.ValidationError{Message: err.Error(), Field: "sopsAgeKey"}
+	}
+	encrypted, err := crypto.Encrypt(strings.TrimSpace(key))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encrypt age key: %w", err)
+	}
+	return encrypted, recipients, nil
+}
+
+// collectSyncFilesInternal reads the files and directories referenced by the compose file
+// from the checkout, keyed by their path relative to the compose file's directory, along
+// with their permission bits. The compose file and its .env are handled separately and
+// excluded. References that point outside the compose directory, directly or through a
+// symlink, can't be placed in the project and are returned as skipped.
+func (s *GitOpsSyncService) collectSyncFilesInternal(ctx context.Context, repoPath, composePath string) (map[string]string, map[string]fs.FileMode, []string, error) {
+	refs, err := projects.ParseFileReferences(filepath.Join(repoPath, composePath))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Reads go through an os.Root so that symlinked directories in a reference can't reach
+	// files outside the checkout.
+	root, err := os.OpenRoot(repoPath)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer func() { _ = root.Close() }()
+	rootFS := root.FS()
+
+	composeDir := filepath.Dir(filepath.Clean(composePath))
+	composeFile := filepath.Base(composePath)
+	files := map[string]string{}
+	modes := map[string]fs.FileMode{}
+	var skipped []string
+	var total int64
+
+	for _, ref := range refs {
+		if ref == ".." || strings.HasPrefix(ref, ".."+string(filepath.Separator)) {
+			skipped = append(skipped, ref)
+			continue
+		}
+		refPath := filepath.Join(composeDir, ref)
+		if err := git.ValidatePath(repoPath, refPath); err != nil {
+			skipped = append(skipped, ref)
+			continue
+		}
+		inside, err := pathResolvesUnderInternal(filepath.Join(repoPath, composeDir), filepath.Join(repoPath, refPath))
+		if errors.Is(err, fs.ErrNotExist) {
+			// Bind mount sources and optional env files may legitimately be missing.
+			continue
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !inside {
+			skipped = append(skipped, ref)
+			continue
+		}
+
+		err = fs.WalkDir(rootFS, filepath.ToSlash(refPath), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if d.IsDir() {
+				if d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			// Symlinks could point outside the repository, only copy regular files.
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(composeDir, filepath.FromSlash(path))
+			if err != nil {
+				return err
+			}
+			if rel == composeFile || rel == ".env" {
+				return nil
+			}
+			if _, ok := files[rel]; ok {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+			if total > maxSyncFilesSize {
+				return fmt.Errorf("referenced files exceed %d MiB", maxSyncFilesSize>>20)
+			}
+
+			content, err := fs.ReadFile(rootFS, path)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", rel, err)
+			}
+			files[rel] = string(content)
+			modes[rel] = info.Mode().Perm()
+			return nil
+		})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	for _, ref := range skipped {
+		slog.WarnContext(ctx, "Skipping compose reference outside the compose directory", "composePath", composePath, "reference", ref)
+	}
+
+	return files, modes, skipped, nil
+}
+
+// pathResolvesUnderInternal reports whether path, with all symlinks resolved, is dir or
+// inside it.
+func pathResolvesUnderInternal(dir, path string) (bool, error) {
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false, err
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false, err
+	}
+	rel, err := filepath.Rel(resolvedDir, resolved)
+	if err != nil {
+		return false, nil
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)), nil
+}
+
+func (s *GitOpsSyncService) recordSyncedFilesInternal(ctx context.Context, id string, files map[string]string) {
+	paths := make(models.StringSlice, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	if err := s.db.WithContext(ctx).Model(&models.GitOpsSync{}).Where("id = ?", id).Update("synced_files", paths).Error; err != nil {
+		slog.ErrorContext(ctx, "Failed to record synced files", "error", err, "syncId", id)
+	}
+}
+
+func (s *GitOpsSyncService) updateSyncStatus(ctx context.Context, id, status, errorMsg string, checkout *git.Checkout, contentHash string) {
 	now := time.Now()
 	updates := map[string]interface{}{
 		"last_sync_at":     now,
@@ -390,9 +823,17 @@ func (s *GitOpsSyncService) updateSyncStatus(ctx context.Context, id, status, er
 	} else {
 		updates["last_sync_error"] = nil
 	}
+	if status == "success" {
+		// The sync rewrote the project files, so earlier drift no longer applies.
+		updates["drift_reasons"] = nil
+	}
 
-	if commitHash != "" {
-		updates["last_sync_commit"] = commitHash
+	if checkout != nil {
+		updates["last_sync_commit"] = checkout.Commit
+		updates["last_sync_ref"] = checkout.Ref
+	}
+	if contentHash != "" {
+		updates["last_sync_hash"] = contentHash
 	}
 
 	if err := s.db.WithContext(ctx).Model(&models.GitOpsSync{}).Where("id = ?", id).Updates(updates).Error; err != nil {
@@ -413,6 +854,8 @@ func (s *GitOpsSyncService) GetSyncStatus(ctx context.Context, environmentID, id
 		LastSyncStatus: sync.LastSyncStatus,
 		LastSyncError:  sync.LastSyncError,
 		LastSyncCommit: sync.LastSyncCommit,
+		DriftCheckedAt: sync.DriftCheckedAt,
+		DriftReasons:   sync.DriftReasons,
 	}
 
 	// Calculate next sync time
@@ -478,16 +921,12 @@ func (s *GitOpsSyncService) BrowseFiles(ctx context.Context, environmentID, id s
 		return nil, err
 	}
 
-	// Clone the repository
-	repoPath, err := s.repoService.gitClient.Clone(browseCtx, repository.URL, sync.Branch, authConfig)
+	checkout, release, err := s.repoService.gitClient.Mirror(browseCtx, repository.URL, syncRefSelector(sync), authConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to clone repository: %w", err)
+		return nil, err
 	}
-	defer func() {
-		if cleanupErr := s.repoService.gitClient.Cleanup(repoPath); cleanupErr != nil {
-			slog.WarnContext(browseCtx, "Failed to cleanup repository", "path", repoPath, "error", cleanupErr)
-		}
-	}()
+	defer release()
+	repoPath := checkout.Dir
 
 	// Browse the tree
 	files, err := s.repoService.gitClient.BrowseTree(browseCtx, repoPath, path)
@@ -501,6 +940,490 @@ func (s *GitOpsSyncService) BrowseFiles(ctx context.Context, environmentID, id s
 	}, nil
 }
 
+// DiffSync previews a sync without applying it: it diffs the files in the repository
+// against the project on disk and plans which services compose would create, recreate or
+// remove.
+func (s *GitOpsSyncService) DiffSync(ctx context.Context, environmentID, id string) (*gitops.SyncDiff, error) {
+	diffCtx, cancel := context.WithTimeout(ctx, defaultGitSyncTimeout)
+	defer cancel()
+
+	sync, err := s.GetSyncByID(diffCtx, environmentID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	repository := sync.Repository
+	if repository == nil {
+		return nil, fmt.Errorf("repository not found")
+	}
+
+	authConfig, err := s.repoService.GetAuthConfig(diffCtx, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	checkout, release, err := s.repoService.gitClient.Mirror(diffCtx, repository.URL, syncRefSelector(sync), authConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repository: %w", err)
+	}
+	defer release()
+	repoPath, commitHash := checkout.Dir, checkout.Commit
+
+	if !s.repoService.gitClient.FileExists(diffCtx, repoPath, sync.ComposePath) {
+		return nil, models.NewNotFoundError(fmt.Sprintf("Compose file %s", sync.ComposePath))
+	}
+
+	source, err := s.readSyncSourceInternal(diffCtx, repoPath, sync)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compose files: %w", err)
+	}
+
+	result := &gitops.SyncDiff{
+		Commit:   commitHash,
+		Files:    []gitops.SyncFileChange{},
+		Services: []gitops.SyncServicePlan{},
+	}
+	for _, ref := range source.skipped {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Reference outside the compose directory is not synced: %s", ref))
+	}
+
+	projectPath := ""
+	projectID := ""
+	projectName := sync.ProjectName
+	if sync.Project != nil {
+		projectPath = sync.Project.Path
+		projectID = sync.Project.ID
+		projectName = sync.Project.Name
+	}
+
+	var diff strings.Builder
+	for _, file := range syncDiffFilesInternal(projectPath, source, sync.SyncedFiles) {
+		if file.before == file.after && file.existedBefore == file.existsAfter {
+			continue
+		}
+
+		status := "modified"
+		switch {
+		case !file.existedBefore:
+			status = "added"
+		case !file.existsAfter:
+			status = "removed"
+		}
+		result.Files = append(result.Files, gitops.SyncFileChange{Path: file.path, Status: status})
+
+		// Both sides of a SOPS encrypted file are plaintext secrets, so only the change is
+		// listed. Removed files aren't in the repository anymore to tell whether they were
+		// encrypted, so those that could have been are treated as such.
+		if !file.encrypted && !file.existsAfter && sync.SopsAgeKey != "" {
+			_, file.encrypted = sops.FormatForPath(file.path)
+		}
+		if file.encrypted {
+			diff.WriteString(encryptedFileDiff(file.path, file.existedBefore, file.existsAfter))
+			continue
+		}
+
+		text, err := unifiedFileDiff(file.path, file.before, file.after, file.existedBefore, file.existsAfter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s: %w", file.path, err)
+		}
+		diff.WriteString(text)
+	}
+	result.Diff = diff.String()
+
+	plan, err := s.projectService.PlanProjectChanges(diffCtx, projectID, projectName, filepath.Join(repoPath, sync.ComposePath))
+	if err != nil {
+		slog.WarnContext(diffCtx, "Failed to plan service changes for GitOps sync", "syncId", id, "error", err)
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Unable to plan service changes: %v", err))
+	}
+	for _, change := range plan {
+		result.Services = append(result.Services, gitops.SyncServicePlan{
+			Name:   change.Name,
+			Action: string(change.Action),
+			Image:  change.Image,
+		})
+		if change.Action != projects.ServiceActionUnchanged {
+			result.HasChanges = true
+		}
+	}
+	if len(result.Files) > 0 {
+		result.HasChanges = true
+	}
+
+	return result, nil
+}
+
+// syncDiffFile pairs a project file on disk with the version a sync would write.
+type syncDiffFile struct {
+	path          string
+	before        string
+	after         string
+	existedBefore bool
+	existsAfter   bool
+	// encrypted marks a file decrypted from SOPS, whose content must not be shown.
+	encrypted bool
+}
+
+// syncDiffFilesInternal lists the files a sync manages, compose file and .env first, with
+// their current content under projectPath (empty when the project doesn't exist yet).
+func syncDiffFilesInternal(projectPath string, source *syncSource, previous []string) []syncDiffFile {
+	// Current files are read through an os.Root so that symlinks in the project can't expose
+	// files outside it in the diff.
+	var root *os.Root
+	if projectPath != "" {
+		if r, err := os.OpenRoot(projectPath); err == nil {
+			root = r
+			defer func() { _ = root.Close() }()
+		}
+	}
+	read := func(rel string) (string, bool) {
+		if root == nil || rel == "" {
+			return "", false
+		}
+		content, err := root.ReadFile(filepath.FromSlash(rel))
+		if err != nil {
+			return "", false
+		}
+		return string(content), true
+	}
+
+	composeName := "compose.yaml"
+	composePath := ""
+	if root != nil {
+		if detected, err := projects.DetectComposeFile(projectPath); err == nil && detected != "" {
+			composeName = filepath.Base(detected)
+			composePath = composeName
+		}
+	}
+	compose := syncDiffFile{path: composeName, after: source.compose, existsAfter: true, encrypted: source.composeEncrypted}
+	compose.before, compose.existedBefore = read(composePath)
+
+	env := syncDiffFile{path: ".env", encrypted: source.encrypted[".env"]}
+	if source.env != nil {
+		env.after, env.existsAfter = *source.env, true
+	}
+	env.before, env.existedBefore = read(".env")
+
+	paths := make([]string, 0, len(source.files)+len(previous))
+	seen := make(map[string]struct{}, len(source.files)+len(previous))
+	for path := range source.files {
+		paths = append(paths, path)
+		seen[path] = struct{}{}
+	}
+	for _, path := range previous {
+		if _, ok := seen[path]; !ok {
+			paths = append(paths, path)
+			seen[path] = struct{}{}
+		}
+	}
+	sort.Strings(paths)
+
+	files := []syncDiffFile{compose, env}
+	for _, path := range paths {
+		file := syncDiffFile{path: path, encrypted: source.encrypted[path]}
+		file.after, file.existsAfter = source.files[path]
+		file.before, file.existedBefore = read(path)
+		files = append(files, file)
+	}
+	return files
+}
+
+// unifiedFileDiff renders a git style unified diff for a single file.
+func unifiedFileDiff(path, before, after string, existedBefore, existsAfter bool) (string, error) {
+	fromFile, toFile := "a/"+path, "b/"+path
+	if !existedBefore {
+		fromFile = "/dev/null"
+	}
+	if !existsAfter {
+		toFile = "/dev/null"
+	}
+
+	header := fmt.Sprintf("diff --git a/%s b/%s\n", path, path)
+	if strings.ContainsRune(before, 0) || strings.ContainsRune(after, 0) {
+		return header + fmt.Sprintf("Binary files %s and %s differ\n", fromFile, toFile), nil
+	}
+
+	text, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitDiffLines(before),
+		B:        splitDiffLines(after),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+	if err != nil {
+		return "", err
+	}
+	return header + text, nil
+}
+
+// encryptedFileDiff renders the git style header of a changed file without its content.
+func encryptedFileDiff(path string, existedBefore, existsAfter bool) string {
+	fromFile, toFile := "a/"+path, "b/"+path
+	if !existedBefore {
+		fromFile = "/dev/null"
+	}
+	if !existsAfter {
+		toFile = "/dev/null"
+	}
+	return fmt.Sprintf("diff --git a/%s b/%s\nEncrypted files %s and %s differ\n", path, path, fromFile, toFile)
+}
+
+// splitDiffLines splits content into newline terminated lines. Unlike difflib.SplitLines
+// it doesn't add an empty line after a trailing newline.
+func splitDiffLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// CheckDrift compares a sync's project with the repository and with the containers it runs,
+// records the result on the sync and returns it.
+func (s *GitOpsSyncService) CheckDrift(ctx context.Context, environmentID, id string) (*gitops.DriftReport, error) {
+	sync, err := s.GetSyncByID(ctx, environmentID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.checkDriftInternal(ctx, sync)
+}
+
+// CheckDriftAll checks every GitOps-managed project that was synced successfully for drift.
+func (s *GitOpsSyncService) CheckDriftAll(ctx context.Context) error {
+	var managed []models.Project
+	if err := s.db.WithContext(ctx).
+		Where("gitops_managed_by IS NOT NULL AND gitops_managed_by <> ''").
+		Find(&managed).Error; err != nil {
+		return fmt.Errorf("failed to get GitOps-managed projects: %w", err)
+	}
+
+	for _, project := range managed {
+		var sync models.GitOpsSync
+		if err := s.db.WithContext(ctx).
+			Preload("Repository").
+			Preload("Project").
+			Where("id = ?", *project.GitOpsManagedBy).
+			First(&sync).Error; err != nil {
+			slog.WarnContext(ctx, "GitOps sync of managed project not found", "projectId", project.ID, "syncId", *project.GitOpsManagedBy, "error", err)
+			continue
+		}
+		// Only projects in a known good state can drift; failed syncs are reported as such.
+		if sync.LastSyncStatus == nil || (*sync.LastSyncStatus != "success" && *sync.LastSyncStatus != "drifted") {
+			continue
+		}
+
+		report, err := s.checkDriftInternal(ctx, &sync)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to check GitOps sync for drift", "syncId", sync.ID, "error", err)
+			continue
+		}
+		if report.Drifted {
+			slog.InfoContext(ctx, "GitOps drift detected", "syncId", sync.ID, "projectId", project.ID, "reasons", report.Reasons)
+		}
+	}
+
+	return nil
+}
+
+func (s *GitOpsSyncService) checkDriftInternal(ctx context.Context, sync *models.GitOpsSync) (*gitops.DriftReport, error) {
+	checkCtx, cancel := context.WithTimeout(ctx, defaultGitSyncTimeout)
+	defer cancel()
+
+	if sync.Project == nil {
+		return nil, &models.ValidationError{Message: "Sync has not deployed a project yet"}
+	}
+	repository := sync.Repository
+	if repository == nil {
+		return nil, fmt.Errorf("repository not found")
+	}
+
+	authConfig, err := s.repoService.GetAuthConfig(checkCtx, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	checkout, release, err := s.repoService.gitClient.Mirror(checkCtx, repository.URL, syncRefSelector(sync), authConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repository: %w", err)
+	}
+	defer release()
+
+	source, err := s.readSyncSourceInternal(checkCtx, checkout.Dir, sync)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compose files: %w", err)
+	}
+
+	report := &gitops.DriftReport{
+		Commit:     checkout.Commit,
+		Containers: []gitops.ContainerDrift{},
+		Reasons:    []string{},
+		CheckedAt:  time.Now(),
+	}
+
+	var filesModified bool
+	report.Files, filesModified, report.SourceChanged = syncFileDriftInternal(sync, source, checkout.Commit)
+	for _, file := range report.Files {
+		report.Reasons = append(report.Reasons, fmt.Sprintf("%s was %s outside of GitOps", file.Path, file.Status))
+	}
+	if filesModified && len(report.Files) == 0 {
+		report.Reasons = append(report.Reasons, "project files were modified outside of GitOps")
+	}
+
+	drifts, err := s.projectService.DetectContainerDrift(checkCtx, sync.Project.ID)
+	if err != nil {
+		slog.WarnContext(checkCtx, "Failed to check containers for drift", "syncId", sync.ID, "error", err)
+		report.Warnings = append(report.Warnings, fmt.Sprintf("Unable to check containers: %v", err))
+	}
+	for _, drift := range drifts {
+		report.Containers = append(report.Containers, gitops.ContainerDrift{
+			Service:   drift.Service,
+			Container: drift.Container,
+			Reasons:   drift.Reasons,
+		})
+		for _, reason := range drift.Reasons {
+			report.Reasons = append(report.Reasons, fmt.Sprintf("container %s: %s", drift.Container, reason))
+		}
+	}
+
+	report.Drifted = len(report.Reasons) > 0
+	s.recordDriftInternal(checkCtx, sync, report)
+	return report, nil
+}
+
+// syncFileDriftInternal compares the project files on disk with what the sync last applied.
+// It returns the changed files when the repository still holds the applied content, and
+// otherwise only whether the files differ from the applied content hash. sourceChanged
+// reports whether the repository moved on since the last sync.
+func syncFileDriftInternal(sync *models.GitOpsSync, source *syncSource, commit string) (files []gitops.SyncFileChange, modified, sourceChanged bool) {
+	files = []gitops.SyncFileChange{}
+	if sync.LastSyncHash != nil {
+		sourceChanged = *sync.LastSyncHash != syncContentHash(source.compose, source.env, source.files, source.modes)
+	} else {
+		sourceChanged = sync.LastSyncCommit == nil || *sync.LastSyncCommit != commit
+	}
+
+	if !sourceChanged {
+		for _, file := range syncDiffFilesInternal(sync.Project.Path, source, sync.SyncedFiles) {
+			if file.path == ".env" && !file.existsAfter {
+				// Syncs without a .env leave the project's own .env alone.
+				continue
+			}
+			if file.before == file.after && file.existedBefore == file.existsAfter {
+				continue
+			}
+
+			// before is the project on disk, after what the sync applied.
+			status := "modified"
+			switch {
+			case !file.existedBefore:
+				status = "removed"
+			case !file.existsAfter:
+				status = "added"
+			}
+			files = append(files, gitops.SyncFileChange{Path: file.path, Status: status})
+		}
+		return files, len(files) > 0, false
+	}
+
+	if sync.LastSyncHash == nil {
+		return files, false, true
+	}
+	disk := readProjectSyncSourceInternal(sync.Project.Path, sync.SyncedFiles)
+	modified = *sync.LastSyncHash != syncContentHash(disk.compose, nil, disk.files, disk.modes)
+	if modified && disk.env != nil {
+		// The hash covers .env only if the repository had one.
+		modified = *sync.LastSyncHash != syncContentHash(disk.compose, disk.env, disk.files, disk.modes)
+	}
+	return files, modified, true
+}
+
+// readProjectSyncSourceInternal reads the files a sync manages back from the project directory.
+func readProjectSyncSourceInternal(projectPath string, syncedFiles []string) *syncSource {
+	source := &syncSource{files: map[string]string{}, modes: map[string]fs.FileMode{}}
+	if composeFile, err := projects.DetectComposeFile(projectPath); err == nil && composeFile != "" {
+		if content, err := os.ReadFile(composeFile); err == nil {
+			source.compose = string(content)
+		}
+	}
+	if content, err := os.ReadFile(filepath.Join(projectPath, ".env")); err == nil {
+		env := string(content)
+		source.env = &env
+	}
+	for _, path := range syncedFiles {
+		validated, err := projects.ValidateIncludePathForWrite(projectPath, path)
+		if err != nil {
+			continue
+		}
+		if content, err := os.ReadFile(validated); err == nil {
+			source.files[path] = string(content)
+		}
+		if info, err := os.Stat(validated); err == nil {
+			source.modes[path] = info.Mode().Perm()
+		}
+	}
+	return source
+}
+
+// recordDriftInternal stores the result of a drift check on the sync. Newly detected drift
+// is logged as an event and sent as a notification; drift that went away restores the
+// success status.
+func (s *GitOpsSyncService) recordDriftInternal(ctx context.Context, sync *models.GitOpsSync, report *gitops.DriftReport) {
+	status := ""
+	if sync.LastSyncStatus != nil {
+		status = *sync.LastSyncStatus
+	}
+
+	updates := map[string]interface{}{
+		"drift_checked_at": report.CheckedAt,
+	}
+	switch {
+	case report.Drifted && (status == "success" || status == "drifted"):
+		updates["last_sync_status"] = "drifted"
+		updates["drift_reasons"] = models.StringSlice(report.Reasons)
+	case !report.Drifted && status == "drifted":
+		updates["last_sync_status"] = "success"
+		updates["drift_reasons"] = nil
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.GitOpsSync{}).Where("id = ?", sync.ID).Updates(updates).Error; err != nil {
+		slog.ErrorContext(ctx, "Failed to record drift check", "error", err, "syncId", sync.ID)
+		return
+	}
+
+	if !report.Drifted || status != "success" {
+		return
+	}
+
+	resourceType := "git_sync"
+	_, _ = s.eventService.CreateEvent(ctx, CreateEventRequest{
+		Type:          models.EventTypeGitSyncDrift,
+		Severity:      models.EventSeverityWarning,
+		Title:         "GitOps drift detected",
+		Description:   fmt.Sprintf("Project '%s' no longer matches sync '%s': %s", sync.Project.Name, sync.Name, strings.Join(report.Reasons, "; ")),
+		ResourceType:  &resourceType,
+		ResourceID:    &sync.ID,
+		ResourceName:  &sync.Name,
+		UserID:        &systemUser.ID,
+		Username:      &systemUser.Username,
+		EnvironmentID: &sync.EnvironmentID,
+		Metadata: models.JSON{
+			"projectId": sync.Project.ID,
+			"commit":    report.Commit,
+			"reasons":   report.Reasons,
+		},
+	})
+
+	if s.notificationService != nil {
+		payload := GitOpsDriftNotificationPayload{SyncName: sync.Name, ProjectName: sync.Project.Name, Reasons: report.Reasons}
+		if err := s.notificationService.SendGitOpsDriftNotification(ctx, payload); err != nil {
+			slog.WarnContext(ctx, "Failed to send GitOps drift notification", "syncId", sync.ID, "error", err)
+		}
+	}
+}
+
 func (s *GitOpsSyncService) ImportSyncs(ctx context.Context, environmentID string, req []gitops.ImportGitOpsSyncRequest) (*gitops.ImportGitOpsSyncResponse, error) {
 	response := &gitops.ImportGitOpsSyncResponse{
 		SuccessCount: 0,
@@ -555,12 +1478,12 @@ func (s *GitOpsSyncService) logSyncError(ctx context.Context, sync *models.GitOp
 func (s *GitOpsSyncService) failSync(ctx context.Context, id string, result *gitops.SyncResult, sync *models.GitOpsSync, message, errMsg string) error {
 	result.Message = message
 	result.Error = &errMsg
-	s.updateSyncStatus(ctx, id, "failed", errMsg, "")
+	s.updateSyncStatus(ctx, id, "failed", errMsg, nil, "")
 	s.logSyncError(ctx, sync, errMsg)
 	return fmt.Errorf("%s", errMsg)
 }
 
-func (s *GitOpsSyncService) createProjectForSyncInternal(ctx context.Context, sync *models.GitOpsSync, id string, composeContent string, envContent *string, result *gitops.SyncResult) (*models.Project, error) {
+func (s *GitOpsSyncService) createProjectForSyncInternal(ctx context.Context, sync *models.GitOpsSync, id string, composeContent string, envContent *string, files map[string]string, modes map[string]fs.FileMode, result *gitops.SyncResult) (*models.Project, error) {
 	project, err := s.projectService.CreateProject(ctx, sync.ProjectName, composeContent, envContent, systemUser)
 	if err != nil {
 		return nil, s.failSync(ctx, id, result, sync, "Failed to create project", err.Error())
@@ -578,6 +1501,10 @@ func (s *GitOpsSyncService) createProjectForSyncInternal(ctx context.Context, sy
 		return nil, s.failSync(ctx, id, result, sync, "Failed to mark project as GitOps-managed", err.Error())
 	}
 
+	if _, err := s.projectService.SyncProjectFiles(ctx, project.ID, files, modes, nil); err != nil {
+		return nil, s.failSync(ctx, id, result, sync, "Failed to write project files", err.Error())
+	}
+
 	slog.InfoContext(ctx, "Created project for GitOps sync", "projectName", sync.ProjectName, "projectId", project.ID)
 
 	// Deploy the project immediately after creation
@@ -589,7 +1516,7 @@ func (s *GitOpsSyncService) createProjectForSyncInternal(ctx context.Context, sy
 	return project, nil
 }
 
-func (s *GitOpsSyncService) getOrCreateProjectInternal(ctx context.Context, sync *models.GitOpsSync, id string, composeContent string, envContent *string, result *gitops.SyncResult) (*models.Project, error) {
+func (s *GitOpsSyncService) getOrCreateProjectInternal(ctx context.Context, sync *models.GitOpsSync, id string, composeContent string, envContent *string, files map[string]string, modes map[string]fs.FileMode, imagesBuilt bool, result *gitops.SyncResult) (*models.Project, error) {
 	var project *models.Project
 	var err error
 
@@ -602,16 +1529,16 @@ func (s *GitOpsSyncService) getOrCreateProjectInternal(ctx context.Context, sync
 	}
 
 	if project == nil {
-		return s.createProjectForSyncInternal(ctx, sync, id, composeContent, envContent, result)
+		return s.createProjectForSyncInternal(ctx, sync, id, composeContent, envContent, files, modes, result)
 	}
 
-	if err := s.updateProjectForSyncInternal(ctx, sync, id, project, composeContent, envContent, result); err != nil {
+	if err := s.updateProjectForSyncInternal(ctx, sync, id, project, composeContent, envContent, files, modes, imagesBuilt, result); err != nil {
 		return nil, err
 	}
 	return project, nil
 }
 
-func (s *GitOpsSyncService) updateProjectForSyncInternal(ctx context.Context, sync *models.GitOpsSync, id string, project *models.Project, composeContent string, envContent *string, result *gitops.SyncResult) error {
+func (s *GitOpsSyncService) updateProjectForSyncInternal(ctx context.Context, sync *models.GitOpsSync, id string, project *models.Project, composeContent string, envContent *string, files map[string]string, modes map[string]fs.FileMode, imagesBuilt bool, result *gitops.SyncResult) error {
 	// Get current content to see if it changed
 	oldCompose, oldEnv, _ := s.projectService.GetProjectContent(ctx, project.ID)
 	contentChanged := oldCompose != composeContent
@@ -628,10 +1555,19 @@ func (s *GitOpsSyncService) updateProjectForSyncInternal(ctx context.Context, sy
 	if err != nil {
 		return s.failSync(ctx, id, result, sync, "Failed to update project files", err.Error())
 	}
+
+	filesChanged, err := s.projectService.SyncProjectFiles(ctx, project.ID, files, modes, sync.SyncedFiles)
+	if err != nil {
+		return s.failSync(ctx, id, result, sync, "Failed to update project files", err.Error())
+	}
+	contentChanged = contentChanged || filesChanged
 	slog.InfoContext(ctx, "Updated project files", "projectName", project.Name, "projectId", project.ID)
 
-	// If content changed and project is running, redeploy
-	if contentChanged {
+	// If content changed and project is running, redeploy. A drifted project is redeployed
+	// too, so containers changed out of band are recreated from the compose file, and so is
+	// a project whose images were just rebuilt.
+	drifted := sync.LastSyncStatus != nil && *sync.LastSyncStatus == "drifted"
+	if contentChanged || drifted || imagesBuilt {
 		details, err := s.projectService.GetProjectDetails(ctx, project.ID)
 		if err == nil && (details.Status == string(models.ProjectStatusRunning) || details.Status == string(models.ProjectStatusPartiallyRunning)) {
 			slog.InfoContext(ctx, "Redeploying project due to content change from Git sync", "projectName", project.Name, "projectId", project.ID)
@@ -643,3 +1579,196 @@ func (s *GitOpsSyncService) updateProjectForSyncInternal(ctx context.Context, sy
 
 	return nil
 }
+
+// WebhookPath returns the public endpoint that receives push webhooks for a sync.
+func WebhookPath(syncID string) string {
+	return "/api/webhooks/gitops/" + syncID
+}
+
+// EnableWebhook enables the push webhook of a sync and stores a new secret. If secret is
+// empty a random one is generated. The plaintext secret is only returned here.
+func (s *GitOpsSyncService) EnableWebhook(ctx context.Context, environmentID, id, secret string) (*gitops.WebhookInfo, error) {
+	sync, err := s.GetSyncByID(ctx, environmentID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		bytes := make([]byte, webhookSecretLength)
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = hex.EncodeToString(bytes)
+	}
+
+	encrypted, err := crypto.Encrypt(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.GitOpsSync{}).Where("id = ?", sync.ID).Updates(map[string]interface{}{
+		"webhook_enabled": true,
+		"webhook_secret":  encrypted,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to enable webhook: %w", err)
+	}
+
+	resourceType := "git_sync"
+	_, _ = s.eventService.CreateEvent(ctx, CreateEventRequest{
+		Type:         models.EventTypeGitSyncUpdate,
+		Severity:     models.EventSeveritySuccess,
+		Title:        "Git sync webhook enabled",
+		Description:  fmt.Sprintf("Enabled push webhook for git sync '%s'", sync.Name),
+		ResourceType: &resourceType,
+		ResourceID:   &sync.ID,
+		ResourceName: &sync.Name,
+		UserID:       &systemUser.ID,
+		Username:     &systemUser.Username,
+	})
+
+	return &gitops.WebhookInfo{
+		Enabled: true,
+		Path:    WebhookPath(sync.ID),
+		Secret:  secret,
+	}, nil
+}
+
+// DisableWebhook disables the push webhook of a sync and discards its secret.
+func (s *GitOpsSyncService) DisableWebhook(ctx context.Context, environmentID, id string) error {
+	sync, err := s.GetSyncByID(ctx, environmentID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.GitOpsSync{}).Where("id = ?", sync.ID).Updates(map[string]interface{}{
+		"webhook_enabled": false,
+		"webhook_secret":  nil,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to disable webhook: %w", err)
+	}
+
+	resourceType := "git_sync"
+	_, _ = s.eventService.CreateEvent(ctx, CreateEventRequest{
+		Type:         models.EventTypeGitSyncUpdate,
+		Severity:     models.EventSeverityInfo,
+		Title:        "Git sync webhook disabled",
+		Description:  fmt.Sprintf("Disabled push webhook for git sync '%s'", sync.Name),
+		ResourceType: &resourceType,
+		ResourceID:   &sync.ID,
+		ResourceName: &sync.Name,
+		UserID:       &systemUser.ID,
+		Username:     &systemUser.Username,
+	})
+
+	return nil
+}
+
+// HandleWebhook verifies a webhook delivery for a sync and, when it is a push to the
+// sync's branch, starts the sync in the background.
+func (s *GitOpsSyncService) HandleWebhook(ctx context.Context, id string, headers git.WebhookHeaders, body []byte) (*gitops.WebhookResult, error) {
+	var sync models.GitOpsSync
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&sync).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.NewNotFoundError("webhook not found")
+		}
+		return nil, fmt.Errorf("failed to get sync: %w", err)
+	}
+
+	// Report disabled webhooks as missing so sync IDs can't be probed.
+	if !sync.WebhookEnabled || sync.WebhookSecret == "" {
+		return nil, models.NewNotFoundError("webhook not found")
+	}
+
+	secret, err := crypto.Decrypt(sync.WebhookSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt webhook secret: %w", err)
+	}
+
+	provider, err := git.VerifyWebhook(headers, body, secret)
+	if err != nil {
+		slog.WarnContext(ctx, "Rejected GitOps webhook delivery", "syncId", id, "provider", provider, "error", err)
+		return nil, models.NewAPIError(err.Error(), models.APIErrorCodeUnauthorized, http.StatusUnauthorized)
+	}
+
+	event, err := git.ParsePushEvent(provider, headers, body)
+	if err != nil {
+		return nil, &models.ValidationError{Message: err.Error(), Field: "body"}
+	}
+
+	result := &gitops.WebhookResult{
+		Provider: string(event.Provider),
+		Branch:   event.Branch,
+		Commit:   event.After,
+	}
+
+	switch {
+	case !event.IsPush:
+		result.Message = fmt.Sprintf("Ignored %q event", event.Event)
+		return result, nil
+	case !syncRefSelector(&sync).MatchesPush(event.Ref):
+		result.Message = fmt.Sprintf("Ignored push to %s, sync tracks %s", event.Ref, syncRefSelector(&sync))
+		return result, nil
+	}
+
+	result.Triggered = true
+	if !s.startWebhookSyncInternal(ctx, &sync, event) {
+		result.Message = fmt.Sprintf("Sync already in progress, queued another run for push to %s", event.Ref)
+		return result, nil
+	}
+
+	result.Message = fmt.Sprintf("Sync triggered by push to %s", event.Ref)
+	return result, nil
+}
+
+// startWebhookSyncInternal runs the sync in the background and reports whether it started.
+// A push that arrives while the sync is running queues one more run after it.
+func (s *GitOpsSyncService) startWebhookSyncInternal(ctx context.Context, sync *models.GitOpsSync, event *git.PushEvent) bool {
+	if !s.claimWebhookSyncInternal(sync.ID) {
+		slog.InfoContext(ctx, "GitOps sync already running, queued another run", "syncId", sync.ID, "ref", event.Ref, "commit", event.After)
+		return false
+	}
+
+	slog.InfoContext(ctx, "GitOps sync triggered by webhook", "syncId", sync.ID, "provider", event.Provider, "ref", event.Ref, "commit", event.After)
+
+	syncCtx := context.WithoutCancel(ctx)
+	go func() {
+		for {
+			if _, err := s.PerformSync(syncCtx, sync.EnvironmentID, sync.ID); err != nil {
+				slog.ErrorContext(syncCtx, "Webhook triggered sync failed", "syncId", sync.ID, "error", err)
+			}
+			if !s.finishWebhookSyncInternal(sync.ID) {
+				return
+			}
+			slog.InfoContext(syncCtx, "Re-running GitOps sync for pushes received during the last run", "syncId", sync.ID)
+		}
+	}()
+
+	return true
+}
+
+// claimWebhookSyncInternal marks the sync as running, or as needing a re-run when it already is.
+func (s *GitOpsSyncService) claimWebhookSyncInternal(id string) bool {
+	s.webhookMu.Lock()
+	defer s.webhookMu.Unlock()
+
+	if _, running := s.webhookSyncs[id]; running {
+		s.webhookSyncs[id] = true
+		return false
+	}
+	s.webhookSyncs[id] = false
+	return true
+}
+
+// finishWebhookSyncInternal reports whether the sync must run again, releasing it otherwise.
+func (s *GitOpsSyncService) finishWebhookSyncInternal(id string) bool {
+	s.webhookMu.Lock()
+	defer s.webhookMu.Unlock()
+
+	if s.webhookSyncs[id] {
+		s.webhookSyncs[id] = false
+		return true
+	}
+	delete(s.webhookSyncs, id)
+	return false
+}
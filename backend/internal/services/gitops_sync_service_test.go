@@ -0,0 +1,479 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	glsqlite "github.com/glebarez/sqlite"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/getarcaneapp/arcane/backend/internal/config"
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/crypto"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/git"
+	"github.com/getarcaneapp/arcane/types/gitops"
+)
+
+func setupGitOpsSyncTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := gorm.Open(glsqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.GitOpsSync{}))
+
+	crypto.InitEncryption(&config.Config{
+		EncryptionKey: "test-encryption-key-for-testing-32bytes-min",
+		Environment:   "test",
+	})
+
+	return &database.DB{DB: db}
+}
+
+func createWebhookTestSync(t *testing.T, db *database.DB, id string, enabled bool, secret string) {
+	t.Helper()
+	encrypted, err := crypto.Encrypt(secret)
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.GitOpsSync{
+		BaseModel:      models.BaseModel{ID: id},
+		Name:           id,
+		EnvironmentID:  "0",
+		RepositoryID:   "repo",
+		Branch:         "main",
+		ComposePath:    "compose.yaml",
+		ProjectName:    id,
+		WebhookEnabled: enabled,
+		WebhookSecret:  encrypted,
+	}).Error)
+}
+
+func githubWebhookHeaders(body []byte, secret, event string) git.WebhookHeaders {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return git.WebhookHeaders{
+		HubSignature256: "sha256=" + hex.EncodeToString(mac.Sum(nil)),
+		GitHubEvent:     event,
+	}
+}
+
+func TestGitOpsSyncService_HandleWebhook_Rejects(t *testing.T) {
+	db := setupGitOpsSyncTestDB(t)
+	svc := NewGitOpsSyncService(db, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	createWebhookTestSync(t, db, "enabled", true, "s3cret")
+	createWebhookTestSync(t, db, "disabled", false, "s3cret")
+
+	body := []byte(`{"ref":"refs/heads/main","after":"abc"}`)
+
+	_, err := svc.HandleWebhook(ctx, "missing", githubWebhookHeaders(body, "s3cret", "push"), body)
+	assert.Equal(t, http.StatusNotFound, models.ToAPIError(err).HTTPStatus())
+
+	_, err = svc.HandleWebhook(ctx, "disabled", githubWebhookHeaders(body, "s3cret", "push"), body)
+	assert.Equal(t, http.StatusNotFound, models.ToAPIError(err).HTTPStatus())
+
+	_, err = svc.HandleWebhook(ctx, "enabled", githubWebhookHeaders(body, "wrong", "push"), body)
+	assert.Equal(t, http.StatusUnauthorized, models.ToAPIError(err).HTTPStatus())
+
+	_, err = svc.HandleWebhook(ctx, "enabled", git.WebhookHeaders{GitHubEvent: "push"}, body)
+	assert.Equal(t, http.StatusUnauthorized, models.ToAPIError(err).HTTPStatus())
+}
+
+func TestGitOpsSyncService_HandleWebhook_IgnoresOtherRefs(t *testing.T) {
+	db := setupGitOpsSyncTestDB(t)
+	svc := NewGitOpsSyncService(db, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	createWebhookTestSync(t, db, "sync", true, "s3cret")
+
+	tests := []struct {
+		name  string
+		event string
+		body  string
+	}{
+		{name: "ping", event: "ping", body: `{"zen":"hi"}`},
+		{name: "other branch", event: "push", body: `{"ref":"refs/heads/develop","after":"abc"}`},
+		{name: "tag", event: "push", body: `{"ref":"refs/tags/main","after":"abc"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := []byte(tt.body)
+			result, err := svc.HandleWebhook(ctx, "sync", githubWebhookHeaders(body, "s3cret", tt.event), body)
+			require.NoError(t, err)
+			assert.False(t, result.Triggered)
+			assert.Equal(t, string(git.WebhookProviderGitHub), result.Provider)
+		})
+	}
+}
+
+func TestGitOpsSyncService_HandleWebhook_QueuesRerunDuringSync(t *testing.T) {
+	db := setupGitOpsSyncTestDB(t)
+	svc := NewGitOpsSyncService(db, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	createWebhookTestSync(t, db, "sync", true, "s3cret")
+	// Pretend a sync is already running so the push doesn't start one.
+	require.True(t, svc.claimWebhookSyncInternal("sync"))
+
+	body := []byte(`{"ref":"refs/heads/main","after":"abc"}`)
+	for range 2 {
+		result, err := svc.HandleWebhook(ctx, "sync", githubWebhookHeaders(body, "s3cret", "push"), body)
+		require.NoError(t, err)
+		assert.True(t, result.Triggered)
+		assert.Contains(t, result.Message, "queued")
+	}
+
+	// Both pushes collapse into a single re-run once the current sync finishes.
+	assert.True(t, svc.finishWebhookSyncInternal("sync"))
+	assert.False(t, svc.finishWebhookSyncInternal("sync"))
+	assert.Empty(t, svc.webhookSyncs)
+	assert.True(t, svc.claimWebhookSyncInternal("sync"))
+}
+
+func TestSyncRefSelector(t *testing.T) {
+	legacy := syncRefSelector(&models.GitOpsSync{Branch: "main"})
+	assert.Equal(t, git.BranchRef("main"), legacy)
+
+	semver := syncRefSelector(&models.GitOpsSync{Branch: "main", RefType: "semver", Ref: "^1.2"})
+	require.NoError(t, semver.Validate())
+	assert.True(t, semver.MatchesPush("refs/tags/v1.4.0"))
+	assert.False(t, semver.MatchesPush("refs/heads/main"))
+	assert.False(t, semver.SharesMirror(legacy))
+}
+
+func TestGitOpsSyncService_IsSyncUpToDate(t *testing.T) {
+	svc := &GitOpsSyncService{}
+	env := "FOO=bar"
+	hash := syncContentHash("services: {}", &env, nil, nil)
+	success := "success"
+	failed := "failed"
+
+	assert.NotEqual(t, hash, syncContentHash("services: {}", nil, nil, nil))
+	assert.NotEqual(t, hash, syncContentHash("services: {}FOO=bar", nil, nil, nil))
+
+	tests := []struct {
+		name string
+		sync models.GitOpsSync
+		want bool
+	}{
+		{
+			name: "same files",
+			sync: models.GitOpsSync{Project: &models.Project{}, LastSyncHash: &hash, LastSyncStatus: &success},
+			want: true,
+		},
+		{
+			name: "never synced",
+			sync: models.GitOpsSync{Project: &models.Project{}},
+			want: false,
+		},
+		{
+			name: "last sync failed",
+			sync: models.GitOpsSync{Project: &models.Project{}, LastSyncHash: &hash, LastSyncStatus: &failed},
+			want: false,
+		},
+		{
+			name: "project missing",
+			sync: models.GitOpsSync{LastSyncHash: &hash, LastSyncStatus: &success},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, svc.isSyncUpToDateInternal(&tt.sync, hash))
+		})
+	}
+
+	other := syncContentHash("services: {web: {}}", &env, nil, nil)
+	assert.False(t, svc.isSyncUpToDateInternal(&models.GitOpsSync{Project: &models.Project{}, LastSyncHash: &hash, LastSyncStatus: &success}, other))
+}
+
+func TestGitOpsSyncService_CollectSyncFiles(t *testing.T) {
+	repo := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(repo, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	write("stacks/web/compose.yaml", `
+include:
+  - extra.yaml
+services:
+  web:
+    image: nginx
+    env_file: web.env
+    volumes:
+      - ./html:/usr/share/nginx/html
+      - ../shared:/shared
+      - ./conf:/conf
+      - ./conf/passwd:/etc/passwd:ro
+      - ./entrypoint.sh:/entrypoint.sh:ro
+`)
+	write("stacks/web/.env", "FOO=bar\n")
+	write("stacks/web/extra.yaml", "services: {}\n")
+	write("stacks/web/web.env", "PORT=80\n")
+	write("stacks/web/html/index.html", "<h1>hi</h1>")
+	write("stacks/web/html/css/site.css", "body {}")
+	write("stacks/web/entrypoint.sh", "#!/bin/sh\n")
+	require.NoError(t, os.Chmod(filepath.Join(repo, "stacks/web/entrypoint.sh"), 0o755))
+	write("stacks/web/unrelated.txt", "not referenced")
+	write("stacks/shared/file.txt", "outside")
+	require.NoError(t, os.Symlink("/etc/hostname", filepath.Join(repo, "stacks/web/html/link")))
+	// A symlinked directory must not let references read outside the checkout.
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "passwd"), []byte("root:x:0:0"), 0o600))
+	require.NoError(t, os.Symlink(outside, filepath.Join(repo, "stacks/web/conf")))
+
+	svc := &GitOpsSyncService{}
+	files, modes, skipped, err := svc.collectSyncFilesInternal(context.Background(), repo, "stacks/web/compose.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"extra.yaml":                             "services: {}\n",
+		"web.env":                                "PORT=80\n",
+		filepath.Join("html", "index.html"):      "<h1>hi</h1>",
+		filepath.Join("html", "css", "site.css"): "body {}",
+		"entrypoint.sh":                          "#!/bin/sh\n",
+	}, files)
+	assert.Equal(t, os.FileMode(0o755), modes["entrypoint.sh"])
+	assert.Equal(t, os.FileMode(0o600), modes["web.env"])
+	assert.Equal(t, []string{filepath.Join("..", "shared"), "conf", filepath.Join("conf", "passwd")}, skipped)
+
+	env := "FOO=bar\n"
+	withFiles := syncContentHash("compose", &env, files, modes)
+	assert.NotEqual(t, syncContentHash("compose", &env, nil, nil), withFiles)
+	modes["entrypoint.sh"] = 0o644
+	assert.NotEqual(t, withFiles, syncContentHash("compose", &env, files, modes), "the executable bit is part of the hash")
+	modes["entrypoint.sh"] = 0o755
+	files["web.env"] = "PORT=8080\n"
+	assert.NotEqual(t, withFiles, syncContentHash("compose", &env, files, modes))
+}
+
+func TestGitOpsSyncService_SyncDiffFiles(t *testing.T) {
+	project := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(project, "compose.yaml"), []byte("services:\n  web:\n    image: nginx:1.25\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".env"), []byte("FOO=bar\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "old.env"), []byte("OLD=1\n"), 0o600))
+
+	env := "FOO=bar\n"
+	source := &syncSource{
+		compose: "services:\n  web:\n    image: nginx:1.27\n",
+		env:     &env,
+		files:   map[string]string{"web.env": "PORT=80\n"},
+	}
+
+	files := syncDiffFilesInternal(project, source, []string{"old.env"})
+	require.Len(t, files, 4)
+
+	assert.Equal(t, syncDiffFile{
+		path:          "compose.yaml",
+		before:        "services:\n  web:\n    image: nginx:1.25\n",
+		after:         source.compose,
+		existedBefore: true,
+		existsAfter:   true,
+	}, files[0])
+	assert.Equal(t, syncDiffFile{path: ".env", before: env, after: env, existedBefore: true, existsAfter: true}, files[1])
+	assert.Equal(t, syncDiffFile{path: "old.env", before: "OLD=1\n", existedBefore: true}, files[2])
+	assert.Equal(t, syncDiffFile{path: "web.env", after: "PORT=80\n", existsAfter: true}, files[3])
+
+	// Previously synced paths behind a symlinked directory don't leak outside files into the diff.
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.env"), []byte("TOKEN=1\n"), 0o600))
+	require.NoError(t, os.Symlink(outside, filepath.Join(project, "linked")))
+	files = syncDiffFilesInternal(project, source, []string{"linked/secret.env"})
+	require.Len(t, files, 4)
+	assert.Equal(t, syncDiffFile{path: "linked/secret.env"}, files[2])
+
+	newProject := syncDiffFilesInternal("", source, nil)
+	require.Len(t, newProject, 3)
+	assert.False(t, newProject[0].existedBefore)
+	assert.False(t, newProject[2].existedBefore)
+}
+
+func TestUnifiedFileDiff(t *testing.T) {
+	diff, err := unifiedFileDiff("compose.yaml", "services:\n  web:\n    image: nginx:1.25\n", "services:\n  web:\n    image: nginx:1.27\n", true, true)
+	require.NoError(t, err)
+	assert.Equal(t, `diff --git a/compose.yaml b/compose.yaml
+--- a/compose.yaml
++++ b/compose.yaml
+@@ -1,3 +1,3 @@
+ services:
+   web:
+-    image: nginx:1.25
++    image: nginx:1.27
+`, diff)
+
+	diff, err = unifiedFileDiff("web.env", "", "PORT=80\n", false, true)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "--- /dev/null\n+++ b/web.env\n")
+	assert.Contains(t, diff, "+PORT=80\n")
+
+	diff, err = unifiedFileDiff("logo.png", "\x00old", "\x00new", true, true)
+	require.NoError(t, err)
+	assert.Equal(t, "diff --git a/logo.png b/logo.png\nBinary files a/logo.png and b/logo.png differ\n", diff)
+}
+
+func TestGitOpsSyncService_DecryptSyncSource(t *testing.T) {
+	setupGitOpsSyncTestDB(t)
+
+	_, _, err := encryptSopsAgeKey("not a key")
+	var validationErr *models.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	encrypted, recipients, err := encryptSopsAgeKey(identity.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.StringSlice{identity.Recipient().String()}, recipients)
+	assert.NotContains(t, encrypted, identity.String())
+
+	env := "FOO=bar\n"
+	source := &syncSource{compose: "services: {}\n", env: &env, files: map[string]string{"web.env": "PORT=80\n"}}
+	require.NoError(t, decryptSyncSource(&models.GitOpsSync{ComposePath: "compose.yaml", SopsAgeKey: encrypted}, source))
+	assert.Equal(t, "FOO=bar\n", *source.env)
+	assert.Equal(t, "PORT=80\n", source.files["web.env"])
+
+	encryptedEnv := "FOO=ENC[AES256_GCM,data:AA==,iv:AA==,tag:AA==,type:str]\nsops_mac=ENC[AES256_GCM,data:AA==,iv:AA==,tag:AA==,type:str]\n"
+	source = &syncSource{compose: "services: {}\n", env: &encryptedEnv}
+	err = decryptSyncSource(&models.GitOpsSync{ComposePath: "compose.yaml"}, source)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no age key is configured")
+}
+
+func TestSyncFileDrift(t *testing.T) {
+	project := t.TempDir()
+	compose := "services:\n  web:\n    image: nginx:1.27\n"
+	require.NoError(t, os.WriteFile(filepath.Join(project, "compose.yaml"), []byte(compose), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "web.env"), []byte("PORT=80\n"), 0o600))
+
+	source := &syncSource{compose: compose, files: map[string]string{"web.env": "PORT=80\n"}}
+	hash := syncContentHash(source.compose, source.env, source.files, source.modes)
+	sync := &models.GitOpsSync{
+		LastSyncHash: &hash,
+		SyncedFiles:  models.StringSlice{"web.env"},
+		Project:      &models.Project{Path: project},
+	}
+
+	files, modified, sourceChanged := syncFileDriftInternal(sync, source, "abc")
+	assert.Empty(t, files)
+	assert.False(t, modified)
+	assert.False(t, sourceChanged)
+
+	require.NoError(t, os.WriteFile(filepath.Join(project, "compose.yaml"), []byte("services:\n  web:\n    image: nginx:1.25\n"), 0o600))
+	require.NoError(t, os.Remove(filepath.Join(project, "web.env")))
+
+	files, modified, sourceChanged = syncFileDriftInternal(sync, source, "abc")
+	assert.Equal(t, []gitops.SyncFileChange{
+		{Path: "compose.yaml", Status: "modified"},
+		{Path: "web.env", Status: "removed"},
+	}, files)
+	assert.True(t, modified)
+	assert.False(t, sourceChanged)
+
+	// Once the repository moves on only the applied content hash is compared.
+	files, modified, sourceChanged = syncFileDriftInternal(sync, &syncSource{compose: "services: {}\n"}, "def")
+	assert.Empty(t, files)
+	assert.True(t, modified)
+	assert.True(t, sourceChanged)
+}
+
+// sopsTestAgeKey and sopsTestEnv are an age key and a dotenv file encrypted for it with the
+// sops CLI (3.13.2). The plaintext is DB_PASSWORD=hunter2-plaintext and API_TOKEN=tok-very-secret.
+const sopsTestAgeKey = "AGE-SECRET-KEY-16N2Z0S8P3CCAN5RSSDNKJANFMGUM7AN9FKWKP4WNTNLFXUXCKEPS4GFEUS"
+
+const sopsTestEnv = "DB_PASSWORD=ENC[AES256_GCM,data:26t4zdUdprBet+B9KpK1gMQ=,iv:A50PJ2dcsDvTwpshus7zHeiw6yizvrmdWyGPcDUYq5s=,tag:dYVBXOe+LN5zke9ByLG6+A==,type:str]\n" +
+	"API_TOKEN=ENC[AES256_GCM,data:Azh/FTIH0FkwEU/YxbK9,iv:8pe8lpsq5Dg/J+4x0JLECgV5RYUlGJ8f7iDaKKon9Vc=,tag:I8BnDvzRDuRDNYK+CAEjhA==,type:str]\n" +
+	"sops_age__list_0__map_enc=-----BEGIN AGE ENCRYPTED FILE-----\\nYWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSAyYkEwVGpRNUlPZ3diSUkx\\nSStpUkVKNXpFRFZDNVBza1FXem9mR2Nac1d3CmlOcTZJekkvZC80STBkc0VpT2tI\\nMTZZaHBLdmpNOERRTng1SExPZGd2OUEKLS0tIDZiZTAxQkxmTWh4V0RZTndZTFZi\\nekI0aVErZms1L0VyM251dmRDTVMrcWcKJHO2vL2+F6omE/D84EI7YGHhu2cYrkRG\\n7W0Kt1X++1WLcIecPoGxOyMLrdP32HLslXDuAZyohqHfKl0W4qYkxg==\\n-----END AGE ENCRYPTED FILE-----\\n\n" +
+	"sops_age__list_0__map_recipient=age13n2vh2ter8pdrvanz2m7xl47p7yjldhekv3s9kawss9vrurn24nqtuvwxr\n" +
+	"sops_lastmodified=2026-10-16T22:26:11Z\n" +
+	"sops_mac=ENC[AES256_GCM,data:AXOV8XdvxOC7O6IQqU4CS5tkmnJW0mch2cvz1mJprL2wXaHVwv2C8s8mGpC0FNRAYPzrFQM2B9OxWSZNVeM9TD3X9YXL9xMHpR0Jv3rVF9s0DMSX6XRiOQYMek5E+MTB1XQhcseoKzsqvkINnYPNdtukT1WwAfya89XKUK7Uj2Q=,iv:hkcTN/kswinQOKUCtBXy6Q9HyupTOgJqfFvRLSKwYjE=,tag:yVU7/EkEntsenACiRcoPPw==,type:str]\n" +
+	"sops_unencrypted_suffix=_unencrypted\n" +
+	"sops_version=3.13.2\n"
+
+func TestGitOpsSyncService_DiffSync_HidesDecryptedSecrets(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	ctx := context.Background()
+	db := setupGitOpsSyncTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.GitRepository{}, &models.Project{}, &models.SettingVariable{}))
+	settingsService, err := NewSettingsService(ctx, db)
+	require.NoError(t, err)
+	root := t.TempDir()
+	require.NoError(t, settingsService.SetStringSetting(ctx, "projectsDirectory", root))
+
+	// The repository holds the encrypted .env; the project on disk the plaintext of the last sync.
+	repoDir := t.TempDir()
+	repo, err := gogit.PlainInitWithOptions(repoDir, &gogit.PlainInitOptions{
+		InitOptions: gogit.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "compose.yaml"), []byte("services:\n  web:\n    image: nginx:1.27\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, ".env"), []byte(sopsTestEnv), 0o600))
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, worktree.AddGlob("."))
+	_, err = worktree.Commit("init", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	projectDir := filepath.Join(root, "demo")
+	require.NoError(t, os.MkdirAll(projectDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "compose.yaml"), []byte("services:\n  web:\n    image: nginx:1.25\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".env"), []byte("DB_PASSWORD=old-plaintext\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "removed.env"), []byte("REMOVED_TOKEN=gone-plaintext\n"), 0o600))
+
+	ageKey, _, err := encryptSopsAgeKey(sopsTestAgeKey)
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.GitRepository{BaseModel: models.BaseModel{ID: "repo"}, Name: "repo", URL: repoDir}).Error)
+	require.NoError(t, db.Create(&models.Project{BaseModel: models.BaseModel{ID: "p1"}, Name: "demo", Path: projectDir}).Error)
+	projectID := "p1"
+	require.NoError(t, db.Create(&models.GitOpsSync{
+		BaseModel:     models.BaseModel{ID: "s1"},
+		Name:          "s1",
+		EnvironmentID: "0",
+		RepositoryID:  "repo",
+		Branch:        "main",
+		ComposePath:   "compose.yaml",
+		ProjectName:   "demo",
+		ProjectID:     &projectID,
+		SyncedFiles:   models.StringSlice{"removed.env"},
+		SopsAgeKey:    ageKey,
+	}).Error)
+
+	repoService := NewGitRepositoryService(db, t.TempDir(), nil, settingsService)
+	projectService := NewProjectService(db, settingsService, nil, nil, nil, nil)
+	svc := NewGitOpsSyncService(db, repoService, projectService, nil, nil, nil)
+
+	diff, err := svc.DiffSync(ctx, "0", "s1")
+	require.NoError(t, err)
+
+	assert.Contains(t, diff.Files, gitops.SyncFileChange{Path: ".env", Status: "modified"})
+	assert.Contains(t, diff.Files, gitops.SyncFileChange{Path: "removed.env", Status: "removed"})
+	assert.Contains(t, diff.Diff, "+    image: nginx:1.27\n")
+	assert.Contains(t, diff.Diff, "Encrypted files a/.env and b/.env differ\n")
+
+	out, err := json.Marshal(diff)
+	require.NoError(t, err)
+	for _, secret := range []string{"hunter2-plaintext", "tok-very-secret", "old-plaintext", "gone-plaintext"} {
+		assert.NotContains(t, string(out), secret)
+	}
+}
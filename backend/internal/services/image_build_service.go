@@ -0,0 +1,850 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	composetypes "github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/build"
+	"github.com/docker/docker/client"
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/docker"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/fs"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/git"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/mapper"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/pagination"
+	"github.com/getarcaneapp/arcane/backend/pkg/projects"
+	"github.com/getarcaneapp/arcane/types/imagebuild"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxBuildLogSize is how much of the plain text build output is stored with a build.
+	maxBuildLogSize = 256 << 10
+	// maxBuildBacklog bounds the progress events replayed to clients that attach to a running build.
+	maxBuildBacklog = 10000
+	// buildRunRetention keeps the progress of a finished build in memory for clients that attach late.
+	buildRunRetention = 10 * time.Minute
+)
+
+// ImageBuildService builds images from project directories and git repositories and
+// streams the build progress to subscribers.
+type ImageBuildService struct {
+	db              *database.DB
+	dockerService   *DockerClientService
+	repoService     *GitRepositoryService
+	settingsService *SettingsService
+	eventService    *EventService
+
+	runsMu sync.Mutex
+	runs   map[string]*buildRun
+}
+
+// ComposeBuildOptions describes a build of the services of a compose project.
+type ComposeBuildOptions struct {
+	ProjectID    string
+	RepositoryID string
+	GitOpsSyncID string
+	Ref          string
+	Commit       string
+	Trigger      string
+	// Services limits the build to these services; all services with a build section are built when empty.
+	Services []string
+}
+
+// buildTarget is a single image to build.
+type buildTarget struct {
+	service    string
+	contextDir string
+	options    build.ImageBuildOptions
+}
+
+// prepareBuildFunc resolves the targets of a build once it runs. The returned cleanup, if any,
+// is called after the targets have been built.
+type prepareBuildFunc func(ctx context.Context, record *models.ImageBuild) ([]buildTarget, func(), error)
+
+// buildRun is the in-memory state of a build: its progress backlog and live subscribers.
+type buildRun struct {
+	id     string
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	backlog     []imagebuild.ProgressEvent
+	subscribers map[chan imagebuild.ProgressEvent]struct{}
+	startedStep map[string]struct{}
+	log         strings.Builder
+	done        bool
+}
+
+func NewImageBuildService(db *database.DB, dockerService *DockerClientService, repoService *GitRepositoryService, settingsService *SettingsService, eventService *EventService) *ImageBuildService {
+	return &ImageBuildService{
+		db:              db,
+		dockerService:   dockerService,
+		repoService:     repoService,
+		settingsService: settingsService,
+		eventService:    eventService,
+		runs:            make(map[string]*buildRun),
+	}
+}
+
+func (s *ImageBuildService) ListBuildsPaginated(ctx context.Context, params pagination.QueryParams) ([]imagebuild.Build, pagination.Response, error) {
+	var builds []models.ImageBuild
+	q := s.db.WithContext(ctx).Model(&models.ImageBuild{})
+
+	if term := strings.TrimSpace(params.Search); term != "" {
+		searchPattern := "%" + term + "%"
+		q = q.Where("tags LIKE ? OR services LIKE ? OR context_path LIKE ? OR ref LIKE ?",
+			searchPattern, searchPattern, searchPattern, searchPattern)
+	}
+
+	q = pagination.ApplyFilter(q, "status", params.Filters["status"])
+	q = pagination.ApplyFilter(q, "trigger", params.Filters["trigger"])
+	q = pagination.ApplyFilter(q, "project_id", params.Filters["projectId"])
+	q = pagination.ApplyFilter(q, "repository_id", params.Filters["repositoryId"])
+
+	if params.Sort == "" {
+		q = q.Order("started_at DESC")
+	}
+
+	paginationResp, err := pagination.PaginateAndSortDB(params, q, &builds)
+	if err != nil {
+		return nil, pagination.Response{}, fmt.Errorf("failed to paginate image builds: %w", err)
+	}
+
+	out, mapErr := mapper.MapSlice[models.ImageBuild, imagebuild.Build](builds)
+	if mapErr != nil {
+		return nil, pagination.Response{}, fmt.Errorf("failed to map image builds: %w", mapErr)
+	}
+
+	return out, paginationResp, nil
+}
+
+func (s *ImageBuildService) GetBuildByID(ctx context.Context, id string) (*models.ImageBuild, error) {
+	var b models.ImageBuild
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&b).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &models.NotFoundError{Message: "Image build not found"}
+		}
+		return nil, fmt.Errorf("failed to get image build: %w", err)
+	}
+
+	if b.Status == imagebuild.StatusRunning && s.getRunInternal(b.ID) == nil {
+		// The process that ran the build is gone, e.g. after a restart.
+		s.markInterruptedInternal(ctx, &b)
+	}
+	return &b, nil
+}
+
+// StartBuild validates the request, records the build and runs it in the background.
+// Progress is available through Subscribe while it runs.
+func (s *ImageBuildService) StartBuild(ctx context.Context, req imagebuild.BuildRequest, user models.User) (*models.ImageBuild, error) {
+	record, prepare, err := s.prepareRequestInternal(ctx, req, user)
+	if err != nil {
+		return nil, err
+	}
+
+	run, runCtx, err := s.beginInternal(context.WithoutCancel(ctx), record)
+	if err != nil {
+		return nil, err
+	}
+
+	started := *record
+	go func() {
+		_ = s.executeInternal(runCtx, run, record, user, prepare)
+	}()
+	return &started, nil
+}
+
+// BuildComposeProject builds the services of a loaded compose project that have a build section
+// and waits for the build to finish. It returns nil without building when no service needs it.
+func (s *ImageBuildService) BuildComposeProject(ctx context.Context, proj *composetypes.Project, opts ComposeBuildOptions, user models.User) (*models.ImageBuild, error) {
+	targets, err := composeBuildTargetsInternal(proj, imagebuild.BuildRequest{Services: opts.Services}, proj.WorkingDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	record := newImageBuildRecordInternal(imagebuild.SourceProject, opts.Trigger, user)
+	if opts.RepositoryID != "" {
+		record.Source = imagebuild.SourceGit
+		record.RepositoryID = &opts.RepositoryID
+		record.Ref = opts.Ref
+	}
+	if opts.ProjectID != "" {
+		record.ProjectID = &opts.ProjectID
+	}
+	if opts.GitOpsSyncID != "" {
+		record.GitOpsSyncID = &opts.GitOpsSyncID
+	}
+	if opts.Commit != "" {
+		record.Commit = &opts.Commit
+	}
+
+	run, runCtx, err := s.beginInternal(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.executeInternal(runCtx, run, record, user, func(context.Context, *models.ImageBuild) ([]buildTarget, func(), error) {
+		return targets, nil, nil
+	})
+	return record, err
+}
+
+// BuildComposeFile loads a compose file and builds its services like BuildComposeProject.
+func (s *ImageBuildService) BuildComposeFile(ctx context.Context, composeFile, projectName string, opts ComposeBuildOptions, user models.User) (*models.ImageBuild, error) {
+	proj, err := s.loadComposeProjectInternal(ctx, composeFile, projectName)
+	if err != nil {
+		return nil, err
+	}
+	return s.BuildComposeProject(ctx, proj, opts, user)
+}
+
+// CancelBuild stops a running build.
+func (s *ImageBuildService) CancelBuild(ctx context.Context, id string) error {
+	if run := s.getRunInternal(id); run != nil {
+		run.mu.Lock()
+		done := run.done
+		run.mu.Unlock()
+		if !done {
+			run.cancel()
+			return nil
+		}
+	}
+
+	b, err := s.GetBuildByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if b.Status != imagebuild.StatusRunning {
+		return &models.ConflictError{Message: "Image build is not running"}
+	}
+	return nil
+}
+
+// Subscribe returns the progress of a build so far and a channel with its live progress,
+// which is closed when the build finishes. For builds that already finished the backlog
+// only holds their result. The returned function unsubscribes.
+func (s *ImageBuildService) Subscribe(ctx context.Context, id string) ([]imagebuild.ProgressEvent, <-chan imagebuild.ProgressEvent, func(), error) {
+	if run := s.getRunInternal(id); run != nil {
+		backlog, ch, unsubscribe := run.subscribe()
+		return backlog, ch, unsubscribe, nil
+	}
+
+	b, err := s.GetBuildByID(ctx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ch := make(chan imagebuild.ProgressEvent)
+	close(ch)
+	return []imagebuild.ProgressEvent{buildResultEventInternal(b)}, ch, func() {}, nil
+}
+
+func (s *ImageBuildService) prepareRequestInternal(ctx context.Context, req imagebuild.BuildRequest, user models.User) (*models.ImageBuild, prepareBuildFunc, error) {
+	req.ProjectID = strings.TrimSpace(req.ProjectID)
+	req.RepositoryID = strings.TrimSpace(req.RepositoryID)
+	req.ContextPath = strings.TrimSpace(req.ContextPath)
+	req.ComposePath = strings.TrimSpace(req.ComposePath)
+
+	if (req.ProjectID == "") == (req.RepositoryID == "") {
+		return nil, nil, &models.ValidationError{Message: "Either projectId or repositoryId is required", Field: "projectId"}
+	}
+	if req.ContextPath != "" {
+		if len(req.Tags) == 0 {
+			return nil, nil, &models.ValidationError{Message: "Tags are required when building a context directory", Field: "tags"}
+		}
+		if !filepath.IsLocal(req.ContextPath) {
+			return nil, nil, &models.ValidationError{Message: "Context path must be relative to the project or repository", Field: "contextPath"}
+		}
+	}
+	if req.ComposePath != "" {
+		if !filepath.IsLocal(req.ComposePath) {
+			return nil, nil, &models.ValidationError{Message: "Compose path must be relative to the repository", Field: "composePath"}
+		}
+	}
+
+	if req.ProjectID != "" {
+		var proj models.Project
+		if err := s.db.WithContext(ctx).Where("id = ?", req.ProjectID).First(&proj).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, &models.NotFoundError{Message: "Project not found"}
+			}
+			return nil, nil, fmt.Errorf("failed to get project: %w", err)
+		}
+
+		record := newImageBuildRecordInternal(imagebuild.SourceProject, imagebuild.TriggerManual, user)
+		record.ProjectID = &proj.ID
+		record.ContextPath = req.ContextPath
+		return record, s.prepareProjectInternal(proj, req), nil
+	}
+
+	if req.ContextPath == "" && req.ComposePath == "" {
+		return nil, nil, &models.ValidationError{Message: "Either composePath or contextPath is required to build from a repository", Field: "composePath"}
+	}
+	repo, err := s.repoService.GetRepositoryByID(ctx, req.RepositoryID)
+	if err != nil {
+		return nil, nil, &models.NotFoundError{Message: "Repository not found"}
+	}
+	auth, err := s.repoService.GetAuthConfig(ctx, repo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get repository credentials: %w", err)
+	}
+
+	record := newImageBuildRecordInternal(imagebuild.SourceGit, imagebuild.TriggerManual, user)
+	record.RepositoryID = &repo.ID
+	record.Ref = req.Ref
+	record.ContextPath = req.ContextPath
+	return record, s.prepareRepositoryInternal(repo.URL, auth, req), nil
+}
+
+func (s *ImageBuildService) prepareProjectInternal(proj models.Project, req imagebuild.BuildRequest) prepareBuildFunc {
+	return func(ctx context.Context, record *models.ImageBuild) ([]buildTarget, func(), error) {
+		if req.ContextPath != "" {
+			dir, err := resolveBuildPathInternal(proj.Path, filepath.Join(proj.Path, req.ContextPath))
+			if err != nil {
+				return nil, nil, fmt.Errorf("build context: %w", err)
+			}
+			return []buildTarget{directoryBuildTargetInternal(dir, req)}, nil, nil
+		}
+
+		composeFile, err := projects.DetectComposeFile(proj.Path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("no compose file found in project directory: %s", proj.Path)
+		}
+		compProj, err := s.loadComposeProjectInternal(ctx, composeFile, proj.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		targets, err := composeBuildTargetsInternal(compProj, req, proj.Path)
+		return targets, nil, err
+	}
+}
+
+func (s *ImageBuildService) prepareRepositoryInternal(url string, auth git.AuthConfig, req imagebuild.BuildRequest) prepareBuildFunc {
+	return func(ctx context.Context, record *models.ImageBuild) ([]buildTarget, func(), error) {
+		gitClient := s.repoService.gitClient
+		repoPath, err := gitClient.Clone(ctx, url, req.Ref, auth)
+		if err != nil {
+			return nil, nil, err
+		}
+		cleanup := func() {
+			if cerr := gitClient.Cleanup(repoPath); cerr != nil {
+				slog.Warn("failed to clean up build checkout", "path", repoPath, "error", cerr)
+			}
+		}
+
+		if commit, cerr := gitClient.GetCurrentCommit(ctx, repoPath); cerr == nil {
+			record.Commit = &commit
+		}
+
+		if req.ContextPath != "" {
+			dir, err := resolveBuildPathInternal(repoPath, filepath.Join(repoPath, req.ContextPath))
+			if err != nil {
+				return nil, cleanup, fmt.Errorf("build context: %w", err)
+			}
+			return []buildTarget{directoryBuildTargetInternal(dir, req)}, cleanup, nil
+		}
+
+		compProj, err := s.loadComposeProjectInternal(ctx, filepath.Join(repoPath, req.ComposePath), filepath.Base(filepath.Dir(filepath.Join(repoPath, req.ComposePath))))
+		if err != nil {
+			return nil, cleanup, err
+		}
+		targets, err := composeBuildTargetsInternal(compProj, req, repoPath)
+		return targets, cleanup, err
+	}
+}
+
+func (s *ImageBuildService) loadComposeProjectInternal(ctx context.Context, composeFile, projectName string) (*composetypes.Project, error) {
+	projectsDirSetting := s.settingsService.GetStringSetting(ctx, "projectsDirectory", "/app/data/projects")
+	projectsDirectory, pdErr := fs.GetProjectsDirectory(ctx, strings.TrimSpace(projectsDirSetting))
+	if pdErr != nil {
+		slog.WarnContext(ctx, "unable to determine projects directory; using default", "error", pdErr)
+		projectsDirectory = "/app/data/projects"
+	}
+
+	// Build contexts are read by Arcane itself, so paths are not translated to host paths.
+	autoInjectEnv := s.settingsService.GetBoolSetting(ctx, "autoInjectEnv", false)
+	proj, err := projects.LoadComposeProject(ctx, composeFile, normalizeComposeProjectName(projectName), projectsDirectory, autoInjectEnv, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load compose project from %s: %w", composeFile, err)
+	}
+	return proj, nil
+}
+
+// beginInternal records a running build and registers its in-memory state.
+func (s *ImageBuildService) beginInternal(ctx context.Context, record *models.ImageBuild) (*buildRun, context.Context, error) {
+	record.Status = imagebuild.StatusRunning
+	record.StartedAt = time.Now()
+	if err := s.db.WithContext(ctx).Create(record).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to create image build: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	run := &buildRun{
+		id:          record.ID,
+		cancel:      cancel,
+		subscribers: make(map[chan imagebuild.ProgressEvent]struct{}),
+		startedStep: make(map[string]struct{}),
+	}
+
+	s.runsMu.Lock()
+	s.runs[record.ID] = run
+	s.runsMu.Unlock()
+
+	return run, runCtx, nil
+}
+
+func (s *ImageBuildService) executeInternal(ctx context.Context, run *buildRun, record *models.ImageBuild, user models.User, prepare prepareBuildFunc) error {
+	defer run.cancel()
+
+	targets, cleanup, err := prepare(ctx, record)
+	if cleanup != nil {
+		defer cleanup()
+	}
+	if err == nil && len(targets) == 0 {
+		err = errors.New("no services with a build section to build")
+	}
+	if err == nil {
+		record.Services, record.Tags = nil, nil
+		for _, t := range targets {
+			if t.service != "" {
+				record.Services = append(record.Services, t.service)
+			}
+			record.Tags = append(record.Tags, t.options.Tags...)
+		}
+		if len(targets) == 1 {
+			record.Target = targets[0].options.Target
+		}
+		err = s.buildTargetsInternal(ctx, run, targets)
+	}
+
+	s.finishInternal(ctx, run, record, user, err)
+	return err
+}
+
+func (s *ImageBuildService) buildTargetsInternal(ctx context.Context, run *buildRun, targets []buildTarget) error {
+	dockerClient, err := s.dockerService.GetClient()
+	if err != nil {
+		return fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+
+	for _, t := range targets {
+		label := t.service
+		if label == "" {
+			label = strings.Join(t.options.Tags, ", ")
+		}
+		run.emit(imagebuild.ProgressEvent{Type: imagebuild.EventLog, Service: t.service, Message: "Building " + label})
+
+		imageID, err := s.buildImageInternal(ctx, dockerClient, run, t)
+		if err != nil {
+			return fmt.Errorf("failed to build %s: %w", label, err)
+		}
+		run.emit(imagebuild.ProgressEvent{Type: imagebuild.EventResult, Service: t.service, ImageID: imageID})
+	}
+	return nil
+}
+
+func (s *ImageBuildService) buildImageInternal(ctx context.Context, dockerClient *client.Client, run *buildRun, t buildTarget) (string, error) {
+	opts := t.options
+	opts.Version = build.BuilderBuildKit
+
+	resp, err := imageBuildInternal(ctx, dockerClient, t.contextDir, opts)
+	if err != nil && buildKitUnavailableInternal(err) {
+		slog.InfoContext(ctx, "BuildKit is not available, falling back to the classic builder", "error", err)
+		opts.Version = build.BuilderV1
+		resp, err = imageBuildInternal(ctx, dockerClient, t.contextDir, opts)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	imageID, err := docker.DecodeBuildStream(resp.Body, func(ev imagebuild.ProgressEvent) {
+		ev.Service = t.service
+		run.emit(ev)
+	})
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	return imageID, err
+}
+
+func imageBuildInternal(ctx context.Context, dockerClient *client.Client, contextDir string, opts build.ImageBuildOptions) (build.ImageBuildResponse, error) {
+	var buildContext io.ReadCloser
+	if opts.RemoteContext == "" {
+		tarball, err := docker.BuildContextTar(contextDir, opts.Dockerfile)
+		if err != nil {
+			return build.ImageBuildResponse{}, err
+		}
+		defer tarball.Close()
+		buildContext = tarball
+	}
+
+	return dockerClient.ImageBuild(ctx, buildContext, opts)
+}
+
+// buildKitUnavailableInternal reports whether the daemon rejected a BuildKit build, either because
+// BuildKit is disabled or because it requires a client session.
+func buildKitUnavailableInternal(err error) bool {
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "buildkit") && (strings.Contains(msg, "not supported") || strings.Contains(msg, "not enabled")) {
+		return true
+	}
+	return strings.Contains(msg, "session") && strings.Contains(msg, "required")
+}
+
+func (s *ImageBuildService) finishInternal(ctx context.Context, run *buildRun, record *models.ImageBuild, user models.User, buildErr error) {
+	now := time.Now()
+	record.FinishedAt = &now
+	record.Status = imagebuild.StatusSucceeded
+	record.Error = nil
+	switch {
+	case buildErr != nil && errors.Is(ctx.Err(), context.Canceled):
+		record.Status = imagebuild.StatusCanceled
+		msg := "Build canceled"
+		record.Error = &msg
+	case buildErr != nil:
+		record.Status = imagebuild.StatusFailed
+		msg := buildErr.Error()
+		record.Error = &msg
+	}
+
+	result := imagebuild.ProgressEvent{Type: imagebuild.EventResult, Status: record.Status}
+	if record.Error != nil {
+		result.Error = *record.Error
+	}
+	run.emit(result)
+	record.Log = run.finish()
+
+	saveCtx := context.WithoutCancel(ctx)
+	if err := s.db.WithContext(saveCtx).Save(record).Error; err != nil {
+		slog.ErrorContext(saveCtx, "failed to save image build", "buildID", record.ID, "error", err)
+	}
+
+	if s.eventService != nil {
+		metadata := models.JSON{
+			"action":   "build",
+			"buildId":  record.ID,
+			"status":   record.Status,
+			"trigger":  record.Trigger,
+			"services": []string(record.Services),
+		}
+		if record.Error != nil {
+			metadata["error"] = *record.Error
+		}
+		if logErr := s.eventService.LogImageEvent(saveCtx, models.EventTypeImageBuild, "", strings.Join(record.Tags, ", "), user.ID, user.Username, "0", metadata); logErr != nil {
+			slog.WarnContext(saveCtx, "could not log image build action", "error", logErr)
+		}
+	}
+
+	time.AfterFunc(buildRunRetention, func() {
+		s.runsMu.Lock()
+		delete(s.runs, record.ID)
+		s.runsMu.Unlock()
+	})
+}
+
+func (s *ImageBuildService) markInterruptedInternal(ctx context.Context, b *models.ImageBuild) {
+	now := time.Now()
+	msg := "Build was interrupted"
+	b.Status = imagebuild.StatusFailed
+	b.Error = &msg
+	b.FinishedAt = &now
+	if err := s.db.WithContext(ctx).Model(b).Updates(map[string]any{"status": b.Status, "error": msg, "finished_at": now}).Error; err != nil {
+		slog.WarnContext(ctx, "failed to mark interrupted image build", "buildID", b.ID, "error", err)
+	}
+}
+
+func (s *ImageBuildService) getRunInternal(id string) *buildRun {
+	s.runsMu.Lock()
+	defer s.runsMu.Unlock()
+	return s.runs[id]
+}
+
+func newImageBuildRecordInternal(source, trigger string, user models.User) *models.ImageBuild {
+	record := &models.ImageBuild{Source: source, Trigger: trigger}
+	if user.ID != "" {
+		record.UserID = &user.ID
+		record.Username = &user.Username
+	}
+	return record
+}
+
+func buildResultEventInternal(b *models.ImageBuild) imagebuild.ProgressEvent {
+	ev := imagebuild.ProgressEvent{BuildID: b.ID, Type: imagebuild.EventResult, Status: b.Status, Timestamp: b.StartedAt}
+	if b.FinishedAt != nil {
+		ev.Timestamp = *b.FinishedAt
+	}
+	if b.Error != nil {
+		ev.Error = *b.Error
+	}
+	return ev
+}
+
+// directoryBuildTargetInternal builds a plain context directory, tagged with the request tags.
+func directoryBuildTargetInternal(dir string, req imagebuild.BuildRequest) buildTarget {
+	opts := build.ImageBuildOptions{
+		Tags:        req.Tags,
+		Dockerfile:  req.Dockerfile,
+		BuildArgs:   map[string]*string{},
+		Target:      req.Target,
+		CacheFrom:   req.CacheFrom,
+		NoCache:     req.NoCache,
+		PullParent:  req.Pull,
+		Platform:    req.Platform,
+		Remove:      true,
+		ForceRemove: true,
+	}
+	for k, v := range req.BuildArgs {
+		opts.BuildArgs[k] = &v
+	}
+	return buildTarget{contextDir: dir, options: opts}
+}
+
+// composeBuildTargetsInternal turns the services of a compose project that have a build section
+// into build targets. Options of the request override those of the services. Local build
+// contexts and Dockerfiles must resolve to paths inside root, the checkout or project
+// directory, as the compose file may come from a repository.
+func composeBuildTargetsInternal(proj *composetypes.Project, req imagebuild.BuildRequest, root string) ([]buildTarget, error) {
+	for _, name := range req.Services {
+		svc, ok := proj.Services[name]
+		if !ok {
+			return nil, &models.ValidationError{Message: fmt.Sprintf("Service %s not found in compose project", name), Field: "services"}
+		}
+		if svc.Build == nil {
+			return nil, &models.ValidationError{Message: fmt.Sprintf("Service %s has no build section", name), Field: "services"}
+		}
+	}
+
+	names := make([]string, 0, len(proj.Services))
+	for name, svc := range proj.Services {
+		if svc.Build == nil || (len(req.Services) > 0 && !slices.Contains(req.Services, name)) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	targets := make([]buildTarget, 0, len(names))
+	for _, name := range names {
+		svc := proj.Services[name]
+		b := svc.Build
+		if b.DockerfileInline != "" {
+			return nil, fmt.Errorf("service %s: inline Dockerfiles are not supported", name)
+		}
+
+		imageName := strings.TrimSpace(svc.Image)
+		if imageName == "" {
+			// Same default compose uses for services without an image.
+			imageName = proj.Name + "-" + name
+		}
+
+		opts := build.ImageBuildOptions{
+			Tags:        append([]string{imageName}, b.Tags...),
+			Dockerfile:  b.Dockerfile,
+			BuildArgs:   map[string]*string{},
+			Labels:      b.Labels,
+			Target:      b.Target,
+			CacheFrom:   append(slices.Clone([]string(b.CacheFrom)), req.CacheFrom...),
+			NoCache:     b.NoCache || req.NoCache,
+			PullParent:  b.Pull || req.Pull,
+			NetworkMode: b.Network,
+			ShmSize:     int64(b.ShmSize),
+			ExtraHosts:  b.ExtraHosts.AsList(":"),
+			Platform:    req.Platform,
+			Remove:      true,
+			ForceRemove: true,
+		}
+		for k, v := range b.Args {
+			opts.BuildArgs[k] = v
+		}
+		for k, v := range req.BuildArgs {
+			opts.BuildArgs[k] = &v
+		}
+		if req.Target != "" {
+			opts.Target = req.Target
+		}
+		if opts.Platform == "" {
+			if len(b.Platforms) == 1 {
+				opts.Platform = b.Platforms[0]
+			} else {
+				opts.Platform = svc.Platform
+			}
+		}
+
+		t := buildTarget{service: name, options: opts}
+		if strings.Contains(b.Context, "://") || strings.HasPrefix(b.Context, "git@") {
+			t.options.RemoteContext = b.Context
+		} else {
+			t.contextDir = b.Context
+			if t.contextDir == "" {
+				t.contextDir = proj.WorkingDir
+			}
+			if !filepath.IsAbs(t.contextDir) {
+				t.contextDir = filepath.Join(proj.WorkingDir, t.contextDir)
+			}
+			contextDir, err := resolveBuildPathInternal(root, t.contextDir)
+			if err != nil {
+				return nil, fmt.Errorf("service %s: build context: %w", name, err)
+			}
+			t.contextDir = contextDir
+			if filepath.IsAbs(t.options.Dockerfile) {
+				dockerfile, err := resolveBuildPathInternal(root, t.options.Dockerfile)
+				if err != nil {
+					return nil, fmt.Errorf("service %s: dockerfile: %w", name, err)
+				}
+				rel, err := filepath.Rel(t.contextDir, dockerfile)
+				if err != nil {
+					return nil, fmt.Errorf("service %s: %w", name, err)
+				}
+				t.options.Dockerfile = rel
+			}
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// resolveBuildPathInternal resolves the symlinks of path and returns the result, as long as it
+// is root or inside it.
+func resolveBuildPathInternal(root, path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	inside, err := pathResolvesUnderInternal(root, resolved)
+	if err != nil {
+		return "", err
+	}
+	if !inside {
+		return "", fmt.Errorf("%s is outside of %s", path, root)
+	}
+	return resolved, nil
+}
+
+// emit records a progress event and passes it to the subscribers. Subscribers that can't
+// keep up miss events rather than stalling the build.
+func (r *buildRun) emit(ev imagebuild.ProgressEvent) {
+	ev.BuildID = r.id
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		return
+	}
+
+	r.appendLogInternal(ev)
+	if len(r.backlog) >= maxBuildBacklog {
+		r.backlog = append(r.backlog[:0:0], r.backlog[maxBuildBacklog/2:]...)
+	}
+	r.backlog = append(r.backlog, ev)
+
+	for ch := range r.subscribers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func (r *buildRun) appendLogInternal(ev imagebuild.ProgressEvent) {
+	var line string
+	switch ev.Type {
+	case imagebuild.EventLog:
+		line = ev.Message
+	case imagebuild.EventStep:
+		_, seen := r.startedStep[ev.Step]
+		switch {
+		case ev.Error != "":
+			line = fmt.Sprintf("ERROR %s: %s", ev.Name, ev.Error)
+		case seen:
+			return
+		case ev.Cached:
+			line = "CACHED " + ev.Name
+		case ev.Started != nil:
+			line = "=> " + ev.Name
+		default:
+			return
+		}
+		r.startedStep[ev.Step] = struct{}{}
+	case imagebuild.EventResult:
+		switch {
+		case ev.ImageID != "":
+			line = fmt.Sprintf("Built %s", ev.ImageID)
+		case ev.Error != "":
+			line = fmt.Sprintf("Build %s: %s", ev.Status, ev.Error)
+		default:
+			line = "Build " + ev.Status
+		}
+	default:
+		return
+	}
+
+	if ev.Service != "" {
+		line = "[" + ev.Service + "] " + line
+	}
+	r.log.WriteString(line)
+	r.log.WriteByte('\n')
+
+	// Let the buffer grow to twice the stored size before dropping the head, so it isn't copied on every line.
+	if r.log.Len() > 2*maxBuildLogSize {
+		tail := r.log.String()[r.log.Len()-maxBuildLogSize:]
+		r.log.Reset()
+		r.log.WriteString(tail)
+	}
+}
+
+func (r *buildRun) subscribe() ([]imagebuild.ProgressEvent, <-chan imagebuild.ProgressEvent, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	backlog := slices.Clone(r.backlog)
+	ch := make(chan imagebuild.ProgressEvent, 256)
+	if r.done {
+		close(ch)
+		return backlog, ch, func() {}
+	}
+
+	r.subscribers[ch] = struct{}{}
+	return backlog, ch, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if _, ok := r.subscribers[ch]; ok {
+			delete(r.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// finish closes the subscriber channels and returns the tail of the build log.
+func (r *buildRun) finish() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.done = true
+	for ch := range r.subscribers {
+		delete(r.subscribers, ch)
+		close(ch)
+	}
+
+	log := r.log.String()
+	if len(log) > maxBuildLogSize {
+		log = log[len(log)-maxBuildLogSize:]
+	}
+	return log
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	composetypes "github.com/compose-spec/compose-go/v2/types"
+	glsqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/types/imagebuild"
+)
+
+func setupImageBuildTestService(t *testing.T) *ImageBuildService {
+	t.Helper()
+	gdb, err := gorm.Open(glsqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, gdb.AutoMigrate(&models.ImageBuild{}, &models.Project{}))
+
+	return NewImageBuildService(&database.DB{DB: gdb}, nil, nil, nil, nil)
+}
+
+func TestComposeBuildTargets(t *testing.T) {
+	// Targets point at resolved paths, and the temporary directory may be behind a symlink.
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, os.Mkdir(filepath.Join(root, "web"), 0o755))
+
+	arg := "1.23"
+	proj := &composetypes.Project{
+		Name:       "shop",
+		WorkingDir: root,
+		Services: composetypes.Services{
+			"web": {
+				Name: "web",
+				Build: &composetypes.BuildConfig{
+					Context:   "./web",
+					Target:    "prod",
+					Args:      composetypes.MappingWithEquals{"GO_VERSION": &arg},
+					CacheFrom: composetypes.StringList{"shop-web:cache"},
+					Platforms: composetypes.StringList{"linux/arm64"},
+				},
+			},
+			"api": {
+				Name:  "api",
+				Image: "registry.local/shop/api:dev",
+				Build: &composetypes.BuildConfig{Context: "https://github.com/example/api.git#main"},
+			},
+			"db": {Name: "db", Image: "postgres:17"},
+		},
+	}
+
+	targets, err := composeBuildTargetsInternal(proj, imagebuild.BuildRequest{
+		BuildArgs: map[string]string{"GO_VERSION": "1.24"},
+		CacheFrom: []string{"shop-web:main"},
+		NoCache:   true,
+	}, root)
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+
+	api := targets[0]
+	assert.Equal(t, "api", api.service)
+	assert.Equal(t, []string{"registry.local/shop/api:dev"}, api.options.Tags)
+	assert.Equal(t, "https://github.com/example/api.git#main", api.options.RemoteContext)
+	assert.Empty(t, api.contextDir)
+
+	web := targets[1]
+	assert.Equal(t, "web", web.service)
+	assert.Equal(t, filepath.Join(root, "web"), web.contextDir)
+	assert.Equal(t, []string{"shop-web"}, web.options.Tags)
+	assert.Equal(t, "prod", web.options.Target)
+	assert.Equal(t, "linux/arm64", web.options.Platform)
+	assert.Equal(t, []string{"shop-web:cache", "shop-web:main"}, web.options.CacheFrom)
+	assert.True(t, web.options.NoCache)
+	require.NotNil(t, web.options.BuildArgs["GO_VERSION"])
+	assert.Equal(t, "1.24", *web.options.BuildArgs["GO_VERSION"])
+
+	targets, err = composeBuildTargetsInternal(proj, imagebuild.BuildRequest{Services: []string{"web"}, Target: "dev"}, root)
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, "dev", targets[0].options.Target)
+
+	_, err = composeBuildTargetsInternal(proj, imagebuild.BuildRequest{Services: []string{"db"}}, root)
+	var validationErr *models.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "services", validationErr.Field)
+}
+
+func TestComposeBuildTargetsStayInsideRoot(t *testing.T) {
+	parent, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	root := filepath.Join(parent, "checkout")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "app"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "app", "Dockerfile"), []byte("FROM scratch\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(parent, "Dockerfile"), []byte("FROM scratch\n"), 0o600))
+	require.NoError(t, os.Symlink(parent, filepath.Join(root, "escape")))
+
+	targetsFor := func(b *composetypes.BuildConfig) ([]buildTarget, error) {
+		proj := &composetypes.Project{
+			Name:       "shop",
+			WorkingDir: root,
+			Services:   composetypes.Services{"web": {Name: "web", Build: b}},
+		}
+		return composeBuildTargetsInternal(proj, imagebuild.BuildRequest{}, root)
+	}
+
+	for _, b := range []*composetypes.BuildConfig{
+		{Context: "../"},
+		{Context: "/"},
+		{Context: "./escape"},
+		{Context: "./app", Dockerfile: filepath.Join(parent, "Dockerfile")},
+		{Context: "./app", Dockerfile: filepath.Join(root, "escape", "Dockerfile")},
+	} {
+		_, err := targetsFor(b)
+		require.Error(t, err, "context %q, dockerfile %q", b.Context, b.Dockerfile)
+		assert.Contains(t, err.Error(), "is outside of "+root)
+	}
+
+	targets, err := targetsFor(&composetypes.BuildConfig{Context: "./app", Dockerfile: filepath.Join(root, "app", "Dockerfile")})
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, filepath.Join(root, "app"), targets[0].contextDir)
+	assert.Equal(t, "Dockerfile", targets[0].options.Dockerfile)
+}
+
+func TestImageBuildService_StartBuildValidation(t *testing.T) {
+	svc := setupImageBuildTestService(t)
+	ctx := context.Background()
+	user := models.User{BaseModel: models.BaseModel{ID: "u1"}, Username: "admin"}
+
+	tests := []struct {
+		name  string
+		req   imagebuild.BuildRequest
+		field string
+	}{
+		{name: "no source", req: imagebuild.BuildRequest{}, field: "projectId"},
+		{name: "both sources", req: imagebuild.BuildRequest{ProjectID: "p1", RepositoryID: "r1"}, field: "projectId"},
+		{name: "context without tags", req: imagebuild.BuildRequest{ProjectID: "p1", ContextPath: "app"}, field: "tags"},
+		{name: "context outside project", req: imagebuild.BuildRequest{ProjectID: "p1", ContextPath: "../other", Tags: []string{"x"}}, field: "contextPath"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.StartBuild(ctx, tt.req, user)
+			var validationErr *models.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.field, validationErr.Field)
+		})
+	}
+
+	_, err := svc.StartBuild(ctx, imagebuild.BuildRequest{ProjectID: "missing"}, user)
+	var notFound *models.NotFoundError
+	require.ErrorAs(t, err, &notFound)
+}
+
+func TestImageBuildService_SubscribeReplaysBacklog(t *testing.T) {
+	svc := setupImageBuildTestService(t)
+	ctx := context.Background()
+
+	record := newImageBuildRecordInternal(imagebuild.SourceProject, imagebuild.TriggerManual, models.User{})
+	run, _, err := svc.beginInternal(ctx, record)
+	require.NoError(t, err)
+
+	run.emit(imagebuild.ProgressEvent{Type: imagebuild.EventLog, Service: "web", Message: "Building web"})
+	backlog, events, unsubscribe, err := svc.Subscribe(ctx, record.ID)
+	require.NoError(t, err)
+	defer unsubscribe()
+	require.Len(t, backlog, 1)
+	assert.Equal(t, record.ID, backlog[0].BuildID)
+
+	run.emit(imagebuild.ProgressEvent{Type: imagebuild.EventResult, Service: "web", ImageID: "sha256:abc"})
+	ev := <-events
+	assert.Equal(t, "sha256:abc", ev.ImageID)
+
+	svc.finishInternal(ctx, run, record, models.User{}, nil)
+	ev = <-events
+	assert.Equal(t, imagebuild.StatusSucceeded, ev.Status)
+	_, open := <-events
+	assert.False(t, open)
+
+	got, err := svc.GetBuildByID(ctx, record.ID)
+	require.NoError(t, err)
+	assert.Equal(t, imagebuild.StatusSucceeded, got.Status)
+	assert.Equal(t, "[web] Building web\n[web] Built sha256:abc\nBuild succeeded\n", got.Log)
+	assert.NotNil(t, got.FinishedAt)
+}
+
+func TestImageBuildService_GetBuildMarksInterruptedBuilds(t *testing.T) {
+	svc := setupImageBuildTestService(t)
+	ctx := context.Background()
+
+	record := &models.ImageBuild{Source: imagebuild.SourceProject, Trigger: imagebuild.TriggerManual, Status: imagebuild.StatusRunning}
+	require.NoError(t, svc.db.WithContext(ctx).Create(record).Error)
+
+	got, err := svc.GetBuildByID(ctx, record.ID)
+	require.NoError(t, err)
+	assert.Equal(t, imagebuild.StatusFailed, got.Status)
+	require.NotNil(t, got.Error)
+
+	err = svc.CancelBuild(ctx, record.ID)
+	var conflict *models.ConflictError
+	require.ErrorAs(t, err, &conflict)
+}
@@ -16,8 +16,10 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/client"
 	"github.com/getarcaneapp/arcane/backend/internal/database"
 	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/crypto"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/pagination"
 	"github.com/getarcaneapp/arcane/types/containerregistry"
 	imagetypes "github.com/getarcaneapp/arcane/types/image"
@@ -34,9 +36,10 @@ type ImageService struct {
 	registryService      *ContainerRegistryService
 	vulnerabilityService *VulnerabilityService
 	eventService         *EventService
+	mirrorService        *RegistryMirrorService
 }
 
-func NewImageService(db *database.DB, dockerService *DockerClientService, registryService *ContainerRegistryService, imageUpdateService *ImageUpdateService, vulnerabilityService *VulnerabilityService, eventService *EventService) *ImageService {
+func NewImageService(db *database.DB, dockerService *DockerClientService, registryService *ContainerRegistryService, imageUpdateService *ImageUpdateService, vulnerabilityService *VulnerabilityService, eventService *EventService, mirrorService *RegistryMirrorService) *ImageService {
 	return &ImageService{
 		db:                   db,
 		dockerService:        dockerService,
@@ -44,6 +47,7 @@ func NewImageService(db *database.DB, dockerService *DockerClientService, regist
 		imageUpdateService:   imageUpdateService,
 		vulnerabilityService: vulnerabilityService,
 		eventService:         eventService,
+		mirrorService:        mirrorService,
 	}
 }
 
@@ -123,6 +127,25 @@ func (s *ImageService) PullImage(ctx context.Context, imageName string, progress
 
 	slog.DebugContext(ctx, "Attempting to pull image", "image", imageName, "externalCredCount", len(externalCreds))
 
+	mirror, err := s.pullFromMirrorsInternal(ctx, dockerClient, imageName, progressWriter, externalCreds)
+	if err != nil {
+		s.eventService.LogErrorEvent(ctx, models.EventTypeImageError, "image", "", imageName, user.ID, user.Username, "0", err, models.JSON{"action": "pull", "step": "mirror"})
+		return err
+	}
+	if mirror != nil {
+		metadata := models.JSON{
+			"action":    "pull",
+			"imageName": imageName,
+			"source":    "mirror",
+			"mirror":    mirror.Mirror.MirrorURL,
+			"mirrorRef": mirror.Ref,
+		}
+		if logErr := s.eventService.LogImageEvent(ctx, models.EventTypeImagePull, "", imageName, user.ID, user.Username, "0", metadata); logErr != nil {
+			slog.Warn("could not log image pull action", "err", logErr, "image", imageName)
+		}
+		return nil
+	}
+
 	pullOptions, err := s.getPullOptionsWithAuth(ctx, imageName, externalCreds)
 	if err != nil {
 		slog.WarnContext(ctx, "Failed to get registry authentication for image; proceeding without auth", "image", imageName, "error", err.Error())
@@ -170,6 +193,7 @@ func (s *ImageService) PullImage(ctx context.Context, imageName string, progress
 	metadata := models.JSON{
 		"action":    "pull",
 		"imageName": imageName,
+		"source":    "upstream",
 	}
 	if logErr := s.eventService.LogImageEvent(ctx, models.EventTypeImagePull, "", imageName, user.ID, user.Username, "0", metadata); logErr != nil {
 		slog.Warn("could not log image pull action", "err", logErr, "image", imageName)
@@ -178,6 +202,284 @@ func (s *ImageService) PullImage(ctx context.Context, imageName string, progress
 	return nil
 }
 
+// pullFromMirrorsInternal tries the registry mirrors configured for imageName in order. The image
+// pulled from a mirror is tagged with the original reference, so compose projects and the updater
+// find it, and the mirror tag is dropped again. It returns the mirror that served the pull, or nil
+// when the upstream registry should be used: no mirror applies, or all failed and allow fallback.
+func (s *ImageService) pullFromMirrorsInternal(ctx context.Context, dockerClient *client.Client, imageName string, progressWriter io.Writer, externalCreds []containerregistry.Credential) (*MirrorCandidate, error) {
+	if s.mirrorService == nil {
+		return nil, nil
+	}
+
+	candidates, err := s.mirrorService.ResolveMirrors(ctx, imageName)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to resolve registry mirrors; pulling from upstream", "image", imageName, "error", err)
+		return nil, nil
+	}
+
+	var failures []string
+	fallback := true
+	for i := range candidates {
+		candidate := &candidates[i]
+		pullErr := s.pullMirrorRefInternal(ctx, dockerClient, imageName, candidate, progressWriter, externalCreds)
+		s.mirrorService.RecordPull(context.WithoutCancel(ctx), candidate.Mirror.ID, pullErr)
+		if pullErr == nil {
+			slog.InfoContext(ctx, "Pulled image from registry mirror", "image", imageName, "mirror", candidate.Mirror.MirrorURL, "ref", candidate.Ref)
+			return candidate, nil
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("image pull canceled for %s: %w", imageName, ctx.Err())
+		}
+
+		slog.WarnContext(ctx, "Pull from registry mirror failed", "image", imageName, "mirror", candidate.Mirror.MirrorURL, "error", pullErr)
+		failures = append(failures, fmt.Sprintf("%s: %v", candidate.Mirror.MirrorURL, pullErr))
+		fallback = fallback && candidate.Mirror.FallbackToUpstream
+	}
+
+	if len(failures) > 0 && !fallback {
+		return nil, fmt.Errorf("failed to pull %s from registry mirrors (%s)", imageName, strings.Join(failures, "; "))
+	}
+	if len(failures) > 0 {
+		writePullStatusInternal(progressWriter, fmt.Sprintf("Registry mirrors failed, pulling %s from upstream", imageName))
+	}
+	return nil, nil
+}
+
+// pullMirrorRefInternal pulls one mirror reference and retags it. Error messages in the pull
+// stream are returned instead of forwarded, so a failed attempt does not end the progress
+// display of a pull that goes on to the next source.
+func (s *ImageService) pullMirrorRefInternal(ctx context.Context, dockerClient *client.Client, imageName string, candidate *MirrorCandidate, progressWriter io.Writer, externalCreds []containerregistry.Credential) error {
+	pullOptions, err := s.getPullOptionsWithAuth(ctx, candidate.Ref, externalCreds)
+	if err != nil {
+		slog.DebugContext(ctx, "No registry authentication for mirror; proceeding without auth", "ref", candidate.Ref, "error", err)
+		pullOptions = image.PullOptions{}
+	}
+
+	writePullStatusInternal(progressWriter, fmt.Sprintf("Pulling %s from mirror %s", imageName, candidate.Mirror.MirrorURL))
+
+	reader, err := dockerClient.ImagePull(ctx, candidate.Ref, pullOptions)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	flusher, implementsFlusher := progressWriter.(http.Flusher)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(line, &msg) == nil && msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+
+		if _, err := progressWriter.Write(append(line, '\n')); err != nil {
+			return fmt.Errorf("error writing pull progress: %w", err)
+		}
+		if implementsFlusher {
+			flusher.Flush()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading image pull stream: %w", err)
+	}
+
+	if err := dockerClient.ImageTag(ctx, candidate.Ref, imageName); err != nil {
+		return fmt.Errorf("failed to tag %s as %s: %w", candidate.Ref, imageName, err)
+	}
+	if _, err := dockerClient.ImageRemove(ctx, candidate.Ref, image.RemoveOptions{}); err != nil {
+		slog.DebugContext(ctx, "Failed to remove mirror tag", "ref", candidate.Ref, "error", err)
+	}
+
+	writePullStatusInternal(progressWriter, fmt.Sprintf("Pulled %s from mirror %s", imageName, candidate.Mirror.MirrorURL))
+	return nil
+}
+
+// TagImage adds the reference target to the image id and returns the normalized reference.
+// A reference without a tag is tagged latest.
+func (s *ImageService) TagImage(ctx context.Context, id, target string, user models.User) (string, error) {
+	named, err := ref.ParseNormalizedNamed(strings.TrimSpace(target))
+	if err != nil {
+		return "", &models.ValidationError{Message: fmt.Sprintf("Invalid image reference %q: %v", target, err), Field: "target"}
+	}
+	if _, isDigested := named.(ref.Digested); isDigested {
+		return "", &models.ValidationError{Message: "Image references with a digest can't be used as a tag", Field: "target"}
+	}
+	tagged := ref.FamiliarString(ref.TagNameOnly(named))
+
+	dockerClient, err := s.dockerService.GetClient()
+	if err != nil {
+		s.eventService.LogErrorEvent(ctx, models.EventTypeImageError, "image", id, tagged, user.ID, user.Username, "0", err, models.JSON{"action": "tag"})
+		return "", fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+
+	if _, err := dockerClient.ImageInspect(ctx, id); err != nil {
+		if client.IsErrNotFound(err) {
+			return "", &models.NotFoundError{Message: "Image not found"}
+		}
+		return "", fmt.Errorf("failed to inspect image: %w", err)
+	}
+
+	if err := dockerClient.ImageTag(ctx, id, tagged); err != nil {
+		s.eventService.LogErrorEvent(ctx, models.EventTypeImageError, "image", id, tagged, user.ID, user.Username, "0", err, models.JSON{"action": "tag"})
+		return "", fmt.Errorf("failed to tag image: %w", err)
+	}
+
+	metadata := models.JSON{
+		"action":  "tag",
+		"imageId": id,
+		"target":  tagged,
+	}
+	if logErr := s.eventService.LogImageEvent(ctx, models.EventTypeImageTag, id, tagged, user.ID, user.Username, "0", metadata); logErr != nil {
+		slog.Warn("could not log image tag action", "err", logErr, "image", tagged, "image_id", id)
+	}
+
+	return tagged, nil
+}
+
+// PushImage pushes a tag of the image id to its registry, authenticating with the matching
+// stored registry credentials, and streams the push progress to progressWriter. The tag may be
+// omitted when id is itself a tag of the image or the image has a single tag. It returns the
+// pushed reference.
+func (s *ImageService) PushImage(ctx context.Context, id, tag string, progressWriter io.Writer, user models.User) (string, error) {
+	dockerClient, err := s.dockerService.GetClient()
+	if err != nil {
+		s.eventService.LogErrorEvent(ctx, models.EventTypeImageError, "image", id, tag, user.ID, user.Username, "0", err, models.JSON{"action": "push"})
+		return "", fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+
+	details, err := dockerClient.ImageInspect(ctx, id)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return "", &models.NotFoundError{Message: "Image not found"}
+		}
+		return "", fmt.Errorf("failed to inspect image: %w", err)
+	}
+
+	pushRef, err := selectPushTagInternal(details.RepoTags, id, tag)
+	if err != nil {
+		return "", err
+	}
+
+	pushOptions := image.PushOptions{}
+	pullOptions, err := s.getPullOptionsWithAuth(ctx, pushRef, nil)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get registry authentication for image; pushing without auth", "image", pushRef, "error", err.Error())
+	}
+	pushOptions.RegistryAuth = pullOptions.RegistryAuth
+	if pushOptions.RegistryAuth == "" {
+		// The daemon rejects pushes without an auth header, even to registries that need none.
+		pushOptions.RegistryAuth, _ = registry.EncodeAuthConfig(registry.AuthConfig{})
+	}
+
+	reader, err := dockerClient.ImagePush(ctx, pushRef, pushOptions)
+	if err != nil {
+		s.eventService.LogErrorEvent(ctx, models.EventTypeImageError, "image", id, pushRef, user.ID, user.Username, "0", err, models.JSON{"action": "push"})
+		return "", fmt.Errorf("failed to initiate image push for %s: %w", pushRef, err)
+	}
+	defer reader.Close()
+
+	flusher, implementsFlusher := progressWriter.(http.Flusher)
+	scanner := bufio.NewScanner(reader)
+	var pushErr error
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(line, &msg) == nil && msg.Error != "" && pushErr == nil {
+			pushErr = errors.New(msg.Error)
+		}
+
+		if _, err := progressWriter.Write(append(line, '\n')); err != nil {
+			return "", fmt.Errorf("error writing push progress for %s: %w", pushRef, err)
+		}
+		if implementsFlusher {
+			flusher.Flush()
+		}
+	}
+	if err := scanner.Err(); err != nil && pushErr == nil {
+		pushErr = fmt.Errorf("error reading image push stream for %s: %w", pushRef, err)
+	}
+	if pushErr != nil {
+		s.eventService.LogErrorEvent(ctx, models.EventTypeImageError, "image", id, pushRef, user.ID, user.Username, "0", pushErr, models.JSON{"action": "push"})
+		return "", pushErr
+	}
+
+	metadata := models.JSON{
+		"action":    "push",
+		"imageId":   id,
+		"imageName": pushRef,
+		"hasAuth":   pullOptions.RegistryAuth != "",
+	}
+	if logErr := s.eventService.LogImageEvent(ctx, models.EventTypeImagePush, id, pushRef, user.ID, user.Username, "0", metadata); logErr != nil {
+		slog.Warn("could not log image push action", "err", logErr, "image", pushRef)
+	}
+
+	return pushRef, nil
+}
+
+// selectPushTagInternal picks the tag of an image to push: the requested tag, the identifier
+// the image was addressed by when that is one of its tags, or its only tag.
+func selectPushTagInternal(repoTags []string, identifier, requested string) (string, error) {
+	normalize := func(s string) string {
+		named, err := ref.ParseNormalizedNamed(strings.TrimSpace(s))
+		if err != nil {
+			return ""
+		}
+		return ref.FamiliarString(ref.TagNameOnly(named))
+	}
+
+	tags := make([]string, 0, len(repoTags))
+	for _, t := range repoTags {
+		if n := normalize(t); n != "" {
+			tags = append(tags, n)
+		}
+	}
+
+	if requested != "" {
+		want := normalize(requested)
+		for _, t := range tags {
+			if t == want {
+				return t, nil
+			}
+		}
+		return "", &models.ValidationError{Message: fmt.Sprintf("Image has no tag %s; tag it first", requested), Field: "tag"}
+	}
+
+	if strings.ContainsAny(identifier, ":/") && !strings.HasPrefix(identifier, "sha256:") {
+		want := normalize(identifier)
+		for _, t := range tags {
+			if t == want {
+				return t, nil
+			}
+		}
+	}
+
+	switch len(tags) {
+	case 0:
+		return "", &models.ValidationError{Message: "Image has no tags to push; tag it first", Field: "tag"}
+	case 1:
+		return tags[0], nil
+	default:
+		return "", &models.ValidationError{Message: fmt.Sprintf("Image has several tags (%s); specify which to push", strings.Join(tags, ", ")), Field: "tag"}
+	}
+}
+
+func writePullStatusInternal(w io.Writer, status string) {
+	line, err := json.Marshal(map[string]string{"status": status})
+	if err != nil {
+		return
+	}
+	_, _ = w.Write(append(line, '\n'))
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func (s *ImageService) LoadImageFromReader(ctx context.Context, reader io.Reader, fileName string, user models.User, maxSizeBytes int64) (*imagetypes.LoadResult, error) {
 	// Wrap reader with size limit enforcement
 	limitedReader := io.LimitReader(reader, maxSizeBytes+1)
@@ -266,7 +568,7 @@ func (s *ImageService) getPullOptionsWithAuth(ctx context.Context, imageRef stri
 		return pullOptions, nil
 	}
 
-	// Check database registries
+	// Check database registries and docker config credentials
 	registries, err := s.registryService.GetEnabledRegistries(ctx)
 	if err != nil {
 		return pullOptions, fmt.Errorf("failed to get registry credentials: %w", err)
@@ -274,7 +576,7 @@ func (s *ImageService) getPullOptionsWithAuth(ctx context.Context, imageRef stri
 
 	for _, reg := range registries {
 		if s.isRegistryMatch(reg.URL, registryHost) {
-			decryptedToken, err := s.registryService.GetDecryptedToken(ctx, reg.ID)
+			decryptedToken, err := crypto.Decrypt(reg.Token)
 			if err != nil {
 				return pullOptions, fmt.Errorf("failed to decrypt token for registry %s: %w", reg.URL, err)
 			}
@@ -285,7 +587,7 @@ func (s *ImageService) getPullOptionsWithAuth(ctx context.Context, imageRef stri
 			}
 			pullOptions.RegistryAuth = authStr
 
-			slog.DebugContext(ctx, "Using database credentials for image pull", "registry", registryHost, "username", reg.Username)
+			slog.DebugContext(ctx, "Using stored credentials for image pull", "registry", registryHost, "username", reg.Username)
 			break
 		}
 	}
@@ -612,19 +914,24 @@ func stringPtrValue(p *string) string {
 
 func buildUpdateInfo(updateRecord *models.ImageUpdateRecord) *imagetypes.UpdateInfo {
 	return &imagetypes.UpdateInfo{
-		HasUpdate:      updateRecord.HasUpdate,
-		UpdateType:     updateRecord.UpdateType,
-		CurrentVersion: updateRecord.CurrentVersion,
-		LatestVersion:  stringPtrValue(updateRecord.LatestVersion),
-		CurrentDigest:  stringPtrValue(updateRecord.CurrentDigest),
-		LatestDigest:   stringPtrValue(updateRecord.LatestDigest),
-		CheckTime:      updateRecord.CheckTime,
-		ResponseTimeMs: updateRecord.ResponseTimeMs,
-		Error:          stringPtrValue(updateRecord.LastError),
-		AuthMethod:     stringPtrValue(updateRecord.AuthMethod),
-		AuthUsername:   stringPtrValue(updateRecord.AuthUsername),
-		AuthRegistry:   stringPtrValue(updateRecord.AuthRegistry),
-		UsedCredential: updateRecord.UsedCredential,
+		HasUpdate:       updateRecord.HasUpdate,
+		UpdateType:      updateRecord.UpdateType,
+		CurrentVersion:  updateRecord.CurrentVersion,
+		LatestVersion:   stringPtrValue(updateRecord.LatestVersion),
+		CurrentDigest:   stringPtrValue(updateRecord.CurrentDigest),
+		LatestDigest:    stringPtrValue(updateRecord.LatestDigest),
+		CheckTime:       updateRecord.CheckTime,
+		ResponseTimeMs:  updateRecord.ResponseTimeMs,
+		Error:           stringPtrValue(updateRecord.LastError),
+		AuthMethod:      stringPtrValue(updateRecord.AuthMethod),
+		AuthUsername:    stringPtrValue(updateRecord.AuthUsername),
+		AuthRegistry:    stringPtrValue(updateRecord.AuthRegistry),
+		UsedCredential:  updateRecord.UsedCredential,
+		ReleaseSource:   stringPtrValue(updateRecord.ReleaseSource),
+		ReleaseVersion:  stringPtrValue(updateRecord.ReleaseVersion),
+		ReleaseRevision: stringPtrValue(updateRecord.ReleaseRevision),
+		ReleaseNotesURL: stringPtrValue(updateRecord.ReleaseNotesURL),
+		ReleaseNotes:    stringPtrValue(updateRecord.ReleaseNotes),
 	}
 }
 
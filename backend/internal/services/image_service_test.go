@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+)
+
+func TestSelectPushTag(t *testing.T) {
+	tags := []string{"registry.local/team/app:1.0", "app:latest"}
+
+	tests := []struct {
+		name       string
+		repoTags   []string
+		identifier string
+		requested  string
+		want       string
+		wantErr    bool
+	}{
+		{name: "requested tag", repoTags: tags, identifier: "sha256:abc", requested: "registry.local/team/app:1.0", want: "registry.local/team/app:1.0"},
+		{name: "requested tag is normalized", repoTags: tags, identifier: "sha256:abc", requested: "docker.io/library/app", want: "app:latest"},
+		{name: "requested tag missing", repoTags: tags, identifier: "sha256:abc", requested: "app:2.0", wantErr: true},
+		{name: "addressed by tag", repoTags: tags, identifier: "registry.local/team/app:1.0", want: "registry.local/team/app:1.0"},
+		{name: "single tag", repoTags: []string{"app:latest"}, identifier: "sha256:abc", want: "app:latest"},
+		{name: "ambiguous", repoTags: tags, identifier: "sha256:abc", wantErr: true},
+		{name: "untagged", repoTags: nil, identifier: "sha256:abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectPushTagInternal(tt.repoTags, tt.identifier, tt.requested)
+			if tt.wantErr {
+				var validationErr *models.ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				assert.Equal(t, "tag", validationErr.Field)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
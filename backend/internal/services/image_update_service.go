@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/crypto"
 	registry "github.com/getarcaneapp/arcane/backend/internal/utils/registry"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/releasenotes"
 	"github.com/getarcaneapp/arcane/types/containerregistry"
 	"github.com/getarcaneapp/arcane/types/imageupdate"
 	ref "go.podman.io/image/v5/docker/reference"
@@ -27,6 +29,11 @@ type ImageUpdateService struct {
 	dockerService       *DockerClientService
 	eventService        *EventService
 	notificationService *NotificationService
+	releaseNotes        *releasenotes.Resolver
+
+	// releaseInfoCache holds the release info last looked up per registry/repository, the same
+	// update is seen on every poll until it is applied. A newer update replaces the entry.
+	releaseInfoCache sync.Map
 }
 
 type ImageParts struct {
@@ -43,6 +50,7 @@ func NewImageUpdateService(db *database.DB, settingsService *SettingsService, re
 		dockerService:       dockerService,
 		eventService:        eventService,
 		notificationService: notificationService,
+		releaseNotes:        releasenotes.NewResolver(),
 	}
 }
 
@@ -87,7 +95,8 @@ func (s *ImageUpdateService) CheckImageUpdate(ctx context.Context, imageRef stri
 		"action":         "check_update",
 		"imageRef":       imageRef,
 		"hasUpdate":      digestResult.HasUpdate,
-		"updateType":     "digest",
+		"updateType":     digestResult.UpdateType,
+		"latestVersion":  digestResult.LatestVersion,
 		"currentDigest":  digestResult.CurrentDigest,
 		"latestDigest":   digestResult.LatestDigest,
 		"responseTimeMs": digestResult.ResponseTimeMs,
@@ -186,6 +195,7 @@ func (s *ImageUpdateService) checkDigestUpdate(ctx context.Context, parts *Image
 		authHeader, _, _, resolveErr := registry.ResolveAuthHeaderForRepository(ctx, parts.Registry, normalizedRepo, parts.Tag, enabledRegs)
 		if resolveErr == nil && authHeader != "" {
 			remoteDigest, _, err = rc.GetLatestDigestTimed(ctx, parts.Registry, normalizedRepo, parts.Tag, authHeader)
+			token = authHeader
 		}
 	}
 	elapsed := time.Since(start)
@@ -215,7 +225,7 @@ func (s *ImageUpdateService) checkDigestUpdate(ctx context.Context, parts *Image
 		"remoteDigest", remoteDigest,
 		"hasUpdate", hasUpdate)
 
-	return &imageupdate.Response{
+	result := &imageupdate.Response{
 		HasUpdate:      hasUpdate,
 		UpdateType:     "digest",
 		CurrentDigest:  localDigest,
@@ -226,7 +236,102 @@ func (s *ImageUpdateService) checkDigestUpdate(ctx context.Context, parts *Image
 		AuthUsername:   auth.Username,
 		AuthRegistry:   auth.Registry,
 		UsedCredential: auth.Method == "credential",
-	}, nil
+	}
+	s.checkTagUpdateInternal(ctx, rc, parts, normalizedRepo, token, result)
+	s.attachReleaseInfoInternal(ctx, rc, parts, normalizedRepo, token, result)
+	return result, nil
+}
+
+// checkTagUpdateInternal looks for a newer version of a pinned tag within the
+// imageTagUpdatePolicy setting and, when there is one, reports result as a tag update.
+// Failing to list tags leaves the digest result as it is.
+func (s *ImageUpdateService) checkTagUpdateInternal(ctx context.Context, rc *registry.Client, parts *ImageParts, normalizedRepo, token string, result *imageupdate.Response) {
+	if s.settingsService == nil || !registry.IsVersionTag(parts.Tag) {
+		return
+	}
+	policy := registry.ParseTagPolicy(s.settingsService.GetStringSetting(ctx, "imageTagUpdatePolicy", string(registry.TagPolicyNone)))
+	if policy == registry.TagPolicyNone {
+		return
+	}
+
+	tags, err := rc.ListTags(ctx, parts.Registry, normalizedRepo, token)
+	if errors.Is(err, registry.ErrTagListIncomplete) {
+		// A newer version may be on a page that wasn't fetched, so a partial list can't tell.
+		slog.WarnContext(ctx, "Skipping tag update check, the repository has too many tags", "repository", normalizedRepo, "tag", parts.Tag, "error", err.Error())
+		return
+	}
+	if err != nil {
+		slog.DebugContext(ctx, "Failed to list tags for tag update check", "repository", normalizedRepo, "error", err.Error())
+		return
+	}
+
+	newer, ok := registry.NewerTag(parts.Tag, tags, policy)
+	if !ok {
+		return
+	}
+	slog.DebugContext(ctx, "newer version tag found", "repository", normalizedRepo, "tag", parts.Tag, "latest", newer, "policy", policy)
+
+	result.HasUpdate = true
+	result.UpdateType = models.UpdateTypeTag
+	result.CurrentVersion = parts.Tag
+	result.LatestVersion = newer
+}
+
+// releaseInfo is what attachReleaseInfoInternal found for one image.
+type releaseInfo struct {
+	reference string
+	origin    registry.ImageOrigin
+	notes     releasenotes.Notes
+}
+
+// attachReleaseInfoInternal reads the OCI source, version and revision annotations of the image
+// an update points to and adds them, with the release notes of that version, to result. Any
+// failure leaves the result without release info.
+func (s *ImageUpdateService) attachReleaseInfoInternal(ctx context.Context, rc *registry.Client, parts *ImageParts, normalizedRepo, token string, result *imageupdate.Response) {
+	if !result.HasUpdate || s.releaseNotes == nil {
+		return
+	}
+
+	reference := result.LatestDigest
+	if result.UpdateType == models.UpdateTypeTag && result.LatestVersion != "" {
+		reference = result.LatestVersion
+	}
+	if reference == "" {
+		reference = parts.Tag
+	}
+	key := parts.Registry + "/" + normalizedRepo
+
+	var info *releaseInfo
+	if cached, ok := s.releaseInfoCache.Load(key); ok && cached.(*releaseInfo).reference == reference {
+		info = cached.(*releaseInfo)
+	} else {
+		origin, err := rc.GetImageOrigin(ctx, parts.Registry, normalizedRepo, reference, token)
+		if err != nil {
+			slog.DebugContext(ctx, "Failed to read image annotations", "repository", normalizedRepo, "reference", reference, "error", err.Error())
+			return
+		}
+		info = &releaseInfo{reference: reference, origin: *origin}
+		if info.origin.Version == "" && result.UpdateType == models.UpdateTypeTag {
+			info.origin.Version = result.LatestVersion
+		}
+		if info.origin.Source != "" {
+			gitlabHosts := strings.Split(s.settingsService.GetStringSetting(ctx, "releaseNotesGitLabHosts", ""), ",")
+			notes, err := s.releaseNotes.Resolve(ctx, info.origin.Source, info.origin.Version, info.origin.Revision, gitlabHosts)
+			if err != nil {
+				// Lookups can be rate limited, try again on the next check.
+				slog.DebugContext(ctx, "Failed to resolve release notes", "source", info.origin.Source, "version", info.origin.Version, "error", err.Error())
+				return
+			}
+			info.notes = *notes
+		}
+		s.releaseInfoCache.Store(key, info)
+	}
+
+	result.ReleaseSource = info.origin.Source
+	result.ReleaseVersion = info.origin.Version
+	result.ReleaseRevision = info.origin.Revision
+	result.ReleaseNotesURL = info.notes.URL
+	result.ReleaseNotes = info.notes.Body
 }
 
 func (s *ImageUpdateService) parseImageReference(imageRef string) *ImageParts {
@@ -422,7 +527,7 @@ func (s *ImageUpdateService) getLocalImageDigestWithAll(ctx context.Context, ima
 func (s *ImageUpdateService) getRegistriesForImage(ctx context.Context, regHost string) []models.ContainerRegistry {
 	normalizedDomain := s.normalizeRegistryURL(regHost)
 
-	registries, err := s.registryService.GetAllRegistries(ctx)
+	registries, err := s.registryService.GetEnabledRegistries(ctx)
 	if err != nil {
 		slog.DebugContext(ctx, "Failed to load registries for image", "registry", regHost, "error", err.Error())
 		return nil
@@ -569,22 +674,27 @@ func buildImageUpdateRecord(imageID, repo, tag string, result *imageupdate.Respo
 	}
 
 	return &models.ImageUpdateRecord{
-		ID:             imageID,
-		Repository:     repo,
-		Tag:            tag,
-		HasUpdate:      result.HasUpdate,
-		UpdateType:     result.UpdateType,
-		CurrentVersion: currentVersion,
-		LatestVersion:  stringToPtr(result.LatestVersion),
-		CurrentDigest:  stringToPtr(result.CurrentDigest),
-		LatestDigest:   stringToPtr(result.LatestDigest),
-		CheckTime:      result.CheckTime,
-		ResponseTimeMs: result.ResponseTimeMs,
-		LastError:      stringToPtr(result.Error),
-		AuthMethod:     stringToPtr(result.AuthMethod),
-		AuthUsername:   stringToPtr(result.AuthUsername),
-		AuthRegistry:   stringToPtr(result.AuthRegistry),
-		UsedCredential: result.UsedCredential,
+		ID:              imageID,
+		Repository:      repo,
+		Tag:             tag,
+		HasUpdate:       result.HasUpdate,
+		UpdateType:      result.UpdateType,
+		CurrentVersion:  currentVersion,
+		LatestVersion:   stringToPtr(result.LatestVersion),
+		CurrentDigest:   stringToPtr(result.CurrentDigest),
+		LatestDigest:    stringToPtr(result.LatestDigest),
+		CheckTime:       result.CheckTime,
+		ResponseTimeMs:  result.ResponseTimeMs,
+		LastError:       stringToPtr(result.Error),
+		AuthMethod:      stringToPtr(result.AuthMethod),
+		AuthUsername:    stringToPtr(result.AuthUsername),
+		AuthRegistry:    stringToPtr(result.AuthRegistry),
+		UsedCredential:  result.UsedCredential,
+		ReleaseSource:   stringToPtr(result.ReleaseSource),
+		ReleaseVersion:  stringToPtr(result.ReleaseVersion),
+		ReleaseRevision: stringToPtr(result.ReleaseRevision),
+		ReleaseNotesURL: stringToPtr(result.ReleaseNotesURL),
+		ReleaseNotes:    stringToPtr(result.ReleaseNotes),
 	}
 }
 
@@ -879,6 +989,7 @@ func (s *ImageUpdateService) checkSingleImageInBatch(
 			remoteDigest, _, digestErr = rc.GetLatestDigestTimed(ctx, parts.Registry, normalizedRepo, parts.Tag, authHeader)
 			if digestErr == nil {
 				auth = &authDetails{Method: method, Username: username, Registry: parts.Registry}
+				token = authHeader
 			}
 		}
 	}
@@ -916,7 +1027,7 @@ func (s *ImageUpdateService) checkSingleImageInBatch(
 		}
 	}
 
-	return &imageupdate.Response{
+	result := &imageupdate.Response{
 		HasUpdate:      hasDigestUpdate,
 		UpdateType:     "digest",
 		CurrentDigest:  localDigest,
@@ -928,6 +1039,10 @@ func (s *ImageUpdateService) checkSingleImageInBatch(
 		AuthRegistry:   auth.Registry,
 		UsedCredential: auth.Method == "credential",
 	}
+	s.checkTagUpdateInternal(ctx, rc, parts, normalizedRepo, token, result)
+	s.attachReleaseInfoInternal(ctx, rc, parts, normalizedRepo, token, result)
+	result.ResponseTimeMs = int(time.Since(start).Milliseconds())
+	return result
 }
 
 func (s *ImageUpdateService) CheckMultipleImages(ctx context.Context, imageRefs []string, externalCreds []containerregistry.Credential) (map[string]*imageupdate.Response, error) {
@@ -999,19 +1114,24 @@ func (s *ImageUpdateService) CheckMultipleImages(ctx context.Context, imageRefs
 				// Construct image ref from repository and tag
 				imageRef := fmt.Sprintf("%s:%s", record.Repository, record.Tag)
 				updatesToNotify[imageRef] = &imageupdate.Response{
-					HasUpdate:      record.HasUpdate,
-					UpdateType:     record.UpdateType,
-					CurrentVersion: record.CurrentVersion,
-					LatestVersion:  stringPtrToString(record.LatestVersion),
-					CurrentDigest:  stringPtrToString(record.CurrentDigest),
-					LatestDigest:   stringPtrToString(record.LatestDigest),
-					CheckTime:      record.CheckTime,
-					ResponseTimeMs: record.ResponseTimeMs,
-					Error:          stringPtrToString(record.LastError),
-					AuthMethod:     stringPtrToString(record.AuthMethod),
-					AuthUsername:   stringPtrToString(record.AuthUsername),
-					AuthRegistry:   stringPtrToString(record.AuthRegistry),
-					UsedCredential: record.UsedCredential,
+					HasUpdate:       record.HasUpdate,
+					UpdateType:      record.UpdateType,
+					CurrentVersion:  record.CurrentVersion,
+					LatestVersion:   stringPtrToString(record.LatestVersion),
+					CurrentDigest:   stringPtrToString(record.CurrentDigest),
+					LatestDigest:    stringPtrToString(record.LatestDigest),
+					CheckTime:       record.CheckTime,
+					ResponseTimeMs:  record.ResponseTimeMs,
+					Error:           stringPtrToString(record.LastError),
+					AuthMethod:      stringPtrToString(record.AuthMethod),
+					AuthUsername:    stringPtrToString(record.AuthUsername),
+					AuthRegistry:    stringPtrToString(record.AuthRegistry),
+					UsedCredential:  record.UsedCredential,
+					ReleaseSource:   stringPtrToString(record.ReleaseSource),
+					ReleaseVersion:  stringPtrToString(record.ReleaseVersion),
+					ReleaseRevision: stringPtrToString(record.ReleaseRevision),
+					ReleaseNotesURL: stringPtrToString(record.ReleaseNotesURL),
+					ReleaseNotes:    stringPtrToString(record.ReleaseNotes),
 				}
 				imageIDsToMark = append(imageIDsToMark, imageID)
 			}
@@ -1124,6 +1244,7 @@ func (s *ImageUpdateService) GetUpdateSummary(ctx context.Context) (*imageupdate
 		TotalImages:       int(totalImages),
 		ImagesWithUpdates: int(imagesWithUpdates),
 		DigestUpdates:     int(digestUpdates),
+		TagUpdates:        int(tagUpdates),
 		ErrorsCount:       int(errorsCount),
 	}, nil
 }
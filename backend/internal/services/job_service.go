@@ -55,6 +55,8 @@ func (s *JobService) GetJobSchedules(ctx context.Context) jobschedule.Config {
 		PollingInterval:            s.settings.GetStringSetting(ctx, "pollingInterval", "0 */15 * * * *"),
 		ScheduledPruneInterval:     s.settings.GetStringSetting(ctx, "scheduledPruneInterval", "0 0 0 * * *"),
 		GitopsSyncInterval:         s.settings.GetStringSetting(ctx, "gitopsSyncInterval", "0 */5 * * * *"),
+		GitopsDriftInterval:        s.settings.GetStringSetting(ctx, "gitopsDriftInterval", "0 */10 * * * *"),
+		UpdateRolloutInterval:      s.settings.GetStringSetting(ctx, "updateRolloutInterval", "0 * * * * *"),
 		VulnerabilityScanInterval:  s.settings.GetStringSetting(ctx, "vulnerabilityScanInterval", "0 0 0 * * *"),
 	}
 }
@@ -81,6 +83,8 @@ func (s *JobService) UpdateJobSchedules(ctx context.Context, updates jobschedule
 		{key: "pollingInterval", current: current.PollingInterval, update: updates.PollingInterval},
 		{key: "scheduledPruneInterval", current: current.ScheduledPruneInterval, update: updates.ScheduledPruneInterval},
 		{key: "gitopsSyncInterval", current: current.GitopsSyncInterval, update: updates.GitopsSyncInterval},
+		{key: "gitopsDriftInterval", current: current.GitopsDriftInterval, update: updates.GitopsDriftInterval},
+		{key: "updateRolloutInterval", current: current.UpdateRolloutInterval, update: updates.UpdateRolloutInterval},
 		{key: "vulnerabilityScanInterval", current: current.VulnerabilityScanInterval, update: updates.VulnerabilityScanInterval},
 	}
 
@@ -221,6 +225,8 @@ func (s *JobService) getJobScheduleInternal(ctx context.Context, meta meta.JobMe
 		"pollingInterval":            "0 */15 * * * *",
 		"scheduledPruneInterval":     "0 0 0 * * *",
 		"gitopsSyncInterval":         "0 */5 * * * *",
+		"gitopsDriftInterval":        "0 */10 * * * *",
+		"updateRolloutInterval":      "0 * * * * *",
 		"vulnerabilityScanInterval":  "0 0 0 * * *",
 	}
 
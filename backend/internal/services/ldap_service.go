@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/go-ldap/ldap/v3"
+)
+
+var (
+	ErrLdapAuthDisabled   = errors.New("LDAP authentication is disabled")
+	ErrLdapNotConfigured  = errors.New("LDAP server URL and base DN are required")
+	ErrLdapUserConflict   = errors.New("a user with this username is not managed by LDAP")
+	errLdapMultipleUsers  = errors.New("LDAP user filter matched more than one entry")
+	errLdapInvalidMapping = errors.New("LDAP group role mapping must be a JSON object of group to role or list of roles")
+)
+
+const (
+	// ldapSearchSizeLimit bounds the group search, which may return many entries for large directories.
+	ldapSearchSizeLimit = 1000
+	// ldapTimeout bounds connecting and each operation.
+	ldapTimeout = 10 * time.Second
+)
+
+// LdapUser is a directory account that passed a bind with its own password.
+type LdapUser struct {
+	DN          string
+	Username    string
+	Email       string
+	DisplayName string
+	Groups      []string
+}
+
+type ldapConfig struct {
+	URL                  string
+	StartTLS             bool
+	SkipTLSVerify        bool
+	BindDN               string
+	BindPassword         string
+	BaseDN               string
+	UserFilter           string
+	UsernameAttribute    string
+	EmailAttribute       string
+	DisplayNameAttribute string
+	GroupAttribute       string
+	GroupFilter          string
+	GroupRoleMapping     map[string][]string
+}
+
+type LdapService struct {
+	settingsService *SettingsService
+	// tlsConfig overrides the TLS settings, e.g. to trust a test server.
+	tlsConfig *tls.Config
+}
+
+func NewLdapService(settingsService *SettingsService) *LdapService {
+	return &LdapService{settingsService: settingsService}
+}
+
+func (s *LdapService) IsEnabled(ctx context.Context) bool {
+	settings, err := s.settingsService.GetSettings(ctx)
+	return err == nil && settings.LdapEnabled.IsTrue()
+}
+
+// Authenticate finds the user with the service account, binds as the user to check the password
+// and collects the user's groups.
+func (s *LdapService) Authenticate(ctx context.Context, username, password string) (*LdapUser, error) {
+	cfg, err := s.getConfigInternal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(username) == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := s.connectInternal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := s.serviceBindInternal(conn, cfg); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.findUserInternal(conn, cfg, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to bind as LDAP user: %w", err)
+	}
+
+	user := &LdapUser{
+		DN:          entry.DN,
+		Username:    entry.GetEqualFoldAttributeValue(cfg.UsernameAttribute),
+		Email:       entry.GetEqualFoldAttributeValue(cfg.EmailAttribute),
+		DisplayName: entry.GetEqualFoldAttributeValue(cfg.DisplayNameAttribute),
+	}
+	if user.Username == "" {
+		user.Username = username
+	}
+
+	// Group lookups run as the service account, since users may not be allowed to read groups.
+	if err := s.serviceBindInternal(conn, cfg); err != nil {
+		return nil, err
+	}
+	user.Groups, err = s.findGroupsInternal(conn, cfg, entry, username)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// MappedRoles returns the roles granted by the user's groups, and every role the mapping manages
+// so that roles from groups the user left can be removed.
+func (s *LdapService) MappedRoles(ctx context.Context, groups []string) (granted []string, managed []string) {
+	cfg, err := s.getConfigInternal(ctx)
+	if err != nil {
+		return nil, nil
+	}
+	return mapLdapGroupsToRoles(cfg.GroupRoleMapping, groups)
+}
+
+func (s *LdapService) getConfigInternal(ctx context.Context) (*ldapConfig, error) {
+	settings, err := s.settingsService.GetSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	if !settings.LdapEnabled.IsTrue() {
+		return nil, ErrLdapAuthDisabled
+	}
+
+	cfg := &ldapConfig{
+		URL:                  strings.TrimSpace(settings.LdapUrl.Value),
+		StartTLS:             settings.LdapStartTls.IsTrue(),
+		SkipTLSVerify:        settings.LdapSkipTlsVerify.IsTrue(),
+		BindDN:               strings.TrimSpace(settings.LdapBindDn.Value),
+		BindPassword:         settings.LdapBindPassword.Value,
+		BaseDN:               strings.TrimSpace(settings.LdapBaseDn.Value),
+		UserFilter:           defaultIfEmptyInternal(settings.LdapUserFilter.Value, "(uid={username})"),
+		UsernameAttribute:    defaultIfEmptyInternal(settings.LdapUsernameAttribute.Value, "uid"),
+		EmailAttribute:       defaultIfEmptyInternal(settings.LdapEmailAttribute.Value, "mail"),
+		DisplayNameAttribute: defaultIfEmptyInternal(settings.LdapDisplayNameAttribute.Value, "cn"),
+		GroupAttribute:       strings.TrimSpace(settings.LdapGroupAttribute.Value),
+		GroupFilter:          strings.TrimSpace(settings.LdapGroupFilter.Value),
+	}
+	if cfg.URL == "" || cfg.BaseDN == "" {
+		return nil, ErrLdapNotConfigured
+	}
+
+	cfg.GroupRoleMapping, err = parseLdapGroupRoleMapping(settings.LdapGroupRoleMapping.Value)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (s *LdapService) connectInternal(cfg *ldapConfig) (*ldap.Conn, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid LDAP server URL %q", cfg.URL)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if s.tlsConfig != nil {
+		tlsConfig = s.tlsConfig.Clone()
+	}
+	// StartTLS doesn't derive the server name from the URL like ldaps:// does.
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = u.Hostname()
+	}
+	if cfg.SkipTLSVerify {
+		// #nosec G402 - verification is only skipped when the admin explicitly disables it
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	conn, err := ldap.DialURL(cfg.URL, ldap.DialWithTLSConfig(tlsConfig), ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP server: %w", err)
+	}
+	conn.SetTimeout(ldapTimeout)
+	if _, isTLS := conn.TLSConnectionState(); cfg.StartTLS && !isTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed to start TLS with LDAP server: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+func (s *LdapService) serviceBindInternal(conn *ldap.Conn, cfg *ldapConfig) error {
+	if cfg.BindDN == "" {
+		return nil
+	}
+	if err := conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+		return fmt.Errorf("failed to bind with the LDAP service account: %w", err)
+	}
+	return nil
+}
+
+func (s *LdapService) findUserInternal(conn *ldap.Conn, cfg *ldapConfig, username string) (*ldap.Entry, error) {
+	attributes := []string{cfg.UsernameAttribute, cfg.EmailAttribute, cfg.DisplayNameAttribute}
+	if cfg.GroupAttribute != "" {
+		attributes = append(attributes, cfg.GroupAttribute)
+	}
+
+	filter := strings.ReplaceAll(cfg.UserFilter, "{username}", ldap.EscapeFilter(username))
+	res, err := conn.Search(ldap.NewSearchRequest(cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(ldapTimeout/time.Second), false, filter, attributes, nil))
+	var entries []*ldap.Entry
+	if res != nil {
+		entries = res.Entries
+	}
+	switch {
+	case ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) || len(entries) > 1:
+		slog.Warn("LDAP user filter matched more than one entry", "username", username)
+		return nil, errLdapMultipleUsers
+	case err != nil:
+		return nil, fmt.Errorf("failed to search LDAP user: %w", err)
+	case len(entries) == 0:
+		return nil, ErrInvalidCredentials
+	}
+	return entries[0], nil
+}
+
+func (s *LdapService) findGroupsInternal(conn *ldap.Conn, cfg *ldapConfig, entry *ldap.Entry, username string) ([]string, error) {
+	var groups []string
+	if cfg.GroupAttribute != "" {
+		groups = append(groups, entry.GetEqualFoldAttributeValues(cfg.GroupAttribute)...)
+	}
+
+	if cfg.GroupFilter != "" {
+		filter := strings.NewReplacer(
+			"{dn}", ldap.EscapeFilter(entry.DN),
+			"{username}", ldap.EscapeFilter(username),
+		).Replace(cfg.GroupFilter)
+		res, err := conn.Search(ldap.NewSearchRequest(cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+			ldapSearchSizeLimit, int(ldapTimeout/time.Second), false, filter, []string{"cn"}, nil))
+		if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			return nil, fmt.Errorf("failed to search LDAP groups: %w", err)
+		}
+		if res != nil {
+			for _, e := range res.Entries {
+				groups = append(groups, e.DN)
+			}
+		}
+	}
+
+	out := make([]string, 0, len(groups))
+	for _, g := range groups {
+		if !slices.ContainsFunc(out, func(o string) bool { return strings.EqualFold(o, g) }) {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+// parseLdapGroupRoleMapping parses a JSON object whose keys are group DNs or names and whose values
+// are a role or a list of roles, e.g. {"cn=admins,ou=groups,dc=example,dc=com": "admin"}.
+func parseLdapGroupRoleMapping(raw string) (map[string][]string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var parsed map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, errLdapInvalidMapping
+	}
+
+	mapping := make(map[string][]string, len(parsed))
+	for group, value := range parsed {
+		var roles []string
+		var single string
+		if err := json.Unmarshal(value, &single); err == nil {
+			roles = []string{single}
+		} else if err := json.Unmarshal(value, &roles); err != nil {
+			return nil, errLdapInvalidMapping
+		}
+
+		group = strings.ToLower(strings.TrimSpace(group))
+		if group == "" {
+			return nil, errLdapInvalidMapping
+		}
+		for _, r := range roles {
+			if r = strings.TrimSpace(r); r != "" {
+				mapping[group] = append(mapping[group], r)
+			}
+		}
+	}
+	return mapping, nil
+}
+
+// mapLdapGroupsToRoles matches groups against the mapping by full DN or by the value of the first
+// RDN, so "admins" matches "cn=admins,ou=groups,dc=example,dc=com".
+func mapLdapGroupsToRoles(mapping map[string][]string, groups []string) (granted []string, managed []string) {
+	for _, roles := range mapping {
+		for _, r := range roles {
+			managed = addRole(managed, r)
+		}
+	}
+
+	for _, g := range groups {
+		dn := strings.ToLower(strings.TrimSpace(g))
+		for _, key := range []string{dn, ldapGroupNameInternal(dn)} {
+			for _, r := range mapping[key] {
+				granted = addRole(granted, r)
+			}
+		}
+	}
+	return granted, managed
+}
+
+func ldapGroupNameInternal(dn string) string {
+	rdn, _, _ := strings.Cut(dn, ",")
+	_, name, found := strings.Cut(rdn, "=")
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(name)
+}
+
+func defaultIfEmptyInternal(value, fallback string) string {
+	if v := strings.TrimSpace(value); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// applyLdapRoles syncs the roles managed by the group mapping, leaving other roles untouched.
+func applyLdapRoles(roles models.StringSlice, granted, managed []string) models.StringSlice {
+	for _, r := range managed {
+		want := slices.ContainsFunc(granted, func(g string) bool { return strings.EqualFold(g, r) })
+		has := hasRole(roles, r)
+		switch {
+		case want && !has:
+			roles = addRole(roles, r)
+		case !want && has:
+			roles = removeRole(roles, r)
+		}
+	}
+	return roles
+}
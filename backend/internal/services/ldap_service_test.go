@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	glsqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/ldap/ldaptest"
+)
+
+func setupLdapTestAuthService(t *testing.T, settings map[string]string) (*AuthService, *ldaptest.Server) {
+	t.Helper()
+	ctx := context.Background()
+
+	srv, err := ldaptest.NewServer(
+		ldaptest.Entry{DN: "cn=arcane,ou=services,dc=example,dc=com", Password: "service-secret"},
+		ldaptest.Entry{
+			DN:       "uid=jane,ou=people,dc=example,dc=com",
+			Password: "jane-secret",
+			Attributes: map[string][]string{
+				"objectClass": {"inetOrgPerson"},
+				"uid":         {"jane"},
+				"cn":          {"Jane Doe"},
+				"mail":        {"jane@example.com"},
+				"memberOf":    {"cn=admins,ou=groups,dc=example,dc=com"},
+			},
+		},
+		ldaptest.Entry{
+			DN:         "cn=operators,ou=groups,dc=example,dc=com",
+			Attributes: map[string][]string{"objectClass": {"groupOfNames"}, "cn": {"operators"}, "member": {"uid=jane,ou=people,dc=example,dc=com"}},
+		},
+	)
+	require.NoError(t, err)
+	t.Cleanup(srv.Close)
+
+	db, err := gorm.Open(glsqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.SettingVariable{}, &models.User{}, &models.Event{}))
+	wrapped := &database.DB{DB: db}
+
+	settingsSvc, err := NewSettingsService(ctx, wrapped)
+	require.NoError(t, err)
+	require.NoError(t, settingsSvc.EnsureDefaultSettings(ctx))
+
+	values := map[string]string{
+		"ldapEnabled":          "true",
+		"ldapUrl":              srv.URL,
+		"ldapStartTls":         "true",
+		"ldapBindDn":           "cn=arcane,ou=services,dc=example,dc=com",
+		"ldapBindPassword":     "service-secret",
+		"ldapBaseDn":           "dc=example,dc=com",
+		"ldapGroupFilter":      "(&(objectClass=groupOfNames)(member={dn}))",
+		"ldapGroupRoleMapping": `{"admins": "admin", "cn=operators,ou=groups,dc=example,dc=com": ["operator"]}`,
+	}
+	for k, v := range settings {
+		values[k] = v
+	}
+	for k, v := range values {
+		require.NoError(t, settingsSvc.SetStringSetting(ctx, k, v))
+	}
+
+	ldapSvc := NewLdapService(settingsSvc)
+	ldapSvc.tlsConfig = &tls.Config{RootCAs: srv.CertPool(), MinVersion: tls.VersionTLS12}
+
+	authSvc := newTestAuthService("")
+	authSvc.userService = NewUserService(wrapped)
+	authSvc.settingsService = settingsSvc
+	authSvc.eventService = NewEventService(wrapped)
+	authSvc.ldapService = ldapSvc
+	return authSvc, srv
+}
+
+func TestLdapLogin_ProvisionsUserWithMappedRoles(t *testing.T) {
+	ctx := context.Background()
+	authSvc, _ := setupLdapTestAuthService(t, map[string]string{"authLocalEnabled": "false"})
+
+	_, _, _, err := authSvc.Login(ctx, "jane", "wrong")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	_, _, _, err = authSvc.Login(ctx, "nobody", "jane-secret")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	// Filter metacharacters in the username are escaped, not interpreted.
+	_, _, _, err = authSvc.Login(ctx, "*", "jane-secret")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	user, tokens, challenge, err := authSvc.Login(ctx, "jane", "jane-secret")
+	require.NoError(t, err)
+	require.Nil(t, challenge)
+	require.NotNil(t, tokens)
+	assert.Equal(t, "jane", user.Username)
+	require.NotNil(t, user.LdapDn)
+	assert.Equal(t, "uid=jane,ou=people,dc=example,dc=com", *user.LdapDn)
+	require.NotNil(t, user.Email)
+	assert.Equal(t, "jane@example.com", *user.Email)
+	require.NotNil(t, user.DisplayName)
+	assert.Equal(t, "Jane Doe", *user.DisplayName)
+	assert.ElementsMatch(t, []string{"user", "admin", "operator"}, user.Roles)
+
+	// The directory owns the password.
+	require.ErrorIs(t, authSvc.ChangePassword(ctx, user.ID, "", "a-new-password"), ErrLdapManagedPassword)
+}
+
+func TestLdapLogin_SyncsRolesAndKeepsLocalAccounts(t *testing.T) {
+	ctx := context.Background()
+	authSvc, _ := setupLdapTestAuthService(t, nil)
+
+	// Sync directly rather than through Login, whose last login update runs in the background.
+	syncUser := func(mapping string) *models.User {
+		t.Helper()
+		require.NoError(t, authSvc.settingsService.SetStringSetting(ctx, "ldapGroupRoleMapping", mapping))
+		ldapUser, err := authSvc.ldapService.Authenticate(ctx, "jane", "jane-secret")
+		require.NoError(t, err)
+		user, err := authSvc.findOrCreateLdapUser(ctx, ldapUser)
+		require.NoError(t, err)
+		return user
+	}
+
+	user := syncUser(`{"admins": "admin", "operators": "operator"}`)
+	assert.ElementsMatch(t, []string{"user", "admin", "operator"}, user.Roles)
+
+	// Roles outside the mapping are kept, mapped roles follow the groups.
+	user.Roles = append(user.Roles, "auditor")
+	_, err := authSvc.userService.UpdateUser(ctx, user)
+	require.NoError(t, err)
+
+	user = syncUser(`{"developers": "developer", "operators": "operator"}`)
+	assert.ElementsMatch(t, []string{"user", "admin", "operator", "auditor"}, user.Roles)
+
+	user = syncUser(`{"admins": ["developer"], "other": "admin"}`)
+	assert.ElementsMatch(t, []string{"user", "operator", "auditor", "developer"}, user.Roles)
+
+	// A local account with the same username as a directory user is never taken over.
+	authSvc2, _ := setupLdapTestAuthService(t, nil)
+	hash, err := authSvc2.userService.hashPassword("local-password")
+	require.NoError(t, err)
+	_, err = authSvc2.userService.CreateUser(ctx, &models.User{BaseModel: models.BaseModel{ID: "local-jane"}, Username: "jane", PasswordHash: hash})
+	require.NoError(t, err)
+
+	_, _, _, err = authSvc2.Login(ctx, "jane", "jane-secret")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	local, _, _, err := authSvc2.Login(ctx, "jane", "local-password")
+	require.NoError(t, err)
+	assert.Equal(t, "local-jane", local.ID)
+	assert.Nil(t, local.LdapDn)
+}
+
+func TestParseLdapGroupRoleMapping(t *testing.T) {
+	mapping, err := parseLdapGroupRoleMapping(`{"CN=Admins,DC=example,DC=com": "admin", "ops": ["operator", " viewer "]}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"cn=admins,dc=example,dc=com": {"admin"},
+		"ops":                         {"operator", "viewer"},
+	}, mapping)
+
+	granted, managed := mapLdapGroupsToRoles(mapping, []string{"cn=admins,dc=example,dc=com", "cn=ops,ou=groups,dc=example,dc=com"})
+	assert.ElementsMatch(t, []string{"admin", "operator", "viewer"}, granted)
+	assert.ElementsMatch(t, []string{"admin", "operator", "viewer"}, managed)
+
+	for _, raw := range []string{`[]`, `{"admins": 1}`, `{"": "admin"}`, `not json`} {
+		_, err := parseLdapGroupRoleMapping(raw)
+		assert.Error(t, err, raw)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"log/slog"
 	"net/mail"
 	"strings"
@@ -166,6 +167,7 @@ func (s *NotificationService) SendImageUpdateNotification(ctx context.Context, i
 			"hasUpdate":     updateInfo.HasUpdate,
 			"currentDigest": updateInfo.CurrentDigest,
 			"latestDigest":  updateInfo.LatestDigest,
+			"releaseNotes":  updateInfo.ReleaseNotesURL,
 			"updateType":    updateInfo.UpdateType,
 			"eventType":     string(eventType),
 		})
@@ -384,6 +386,9 @@ func (s *NotificationService) sendDiscordNotification(ctx context.Context, image
 	if updateInfo.LatestDigest != "" {
 		message += fmt.Sprintf("**Latest Digest:** `%s`\n", updateInfo.LatestDigest)
 	}
+	if updateInfo.ReleaseNotesURL != "" {
+		message += fmt.Sprintf("**Release Notes:** %s\n", updateInfo.ReleaseNotesURL)
+	}
 
 	if err := notifications.SendDiscord(ctx, discordConfig, message); err != nil {
 		return fmt.Errorf("failed to send Discord notification: %w", err)
@@ -438,6 +443,9 @@ func (s *NotificationService) sendTelegramNotification(ctx context.Context, imag
 	if updateInfo.LatestDigest != "" {
 		message += fmt.Sprintf("<b>Latest Digest:</b> <code>%s</code>\n", updateInfo.LatestDigest)
 	}
+	if updateInfo.ReleaseNotesURL != "" {
+		message += fmt.Sprintf("<b>Release Notes:</b> %s\n", html.EscapeString(updateInfo.ReleaseNotesURL))
+	}
 
 	// Set parse mode to HTML if not already set
 	if telegramConfig.ParseMode == "" {
@@ -502,15 +510,16 @@ func (s *NotificationService) renderEmailTemplate(imageRef string, updateInfo *i
 	appURL := s.config.GetAppURL()
 	logoURL := appURL + logoURLPath
 	data := map[string]interface{}{
-		"LogoURL":       logoURL,
-		"AppURL":        appURL,
-		"Environment":   "Local Docker",
-		"ImageRef":      imageRef,
-		"HasUpdate":     updateInfo.HasUpdate,
-		"UpdateType":    updateInfo.UpdateType,
-		"CurrentDigest": updateInfo.CurrentDigest,
-		"LatestDigest":  updateInfo.LatestDigest,
-		"CheckTime":     updateInfo.CheckTime.Format(time.RFC1123),
+		"LogoURL":         logoURL,
+		"AppURL":          appURL,
+		"Environment":     "Local Docker",
+		"ImageRef":        imageRef,
+		"HasUpdate":       updateInfo.HasUpdate,
+		"UpdateType":      updateInfo.UpdateType,
+		"CurrentDigest":   updateInfo.CurrentDigest,
+		"LatestDigest":    updateInfo.LatestDigest,
+		"CheckTime":       updateInfo.CheckTime.Format(time.RFC1123),
+		"ReleaseNotesURL": updateInfo.ReleaseNotesURL,
 	}
 
 	htmlContent, err := resources.FS.ReadFile("email-templates/image-update_html.tmpl")
@@ -1069,12 +1078,16 @@ func (s *NotificationService) sendBatchDiscordNotification(ctx context.Context,
 		message += fmt.Sprintf("**%s**\n"+
 			"• **Type:** %s\n"+
 			"• **Current:** `%s`\n"+
-			"• **Latest:** `%s`\n\n",
+			"• **Latest:** `%s`\n",
 			imageRef,
 			update.UpdateType,
 			update.CurrentDigest,
 			update.LatestDigest,
 		)
+		if update.ReleaseNotesURL != "" {
+			message += fmt.Sprintf("• **Release notes:** %s\n", update.ReleaseNotesURL)
+		}
+		message += "\n"
 	}
 
 	if err := notifications.SendDiscord(ctx, discordConfig, message); err != nil {
@@ -1112,12 +1125,16 @@ func (s *NotificationService) sendBatchTelegramNotification(ctx context.Context,
 		message += fmt.Sprintf("*%s*\n"+
 			"• *Type:* %s\n"+
 			"• *Current:* `%s`\n"+
-			"• *Latest:* `%s`\n\n",
+			"• *Latest:* `%s`\n",
 			imageRef,
 			update.UpdateType,
 			update.CurrentDigest,
 			update.LatestDigest,
 		)
+		if update.ReleaseNotesURL != "" {
+			message += fmt.Sprintf("• *Release notes:* %s\n", update.ReleaseNotesURL)
+		}
+		message += "\n"
 	}
 
 	if err := notifications.SendTelegram(ctx, telegramConfig, message); err != nil {
@@ -1297,6 +1314,9 @@ func (s *NotificationService) sendSignalNotification(ctx context.Context, imageR
 	if updateInfo.LatestDigest != "" {
 		message += fmt.Sprintf("Latest Digest: %s\n", updateInfo.LatestDigest)
 	}
+	if updateInfo.ReleaseNotesURL != "" {
+		message += fmt.Sprintf("Release Notes: %s\n", updateInfo.ReleaseNotesURL)
+	}
 
 	if err := notifications.SendSignal(ctx, signalConfig, message); err != nil {
 		return fmt.Errorf("failed to send Signal notification: %w", err)
@@ -1421,12 +1441,16 @@ func (s *NotificationService) sendBatchSignalNotification(ctx context.Context, u
 		message += fmt.Sprintf("%s\n"+
 			"• Type: %s\n"+
 			"• Current: %s\n"+
-			"• Latest: %s\n\n",
+			"• Latest: %s\n",
 			imageRef,
 			update.UpdateType,
 			update.CurrentDigest,
 			update.LatestDigest,
 		)
+		if update.ReleaseNotesURL != "" {
+			message += fmt.Sprintf("• Release notes: %s\n", update.ReleaseNotesURL)
+		}
+		message += "\n"
 	}
 
 	if err := notifications.SendSignal(ctx, signalConfig, message); err != nil {
@@ -1477,6 +1501,9 @@ func (s *NotificationService) sendSlackNotification(ctx context.Context, imageRe
 	if updateInfo.LatestDigest != "" {
 		message += fmt.Sprintf("*Latest Digest:* `%s`\n", updateInfo.LatestDigest)
 	}
+	if updateInfo.ReleaseNotesURL != "" {
+		message += fmt.Sprintf("*Release Notes:* %s\n", updateInfo.ReleaseNotesURL)
+	}
 
 	if err := notifications.SendSlack(ctx, slackConfig, message); err != nil {
 		return fmt.Errorf("failed to send Slack notification: %w", err)
@@ -1558,12 +1585,16 @@ func (s *NotificationService) sendBatchSlackNotification(ctx context.Context, up
 		message += fmt.Sprintf("*%s*\n"+
 			"• *Type:* %s\n"+
 			"• *Current:* `%s`\n"+
-			"• *Latest:* `%s`\n\n",
+			"• *Latest:* `%s`\n",
 			imageRef,
 			update.UpdateType,
 			update.CurrentDigest,
 			update.LatestDigest,
 		)
+		if update.ReleaseNotesURL != "" {
+			message += fmt.Sprintf("• *Release notes:* %s\n", update.ReleaseNotesURL)
+		}
+		message += "\n"
 	}
 
 	if err := notifications.SendSlack(ctx, slackConfig, message); err != nil {
@@ -1614,6 +1645,9 @@ func (s *NotificationService) sendNtfyNotification(ctx context.Context, imageRef
 	if updateInfo.LatestDigest != "" {
 		message += fmt.Sprintf("Latest Digest: %s\n", updateInfo.LatestDigest)
 	}
+	if updateInfo.ReleaseNotesURL != "" {
+		message += fmt.Sprintf("Release Notes: %s\n", updateInfo.ReleaseNotesURL)
+	}
 
 	if err := notifications.SendNtfy(ctx, ntfyConfig, message); err != nil {
 		return fmt.Errorf("failed to send Ntfy notification: %w", err)
@@ -1697,12 +1731,16 @@ func (s *NotificationService) sendBatchNtfyNotification(ctx context.Context, upd
 		message += fmt.Sprintf("%s\n"+
 			"• Type: %s\n"+
 			"• Current: %s\n"+
-			"• Latest: %s\n\n",
+			"• Latest: %s\n",
 			imageRef,
 			update.UpdateType,
 			update.CurrentDigest,
 			update.LatestDigest,
 		)
+		if update.ReleaseNotesURL != "" {
+			message += fmt.Sprintf("• Release notes: %s\n", update.ReleaseNotesURL)
+		}
+		message += "\n"
 	}
 
 	if err := notifications.SendNtfy(ctx, ntfyConfig, message); err != nil {
@@ -1754,6 +1792,9 @@ func (s *NotificationService) sendPushoverNotification(ctx context.Context, imag
 	if updateInfo.LatestDigest != "" {
 		message += fmt.Sprintf("Latest Digest: %s\n", updateInfo.LatestDigest)
 	}
+	if updateInfo.ReleaseNotesURL != "" {
+		message += fmt.Sprintf("Release Notes: %s\n", updateInfo.ReleaseNotesURL)
+	}
 
 	if err := notifications.SendPushover(ctx, pushoverConfig, message); err != nil {
 		return fmt.Errorf("failed to send Pushover notification: %w", err)
@@ -1837,12 +1878,16 @@ func (s *NotificationService) sendBatchPushoverNotification(ctx context.Context,
 		message += fmt.Sprintf("%s\n"+
 			"• Type: %s\n"+
 			"• Current: %s\n"+
-			"• Latest: %s\n\n",
+			"• Latest: %s\n",
 			imageRef,
 			update.UpdateType,
 			update.CurrentDigest,
 			update.LatestDigest,
 		)
+		if update.ReleaseNotesURL != "" {
+			message += fmt.Sprintf("• Release notes: %s\n", update.ReleaseNotesURL)
+		}
+		message += "\n"
 	}
 
 	if err := notifications.SendPushover(ctx, pushoverConfig, message); err != nil {
@@ -1884,6 +1929,9 @@ func (s *NotificationService) sendGenericNotification(ctx context.Context, image
 	if updateInfo.LatestDigest != "" {
 		message += fmt.Sprintf("Latest Digest: %s\n", updateInfo.LatestDigest)
 	}
+	if updateInfo.ReleaseNotesURL != "" {
+		message += fmt.Sprintf("Release Notes: %s\n", updateInfo.ReleaseNotesURL)
+	}
 
 	// Use SendGenericWithTitle to include a title
 	title := "Container Image Update"
@@ -2365,12 +2413,16 @@ func (s *NotificationService) sendBatchGenericNotification(ctx context.Context,
 		message += fmt.Sprintf("%s\n"+
 			"• Type: %s\n"+
 			"• Current: %s\n"+
-			"• Latest: %s\n\n",
+			"• Latest: %s\n",
 			imageRef,
 			update.UpdateType,
 			update.CurrentDigest,
 			update.LatestDigest,
 		)
+		if update.ReleaseNotesURL != "" {
+			message += fmt.Sprintf("• Release notes: %s\n", update.ReleaseNotesURL)
+		}
+		message += "\n"
 	}
 
 	if err := notifications.SendGenericWithTitle(ctx, genericConfig, title, message); err != nil {
@@ -2415,6 +2467,9 @@ func (s *NotificationService) sendGotifyNotification(ctx context.Context, imageR
 	if updateInfo.LatestDigest != "" {
 		message += fmt.Sprintf("Latest Digest: %s\n", updateInfo.LatestDigest)
 	}
+	if updateInfo.ReleaseNotesURL != "" {
+		message += fmt.Sprintf("Release Notes: %s\n", updateInfo.ReleaseNotesURL)
+	}
 
 	if err := notifications.SendGotify(ctx, gotifyConfig, message); err != nil {
 		return fmt.Errorf("failed to send Gotify notification: %w", err)
@@ -2491,12 +2546,16 @@ func (s *NotificationService) sendBatchGotifyNotification(ctx context.Context, u
 		message += fmt.Sprintf("%s\n"+
 			"• Type: %s\n"+
 			"• Current: %s\n"+
-			"• Latest: %s\n\n",
+			"• Latest: %s\n",
 			imageRef,
 			update.UpdateType,
 			update.CurrentDigest,
 			update.LatestDigest,
 		)
+		if update.ReleaseNotesURL != "" {
+			message += fmt.Sprintf("• Release notes: %s\n", update.ReleaseNotesURL)
+		}
+		message += "\n"
 	}
 
 	if err := notifications.SendGotify(ctx, gotifyConfig, message); err != nil {
@@ -2539,6 +2598,9 @@ func (s *NotificationService) sendMatrixNotification(ctx context.Context, imageR
 	if updateInfo.LatestDigest != "" {
 		message += fmt.Sprintf("Latest Digest: %s\n", updateInfo.LatestDigest)
 	}
+	if updateInfo.ReleaseNotesURL != "" {
+		message += fmt.Sprintf("Release Notes: %s\n", updateInfo.ReleaseNotesURL)
+	}
 
 	if err := notifications.SendMatrix(ctx, matrixConfig, message); err != nil {
 		return fmt.Errorf("failed to send Matrix notification: %w", err)
@@ -2613,12 +2675,16 @@ func (s *NotificationService) sendBatchMatrixNotification(ctx context.Context, u
 		message += fmt.Sprintf("%s\n"+
 			"• Type: %s\n"+
 			"• Current: %s\n"+
-			"• Latest: %s\n\n",
+			"• Latest: %s\n",
 			imageRef,
 			update.UpdateType,
 			update.CurrentDigest,
 			update.LatestDigest,
 		)
+		if update.ReleaseNotesURL != "" {
+			message += fmt.Sprintf("• Release notes: %s\n", update.ReleaseNotesURL)
+		}
+		message += "\n"
 	}
 
 	if err := notifications.SendMatrix(ctx, matrixConfig, message); err != nil {
@@ -3073,6 +3139,272 @@ func (s *NotificationService) sendGenericPruneNotification(ctx context.Context,
 	return notifications.SendGenericWithTitle(ctx, genericConfig, "System Prune Report", message)
 }
 
+// GitOpsDriftNotificationPayload describes a GitOps-managed project that no longer matches its sync.
+type GitOpsDriftNotificationPayload struct {
+	SyncName    string
+	ProjectName string
+	Reasons     []string
+}
+
+// SendGitOpsDriftNotification notifies all enabled providers that have the gitops_drift event enabled.
+func (s *NotificationService) SendGitOpsDriftNotification(ctx context.Context, payload GitOpsDriftNotificationPayload) error {
+	settings, err := s.GetAllSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get notification settings: %w", err)
+	}
+
+	var errors []string
+	for _, setting := range settings {
+		if !setting.Enabled {
+			continue
+		}
+
+		if !s.isEventEnabled(setting.Config, models.NotificationEventGitOpsDrift) {
+			continue
+		}
+
+		var sendErr error
+		switch setting.Provider {
+		case models.NotificationProviderDiscord:
+			sendErr = s.sendDiscordGitOpsDriftNotification(ctx, payload, setting.Config)
+		case models.NotificationProviderEmail:
+			sendErr = s.sendEmailGitOpsDriftNotification(ctx, payload, setting.Config)
+		case models.NotificationProviderTelegram:
+			sendErr = s.sendTelegramGitOpsDriftNotification(ctx, payload, setting.Config)
+		case models.NotificationProviderSignal:
+			sendErr = s.sendSignalGitOpsDriftNotification(ctx, payload, setting.Config)
+		case models.NotificationProviderSlack:
+			sendErr = s.sendSlackGitOpsDriftNotification(ctx, payload, setting.Config)
+		case models.NotificationProviderNtfy:
+			sendErr = s.sendNtfyGitOpsDriftNotification(ctx, payload, setting.Config)
+		case models.NotificationProviderPushover:
+			sendErr = s.sendPushoverGitOpsDriftNotification(ctx, payload, setting.Config)
+		case models.NotificationProviderGotify:
+			sendErr = s.sendGotifyGitOpsDriftNotification(ctx, payload, setting.Config)
+		case models.NotificationProviderMatrix:
+			sendErr = s.sendMatrixGitOpsDriftNotification(ctx, payload, setting.Config)
+		case models.NotificationProviderGeneric:
+			sendErr = s.sendGenericGitOpsDriftNotification(ctx, payload, setting.Config)
+		default:
+			slog.WarnContext(ctx, "Unknown notification provider", "provider", setting.Provider)
+			continue
+		}
+
+		status := "success"
+		var errMsg *string
+		if sendErr != nil {
+			status = "failed"
+			msg := sendErr.Error()
+			errMsg = &msg
+			errors = append(errors, fmt.Sprintf("%s: %s", setting.Provider, msg))
+		}
+
+		s.logNotification(ctx, setting.Provider, payload.ProjectName, status, errMsg, models.JSON{
+			"syncName":  payload.SyncName,
+			"reasons":   payload.Reasons,
+			"eventType": string(models.NotificationEventGitOpsDrift),
+		})
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("notification errors: %s", strings.Join(errors, "; "))
+	}
+
+	return nil
+}
+
+// gitOpsDriftMessageInternal renders the drift reasons as a plain text list, prefixing every
+// line with bullet.
+func (s *NotificationService) gitOpsDriftMessageInternal(payload GitOpsDriftNotificationPayload, bullet string) string {
+	var b strings.Builder
+	for _, reason := range payload.Reasons {
+		b.WriteString(bullet + reason + "\n")
+	}
+	return b.String()
+}
+
+func (s *NotificationService) sendDiscordGitOpsDriftNotification(ctx context.Context, payload GitOpsDriftNotificationPayload, config models.JSON) error {
+	var discordConfig models.DiscordConfig
+	if err := s.unmarshalConfigInternal(config, &discordConfig); err != nil {
+		return err
+	}
+
+	if discordConfig.WebhookID == "" || discordConfig.Token == "" {
+		return fmt.Errorf("discord webhook ID or token not configured")
+	}
+
+	s.decryptDiscordTokenInternal(&discordConfig)
+
+	message := fmt.Sprintf("**🔀 GitOps Drift Detected**\n\n"+
+		"**Project:** %s\n"+
+		"**Sync:** %s\n\n"+
+		"%s",
+		payload.ProjectName, payload.SyncName, s.gitOpsDriftMessageInternal(payload, "- "))
+
+	if err := notifications.SendDiscord(ctx, discordConfig, message); err != nil {
+		return fmt.Errorf("failed to send Discord notification: %w", err)
+	}
+
+	return nil
+}
+
+func (s *NotificationService) sendTelegramGitOpsDriftNotification(ctx context.Context, payload GitOpsDriftNotificationPayload, config models.JSON) error {
+	var telegramConfig models.TelegramConfig
+	if err := s.unmarshalConfigInternal(config, &telegramConfig); err != nil {
+		return err
+	}
+
+	if telegramConfig.BotToken == "" || len(telegramConfig.ChatIDs) == 0 {
+		return fmt.Errorf("telegram bot token or chat IDs not configured")
+	}
+
+	s.decryptTelegramTokenInternal(&telegramConfig)
+
+	message := fmt.Sprintf("🔀 <b>GitOps Drift Detected</b>\n\n"+
+		"<b>Project:</b> %s\n"+
+		"<b>Sync:</b> %s\n\n"+
+		"%s",
+		payload.ProjectName, payload.SyncName, s.gitOpsDriftMessageInternal(payload, "- "))
+
+	if telegramConfig.ParseMode == "" {
+		telegramConfig.ParseMode = "HTML"
+	}
+
+	if err := notifications.SendTelegram(ctx, telegramConfig, message); err != nil {
+		return fmt.Errorf("failed to send Telegram notification: %w", err)
+	}
+
+	return nil
+}
+
+func (s *NotificationService) sendEmailGitOpsDriftNotification(ctx context.Context, payload GitOpsDriftNotificationPayload, config models.JSON) error {
+	var emailConfig models.EmailConfig
+	if err := s.unmarshalConfigInternal(config, &emailConfig); err != nil {
+		return err
+	}
+
+	if err := s.validateEmailConfigInternal(&emailConfig); err != nil {
+		return err
+	}
+
+	s.decryptEmailPasswordInternal(&emailConfig)
+
+	appURL := s.config.GetAppURL()
+	htmlBody, _, err := s.renderTemplatesInternal("gitops-drift", map[string]interface{}{
+		"LogoURL":     appURL + logoURLPath,
+		"AppURL":      appURL,
+		"SyncName":    payload.SyncName,
+		"ProjectName": payload.ProjectName,
+		"Reasons":     payload.Reasons,
+		"Time":        time.Now().Format(time.RFC1123),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render email template: %w", err)
+	}
+
+	subject := fmt.Sprintf("GitOps Drift Detected: %s", notifications.SanitizeForEmail(payload.ProjectName))
+	if err := notifications.SendEmail(ctx, emailConfig, subject, htmlBody); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *NotificationService) sendSignalGitOpsDriftNotification(ctx context.Context, payload GitOpsDriftNotificationPayload, config models.JSON) error {
+	var signalConfig models.SignalConfig
+	if err := s.unmarshalConfigInternal(config, &signalConfig); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("🔀 GitOps Drift Detected\n\nProject: %s\nSync: %s\n\n%s",
+		payload.ProjectName, payload.SyncName, s.gitOpsDriftMessageInternal(payload, "- "))
+
+	return notifications.SendSignal(ctx, signalConfig, message)
+}
+
+func (s *NotificationService) sendSlackGitOpsDriftNotification(ctx context.Context, payload GitOpsDriftNotificationPayload, config models.JSON) error {
+	var slackConfig models.SlackConfig
+	if err := s.unmarshalConfigInternal(config, &slackConfig); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("*🔀 GitOps Drift Detected*\n\n"+
+		"*Project:* %s\n"+
+		"*Sync:* %s\n\n"+
+		"%s",
+		payload.ProjectName, payload.SyncName, s.gitOpsDriftMessageInternal(payload, "- "))
+
+	return notifications.SendSlack(ctx, slackConfig, message)
+}
+
+func (s *NotificationService) sendNtfyGitOpsDriftNotification(ctx context.Context, payload GitOpsDriftNotificationPayload, config models.JSON) error {
+	var ntfyConfig models.NtfyConfig
+	if err := s.unmarshalConfigInternal(config, &ntfyConfig); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("GitOps drift detected in project %s (sync %s):\n%s",
+		payload.ProjectName, payload.SyncName, s.gitOpsDriftMessageInternal(payload, "- "))
+
+	return notifications.SendNtfy(ctx, ntfyConfig, message)
+}
+
+func (s *NotificationService) sendPushoverGitOpsDriftNotification(ctx context.Context, payload GitOpsDriftNotificationPayload, config models.JSON) error {
+	var pushoverConfig models.PushoverConfig
+	if err := s.unmarshalConfigInternal(config, &pushoverConfig); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Project %s no longer matches sync %s:\n%s",
+		payload.ProjectName, payload.SyncName, s.gitOpsDriftMessageInternal(payload, "- "))
+
+	if pushoverConfig.Title == "" {
+		pushoverConfig.Title = "GitOps Drift Detected"
+	}
+
+	return notifications.SendPushover(ctx, pushoverConfig, message)
+}
+
+func (s *NotificationService) sendGotifyGitOpsDriftNotification(ctx context.Context, payload GitOpsDriftNotificationPayload, config models.JSON) error {
+	var gotifyConfig models.GotifyConfig
+	if err := s.unmarshalConfigInternal(config, &gotifyConfig); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Project %s no longer matches sync %s:\n%s",
+		payload.ProjectName, payload.SyncName, s.gitOpsDriftMessageInternal(payload, "- "))
+
+	if gotifyConfig.Title == "" {
+		gotifyConfig.Title = "GitOps Drift Detected"
+	}
+
+	return notifications.SendGotify(ctx, gotifyConfig, message)
+}
+
+func (s *NotificationService) sendMatrixGitOpsDriftNotification(ctx context.Context, payload GitOpsDriftNotificationPayload, config models.JSON) error {
+	var matrixConfig models.MatrixConfig
+	if err := s.unmarshalConfigInternal(config, &matrixConfig); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("GitOps drift detected in project %s (sync %s):\n%s",
+		payload.ProjectName, payload.SyncName, s.gitOpsDriftMessageInternal(payload, "- "))
+
+	return notifications.SendMatrix(ctx, matrixConfig, message)
+}
+
+func (s *NotificationService) sendGenericGitOpsDriftNotification(ctx context.Context, payload GitOpsDriftNotificationPayload, config models.JSON) error {
+	var genericConfig models.GenericConfig
+	if err := s.unmarshalConfigInternal(config, &genericConfig); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Project %s no longer matches sync %s:\n%s",
+		payload.ProjectName, payload.SyncName, s.gitOpsDriftMessageInternal(payload, "- "))
+
+	return notifications.SendGenericWithTitle(ctx, genericConfig, "GitOps Drift Detected", message)
+}
+
 // Helper methods to reduce code duplication
 func (s *NotificationService) unmarshalConfigInternal(config models.JSON, dest interface{}) error {
 	configBytes, err := json.Marshal(config)
@@ -0,0 +1,505 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getarcaneapp/arcane/backend/internal/config"
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/stringutils"
+	"github.com/getarcaneapp/arcane/types/auth"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPasskeyInvalid        = errors.New("passkey verification failed")
+	ErrPasskeySessionExpired = errors.New("passkey ceremony expired or unknown")
+	ErrPasskeyNotFound       = errors.New("passkey not found")
+	ErrPasskeyTooManyPending = errors.New("too many pending passkey ceremonies, try again later")
+)
+
+const (
+	passkeyRelyingPartyName = "Arcane"
+	passkeyDefaultName      = "Passkey"
+	passkeyCeremonyTimeout  = 5 * time.Minute
+	// maxPendingPasskeyCeremonies bounds the memory unauthenticated login attempts can take.
+	maxPendingPasskeyCeremonies = 1000
+	// maxPasskeyCeremoniesPerClient bounds the pending ceremonies of one client or user.
+	maxPasskeyCeremoniesPerClient = 5
+)
+
+type passkeyCeremony struct {
+	session   webauthn.SessionData
+	userID    string // empty for logins
+	client    string
+	expiresAt time.Time
+}
+
+// PasskeyService registers WebAuthn credentials of local users and verifies passkey logins.
+// Pending ceremonies are kept in memory; each challenge can be answered once.
+type PasskeyService struct {
+	db          *database.DB
+	userService *UserService
+	config      *config.Config
+
+	mu         sync.Mutex
+	ceremonies map[string]passkeyCeremony
+}
+
+func NewPasskeyService(db *database.DB, userService *UserService, cfg *config.Config) *PasskeyService {
+	return &PasskeyService{
+		db:          db,
+		userService: userService,
+		config:      cfg,
+		ceremonies:  make(map[string]passkeyCeremony),
+	}
+}
+
+// BeginRegistration returns the options for creating a new passkey for a user.
+func (s *PasskeyService) BeginRegistration(ctx context.Context, userID string) (*auth.PasskeyRegistrationOptions, error) {
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.PasswordHash == "" {
+		return nil, &models.ValidationError{Message: "Passkeys are only available for local accounts"}
+	}
+
+	wa, err := s.webAuthnInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []models.Passkey
+	if err := s.db.WithContext(ctx).Where("user_id = ?", user.ID).Find(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+	waUser := &passkeyUser{user: user, passkeys: existing}
+
+	creation, session, err := wa.BeginRegistration(waUser,
+		webauthn.WithExclusions(webauthn.Credentials(waUser.WebAuthnCredentials()).CredentialDescriptors()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start passkey registration: %w", err)
+	}
+	sessionID, err := s.startCeremonyInternal("user:"+user.ID, user.ID, session)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := creation.Response
+	params := make([]auth.PasskeyCredentialParameter, 0, len(opts.Parameters))
+	for _, p := range opts.Parameters {
+		params = append(params, auth.PasskeyCredentialParameter{Type: string(p.Type), Alg: int64(p.Algorithm)})
+	}
+
+	return &auth.PasskeyRegistrationOptions{
+		SessionID: sessionID,
+		PublicKey: auth.PasskeyCreationOptions{
+			RP:                 auth.PasskeyRelyingParty{ID: opts.RelyingParty.ID, Name: opts.RelyingParty.Name},
+			User:               auth.PasskeyUser{ID: base64.RawURLEncoding.EncodeToString(waUser.WebAuthnID()), Name: waUser.WebAuthnName(), DisplayName: waUser.WebAuthnDisplayName()},
+			Challenge:          opts.Challenge.String(),
+			PubKeyCredParams:   params,
+			Timeout:            opts.Timeout,
+			ExcludeCredentials: toPasskeyDescriptorsInternal(opts.CredentialExcludeList),
+			AuthenticatorSelection: auth.PasskeyAuthenticatorSelection{
+				ResidentKey:        string(opts.AuthenticatorSelection.ResidentKey),
+				RequireResidentKey: opts.AuthenticatorSelection.RequireResidentKey != nil && *opts.AuthenticatorSelection.RequireResidentKey,
+				UserVerification:   string(opts.AuthenticatorSelection.UserVerification),
+			},
+			Attestation: string(opts.Attestation),
+		},
+	}, nil
+}
+
+// FinishRegistration verifies the new credential of a ceremony started with BeginRegistration
+// and stores it.
+func (s *PasskeyService) FinishRegistration(ctx context.Context, userID string, req auth.FinishPasskeyRegistration) (*auth.Passkey, error) {
+	ceremony, ok := s.takeCeremonyInternal(req.SessionID)
+	if !ok || ceremony.userID != userID {
+		return nil, ErrPasskeySessionExpired
+	}
+
+	wa, err := s.webAuthnInternal()
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	credential := req.Credential
+	if credential.RawID == "" {
+		credential.RawID = credential.ID
+	}
+	body, err := json.Marshal(credential)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode passkey credential: %w", err)
+	}
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPasskeyInvalid, err)
+	}
+
+	cred, err := wa.CreateCredential(&passkeyUser{user: user}, ceremony.session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPasskeyInvalid, err)
+	}
+	credentialID := base64.RawURLEncoding.EncodeToString(cred.ID)
+	if strings.TrimRight(req.Credential.ID, "=") != credentialID {
+		return nil, fmt.Errorf("%w: credential ID mismatch", ErrPasskeyInvalid)
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Passkey{}).Where("credential_id = ?", credentialID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check passkey: %w", err)
+	}
+	if count > 0 {
+		return nil, &models.ConflictError{Message: "This passkey is already registered"}
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = passkeyDefaultName
+	}
+
+	pk := &models.Passkey{
+		UserID:         userID,
+		Name:           name,
+		CredentialID:   credentialID,
+		PublicKey:      cred.PublicKey,
+		SignCount:      int64(cred.Authenticator.SignCount),
+		Transports:     req.Credential.Response.Transports,
+		AAGUID:         hex.EncodeToString(cred.Authenticator.AAGUID),
+		BackupEligible: cred.Flags.BackupEligible,
+		BackedUp:       cred.Flags.BackupState,
+	}
+	if err := s.db.WithContext(ctx).Create(pk).Error; err != nil {
+		return nil, fmt.Errorf("failed to store passkey: %w", err)
+	}
+
+	out := toPasskeyDtoInternal(pk)
+	return &out, nil
+}
+
+// ListPasskeys returns the passkeys of a user, oldest first.
+func (s *PasskeyService) ListPasskeys(ctx context.Context, userID string) ([]auth.Passkey, error) {
+	var passkeys []models.Passkey
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at ASC").Find(&passkeys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+
+	out := make([]auth.Passkey, 0, len(passkeys))
+	for i := range passkeys {
+		out = append(out, toPasskeyDtoInternal(&passkeys[i]))
+	}
+	return out, nil
+}
+
+// RenamePasskey changes the name of a passkey of a user.
+func (s *PasskeyService) RenamePasskey(ctx context.Context, userID, passkeyID, name string) (*auth.Passkey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, &models.ValidationError{Message: "Name is required", Field: "name"}
+	}
+
+	res := s.db.WithContext(ctx).Model(&models.Passkey{}).
+		Where("id = ? AND user_id = ?", passkeyID, userID).
+		Update("name", name)
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to rename passkey: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrPasskeyNotFound
+	}
+
+	var pk models.Passkey
+	if err := s.db.WithContext(ctx).Where("id = ?", passkeyID).First(&pk).Error; err != nil {
+		return nil, fmt.Errorf("failed to get passkey: %w", err)
+	}
+	out := toPasskeyDtoInternal(&pk)
+	return &out, nil
+}
+
+// DeletePasskey removes a passkey of a user.
+func (s *PasskeyService) DeletePasskey(ctx context.Context, userID, passkeyID string) error {
+	res := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", passkeyID, userID).Delete(&models.Passkey{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete passkey: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrPasskeyNotFound
+	}
+	return nil
+}
+
+// BeginLogin returns the options for signing in with a discoverable passkey. clientKey identifies
+// the unauthenticated client, usually by IP, so one client can't use up the capacity of others.
+// Behind a reverse proxy that only holds when TRUSTED_PROXIES is set; otherwise every browser
+// shares the proxy's address.
+func (s *PasskeyService) BeginLogin(ctx context.Context, clientKey string) (*auth.PasskeyLoginOptions, error) {
+	wa, err := s.webAuthnInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	assertion, session, err := wa.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start passkey login: %w", err)
+	}
+	sessionID, err := s.startCeremonyInternal("client:"+clientKey, "", session)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := assertion.Response
+	return &auth.PasskeyLoginOptions{
+		SessionID: sessionID,
+		PublicKey: auth.PasskeyRequestOptions{
+			RPID:             opts.RelyingPartyID,
+			Challenge:        opts.Challenge.String(),
+			Timeout:          opts.Timeout,
+			AllowCredentials: toPasskeyDescriptorsInternal(opts.AllowedCredentials),
+			UserVerification: string(opts.UserVerification),
+		},
+	}, nil
+}
+
+// FinishLogin verifies the assertion of a ceremony started with BeginLogin and returns the
+// user the passkey belongs to. User verification is required, so a passkey counts as two
+// factors.
+func (s *PasskeyService) FinishLogin(ctx context.Context, req auth.FinishPasskeyLogin) (*models.User, error) {
+	ceremony, ok := s.takeCeremonyInternal(req.SessionID)
+	if !ok || ceremony.userID != "" {
+		return nil, ErrPasskeySessionExpired
+	}
+
+	wa, err := s.webAuthnInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	credential := req.Credential
+	if credential.RawID == "" {
+		credential.RawID = credential.ID
+	}
+	body, err := json.Marshal(credential)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode passkey credential: %w", err)
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPasskeyInvalid, err)
+	}
+
+	// lookupErr keeps database failures apart from unknown credentials, which the library
+	// reports the same way.
+	var pk models.Passkey
+	var lookupErr error
+	findUser := func(rawID, userHandle []byte) (webauthn.User, error) {
+		if err := s.db.WithContext(ctx).Where("credential_id = ?", base64.RawURLEncoding.EncodeToString(rawID)).First(&pk).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				lookupErr = fmt.Errorf("failed to get passkey: %w", err)
+			}
+			return nil, errors.New("unknown credential")
+		}
+		if string(userHandle) != pk.UserID {
+			return nil, errors.New("user handle mismatch")
+		}
+		user, err := s.userService.GetUserByID(ctx, pk.UserID)
+		if err != nil {
+			if !errors.Is(err, ErrUserNotFound) {
+				lookupErr = err
+			}
+			return nil, errors.New("unknown user")
+		}
+		return &passkeyUser{user: user, passkeys: []models.Passkey{pk}}, nil
+	}
+
+	waUser, cred, err := wa.ValidatePasskeyLogin(findUser, ceremony.session, parsed)
+	if lookupErr != nil {
+		return nil, lookupErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPasskeyInvalid, err)
+	}
+	if cred.Authenticator.CloneWarning {
+		return nil, fmt.Errorf("%w: signature counter did not increase", ErrPasskeyInvalid)
+	}
+
+	// The sign count guard makes concurrent logins with the same response fail.
+	res := s.db.WithContext(ctx).Model(&models.Passkey{}).
+		Where("id = ? AND sign_count = ?", pk.ID, pk.SignCount).
+		Updates(map[string]any{
+			"sign_count":   int64(cred.Authenticator.SignCount),
+			"backed_up":    cred.Flags.BackupState,
+			"last_used_at": time.Now(),
+		})
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to update passkey: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: passkey was used concurrently", ErrPasskeyInvalid)
+	}
+
+	return waUser.(*passkeyUser).user, nil
+}
+
+// webAuthnInternal builds the relying party from APP_URL: its host name is the RP ID and its
+// scheme and host the only accepted origin.
+func (s *PasskeyService) webAuthnInternal() (*webauthn.WebAuthn, error) {
+	appURL := s.config.GetAppURL()
+	u, err := url.Parse(strings.TrimSpace(appURL))
+	if err != nil || u.Scheme == "" || u.Hostname() == "" {
+		return nil, fmt.Errorf("passkeys need a valid APP_URL, got %q", appURL)
+	}
+
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: passkeyCeremonyTimeout, TimeoutUVD: passkeyCeremonyTimeout}
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          u.Hostname(),
+		RPDisplayName: passkeyRelyingPartyName,
+		RPOrigins:     []string{u.Scheme + "://" + u.Host},
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			UserVerification:   protocol.VerificationRequired,
+		},
+		AttestationPreference: protocol.PreferNoAttestation,
+		Timeouts:              webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("passkeys need a valid APP_URL: %w", err)
+	}
+	return wa, nil
+}
+
+// startCeremonyInternal stores a pending ceremony and returns its ID. Pending ceremonies are
+// never dropped to make room: when client already has maxPasskeyCeremoniesPerClient pending, or
+// the service is full, the new one is refused with ErrPasskeyTooManyPending until some finish or
+// expire.
+func (s *PasskeyService) startCeremonyInternal(client, userID string, session *webauthn.SessionData) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	pending := 0
+	for id, c := range s.ceremonies {
+		switch {
+		case now.After(c.expiresAt):
+			delete(s.ceremonies, id)
+		case c.client == client:
+			pending++
+		}
+	}
+	if pending >= maxPasskeyCeremoniesPerClient || len(s.ceremonies) >= maxPendingPasskeyCeremonies {
+		return "", ErrPasskeyTooManyPending
+	}
+
+	sessionID := stringutils.GenerateRandomString(32)
+	s.ceremonies[sessionID] = passkeyCeremony{
+		session:   *session,
+		userID:    userID,
+		client:    client,
+		expiresAt: now.Add(passkeyCeremonyTimeout),
+	}
+	return sessionID, nil
+}
+
+// takeCeremonyInternal removes and returns a pending ceremony, so each challenge is only
+// accepted once.
+func (s *PasskeyService) takeCeremonyInternal(sessionID string) (passkeyCeremony, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.ceremonies[sessionID]
+	if !ok {
+		return passkeyCeremony{}, false
+	}
+	delete(s.ceremonies, sessionID)
+	if time.Now().After(c.expiresAt) {
+		return passkeyCeremony{}, false
+	}
+	return c, true
+}
+
+// passkeyUser adapts a user and their stored passkeys to webauthn.User.
+type passkeyUser struct {
+	user     *models.User
+	passkeys []models.Passkey
+}
+
+func (u *passkeyUser) WebAuthnID() []byte {
+	return []byte(u.user.ID)
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.user.Username
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	if u.user.DisplayName != nil && *u.user.DisplayName != "" {
+		return *u.user.DisplayName
+	}
+	return u.user.Username
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	out := make([]webauthn.Credential, 0, len(u.passkeys))
+	for _, pk := range u.passkeys {
+		id, err := base64.RawURLEncoding.DecodeString(pk.CredentialID)
+		if err != nil {
+			continue
+		}
+		aaguid, _ := hex.DecodeString(pk.AAGUID)
+		transports := make([]protocol.AuthenticatorTransport, 0, len(pk.Transports))
+		for _, t := range pk.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(t))
+		}
+		out = append(out, webauthn.Credential{
+			ID:              id,
+			PublicKey:       pk.PublicKey,
+			AttestationType: string(protocol.PreferNoAttestation),
+			Transport:       transports,
+			Flags:           webauthn.CredentialFlags{BackupEligible: pk.BackupEligible, BackupState: pk.BackedUp},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    aaguid,
+				SignCount: uint32(pk.SignCount), // #nosec G115: sign counts are stored from uint32 values
+			},
+		})
+	}
+	return out
+}
+
+func toPasskeyDescriptorsInternal(descriptors []protocol.CredentialDescriptor) []auth.PasskeyCredentialDescriptor {
+	out := make([]auth.PasskeyCredentialDescriptor, 0, len(descriptors))
+	for _, d := range descriptors {
+		var transports []string
+		for _, t := range d.Transport {
+			transports = append(transports, string(t))
+		}
+		out = append(out, auth.PasskeyCredentialDescriptor{Type: string(d.Type), ID: d.CredentialID.String(), Transports: transports})
+	}
+	return out
+}
+
+func toPasskeyDtoInternal(pk *models.Passkey) auth.Passkey {
+	return auth.Passkey{
+		ID:         pk.ID,
+		Name:       pk.Name,
+		BackedUp:   pk.BackedUp,
+		Transports: pk.Transports,
+		CreatedAt:  pk.CreatedAt,
+		LastUsedAt: pk.LastUsedAt,
+	}
+}
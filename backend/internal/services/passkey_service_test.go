@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	glsqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/getarcaneapp/arcane/backend/internal/config"
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/webauthn/webauthntest"
+	"github.com/getarcaneapp/arcane/types/auth"
+)
+
+func setupPasskeyTestService(t *testing.T) (*PasskeyService, *models.User) {
+	t.Helper()
+	db, err := gorm.Open(glsqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Passkey{}))
+
+	user := &models.User{Username: "jane", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	wrapped := &database.DB{DB: db}
+	cfg := &config.Config{AppUrl: "https://arcane.example.com"}
+	return NewPasskeyService(wrapped, NewUserService(wrapped), cfg), user
+}
+
+func registerTestPasskey(t *testing.T, svc *PasskeyService, user *models.User, authenticator *webauthntest.Authenticator) *auth.Passkey {
+	t.Helper()
+	ctx := context.Background()
+
+	options, err := svc.BeginRegistration(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "arcane.example.com", options.PublicKey.RP.ID)
+
+	userHandle, err := base64.RawURLEncoding.DecodeString(options.PublicKey.User.ID)
+	require.NoError(t, err)
+	challenge, err := base64.RawURLEncoding.DecodeString(options.PublicKey.Challenge)
+	require.NoError(t, err)
+	reg, err := authenticator.Create(options.PublicKey.RP.ID, userHandle, challenge)
+	require.NoError(t, err)
+
+	passkey, err := svc.FinishRegistration(ctx, user.ID, auth.FinishPasskeyRegistration{
+		SessionID: options.SessionID,
+		Name:      "Laptop",
+		Credential: auth.PasskeyRegistrationCredential{
+			ID:   base64.RawURLEncoding.EncodeToString(reg.CredentialID),
+			Type: "public-key",
+			Response: auth.PasskeyAttestationResponse{
+				ClientDataJSON:    base64.RawURLEncoding.EncodeToString(reg.ClientDataJSON),
+				AttestationObject: base64.RawURLEncoding.EncodeToString(reg.AttestationObject),
+				Transports:        []string{"internal"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	return passkey
+}
+
+func passkeyLoginRequest(t *testing.T, options *auth.PasskeyLoginOptions, authenticator *webauthntest.Authenticator) auth.FinishPasskeyLogin {
+	t.Helper()
+	challenge, err := base64.RawURLEncoding.DecodeString(options.PublicKey.Challenge)
+	require.NoError(t, err)
+	assertion, err := authenticator.Get(options.PublicKey.RPID, nil, challenge)
+	require.NoError(t, err)
+
+	return auth.FinishPasskeyLogin{
+		SessionID: options.SessionID,
+		Credential: auth.PasskeyAssertionCredential{
+			ID:   base64.RawURLEncoding.EncodeToString(assertion.CredentialID),
+			Type: "public-key",
+			Response: auth.PasskeyAssertionResponse{
+				ClientDataJSON:    base64.RawURLEncoding.EncodeToString(assertion.ClientDataJSON),
+				AuthenticatorData: base64.RawURLEncoding.EncodeToString(assertion.AuthenticatorData),
+				Signature:         base64.RawURLEncoding.EncodeToString(assertion.Signature),
+				UserHandle:        base64.RawURLEncoding.EncodeToString(assertion.UserHandle),
+			},
+		},
+	}
+}
+
+func TestPasskeyService_RegisterAndLogin(t *testing.T) {
+	ctx := context.Background()
+	svc, user := setupPasskeyTestService(t)
+	authenticator := webauthntest.New("https://arcane.example.com")
+
+	passkey := registerTestPasskey(t, svc, user, authenticator)
+	assert.Equal(t, "Laptop", passkey.Name)
+	assert.True(t, passkey.BackedUp)
+
+	options, err := svc.BeginLogin(ctx, "192.0.2.1")
+	require.NoError(t, err)
+	req := passkeyLoginRequest(t, options, authenticator)
+
+	loggedIn, err := svc.FinishLogin(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, loggedIn.ID)
+
+	// The ceremony is consumed, so the same response can't be used again.
+	_, err = svc.FinishLogin(ctx, req)
+	require.ErrorIs(t, err, ErrPasskeySessionExpired)
+
+	passkeys, err := svc.ListPasskeys(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, passkeys, 1)
+	assert.NotNil(t, passkeys[0].LastUsedAt)
+
+	// A new registration excludes the existing passkey.
+	regOptions, err := svc.BeginRegistration(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, regOptions.PublicKey.ExcludeCredentials, 1)
+	assert.Equal(t, []string{"internal"}, regOptions.PublicKey.ExcludeCredentials[0].Transports)
+}
+
+func TestPasskeyService_RejectsInvalidResponses(t *testing.T) {
+	ctx := context.Background()
+	svc, user := setupPasskeyTestService(t)
+	authenticator := webauthntest.New("https://arcane.example.com")
+	registerTestPasskey(t, svc, user, authenticator)
+
+	options, err := svc.BeginLogin(ctx, "192.0.2.1")
+	require.NoError(t, err)
+	req := passkeyLoginRequest(t, options, authenticator)
+	req.Credential.Response.UserHandle = base64.RawURLEncoding.EncodeToString([]byte("someone-else"))
+	_, err = svc.FinishLogin(ctx, req)
+	require.ErrorIs(t, err, ErrPasskeyInvalid)
+
+	options, err = svc.BeginLogin(ctx, "192.0.2.1")
+	require.NoError(t, err)
+	req = passkeyLoginRequest(t, options, authenticator)
+	req.Credential.ID = base64.RawURLEncoding.EncodeToString([]byte("unknown"))
+	_, err = svc.FinishLogin(ctx, req)
+	require.ErrorIs(t, err, ErrPasskeyInvalid)
+
+	// Registration sessions belong to the user that started them.
+	regOptions, err := svc.BeginRegistration(ctx, user.ID)
+	require.NoError(t, err)
+	_, err = svc.FinishRegistration(ctx, "other-user", auth.FinishPasskeyRegistration{SessionID: regOptions.SessionID})
+	require.ErrorIs(t, err, ErrPasskeySessionExpired)
+
+	oidcUser := &models.User{Username: "oidc"}
+	require.NoError(t, svc.db.Create(oidcUser).Error)
+	var validationErr *models.ValidationError
+	_, err = svc.BeginRegistration(ctx, oidcUser.ID)
+	require.ErrorAs(t, err, &validationErr)
+}
+
+func TestPasskeyService_RenameAndDelete(t *testing.T) {
+	ctx := context.Background()
+	svc, user := setupPasskeyTestService(t)
+	passkey := registerTestPasskey(t, svc, user, webauthntest.New("https://arcane.example.com"))
+
+	renamed, err := svc.RenamePasskey(ctx, user.ID, passkey.ID, " Phone ")
+	require.NoError(t, err)
+	assert.Equal(t, "Phone", renamed.Name)
+
+	_, err = svc.RenamePasskey(ctx, "other-user", passkey.ID, "Mine")
+	require.ErrorIs(t, err, ErrPasskeyNotFound)
+	require.ErrorIs(t, svc.DeletePasskey(ctx, "other-user", passkey.ID), ErrPasskeyNotFound)
+
+	require.NoError(t, svc.DeletePasskey(ctx, user.ID, passkey.ID))
+	passkeys, err := svc.ListPasskeys(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, passkeys)
+}
+
+func TestPasskeyService_LoginCeremoniesAreBoundedPerClient(t *testing.T) {
+	ctx := context.Background()
+	svc, user := setupPasskeyTestService(t)
+	authenticator := webauthntest.New("https://arcane.example.com")
+	registerTestPasskey(t, svc, user, authenticator)
+
+	pending, err := svc.BeginLogin(ctx, "192.0.2.1")
+	require.NoError(t, err)
+
+	// A flooding client is refused once at its cap and can't displace any pending ceremony,
+	// its own included.
+	first, err := svc.BeginLogin(ctx, "203.0.113.7")
+	require.NoError(t, err)
+	for range maxPasskeyCeremoniesPerClient - 1 {
+		_, err := svc.BeginLogin(ctx, "203.0.113.7")
+		require.NoError(t, err)
+	}
+	_, err = svc.BeginLogin(ctx, "203.0.113.7")
+	require.ErrorIs(t, err, ErrPasskeyTooManyPending)
+	assert.Len(t, svc.ceremonies, maxPasskeyCeremoniesPerClient+1)
+
+	loggedIn, err := svc.FinishLogin(ctx, passkeyLoginRequest(t, first, authenticator))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, loggedIn.ID)
+	loggedIn, err = svc.FinishLogin(ctx, passkeyLoginRequest(t, pending, authenticator))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, loggedIn.ID)
+
+	// A finished ceremony frees its slot.
+	_, err = svc.BeginLogin(ctx, "203.0.113.7")
+	require.NoError(t, err)
+
+	// When the service is full new ceremonies are refused instead of dropping pending ones.
+	oldest, err := svc.BeginLogin(ctx, "192.0.2.1")
+	require.NoError(t, err)
+	for i := 0; len(svc.ceremonies) < maxPendingPasskeyCeremonies; i++ {
+		_, err := svc.BeginLogin(ctx, fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+		require.NoError(t, err)
+	}
+	_, err = svc.BeginLogin(ctx, "198.51.100.1")
+	require.ErrorIs(t, err, ErrPasskeyTooManyPending)
+	_, err = svc.FinishLogin(ctx, passkeyLoginRequest(t, oldest, authenticator))
+	require.NoError(t, err)
+}
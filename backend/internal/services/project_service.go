@@ -16,6 +16,7 @@ import (
 	composetypes "github.com/compose-spec/compose-go/v2/types"
 	"github.com/docker/compose/v5/pkg/api"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
 	"github.com/getarcaneapp/arcane/backend/internal/common"
 	"github.com/getarcaneapp/arcane/backend/internal/database"
 	"github.com/getarcaneapp/arcane/backend/internal/models"
@@ -28,25 +29,38 @@ import (
 	"github.com/getarcaneapp/arcane/backend/internal/utils/timeouts"
 	"github.com/getarcaneapp/arcane/backend/pkg/projects"
 	"github.com/getarcaneapp/arcane/types/containerregistry"
+	"github.com/getarcaneapp/arcane/types/imagebuild"
 	"github.com/getarcaneapp/arcane/types/project"
+	ref "go.podman.io/image/v5/docker/reference"
 	"gorm.io/gorm"
 )
 
+// maxProjectDeploymentHistory is the number of deployment snapshots kept per project.
+const maxProjectDeploymentHistory = 25
+
+var (
+	ErrProjectNotFound            = errors.New("project not found")
+	ErrProjectDeploymentNotFound  = errors.New("deployment not found")
+	ErrProjectRollbackUnavailable = errors.New("project cannot be rolled back")
+)
+
 type ProjectService struct {
 	db              *database.DB
 	settingsService *SettingsService
 	eventService    *EventService
 	imageService    *ImageService
 	dockerService   *DockerClientService
+	buildService    *ImageBuildService
 }
 
-func NewProjectService(db *database.DB, settingsService *SettingsService, eventService *EventService, imageService *ImageService, dockerService *DockerClientService) *ProjectService {
+func NewProjectService(db *database.DB, settingsService *SettingsService, eventService *EventService, imageService *ImageService, dockerService *DockerClientService, buildService *ImageBuildService) *ProjectService {
 	return &ProjectService{
 		db:              db,
 		settingsService: settingsService,
 		eventService:    eventService,
 		imageService:    imageService,
 		dockerService:   dockerService,
+		buildService:    buildService,
 	}
 }
 
@@ -132,7 +146,7 @@ func (s *ProjectService) GetProjectFromDatabaseByID(ctx context.Context, id stri
 			return nil, fmt.Errorf("request canceled or timed out")
 		}
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("project not found")
+			return nil, ErrProjectNotFound
 		}
 		return nil, fmt.Errorf("failed to get project: %w", err)
 	}
@@ -699,6 +713,10 @@ func (s *ProjectService) GetProjectStatusCounts(ctx context.Context) (folderCoun
 // Project Actions
 
 func (s *ProjectService) DeployProject(ctx context.Context, projectID string, user models.User) error {
+	return s.deployProjectInternal(ctx, projectID, user, models.ProjectDeploymentActionDeploy, nil)
+}
+
+func (s *ProjectService) deployProjectInternal(ctx context.Context, projectID string, user models.User, action string, sourceDeploymentID *string) error {
 	projectFromDb, err := s.GetProjectFromDatabaseByID(ctx, projectID)
 	if err != nil {
 		return fmt.Errorf("failed to get project: %w", err)
@@ -732,6 +750,12 @@ func (s *ProjectService) DeployProject(ctx context.Context, projectID string, us
 		return fmt.Errorf("failed to update project status to deploying: %w", err)
 	}
 
+	if berr := s.buildProjectImagesInternal(ctx, projectID, project, user); berr != nil {
+		_ = s.updateProjectStatusandCountsInternal(ctx, projectID, models.ProjectStatusStopped)
+		s.recordProjectDeploymentInternal(ctx, projectFromDb, project, user, action, sourceDeploymentID, berr)
+		return fmt.Errorf("failed to build project images: %w", berr)
+	}
+
 	if perr := s.EnsureProjectImagesPresent(ctx, projectID, io.Discard, nil); perr != nil {
 		slog.Warn("ensure images present failed (continuing to compose up)", "projectID", projectID, "error", perr)
 	}
@@ -746,6 +770,7 @@ func (s *ProjectService) DeployProject(ctx context.Context, projectID string, us
 			slog.Info("containers after failed deploy", "projectID", projectID, "containers", containers)
 		}
 		_ = s.updateProjectStatusandCountsInternal(ctx, projectID, models.ProjectStatusStopped)
+		s.recordProjectDeploymentInternal(ctx, projectFromDb, project, user, action, sourceDeploymentID, err)
 
 		// Provide more helpful error messages
 		errMsg := err.Error()
@@ -755,6 +780,7 @@ func (s *ProjectService) DeployProject(ctx context.Context, projectID string, us
 		return fmt.Errorf("failed to deploy project: %w", err)
 	}
 	slog.Info("compose up completed successfully", "projectID", projectID, "projectName", project.Name)
+	s.recordProjectDeploymentInternal(ctx, projectFromDb, project, user, action, sourceDeploymentID, nil)
 
 	metadata := models.JSON{"action": "deploy", "projectID": projectID, "projectName": project.Name}
 	if logErr := s.eventService.LogProjectEvent(ctx, models.EventTypeProjectDeploy, projectID, project.Name, user.ID, user.Username, "0", metadata); logErr != nil {
@@ -768,6 +794,45 @@ func (s *ProjectService) DeployProject(ctx context.Context, projectID string, us
 	return err
 }
 
+// buildProjectImagesInternal builds the images of services with a build section that are
+// missing locally, or that always build because their pull_policy is build.
+func (s *ProjectService) buildProjectImagesInternal(ctx context.Context, projectID string, project *composetypes.Project, user models.User) error {
+	if s.buildService == nil {
+		return nil
+	}
+
+	var services []string
+	for name, svc := range project.Services {
+		if svc.Build == nil {
+			continue
+		}
+		if svc.PullPolicy != composetypes.PullPolicyBuild {
+			img := strings.TrimSpace(svc.Image)
+			if img == "" {
+				img = project.Name + "-" + name
+			}
+			exists, ierr := s.imageService.ImageExistsLocally(ctx, img)
+			if ierr != nil {
+				slog.WarnContext(ctx, "failed to check local image existence", "image", img, "error", ierr)
+			}
+			if exists {
+				continue
+			}
+		}
+		services = append(services, name)
+	}
+	if len(services) == 0 {
+		return nil
+	}
+
+	_, err := s.buildService.BuildComposeProject(ctx, project, ComposeBuildOptions{
+		ProjectID: projectID,
+		Trigger:   imagebuild.TriggerDeploy,
+		Services:  services,
+	}, user)
+	return err
+}
+
 func (s *ProjectService) DownProject(ctx context.Context, projectID string, user models.User) error {
 	projectFromDb, err := s.GetProjectFromDatabaseByID(ctx, projectID)
 	if err != nil {
@@ -912,6 +977,10 @@ func (s *ProjectService) DestroyProject(ctx context.Context, projectID string, r
 		slog.DebugContext(ctx, "Skipping file removal (removeFiles=false)", "path", proj.Path)
 	}
 
+	if err := s.db.WithContext(ctx).Where("project_id = ?", projectID).Delete(&models.ProjectDeployment{}).Error; err != nil {
+		slog.WarnContext(ctx, "failed to delete project deployment history", "projectID", projectID, "error", err)
+	}
+
 	if err := s.db.WithContext(ctx).Delete(proj).Error; err != nil {
 		return fmt.Errorf("failed to delete project from database: %w", err)
 	}
@@ -970,7 +1039,8 @@ func (s *ProjectService) PullProjectImages(ctx context.Context, projectID string
 	images := map[string]struct{}{}
 	for _, svc := range compProj.Services {
 		img := strings.TrimSpace(svc.Image)
-		if img == "" {
+		// Images of services with a build section are built on deploy, not pulled.
+		if img == "" || svc.Build != nil {
 			continue
 		}
 		images[img] = struct{}{}
@@ -1027,7 +1097,8 @@ func (s *ProjectService) EnsureProjectImagesPresent(ctx context.Context, project
 	images := map[string]struct{}{}
 	for _, svc := range compProj.Services {
 		img := strings.TrimSpace(svc.Image)
-		if img == "" {
+		// Images of services with a build section are built on deploy, not pulled.
+		if img == "" || svc.Build != nil {
 			continue
 		}
 		images[img] = struct{}{}
@@ -1173,6 +1244,620 @@ func (s *ProjectService) UpdateProjectIncludeFile(ctx context.Context, projectID
 	return nil
 }
 
+// SyncProjectFiles writes files (keyed by path relative to the project directory) into a
+// project and removes the paths in previous that are no longer part of files. Files whose
+// mode in modes is executable are made executable. It reports whether anything on disk
+// changed.
+func (s *ProjectService) SyncProjectFiles(ctx context.Context, projectID string, files map[string]string, modes map[string]os.FileMode, previous []string) (bool, error) {
+	proj, err := s.GetProjectFromDatabaseByID(ctx, projectID)
+	if err != nil {
+		return false, err
+	}
+	if err := s.ensureProjectPathUnderRoot(ctx, proj, true); err != nil {
+		return false, err
+	}
+
+	changed := false
+	for relativePath, content := range files {
+		if existing, err := os.ReadFile(filepath.Join(proj.Path, relativePath)); err != nil || string(existing) != content {
+			if err := projects.WriteIncludeFile(proj.Path, relativePath, content); err != nil {
+				return changed, fmt.Errorf("failed to write project file %s: %w", relativePath, err)
+			}
+			changed = true
+		}
+
+		modeChanged, err := syncExecutableBitInternal(proj.Path, relativePath, modes[relativePath]&0o111 != 0)
+		if err != nil {
+			return changed, fmt.Errorf("failed to set mode of project file %s: %w", relativePath, err)
+		}
+		changed = changed || modeChanged
+	}
+
+	for _, relativePath := range previous {
+		if _, ok := files[relativePath]; ok {
+			continue
+		}
+		validatedPath, err := projects.ValidateIncludePathForWrite(proj.Path, relativePath)
+		if err != nil {
+			slog.WarnContext(ctx, "Skipping removal of project file", "projectID", proj.ID, "file", relativePath, "error", err)
+			continue
+		}
+		if err := os.Remove(validatedPath); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return changed, fmt.Errorf("failed to remove project file %s: %w", relativePath, err)
+			}
+			continue
+		}
+		changed = true
+	}
+
+	if changed {
+		slog.InfoContext(ctx, "project files synced", "projectID", proj.ID, "files", len(files))
+	}
+	return changed, nil
+}
+
+// syncExecutableBitInternal adds or removes the executable bits of a project file, giving
+// execute permission to whoever may read it. Other permission bits are left as written.
+func syncExecutableBitInternal(projectDir, relativePath string, executable bool) (bool, error) {
+	path, err := projects.ValidateIncludePathForWrite(projectDir, relativePath)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	perm := info.Mode().Perm()
+	if (perm&0o111 != 0) == executable {
+		return false, nil
+	}
+
+	mode := perm &^ 0o111
+	if executable {
+		mode |= (perm & 0o444) >> 2
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PlanProjectChanges loads desiredComposeFile as it would be deployed under projectName and
+// compares its services with the project's current compose file. projectID may be empty when
+// the project doesn't exist yet, in which case every service is planned for creation.
+func (s *ProjectService) PlanProjectChanges(ctx context.Context, projectID, projectName, desiredComposeFile string) ([]projects.ServiceChange, error) {
+	projectsDirSetting := s.settingsService.GetStringSetting(ctx, "projectsDirectory", "/app/data/projects")
+	projectsDirectory, _ := fs.GetProjectsDirectory(ctx, strings.TrimSpace(projectsDirSetting))
+	autoInjectEnv := s.settingsService.GetBoolSetting(ctx, "autoInjectEnv", false)
+
+	// Both sides are loaded without host path translation so their paths stay comparable.
+	load := func(composeFile string) (*composetypes.Project, string, error) {
+		absPath, err := filepath.Abs(composeFile)
+		if err != nil {
+			return nil, "", err
+		}
+		proj, err := projects.LoadComposeProject(ctx, absPath, normalizeComposeProjectName(projectName), projectsDirectory, autoInjectEnv, nil)
+		return proj, filepath.Dir(absPath), err
+	}
+
+	desired, desiredDir, err := load(desiredComposeFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load desired compose project: %w", err)
+	}
+
+	var current *composetypes.Project
+	var currentDir string
+	if projectID != "" {
+		proj, err := s.GetProjectFromDatabaseByID(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		if composeFile, derr := projects.DetectComposeFile(proj.Path); derr == nil && composeFile != "" {
+			current, currentDir, err = load(composeFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load current compose project: %w", err)
+			}
+		}
+	}
+
+	return projects.PlanServiceChanges(current, currentDir, desired, desiredDir)
+}
+
+// DetectContainerDrift compares the project's running containers with its compose file and
+// returns the containers whose image, environment or labels no longer match, e.g. because
+// they were changed outside of Arcane.
+func (s *ProjectService) DetectContainerDrift(ctx context.Context, projectID string) ([]projects.ServiceDrift, error) {
+	proj, err := s.GetProjectFromDatabaseByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	composeFile, err := projects.DetectComposeFile(proj.Path)
+	if err != nil {
+		return nil, fmt.Errorf("no compose file found in project directory: %s", proj.Path)
+	}
+
+	projectsDirSetting := s.settingsService.GetStringSetting(ctx, "projectsDirectory", "/app/data/projects")
+	projectsDirectory, _ := fs.GetProjectsDirectory(ctx, strings.TrimSpace(projectsDirSetting))
+	pathMapper, pmErr := s.getPathMapper(ctx)
+	if pmErr != nil {
+		slog.WarnContext(ctx, "failed to create path mapper, continuing without translation", "error", pmErr)
+	}
+	autoInjectEnv := s.settingsService.GetBoolSetting(ctx, "autoInjectEnv", false)
+	composeProj, err := projects.LoadComposeProject(ctx, composeFile, normalizeComposeProjectName(proj.Name), projectsDirectory, autoInjectEnv, pathMapper)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load compose project from %s: %w", proj.Path, err)
+	}
+
+	containers, err := projects.ComposePs(ctx, composeProj, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project containers: %w", err)
+	}
+	if len(containers) == 0 {
+		return nil, nil
+	}
+
+	dockerClient, err := s.dockerService.GetClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+
+	imageIDs := map[string]string{}
+	var drifts []projects.ServiceDrift
+	for _, c := range containers {
+		svc, ok := composeProj.Services[c.Service]
+		if !ok {
+			drifts = append(drifts, projects.ServiceDrift{
+				Service:   c.Service,
+				Container: c.Name,
+				Reasons:   []string{"service is not defined in the compose file"},
+			})
+			continue
+		}
+
+		inspect, err := dockerClient.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			slog.DebugContext(ctx, "unable to inspect container for drift detection", "container", c.Name, "error", err)
+			continue
+		}
+		state := projects.ContainerState{Name: c.Name, ImageID: inspect.Image}
+		if inspect.Config != nil {
+			state.Image = inspect.Config.Image
+			state.Env = inspect.Config.Env
+			state.Labels = inspect.Config.Labels
+		}
+
+		imageID, seen := imageIDs[svc.Image]
+		if !seen && svc.Image != "" {
+			if img, err := dockerClient.ImageInspect(ctx, svc.Image); err == nil {
+				imageID = img.ID
+			}
+			imageIDs[svc.Image] = imageID
+		}
+
+		if reasons := projects.DetectServiceDrift(svc, imageID, state); len(reasons) > 0 {
+			drifts = append(drifts, projects.ServiceDrift{Service: c.Service, Container: c.Name, Reasons: reasons})
+		}
+	}
+
+	return drifts, nil
+}
+
+// ListProjectDeployments returns the recorded deployment history for a project, newest first.
+func (s *ProjectService) ListProjectDeployments(ctx context.Context, projectID string) ([]models.ProjectDeployment, error) {
+	if _, err := s.GetProjectFromDatabaseByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	var deployments []models.ProjectDeployment
+	if err := s.db.WithContext(ctx).Where("project_id = ?", projectID).Order("revision DESC").Find(&deployments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list project deployments: %w", err)
+	}
+	return deployments, nil
+}
+
+// RollbackProject restores the compose file, .env and include files recorded for a previous
+// deployment, re-tags the images to the digests that were running at the time, and redeploys.
+// When deploymentID is empty the last successful deployment before the current one is used.
+func (s *ProjectService) RollbackProject(ctx context.Context, projectID, deploymentID string, user models.User) (*models.ProjectDeployment, error) {
+	proj, err := s.GetProjectFromDatabaseByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.findRollbackTargetInternal(ctx, projectID, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	projectsDirectory, err := fs.GetProjectsDirectory(ctx, s.settingsService.GetStringSetting(ctx, "projectsDirectory", "/app/data/projects"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects directory: %w", err)
+	}
+	if err := s.ensureProjectPathUnderRoot(ctx, proj, true); err != nil {
+		return nil, err
+	}
+
+	if proj.GitOpsManagedBy != nil && *proj.GitOpsManagedBy != "" {
+		slog.WarnContext(ctx, "rolling back a GitOps-managed project; the next sync may overwrite it", "projectID", projectID, "syncID", *proj.GitOpsManagedBy)
+	}
+
+	// Keep the files on disk so a failed deploy doesn't leave the project between its current
+	// revision and the target.
+	previous := s.snapshotProjectFilesInternal(ctx, proj)
+	added := addedIncludeFilesInternal(proj, target)
+
+	if err := restoreDeploymentFilesInternal(projectsDirectory, proj, target); err != nil {
+		return nil, err
+	}
+
+	previousTags := s.pinProjectImagesInternal(ctx, target.ImageDigests, user)
+
+	if err := s.deployProjectInternal(ctx, projectID, user, models.ProjectDeploymentActionRollback, &target.ID); err != nil {
+		if previous != nil {
+			if restoreErr := restorePreRollbackFilesInternal(projectsDirectory, proj, previous, added); restoreErr != nil {
+				slog.ErrorContext(ctx, "failed to restore project files after failed rollback", "projectID", projectID, "error", restoreErr)
+			}
+		}
+		s.restoreImageTagsInternal(ctx, previousTags)
+		return nil, fmt.Errorf("failed to deploy revision %d: %w", target.Revision, err)
+	}
+
+	metadata := models.JSON{
+		"action":       "rollback",
+		"projectID":    projectID,
+		"projectName":  proj.Name,
+		"deploymentID": target.ID,
+		"revision":     target.Revision,
+	}
+	if logErr := s.eventService.LogProjectEvent(ctx, models.EventTypeProjectRollback, projectID, proj.Name, user.ID, user.Username, "0", metadata); logErr != nil {
+		slog.ErrorContext(ctx, "could not log project rollback action", "error", logErr)
+	}
+
+	slog.InfoContext(ctx, "project rolled back", "projectID", projectID, "deploymentID", target.ID, "revision", target.Revision)
+	return target, nil
+}
+
+// restoreDeploymentFilesInternal writes the compose file, .env and include files of a snapshot.
+func restoreDeploymentFilesInternal(projectsDirectory string, proj *models.Project, target *models.ProjectDeployment) error {
+	// A snapshot without a .env must not keep the current one; it is blanked rather than removed
+	// because compose files may still reference it with env_file.
+	envContent := target.EnvContent
+	if envContent == nil {
+		envContent = new(string)
+	}
+	if err := fs.SaveOrUpdateProjectFiles(projectsDirectory, proj.Path, target.ComposeContent, envContent); err != nil {
+		return fmt.Errorf("failed to restore project files: %w", err)
+	}
+
+	for relativePath, raw := range target.IncludeFiles {
+		content, ok := raw.(string)
+		if !ok {
+			continue
+		}
+		if err := projects.WriteIncludeFile(proj.Path, relativePath, content); err != nil {
+			return fmt.Errorf("failed to restore include file %s: %w", relativePath, err)
+		}
+	}
+	return nil
+}
+
+// snapshotProjectFilesInternal returns the compose file, .env and include files currently on disk,
+// or nil when there is no compose file to keep.
+func (s *ProjectService) snapshotProjectFilesInternal(ctx context.Context, proj *models.Project) *models.ProjectDeployment {
+	composeContent, envContent, err := fs.ReadProjectFiles(proj.Path)
+	if err != nil || composeContent == "" {
+		return nil
+	}
+
+	snapshot := &models.ProjectDeployment{
+		ComposeContent: composeContent,
+		IncludeFiles:   s.snapshotIncludeFilesInternal(ctx, proj.Path),
+	}
+	if _, statErr := os.Stat(filepath.Join(proj.Path, ".env")); statErr == nil {
+		snapshot.EnvContent = &envContent
+	}
+	return snapshot
+}
+
+// restorePreRollbackFilesInternal puts back the files a project had before a rollback whose deploy
+// failed. Unlike a rollback target, a project that had no .env gets none back, and the include
+// files the rollback added are removed.
+func restorePreRollbackFilesInternal(projectsDirectory string, proj *models.Project, previous *models.ProjectDeployment, added []string) error {
+	if err := restoreDeploymentFilesInternal(projectsDirectory, proj, previous); err != nil {
+		return err
+	}
+	if previous.EnvContent == nil {
+		if err := os.Remove(filepath.Join(proj.Path, ".env")); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove restored .env: %w", err)
+		}
+	}
+	for _, relativePath := range added {
+		path, err := projects.ValidateIncludePathForWrite(proj.Path, relativePath)
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove restored include file %s: %w", relativePath, err)
+		}
+	}
+	return nil
+}
+
+// addedIncludeFilesInternal returns the include files of a rollback target that don't exist in
+// the project yet.
+func addedIncludeFilesInternal(proj *models.Project, target *models.ProjectDeployment) []string {
+	var added []string
+	for relativePath := range target.IncludeFiles {
+		path, err := projects.ValidateIncludePathForWrite(proj.Path, relativePath)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			added = append(added, relativePath)
+		}
+	}
+	return added
+}
+
+func (s *ProjectService) findRollbackTargetInternal(ctx context.Context, projectID, deploymentID string) (*models.ProjectDeployment, error) {
+	var target models.ProjectDeployment
+
+	if deploymentID != "" {
+		if err := s.db.WithContext(ctx).Where("id = ? AND project_id = ?", deploymentID, projectID).First(&target).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrProjectDeploymentNotFound
+			}
+			return nil, fmt.Errorf("failed to get deployment: %w", err)
+		}
+		if target.Status != models.ProjectDeploymentStatusSucceeded {
+			return nil, fmt.Errorf("%w: deployment revision %d did not succeed", ErrProjectRollbackUnavailable, target.Revision)
+		}
+		return &target, nil
+	}
+
+	var latest models.ProjectDeployment
+	res := s.db.WithContext(ctx).Where("project_id = ?", projectID).Order("revision DESC").Limit(1).Find(&latest)
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to get latest deployment: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: project has no recorded deployments", ErrProjectRollbackUnavailable)
+	}
+
+	res = s.db.WithContext(ctx).
+		Where("project_id = ? AND status = ? AND revision < ?", projectID, models.ProjectDeploymentStatusSucceeded, latest.Revision).
+		Order("revision DESC").
+		Limit(1).
+		Find(&target)
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to get previous deployment: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: no previous successful deployment to roll back to", ErrProjectRollbackUnavailable)
+	}
+	return &target, nil
+}
+
+// recordProjectDeploymentInternal snapshots the files currently on disk and the image digests the
+// compose services resolved to. Failures are logged and never fail the deploy itself.
+func (s *ProjectService) recordProjectDeploymentInternal(ctx context.Context, proj *models.Project, composeProj *composetypes.Project, user models.User, action string, sourceDeploymentID *string, deployErr error) {
+	composeContent, envContent, err := fs.ReadProjectFiles(proj.Path)
+	if err != nil || composeContent == "" {
+		slog.WarnContext(ctx, "skipping deployment snapshot; compose file could not be read", "projectID", proj.ID, "error", err)
+		return
+	}
+
+	deployment := &models.ProjectDeployment{
+		ProjectID:          proj.ID,
+		Action:             action,
+		Status:             models.ProjectDeploymentStatusSucceeded,
+		ComposeContent:     composeContent,
+		IncludeFiles:       s.snapshotIncludeFilesInternal(ctx, proj.Path),
+		ImageDigests:       s.resolveImageDigestsInternal(ctx, composeProj),
+		SourceDeploymentID: sourceDeploymentID,
+	}
+	if _, statErr := os.Stat(filepath.Join(proj.Path, ".env")); statErr == nil {
+		deployment.EnvContent = &envContent
+	}
+	if user.ID != "" {
+		deployment.UserID = &user.ID
+		deployment.Username = &user.Username
+	}
+	if deployErr != nil {
+		msg := deployErr.Error()
+		deployment.Status = models.ProjectDeploymentStatusFailed
+		deployment.Error = &msg
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest models.ProjectDeployment
+		if err := tx.Where("project_id = ?", proj.ID).Order("revision DESC").Limit(1).Find(&latest).Error; err != nil {
+			return err
+		}
+		deployment.Revision = latest.Revision + 1
+
+		if err := tx.Create(deployment).Error; err != nil {
+			return err
+		}
+
+		// Trim history so it doesn't grow without bound.
+		var oldest models.ProjectDeployment
+		res := tx.Where("project_id = ?", proj.ID).Order("revision DESC").Offset(maxProjectDeploymentHistory).Limit(1).Find(&oldest)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			return tx.Where("project_id = ? AND revision <= ?", proj.ID, oldest.Revision).Delete(&models.ProjectDeployment{}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record project deployment", "projectID", proj.ID, "error", err)
+	}
+}
+
+func (s *ProjectService) snapshotIncludeFilesInternal(ctx context.Context, projectPath string) models.JSON {
+	composeFile, err := projects.DetectComposeFile(projectPath)
+	if err != nil {
+		return nil
+	}
+	includes, err := projects.ParseIncludes(composeFile)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to parse includes", "error", err, "path", projectPath)
+		return nil
+	}
+
+	files := models.JSON{}
+	for _, inc := range includes {
+		// Only files we're allowed to write back are worth keeping for a rollback.
+		if _, err := projects.ValidateIncludePathForWrite(projectPath, inc.RelativePath); err != nil {
+			continue
+		}
+		if _, err := os.Stat(inc.Path); err != nil {
+			continue
+		}
+		files[inc.RelativePath] = inc.Content
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	return files
+}
+
+func (s *ProjectService) resolveImageDigestsInternal(ctx context.Context, composeProj *composetypes.Project) models.JSON {
+	if s.dockerService == nil || composeProj == nil {
+		return nil
+	}
+	dockerClient, err := s.dockerService.GetClient()
+	if err != nil {
+		slog.WarnContext(ctx, "failed to connect to Docker for image digests", "error", err)
+		return nil
+	}
+
+	digests := models.JSON{}
+	for _, svc := range composeProj.Services {
+		img := strings.TrimSpace(svc.Image)
+		if img == "" {
+			continue
+		}
+		if _, seen := digests[img]; seen {
+			continue
+		}
+
+		inspect, err := dockerClient.ImageInspect(ctx, img)
+		if err != nil {
+			slog.DebugContext(ctx, "unable to inspect image for deployment snapshot", "image", img, "error", err)
+			continue
+		}
+		if repoDigest := matchRepoDigest(img, inspect.RepoDigests); repoDigest != "" {
+			digests[img] = repoDigest
+		}
+	}
+	if len(digests) == 0 {
+		return nil
+	}
+	return digests
+}
+
+// matchRepoDigest picks the repo digest that belongs to the same repository as imageRef,
+// falling back to the first parseable digest.
+func matchRepoDigest(imageRef string, repoDigests []string) string {
+	wantName := ""
+	if named, err := ref.ParseNormalizedNamed(imageRef); err == nil {
+		wantName = named.Name()
+	}
+
+	fallback := ""
+	for _, repoDigest := range repoDigests {
+		named, err := ref.ParseNormalizedNamed(repoDigest)
+		if err != nil {
+			continue
+		}
+		if _, ok := named.(ref.Digested); !ok {
+			continue
+		}
+		if named.Name() == wantName {
+			return repoDigest
+		}
+		if fallback == "" {
+			fallback = repoDigest
+		}
+	}
+	return fallback
+}
+
+// pinProjectImagesInternal points each image reference back at the digest recorded for it, pulling
+// the digest if it is no longer present locally. Pinning is best effort: an image that can't be
+// restored is deployed with whatever the tag currently resolves to.
+func (s *ProjectService) pinProjectImagesInternal(ctx context.Context, imageDigests models.JSON, user models.User) map[string]string {
+	if len(imageDigests) == 0 || s.dockerService == nil {
+		return nil
+	}
+	dockerClient, err := s.dockerService.GetClient()
+	if err != nil {
+		slog.WarnContext(ctx, "failed to connect to Docker to pin rollback images", "error", err)
+		return nil
+	}
+
+	// The image each tag pointed to before, "" when the tag didn't exist.
+	previous := map[string]string{}
+
+	for imageRef, raw := range imageDigests {
+		digestRef, ok := raw.(string)
+		if !ok || digestRef == "" {
+			continue
+		}
+
+		if _, err := dockerClient.ImageInspect(ctx, digestRef); err != nil {
+			if s.imageService == nil {
+				continue
+			}
+			if perr := s.imageService.PullImage(ctx, digestRef, io.Discard, user, nil); perr != nil {
+				slog.WarnContext(ctx, "failed to pull image digest for rollback", "image", imageRef, "digest", digestRef, "error", perr)
+				continue
+			}
+		}
+
+		previousID := ""
+		if inspect, err := dockerClient.ImageInspect(ctx, imageRef); err == nil {
+			previousID = inspect.ID
+		}
+		if err := dockerClient.ImageTag(ctx, digestRef, imageRef); err != nil {
+			slog.WarnContext(ctx, "failed to re-tag image for rollback", "image", imageRef, "digest", digestRef, "error", err)
+			continue
+		}
+		previous[imageRef] = previousID
+	}
+	return previous
+}
+
+// restoreImageTagsInternal points the tags re-pointed by pinProjectImagesInternal back to the
+// images they had before, and removes the tags that didn't exist.
+func (s *ProjectService) restoreImageTagsInternal(ctx context.Context, previous map[string]string) {
+	if len(previous) == 0 {
+		return
+	}
+	dockerClient, err := s.dockerService.GetClient()
+	if err != nil {
+		slog.WarnContext(ctx, "failed to connect to Docker to restore image tags", "error", err)
+		return
+	}
+
+	for imageRef, imageID := range previous {
+		if imageID == "" {
+			if _, err := dockerClient.ImageRemove(ctx, imageRef, image.RemoveOptions{}); err != nil {
+				slog.WarnContext(ctx, "failed to remove image tag after failed rollback", "image", imageRef, "error", err)
+			}
+			continue
+		}
+		if err := dockerClient.ImageTag(ctx, imageID, imageRef); err != nil {
+			slog.WarnContext(ctx, "failed to restore image tag after failed rollback", "image", imageRef, "imageId", imageID, "error", err)
+		}
+	}
+}
+
 // ensureProjectPathUnderRoot validates that the project's path is a safe subdirectory of the configured projects root.
 // If not, it normalizes the path to `<projectsRoot>/<dirName or sanitized project name>`. When persist=true, it saves
 // the updated project path to the database.
@@ -2,6 +2,9 @@ package services
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,7 +22,7 @@ func setupProjectTestDB(t *testing.T) *database.DB {
 	t.Helper()
 	db, err := gorm.Open(glsqlite.Open(":memory:"), &gorm.Config{})
 	require.NoError(t, err)
-	require.NoError(t, db.AutoMigrate(&models.Project{}, &models.SettingVariable{}))
+	require.NoError(t, db.AutoMigrate(&models.Project{}, &models.SettingVariable{}, &models.ProjectDeployment{}))
 	return &database.DB{DB: db}
 }
 
@@ -29,7 +32,7 @@ func TestProjectService_GetProjectFromDatabaseByID(t *testing.T) {
 
 	// Setup dependencies
 	settingsService, _ := NewSettingsService(ctx, db)
-	svc := NewProjectService(db, settingsService, nil, nil, nil)
+	svc := NewProjectService(db, settingsService, nil, nil, nil, nil)
 
 	// Create test project
 	proj := &models.Project{
@@ -146,7 +149,7 @@ func TestProjectService_CalculateProjectStatus(t *testing.T) {
 func TestProjectService_UpdateProjectStatusInternal(t *testing.T) {
 	db := setupProjectTestDB(t)
 	ctx := context.Background()
-	svc := NewProjectService(db, nil, nil, nil, nil)
+	svc := NewProjectService(db, nil, nil, nil, nil, nil)
 
 	proj := &models.Project{
 		BaseModel: models.BaseModel{
@@ -252,3 +255,233 @@ func TestProjectService_NormalizeComposeProjectName(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectService_RecordProjectDeploymentInternal(t *testing.T) {
+	db := setupProjectTestDB(t)
+	ctx := context.Background()
+	svc := NewProjectService(db, nil, nil, nil, nil, nil)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("include:\n  - extra.yaml\nservices:\n  web:\n    image: nginx:1.25\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "extra.yaml"), []byte("services:\n  db:\n    image: postgres:16\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("FOO=bar\n"), 0o600))
+
+	proj := &models.Project{BaseModel: models.BaseModel{ID: "p1"}, Name: "demo", Path: dir}
+	require.NoError(t, db.Create(proj).Error)
+
+	user := models.User{BaseModel: models.BaseModel{ID: "u1"}, Username: "alice"}
+	svc.recordProjectDeploymentInternal(ctx, proj, nil, user, models.ProjectDeploymentActionDeploy, nil, nil)
+	svc.recordProjectDeploymentInternal(ctx, proj, nil, user, models.ProjectDeploymentActionDeploy, nil, assert.AnError)
+
+	deployments, err := svc.ListProjectDeployments(ctx, "p1")
+	require.NoError(t, err)
+	require.Len(t, deployments, 2)
+
+	_, err = svc.ListProjectDeployments(ctx, "missing")
+	require.ErrorIs(t, err, ErrProjectNotFound)
+
+	assert.Equal(t, 2, deployments[0].Revision)
+	assert.Equal(t, models.ProjectDeploymentStatusFailed, deployments[0].Status)
+	require.NotNil(t, deployments[0].Error)
+
+	first := deployments[1]
+	assert.Equal(t, 1, first.Revision)
+	assert.Equal(t, models.ProjectDeploymentStatusSucceeded, first.Status)
+	assert.Contains(t, first.ComposeContent, "nginx:1.25")
+	require.NotNil(t, first.EnvContent)
+	assert.Equal(t, "FOO=bar\n", *first.EnvContent)
+	assert.Equal(t, "services:\n  db:\n    image: postgres:16\n", first.IncludeFiles["extra.yaml"])
+	require.NotNil(t, first.Username)
+	assert.Equal(t, "alice", *first.Username)
+}
+
+func TestProjectService_RecordProjectDeploymentInternal_PrunesHistory(t *testing.T) {
+	db := setupProjectTestDB(t)
+	ctx := context.Background()
+	svc := NewProjectService(db, nil, nil, nil, nil, nil)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services:\n  web:\n    image: nginx\n"), 0o600))
+
+	proj := &models.Project{BaseModel: models.BaseModel{ID: "p1"}, Name: "demo", Path: dir}
+	require.NoError(t, db.Create(proj).Error)
+
+	for range maxProjectDeploymentHistory + 3 {
+		svc.recordProjectDeploymentInternal(ctx, proj, nil, models.User{}, models.ProjectDeploymentActionDeploy, nil, nil)
+	}
+
+	deployments, err := svc.ListProjectDeployments(ctx, "p1")
+	require.NoError(t, err)
+	require.Len(t, deployments, maxProjectDeploymentHistory)
+	assert.Equal(t, maxProjectDeploymentHistory+3, deployments[0].Revision)
+	assert.Equal(t, 4, deployments[len(deployments)-1].Revision)
+}
+
+func TestProjectService_FindRollbackTargetInternal(t *testing.T) {
+	db := setupProjectTestDB(t)
+	ctx := context.Background()
+	svc := NewProjectService(db, nil, nil, nil, nil, nil)
+
+	_, err := svc.findRollbackTargetInternal(ctx, "p1", "")
+	require.ErrorIs(t, err, ErrProjectRollbackUnavailable)
+	assert.Contains(t, err.Error(), "no recorded deployments")
+
+	for _, d := range []models.ProjectDeployment{
+		{BaseModel: models.BaseModel{ID: "d1"}, ProjectID: "p1", Revision: 1, Status: models.ProjectDeploymentStatusSucceeded},
+		{BaseModel: models.BaseModel{ID: "d2"}, ProjectID: "p1", Revision: 2, Status: models.ProjectDeploymentStatusFailed},
+		{BaseModel: models.BaseModel{ID: "d3"}, ProjectID: "p1", Revision: 3, Status: models.ProjectDeploymentStatusSucceeded},
+		{BaseModel: models.BaseModel{ID: "other"}, ProjectID: "p2", Revision: 1, Status: models.ProjectDeploymentStatusSucceeded},
+	} {
+		require.NoError(t, db.Create(&d).Error)
+	}
+
+	target, err := svc.findRollbackTargetInternal(ctx, "p1", "")
+	require.NoError(t, err)
+	assert.Equal(t, "d1", target.ID)
+
+	target, err = svc.findRollbackTargetInternal(ctx, "p1", "d3")
+	require.NoError(t, err)
+	assert.Equal(t, "d3", target.ID)
+
+	_, err = svc.findRollbackTargetInternal(ctx, "p1", "d2")
+	require.ErrorIs(t, err, ErrProjectRollbackUnavailable)
+	assert.Contains(t, err.Error(), "did not succeed")
+
+	_, err = svc.findRollbackTargetInternal(ctx, "p1", "other")
+	require.ErrorIs(t, err, ErrProjectDeploymentNotFound)
+}
+
+func TestRestoreDeploymentFilesInternal_BlanksEnvWhenSnapshotHadNone(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "demo")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("SECRET=current\n"), 0o600))
+	proj := &models.Project{Name: "demo", Path: dir}
+
+	target := &models.ProjectDeployment{
+		ComposeContent: "services:\n  web:\n    image: nginx:1.25\n",
+		IncludeFiles:   models.JSON{"extra.yaml": "services: {}\n"},
+	}
+	require.NoError(t, restoreDeploymentFilesInternal(root, proj, target))
+
+	env, err := os.ReadFile(filepath.Join(dir, ".env"))
+	require.NoError(t, err)
+	assert.Empty(t, env)
+	extra, err := os.ReadFile(filepath.Join(dir, "extra.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "services: {}\n", string(extra))
+
+	envContent := "FOO=bar\n"
+	target.EnvContent = &envContent
+	require.NoError(t, restoreDeploymentFilesInternal(root, proj, target))
+	env, err = os.ReadFile(filepath.Join(dir, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "FOO=bar\n", string(env))
+}
+
+func TestProjectService_RollbackProject_RestoresFilesWhenDeployFails(t *testing.T) {
+	db := setupProjectTestDB(t)
+	ctx := context.Background()
+	settingsService, err := NewSettingsService(ctx, db)
+	require.NoError(t, err)
+	root := t.TempDir()
+	require.NoError(t, settingsService.SetStringSetting(ctx, "projectsDirectory", root))
+	svc := NewProjectService(db, settingsService, nil, nil, nil, nil)
+	engine, dcli := newFakeDockerEngine(t)
+	svc.dockerService = &DockerClientService{client: dcli}
+	currentImage := "sha256:" + strings.Repeat("c", 64)
+	engine.tags["nginx:1.25"] = currentImage
+
+	dir := filepath.Join(root, "demo")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	current := "include:\n  - extra.yaml\nservices:\n  web:\n    image: nginx:1.27\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte(current), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "extra.yaml"), []byte("services:\n  db:\n    image: postgres:17\n"), 0o600))
+
+	proj := &models.Project{BaseModel: models.BaseModel{ID: "p1"}, Name: "demo", Path: dir}
+	require.NoError(t, db.Create(proj).Error)
+
+	// The target cannot be loaded, so its deploy fails before reaching Docker.
+	envContent := "FOO=old\n"
+	target := &models.ProjectDeployment{
+		BaseModel:      models.BaseModel{ID: "d1"},
+		ProjectID:      "p1",
+		Revision:       1,
+		Status:         models.ProjectDeploymentStatusSucceeded,
+		ComposeContent: "services: [\n",
+		EnvContent:     &envContent,
+		IncludeFiles:   models.JSON{"extra.yaml": "services: {}\n", "added.yaml": "services: {}\n"},
+		ImageDigests:   models.JSON{"nginx:1.25": "nginx@sha256:" + strings.Repeat("a", 64)},
+	}
+	require.NoError(t, db.Create(target).Error)
+
+	_, err = svc.RollbackProject(ctx, "p1", "d1", models.User{})
+	require.Error(t, err)
+
+	assert.Equal(t, currentImage, engine.tags["docker.io/library/nginx:1.25"], "the pinned tag must point back to its previous image")
+	assert.Equal(t, []string{"tag nginx@sha256:" + strings.Repeat("a", 64) + " docker.io/library/nginx:1.25", "tag " + currentImage + " docker.io/library/nginx:1.25"}, engine.recordedCalls())
+
+	compose, err := os.ReadFile(filepath.Join(dir, "compose.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, current, string(compose))
+	extra, err := os.ReadFile(filepath.Join(dir, "extra.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "services:\n  db:\n    image: postgres:17\n", string(extra))
+	_, err = os.Stat(filepath.Join(dir, ".env"))
+	assert.True(t, os.IsNotExist(err), "a .env the project did not have must not be left behind")
+	_, err = os.Stat(filepath.Join(dir, "added.yaml"))
+	assert.True(t, os.IsNotExist(err), "an include file the project did not have must not be left behind")
+}
+
+func TestMatchRepoDigest(t *testing.T) {
+	digests := []string{
+		"ghcr.io/example/nginx@sha256:1111111111111111111111111111111111111111111111111111111111111111",
+		"nginx@sha256:2222222222222222222222222222222222222222222222222222222222222222",
+	}
+
+	assert.Equal(t, digests[1], matchRepoDigest("nginx:1.25", digests))
+	assert.Equal(t, digests[0], matchRepoDigest("ghcr.io/example/nginx:latest", digests))
+	assert.Equal(t, digests[0], matchRepoDigest("redis:7", digests))
+	assert.Empty(t, matchRepoDigest("nginx:1.25", nil))
+}
+
+func TestProjectService_SyncProjectFiles_AppliesExecutableBit(t *testing.T) {
+	db := setupProjectTestDB(t)
+	ctx := context.Background()
+	settingsService, err := NewSettingsService(ctx, db)
+	require.NoError(t, err)
+	root := t.TempDir()
+	require.NoError(t, settingsService.SetStringSetting(ctx, "projectsDirectory", root))
+	svc := NewProjectService(db, settingsService, nil, nil, nil, nil)
+
+	dir := filepath.Join(root, "demo")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	proj := &models.Project{BaseModel: models.BaseModel{ID: "p1"}, Name: "demo", Path: dir}
+	require.NoError(t, db.Create(proj).Error)
+
+	files := map[string]string{"entrypoint.sh": "#!/bin/sh\n", "web.env": "PORT=80\n"}
+	modes := map[string]os.FileMode{"entrypoint.sh": 0o755, "web.env": 0o644}
+	changed, err := svc.SyncProjectFiles(ctx, "p1", files, modes, nil)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	info, err := os.Stat(filepath.Join(dir, "entrypoint.sh"))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode().Perm()&0o100, "the script must stay executable")
+	info, err = os.Stat(filepath.Join(dir, "web.env"))
+	require.NoError(t, err)
+	assert.Zero(t, info.Mode().Perm()&0o111)
+
+	changed, err = svc.SyncProjectFiles(ctx, "p1", files, modes, nil)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// A mode change alone is a change too.
+	modes["entrypoint.sh"] = 0o644
+	changed, err = svc.SyncProjectFiles(ctx, "p1", files, modes, nil)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	info, err = os.Stat(filepath.Join(dir, "entrypoint.sh"))
+	require.NoError(t, err)
+	assert.Zero(t, info.Mode().Perm()&0o111)
+}
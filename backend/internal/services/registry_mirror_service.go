@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/types/registrymirror"
+	"gorm.io/gorm"
+)
+
+// maxMirrorErrorLength bounds the pull error stored on a mirror.
+const maxMirrorErrorLength = 1024
+
+// RegistryMirrorService manages the registry mirrors of this environment and picks the mirror
+// references an image pull should try before going to the upstream registry.
+type RegistryMirrorService struct {
+	db *database.DB
+}
+
+// MirrorCandidate is an image reference rewritten for one mirror.
+type MirrorCandidate struct {
+	Mirror models.RegistryMirror
+	Ref    string
+}
+
+func NewRegistryMirrorService(db *database.DB) *RegistryMirrorService {
+	return &RegistryMirrorService{db: db}
+}
+
+func (s *RegistryMirrorService) ListMirrors(ctx context.Context) ([]models.RegistryMirror, error) {
+	var mirrors []models.RegistryMirror
+	if err := s.db.WithContext(ctx).Order("registry ASC, priority ASC, created_at ASC").Find(&mirrors).Error; err != nil {
+		return nil, fmt.Errorf("failed to list registry mirrors: %w", err)
+	}
+	return mirrors, nil
+}
+
+func (s *RegistryMirrorService) GetMirrorByID(ctx context.Context, id string) (*models.RegistryMirror, error) {
+	var m models.RegistryMirror
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &models.NotFoundError{Message: "Registry mirror not found"}
+		}
+		return nil, fmt.Errorf("failed to get registry mirror: %w", err)
+	}
+	return &m, nil
+}
+
+func (s *RegistryMirrorService) CreateMirror(ctx context.Context, req registrymirror.CreateRequest) (*models.RegistryMirror, error) {
+	m := models.RegistryMirror{
+		Registry:           models.NormalizeMirrorRegistry(req.Registry),
+		MirrorURL:          normalizeMirrorURLInternal(req.MirrorURL),
+		RepositoryPattern:  strings.TrimSpace(req.RepositoryPattern),
+		Description:        req.Description,
+		Priority:           req.Priority,
+		Enabled:            req.Enabled == nil || *req.Enabled,
+		FallbackToUpstream: req.FallbackToUpstream == nil || *req.FallbackToUpstream,
+	}
+	if err := validateMirrorInternal(&m); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Create(&m).Error; err != nil {
+		return nil, fmt.Errorf("failed to create registry mirror: %w", err)
+	}
+	return &m, nil
+}
+
+func (s *RegistryMirrorService) UpdateMirror(ctx context.Context, id string, req registrymirror.UpdateRequest) (*models.RegistryMirror, error) {
+	m, err := s.GetMirrorByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Registry != nil {
+		m.Registry = models.NormalizeMirrorRegistry(*req.Registry)
+	}
+	if req.MirrorURL != nil {
+		m.MirrorURL = normalizeMirrorURLInternal(*req.MirrorURL)
+	}
+	if req.RepositoryPattern != nil {
+		m.RepositoryPattern = strings.TrimSpace(*req.RepositoryPattern)
+	}
+	if req.Description != nil {
+		m.Description = req.Description
+	}
+	if req.Priority != nil {
+		m.Priority = *req.Priority
+	}
+	if req.Enabled != nil {
+		m.Enabled = *req.Enabled
+	}
+	if req.FallbackToUpstream != nil {
+		m.FallbackToUpstream = *req.FallbackToUpstream
+	}
+	if err := validateMirrorInternal(m); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Save(m).Error; err != nil {
+		return nil, fmt.Errorf("failed to update registry mirror: %w", err)
+	}
+	return m, nil
+}
+
+func (s *RegistryMirrorService) DeleteMirror(ctx context.Context, id string) error {
+	res := s.db.WithContext(ctx).Where("id = ?", id).Delete(&models.RegistryMirror{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete registry mirror: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return &models.NotFoundError{Message: "Registry mirror not found"}
+	}
+	return nil
+}
+
+// ResolveMirrors returns the enabled mirrors that serve imageRef, in the order they should be
+// tried, together with the rewritten reference for each.
+func (s *RegistryMirrorService) ResolveMirrors(ctx context.Context, imageRef string) ([]MirrorCandidate, error) {
+	var mirrors []models.RegistryMirror
+	if err := s.db.WithContext(ctx).Where("enabled = ?", true).Order("priority ASC, created_at ASC").Find(&mirrors).Error; err != nil {
+		return nil, fmt.Errorf("failed to load registry mirrors: %w", err)
+	}
+
+	var candidates []MirrorCandidate
+	for _, m := range mirrors {
+		if rewritten, ok := m.Rewrite(imageRef); ok {
+			candidates = append(candidates, MirrorCandidate{Mirror: m, Ref: rewritten})
+		}
+	}
+	return candidates, nil
+}
+
+// RecordPull stores the outcome of a pull through a mirror. A nil error marks the mirror as
+// used and clears the last error.
+func (s *RegistryMirrorService) RecordPull(ctx context.Context, id string, pullErr error) {
+	updates := map[string]interface{}{}
+	if pullErr == nil {
+		updates["last_used_at"] = time.Now()
+		updates["last_error"] = nil
+	} else {
+		msg := pullErr.Error()
+		if len(msg) > maxMirrorErrorLength {
+			msg = msg[:maxMirrorErrorLength]
+		}
+		updates["last_error"] = msg
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.RegistryMirror{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		slog.WarnContext(ctx, "Failed to record registry mirror pull", "mirror", id, "error", err)
+	}
+}
+
+func normalizeMirrorURLInternal(u string) string {
+	u = strings.TrimSpace(u)
+	u = strings.TrimPrefix(u, "https://")
+	u = strings.TrimPrefix(u, "http://")
+	return strings.TrimSuffix(u, "/")
+}
+
+func validateMirrorInternal(m *models.RegistryMirror) error {
+	if m.Registry == "" {
+		return &models.ValidationError{Message: "Registry is required", Field: "registry"}
+	}
+	if m.MirrorURL == "" {
+		return &models.ValidationError{Message: "Mirror URL is required", Field: "mirrorUrl"}
+	}
+	if models.NormalizeMirrorRegistry(m.MirrorURL) == m.Registry && !strings.Contains(m.MirrorURL, "/") {
+		return &models.ValidationError{Message: "Mirror URL must differ from the upstream registry", Field: "mirrorUrl"}
+	}
+	if m.RepositoryPattern != "" {
+		if _, err := path.Match(m.RepositoryPattern, ""); err != nil {
+			return &models.ValidationError{Message: "Invalid repository pattern: " + err.Error(), Field: "repositoryPattern"}
+		}
+	}
+	return nil
+}
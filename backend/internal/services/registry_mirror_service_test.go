@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	glsqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/types/registrymirror"
+)
+
+func setupRegistryMirrorTestService(t *testing.T) *RegistryMirrorService {
+	t.Helper()
+	gdb, err := gorm.Open(glsqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, gdb.AutoMigrate(&models.RegistryMirror{}))
+
+	return NewRegistryMirrorService(&database.DB{DB: gdb})
+}
+
+func TestRegistryMirror_Rewrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		mirror models.RegistryMirror
+		image  string
+		want   string
+		ok     bool
+	}{
+		{
+			name:   "docker hub official image",
+			mirror: models.RegistryMirror{Registry: "docker.io", MirrorURL: "mirror.local", Enabled: true},
+			image:  "nginx",
+			want:   "mirror.local/library/nginx:latest",
+			ok:     true,
+		},
+		{
+			name:   "path prefix and registry alias",
+			mirror: models.RegistryMirror{Registry: "https://index.docker.io/v1/", MirrorURL: "https://harbor.local/dockerhub/", Enabled: true},
+			image:  "grafana/grafana:11.0.0",
+			want:   "harbor.local/dockerhub/grafana/grafana:11.0.0",
+			ok:     true,
+		},
+		{
+			name:   "other registry",
+			mirror: models.RegistryMirror{Registry: "ghcr.io", MirrorURL: "ghcr-cache.local", Enabled: true},
+			image:  "ghcr.io/getarcaneapp/arcane:v1",
+			want:   "ghcr-cache.local/getarcaneapp/arcane:v1",
+			ok:     true,
+		},
+		{
+			name:   "registry mismatch",
+			mirror: models.RegistryMirror{Registry: "ghcr.io", MirrorURL: "ghcr-cache.local", Enabled: true},
+			image:  "nginx:1.27",
+		},
+		{
+			name:   "pattern match",
+			mirror: models.RegistryMirror{Registry: "docker.io", MirrorURL: "mirror.local", RepositoryPattern: "library/*", Enabled: true},
+			image:  "redis:7",
+			want:   "mirror.local/library/redis:7",
+			ok:     true,
+		},
+		{
+			name:   "pattern mismatch",
+			mirror: models.RegistryMirror{Registry: "docker.io", MirrorURL: "mirror.local", RepositoryPattern: "library/*", Enabled: true},
+			image:  "grafana/grafana",
+		},
+		{
+			name:   "digest reference",
+			mirror: models.RegistryMirror{Registry: "docker.io", MirrorURL: "mirror.local", Enabled: true},
+			image:  "nginx@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:   "disabled",
+			mirror: models.RegistryMirror{Registry: "docker.io", MirrorURL: "mirror.local"},
+			image:  "nginx",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.mirror.Rewrite(tt.image)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegistryMirrorService_ResolveMirrorsOrdersByPriority(t *testing.T) {
+	svc := setupRegistryMirrorTestService(t)
+	ctx := context.Background()
+	disabled := false
+
+	_, err := svc.CreateMirror(ctx, registrymirror.CreateRequest{Registry: "docker.io", MirrorURL: "second.local", Priority: 20})
+	require.NoError(t, err)
+	_, err = svc.CreateMirror(ctx, registrymirror.CreateRequest{Registry: "docker.io", MirrorURL: "first.local", Priority: 10})
+	require.NoError(t, err)
+	_, err = svc.CreateMirror(ctx, registrymirror.CreateRequest{Registry: "docker.io", MirrorURL: "off.local", Enabled: &disabled})
+	require.NoError(t, err)
+	_, err = svc.CreateMirror(ctx, registrymirror.CreateRequest{Registry: "ghcr.io", MirrorURL: "ghcr.local"})
+	require.NoError(t, err)
+
+	candidates, err := svc.ResolveMirrors(ctx, "docker.io/library/alpine:3.20")
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	assert.Equal(t, "first.local/library/alpine:3.20", candidates[0].Ref)
+	assert.Equal(t, "second.local/library/alpine:3.20", candidates[1].Ref)
+	assert.True(t, candidates[0].Mirror.FallbackToUpstream)
+}
+
+func TestRegistryMirrorService_RecordPull(t *testing.T) {
+	svc := setupRegistryMirrorTestService(t)
+	ctx := context.Background()
+
+	m, err := svc.CreateMirror(ctx, registrymirror.CreateRequest{Registry: "docker.io", MirrorURL: "mirror.local"})
+	require.NoError(t, err)
+
+	svc.RecordPull(ctx, m.ID, errors.New("manifest unknown"))
+	got, err := svc.GetMirrorByID(ctx, m.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.LastError)
+	assert.Equal(t, "manifest unknown", *got.LastError)
+	assert.Nil(t, got.LastUsedAt)
+
+	svc.RecordPull(ctx, m.ID, nil)
+	got, err = svc.GetMirrorByID(ctx, m.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.LastError)
+	assert.NotNil(t, got.LastUsedAt)
+}
+
+func TestRegistryMirrorService_Validation(t *testing.T) {
+	svc := setupRegistryMirrorTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateMirror(ctx, registrymirror.CreateRequest{Registry: "docker.io", MirrorURL: "docker.io"})
+	var validationErr *models.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "mirrorUrl", validationErr.Field)
+
+	_, err = svc.CreateMirror(ctx, registrymirror.CreateRequest{Registry: "docker.io", MirrorURL: "mirror.local", RepositoryPattern: "library/["})
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "repositoryPattern", validationErr.Field)
+
+	m, err := svc.CreateMirror(ctx, registrymirror.CreateRequest{Registry: "Registry-1.Docker.io", MirrorURL: "http://mirror.local/"})
+	require.NoError(t, err)
+	assert.Equal(t, "docker.io", m.Registry)
+	assert.Equal(t, "mirror.local", m.MirrorURL)
+
+	_, err = svc.UpdateMirror(ctx, "missing", registrymirror.UpdateRequest{})
+	var notFound *models.NotFoundError
+	require.ErrorAs(t, err, &notFound)
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/rbac"
+	roletypes "github.com/getarcaneapp/arcane/types/role"
+	"gorm.io/gorm"
+)
+
+// builtInRoleOrder lists the built-in roles from least to most privileged.
+var builtInRoleOrder = []string{rbac.RoleViewer, rbac.RoleOperator, rbac.RoleDeployer, rbac.RoleAdmin}
+
+// RoleService manages custom roles and role assignments, and decides whether a user holds a
+// permission in an environment.
+//
+// A user's roles come from two places: User.Roles, which apply in every environment, and
+// role assignments, which apply in one environment or all of them and optionally only to one
+// project.
+type RoleService struct {
+	db *database.DB
+}
+
+func NewRoleService(db *database.DB) *RoleService {
+	return &RoleService{db: db}
+}
+
+// ListRoles returns the built-in roles followed by the custom roles.
+func (s *RoleService) ListRoles(ctx context.Context) ([]roletypes.Role, error) {
+	roles := make([]roletypes.Role, 0, len(builtInRoleOrder))
+	for _, name := range builtInRoleOrder {
+		roles = append(roles, builtInRoleInternal(name))
+	}
+
+	var custom []models.Role
+	if err := s.db.WithContext(ctx).Order("name ASC").Find(&custom).Error; err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	for i := range custom {
+		roles = append(roles, customRoleInternal(&custom[i]))
+	}
+	return roles, nil
+}
+
+func (s *RoleService) GetRole(ctx context.Context, name string) (*roletypes.Role, error) {
+	if rbac.IsBuiltInRole(name) && name != rbac.RoleUser {
+		r := builtInRoleInternal(name)
+		return &r, nil
+	}
+
+	m, err := s.getCustomRoleInternal(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	r := customRoleInternal(m)
+	return &r, nil
+}
+
+func (s *RoleService) CreateRole(ctx context.Context, req roletypes.CreateRequest) (*roletypes.Role, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if name == "" {
+		return nil, &models.ValidationError{Message: "Role name is required", Field: "name"}
+	}
+	if rbac.IsBuiltInRole(name) {
+		return nil, &models.ConflictError{Message: fmt.Sprintf("Role %s is a built-in role", name)}
+	}
+	perms, err := normalizePermissionsInternal(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Role{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check role name: %w", err)
+	}
+	if count > 0 {
+		return nil, &models.ConflictError{Message: fmt.Sprintf("Role %s already exists", name)}
+	}
+
+	m := models.Role{Name: name, Description: req.Description, Permissions: perms}
+	if err := s.db.WithContext(ctx).Create(&m).Error; err != nil {
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+	r := customRoleInternal(&m)
+	return &r, nil
+}
+
+func (s *RoleService) UpdateRole(ctx context.Context, name string, req roletypes.UpdateRequest) (*roletypes.Role, error) {
+	if rbac.IsBuiltInRole(name) {
+		return nil, &models.ConflictError{Message: "Built-in roles can't be changed"}
+	}
+	m, err := s.getCustomRoleInternal(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Description != nil {
+		m.Description = req.Description
+	}
+	if req.Permissions != nil {
+		perms, err := normalizePermissionsInternal(req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		m.Permissions = perms
+	}
+
+	if err := s.db.WithContext(ctx).Save(m).Error; err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+	r := customRoleInternal(m)
+	return &r, nil
+}
+
+// DeleteRole deletes a custom role, its assignments, and removes it from the users that hold it.
+func (s *RoleService) DeleteRole(ctx context.Context, name string) error {
+	if rbac.IsBuiltInRole(name) {
+		return &models.ConflictError{Message: "Built-in roles can't be deleted"}
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("name = ?", name).Delete(&models.Role{})
+		if res.Error != nil {
+			return fmt.Errorf("failed to delete role: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return &models.NotFoundError{Message: "Role not found"}
+		}
+
+		if err := tx.Where("role = ?", name).Delete(&models.RoleAssignment{}).Error; err != nil {
+			return fmt.Errorf("failed to delete role assignments: %w", err)
+		}
+
+		// Roles are stored as JSON, so the users holding the role are filtered here.
+		var users []models.User
+		if err := tx.Select("id", "roles").Find(&users).Error; err != nil {
+			return fmt.Errorf("failed to load users: %w", err)
+		}
+		for i := range users {
+			if !slices.Contains(users[i].Roles, name) {
+				continue
+			}
+			users[i].Roles = slices.DeleteFunc(users[i].Roles, func(r string) bool { return r == name })
+			if err := tx.Model(&users[i]).Update("roles", users[i].Roles).Error; err != nil {
+				return fmt.Errorf("failed to remove role from user: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ValidateRoleNames checks that every name is a built-in or custom role.
+func (s *RoleService) ValidateRoleNames(ctx context.Context, names []string) error {
+	var custom []string
+	for _, n := range names {
+		if !rbac.IsBuiltInRole(n) {
+			custom = append(custom, n)
+		}
+	}
+	if len(custom) == 0 {
+		return nil
+	}
+
+	var found []string
+	if err := s.db.WithContext(ctx).Model(&models.Role{}).Where("name IN ?", custom).Pluck("name", &found).Error; err != nil {
+		return fmt.Errorf("failed to look up roles: %w", err)
+	}
+	for _, n := range custom {
+		if !slices.Contains(found, n) {
+			return &models.ValidationError{Message: fmt.Sprintf("Role %s does not exist", n), Field: "roles"}
+		}
+	}
+	return nil
+}
+
+func (s *RoleService) ListUserAssignments(ctx context.Context, userID string) ([]models.RoleAssignment, error) {
+	var assignments []models.RoleAssignment
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at ASC").Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list role assignments: %w", err)
+	}
+	return assignments, nil
+}
+
+// SetUserAssignments replaces the role assignments of a user.
+func (s *RoleService) SetUserAssignments(ctx context.Context, userID string, req []roletypes.Assignment) ([]models.RoleAssignment, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+	if count == 0 {
+		return nil, &models.NotFoundError{Message: "User not found"}
+	}
+
+	assignments := make([]models.RoleAssignment, 0, len(req))
+	names := make([]string, 0, len(req))
+	for _, a := range req {
+		envID := trimmedOrNilInternal(a.EnvironmentID)
+		projectID := trimmedOrNilInternal(a.ProjectID)
+		if projectID != nil && envID == nil {
+			return nil, &models.ValidationError{Message: "A project role assignment needs an environment", Field: "environmentId"}
+		}
+		role := strings.TrimSpace(a.Role)
+		assignments = append(assignments, models.RoleAssignment{
+			UserID:        userID,
+			Role:          role,
+			EnvironmentID: envID,
+			ProjectID:     projectID,
+		})
+		names = append(names, role)
+	}
+	if err := s.ValidateRoleNames(ctx, names); err != nil {
+		return nil, err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RoleAssignment{}).Error; err != nil {
+			return fmt.Errorf("failed to clear role assignments: %w", err)
+		}
+		if len(assignments) == 0 {
+			return nil
+		}
+		if err := tx.Create(&assignments).Error; err != nil {
+			return fmt.Errorf("failed to save role assignments: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// Permissions returns the permissions user holds in an environment, and for a project when
+// projectID is set. Project-scoped assignments only count for requests that name their
+// project, so routes covering a whole environment need an environment-wide grant. admin is
+// true when the user holds every permission there.
+func (s *RoleService) Permissions(ctx context.Context, user *models.User, envID, projectID string) (perms []string, admin bool, err error) {
+	if user == nil {
+		return nil, false, nil
+	}
+
+	names := slices.Clone([]string(user.Roles))
+
+	q := s.db.WithContext(ctx).Model(&models.RoleAssignment{}).
+		Where("user_id = ?", user.ID).
+		Where("(environment_id IS NULL OR environment_id = ?)", envID)
+	if projectID == "" {
+		q = q.Where("project_id IS NULL")
+	} else {
+		q = q.Where("(project_id IS NULL OR project_id = ?)", projectID)
+	}
+	var assigned []string
+	if err := q.Pluck("role", &assigned).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to load role assignments: %w", err)
+	}
+	names = append(names, assigned...)
+
+	var custom []string
+	for _, n := range names {
+		if n == rbac.RoleAdmin {
+			return []string{rbac.Wildcard}, true, nil
+		}
+		if builtIn, ok := rbac.BuiltInPermissions(n); ok {
+			perms = append(perms, builtIn...)
+		} else {
+			custom = append(custom, n)
+		}
+	}
+
+	if len(custom) > 0 {
+		var roles []models.Role
+		if err := s.db.WithContext(ctx).Where("name IN ?", custom).Find(&roles).Error; err != nil {
+			return nil, false, fmt.Errorf("failed to load roles: %w", err)
+		}
+		for _, r := range roles {
+			perms = append(perms, r.Permissions...)
+		}
+	}
+
+	slices.Sort(perms)
+	perms = slices.Compact(perms)
+	return perms, slices.ContainsFunc(perms, isFullWildcardInternal), nil
+}
+
+// Authorize returns a ForbiddenError unless user holds permission in the environment, or in
+// the project when projectID is set.
+func (s *RoleService) Authorize(ctx context.Context, user *models.User, permission, envID, projectID string) error {
+	perms, admin, err := s.Permissions(ctx, user, envID, projectID)
+	if err != nil {
+		return err
+	}
+	if admin || rbac.GrantsAny(perms, permission) {
+		return nil
+	}
+	return &models.ForbiddenError{Message: fmt.Sprintf("Missing permission %s in this environment", permission)}
+}
+
+// AuthorizeRoute authorizes a request to a route pattern of an environment. Routes that are not
+// environment resources are left to the handlers, and environment routes without a mapped
+// permission are denied to everyone but admins. A missing envID only matches global assignments.
+func (s *RoleService) AuthorizeRoute(ctx context.Context, user *models.User, method, route, envID, projectID string) error {
+	permission, scoped, err := rbac.RequiredPermission(method, route)
+	switch {
+	case errors.Is(err, rbac.ErrUnmappedRoute):
+		_, admin, err := s.Permissions(ctx, user, envID, projectID)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			return &models.ForbiddenError{Message: "This route is restricted to administrators"}
+		}
+		return nil
+	case !scoped:
+		return nil
+	}
+	return s.Authorize(ctx, user, permission, envID, projectID)
+}
+
+func (s *RoleService) getCustomRoleInternal(ctx context.Context, name string) (*models.Role, error) {
+	var m models.Role
+	if err := s.db.WithContext(ctx).Where("name = ?", name).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &models.NotFoundError{Message: "Role not found"}
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return &m, nil
+}
+
+func builtInRoleInternal(name string) roletypes.Role {
+	perms, _ := rbac.BuiltInPermissions(name)
+	desc := rbac.BuiltInRoleDescriptions[name]
+	return roletypes.Role{
+		ID:          name,
+		Name:        name,
+		Description: &desc,
+		Permissions: slices.Clone(perms),
+		BuiltIn:     true,
+	}
+}
+
+func customRoleInternal(m *models.Role) roletypes.Role {
+	createdAt := m.CreatedAt
+	perms := []string(m.Permissions)
+	if perms == nil {
+		perms = []string{}
+	}
+	return roletypes.Role{
+		ID:          m.ID,
+		Name:        m.Name,
+		Description: m.Description,
+		Permissions: perms,
+		CreatedAt:   &createdAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+func normalizePermissionsInternal(perms []string) (models.StringSlice, error) {
+	out := make(models.StringSlice, 0, len(perms))
+	for _, p := range perms {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if !rbac.ValidatePermission(p) {
+			return nil, &models.ValidationError{Message: fmt.Sprintf("Unknown permission %q", p), Field: "permissions"}
+		}
+		if !slices.Contains(out, p) {
+			out = append(out, p)
+		}
+	}
+	if len(out) == 0 {
+		return nil, &models.ValidationError{Message: "A role needs at least one permission", Field: "permissions"}
+	}
+	return out, nil
+}
+
+func isFullWildcardInternal(p string) bool {
+	return p == rbac.Wildcard || p == rbac.Permission(rbac.Wildcard, rbac.Wildcard)
+}
+
+func trimmedOrNilInternal(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
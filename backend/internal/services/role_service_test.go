@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	glsqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	roletypes "github.com/getarcaneapp/arcane/types/role"
+)
+
+func setupRoleTestService(t *testing.T) *RoleService {
+	t.Helper()
+	gdb, err := gorm.Open(glsqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, gdb.AutoMigrate(&models.User{}, &models.Role{}, &models.RoleAssignment{}))
+
+	return NewRoleService(&database.DB{DB: gdb})
+}
+
+func TestRoleService_AuthorizeScopedAssignments(t *testing.T) {
+	svc := setupRoleTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateRole(ctx, roletypes.CreateRequest{Name: "Restarter", Permissions: []string{"containers:read", "containers:restart"}})
+	require.NoError(t, err)
+
+	contractor := &models.User{BaseModel: models.BaseModel{ID: "u1"}, Username: "contractor", Roles: models.StringSlice{}}
+	require.NoError(t, svc.db.WithContext(ctx).Create(contractor).Error)
+
+	env := "env-1"
+	project := "p1"
+	_, err = svc.SetUserAssignments(ctx, contractor.ID, []roletypes.Assignment{
+		{Role: "restarter", EnvironmentID: &env},
+		{Role: "deployer", EnvironmentID: &env, ProjectID: &project},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Authorize(ctx, contractor, "containers:restart", env, ""))
+
+	err = svc.Authorize(ctx, contractor, "containers:stop", env, "")
+	var forbidden *models.ForbiddenError
+	require.ErrorAs(t, err, &forbidden)
+
+	err = svc.Authorize(ctx, contractor, "containers:restart", "env-2", "")
+	require.ErrorAs(t, err, &forbidden)
+
+	require.NoError(t, svc.AuthorizeRoute(ctx, contractor, http.MethodPost, "/environments/{id}/projects/{projectId}/up", env, project))
+	err = svc.AuthorizeRoute(ctx, contractor, http.MethodPost, "/environments/{id}/projects/{projectId}/up", env, "p2")
+	require.ErrorAs(t, err, &forbidden)
+
+	require.NoError(t, svc.AuthorizeRoute(ctx, contractor, http.MethodGet, "/users", env, ""))
+
+	// Project-scoped assignments don't grant routes covering the whole environment.
+	err = svc.AuthorizeRoute(ctx, contractor, http.MethodGet, "/environments/{id}/projects", env, "")
+	require.ErrorAs(t, err, &forbidden)
+
+	// Without an environment only global assignments count.
+	err = svc.AuthorizeRoute(ctx, contractor, http.MethodPost, "/environments/{id}/containers/{containerId}/restart", "", "")
+	require.ErrorAs(t, err, &forbidden)
+
+	// Environment routes without a mapped permission are denied to everyone but admins.
+	err = svc.AuthorizeRoute(ctx, contractor, http.MethodPost, "/environments/{id}/unknown", env, "")
+	require.ErrorAs(t, err, &forbidden)
+
+	admin := &models.User{BaseModel: models.BaseModel{ID: "u2"}, Roles: models.StringSlice{"admin"}}
+	perms, isAdmin, err := svc.Permissions(ctx, admin, "env-2", "")
+	require.NoError(t, err)
+	assert.True(t, isAdmin)
+	assert.Equal(t, []string{"*"}, perms)
+	require.NoError(t, svc.AuthorizeRoute(ctx, admin, http.MethodPost, "/environments/{id}/unknown", "env-2", ""))
+
+	legacy := &models.User{BaseModel: models.BaseModel{ID: "u3"}, Roles: models.StringSlice{"user"}}
+	require.NoError(t, svc.Authorize(ctx, legacy, "projects:deploy", "env-2", ""))
+	require.ErrorAs(t, svc.Authorize(ctx, legacy, "settings:update", "env-2", ""), &forbidden)
+}
+
+func TestRoleService_RoleValidation(t *testing.T) {
+	svc := setupRoleTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateRole(ctx, roletypes.CreateRequest{Name: "viewer", Permissions: []string{"containers:read"}})
+	var conflict *models.ConflictError
+	require.ErrorAs(t, err, &conflict)
+
+	_, err = svc.CreateRole(ctx, roletypes.CreateRequest{Name: "ops", Permissions: []string{"containers:fly"}})
+	var validationErr *models.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "permissions", validationErr.Field)
+
+	require.ErrorAs(t, svc.ValidateRoleNames(ctx, []string{"admin", "missing"}), &validationErr)
+
+	_, err = svc.SetUserAssignments(ctx, "nobody", nil)
+	var notFound *models.NotFoundError
+	require.ErrorAs(t, err, &notFound)
+}
+
+func TestRoleService_DeleteRoleRemovesItFromUsers(t *testing.T) {
+	svc := setupRoleTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateRole(ctx, roletypes.CreateRequest{Name: "ops", Permissions: []string{"containers:*"}})
+	require.NoError(t, err)
+	u := &models.User{BaseModel: models.BaseModel{ID: "u1"}, Roles: models.StringSlice{"user", "ops"}}
+	require.NoError(t, svc.db.WithContext(ctx).Create(u).Error)
+	_, err = svc.SetUserAssignments(ctx, u.ID, []roletypes.Assignment{{Role: "ops"}})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.DeleteRole(ctx, "ops"))
+
+	var got models.User
+	require.NoError(t, svc.db.WithContext(ctx).First(&got, "id = ?", u.ID).Error)
+	assert.Equal(t, models.StringSlice{"user"}, got.Roles)
+
+	assignments, err := svc.ListUserAssignments(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Empty(t, assignments)
+
+	require.ErrorAs(t, svc.DeleteRole(ctx, "admin"), new(*models.ConflictError))
+}
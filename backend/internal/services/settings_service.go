@@ -89,6 +89,10 @@ func (s *SettingsService) getDefaultSettings() *models.Settings {
 		DiskUsagePath:              models.SettingVariable{Value: "/app/data/projects"},
 		AutoUpdate:                 models.SettingVariable{Value: "false"},
 		AutoUpdateInterval:         models.SettingVariable{Value: "0 0 0 * * *"},
+		AutoUpdateHealthTimeout:    models.SettingVariable{Value: "120"},
+		AutoUpdateMinUptime:        models.SettingVariable{Value: "10"},
+		AutoUpdateBumpComposeTags:  models.SettingVariable{Value: "false"},
+		ImageTagUpdatePolicy:       models.SettingVariable{Value: "none"},
 		PollingEnabled:             models.SettingVariable{Value: "true"},
 		PollingInterval:            models.SettingVariable{Value: "0 0 * * * *"},
 		EventCleanupInterval:       models.SettingVariable{Value: "0 0 */6 * * *"},
@@ -106,9 +110,11 @@ func (s *SettingsService) getDefaultSettings() *models.Settings {
 		EnableGravatar:             models.SettingVariable{Value: "true"},
 		DefaultShell:               models.SettingVariable{Value: "/bin/sh"},
 		DockerHost:                 models.SettingVariable{Value: "unix:///var/run/docker.sock"},
+		DockerConfigPath:           models.SettingVariable{Value: ""},
 		AuthLocalEnabled:           models.SettingVariable{Value: "true"},
 		AuthSessionTimeout:         models.SettingVariable{Value: "1440"},
 		AuthPasswordPolicy:         models.SettingVariable{Value: "strong"},
+		AuthRequireTwoFactor:       models.SettingVariable{Value: "false"},
 		TrivyImage:                 models.SettingVariable{Value: "ghcr.io/aquasecurity/trivy:latest"},
 		// AuthOidcConfig DEPRECATED will be removed in a future release
 		AuthOidcConfig:             models.SettingVariable{Value: "{}"},
@@ -128,6 +134,20 @@ func (s *SettingsService) getDefaultSettings() *models.Settings {
 		OidcMergeAccounts:          models.SettingVariable{Value: "false"},
 		OidcProviderName:           models.SettingVariable{Value: ""},
 		OidcProviderLogoUrl:        models.SettingVariable{Value: ""},
+		LdapEnabled:                models.SettingVariable{Value: "false"},
+		LdapUrl:                    models.SettingVariable{Value: ""},
+		LdapStartTls:               models.SettingVariable{Value: "false"},
+		LdapSkipTlsVerify:          models.SettingVariable{Value: "false"},
+		LdapBindDn:                 models.SettingVariable{Value: ""},
+		LdapBindPassword:           models.SettingVariable{Value: ""},
+		LdapBaseDn:                 models.SettingVariable{Value: ""},
+		LdapUserFilter:             models.SettingVariable{Value: "(uid={username})"},
+		LdapUsernameAttribute:      models.SettingVariable{Value: "uid"},
+		LdapEmailAttribute:         models.SettingVariable{Value: "mail"},
+		LdapDisplayNameAttribute:   models.SettingVariable{Value: "cn"},
+		LdapGroupAttribute:         models.SettingVariable{Value: "memberOf"},
+		LdapGroupFilter:            models.SettingVariable{Value: ""},
+		LdapGroupRoleMapping:       models.SettingVariable{Value: "{}"},
 		MobileNavigationMode:       models.SettingVariable{Value: "floating"},
 		MobileNavigationShowLabels: models.SettingVariable{Value: "true"},
 		SidebarHoverExpansion:      models.SettingVariable{Value: "true"},
@@ -446,6 +466,10 @@ func (s *SettingsService) UpdateSettings(ctx context.Context, updates settings.U
 		return nil, err
 	}
 
+	if err := s.handleLdapBindPasswordUpdate(ctx, updates); err != nil {
+		return nil, err
+	}
+
 	// Reload and store settings BEFORE calling callbacks so they read updated values
 	settings, err := s.GetSettings(ctx)
 	if err != nil {
@@ -520,6 +544,12 @@ func (s *SettingsService) prepareUpdateValues(updates settings.Update, cfg, defa
 			}
 		}
 
+		if key == "ldapGroupRoleMapping" && value != "" {
+			if _, err := parseLdapGroupRoleMapping(value); err != nil {
+				return nil, false, false, false, false, nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+		}
+
 		var valueToSave string
 		var err error
 
@@ -631,6 +661,17 @@ func (s *SettingsService) handleOidcConfigUpdate(ctx context.Context, updates se
 	return nil
 }
 
+// handleLdapBindPasswordUpdate stores the sensitive LDAP bind password. An empty value keeps the existing password.
+func (s *SettingsService) handleLdapBindPasswordUpdate(ctx context.Context, updates settings.Update) error {
+	if updates.LdapBindPassword == nil || *updates.LdapBindPassword == "" {
+		return nil
+	}
+	if err := s.UpdateSetting(ctx, "ldapBindPassword", *updates.LdapBindPassword); err != nil {
+		return fmt.Errorf("failed to update ldapBindPassword: %w", err)
+	}
+	return nil
+}
+
 func (s *SettingsService) EnsureDefaultSettings(ctx context.Context) error {
 	defaultSettings := s.getDefaultSettings()
 	defaultSettingVars := defaultSettings.ToSettingVariableSlice(true, false)
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/crypto"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/totp"
+	"github.com/getarcaneapp/arcane/types/auth"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTwoFactorInvalidCode = errors.New("invalid two-factor code")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotSetUp    = errors.New("two-factor authentication setup has not been started")
+	ErrTwoFactorLocked      = errors.New("too many invalid two-factor codes, try again later")
+)
+
+const (
+	totpIssuer        = "Arcane"
+	recoveryCodeCount = 10
+	// recoveryCodeBytes of randomness give a 16 character base32 code, shown as two groups of 8.
+	recoveryCodeBytes = 10
+	// maxTwoFactorFailures invalid codes in a row lock two-factor checks of the user for
+	// twoFactorLockout, which bounds guessing to a few codes per window.
+	maxTwoFactorFailures = 5
+	twoFactorLockout     = 15 * time.Minute
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorService manages TOTP enrollment and recovery codes of local accounts.
+type TwoFactorService struct {
+	db              *database.DB
+	userService     *UserService
+	settingsService *SettingsService
+}
+
+func NewTwoFactorService(db *database.DB, userService *UserService, settingsService *SettingsService) *TwoFactorService {
+	return &TwoFactorService{
+		db:              db,
+		userService:     userService,
+		settingsService: settingsService,
+	}
+}
+
+// IsRequired reports whether the admins require two-factor authentication for local accounts.
+func (s *TwoFactorService) IsRequired(ctx context.Context) bool {
+	if s.settingsService == nil {
+		return false
+	}
+	settings, err := s.settingsService.GetSettings(ctx)
+	if err != nil {
+		return false
+	}
+	return settings.AuthRequireTwoFactor.IsTrue()
+}
+
+// GetStatus returns the two-factor authentication status of a user.
+func (s *TwoFactorService) GetStatus(ctx context.Context, userID string) (*auth.TwoFactorStatus, error) {
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var remaining int64
+	if user.TotpEnabled {
+		if err := s.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Count(&remaining).Error; err != nil {
+			return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+		}
+	}
+
+	return &auth.TwoFactorStatus{
+		Enabled:                user.TotpEnabled,
+		Required:               s.IsRequired(ctx),
+		RecoveryCodesRemaining: int(remaining),
+	}, nil
+}
+
+// BeginTotpSetup generates a new secret for a user. It only takes effect once EnableTotp
+// confirms the user's authenticator app produces matching codes.
+func (s *TwoFactorService) BeginTotpSetup(ctx context.Context, userID string) (*auth.TotpSetup, error) {
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.PasswordHash == "" {
+		return nil, &models.ValidationError{Message: "Two-factor authentication is only available for local accounts"}
+	}
+	if user.TotpEnabled {
+		return nil, &models.ConflictError{Message: "Two-factor authentication is already enabled"}
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := crypto.Encrypt(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt TOTP secret: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", user.ID).
+		Updates(map[string]any{"totp_secret": encrypted, "totp_last_step": 0}).Error; err != nil {
+		return nil, fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+
+	return &auth.TotpSetup{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(totpIssuer, user.Username, secret),
+	}, nil
+}
+
+// EnableTotp confirms a setup started with BeginTotpSetup and returns a fresh set of
+// recovery codes.
+func (s *TwoFactorService) EnableTotp(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TotpEnabled {
+		return nil, &models.ConflictError{Message: "Two-factor authentication is already enabled"}
+	}
+	if user.TotpSecret == nil || *user.TotpSecret == "" {
+		return nil, ErrTwoFactorNotSetUp
+	}
+
+	secret, err := crypto.Decrypt(*user.TotpSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+	step, ok := totp.Validate(secret, code, time.Now(), user.TotpLastStep)
+	if !ok {
+		return nil, ErrTwoFactorInvalidCode
+	}
+
+	var codes []string
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Updates(map[string]any{"totp_enabled": true, "totp_last_step": step}).Error; err != nil {
+			return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+		}
+		codes, err = replaceRecoveryCodesInternal(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTotp turns off two-factor authentication after checking a current code.
+func (s *TwoFactorService) DisableTotp(ctx context.Context, userID, code string) error {
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.VerifyCode(ctx, user, code); err != nil {
+		return err
+	}
+	return s.Reset(ctx, user.ID)
+}
+
+// Reset removes the authenticator and recovery codes of a user, e.g. when an admin helps a
+// user who lost both.
+func (s *TwoFactorService) Reset(ctx context.Context, userID string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.User{}).Where("id = ?", userID).
+			Updates(map[string]any{
+				"totp_secret":          nil,
+				"totp_enabled":         false,
+				"totp_last_step":       0,
+				"totp_failed_attempts": 0,
+				"totp_locked_until":    nil,
+			})
+		if res.Error != nil {
+			return fmt.Errorf("failed to disable two-factor authentication: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+		if err := tx.Delete(&models.RecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		return nil
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after checking a current code.
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.VerifyCode(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		codes, err = replaceRecoveryCodesInternal(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// VerifyCode checks an authenticator code or an unused recovery code of a user with
+// two-factor authentication enabled. Both can only be used once. After maxTwoFactorFailures
+// invalid codes in a row, codes are refused with ErrTwoFactorLocked until the lockout ends.
+func (s *TwoFactorService) VerifyCode(ctx context.Context, user *models.User, code string) error {
+	if !user.TotpEnabled || user.TotpSecret == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	// The lockout is read from the database, since user may have been loaded before it began.
+	var current models.User
+	if err := s.db.WithContext(ctx).Select("totp_locked_until").Where("id = ?", user.ID).Take(&current).Error; err != nil {
+		return fmt.Errorf("failed to check two-factor lockout: %w", err)
+	}
+	user.TotpLockedUntil = current.TotpLockedUntil
+	if user.TotpLockedUntil != nil && user.TotpLockedUntil.After(time.Now()) {
+		return ErrTwoFactorLocked
+	}
+
+	err := s.verifyCodeInternal(ctx, user, code)
+	switch {
+	case errors.Is(err, ErrTwoFactorInvalidCode):
+		return s.recordFailureInternal(ctx, user)
+	case err != nil:
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND totp_failed_attempts > 0", user.ID).
+		Update("totp_failed_attempts", 0).Error; err != nil {
+		return fmt.Errorf("failed to reset two-factor failures: %w", err)
+	}
+	user.TotpFailedAttempts = 0
+	return nil
+}
+
+// twoFactorLockoutStampInternal identifies the latest two-factor lockout of user, or is 0.
+func twoFactorLockoutStampInternal(user *models.User) int64 {
+	if user.TotpLockedUntil == nil {
+		return 0
+	}
+	return user.TotpLockedUntil.Unix()
+}
+
+func (s *TwoFactorService) verifyCodeInternal(ctx context.Context, user *models.User, code string) error {
+	code = strings.TrimSpace(code)
+	if len(strings.ReplaceAll(code, " ", "")) == totp.Digits {
+		secret, err := crypto.Decrypt(*user.TotpSecret)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+		}
+		step, ok := totp.Validate(secret, code, time.Now(), user.TotpLastStep)
+		if !ok {
+			return ErrTwoFactorInvalidCode
+		}
+		// Only the request that moves the last step forward wins, so a code can't be replayed.
+		res := s.db.WithContext(ctx).Model(&models.User{}).
+			Where("id = ? AND totp_last_step < ?", user.ID, step).
+			Update("totp_last_step", step)
+		if res.Error != nil {
+			return fmt.Errorf("failed to record TOTP code use: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrTwoFactorInvalidCode
+		}
+		user.TotpLastStep = step
+		return nil
+	}
+
+	res := s.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashRecoveryCodeInternal(code)).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return fmt.Errorf("failed to use recovery code: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrTwoFactorInvalidCode
+	}
+	return nil
+}
+
+// recordFailureInternal counts an invalid code and starts the lockout once there are too many.
+// The counter is incremented in the database so concurrent guesses all count.
+func (s *TwoFactorService) recordFailureInternal(ctx context.Context, user *models.User) error {
+	var failures int
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Update("totp_failed_attempts", gorm.Expr("totp_failed_attempts + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Pluck("totp_failed_attempts", &failures).Error; err != nil {
+			return err
+		}
+		if failures < maxTwoFactorFailures {
+			return nil
+		}
+		lockedUntil := time.Now().Add(twoFactorLockout)
+		user.TotpLockedUntil = &lockedUntil
+		failures = 0
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Updates(map[string]any{"totp_failed_attempts": 0, "totp_locked_until": lockedUntil}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record two-factor failure: %w", err)
+	}
+
+	user.TotpFailedAttempts = failures
+	if user.TotpLockedUntil != nil && user.TotpLockedUntil.After(time.Now()) {
+		slog.WarnContext(ctx, "Locked two-factor authentication after too many invalid codes", "user_id", user.ID)
+		return ErrTwoFactorLocked
+	}
+	return ErrTwoFactorInvalidCode
+}
+
+func replaceRecoveryCodesInternal(tx *gorm.DB, userID string) ([]string, error) {
+	if err := tx.Delete(&models.RecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		code := raw[:8] + "-" + raw[8:]
+		codes = append(codes, code)
+		rows = append(rows, models.RecoveryCode{UserID: userID, CodeHash: hashRecoveryCodeInternal(code)})
+	}
+
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// hashRecoveryCodeInternal hashes a recovery code ignoring case, spaces and dashes. The codes
+// carry 80 bits of randomness, so a fast hash is enough.
+func hashRecoveryCodeInternal(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	glsqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/getarcaneapp/arcane/backend/internal/config"
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/crypto"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/totp"
+)
+
+func setupTwoFactorTestService(t *testing.T) (*TwoFactorService, *models.User) {
+	t.Helper()
+	db, err := gorm.Open(glsqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.RecoveryCode{}))
+
+	crypto.InitEncryption(&config.Config{
+		EncryptionKey: "test-encryption-key-for-testing-32bytes-min",
+		Environment:   "test",
+	})
+
+	user := &models.User{Username: "jane", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	wrapped := &database.DB{DB: db}
+	return NewTwoFactorService(wrapped, NewUserService(wrapped), nil), user
+}
+
+func TestTwoFactorService_EnableAndVerify(t *testing.T) {
+	ctx := context.Background()
+	svc, user := setupTwoFactorTestService(t)
+
+	setup, err := svc.BeginTotpSetup(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Contains(t, setup.ProvisioningURI, "secret="+setup.Secret)
+
+	stored, err := svc.userService.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.TotpSecret)
+	assert.NotEqual(t, setup.Secret, *stored.TotpSecret, "the secret is stored encrypted")
+
+	_, err = svc.EnableTotp(ctx, user.ID, "000000")
+	require.ErrorIs(t, err, ErrTwoFactorInvalidCode)
+
+	step := totp.Step(time.Now())
+	code, err := totp.Code(setup.Secret, step-1)
+	require.NoError(t, err)
+	recoveryCodes, err := svc.EnableTotp(ctx, user.ID, code)
+	require.NoError(t, err)
+	require.Len(t, recoveryCodes, recoveryCodeCount)
+
+	stored, err = svc.userService.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.TotpEnabled)
+
+	// The code used for enrollment can't be used again.
+	require.ErrorIs(t, svc.VerifyCode(ctx, stored, code), ErrTwoFactorInvalidCode)
+
+	next, err := totp.Code(setup.Secret, step)
+	require.NoError(t, err)
+	require.NoError(t, svc.VerifyCode(ctx, stored, next))
+
+	// A stale copy of the user must not allow a replay either.
+	stale := *stored
+	stale.TotpLastStep = 0
+	require.ErrorIs(t, svc.VerifyCode(ctx, &stale, next), ErrTwoFactorInvalidCode)
+
+	status, err := svc.GetStatus(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, recoveryCodeCount, status.RecoveryCodesRemaining)
+}
+
+func TestTwoFactorService_RecoveryCodes(t *testing.T) {
+	ctx := context.Background()
+	svc, user := setupTwoFactorTestService(t)
+
+	setup, err := svc.BeginTotpSetup(ctx, user.ID)
+	require.NoError(t, err)
+	code, err := totp.Code(setup.Secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	recoveryCodes, err := svc.EnableTotp(ctx, user.ID, code)
+	require.NoError(t, err)
+
+	stored, err := svc.userService.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.VerifyCode(ctx, stored, " "+recoveryCodes[0]+" "))
+	require.ErrorIs(t, svc.VerifyCode(ctx, stored, recoveryCodes[0]), ErrTwoFactorInvalidCode, "recovery codes are single use")
+	require.ErrorIs(t, svc.VerifyCode(ctx, stored, "aaaaaaaa-bbbbbbbb"), ErrTwoFactorInvalidCode)
+
+	status, err := svc.GetStatus(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, recoveryCodeCount-1, status.RecoveryCodesRemaining)
+
+	require.NoError(t, svc.Reset(ctx, user.ID))
+	stored, err = svc.userService.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.TotpEnabled)
+	assert.Nil(t, stored.TotpSecret)
+	require.ErrorIs(t, svc.VerifyCode(ctx, stored, recoveryCodes[1]), ErrTwoFactorNotEnabled)
+
+	require.ErrorIs(t, svc.Reset(ctx, "missing"), ErrUserNotFound)
+}
+
+func TestTwoFactorService_LocksAfterRepeatedFailures(t *testing.T) {
+	ctx := context.Background()
+	svc, user := setupTwoFactorTestService(t)
+
+	setup, err := svc.BeginTotpSetup(ctx, user.ID)
+	require.NoError(t, err)
+	step := totp.Step(time.Now())
+	code, err := totp.Code(setup.Secret, step-1)
+	require.NoError(t, err)
+	recoveryCodes, err := svc.EnableTotp(ctx, user.ID, code)
+	require.NoError(t, err)
+
+	authSvc := newTestAuthService("")
+	authSvc.userService = svc.userService
+	authSvc.twoFactorService = svc
+
+	stored, err := svc.userService.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	challenge, err := authSvc.issueTwoFactorChallenge(stored, twoFactorPurposeVerify)
+	require.NoError(t, err)
+
+	// A valid code clears the failures before it.
+	for range maxTwoFactorFailures - 1 {
+		_, _, _, err = authSvc.VerifyTwoFactorLogin(ctx, challenge.Token, "000000")
+		require.ErrorIs(t, err, ErrTwoFactorInvalidCode)
+	}
+	require.NoError(t, svc.VerifyCode(ctx, stored, recoveryCodes[0]))
+	stored, err = svc.userService.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Zero(t, stored.TotpFailedAttempts)
+
+	for range maxTwoFactorFailures - 1 {
+		_, _, _, err = authSvc.VerifyTwoFactorLogin(ctx, challenge.Token, "000000")
+		require.ErrorIs(t, err, ErrTwoFactorInvalidCode)
+	}
+	_, _, _, err = authSvc.VerifyTwoFactorLogin(ctx, challenge.Token, "aaaaaaaa-bbbbbbbb")
+	require.ErrorIs(t, err, ErrTwoFactorLocked)
+
+	next, err := totp.Code(setup.Secret, step)
+	require.NoError(t, err)
+
+	// The challenge used for guessing is invalidated, and a new one is locked until the lockout ends.
+	_, _, _, err = authSvc.VerifyTwoFactorLogin(ctx, challenge.Token, next)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	stored, err = svc.userService.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.TotpLockedUntil)
+	assert.WithinDuration(t, time.Now().Add(twoFactorLockout), *stored.TotpLockedUntil, time.Minute)
+	challenge, err = authSvc.issueTwoFactorChallenge(stored, twoFactorPurposeVerify)
+	require.NoError(t, err)
+	_, _, _, err = authSvc.VerifyTwoFactorLogin(ctx, challenge.Token, next)
+	require.ErrorIs(t, err, ErrTwoFactorLocked)
+
+	require.NoError(t, svc.db.Model(&models.User{}).Where("id = ?", user.ID).
+		Update("totp_locked_until", time.Now().Add(-time.Second)).Error)
+	stored, err = svc.userService.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NoError(t, svc.VerifyCode(ctx, stored, next))
+}
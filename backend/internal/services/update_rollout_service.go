@@ -0,0 +1,556 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/mapper"
+	"github.com/getarcaneapp/arcane/backend/internal/utils/pagination"
+	"github.com/getarcaneapp/arcane/types/base"
+	"github.com/getarcaneapp/arcane/types/rollout"
+	"github.com/getarcaneapp/arcane/types/updater"
+	"gorm.io/gorm"
+)
+
+// rolloutHistoryLimit is how many update records of an environment are checked when gating a
+// stage, the same number the updater history endpoint returns by default.
+const rolloutHistoryLimit = 50
+
+// rolloutPollInterval is how often the status of an updater run started on a remote
+// environment is checked.
+var rolloutPollInterval = 5 * time.Second
+
+var activeRolloutStatuses = []models.UpdateRolloutStatus{models.UpdateRolloutStatusRunning, models.UpdateRolloutStatusSoaking}
+
+// UpdateRolloutService drives the updater of several environments in stages, so that an image
+// update is only promoted to the next group of environments once the previous group stayed
+// healthy for its soak time.
+type UpdateRolloutService struct {
+	db                 *database.DB
+	environmentService *EnvironmentService
+	updaterService     *UpdaterService
+	eventService       *EventService
+
+	processMu sync.Mutex
+}
+
+func NewUpdateRolloutService(db *database.DB, environmentService *EnvironmentService, updaterService *UpdaterService, eventService *EventService) *UpdateRolloutService {
+	return &UpdateRolloutService{
+		db:                 db,
+		environmentService: environmentService,
+		updaterService:     updaterService,
+		eventService:       eventService,
+	}
+}
+
+func (s *UpdateRolloutService) GetRolloutsPaginated(ctx context.Context, params pagination.QueryParams) ([]rollout.Rollout, pagination.Response, error) {
+	var rollouts []models.UpdateRollout
+	q := s.db.WithContext(ctx).Model(&models.UpdateRollout{})
+
+	if term := strings.TrimSpace(params.Search); term != "" {
+		q = q.Where("name LIKE ?", "%"+term+"%")
+	}
+	q = pagination.ApplyFilter(q, "status", params.Filters["status"])
+
+	paginationResp, err := pagination.PaginateAndSortDB(params, q, &rollouts)
+	if err != nil {
+		return nil, pagination.Response{}, fmt.Errorf("failed to paginate update rollouts: %w", err)
+	}
+
+	out, mapErr := mapper.MapSlice[models.UpdateRollout, rollout.Rollout](rollouts)
+	if mapErr != nil {
+		return nil, pagination.Response{}, fmt.Errorf("failed to map rollouts: %w", mapErr)
+	}
+
+	return out, paginationResp, nil
+}
+
+func (s *UpdateRolloutService) GetRolloutByID(ctx context.Context, id string) (*models.UpdateRollout, error) {
+	var r models.UpdateRollout
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&r).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &models.NotFoundError{Message: "Rollout not found"}
+		}
+		return nil, fmt.Errorf("failed to get rollout: %w", err)
+	}
+	return &r, nil
+}
+
+func (s *UpdateRolloutService) CreateRollout(ctx context.Context, req rollout.CreateRequest) (*models.UpdateRollout, error) {
+	stages, err := s.validateStagesInternal(ctx, req.Stages)
+	if err != nil {
+		return nil, err
+	}
+
+	r := models.UpdateRollout{
+		Name:   strings.TrimSpace(req.Name),
+		Stages: stages,
+		Status: models.UpdateRolloutStatusIdle,
+	}
+	if r.Name == "" {
+		return nil, &models.ValidationError{Message: "Name is required", Field: "name"}
+	}
+	if err := s.db.WithContext(ctx).Create(&r).Error; err != nil {
+		return nil, fmt.Errorf("failed to create rollout: %w", err)
+	}
+	return &r, nil
+}
+
+func (s *UpdateRolloutService) UpdateRollout(ctx context.Context, id string, req rollout.UpdateRequest) (*models.UpdateRollout, error) {
+	r, err := s.GetRolloutByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Active() {
+		return nil, &models.ConflictError{Message: "Rollout is in progress, cancel it before editing"}
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, &models.ValidationError{Message: "Name is required", Field: "name"}
+		}
+		r.Name = name
+	}
+	if req.Stages != nil {
+		if r.Stages, err = s.validateStagesInternal(ctx, req.Stages); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Save(r).Error; err != nil {
+		return nil, fmt.Errorf("failed to update rollout: %w", err)
+	}
+	return r, nil
+}
+
+func (s *UpdateRolloutService) DeleteRollout(ctx context.Context, id string) error {
+	res := s.db.WithContext(ctx).Where("id = ?", id).Delete(&models.UpdateRollout{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete rollout: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return &models.NotFoundError{Message: "Rollout not found"}
+	}
+	return nil
+}
+
+// StartRollout starts the first stage of a rollout in the background.
+func (s *UpdateRolloutService) StartRollout(ctx context.Context, id string) (*models.UpdateRollout, error) {
+	r, err := s.GetRolloutByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Active() {
+		return nil, &models.ConflictError{Message: "Rollout is already in progress"}
+	}
+	for _, stage := range r.Stages {
+		for _, envID := range stage.EnvironmentIDs {
+			if _, err := s.environmentService.GetEnvironmentByID(ctx, envID); err != nil {
+				return nil, &models.ValidationError{Message: fmt.Sprintf("Environment %s not found", envID), Field: "stages"}
+			}
+		}
+	}
+
+	now := time.Now()
+	r.Status = models.UpdateRolloutStatusRunning
+	r.CurrentStage = 0
+	r.NextStageAt = &now
+	r.StageStartedAt = nil
+	r.StartedAt = &now
+	r.FinishedAt = nil
+	r.LastError = nil
+	r.Results = nil
+	r.Images = nil
+	if err := s.db.WithContext(ctx).Save(r).Error; err != nil {
+		return nil, fmt.Errorf("failed to start rollout: %w", err)
+	}
+
+	go func() {
+		if err := s.ProcessDue(context.WithoutCancel(ctx)); err != nil {
+			slog.ErrorContext(ctx, "Failed to process update rollouts", "error", err)
+		}
+	}()
+
+	return r, nil
+}
+
+// CancelRollout stops a rollout before its next stage. Updates already applied are kept.
+func (s *UpdateRolloutService) CancelRollout(ctx context.Context, id string) (*models.UpdateRollout, error) {
+	r, err := s.GetRolloutByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !r.Active() {
+		return nil, &models.ConflictError{Message: "Rollout is not in progress"}
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":        models.UpdateRolloutStatusCancelled,
+		"next_stage_at": nil,
+		"finished_at":   now,
+	}
+	if err := s.db.WithContext(ctx).Model(r).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to cancel rollout: %w", err)
+	}
+	return s.GetRolloutByID(ctx, id)
+}
+
+// ProcessDue runs or promotes every rollout whose next stage is due. Runs never overlap, a
+// call made while another one is in progress returns immediately.
+func (s *UpdateRolloutService) ProcessDue(ctx context.Context) error {
+	if !s.processMu.TryLock() {
+		return nil
+	}
+	defer s.processMu.Unlock()
+
+	var due []models.UpdateRollout
+	err := s.db.WithContext(ctx).
+		Where("status IN ? AND next_stage_at <= ?", activeRolloutStatuses, time.Now()).
+		Find(&due).Error
+	if err != nil {
+		return fmt.Errorf("failed to list due rollouts: %w", err)
+	}
+
+	for i := range due {
+		s.advanceInternal(ctx, &due[i])
+	}
+	return nil
+}
+
+// advanceInternal moves a due rollout forward: a running stage is applied and starts soaking,
+// a soaked stage is checked and either fails the rollout or promotes the next stage. The first
+// stage applies every pending update and records the images it pulled, later stages apply only
+// those images, pinned to the digests the first stage was tested with.
+func (s *UpdateRolloutService) advanceInternal(ctx context.Context, r *models.UpdateRollout) {
+	if r.CurrentStage >= len(r.Stages) {
+		s.finishInternal(ctx, r, models.UpdateRolloutStatusFailed, "rollout has no stage to run")
+		return
+	}
+
+	if r.Status == models.UpdateRolloutStatusSoaking {
+		if err := s.checkStageHealthInternal(ctx, r); err != nil {
+			s.finishInternal(ctx, r, models.UpdateRolloutStatusFailed, err.Error())
+			return
+		}
+		if r.CurrentStage == len(r.Stages)-1 {
+			s.finishInternal(ctx, r, models.UpdateRolloutStatusCompleted, "")
+			return
+		}
+		r.CurrentStage++
+	}
+
+	now := time.Now()
+	r.Status = models.UpdateRolloutStatusRunning
+	r.StageStartedAt = &now
+	if !s.saveActiveInternal(ctx, r) {
+		return
+	}
+
+	stage := r.Stages[r.CurrentStage]
+	slog.InfoContext(ctx, "Running update rollout stage", "rollout", r.Name, "stage", r.CurrentStage, "environments", len(stage.EnvironmentIDs))
+
+	var images []string
+	if r.CurrentStage > 0 {
+		images = r.Images
+	}
+
+	var failures []string
+	for _, envID := range stage.EnvironmentIDs {
+		result := models.RolloutResult{Stage: r.CurrentStage, EnvironmentID: envID}
+		if r.CurrentStage > 0 && len(images) == 0 {
+			// The first stage applied no update, so there is nothing to promote.
+			result.FinishedAt = time.Now()
+			r.Results = append(r.Results, result)
+			continue
+		}
+
+		out, err := s.runUpdaterInternal(ctx, envID, images)
+		if err != nil {
+			result.Error = err.Error()
+			failures = append(failures, fmt.Sprintf("environment %s: %v", envID, err))
+		} else {
+			result.Updated, result.Failed, result.RolledBack = out.Updated, out.Failed, out.RolledBack
+			if out.Failed > 0 || out.RolledBack > 0 {
+				failures = append(failures, fmt.Sprintf("environment %s: %d failed, %d rolled back", envID, out.Failed, out.RolledBack))
+			}
+			if r.CurrentStage == 0 {
+				r.Images = appendAppliedImagesInternal(r.Images, out)
+			}
+		}
+		result.FinishedAt = time.Now()
+		r.Results = append(r.Results, result)
+	}
+
+	if len(failures) > 0 {
+		s.finishInternal(ctx, r, models.UpdateRolloutStatusFailed, fmt.Sprintf("stage %s failed: %s", stageLabelInternal(r, r.CurrentStage), strings.Join(failures, "; ")))
+		return
+	}
+
+	next := time.Now().Add(time.Duration(stage.SoakMinutes) * time.Minute)
+	r.Status = models.UpdateRolloutStatusSoaking
+	r.NextStageAt = &next
+	if !s.saveActiveInternal(ctx, r) {
+		return
+	}
+
+	s.logRolloutEventInternal(ctx, r, models.EventTypeUpdateRolloutStage, models.EventSeverityInfo,
+		"Rollout stage applied",
+		fmt.Sprintf("Stage %s of rollout '%s' was applied, soaking until %s", stageLabelInternal(r, r.CurrentStage), r.Name, next.Format(time.RFC3339)))
+}
+
+// checkStageHealthInternal verifies that the environments of the current stage are still online
+// and that no update on them failed or was rolled back since the stage started.
+func (s *UpdateRolloutService) checkStageHealthInternal(ctx context.Context, r *models.UpdateRollout) error {
+	since := time.Time{}
+	if r.StageStartedAt != nil {
+		since = *r.StageStartedAt
+	}
+
+	for _, envID := range r.Stages[r.CurrentStage].EnvironmentIDs {
+		env, err := s.environmentService.GetEnvironmentByID(ctx, envID)
+		if err != nil {
+			return fmt.Errorf("environment %s: %w", envID, err)
+		}
+		if envID != "0" && env.Status != string(models.EnvironmentStatusOnline) {
+			return fmt.Errorf("environment %s is %s", env.Name, env.Status)
+		}
+
+		history, err := s.getHistoryInternal(ctx, envID)
+		if err != nil {
+			return fmt.Errorf("environment %s: %w", env.Name, err)
+		}
+		for _, rec := range history {
+			if rec.StartTime.Before(since) {
+				continue
+			}
+			if rec.Status == models.AutoUpdateStatusFailed || rec.Status == models.AutoUpdateStatusRolledBack {
+				return fmt.Errorf("update of %s on environment %s %s", rec.ResourceName, env.Name, strings.ReplaceAll(string(rec.Status), "_", " "))
+			}
+		}
+	}
+	return nil
+}
+
+// runUpdaterInternal applies the pending updates of an environment, limited to images when it
+// is not nil.
+func (s *UpdateRolloutService) runUpdaterInternal(ctx context.Context, envID string, images []string) (*updater.Result, error) {
+	if envID == "0" {
+		return s.updaterService.ApplyPending(ctx, false, images)
+	}
+
+	// Health checks can keep a run going far longer than a proxied request may take, so the run
+	// is started in the background and its result polled from the updater status.
+	body, err := json.Marshal(updater.Options{Background: true, Images: images})
+	if err != nil {
+		return nil, err
+	}
+	var resp base.ApiResponse[updater.Result]
+	if err := s.proxyInternal(ctx, envID, http.MethodPost, "/api/environments/0/updater/run", body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.RunID == "" {
+		// Agents without background runs apply the updates within the request.
+		return &resp.Data, nil
+	}
+	return s.waitForUpdaterRunInternal(ctx, envID, resp.Data.RunID)
+}
+
+// waitForUpdaterRunInternal polls the updater status of an environment until the background
+// run runID finished and returns its result.
+func (s *UpdateRolloutService) waitForUpdaterRunInternal(ctx context.Context, envID, runID string) (*updater.Result, error) {
+	ticker := time.NewTicker(rolloutPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		var resp base.ApiResponse[updater.Status]
+		if err := s.proxyInternal(ctx, envID, http.MethodGet, "/api/environments/0/updater/status", nil, &resp); err != nil {
+			return nil, err
+		}
+		status := resp.Data
+		switch {
+		case status.RunID != runID:
+			return nil, fmt.Errorf("updater run %s is no longer tracked by the environment", runID)
+		case status.Running:
+			continue
+		case status.LastRunError != "":
+			return nil, errors.New(status.LastRunError)
+		case status.LastRun == nil:
+			return nil, fmt.Errorf("updater run %s finished without a result", runID)
+		default:
+			return status.LastRun, nil
+		}
+	}
+}
+
+func (s *UpdateRolloutService) getHistoryInternal(ctx context.Context, envID string) ([]models.AutoUpdateRecord, error) {
+	if envID == "0" {
+		return s.updaterService.GetHistory(ctx, rolloutHistoryLimit)
+	}
+
+	var resp base.ApiResponse[[]models.AutoUpdateRecord]
+	if err := s.proxyInternal(ctx, envID, http.MethodGet, "/api/environments/0/updater/history", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+func (s *UpdateRolloutService) proxyInternal(ctx context.Context, envID, method, path string, body []byte, out any) error {
+	env, err := s.environmentService.GetEnvironmentByID(ctx, envID)
+	if err != nil {
+		return err
+	}
+	if !env.Enabled {
+		return fmt.Errorf("environment is disabled")
+	}
+
+	respBody, statusCode, err := s.environmentService.ProxyRequest(ctx, envID, method, path, body)
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("environment returned status %d", statusCode)
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func (s *UpdateRolloutService) finishInternal(ctx context.Context, r *models.UpdateRollout, status models.UpdateRolloutStatus, reason string) {
+	now := time.Now()
+	r.Status = status
+	r.NextStageAt = nil
+	r.FinishedAt = &now
+	r.LastError = nil
+	if reason != "" {
+		r.LastError = &reason
+	}
+	if !s.saveActiveInternal(ctx, r) {
+		return
+	}
+
+	if status == models.UpdateRolloutStatusCompleted {
+		s.logRolloutEventInternal(ctx, r, models.EventTypeUpdateRolloutComplete, models.EventSeveritySuccess,
+			"Rollout completed", fmt.Sprintf("All %d stages of rollout '%s' were applied", len(r.Stages), r.Name))
+		return
+	}
+	slog.WarnContext(ctx, "Update rollout halted", "rollout", r.Name, "stage", r.CurrentStage, "reason", reason)
+	s.logRolloutEventInternal(ctx, r, models.EventTypeUpdateRolloutError, models.EventSeverityError,
+		"Rollout halted", fmt.Sprintf("Rollout '%s' stopped at stage %s: %s", r.Name, stageLabelInternal(r, r.CurrentStage), reason))
+}
+
+// saveActiveInternal stores the progress of a rollout unless it was cancelled meanwhile, and
+// reports whether the rollout should carry on.
+func (s *UpdateRolloutService) saveActiveInternal(ctx context.Context, r *models.UpdateRollout) bool {
+	res := s.db.WithContext(ctx).Model(r).
+		Where("status IN ?", activeRolloutStatuses).
+		Select("*").Updates(r)
+	if res.Error != nil {
+		slog.ErrorContext(ctx, "Failed to save rollout", "rolloutId", r.ID, "error", res.Error)
+		return false
+	}
+	if res.RowsAffected == 0 {
+		slog.InfoContext(ctx, "Update rollout is no longer in progress", "rollout", r.Name)
+		return false
+	}
+	return true
+}
+
+func (s *UpdateRolloutService) logRolloutEventInternal(ctx context.Context, r *models.UpdateRollout, eventType models.EventType, severity models.EventSeverity, title, description string) {
+	if s.eventService == nil {
+		return
+	}
+	resourceType := "update_rollout"
+	_, _ = s.eventService.CreateEvent(ctx, CreateEventRequest{
+		Type:         eventType,
+		Severity:     severity,
+		Title:        title,
+		Description:  description,
+		ResourceType: &resourceType,
+		ResourceID:   &r.ID,
+		ResourceName: &r.Name,
+		UserID:       &systemUser.ID,
+		Username:     &systemUser.Username,
+		Metadata: models.JSON{
+			"stage":  r.CurrentStage,
+			"status": string(r.Status),
+		},
+	})
+}
+
+func (s *UpdateRolloutService) validateStagesInternal(ctx context.Context, stages []rollout.Stage) (models.RolloutStages, error) {
+	if len(stages) == 0 {
+		return nil, &models.ValidationError{Message: "A rollout needs at least one stage", Field: "stages"}
+	}
+
+	seen := map[string]int{}
+	out := make(models.RolloutStages, 0, len(stages))
+	for i, stage := range stages {
+		if len(stage.EnvironmentIDs) == 0 {
+			return nil, &models.ValidationError{Message: fmt.Sprintf("Stage %d has no environments", i+1), Field: "stages"}
+		}
+		if stage.SoakMinutes < 0 {
+			return nil, &models.ValidationError{Message: fmt.Sprintf("Stage %d has a negative soak time", i+1), Field: "stages"}
+		}
+		for _, envID := range stage.EnvironmentIDs {
+			if prev, ok := seen[envID]; ok {
+				return nil, &models.ValidationError{Message: fmt.Sprintf("Environment %s is in stages %d and %d", envID, prev+1, i+1), Field: "stages"}
+			}
+			seen[envID] = i
+			if _, err := s.environmentService.GetEnvironmentByID(ctx, envID); err != nil {
+				return nil, &models.ValidationError{Message: fmt.Sprintf("Environment %s not found", envID), Field: "stages"}
+			}
+		}
+		out = append(out, models.RolloutStage{
+			Name:           strings.TrimSpace(stage.Name),
+			EnvironmentIDs: stage.EnvironmentIDs,
+			SoakMinutes:    stage.SoakMinutes,
+		})
+	}
+	return out, nil
+}
+
+// appendAppliedImagesInternal adds the images an updater run pulled to images, as references
+// pinned to the pulled digest. An image keeps the digest it was first pulled with, and images
+// pulled without a known digest can't be pinned and are not promoted.
+func appendAppliedImagesInternal(images models.StringSlice, out *updater.Result) models.StringSlice {
+	for _, item := range out.Items {
+		if item.ResourceType != "image" || !item.UpdateApplied || item.Digest == "" {
+			continue
+		}
+		ref := item.NewImages["main"]
+		known := slices.ContainsFunc(images, func(img string) bool {
+			pinnedRef, _, _ := strings.Cut(img, "@")
+			return pinnedRef == ref
+		})
+		if !known {
+			images = append(images, ref+"@"+item.Digest)
+		}
+	}
+	return images
+}
+
+func stageLabelInternal(r *models.UpdateRollout, i int) string {
+	if i < len(r.Stages) && r.Stages[i].Name != "" {
+		return fmt.Sprintf("%d (%s)", i+1, r.Stages[i].Name)
+	}
+	return fmt.Sprintf("%d", i+1)
+}
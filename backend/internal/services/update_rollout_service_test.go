@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	glsqlite "github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/getarcaneapp/arcane/backend/internal/database"
+	"github.com/getarcaneapp/arcane/backend/internal/models"
+	"github.com/getarcaneapp/arcane/types/base"
+	"github.com/getarcaneapp/arcane/types/rollout"
+	"github.com/getarcaneapp/arcane/types/updater"
+)
+
+func setupUpdateRolloutTestService(t *testing.T) (*UpdateRolloutService, *database.DB) {
+	t.Helper()
+	gdb, err := gorm.Open(glsqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, gdb.AutoMigrate(&models.Environment{}, &models.UpdateRollout{}, &models.AutoUpdateRecord{}, &models.ImageUpdateRecord{}))
+
+	db := &database.DB{DB: gdb}
+	require.NoError(t, gdb.Create(&models.Environment{BaseModel: models.BaseModel{ID: "0"}, Name: "Local", Enabled: true}).Error)
+	require.NoError(t, gdb.Create(&models.Environment{BaseModel: models.BaseModel{ID: "edge-1"}, Name: "Prod", Enabled: true}).Error)
+
+	svc := NewUpdateRolloutService(db, &EnvironmentService{db: db}, &UpdaterService{db: db}, nil)
+	return svc, db
+}
+
+func TestUpdateRolloutService_CreateRolloutValidatesStages(t *testing.T) {
+	svc, _ := setupUpdateRolloutTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateRollout(ctx, rollout.CreateRequest{Name: "canary", Stages: []rollout.Stage{
+		{EnvironmentIDs: []string{"0"}},
+		{EnvironmentIDs: []string{"0", "edge-1"}},
+	}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is in stages 1 and 2")
+
+	_, err = svc.CreateRollout(ctx, rollout.CreateRequest{Name: "canary", Stages: []rollout.Stage{{EnvironmentIDs: []string{"missing"}}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	r, err := svc.CreateRollout(ctx, rollout.CreateRequest{Name: " canary ", Stages: []rollout.Stage{
+		{Name: "staging", EnvironmentIDs: []string{"0"}, SoakMinutes: 1440},
+		{Name: "prod", EnvironmentIDs: []string{"edge-1"}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "canary", r.Name)
+	assert.Equal(t, models.UpdateRolloutStatusIdle, r.Status)
+
+	stored, err := svc.GetRolloutByID(ctx, r.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.Stages, 2)
+	assert.Equal(t, 1440, stored.Stages[0].SoakMinutes)
+	assert.Equal(t, []string{"edge-1"}, stored.Stages[1].EnvironmentIDs)
+}
+
+func TestUpdateRolloutService_ProcessDue(t *testing.T) {
+	ctx := context.Background()
+	stageStart := time.Now().Add(-2 * time.Hour)
+	due := time.Now().Add(-time.Minute)
+
+	newSoaking := func(t *testing.T, db *database.DB, stages models.RolloutStages) *models.UpdateRollout {
+		t.Helper()
+		r := &models.UpdateRollout{
+			Name:           "canary",
+			Stages:         stages,
+			Status:         models.UpdateRolloutStatusSoaking,
+			StageStartedAt: &stageStart,
+			NextStageAt:    &due,
+		}
+		require.NoError(t, db.Create(r).Error)
+		return r
+	}
+
+	t.Run("promotes the next stage after a healthy soak", func(t *testing.T) {
+		svc, db := setupUpdateRolloutTestService(t)
+		r := newSoaking(t, db, models.RolloutStages{
+			{Name: "staging", EnvironmentIDs: []string{"0"}, SoakMinutes: 60},
+			{Name: "prod", EnvironmentIDs: []string{"0"}, SoakMinutes: 30},
+		})
+		// Failures from before the stage started don't hold it back.
+		require.NoError(t, db.Create(&models.AutoUpdateRecord{ResourceName: "web", Status: models.AutoUpdateStatusFailed, StartTime: stageStart.Add(-time.Hour)}).Error)
+
+		require.NoError(t, svc.ProcessDue(ctx))
+
+		got, err := svc.GetRolloutByID(ctx, r.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.UpdateRolloutStatusSoaking, got.Status)
+		assert.Equal(t, 1, got.CurrentStage)
+		require.Len(t, got.Results, 1)
+		assert.Equal(t, "0", got.Results[0].EnvironmentID)
+		require.NotNil(t, got.NextStageAt)
+		assert.True(t, got.NextStageAt.After(time.Now().Add(29*time.Minute)))
+	})
+
+	t.Run("completes after the last stage", func(t *testing.T) {
+		svc, db := setupUpdateRolloutTestService(t)
+		r := newSoaking(t, db, models.RolloutStages{{EnvironmentIDs: []string{"0"}}})
+
+		require.NoError(t, svc.ProcessDue(ctx))
+
+		got, err := svc.GetRolloutByID(ctx, r.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.UpdateRolloutStatusCompleted, got.Status)
+		assert.Nil(t, got.NextStageAt)
+		assert.NotNil(t, got.FinishedAt)
+	})
+
+	t.Run("halts when an update was rolled back during the soak", func(t *testing.T) {
+		svc, db := setupUpdateRolloutTestService(t)
+		r := newSoaking(t, db, models.RolloutStages{
+			{EnvironmentIDs: []string{"0"}, SoakMinutes: 60},
+			{EnvironmentIDs: []string{"edge-1"}},
+		})
+		require.NoError(t, db.Create(&models.AutoUpdateRecord{ResourceName: "web", Status: models.AutoUpdateStatusRolledBack, StartTime: stageStart.Add(time.Hour)}).Error)
+
+		require.NoError(t, svc.ProcessDue(ctx))
+
+		got, err := svc.GetRolloutByID(ctx, r.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.UpdateRolloutStatusFailed, got.Status)
+		assert.Equal(t, 0, got.CurrentStage)
+		require.NotNil(t, got.LastError)
+		assert.Contains(t, *got.LastError, "update of web on environment Local rolled back")
+	})
+
+	t.Run("leaves cancelled rollouts alone", func(t *testing.T) {
+		svc, db := setupUpdateRolloutTestService(t)
+		r := newSoaking(t, db, models.RolloutStages{{EnvironmentIDs: []string{"0"}}})
+		_, err := svc.CancelRollout(ctx, r.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, svc.ProcessDue(ctx))
+
+		got, err := svc.GetRolloutByID(ctx, r.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.UpdateRolloutStatusCancelled, got.Status)
+	})
+}
+
+func TestUpdateRolloutService_RemoteStageOutlastingProxyTimeout(t *testing.T) {
+	ctx := context.Background()
+	svc, db := setupUpdateRolloutTestService(t)
+
+	require.NoError(t, db.AutoMigrate(&models.SettingVariable{}))
+	settingsSvc, err := NewSettingsService(ctx, db)
+	require.NoError(t, err)
+	require.NoError(t, settingsSvc.EnsureDefaultSettings(ctx))
+	require.NoError(t, settingsSvc.SetStringSetting(ctx, "proxyRequestTimeout", "1"))
+	svc.environmentService.settingsService = settingsSvc
+
+	interval := rolloutPollInterval
+	rolloutPollInterval = 50 * time.Millisecond
+	t.Cleanup(func() { rolloutPollInterval = interval })
+
+	// The agent takes longer than the proxy timeout to apply the update.
+	var mu sync.Mutex
+	var started time.Time
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/environments/0/updater/run", func(w http.ResponseWriter, r *http.Request) {
+		var opts updater.Options
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&opts))
+		assert.True(t, opts.Background)
+		mu.Lock()
+		started = time.Now()
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(base.ApiResponse[updater.Result]{Success: true, Data: updater.Result{RunID: "run-1"}})
+	})
+	mux.HandleFunc("GET /api/environments/0/updater/status", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		running := time.Since(started) < 1500*time.Millisecond
+		mu.Unlock()
+		status := updater.Status{RunID: "run-1", Running: running}
+		if !running {
+			status.LastRun = &updater.Result{RunID: "run-1", Checked: 2, Updated: 2}
+		}
+		_ = json.NewEncoder(w).Encode(base.ApiResponse[updater.Status]{Success: true, Data: status})
+	})
+	agent := httptest.NewServer(mux)
+	t.Cleanup(agent.Close)
+	require.NoError(t, db.Model(&models.Environment{}).Where("id = ?", "edge-1").Update("api_url", agent.URL).Error)
+
+	due := time.Now().Add(-time.Minute)
+	r := &models.UpdateRollout{
+		Name:        "canary",
+		Stages:      models.RolloutStages{{Name: "prod", EnvironmentIDs: []string{"edge-1"}, SoakMinutes: 30}},
+		Status:      models.UpdateRolloutStatusRunning,
+		NextStageAt: &due,
+	}
+	require.NoError(t, db.Create(r).Error)
+
+	require.NoError(t, svc.ProcessDue(ctx))
+
+	got, err := svc.GetRolloutByID(ctx, r.ID)
+	require.NoError(t, err)
+	require.Nil(t, got.LastError)
+	assert.Equal(t, models.UpdateRolloutStatusSoaking, got.Status)
+	require.Len(t, got.Results, 1)
+	assert.Equal(t, "edge-1", got.Results[0].EnvironmentID)
+	assert.Empty(t, got.Results[0].Error)
+	assert.Equal(t, 2, got.Results[0].Updated)
+}
+
+func TestUpdateRolloutService_LaterStagesApplyImagesOfFirstStage(t *testing.T) {
+	ctx := context.Background()
+	svc, db := setupUpdateRolloutTestService(t)
+	require.NoError(t, db.AutoMigrate(&models.SettingVariable{}))
+	settingsSvc, err := NewSettingsService(ctx, db)
+	require.NoError(t, err)
+	require.NoError(t, settingsSvc.EnsureDefaultSettings(ctx))
+	svc.environmentService.settingsService = settingsSvc
+
+	// Each agent applies updates within the request and reports the images it was asked for.
+	var mu sync.Mutex
+	requested := map[string][]string{}
+	newAgent := func(envID string, result updater.Result) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /api/environments/0/updater/run", func(w http.ResponseWriter, r *http.Request) {
+			var opts updater.Options
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&opts))
+			mu.Lock()
+			requested[envID] = opts.Images
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(base.ApiResponse[updater.Result]{Success: true, Data: result})
+		})
+		mux.HandleFunc("GET /api/environments/0/updater/history", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(base.ApiResponse[[]models.AutoUpdateRecord]{Success: true, Data: []models.AutoUpdateRecord{}})
+		})
+		agent := httptest.NewServer(mux)
+		t.Cleanup(agent.Close)
+		require.NoError(t, db.Model(&models.Environment{}).Where("id = ?", envID).Updates(map[string]any{"api_url": agent.URL, "status": string(models.EnvironmentStatusOnline)}).Error)
+	}
+	require.NoError(t, db.Create(&models.Environment{BaseModel: models.BaseModel{ID: "edge-2"}, Name: "Prod 2", Enabled: true}).Error)
+
+	image := func(newRef, digest string, applied bool) updater.ResourceResult {
+		return updater.ResourceResult{ResourceType: "image", UpdateApplied: applied, NewImages: map[string]string{"main": newRef}, Digest: digest}
+	}
+	newAgent("edge-1", updater.Result{Updated: 2, Items: []updater.ResourceResult{
+		image("nginx:1.27", "sha256:aaa", true),
+		image("redis:8", "sha256:bbb", true),
+		image("postgres:17", "", false),
+		{ResourceType: "container", UpdateApplied: true, NewImages: map[string]string{"main": "nginx:1.27"}},
+	}})
+	newAgent("edge-2", updater.Result{Updated: 1})
+
+	due := time.Now().Add(-time.Minute)
+	r := &models.UpdateRollout{
+		Name: "canary",
+		Stages: models.RolloutStages{
+			{Name: "canary", EnvironmentIDs: []string{"edge-1"}, SoakMinutes: 60},
+			{Name: "prod", EnvironmentIDs: []string{"edge-2"}},
+		},
+		Status:      models.UpdateRolloutStatusRunning,
+		NextStageAt: &due,
+	}
+	require.NoError(t, db.Create(r).Error)
+
+	require.NoError(t, svc.ProcessDue(ctx))
+	got, err := svc.GetRolloutByID(ctx, r.ID)
+	require.NoError(t, err)
+	require.Nil(t, got.LastError)
+	assert.Nil(t, requested["edge-1"])
+	assert.Equal(t, models.StringSlice{"nginx:1.27@sha256:aaa", "redis:8@sha256:bbb"}, got.Images)
+
+	require.NoError(t, db.Model(got).Update("next_stage_at", due).Error)
+	require.NoError(t, svc.ProcessDue(ctx))
+	got, err = svc.GetRolloutByID(ctx, r.ID)
+	require.NoError(t, err)
+	require.Nil(t, got.LastError)
+	assert.Equal(t, 1, got.CurrentStage)
+	assert.Equal(t, []string{"nginx:1.27@sha256:aaa", "redis:8@sha256:bbb"}, requested["edge-2"])
+}
+
+func TestUpdateRolloutService_NothingToPromoteWhenFirstStageAppliedNoUpdate(t *testing.T) {
+	ctx := context.Background()
+	svc, db := setupUpdateRolloutTestService(t)
+
+	called := false
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(agent.Close)
+	require.NoError(t, db.Model(&models.Environment{}).Where("id = ?", "edge-1").Update("api_url", agent.URL).Error)
+
+	stageStart := time.Now().Add(-2 * time.Hour)
+	due := time.Now().Add(-time.Minute)
+	r := &models.UpdateRollout{
+		Name: "canary",
+		Stages: models.RolloutStages{
+			{EnvironmentIDs: []string{"0"}, SoakMinutes: 60},
+			{EnvironmentIDs: []string{"edge-1"}},
+		},
+		Status:         models.UpdateRolloutStatusSoaking,
+		StageStartedAt: &stageStart,
+		NextStageAt:    &due,
+	}
+	require.NoError(t, db.Create(r).Error)
+
+	require.NoError(t, svc.ProcessDue(ctx))
+
+	got, err := svc.GetRolloutByID(ctx, r.ID)
+	require.NoError(t, err)
+	require.Nil(t, got.LastError)
+	assert.Equal(t, models.UpdateRolloutStatusSoaking, got.Status)
+	assert.False(t, called)
+	require.Len(t, got.Results, 1)
+	assert.Equal(t, 0, got.Results[0].Updated)
+}
@@ -2,22 +2,29 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/docker/compose/v5/pkg/api"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
+	"github.com/google/uuid"
 
 	"github.com/getarcaneapp/arcane/backend/internal/database"
 	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/arcaneupdater"
 	arcRegistry "github.com/getarcaneapp/arcane/backend/internal/utils/registry"
+	"github.com/getarcaneapp/arcane/backend/pkg/projects"
 	"github.com/getarcaneapp/arcane/types/updater"
 )
 
@@ -25,6 +32,7 @@ type UpdaterService struct {
 	db                  *database.DB
 	settingsService     *SettingsService
 	dockerService       *DockerClientService
+	containerService    *ContainerService
 	projectService      *ProjectService
 	imageUpdateService  *ImageUpdateService
 	registryService     *ContainerRegistryService
@@ -35,12 +43,28 @@ type UpdaterService struct {
 
 	updatingContainers map[string]bool
 	updatingProjects   map[string]bool
+
+	runMu         sync.Mutex
+	backgroundRun *backgroundUpdaterRun
+}
+
+// ErrUpdaterRunInProgress is returned when a background run is started while another one is
+// still in progress.
+var ErrUpdaterRunInProgress = errors.New("an updater run is already in progress")
+
+// backgroundUpdaterRun tracks the last run started with StartApplyPending.
+type backgroundUpdaterRun struct {
+	id      string
+	running bool
+	result  *updater.Result
+	err     error
 }
 
 func NewUpdaterService(
 	db *database.DB,
 	settings *SettingsService,
 	docker *DockerClientService,
+	containers *ContainerService,
 	projects *ProjectService,
 	imageUpdates *ImageUpdateService,
 	registries *ContainerRegistryService,
@@ -53,6 +77,7 @@ func NewUpdaterService(
 		db:                  db,
 		settingsService:     settings,
 		dockerService:       docker,
+		containerService:    containers,
 		projectService:      projects,
 		imageUpdateService:  imageUpdates,
 		registryService:     registries,
@@ -65,8 +90,11 @@ func NewUpdaterService(
 	}
 }
 
+// ApplyPending pulls the pending image updates and restarts the containers using them. When
+// images is not nil, only updates to the images in it are applied, see updater.Options.Images.
+//
 //nolint:gocognit
-func (s *UpdaterService) ApplyPending(ctx context.Context, dryRun bool) (*updater.Result, error) {
+func (s *UpdaterService) ApplyPending(ctx context.Context, dryRun bool, images []string) (*updater.Result, error) {
 	start := time.Now()
 	out := &updater.Result{Items: []updater.ResourceResult{}}
 
@@ -93,10 +121,12 @@ func (s *UpdaterService) ApplyPending(ctx context.Context, dryRun bool) (*update
 	type updatePlan struct {
 		oldRef string
 		newRef string
+		digest string   // repository digest newRef is pinned to, if any
 		oldIDs []string // sha256:... image IDs that currently back oldRef
 		pulled bool
 	}
 	var plans []updatePlan
+	allowed := s.allowedImagesInternal(images)
 
 	for _, r := range records {
 		if r.Repository == "" || r.Tag == "" {
@@ -116,8 +146,13 @@ func (s *UpdaterService) ApplyPending(ctx context.Context, dryRun bool) (*update
 			newRef = fmt.Sprintf("%s:%s", r.Repository, *r.LatestVersion)
 		}
 
+		digest, ok := allowed[s.normalizeRef(newRef)]
+		if allowed != nil && !ok {
+			continue
+		}
+
 		oldIDs, _ := s.resolveLocalImageIDsForRef(ctx, oldRef)
-		plans = append(plans, updatePlan{oldRef: oldRef, newRef: newRef, oldIDs: oldIDs})
+		plans = append(plans, updatePlan{oldRef: oldRef, newRef: newRef, digest: digest, oldIDs: oldIDs})
 	}
 
 	if len(plans) == 0 {
@@ -140,6 +175,19 @@ func (s *UpdaterService) ApplyPending(ctx context.Context, dryRun bool) (*update
 	if err != nil {
 		return nil, fmt.Errorf("docker connect: %w", err)
 	}
+
+	// Images whose update policy holds back every container using them are neither pulled nor
+	// written back into compose files.
+	planRefs := make(map[string]string, len(plans))
+	planIDs := map[string]string{}
+	for _, p := range plans {
+		oldNorm := s.normalizeRef(p.oldRef)
+		planRefs[oldNorm] = p.newRef
+		for _, id := range p.oldIDs {
+			planIDs[id] = oldNorm
+		}
+	}
+	held := s.heldImageUpdatesInternal(ctx, dcli, planRefs, planIDs)
 	registryClient := arcRegistry.NewClient()
 	digestChecker := arcaneupdater.NewDigestChecker(dcli, registryClient)
 
@@ -165,6 +213,24 @@ func (s *UpdaterService) ApplyPending(ctx context.Context, dryRun bool) (*update
 		}
 		out.Checked++
 
+		if reason := held[s.normalizeRef(p.oldRef)]; reason != "" {
+			item.Status = "skipped"
+			item.Error = reason
+			out.Skipped++
+			out.Items = append(out.Items, item)
+			_ = s.recordRun(ctx, item)
+
+			s.logAutoUpdate(ctx, s.severityFromStatus(item.Status), models.JSON{
+				"phase":    "image_pull",
+				"imageOld": p.oldRef,
+				"imageNew": p.newRef,
+				"status":   item.Status,
+				"error":    item.Error,
+				"dryRun":   dryRun,
+			})
+			continue
+		}
+
 		if dryRun {
 			item.Status = "skipped"
 			out.Skipped++
@@ -181,6 +247,45 @@ func (s *UpdaterService) ApplyPending(ctx context.Context, dryRun bool) (*update
 			continue
 		}
 
+		if p.digest != "" {
+			// Pinned images are pulled by digest, the tag may point elsewhere by now.
+			upToDate, err := s.pullPinnedImageInternal(ctx, dcli, p.newRef, p.digest, p.oldIDs)
+			switch {
+			case err != nil:
+				item.Status = "failed"
+				item.Error = err.Error()
+				out.Failed++
+			case upToDate:
+				item.Status = "skipped"
+				item.Error = "image already up to date"
+				out.Skipped++
+				plans[i].pulled = true
+				plans[i].oldIDs = nil
+			default:
+				item.Status = "updated"
+				item.UpdateApplied = true
+				item.Digest = p.digest
+				out.Updated++
+				plans[i].pulled = true
+				for _, id := range p.oldIDs {
+					if id != "" {
+						oldIDSet[id] = struct{}{}
+					}
+				}
+			}
+			s.logAutoUpdate(ctx, s.severityFromStatus(item.Status), models.JSON{
+				"phase":    "image_pull",
+				"imageOld": p.oldRef,
+				"imageNew": p.newRef,
+				"digest":   p.digest,
+				"status":   item.Status,
+				"error":    item.Error,
+			})
+			out.Items = append(out.Items, item)
+			_ = s.recordRun(ctx, item)
+			continue
+		}
+
 		// Digest pre-check: if registry supports it and digests match, avoid pulling entirely.
 		// This also prevents unnecessary restarts when the update record is stale.
 		normNew := s.normalizeRef(p.newRef)
@@ -220,6 +325,7 @@ func (s *UpdaterService) ApplyPending(ctx context.Context, dryRun bool) (*update
 			} else {
 				item.Status = "updated"
 				item.UpdateApplied = true
+				item.Digest = s.repoDigestInternal(ctx, dcli, p.newRef)
 				out.Updated++
 				plans[i].pulled = true
 				for _, id := range p.oldIDs {
@@ -256,8 +362,9 @@ func (s *UpdaterService) ApplyPending(ctx context.Context, dryRun bool) (*update
 		}
 	}
 
+	var results []updater.ResourceResult
 	if !dryRun && (len(oldIDToNewRef) > 0 || len(oldRefToNewRef) > 0) {
-		results, err := s.restartContainersUsingOldIDs(ctx, oldIDToNewRef, oldRefToNewRef)
+		results, err = s.restartContainersUsingOldIDs(ctx, oldIDToNewRef, oldRefToNewRef)
 		if err != nil {
 			slog.Warn("container restarts had errors", "err", err)
 		}
@@ -277,6 +384,10 @@ func (s *UpdaterService) ApplyPending(ctx context.Context, dryRun bool) (*update
 			switch {
 			case r.UpdateApplied:
 				out.Updated++
+			case r.Status == string(models.AutoUpdateStatusRolledBack):
+				out.RolledBack++
+			case r.Status == "skipped":
+				out.Skipped++
 			case r.Error != "":
 				out.Failed++
 			default:
@@ -296,6 +407,17 @@ func (s *UpdaterService) ApplyPending(ctx context.Context, dryRun bool) (*update
 		}
 	}
 
+	// Write new version tags back into compose files so redeploys keep the updated image. Only
+	// images every container moved to are written back, not rolled back or held ones.
+	if !dryRun && s.settingsService.GetBoolSetting(ctx, "autoUpdateBumpComposeTags", false) {
+		applied := s.fullyAppliedImageRefsInternal(results)
+		for _, p := range plans {
+			if p.pulled && p.newRef != p.oldRef && applied[s.normalizeRef(p.newRef)] {
+				s.bumpComposeImageTagsInternal(ctx, p.oldRef, p.newRef)
+			}
+		}
+	}
+
 	// Prune old images that are no longer used (only for images that were actually updated)
 	if !dryRun && len(oldIDSet) > 0 {
 		ids := make([]string, 0, len(oldIDSet))
@@ -525,14 +647,21 @@ func (s *UpdaterService) UpdateSingleContainer(ctx context.Context, containerID
 
 	// Update the container
 	if err := s.updateContainer(ctx, *targetContainer, inspect, normalizedRef); err != nil {
+		status := "failed"
+		var rolledBack *updateRolledBackError
+		if errors.As(err, &rolledBack) {
+			status = string(models.AutoUpdateStatusRolledBack)
+			out.RolledBack++
+		} else {
+			out.Failed++
+		}
 		out.Items = append(out.Items, updater.ResourceResult{
 			ResourceID:   targetContainer.ID,
 			ResourceType: "container",
 			ResourceName: containerName,
-			Status:       "failed",
+			Status:       status,
 			Error:        err.Error(),
 		})
-		out.Failed++
 	} else {
 		out.Items = append(out.Items, updater.ResourceResult{
 			ResourceID:   targetContainer.ID,
@@ -602,6 +731,34 @@ func (s *UpdaterService) pruneImageIDs(ctx context.Context, ids []string) error
 	return nil
 }
 
+// StartApplyPending runs ApplyPending in the background and returns the ID of the run. Its
+// result is reported by GetStatus once it finishes, so callers behind a proxy with a request
+// timeout can poll for it instead of waiting on health checks that may take much longer.
+func (s *UpdaterService) StartApplyPending(ctx context.Context, dryRun bool, images []string) (string, error) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if s.backgroundRun != nil && s.backgroundRun.running {
+		return "", ErrUpdaterRunInProgress
+	}
+
+	run := &backgroundUpdaterRun{id: uuid.NewString(), running: true}
+	s.backgroundRun = run
+	go func() {
+		result, err := s.ApplyPending(context.WithoutCancel(ctx), dryRun, images)
+		if err != nil {
+			slog.ErrorContext(ctx, "Background updater run failed", "runId", run.id, "error", err)
+		}
+
+		s.runMu.Lock()
+		defer s.runMu.Unlock()
+		run.running = false
+		run.result = result
+		run.err = err
+	}()
+
+	return run.id, nil
+}
+
 func (s *UpdaterService) GetStatus() updater.Status {
 	containerIDs := make([]string, 0, len(s.updatingContainers))
 	for id := range s.updatingContainers {
@@ -612,12 +769,28 @@ func (s *UpdaterService) GetStatus() updater.Status {
 		projectIDs = append(projectIDs, id)
 	}
 
-	return updater.Status{
+	status := updater.Status{
 		UpdatingContainers: len(s.updatingContainers),
 		UpdatingProjects:   len(s.updatingProjects),
 		ContainerIds:       containerIDs,
 		ProjectIds:         projectIDs,
 	}
+
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if run := s.backgroundRun; run != nil {
+		status.Running = run.running
+		status.RunID = run.id
+		if run.result != nil {
+			result := *run.result
+			result.RunID = run.id
+			status.LastRun = &result
+		}
+		if run.err != nil {
+			status.LastRunError = run.err.Error()
+		}
+	}
+	return status
 }
 
 func (s *UpdaterService) GetHistory(ctx context.Context, limit int) ([]models.AutoUpdateRecord, error) {
@@ -634,7 +807,6 @@ func (s *UpdaterService) GetHistory(ctx context.Context, limit int) ([]models.Au
 
 // --- internals ---
 
-//nolint:gocognit
 func (s *UpdaterService) updateContainer(ctx context.Context, cnt container.Summary, inspect container.InspectResponse, newRef string) error {
 	dcli, err := s.dockerService.GetClient()
 	if err != nil {
@@ -642,8 +814,7 @@ func (s *UpdaterService) updateContainer(ctx context.Context, cnt container.Summ
 	}
 
 	name := s.getContainerName(cnt)
-	labels := inspect.Config.Labels
-	isArcane := arcaneupdater.IsArcaneContainer(labels)
+	isArcane := arcaneupdater.IsArcaneContainer(inspect.Config.Labels)
 
 	// Arcane containers should always use CLI upgrade, not inline update
 	// This method should not be called for Arcane containers
@@ -654,10 +825,39 @@ func (s *UpdaterService) updateContainer(ctx context.Context, cnt container.Summ
 
 	slog.DebugContext(ctx, "updateContainer: starting update", "containerId", cnt.ID, "containerName", name, "newRef", newRef, "isArcane", isArcane)
 
-	originalName := inspect.Name
+	labels := inspect.Config.Labels
+	if err := s.runHookInternal(ctx, cnt.ID, name, labels, arcaneupdater.LabelPreUpdate); err != nil {
+		return err
+	}
+	if err := s.stopContainerForUpdateInternal(ctx, dcli, cnt, inspect); err != nil {
+		return err
+	}
+	recreated, err := s.recreateContainerInternal(ctx, dcli, cnt, inspect, newRef)
+	if err != nil {
+		return err
+	}
+	_ = s.runHookInternal(ctx, recreated.id, name, labels, arcaneupdater.LabelPostUpdate)
+	return nil
+}
+
+// recreatedContainer is a container the updater recreated, with what it takes to put the
+// previous image back.
+type recreatedContainer struct {
+	id               string
+	name             string
+	previousImage    string
+	previousRef      string
+	cfg              *container.Config
+	hostConfig       *container.HostConfig
+	networkingConfig *network.NetworkingConfig
+}
+
+// stopContainerForUpdateInternal stops and removes a container that is about to be recreated.
+func (s *UpdaterService) stopContainerForUpdateInternal(ctx context.Context, dcli *client.Client, cnt container.Summary, inspect container.InspectResponse) error {
+	name := s.getContainerName(cnt)
 
 	// Get custom stop signal if configured
-	stopSignal := arcaneupdater.GetStopSignal(labels)
+	stopSignal := arcaneupdater.GetStopSignal(inspect.Config.Labels)
 	stopOpts := container.StopOptions{}
 	if stopSignal != "" {
 		stopOpts.Signal = stopSignal
@@ -678,6 +878,24 @@ func (s *UpdaterService) updateContainer(ctx context.Context, cnt container.Summ
 	}
 	_ = s.eventService.LogContainerEvent(ctx, models.EventTypeContainerDelete, cnt.ID, name, systemUser.ID, systemUser.Username, "0", models.JSON{"action": "updater_delete"})
 
+	return nil
+}
+
+// recreateContainerInternal creates and starts a container with the configuration of the removed
+// one and the image newRef. When the new container fails to start or become healthy it is
+// replaced by one running the previous image and an updateRolledBackError is returned.
+func (s *UpdaterService) recreateContainerInternal(ctx context.Context, dcli *client.Client, cnt container.Summary, inspect container.InspectResponse, newRef string) (*recreatedContainer, error) {
+	name := s.getContainerName(cnt)
+	originalName := inspect.Name
+
+	// Keep the image the container ran, and the reference it was created from, so a failed update
+	// can be rolled back.
+	previousImage := inspect.Image
+	previousRef := ""
+	if inspect.Config != nil {
+		previousRef = inspect.Config.Image
+	}
+
 	// recreate with new image ref
 	cfg := inspect.Config
 	cfg.Image = newRef
@@ -709,15 +927,41 @@ func (s *UpdaterService) updateContainer(ctx context.Context, cnt container.Summ
 	resp, err := dcli.ContainerCreate(ctx, cfg, inspect.HostConfig, networkingConfig, nil, containerName)
 	if err != nil {
 		slog.DebugContext(ctx, "updateContainer: create failed", "containerName", containerName, "err", err)
-		return fmt.Errorf("create: %w", err)
+		return nil, fmt.Errorf("create: %w", err)
 	}
 	_ = s.eventService.LogContainerEvent(ctx, models.EventTypeContainerCreate, resp.ID, name, systemUser.ID, systemUser.Username, "0", models.JSON{"action": "updater_create", "newImageId": resp.ID})
 
-	if err := dcli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
-		slog.DebugContext(ctx, "updateContainer: start failed", "newContainerId", resp.ID, "err", err)
-		return fmt.Errorf("start: %w", err)
+	healthErr := dcli.ContainerStart(ctx, resp.ID, container.StartOptions{})
+	if healthErr != nil {
+		slog.DebugContext(ctx, "updateContainer: start failed", "newContainerId", resp.ID, "err", healthErr)
+		healthErr = fmt.Errorf("start: %w", healthErr)
+	} else {
+		_ = s.eventService.LogContainerEvent(ctx, models.EventTypeContainerStart, resp.ID, name, systemUser.ID, systemUser.Username, "0", models.JSON{"action": "updater_start"})
+		healthErr = s.waitForUpdatedContainerInternal(ctx, dcli, resp.ID)
+	}
+
+	if healthErr != nil {
+		if previousImage == "" {
+			return nil, healthErr
+		}
+		slog.WarnContext(ctx, "updateContainer: updated container failed, rolling back", "containerName", containerName, "newContainerId", resp.ID, "previousImage", previousImage, "err", healthErr)
+
+		rollbackCfg := *cfg
+		rollbackCfg.Image = s.rollbackImageRefInternal(ctx, dcli, previousRef, previousImage)
+		rollbackID, err := s.rollbackContainerInternal(ctx, dcli, resp.ID, containerName, &rollbackCfg, inspect.HostConfig, networkingConfig)
+		if err != nil {
+			return nil, fmt.Errorf("%w; rollback failed: %w", healthErr, err)
+		}
+		_ = s.eventService.LogContainerEvent(ctx, models.EventTypeContainerUpdate, rollbackID, name, systemUser.ID, systemUser.Username, "0", models.JSON{
+			"action":         "updater_rollback",
+			"oldContainerId": resp.ID,
+			"newContainerId": rollbackID,
+			"failedImage":    newRef,
+			"previousImage":  previousImage,
+			"error":          healthErr.Error(),
+		})
+		return nil, &updateRolledBackError{cause: healthErr}
 	}
-	_ = s.eventService.LogContainerEvent(ctx, models.EventTypeContainerStart, resp.ID, name, systemUser.ID, systemUser.Username, "0", models.JSON{"action": "updater_start"})
 
 	_ = s.eventService.LogContainerEvent(ctx, models.EventTypeContainerUpdate, resp.ID, name, systemUser.ID, systemUser.Username, "0", models.JSON{
 		"oldContainerId": cnt.ID,
@@ -726,7 +970,265 @@ func (s *UpdaterService) updateContainer(ctx context.Context, cnt container.Summ
 	})
 
 	slog.DebugContext(ctx, "updateContainer: update complete", "oldContainerId", cnt.ID, "newContainerId", resp.ID)
-	return nil
+	return &recreatedContainer{
+		id:               resp.ID,
+		name:             containerName,
+		previousImage:    previousImage,
+		previousRef:      previousRef,
+		cfg:              cfg,
+		hostConfig:       inspect.HostConfig,
+		networkingConfig: networkingConfig,
+	}, nil
+}
+
+// heldImageUpdatesInternal evaluates the update policies of the running containers using the
+// planned images before anything is pulled. planRefs maps the normalized old references to
+// their new ones and planIDs the local image IDs backing them to the old references. The
+// result maps every old reference whose containers all hold the update back to the reason.
+// The age of the new image is not known yet, a minimum age is checked again after the pull.
+func (s *UpdaterService) heldImageUpdatesInternal(ctx context.Context, dcli *client.Client, planRefs map[string]string, planIDs map[string]string) map[string]string {
+	held := map[string]string{}
+	list, err := dcli.ContainerList(ctx, container.ListOptions{All: false})
+	if err != nil {
+		slog.WarnContext(ctx, "heldImageUpdates: list containers failed", "err", err)
+		return held
+	}
+
+	excluded := s.excludedContainersInternal(ctx)
+	now := time.Now()
+	projectPolicies := map[string]map[string]string{}
+	newImageCreated := map[string]time.Time{}
+	for _, newRef := range planRefs {
+		newImageCreated[newRef] = time.Time{}
+	}
+
+	applied := map[string]bool{}
+	for _, c := range list {
+		if slices.ContainsFunc(c.Names, func(name string) bool { return excluded[strings.TrimPrefix(name, "/")] }) {
+			continue
+		}
+		inspect, err := dcli.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			continue
+		}
+		labels := c.Labels
+		if inspect.Config != nil && inspect.Config.Labels != nil {
+			labels = inspect.Config.Labels
+		}
+		if arcaneupdater.IsUpdateDisabled(labels) {
+			continue
+		}
+
+		oldNorm, ok := planIDs[inspect.Image]
+		if !ok {
+			for _, t := range s.getNormalizedTagsForContainer(ctx, dcli, inspect) {
+				if _, ok = planRefs[t]; ok {
+					oldNorm = t
+					break
+				}
+			}
+		}
+		if oldNorm == "" || applied[oldNorm] {
+			continue
+		}
+
+		currentRef := c.Image
+		if inspect.Config != nil && inspect.Config.Image != "" {
+			currentRef = inspect.Config.Image
+		}
+		reason := s.updatePolicySkipReasonInternal(ctx, dcli, labels, currentRef, planRefs[oldNorm], now, projectPolicies, newImageCreated)
+		if reason == "" {
+			applied[oldNorm] = true
+			delete(held, oldNorm)
+			continue
+		}
+		if _, ok := held[oldNorm]; !ok {
+			held[oldNorm] = reason
+		}
+	}
+	return held
+}
+
+// excludedContainersInternal returns the container names listed in autoUpdateExcludedContainers.
+func (s *UpdaterService) excludedContainersInternal(ctx context.Context) map[string]bool {
+	excluded := map[string]bool{}
+	for name := range strings.SplitSeq(s.settingsService.GetStringSetting(ctx, "autoUpdateExcludedContainers", ""), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			excluded[name] = true
+		}
+	}
+	return excluded
+}
+
+// updatePolicySkipReasonInternal evaluates the update policy of a container, set through its
+// labels and its compose project's x-arcane.updater block, for an update from currentRef, the
+// image reference the container was created with, to newRef. It returns why the update must be
+// held back, or "" to apply it.
+func (s *UpdaterService) updatePolicySkipReasonInternal(ctx context.Context, dcli *client.Client, labels map[string]string, currentRef, newRef string, now time.Time, projectPolicies map[string]map[string]string, newImageCreated map[string]time.Time) string {
+	var projectPolicy map[string]string
+	if files := labels[api.ConfigFilesLabel]; files != "" {
+		composeFile, _, _ := strings.Cut(files, ",")
+		p, ok := projectPolicies[composeFile]
+		if !ok {
+			if meta, err := projects.ParseArcaneComposeMetadata(ctx, composeFile); err == nil {
+				p = meta.UpdatePolicy
+			}
+			projectPolicies[composeFile] = p
+		}
+		projectPolicy = p
+	}
+
+	policy, err := arcaneupdater.ParseUpdatePolicy(projectPolicy, labels)
+	if err != nil {
+		return fmt.Sprintf("invalid update policy: %v", err)
+	}
+
+	created, ok := newImageCreated[newRef]
+	if !ok && policy.MinAge > 0 {
+		if ii, err := dcli.ImageInspect(ctx, newRef); err == nil {
+			created, _ = time.Parse(time.RFC3339Nano, ii.Created)
+		}
+		newImageCreated[newRef] = created
+	}
+
+	tagUpdate := currentRef != "" && s.normalizeRef(currentRef) != s.normalizeRef(newRef)
+	return policy.SkipReason(now, tagUpdate, created)
+}
+
+// fullyAppliedImageRefsInternal returns the normalized image references that every container
+// moving to them was updated to. A reference with a container that was held back, rolled back
+// or failed is left out.
+func (s *UpdaterService) fullyAppliedImageRefsInternal(results []updater.ResourceResult) map[string]bool {
+	applied := map[string]bool{}
+	for _, r := range results {
+		ref := r.NewImages["main"]
+		if ref == "" {
+			continue
+		}
+		if ok, seen := applied[ref]; seen && !ok {
+			continue
+		}
+		applied[ref] = r.UpdateApplied
+	}
+	return applied
+}
+
+// bumpComposeImageTagsInternal rewrites the compose files of projects that pin oldRef to use
+// the tag of newRef. Projects managed by GitOps are skipped, their compose file comes from git.
+func (s *UpdaterService) bumpComposeImageTagsInternal(ctx context.Context, oldRef, newRef string) {
+	if s.projectService == nil {
+		return
+	}
+	repo, oldTag := s.parseRepoAndTag(oldRef)
+	_, newTag := s.parseRepoAndTag(newRef)
+
+	projs, err := s.projectService.ListAllProjects(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "bumpComposeImageTags: list projects failed", "err", err)
+		return
+	}
+
+	for _, proj := range projs {
+		if proj.GitOpsManagedBy != nil && *proj.GitOpsManagedBy != "" {
+			continue
+		}
+		composeFile, err := projects.DetectComposeFile(proj.Path)
+		if err != nil || composeFile == "" {
+			continue
+		}
+		content, err := os.ReadFile(composeFile)
+		if err != nil {
+			continue
+		}
+
+		updated, changed := projects.BumpImageTag(string(content), repo, oldTag, newTag)
+		if changed == 0 {
+			continue
+		}
+		if _, err := s.projectService.UpdateProject(ctx, proj.ID, nil, &updated, nil); err != nil {
+			slog.WarnContext(ctx, "bumpComposeImageTags: update compose file failed", "projectId", proj.ID, "err", err)
+			continue
+		}
+
+		s.logAutoUpdate(ctx, models.EventSeverityInfo, models.JSON{
+			"phase":       "project",
+			"projectId":   proj.ID,
+			"projectName": proj.Name,
+			"imageOld":    oldRef,
+			"imageNew":    newRef,
+			"images":      changed,
+			"status":      "compose_updated",
+		})
+	}
+}
+
+// updateRolledBackError reports that an updated container failed to start or become healthy
+// and was recreated from its previous image.
+type updateRolledBackError struct {
+	cause error
+}
+
+func (e *updateRolledBackError) Error() string {
+	return fmt.Sprintf("rolled back to previous image: %v", e.cause)
+}
+
+func (e *updateRolledBackError) Unwrap() error {
+	return e.cause
+}
+
+// waitForUpdatedContainerInternal waits for a recreated container to pass its healthcheck, or
+// to keep running for autoUpdateMinUptime seconds when it has none. A health timeout of 0
+// disables the check.
+func (s *UpdaterService) waitForUpdatedContainerInternal(ctx context.Context, dcli *client.Client, containerID string) error {
+	timeout := s.settingsService.GetIntSetting(ctx, "autoUpdateHealthTimeout", 120)
+	if timeout <= 0 {
+		return nil
+	}
+	minUptime := s.settingsService.GetIntSetting(ctx, "autoUpdateMinUptime", 10)
+
+	slog.DebugContext(ctx, "updateContainer: waiting for container to become healthy", "containerId", containerID, "timeout", timeout, "minUptime", minUptime)
+	return arcaneupdater.WaitForStartup(ctx, dcli, containerID, time.Duration(timeout)*time.Second, time.Duration(minUptime)*time.Second)
+}
+
+// rollbackImageRefInternal returns the image reference a rolled back container is created from.
+// The tag it was created from is pointed back to previousImage first, so the container keeps
+// its reference instead of a bare image ID. The ID is used when the tag can't be restored.
+func (s *UpdaterService) rollbackImageRefInternal(ctx context.Context, dcli *client.Client, previousRef, previousImage string) string {
+	if previousRef == "" || previousRef == previousImage || strings.HasPrefix(previousRef, "sha256:") {
+		return previousImage
+	}
+	if strings.Contains(previousRef, "@") {
+		// A digest reference still names the previous image.
+		return previousRef
+	}
+	if err := dcli.ImageTag(ctx, previousImage, previousRef); err != nil {
+		slog.WarnContext(ctx, "updateContainer: failed to restore image tag for rollback", "image", previousRef, "previousImage", previousImage, "err", err)
+		return previousImage
+	}
+	return previousRef
+}
+
+// rollbackContainerInternal replaces the failed container with one created from cfg, which
+// points at the previous image, and returns the new container's ID.
+func (s *UpdaterService) rollbackContainerInternal(ctx context.Context, dcli *client.Client, failedID, containerName string, cfg *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig) (string, error) {
+	_ = dcli.ContainerStop(ctx, failedID, container.StopOptions{})
+	if err := dcli.ContainerRemove(ctx, failedID, container.RemoveOptions{Force: true}); err != nil {
+		return "", fmt.Errorf("remove failed container: %w", err)
+	}
+	_ = s.eventService.LogContainerEvent(ctx, models.EventTypeContainerDelete, failedID, containerName, systemUser.ID, systemUser.Username, "0", models.JSON{"action": "updater_rollback_delete"})
+
+	resp, err := dcli.ContainerCreate(ctx, cfg, hostConfig, networkingConfig, nil, containerName)
+	if err != nil {
+		return "", fmt.Errorf("create: %w", err)
+	}
+	_ = s.eventService.LogContainerEvent(ctx, models.EventTypeContainerCreate, resp.ID, containerName, systemUser.ID, systemUser.Username, "0", models.JSON{"action": "updater_rollback_create", "image": cfg.Image})
+
+	if err := dcli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return resp.ID, fmt.Errorf("start: %w", err)
+	}
+	_ = s.eventService.LogContainerEvent(ctx, models.EventTypeContainerStart, resp.ID, containerName, systemUser.ID, systemUser.Username, "0", models.JSON{"action": "updater_rollback_start"})
+
+	return resp.ID, nil
 }
 
 // normalizeRef returns a canonical "registry/repository:tag" without digest.
@@ -765,6 +1267,60 @@ func (s *UpdaterService) normalizeRef(ref string) string {
 	return strings.ToLower(domain + "/" + repo + ":" + tag)
 }
 
+// allowedImagesInternal maps the normalized references of an image allow-list to the digest
+// each one is pinned to, or returns nil when there is no allow-list.
+func (s *UpdaterService) allowedImagesInternal(images []string) map[string]string {
+	if images == nil {
+		return nil
+	}
+	allowed := make(map[string]string, len(images))
+	for _, img := range images {
+		ref, digest, _ := strings.Cut(img, "@")
+		allowed[s.normalizeRef(ref)] = digest
+	}
+	return allowed
+}
+
+// pullPinnedImageInternal pulls ref by digest and tags the pulled image as ref. It reports
+// whether ref was already backed by that image, in which case there is nothing to restart.
+func (s *UpdaterService) pullPinnedImageInternal(ctx context.Context, dcli *client.Client, ref, digest string, oldIDs []string) (bool, error) {
+	repo, _ := s.parseRepoAndTag(ref)
+	pinned := repo + "@" + digest
+	if err := s.imageService.PullImage(ctx, pinned, io.Discard, systemUser, nil); err != nil {
+		return false, err
+	}
+	inspect, err := dcli.ImageInspect(ctx, pinned)
+	if err != nil {
+		return false, fmt.Errorf("inspect %s: %w", pinned, err)
+	}
+	if slices.Contains(oldIDs, inspect.ID) {
+		return true, nil
+	}
+	if err := dcli.ImageTag(ctx, pinned, ref); err != nil {
+		return false, fmt.Errorf("tag %s as %s: %w", pinned, ref, err)
+	}
+	return false, nil
+}
+
+// repoDigestInternal returns the repository digest of the local image ref, if Docker knows it.
+func (s *UpdaterService) repoDigestInternal(ctx context.Context, dcli *client.Client, ref string) string {
+	inspect, err := dcli.ImageInspect(ctx, ref)
+	if err != nil {
+		return ""
+	}
+	repo, _ := s.parseRepoAndTag(s.normalizeRef(ref))
+	for _, repoDigest := range inspect.RepoDigests {
+		name, digest, ok := strings.Cut(repoDigest, "@")
+		if !ok {
+			continue
+		}
+		if r, _ := s.parseRepoAndTag(s.normalizeRef(name)); r == repo {
+			return digest
+		}
+	}
+	return ""
+}
+
 func (s *UpdaterService) stripDigest(ref string) string {
 	if i := strings.Index(ref, "@"); i != -1 {
 		return ref[:i]
@@ -965,15 +1521,7 @@ func (s *UpdaterService) restartContainersUsingOldIDs(ctx context.Context, oldID
 	}
 	slog.DebugContext(ctx, "restartContainersUsingOldIDs: scanning containers for matching images", "containers", len(list), "oldIDMatches", len(oldIDToNewRef), "oldRefMatches", len(oldRefToNewRef))
 
-	// Parse excluded containers settings
-	excludedSetting := s.settingsService.GetStringSetting(ctx, "autoUpdateExcludedContainers", "")
-	excludedContainers := make(map[string]bool)
-	if excludedSetting != "" {
-		parts := strings.Split(excludedSetting, ",")
-		for _, p := range parts {
-			excludedContainers[strings.TrimSpace(p)] = true
-		}
-	}
+	excludedContainers := s.excludedContainersInternal(ctx)
 
 	updatedNorm := map[string]string{}
 	for oldRef, nr := range oldRefToNewRef {
@@ -996,6 +1544,12 @@ func (s *UpdaterService) restartContainersUsingOldIDs(ctx context.Context, oldID
 	// Cache resolved IDs for newRefs to avoid repeated API calls
 	targetImageIDs := map[string][]string{}
 
+	// Per-resource update policies, with project policies cached by compose file
+	now := time.Now()
+	projectPolicies := map[string]map[string]string{}
+	newImageCreated := map[string]time.Time{}
+	var heldBack []updater.ResourceResult
+
 	for _, c := range list {
 		// Check exclusions first by container name(s)
 		isExcluded := false
@@ -1073,6 +1627,26 @@ func (s *UpdaterService) restartContainersUsingOldIDs(ctx context.Context, oldID
 			}
 		}
 
+		if newRef != "" {
+			currentRef := c.Image
+			if inspect.Config != nil && inspect.Config.Image != "" {
+				currentRef = inspect.Config.Image
+			}
+			if reason := s.updatePolicySkipReasonInternal(ctx, dcli, labels, currentRef, newRef, now, projectPolicies, newImageCreated); reason != "" {
+				slog.InfoContext(ctx, "restartContainersUsingOldIDs: update held back by policy", "containerId", c.ID, "containerName", dep.Name, "newRef", newRef, "reason", reason)
+				heldBack = append(heldBack, updater.ResourceResult{
+					ResourceID:   c.ID,
+					ResourceName: dep.Name,
+					ResourceType: "container",
+					Status:       "skipped",
+					Error:        reason,
+					OldImages:    map[string]string{"main": match},
+					NewImages:    map[string]string{"main": s.normalizeRef(newRef)},
+				})
+				newRef = ""
+			}
+		}
+
 		p := &restartPlan{cnt: c, inspect: inspect, newRef: newRef, match: match, explicit: newRef != ""}
 		plansByName[dep.Name] = p
 		if p.explicit {
@@ -1080,26 +1654,28 @@ func (s *UpdaterService) restartContainersUsingOldIDs(ctx context.Context, oldID
 		}
 	}
 
-	// Propagate implicit restarts: if a dependency is restarting, restart dependents too.
+	// Resolve compose depends_on services to container names, then propagate implicit restarts:
+	// dependents of a restarting container restart too, and so does the rest of its project.
+	arcaneupdater.ResolveDependencies(containersWithDeps)
+	markImplicit := func(names []string, match string) {
+		for _, name := range names {
+			p, ok := plansByName[name]
+			if !ok || p.newRef != "" {
+				continue
+			}
+			p.newRef = s.currentImageRefInternal(ctx, p.cnt, p.inspect, targetImageIDs)
+			p.match = match
+			p.implicit = true
+		}
+	}
 	for {
-		added := arcaneupdater.UpdateImplicitRestart(containersWithDeps, markedForRestart)
-		if len(added) == 0 {
+		byDeps := arcaneupdater.UpdateImplicitRestart(containersWithDeps, markedForRestart)
+		markImplicit(byDeps, "dependency_restart")
+		byProject := arcaneupdater.ExpandProjectRestarts(containersWithDeps, markedForRestart)
+		markImplicit(byProject, "project_restart")
+		if len(byDeps) == 0 && len(byProject) == 0 {
 			break
 		}
-		for _, name := range added {
-			if p, ok := plansByName[name]; ok {
-				if p.newRef == "" {
-					if p.inspect.Config != nil {
-						p.newRef = strings.TrimSpace(p.inspect.Config.Image)
-					}
-					if p.newRef == "" {
-						p.newRef = p.cnt.Image
-					}
-					p.match = "dependency_restart"
-					p.implicit = true
-				}
-			}
-		}
 	}
 
 	// Build the set of containers that will be restarted and sort them by dependency order.
@@ -1110,15 +1686,110 @@ func (s *UpdaterService) restartContainersUsingOldIDs(ctx context.Context, oldID
 		}
 	}
 
-	sorter := arcaneupdater.NewContainerSorter(candidates)
-	sorted, sortErr := sorter.Sort()
-	_, _ = sorter.SortReverse() // keep method used; reverse order may be useful for future stop-first flows
+	sorted, sortErr := arcaneupdater.NewContainerSorter(candidates).Sort()
 	if sortErr != nil {
 		slog.WarnContext(ctx, "restartContainersUsingOldIDs: dependency sort failed, falling back to unsorted order", "error", sortErr.Error())
 		sorted = candidates
 	}
 
-	var results []updater.ResourceResult
+	stopOrder := slices.Clone(sorted)
+	slices.Reverse(stopOrder)
+	recreate := func(cd arcaneupdater.ContainerWithDeps) *restartPlan {
+		p := plansByName[cd.Name]
+		if p == nil || p.newRef == "" || p.inspect.Config == nil || arcaneupdater.IsArcaneContainer(p.inspect.Config.Labels) {
+			return nil
+		}
+		return p
+	}
+
+	// Run the pre-update hooks before anything is stopped. A failing hook aborts the update of
+	// its container, or of its whole project.
+	hookErrs := map[string]error{}
+	abortedProjects := map[string]string{}
+	for _, cd := range stopOrder {
+		p := recreate(cd)
+		if p == nil || abortedProjects[cd.Project] != "" && cd.Project != "" {
+			continue
+		}
+		if err := s.runHookInternal(ctx, p.cnt.ID, cd.Name, p.inspect.Config.Labels, arcaneupdater.LabelPreUpdate); err != nil {
+			hookErrs[cd.Name] = err
+			if cd.Project != "" {
+				abortedProjects[cd.Project] = fmt.Sprintf("%s: %v", cd.Name, err)
+			}
+		}
+	}
+
+	// Containers are stopped right before they are recreated, so the rest keeps running until its
+	// turn. Dependents that will be recreated are stopped first, since their dependency goes away.
+	dependents := map[string][]string{}
+	for _, cd := range sorted {
+		for _, dep := range slices.Concat(cd.Links, cd.DependsOn, cd.NetworkDeps) {
+			if dep != cd.Name && !slices.Contains(dependents[dep], cd.Name) {
+				dependents[dep] = append(dependents[dep], cd.Name)
+			}
+		}
+	}
+	candidatesByName := make(map[string]arcaneupdater.ContainerWithDeps, len(sorted))
+	for _, cd := range sorted {
+		candidatesByName[cd.Name] = cd
+	}
+	stopped := map[string]bool{}
+	stopErrs := map[string]error{}
+	var stopWithDependents func(name string)
+	stopWithDependents = func(name string) {
+		cd, ok := candidatesByName[name]
+		if !ok || stopped[name] {
+			return
+		}
+		p := recreate(cd)
+		if p == nil || hookErrs[name] != nil || (cd.Project != "" && abortedProjects[cd.Project] != "") {
+			return
+		}
+		// Marked before recursing so that dependency cycles terminate.
+		stopped[name] = true
+		for _, dependent := range dependents[name] {
+			stopWithDependents(dependent)
+		}
+		if err := s.stopContainerForUpdateInternal(ctx, dcli, p.cnt, p.inspect); err != nil {
+			stopErrs[name] = err
+		}
+	}
+
+	// A compose project is updated as a whole: once one of its containers fails, the members
+	// already updated go back to their previous image and the rest keep theirs.
+	type projectMember struct {
+		result    int
+		recreated *recreatedContainer
+	}
+	failedProjects := map[string]string{}
+	updatedMembers := map[string][]projectMember{}
+	newIDs := map[string]string{}
+	failProject := func(project, reason string, results []updater.ResourceResult) {
+		if project == "" {
+			return
+		}
+		if _, ok := failedProjects[project]; ok {
+			return
+		}
+		failedProjects[project] = reason
+		for _, m := range updatedMembers[project] {
+			res := &results[m.result]
+			rc := m.recreated
+			rollbackCfg := *rc.cfg
+			rollbackCfg.Image = s.rollbackImageRefInternal(ctx, dcli, rc.previousRef, rc.previousImage)
+			if _, err := s.rollbackContainerInternal(ctx, dcli, rc.id, rc.name, &rollbackCfg, rc.hostConfig, rc.networkingConfig); err != nil {
+				res.Status = "failed"
+				res.Error = fmt.Sprintf("project update failed (%s) and rollback failed: %v", reason, err)
+				continue
+			}
+			res.Status = string(models.AutoUpdateStatusRolledBack)
+			res.UpdateApplied = false
+			res.Error = "project update rolled back: " + reason
+		}
+		delete(updatedMembers, project)
+	}
+
+	results := heldBack
 	for _, cd := range sorted {
 		p := plansByName[cd.Name]
 		if p == nil {
@@ -1150,10 +1821,13 @@ func (s *UpdaterService) restartContainersUsingOldIDs(ctx context.Context, oldID
 		slog.DebugContext(ctx, "restartContainersUsingOldIDs: restarting container", "containerId", p.cnt.ID, "container", name, "match", p.match, "newRef", p.newRef, "implicit", p.implicit)
 
 		// Check if this is Arcane self-update - use CLI upgrade instead
-		if arcaneupdater.IsArcaneContainer(labels) && s.upgradeService != nil {
+		if arcaneupdater.IsArcaneContainer(labels) {
 			slog.InfoContext(ctx, "restartContainersUsingOldIDs: detected Arcane self-update, using CLI upgrade method", "containerId", p.cnt.ID, "container", name)
 
-			if err := s.upgradeService.TriggerUpgradeViaCLI(ctx, systemUser); err != nil {
+			if s.upgradeService == nil {
+				res.Status = "failed"
+				res.Error = "arcane containers must use CLI upgrade method (TriggerUpgradeViaCLI), not inline update"
+			} else if err := s.upgradeService.TriggerUpgradeViaCLI(ctx, systemUser); err != nil {
 				res.Status = "failed"
 				res.Error = fmt.Sprintf("CLI upgrade failed: %v", err)
 				slog.WarnContext(ctx, "restartContainersUsingOldIDs: CLI upgrade failed", "containerId", p.cnt.ID, "err", err)
@@ -1163,29 +1837,194 @@ func (s *UpdaterService) restartContainersUsingOldIDs(ctx context.Context, oldID
 				res.UpdateApplied = true
 				slog.InfoContext(ctx, "restartContainersUsingOldIDs: CLI upgrade triggered successfully", "containerId", p.cnt.ID)
 			}
-		} else if err := s.updateContainer(ctx, p.cnt, p.inspect, p.newRef); err != nil {
+			results = append(results, res)
+			continue
+		}
+
+		if err := hookErrs[name]; err != nil {
 			res.Status = "failed"
 			res.Error = err.Error()
-			slog.DebugContext(ctx, "restartContainersUsingOldIDs: update failed", "containerId", p.cnt.ID, "err", err)
-		} else {
-			res.Status = "updated"
-			res.UpdateAvailable = true
-			res.UpdateApplied = true
-			slog.DebugContext(ctx, "restartContainersUsingOldIDs: update succeeded", "containerId", p.cnt.ID)
-
-			// Send notification after successful container update
-			if s.notificationService != nil {
-				if notifErr := s.notificationService.SendContainerUpdateNotification(ctx, name, p.newRef, p.match, s.normalizeRef(p.newRef)); notifErr != nil {
-					slog.WarnContext(ctx, "Failed to send container update notification", "containerId", p.cnt.ID, "containerName", name, "imageRef", p.newRef, "error", notifErr.Error())
-				}
+			results = append(results, res)
+			continue
+		}
+		if reason := abortedProjects[cd.Project]; cd.Project != "" && reason != "" {
+			res.Status = "skipped"
+			res.Error = "project update aborted: " + reason
+			results = append(results, res)
+			continue
+		}
+
+		if reason, failed := failedProjects[cd.Project]; failed && !p.implicit && !stopped[name] {
+			// Still running its previous image, leave it alone.
+			res.Status = "skipped"
+			res.Error = "project update rolled back: " + reason
+			results = append(results, res)
+			continue
+		}
+
+		stopWithDependents(name)
+		if err := stopErrs[name]; err != nil {
+			res.Status = "failed"
+			res.Error = err.Error()
+			results = append(results, res)
+			failProject(cd.Project, fmt.Sprintf("%s: %v", name, err), results)
+			continue
+		}
+
+		if reason, failed := failedProjects[cd.Project]; failed && !p.implicit {
+			// Bring the container back on the image it ran before the update.
+			res.Status = "skipped"
+			res.Error = "project update rolled back: " + reason
+			previousRef := s.currentImageRefInternal(ctx, p.cnt, p.inspect, targetImageIDs)
+			if _, err := s.recreateContainerInternal(ctx, dcli, p.cnt, p.inspect, previousRef); err != nil {
+				res.Status = "failed"
+				res.Error = fmt.Sprintf("project update rolled back (%s), restart failed: %v", reason, err)
 			}
+			results = append(results, res)
+			continue
 		}
+
+		recreated, err := s.recreateContainerInternal(ctx, dcli, p.cnt, p.inspect, p.newRef)
+		if err != nil {
+			res.Status = "failed"
+			var rolledBack *updateRolledBackError
+			if errors.As(err, &rolledBack) {
+				res.Status = string(models.AutoUpdateStatusRolledBack)
+			}
+			res.Error = err.Error()
+			slog.DebugContext(ctx, "restartContainersUsingOldIDs: update failed", "containerId", p.cnt.ID, "status", res.Status, "err", err)
+			results = append(results, res)
+			failProject(cd.Project, fmt.Sprintf("%s: %v", name, err), results)
+			continue
+		}
+
+		res.Status = "updated"
+		res.UpdateAvailable = true
+		res.UpdateApplied = true
+		slog.DebugContext(ctx, "restartContainersUsingOldIDs: update succeeded", "containerId", p.cnt.ID)
 		results = append(results, res)
+		newIDs[name] = recreated.id
+		if cd.Project != "" && !p.implicit {
+			updatedMembers[cd.Project] = append(updatedMembers[cd.Project], projectMember{result: len(results) - 1, recreated: recreated})
+		}
 	}
+
+	// Run post-update hooks and send notifications once every project has settled, so rolled
+	// back updates are left alone.
+	for _, res := range results {
+		p := plansByName[res.ResourceName]
+		if res.Status != "updated" || p == nil {
+			continue
+		}
+		if id := newIDs[res.ResourceName]; id != "" {
+			_ = s.runHookInternal(ctx, id, res.ResourceName, p.inspect.Config.Labels, arcaneupdater.LabelPostUpdate)
+		}
+		if s.notificationService != nil {
+			if notifErr := s.notificationService.SendContainerUpdateNotification(ctx, res.ResourceName, p.newRef, p.match, s.normalizeRef(p.newRef)); notifErr != nil {
+				slog.WarnContext(ctx, "Failed to send container update notification", "containerId", p.cnt.ID, "containerName", res.ResourceName, "imageRef", p.newRef, "error", notifErr.Error())
+			}
+		}
+	}
+
+	checked := make(map[string]bool, len(plansByName))
+	for name := range plansByName {
+		checked[name] = true
+	}
+	s.runPostCheckHooksInternal(ctx, dcli, checked)
+
 	slog.DebugContext(ctx, "restartContainersUsingOldIDs: completed scanning", "results", len(results))
 	return results, nil
 }
 
+// runPostCheckHooksInternal runs the post-check hook of every container the updater looked at,
+// in the container now running under its name.
+func (s *UpdaterService) runPostCheckHooksInternal(ctx context.Context, dcli *client.Client, checked map[string]bool) {
+	list, err := dcli.ContainerList(ctx, container.ListOptions{})
+	if err != nil {
+		slog.WarnContext(ctx, "runPostCheckHooks: list containers failed", "err", err)
+		return
+	}
+	for _, c := range list {
+		name := s.getContainerName(c)
+		if checked[name] {
+			_ = s.runHookInternal(ctx, c.ID, name, c.Labels, arcaneupdater.LabelPostCheck)
+		}
+	}
+}
+
+// maxHookOutput caps how much of a failed hook's output ends up in its error.
+const maxHookOutput = 512
+
+// runHookInternal runs the lifecycle hook set by label in the container and returns its error.
+// Containers without that hook are left alone.
+func (s *UpdaterService) runHookInternal(ctx context.Context, containerID, containerName string, labels map[string]string, label string) error {
+	command := arcaneupdater.GetHookCommand(labels, label)
+	if command == "" || s.containerService == nil {
+		return nil
+	}
+	hook := strings.TrimPrefix(label, "com.getarcaneapp.arcane.")
+
+	timeout := arcaneupdater.GetHookTimeout(labels)
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	slog.InfoContext(ctx, "runHook: running lifecycle hook", "hook", hook, "containerId", containerID, "containerName", containerName)
+	output, exitCode, err := s.containerService.RunExec(hookCtx, containerID, arcaneupdater.HookCommand(command))
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+		err = fmt.Errorf("timed out after %s", timeout)
+	case err == nil && exitCode != 0:
+		err = fmt.Errorf("exit status %d", exitCode)
+	}
+	if err == nil {
+		return nil
+	}
+
+	// Keep the end of the output, that is where shells and tools report what went wrong.
+	output = strings.TrimSpace(output)
+	if len(output) > maxHookOutput {
+		output = "..." + output[len(output)-maxHookOutput:]
+	}
+	if output != "" {
+		err = fmt.Errorf("%w: %s", err, output)
+	}
+	err = fmt.Errorf("%s hook failed: %w", hook, err)
+
+	slog.WarnContext(ctx, "runHook: lifecycle hook failed", "hook", hook, "containerId", containerID, "containerName", containerName, "err", err)
+	s.logAutoUpdate(ctx, models.EventSeverityWarning, models.JSON{
+		"phase":       "hook",
+		"hook":        hook,
+		"container":   containerName,
+		"containerId": containerID,
+		"status":      "failed",
+		"error":       err.Error(),
+	})
+	return err
+}
+
+// currentImageRefInternal returns the reference to recreate a container with when it has to
+// restart on the image it runs now: the tag it was created from while that still points to the
+// same image, the image ID otherwise.
+func (s *UpdaterService) currentImageRefInternal(ctx context.Context, cnt container.Summary, inspect container.InspectResponse, imageIDs map[string][]string) string {
+	ref := cnt.Image
+	if inspect.Config != nil && strings.TrimSpace(inspect.Config.Image) != "" {
+		ref = strings.TrimSpace(inspect.Config.Image)
+	}
+	if ref == "" || inspect.Image == "" {
+		return ref
+	}
+
+	ids, cached := imageIDs[ref]
+	if !cached {
+		ids, _ = s.resolveLocalImageIDsForRef(ctx, ref)
+		imageIDs[ref] = ids
+	}
+	if slices.Contains(ids, inspect.Image) {
+		return ref
+	}
+	return inspect.Image
+}
+
 // parseNormalizedRef expects a normalized ref in the form "host/repository:tag".
 func (s *UpdaterService) parseNormalizedRef(ref string) (host, repository, tag string) {
 	// host/repo:tag
@@ -1249,6 +2088,8 @@ func (s *UpdaterService) logAutoUpdate(ctx context.Context, sev models.EventSeve
 		} else {
 			title = "Auto-update: project"
 		}
+	case "hook":
+		title = fmt.Sprintf("Auto-update: %v hook on %v", metadata["hook"], metadata["container"])
 	case "complete":
 		title = "Auto-update run completed"
 	}
@@ -1272,6 +2113,8 @@ func (s *UpdaterService) severityFromStatus(status string) models.EventSeverity
 	switch status {
 	case "failed":
 		return models.EventSeverityError
+	case string(models.AutoUpdateStatusRolledBack):
+		return models.EventSeverityWarning
 	case "updated":
 		return models.EventSeveritySuccess
 	default:
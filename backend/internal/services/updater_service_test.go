@@ -2,14 +2,30 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	glsqlite "github.com/glebarez/sqlite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 
+	"github.com/getarcaneapp/arcane/backend/internal/database"
 	"github.com/getarcaneapp/arcane/backend/internal/models"
 	"github.com/getarcaneapp/arcane/backend/internal/utils/arcaneupdater"
+	"github.com/getarcaneapp/arcane/types/updater"
 )
 
 // mockSystemUpgradeService is a simple mock implementation for testing
@@ -229,3 +245,694 @@ func TestUpdaterService_UpgradeServiceNotNilCheck(t *testing.T) {
 
 	assert.True(t, mockUpgrade.triggerCalled, "Should call CLI upgrade when service is not nil")
 }
+
+// TestUpdaterService_RolledBackError verifies rolled back updates are told apart from failures
+func TestUpdaterService_RolledBackError(t *testing.T) {
+	s := &UpdaterService{}
+
+	cause := errors.New("healthcheck reported unhealthy")
+	err := fmt.Errorf("update web: %w", &updateRolledBackError{cause: cause})
+
+	var rolledBack *updateRolledBackError
+	require.ErrorAs(t, err, &rolledBack)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "rolled back to previous image: healthcheck reported unhealthy", rolledBack.Error())
+
+	assert.Equal(t, models.EventSeverityWarning, s.severityFromStatus(string(models.AutoUpdateStatusRolledBack)))
+	assert.Equal(t, models.EventSeverityError, s.severityFromStatus("failed"))
+}
+
+func TestUpdaterService_FullyAppliedImageRefs(t *testing.T) {
+	s := &UpdaterService{}
+	result := func(newRef string, applied bool, status string) updater.ResourceResult {
+		return updater.ResourceResult{Status: status, UpdateApplied: applied, NewImages: map[string]string{"main": newRef}}
+	}
+
+	applied := s.fullyAppliedImageRefsInternal([]updater.ResourceResult{
+		result("docker.io/library/nginx:1.27", true, "updated"),
+		result("docker.io/library/nginx:1.27", true, "updated"),
+		result("docker.io/library/redis:8", true, "updated"),
+		result("docker.io/library/redis:8", false, string(models.AutoUpdateStatusRolledBack)),
+		result("docker.io/library/postgres:17", false, "skipped"),
+		result("docker.io/library/postgres:17", true, "updated"),
+	})
+
+	assert.Equal(t, map[string]bool{
+		"docker.io/library/nginx:1.27":  true,
+		"docker.io/library/redis:8":     false,
+		"docker.io/library/postgres:17": false,
+	}, applied)
+}
+
+// fakeDockerEngine serves the container endpoints the updater uses, keeping containers in
+// memory. Started containers take the state configured for their image. Execs never exit, like
+// a hook that hangs, until the client hangs up.
+type fakeDockerEngine struct {
+	mu         sync.Mutex
+	nextID     int
+	containers map[string]*fakeDockerContainer
+	calls      []string
+	// states is the state a container of the given image has once started.
+	states map[string]*container.State
+	// failCreate and failStart make the engine refuse containers of the given image.
+	failCreate map[string]bool
+	failStart  map[string]bool
+	failRemove bool
+	// tags is the image ID of a tag that was re-pointed. Other references resolve to their name
+	// prefixed with sha256:.
+	tags map[string]string
+}
+
+type fakeDockerContainer struct {
+	id     string
+	name   string
+	image  string
+	labels map[string]string
+	state  *container.State
+}
+
+func newFakeDockerEngine(t *testing.T) (*fakeDockerEngine, *client.Client) {
+	t.Helper()
+	e := &fakeDockerEngine{
+		containers: map[string]*fakeDockerContainer{},
+		states:     map[string]*container.State{},
+		failCreate: map[string]bool{},
+		failStart:  map[string]bool{},
+		tags:       map[string]string{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /{version}/containers/create", e.handleCreate)
+	mux.HandleFunc("POST /{version}/containers/{id}/start", e.handleStart)
+	mux.HandleFunc("POST /{version}/containers/{id}/stop", e.handleStop)
+	mux.HandleFunc("DELETE /{version}/containers/{id}", e.handleRemove)
+	mux.HandleFunc("GET /{version}/containers/{id}/json", e.handleInspect)
+	mux.HandleFunc("GET /{version}/containers/json", e.handleList)
+	mux.HandleFunc("GET /{version}/images/", e.handleImageInspect)
+	mux.HandleFunc("POST /{version}/images/", e.handleImageTag)
+	mux.HandleFunc("POST /{version}/containers/{id}/exec", e.handleExecCreate)
+	mux.HandleFunc("POST /{version}/exec/{id}/start", e.handleExecStart)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	dcli, err := client.NewClientWithOpts(client.WithHost("tcp://"+srv.Listener.Addr().String()), client.WithHTTPClient(srv.Client()), client.WithVersion("1.47"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = dcli.Close() })
+	return e, dcli
+}
+
+// add registers an existing container and returns its summary and inspect data.
+func (e *fakeDockerEngine) add(name, image string, labels map[string]string) (container.Summary, container.InspectResponse) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.nextID++
+	c := &fakeDockerContainer{
+		id:     fmt.Sprintf("%s-%d", name, e.nextID),
+		name:   name,
+		image:  image,
+		labels: labels,
+		state:  &container.State{Status: container.StateRunning, Running: true, StartedAt: time.Now().Format(time.RFC3339Nano)},
+	}
+	e.containers[c.id] = c
+	return container.Summary{ID: c.id, Names: []string{"/" + name}, Image: image, Labels: labels}, e.inspectInternal(c)
+}
+
+func (e *fakeDockerEngine) inspectInternal(c *fakeDockerContainer) container.InspectResponse {
+	return container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{
+			ID:         c.id,
+			Name:       "/" + c.name,
+			Image:      e.imageIDLocked(c.image),
+			State:      c.state,
+			HostConfig: &container.HostConfig{NetworkMode: "bridge"},
+		},
+		Config:          &container.Config{Image: c.image, Labels: c.labels},
+		NetworkSettings: &container.NetworkSettings{},
+	}
+}
+
+// byName returns the container with the given name, if any.
+func (e *fakeDockerEngine) byName(name string) *fakeDockerContainer {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, c := range e.containers {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (e *fakeDockerEngine) recordedCalls() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.calls)
+}
+
+func (e *fakeDockerEngine) fail(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": msg})
+}
+
+func (e *fakeDockerEngine) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req container.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		e.fail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	name := r.URL.Query().Get("name")
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, "create "+name+" "+req.Image)
+	if e.failCreate[req.Image] {
+		e.fail(w, http.StatusInternalServerError, "create refused")
+		return
+	}
+	for _, c := range e.containers {
+		if c.name == name {
+			e.fail(w, http.StatusConflict, "name already in use")
+			return
+		}
+	}
+	e.nextID++
+	c := &fakeDockerContainer{
+		id:     fmt.Sprintf("%s-%d", name, e.nextID),
+		name:   name,
+		image:  req.Image,
+		labels: req.Labels,
+		state:  &container.State{Status: container.StateCreated},
+	}
+	e.containers[c.id] = c
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(container.CreateResponse{ID: c.id})
+}
+
+func (e *fakeDockerEngine) handleStart(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, ok := e.containers[r.PathValue("id")]
+	if !ok {
+		e.fail(w, http.StatusNotFound, "no such container")
+		return
+	}
+	e.calls = append(e.calls, "start "+c.name+" "+c.image)
+	if e.failStart[c.image] {
+		e.fail(w, http.StatusInternalServerError, "start refused")
+		return
+	}
+	if state, ok := e.states[c.image]; ok {
+		copied := *state
+		c.state = &copied
+	} else {
+		c.state = &container.State{Status: container.StateRunning, Running: true}
+	}
+	if c.state.StartedAt == "" {
+		c.state.StartedAt = time.Now().Format(time.RFC3339Nano)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (e *fakeDockerEngine) handleStop(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, ok := e.containers[r.PathValue("id")]
+	if !ok {
+		e.fail(w, http.StatusNotFound, "no such container")
+		return
+	}
+	e.calls = append(e.calls, "stop "+c.name+" "+c.image)
+	c.state = &container.State{Status: container.StateExited}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (e *fakeDockerEngine) handleRemove(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, ok := e.containers[r.PathValue("id")]
+	if !ok {
+		e.fail(w, http.StatusNotFound, "no such container")
+		return
+	}
+	e.calls = append(e.calls, "remove "+c.name+" "+c.image)
+	if e.failRemove {
+		e.fail(w, http.StatusInternalServerError, "remove refused")
+		return
+	}
+	delete(e.containers, c.id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (e *fakeDockerEngine) handleInspect(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, ok := e.containers[r.PathValue("id")]
+	if !ok {
+		e.fail(w, http.StatusNotFound, "no such container")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(e.inspectInternal(c))
+}
+
+func (e *fakeDockerEngine) handleList(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	list := []container.Summary{}
+	for _, c := range e.containers {
+		if c.state.Running {
+			list = append(list, container.Summary{ID: c.id, Names: []string{"/" + c.name}, Image: c.image, Labels: c.labels, State: container.StateRunning})
+		}
+	}
+	slices.SortFunc(list, func(a, b container.Summary) int { return strings.Compare(a.Names[0], b.Names[0]) })
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+// imageIDLocked resolves a reference the way handleImageInspect does.
+func (e *fakeDockerEngine) imageIDLocked(ref string) string {
+	if id, ok := e.tags[ref]; ok {
+		return id
+	}
+	if strings.HasPrefix(ref, "sha256:") {
+		return ref
+	}
+	return "sha256:" + ref
+}
+
+// handleImageInspect knows every image: its ID is its reference prefixed with sha256:, unless the
+// reference was re-tagged.
+func (e *fakeDockerEngine) handleImageInspect(w http.ResponseWriter, r *http.Request) {
+	ref, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/"+r.PathValue("version")+"/images/"), "/json")
+	if !ok || ref == "" {
+		e.fail(w, http.StatusNotFound, "no such image")
+		return
+	}
+	e.mu.Lock()
+	id := e.imageIDLocked(ref)
+	e.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(image.InspectResponse{ID: id, RepoTags: []string{strings.TrimPrefix(ref, "sha256:")}})
+}
+
+func (e *fakeDockerEngine) handleImageTag(w http.ResponseWriter, r *http.Request) {
+	source, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/"+r.PathValue("version")+"/images/"), "/tag")
+	if !ok || source == "" {
+		e.fail(w, http.StatusNotFound, "no such image")
+		return
+	}
+	target := r.URL.Query().Get("repo") + ":" + r.URL.Query().Get("tag")
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tags[target] = e.imageIDLocked(source)
+	e.calls = append(e.calls, "tag "+source+" "+target)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (e *fakeDockerEngine) handleExecCreate(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, ok := e.containers[r.PathValue("id")]
+	if !ok {
+		e.fail(w, http.StatusNotFound, "no such container")
+		return
+	}
+	e.calls = append(e.calls, "exec "+c.name)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(container.ExecCreateResponse{ID: c.id})
+}
+
+// handleExecStart upgrades the connection and keeps it open without output until the client
+// closes it.
+func (e *fakeDockerEngine) handleExecStart(w http.ResponseWriter, r *http.Request) {
+	conn, buf, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		e.fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer conn.Close()
+	_, _ = buf.WriteString("HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.multiplexed-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+	_ = buf.Flush()
+
+	_, _ = io.Copy(io.Discard, conn)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, "exec detached "+r.PathValue("id"))
+}
+
+func setupUpdaterTestService(t *testing.T, settings map[string]string) *UpdaterService {
+	t.Helper()
+	ctx := context.Background()
+
+	db, err := gorm.Open(glsqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.SettingVariable{}, &models.Event{}))
+	wrapped := &database.DB{DB: db}
+
+	settingsSvc, err := NewSettingsService(ctx, wrapped)
+	require.NoError(t, err)
+	require.NoError(t, settingsSvc.EnsureDefaultSettings(ctx))
+	for k, v := range settings {
+		require.NoError(t, settingsSvc.SetStringSetting(ctx, k, v))
+	}
+
+	return &UpdaterService{db: wrapped, settingsService: settingsSvc, eventService: NewEventService(wrapped)}
+}
+
+func TestUpdaterService_RecreateContainer_Healthy(t *testing.T) {
+	ctx := context.Background()
+	s := setupUpdaterTestService(t, map[string]string{"autoUpdateHealthTimeout": "5", "autoUpdateMinUptime": "0"})
+	engine, dcli := newFakeDockerEngine(t)
+	engine.states["nginx:1.27"] = &container.State{
+		Status:  container.StateRunning,
+		Running: true,
+		Health:  &container.Health{Status: container.Healthy},
+	}
+
+	cnt, inspect := engine.add("web", "nginx:1.25", nil)
+	require.NoError(t, s.stopContainerForUpdateInternal(ctx, dcli, cnt, inspect))
+
+	recreated, err := s.recreateContainerInternal(ctx, dcli, cnt, inspect, "nginx:1.27")
+	require.NoError(t, err)
+	assert.Equal(t, "web", recreated.name)
+	assert.Equal(t, "sha256:nginx:1.25", recreated.previousImage)
+	assert.Equal(t, []string{
+		"stop web nginx:1.25",
+		"remove web nginx:1.25",
+		"create web nginx:1.27",
+		"start web nginx:1.27",
+	}, engine.recordedCalls())
+
+	running := engine.byName("web")
+	require.NotNil(t, running)
+	assert.Equal(t, recreated.id, running.id)
+	assert.Equal(t, "nginx:1.27", running.image)
+}
+
+func TestUpdaterService_RecreateContainer_UnhealthyRollsBack(t *testing.T) {
+	ctx := context.Background()
+	s := setupUpdaterTestService(t, map[string]string{"autoUpdateHealthTimeout": "5", "autoUpdateMinUptime": "0"})
+	engine, dcli := newFakeDockerEngine(t)
+	engine.states["nginx:1.27"] = &container.State{
+		Status:  container.StateRunning,
+		Running: true,
+		Health: &container.Health{
+			Status: container.Unhealthy,
+			Log:    []*container.HealthcheckResult{{ExitCode: 1, Output: "connection refused\n"}},
+		},
+	}
+
+	cnt, inspect := engine.add("web", "nginx:1.25", nil)
+	require.NoError(t, s.stopContainerForUpdateInternal(ctx, dcli, cnt, inspect))
+
+	_, err := s.recreateContainerInternal(ctx, dcli, cnt, inspect, "nginx:1.27")
+	var rolledBack *updateRolledBackError
+	require.ErrorAs(t, err, &rolledBack)
+	assert.EqualError(t, rolledBack.cause, "healthcheck reported unhealthy: connection refused")
+
+	assert.Equal(t, []string{
+		"stop web nginx:1.25",
+		"remove web nginx:1.25",
+		"create web nginx:1.27",
+		"start web nginx:1.27",
+		"stop web nginx:1.27",
+		"remove web nginx:1.27",
+		"create web sha256:nginx:1.25",
+		"start web sha256:nginx:1.25",
+	}, engine.recordedCalls())
+
+	running := engine.byName("web")
+	require.NotNil(t, running)
+	assert.Equal(t, "sha256:nginx:1.25", running.image)
+	assert.True(t, running.state.Running)
+
+	var events []models.Event
+	require.NoError(t, s.db.Where("type = ?", models.EventTypeContainerUpdate).Find(&events).Error)
+	require.Len(t, events, 1)
+	assert.Equal(t, "updater_rollback", events[0].Metadata["action"])
+	assert.Equal(t, "nginx:1.27", events[0].Metadata["failedImage"])
+}
+
+func TestUpdaterService_RecreateContainer_RollbackRestoresTag(t *testing.T) {
+	ctx := context.Background()
+	s := setupUpdaterTestService(t, map[string]string{"autoUpdateHealthTimeout": "5", "autoUpdateMinUptime": "0"})
+	engine, dcli := newFakeDockerEngine(t)
+	engine.failStart["nginx:1.27"] = true
+	previousImage := "sha256:" + strings.Repeat("b", 64)
+	engine.tags["nginx:1.25"] = previousImage
+
+	cnt, inspect := engine.add("web", "nginx:1.25", nil)
+	require.NoError(t, s.stopContainerForUpdateInternal(ctx, dcli, cnt, inspect))
+
+	_, err := s.recreateContainerInternal(ctx, dcli, cnt, inspect, "nginx:1.27")
+	var rolledBack *updateRolledBackError
+	require.ErrorAs(t, err, &rolledBack)
+
+	// The container comes back on the reference it was created from, not the bare image ID.
+	assert.Equal(t, []string{
+		"stop web nginx:1.25",
+		"remove web nginx:1.25",
+		"create web nginx:1.27",
+		"start web nginx:1.27",
+		"tag " + previousImage + " docker.io/library/nginx:1.25",
+		"stop web nginx:1.27",
+		"remove web nginx:1.27",
+		"create web nginx:1.25",
+		"start web nginx:1.25",
+	}, engine.recordedCalls())
+	assert.Equal(t, previousImage, engine.tags["docker.io/library/nginx:1.25"])
+}
+
+func TestUpdaterService_RecreateContainer_FailedRollback(t *testing.T) {
+	ctx := context.Background()
+	s := setupUpdaterTestService(t, map[string]string{"autoUpdateHealthTimeout": "5", "autoUpdateMinUptime": "0"})
+	engine, dcli := newFakeDockerEngine(t)
+	engine.failStart["nginx:1.27"] = true
+	engine.failCreate["sha256:nginx:1.25"] = true
+
+	cnt, inspect := engine.add("web", "nginx:1.25", nil)
+	require.NoError(t, s.stopContainerForUpdateInternal(ctx, dcli, cnt, inspect))
+
+	_, err := s.recreateContainerInternal(ctx, dcli, cnt, inspect, "nginx:1.27")
+	require.Error(t, err)
+	var rolledBack *updateRolledBackError
+	assert.NotErrorAs(t, err, &rolledBack)
+	assert.Contains(t, err.Error(), "start: ")
+	assert.Contains(t, err.Error(), "rollback failed: create: ")
+
+	// The failed container is gone and nothing replaced it.
+	assert.Nil(t, engine.byName("web"))
+}
+
+func TestUpdaterService_WaitForUpdatedContainer(t *testing.T) {
+	ctx := context.Background()
+	engine, dcli := newFakeDockerEngine(t)
+	engine.states["crash:1"] = &container.State{Status: container.StateExited, ExitCode: 2}
+
+	disabled := setupUpdaterTestService(t, map[string]string{"autoUpdateHealthTimeout": "0"})
+	require.NoError(t, disabled.waitForUpdatedContainerInternal(ctx, dcli, "missing"))
+
+	s := setupUpdaterTestService(t, map[string]string{"autoUpdateHealthTimeout": "5", "autoUpdateMinUptime": "0"})
+	require.NoError(t, dcli.ContainerStart(ctx, mustCreateFakeContainer(t, dcli, "crash", "crash:1"), container.StartOptions{}))
+	err := s.waitForUpdatedContainerInternal(ctx, dcli, engine.byName("crash").id)
+	require.EqualError(t, err, "container exited with code 2")
+
+	require.NoError(t, dcli.ContainerStart(ctx, mustCreateFakeContainer(t, dcli, "ok", "ok:1"), container.StartOptions{}))
+	require.NoError(t, s.waitForUpdatedContainerInternal(ctx, dcli, engine.byName("ok").id))
+
+	// A container that never reports healthy times out.
+	engine.states["slow:1"] = &container.State{Status: container.StateRunning, Running: true, Health: &container.Health{Status: container.Starting}}
+	slow := setupUpdaterTestService(t, map[string]string{"autoUpdateHealthTimeout": "1", "autoUpdateMinUptime": "0"})
+	require.NoError(t, dcli.ContainerStart(ctx, mustCreateFakeContainer(t, dcli, "slow", "slow:1"), container.StartOptions{}))
+	err = slow.waitForUpdatedContainerInternal(ctx, dcli, engine.byName("slow").id)
+	require.EqualError(t, err, "container did not become healthy within 1s")
+}
+
+func TestUpdaterService_RollbackContainer(t *testing.T) {
+	ctx := context.Background()
+	s := setupUpdaterTestService(t, nil)
+	engine, dcli := newFakeDockerEngine(t)
+
+	failedID := mustCreateFakeContainer(t, dcli, "web", "nginx:1.27")
+	id, err := s.rollbackContainerInternal(ctx, dcli, failedID, "web", &container.Config{Image: "nginx:1.25"}, &container.HostConfig{}, nil)
+	require.NoError(t, err)
+	running := engine.byName("web")
+	require.NotNil(t, running)
+	assert.Equal(t, id, running.id)
+	assert.Equal(t, "nginx:1.25", running.image)
+
+	// The failed container must be removed before its name can be reused.
+	engine.failRemove = true
+	_, err = s.rollbackContainerInternal(ctx, dcli, id, "web", &container.Config{Image: "nginx:1.24"}, &container.HostConfig{}, nil)
+	require.ErrorContains(t, err, "remove failed container")
+	engine.failRemove = false
+
+	// A rollback container that can't start is reported with its ID so it can be inspected.
+	engine.failStart["nginx:1.24"] = true
+	id, err = s.rollbackContainerInternal(ctx, dcli, id, "web", &container.Config{Image: "nginx:1.24"}, &container.HostConfig{}, nil)
+	require.ErrorContains(t, err, "start: ")
+	assert.Equal(t, engine.byName("web").id, id)
+}
+
+func mustCreateFakeContainer(t *testing.T, dcli *client.Client, name, image string) string {
+	t.Helper()
+	resp, err := dcli.ContainerCreate(context.Background(), &container.Config{Image: image}, &container.HostConfig{}, nil, nil, name)
+	require.NoError(t, err)
+	return resp.ID
+}
+
+func TestUpdaterService_RestartContainers_StopsEachContainerWhenItsTurnComes(t *testing.T) {
+	ctx := context.Background()
+	s := setupUpdaterTestService(t, map[string]string{"autoUpdateHealthTimeout": "5", "autoUpdateMinUptime": "0"})
+	engine, dcli := newFakeDockerEngine(t)
+	s.dockerService = &DockerClientService{client: dcli}
+
+	compose := func(service, dependsOn string) map[string]string {
+		return map[string]string{
+			"com.docker.compose.project":    "app",
+			"com.docker.compose.service":    service,
+			"com.docker.compose.depends_on": dependsOn,
+		}
+	}
+	engine.add("app-db-1", "postgres:16", compose("db", ""))
+	engine.add("app-web-1", "nginx:1.25", compose("web", "db:service_started:false"))
+	engine.add("cache", "redis:7", nil)
+	engine.add("unrelated", "alpine:3", nil)
+
+	results, err := s.restartContainersUsingOldIDs(ctx,
+		map[string]string{"sha256:postgres:16": "postgres:17", "sha256:redis:7": "redis:8"},
+		map[string]string{})
+	require.NoError(t, err)
+
+	statuses := map[string]string{}
+	for _, res := range results {
+		statuses[res.ResourceName] = res.Status
+	}
+	assert.Equal(t, map[string]string{"app-db-1": "updated", "app-web-1": "updated", "cache": "updated"}, statuses)
+
+	calls := engine.recordedCalls()
+	index := func(call string) int {
+		t.Helper()
+		i := slices.Index(calls, call)
+		require.NotEqual(t, -1, i, "missing call %q in %v", call, calls)
+		return i
+	}
+
+	// The dependent goes down before the database it depends on and comes back after it.
+	assert.Less(t, index("stop app-web-1 nginx:1.25"), index("stop app-db-1 postgres:16"))
+	assert.Less(t, index("start app-db-1 postgres:17"), index("start app-web-1 nginx:1.25"))
+	// Containers outside the dependency chain keep running until they are replaced themselves.
+	cacheStop := index("stop cache redis:7")
+	assert.Greater(t, cacheStop, index("start app-web-1 nginx:1.25"))
+	assert.Equal(t, cacheStop+2, index("create cache redis:8"))
+	assert.NotContains(t, calls, "stop unrelated alpine:3")
+}
+
+func TestUpdaterService_ApplyPending_SkipsImagesHeldByPolicyBeforePull(t *testing.T) {
+	ctx := context.Background()
+	s := setupUpdaterTestService(t, nil)
+	require.NoError(t, s.db.AutoMigrate(&models.ImageUpdateRecord{}, &models.AutoUpdateRecord{}))
+	engine, dcli := newFakeDockerEngine(t)
+	s.dockerService = &DockerClientService{client: dcli}
+
+	latest := "1.27"
+	require.NoError(t, s.db.Create(&models.ImageUpdateRecord{ID: "nginx", Repository: "nginx", Tag: "1.25", HasUpdate: true, UpdateType: models.UpdateTypeTag, LatestVersion: &latest}).Error)
+	require.NoError(t, s.db.Create(&models.ImageUpdateRecord{ID: "redis", Repository: "redis", Tag: "7", HasUpdate: true, UpdateType: models.UpdateTypeDigest}).Error)
+	require.NoError(t, s.db.Create(&models.ImageUpdateRecord{ID: "postgres", Repository: "postgres", Tag: "16", HasUpdate: true, UpdateType: models.UpdateTypeDigest}).Error)
+
+	notify := map[string]string{arcaneupdater.LabelUpdateMode: "notify"}
+	engine.add("web", "nginx:1.25", map[string]string{arcaneupdater.LabelUpdateTypes: "digest"})
+	engine.add("cache", "redis:7", notify)
+	engine.add("db", "postgres:16", notify)
+	engine.add("db-replica", "postgres:16", nil)
+
+	result, err := s.ApplyPending(ctx, true, nil)
+	require.NoError(t, err)
+
+	reasons := map[string]string{}
+	for _, item := range result.Items {
+		assert.Equal(t, "skipped", item.Status, item.ResourceName)
+		reasons[item.ResourceName] = item.Error
+	}
+	assert.Equal(t, map[string]string{
+		"nginx:1.25":  "update policy only allows digest updates",
+		"redis:7":     "update policy is notify only",
+		"postgres:16": "",
+	}, reasons)
+}
+
+func TestUpdaterService_StartApplyPendingReportsResultInStatus(t *testing.T) {
+	ctx := context.Background()
+	s := setupUpdaterTestService(t, nil)
+	require.NoError(t, s.db.AutoMigrate(&models.ImageUpdateRecord{}))
+
+	runID, err := s.StartApplyPending(ctx, false, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, runID)
+
+	var status updater.Status
+	require.Eventually(t, func() bool {
+		status = s.GetStatus()
+		return !status.Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, runID, status.RunID)
+	assert.Empty(t, status.LastRunError)
+	require.NotNil(t, status.LastRun)
+	assert.Equal(t, runID, status.LastRun.RunID)
+}
+
+func TestUpdaterService_ApplyPending_OnlyAppliesAllowedImages(t *testing.T) {
+	ctx := context.Background()
+	s := setupUpdaterTestService(t, nil)
+	require.NoError(t, s.db.AutoMigrate(&models.ImageUpdateRecord{}, &models.AutoUpdateRecord{}))
+	engine, dcli := newFakeDockerEngine(t)
+	s.dockerService = &DockerClientService{client: dcli}
+
+	latest := "1.27"
+	require.NoError(t, s.db.Create(&models.ImageUpdateRecord{ID: "nginx", Repository: "nginx", Tag: "1.25", HasUpdate: true, UpdateType: models.UpdateTypeTag, LatestVersion: &latest}).Error)
+	require.NoError(t, s.db.Create(&models.ImageUpdateRecord{ID: "redis", Repository: "redis", Tag: "7", HasUpdate: true, UpdateType: models.UpdateTypeDigest}).Error)
+	engine.add("web", "nginx:1.25", nil)
+	engine.add("cache", "redis:7", nil)
+
+	result, err := s.ApplyPending(ctx, true, []string{"docker.io/library/nginx:1.27@sha256:aaa"})
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "nginx:1.25", result.Items[0].ResourceName)
+
+	// An empty allow-list applies nothing, a nil one everything.
+	result, err = s.ApplyPending(ctx, true, []string{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Items)
+
+	result, err = s.ApplyPending(ctx, true, nil)
+	require.NoError(t, err)
+	assert.Len(t, result.Items, 2)
+}
+
+func TestUpdaterService_RunHook_TimesOut(t *testing.T) {
+	ctx := context.Background()
+	s := setupUpdaterTestService(t, nil)
+	engine, dcli := newFakeDockerEngine(t)
+	docker := &DockerClientService{client: dcli}
+	s.containerService = &ContainerService{dockerService: docker}
+
+	labels := map[string]string{
+		arcaneupdater.LabelPreUpdate:   "sleep infinity",
+		arcaneupdater.LabelHookTimeout: "200ms",
+	}
+	cnt, _ := engine.add("web", "nginx:1.25", labels)
+
+	start := time.Now()
+	err := s.runHookInternal(ctx, cnt.ID, "web", labels, arcaneupdater.LabelPreUpdate)
+	require.EqualError(t, err, "pre-update hook failed: timed out after 200ms")
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// The updater hangs up on the exec instead of leaving the connection open.
+	assert.Eventually(t, func() bool {
+		return slices.Contains(engine.recordedCalls(), "exec detached "+cnt.ID)
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -176,6 +176,17 @@ func (s *UserService) GetUserByOidcSubjectId(ctx context.Context, subjectId stri
 	return &user, nil
 }
 
+func (s *UserService) GetUserByLdapDn(ctx context.Context, dn string) (*models.User, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("ldap_dn = ?", dn).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
 func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 	if err := s.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
@@ -293,6 +304,15 @@ func (s *UserService) CreateDefaultAdmin(ctx context.Context) error {
 
 func (s *UserService) DeleteUser(ctx context.Context, id string) error {
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.RoleAssignment{}, "user_id = ?", id).Error; err != nil {
+			return fmt.Errorf("failed to delete user role assignments: %w", err)
+		}
+		if err := tx.Delete(&models.RecoveryCode{}, "user_id = ?", id).Error; err != nil {
+			return fmt.Errorf("failed to delete user recovery codes: %w", err)
+		}
+		if err := tx.Delete(&models.Passkey{}, "user_id = ?", id).Error; err != nil {
+			return fmt.Errorf("failed to delete user passkeys: %w", err)
+		}
 		if err := tx.Delete(&models.User{}, "id = ?", id).Error; err != nil {
 			return fmt.Errorf("failed to delete user: %w", err)
 		}
@@ -357,6 +377,7 @@ func toUserResponseDto(u models.User) user.User {
 		Email:         u.Email,
 		Roles:         u.Roles,
 		OidcSubjectId: u.OidcSubjectId,
+		LdapDn:        u.LdapDn,
 		Locale:        u.Locale,
 		CreatedAt:     u.CreatedAt.Format("2006-01-02T15:04:05.999999Z"),
 		UpdatedAt:     u.UpdatedAt.Format("2006-01-02T15:04:05.999999Z"),
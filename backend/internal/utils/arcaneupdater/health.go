@@ -0,0 +1,94 @@
+package arcaneupdater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// healthPollInterval is how often WaitForStartup inspects the container.
+const healthPollInterval = time.Second
+
+// EvaluateStartup inspects the state of a freshly started container.
+// It returns done once the container passed its healthcheck, or has been running for
+// minUptime when it has no healthcheck. A non-nil error means the container failed.
+func EvaluateStartup(state *container.State, minUptime time.Duration, now time.Time) (bool, error) {
+	if state == nil {
+		return false, nil
+	}
+
+	if state.Restarting {
+		return false, fmt.Errorf("container is restarting (last exit code %d)", state.ExitCode)
+	}
+	if !state.Running {
+		if state.OOMKilled {
+			return false, errors.New("container was killed for running out of memory")
+		}
+		if state.Status == container.StateCreated {
+			return false, nil
+		}
+		return false, fmt.Errorf("container exited with code %d", state.ExitCode)
+	}
+
+	if state.Health != nil && state.Health.Status != container.NoHealthcheck && state.Health.Status != "" {
+		switch state.Health.Status {
+		case container.Healthy:
+			return true, nil
+		case container.Unhealthy:
+			msg := "healthcheck reported unhealthy"
+			if n := len(state.Health.Log); n > 0 {
+				if out := strings.TrimSpace(state.Health.Log[n-1].Output); out != "" {
+					msg += ": " + out
+				}
+			}
+			return false, errors.New(msg)
+		default:
+			return false, nil
+		}
+	}
+
+	started, err := time.Parse(time.RFC3339Nano, state.StartedAt)
+	if err != nil {
+		return false, nil
+	}
+	return now.Sub(started) >= minUptime, nil
+}
+
+// WaitForStartup polls a container until EvaluateStartup reports it healthy or failed, or
+// until timeout passes, which also counts as a failure.
+func WaitForStartup(ctx context.Context, dcli *client.Client, containerID string, timeout, minUptime time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(healthPollInterval)
+	defer ticker.Stop()
+
+	for {
+		inspect, err := dcli.ContainerInspect(ctx, containerID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("container did not become healthy within %s", timeout)
+			}
+			return fmt.Errorf("inspect: %w", err)
+		}
+
+		done, err := EvaluateStartup(inspect.State, minUptime, time.Now())
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("container did not become healthy within %s", timeout)
+		case <-ticker.C:
+		}
+	}
+}
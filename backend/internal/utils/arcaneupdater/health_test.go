@@ -0,0 +1,74 @@
+package arcaneupdater
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestEvaluateStartup(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	startedAt := now.Add(-5 * time.Second).Format(time.RFC3339Nano)
+
+	tests := []struct {
+		name     string
+		state    *container.State
+		wantDone bool
+		wantErr  bool
+	}{
+		{name: "nil state"},
+		{
+			name:  "created",
+			state: &container.State{Status: container.StateCreated},
+		},
+		{
+			name:    "exited",
+			state:   &container.State{Status: container.StateExited, ExitCode: 1},
+			wantErr: true,
+		},
+		{
+			name:    "restarting",
+			state:   &container.State{Status: container.StateRestarting, Running: true, Restarting: true, ExitCode: 137},
+			wantErr: true,
+		},
+		{
+			name:  "healthcheck starting",
+			state: &container.State{Running: true, StartedAt: startedAt, Health: &container.Health{Status: container.Starting}},
+		},
+		{
+			name:     "healthy",
+			state:    &container.State{Running: true, StartedAt: startedAt, Health: &container.Health{Status: container.Healthy}},
+			wantDone: true,
+		},
+		{
+			name: "unhealthy",
+			state: &container.State{Running: true, StartedAt: startedAt, Health: &container.Health{
+				Status: container.Unhealthy,
+				Log:    []*container.HealthcheckResult{{ExitCode: 1, Output: "connection refused\n"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name:  "no healthcheck, not running long enough",
+			state: &container.State{Running: true, StartedAt: now.Add(-time.Second).Format(time.RFC3339Nano)},
+		},
+		{
+			name:     "no healthcheck, running long enough",
+			state:    &container.State{Running: true, StartedAt: startedAt, Health: &container.Health{Status: container.NoHealthcheck}},
+			wantDone: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done, err := EvaluateStartup(tt.state, 3*time.Second, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EvaluateStartup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if done != tt.wantDone {
+				t.Errorf("EvaluateStartup() done = %v, want %v", done, tt.wantDone)
+			}
+		})
+	}
+}
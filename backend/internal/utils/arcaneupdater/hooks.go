@@ -0,0 +1,52 @@
+package arcaneupdater
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lifecycle hook labels. Each holds a shell command the updater runs inside the container
+// with "sh -c".
+const (
+	LabelPreUpdate   = "com.getarcaneapp.arcane.pre-update"   // Runs in the old container before it is stopped, a failure aborts the update
+	LabelPostUpdate  = "com.getarcaneapp.arcane.post-update"  // Runs in the new container once it has started
+	LabelPostCheck   = "com.getarcaneapp.arcane.post-check"   // Runs after every updater run that checked the container
+	LabelHookTimeout = "com.getarcaneapp.arcane.hook-timeout" // Timeout for each hook, e.g. "30s" or "5m", in seconds when no unit is given
+
+	DefaultHookTimeout = time.Minute
+)
+
+// GetHookCommand returns the command set by a lifecycle hook label, or "" when there is none.
+func GetHookCommand(labels map[string]string, label string) string {
+	for k, v := range labels {
+		if strings.EqualFold(k, label) {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// HookCommand wraps a hook command so it runs through the container's shell.
+func HookCommand(command string) []string {
+	return []string{"sh", "-c", command}
+}
+
+// GetHookTimeout returns how long a lifecycle hook may run. Invalid or missing values give
+// DefaultHookTimeout.
+func GetHookTimeout(labels map[string]string) time.Duration {
+	raw := GetHookCommand(labels, LabelHookTimeout)
+	if raw == "" {
+		return DefaultHookTimeout
+	}
+	if secs, err := strconv.Atoi(raw); err == nil {
+		if secs > 0 {
+			return time.Duration(secs) * time.Second
+		}
+		return DefaultHookTimeout
+	}
+	if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+		return d
+	}
+	return DefaultHookTimeout
+}
@@ -0,0 +1,90 @@
+package arcaneupdater
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetHookCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		label  string
+		want   string
+	}{
+		{
+			name:   "nil labels",
+			labels: nil,
+			label:  LabelPreUpdate,
+			want:   "",
+		},
+		{
+			name:   "pre-update hook",
+			labels: map[string]string{LabelPreUpdate: " pg_dumpall -f /backup/dump.sql "},
+			label:  LabelPreUpdate,
+			want:   "pg_dumpall -f /backup/dump.sql",
+		},
+		{
+			name:   "other hook only",
+			labels: map[string]string{LabelPostUpdate: "redis-cli flushall"},
+			label:  LabelPreUpdate,
+			want:   "",
+		},
+		{
+			name:   "case insensitive label key",
+			labels: map[string]string{"COM.GETARCANEAPP.ARCANE.POST-CHECK": "echo ok"},
+			label:  LabelPostCheck,
+			want:   "echo ok",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetHookCommand(tt.labels, tt.label); got != tt.want {
+				t.Errorf("GetHookCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetHookTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   time.Duration
+	}{
+		{
+			name:   "default",
+			labels: nil,
+			want:   DefaultHookTimeout,
+		},
+		{
+			name:   "seconds",
+			labels: map[string]string{LabelHookTimeout: "90"},
+			want:   90 * time.Second,
+		},
+		{
+			name:   "duration",
+			labels: map[string]string{LabelHookTimeout: "5m"},
+			want:   5 * time.Minute,
+		},
+		{
+			name:   "zero falls back to default",
+			labels: map[string]string{LabelHookTimeout: "0"},
+			want:   DefaultHookTimeout,
+		},
+		{
+			name:   "invalid falls back to default",
+			labels: map[string]string{LabelHookTimeout: "soon"},
+			want:   DefaultHookTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetHookTimeout(tt.labels); got != tt.want {
+				t.Errorf("GetHookTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
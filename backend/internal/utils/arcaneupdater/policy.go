@@ -0,0 +1,219 @@
+package arcaneupdater
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Update policy labels. The same keys without the "com.getarcaneapp.arcane.updater." prefix
+// can be set for a whole project in the x-arcane.updater block of its compose file; labels
+// on a service override them.
+const (
+	LabelUpdateWindow = "com.getarcaneapp.arcane.updater.window"  // Allowed time windows, e.g. "mon-fri 02:00-05:00; sat,sun 00:00-23:59"
+	LabelUpdateMode   = "com.getarcaneapp.arcane.updater.mode"    // "apply" (default) or "notify" to never apply updates
+	LabelUpdateTypes  = "com.getarcaneapp.arcane.updater.types"   // "all" (default) or "digest" to skip version tag bumps
+	LabelUpdateMinAge = "com.getarcaneapp.arcane.updater.min-age" // Minimum age of the new image, e.g. "72h" or "3d"
+
+	policyLabelPrefix = "com.getarcaneapp.arcane.updater."
+)
+
+// UpdatePolicy controls when and how the auto-updater may update a container.
+type UpdatePolicy struct {
+	Windows    []UpdateWindow
+	NotifyOnly bool
+	DigestOnly bool
+	MinAge     time.Duration
+}
+
+// UpdateWindow is a daily time range, in local time, on a set of weekdays. A range that ends
+// before it starts runs past midnight into the next day.
+type UpdateWindow struct {
+	Days  [7]bool // indexed by time.Weekday
+	Start int     // minutes after midnight
+	End   int     // minutes after midnight, exclusive
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseUpdatePolicy builds the policy for a container from its project's x-arcane.updater
+// settings and its own labels, which take precedence.
+func ParseUpdatePolicy(project map[string]string, labels map[string]string) (UpdatePolicy, error) {
+	values := map[string]string{}
+	for k, v := range project {
+		values[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+	for k, v := range labels {
+		key := strings.ToLower(k)
+		if strings.HasPrefix(key, policyLabelPrefix) {
+			values[strings.TrimPrefix(key, policyLabelPrefix)] = strings.TrimSpace(v)
+		}
+	}
+
+	var policy UpdatePolicy
+	if raw := values["window"]; raw != "" {
+		for spec := range strings.SplitSeq(raw, ";") {
+			if strings.TrimSpace(spec) == "" {
+				continue
+			}
+			w, err := parseUpdateWindow(spec)
+			if err != nil {
+				return UpdatePolicy{}, err
+			}
+			policy.Windows = append(policy.Windows, w)
+		}
+	}
+
+	switch mode := strings.ToLower(values["mode"]); mode {
+	case "", "apply":
+	case "notify":
+		policy.NotifyOnly = true
+	default:
+		return UpdatePolicy{}, fmt.Errorf("invalid update mode %q", mode)
+	}
+
+	switch types := strings.ToLower(values["types"]); types {
+	case "", "all":
+	case "digest":
+		policy.DigestOnly = true
+	default:
+		return UpdatePolicy{}, fmt.Errorf("invalid update types %q", types)
+	}
+
+	if raw := values["min-age"]; raw != "" {
+		d, err := parseAge(raw)
+		if err != nil {
+			return UpdatePolicy{}, err
+		}
+		policy.MinAge = d
+	}
+
+	return policy, nil
+}
+
+// parseUpdateWindow parses "[days ]HH:MM-HH:MM", where days is a comma separated list of
+// weekdays or ranges such as "mon-fri". Without days the window applies every day.
+func parseUpdateWindow(spec string) (UpdateWindow, error) {
+	var w UpdateWindow
+	fields := strings.Fields(spec)
+	var days, hours string
+	switch len(fields) {
+	case 1:
+		hours = fields[0]
+		for i := range w.Days {
+			w.Days[i] = true
+		}
+	case 2:
+		days, hours = fields[0], fields[1]
+	default:
+		return w, fmt.Errorf("invalid update window %q", strings.TrimSpace(spec))
+	}
+
+	for part := range strings.SplitSeq(strings.ToLower(days), ",") {
+		if part == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		start, ok := weekdays[from]
+		if !ok {
+			return w, fmt.Errorf("invalid weekday %q in update window", from)
+		}
+		end := start
+		if isRange {
+			if end, ok = weekdays[to]; !ok {
+				return w, fmt.Errorf("invalid weekday %q in update window", to)
+			}
+		}
+		for d := start; ; d = (d + 1) % 7 {
+			w.Days[d] = true
+			if d == end {
+				break
+			}
+		}
+	}
+
+	from, to, ok := strings.Cut(hours, "-")
+	if !ok {
+		return w, fmt.Errorf("invalid update window hours %q", hours)
+	}
+	var err error
+	if w.Start, err = parseClock(from); err != nil {
+		return w, err
+	}
+	if w.End, err = parseClock(to); err != nil {
+		return w, err
+	}
+	return w, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q in update window", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseAge parses a Go duration, additionally accepting a number of days such as "3d".
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid minimum image age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid minimum image age %q", s)
+	}
+	return d, nil
+}
+
+// Contains reports whether t falls inside the window.
+func (w UpdateWindow) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if w.Start <= w.End {
+		return w.Days[t.Weekday()] && minute >= w.Start && minute < w.End
+	}
+	// Past midnight: the early part belongs to the window that started the day before.
+	if minute >= w.Start {
+		return w.Days[t.Weekday()]
+	}
+	return minute < w.End && w.Days[(t.Weekday()+6)%7]
+}
+
+// InWindow reports whether updates are allowed at t. Without windows they always are.
+func (p UpdatePolicy) InWindow(t time.Time) bool {
+	if len(p.Windows) == 0 {
+		return true
+	}
+	for _, w := range p.Windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipReason returns why the policy holds back an update at now, or "" when it may be applied.
+// tagUpdate tells whether the update moves to another tag, imageCreated is when the new image
+// was built (zero if unknown).
+func (p UpdatePolicy) SkipReason(now time.Time, tagUpdate bool, imageCreated time.Time) string {
+	switch {
+	case p.NotifyOnly:
+		return "update policy is notify only"
+	case p.DigestOnly && tagUpdate:
+		return "update policy only allows digest updates"
+	case !p.InWindow(now):
+		return "outside of the update window"
+	case p.MinAge > 0 && !imageCreated.IsZero() && now.Sub(imageCreated) < p.MinAge:
+		return fmt.Sprintf("new image is younger than %s", p.MinAge)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,100 @@
+package arcaneupdater
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseUpdatePolicy(t *testing.T) {
+	project := map[string]string{"window": "mon-fri 02:00-05:00", "types": "digest"}
+	labels := map[string]string{
+		LabelUpdateWindow: "sat,sun 22:00-04:00; wed 12:00-13:00",
+		LabelUpdateMinAge: "3d",
+		"unrelated":       "x",
+	}
+
+	policy, err := ParseUpdatePolicy(project, labels)
+	if err != nil {
+		t.Fatalf("ParseUpdatePolicy() error = %v", err)
+	}
+	if !policy.DigestOnly || policy.NotifyOnly {
+		t.Errorf("policy flags = %+v", policy)
+	}
+	if policy.MinAge != 72*time.Hour {
+		t.Errorf("MinAge = %s", policy.MinAge)
+	}
+	if len(policy.Windows) != 2 {
+		t.Fatalf("Windows = %+v, want label windows to replace the project window", policy.Windows)
+	}
+
+	for _, bad := range []map[string]string{
+		{LabelUpdateWindow: "someday 02:00-03:00"},
+		{LabelUpdateWindow: "02:00"},
+		{LabelUpdateMode: "sometimes"},
+		{LabelUpdateTypes: "major"},
+		{LabelUpdateMinAge: "soon"},
+	} {
+		if _, err := ParseUpdatePolicy(nil, bad); err == nil {
+			t.Errorf("ParseUpdatePolicy(%v) expected an error", bad)
+		}
+	}
+}
+
+func TestUpdatePolicyInWindow(t *testing.T) {
+	policy, err := ParseUpdatePolicy(nil, map[string]string{LabelUpdateWindow: "fri-sat 23:00-02:00; 12:00-12:30"})
+	if err != nil {
+		t.Fatalf("ParseUpdatePolicy() error = %v", err)
+	}
+
+	// 2025-01-03 is a Friday.
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 1, day, hour, minute, 0, 0, time.Local) }
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{at(3, 23, 30), true},  // Friday night
+		{at(4, 1, 59), true},   // early Saturday, Friday's window
+		{at(4, 2, 0), false},   // window end is exclusive
+		{at(5, 1, 0), true},    // early Sunday, Saturday's window
+		{at(5, 23, 30), false}, // Sunday night
+		{at(2, 1, 0), false},   // early Thursday
+		{at(1, 12, 15), true},  // daily window
+	}
+	for _, tt := range tests {
+		if got := policy.InWindow(tt.at); got != tt.want {
+			t.Errorf("InWindow(%s) = %v, want %v", tt.at.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+
+	if !(UpdatePolicy{}).InWindow(at(1, 3, 0)) {
+		t.Error("a policy without windows should always be in window")
+	}
+}
+
+func TestUpdatePolicySkipReason(t *testing.T) {
+	now := time.Date(2025, 1, 3, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		policy    UpdatePolicy
+		tagUpdate bool
+		created   time.Time
+		skip      bool
+	}{
+		{name: "default", policy: UpdatePolicy{}, tagUpdate: true, created: now},
+		{name: "notify only", policy: UpdatePolicy{NotifyOnly: true}, skip: true},
+		{name: "digest only, tag update", policy: UpdatePolicy{DigestOnly: true}, tagUpdate: true, skip: true},
+		{name: "digest only, digest update", policy: UpdatePolicy{DigestOnly: true}},
+		{name: "image too new", policy: UpdatePolicy{MinAge: 48 * time.Hour}, created: now.Add(-time.Hour), skip: true},
+		{name: "image old enough", policy: UpdatePolicy{MinAge: 48 * time.Hour}, created: now.Add(-72 * time.Hour)},
+		{name: "image age unknown", policy: UpdatePolicy{MinAge: 48 * time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := tt.policy.SkipReason(now, tt.tagUpdate, tt.created)
+			if (reason != "") != tt.skip {
+				t.Errorf("SkipReason() = %q, want skip %v", reason, tt.skip)
+			}
+		})
+	}
+}
@@ -11,14 +11,24 @@ import (
 	"github.com/docker/docker/client"
 )
 
+// Compose labels that place a container in its project's dependency graph.
+const (
+	composeProjectLabel   = "com.docker.compose.project"
+	composeServiceLabel   = "com.docker.compose.service"
+	composeDependsOnLabel = "com.docker.compose.depends_on" // "service:condition:restart,..."
+)
+
 // ContainerWithDeps represents a container with its dependency information for sorting
 type ContainerWithDeps struct {
 	Container   container.Summary
 	Inspect     container.InspectResponse
 	Name        string
 	Links       []string // Container names this one links to
-	DependsOn   []string // Explicit dependencies from label
+	DependsOn   []string // Explicit dependencies from label, plus compose dependencies once resolved
 	NetworkDeps []string // Implicit dependencies from container network mode
+	Project     string   // Compose project, empty for standalone containers
+	Service     string   // Compose service
+	ComposeDeps []string // Services of the same project this container's service depends on
 }
 
 // ContainerSorter handles topological sorting of containers based on dependencies
@@ -154,6 +164,19 @@ func ExtractContainerDeps(ctx context.Context, dcli *client.Client, cnt containe
 		}
 	}
 
+	// Extract the compose project, service and depends_on services
+	if inspect.Config != nil && inspect.Config.Labels != nil {
+		labels := inspect.Config.Labels
+		c.Project = labels[composeProjectLabel]
+		c.Service = labels[composeServiceLabel]
+		for dep := range strings.SplitSeq(labels[composeDependsOnLabel], ",") {
+			service, _, _ := strings.Cut(strings.TrimSpace(dep), ":")
+			if service != "" {
+				c.ComposeDeps = append(c.ComposeDeps, service)
+			}
+		}
+	}
+
 	// Extract implicit dependencies from network mode (container:xxx)
 	if inspect.HostConfig != nil {
 		nm := inspect.HostConfig.NetworkMode
@@ -168,11 +191,50 @@ func ExtractContainerDeps(ctx context.Context, dcli *client.Client, cnt containe
 		"container", c.Name,
 		"links", c.Links,
 		"dependsOn", c.DependsOn,
-		"networkDeps", c.NetworkDeps)
+		"networkDeps", c.NetworkDeps,
+		"project", c.Project,
+		"composeDeps", c.ComposeDeps)
 
 	return c
 }
 
+// ResolveDependencies turns the compose dependencies of each container into the names of the
+// containers running those services, and network dependencies given as container IDs into
+// container names, so that the sorter sees the whole graph.
+func ResolveDependencies(containers []ContainerWithDeps) {
+	byService := map[string][]string{}
+	byID := map[string]string{}
+	for _, c := range containers {
+		if c.Project != "" && c.Service != "" {
+			key := c.Project + "/" + c.Service
+			byService[key] = append(byService[key], c.Name)
+		}
+		if id := c.Container.ID; id != "" {
+			byID[id] = c.Name
+			if len(id) > 12 {
+				byID[id[:12]] = c.Name
+			}
+		}
+	}
+
+	for i, c := range containers {
+		if c.Project != "" {
+			for _, svc := range c.ComposeDeps {
+				for _, name := range byService[c.Project+"/"+svc] {
+					if !slices.Contains(containers[i].DependsOn, name) {
+						containers[i].DependsOn = append(containers[i].DependsOn, name)
+					}
+				}
+			}
+		}
+		for j, ref := range c.NetworkDeps {
+			if name, ok := byID[ref]; ok {
+				containers[i].NetworkDeps[j] = name
+			}
+		}
+	}
+}
+
 // UpdateImplicitRestart marks containers that need to restart because their dependencies are restarting.
 // Returns the names of containers that were marked for implicit restart.
 // Note: This function mutates the containers slice by adding "_arcane_implicit_restart" labels.
@@ -207,6 +269,32 @@ func UpdateImplicitRestart(containers []ContainerWithDeps, markedForRestart map[
 	return implicitRestarts
 }
 
+// ExpandProjectRestarts marks every container of a compose project for restart once one of its
+// containers is, so that projects are always restarted as a whole.
+// Returns the names of containers that were newly marked.
+func ExpandProjectRestarts(containers []ContainerWithDeps, markedForRestart map[string]bool) []string {
+	projects := map[string]bool{}
+	for _, c := range containers {
+		if c.Project != "" && markedForRestart[c.Name] {
+			projects[c.Project] = true
+		}
+	}
+
+	var added []string
+	for i, c := range containers {
+		if markedForRestart[c.Name] || !projects[c.Project] {
+			continue
+		}
+		markedForRestart[c.Name] = true
+		if containers[i].Container.Labels == nil {
+			containers[i].Container.Labels = map[string]string{}
+		}
+		containers[i].Container.Labels["_arcane_implicit_restart"] = "true"
+		added = append(added, c.Name)
+	}
+	return added
+}
+
 // ExtractContainerName extracts a clean container name from the summary
 func ExtractContainerName(cnt container.Summary) string {
 	if len(cnt.Names) > 0 {
@@ -352,6 +352,85 @@ func TestUpdateImplicitRestart(t *testing.T) {
 	}
 }
 
+func TestExtractContainerDeps_Compose(t *testing.T) {
+	inspect := container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{HostConfig: &container.HostConfig{}},
+		Config: &container.Config{Labels: map[string]string{
+			composeProjectLabel:   "shop",
+			composeServiceLabel:   "api",
+			composeDependsOnLabel: "db:service_healthy:false, cache:service_started:true",
+		}},
+	}
+
+	c := ExtractContainerDeps(t.Context(), nil, container.Summary{ID: "api1", Names: []string{"/shop-api-1"}}, inspect)
+
+	if c.Project != "shop" || c.Service != "api" {
+		t.Errorf("project/service = %q/%q, want shop/api", c.Project, c.Service)
+	}
+	if len(c.ComposeDeps) != 2 || c.ComposeDeps[0] != "db" || c.ComposeDeps[1] != "cache" {
+		t.Errorf("ComposeDeps = %v, want [db cache]", c.ComposeDeps)
+	}
+}
+
+func TestResolveDependencies(t *testing.T) {
+	containers := []ContainerWithDeps{
+		{Name: "shop-api-1", Project: "shop", Service: "api", ComposeDeps: []string{"db"}, Container: container.Summary{ID: "api1"}},
+		{Name: "shop-api-2", Project: "shop", Service: "api", ComposeDeps: []string{"db"}, Container: container.Summary{ID: "api2"}},
+		{Name: "shop-db-1", Project: "shop", Service: "db", Container: container.Summary{ID: "db1"}},
+		{Name: "blog-db-1", Project: "blog", Service: "db", Container: container.Summary{ID: "blogdb1"}},
+		{Name: "vpn", Container: container.Summary{ID: "0123456789abcdef0123"}},
+		{Name: "torrent", NetworkDeps: []string{"0123456789ab"}, Container: container.Summary{ID: "torrent1"}},
+	}
+
+	ResolveDependencies(containers)
+
+	for _, i := range []int{0, 1} {
+		if len(containers[i].DependsOn) != 1 || containers[i].DependsOn[0] != "shop-db-1" {
+			t.Errorf("%s DependsOn = %v, want [shop-db-1]", containers[i].Name, containers[i].DependsOn)
+		}
+	}
+	if containers[5].NetworkDeps[0] != "vpn" {
+		t.Errorf("torrent NetworkDeps = %v, want [vpn]", containers[5].NetworkDeps)
+	}
+
+	sorted, err := NewContainerSorter(containers).Sort()
+	if err != nil {
+		t.Fatalf("Sort() error = %v", err)
+	}
+	pos := map[string]int{}
+	for i, c := range sorted {
+		pos[c.Name] = i
+	}
+	if pos["shop-db-1"] > pos["shop-api-1"] || pos["shop-db-1"] > pos["shop-api-2"] {
+		t.Errorf("Sort() = %v, want shop-db-1 before the api containers", sorted)
+	}
+	if pos["vpn"] > pos["torrent"] {
+		t.Errorf("Sort() = %v, want vpn before torrent", sorted)
+	}
+}
+
+func TestExpandProjectRestarts(t *testing.T) {
+	containers := []ContainerWithDeps{
+		{Name: "shop-api-1", Project: "shop", Container: container.Summary{ID: "api1"}},
+		{Name: "shop-db-1", Project: "shop", Container: container.Summary{ID: "db1"}},
+		{Name: "blog-web-1", Project: "blog", Container: container.Summary{ID: "web1"}},
+		{Name: "standalone", Container: container.Summary{ID: "s1"}},
+	}
+	marked := map[string]bool{"shop-db-1": true, "standalone": true}
+
+	added := ExpandProjectRestarts(containers, marked)
+
+	if len(added) != 1 || added[0] != "shop-api-1" {
+		t.Errorf("ExpandProjectRestarts() = %v, want [shop-api-1]", added)
+	}
+	if marked["blog-web-1"] {
+		t.Error("containers of other projects must not be marked")
+	}
+	if containers[0].Container.Labels["_arcane_implicit_restart"] != "true" {
+		t.Error("expanded container should carry the implicit restart label")
+	}
+}
+
 func TestExtractContainerName(t *testing.T) {
 	tests := []struct {
 		name string
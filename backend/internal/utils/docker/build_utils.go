@@ -0,0 +1,184 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/getarcaneapp/arcane/types/imagebuild"
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/go-archive"
+	"github.com/moby/patternmatcher/ignorefile"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	buildKitTraceID = "moby.buildkit.trace"
+	imageIDAuxID    = "moby.image.id"
+)
+
+// BuildContextTar returns the directory dir as a tar stream to send as a build context.
+// Paths matched by the .dockerignore file of the context are left out; the Dockerfile and
+// the .dockerignore file itself are always sent since the daemon reads them.
+func BuildContextTar(dir, dockerfile string) (io.ReadCloser, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("build context %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("build context %s is not a directory", dir)
+	}
+
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	if !filepath.IsLocal(dockerfile) {
+		return nil, fmt.Errorf("dockerfile %s must be inside the build context", dockerfile)
+	}
+	if _, err := os.Stat(filepath.Join(dir, dockerfile)); err != nil {
+		return nil, fmt.Errorf("dockerfile %s: %w", dockerfile, err)
+	}
+
+	excludes, err := readDockerignoreInternal(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(excludes) > 0 {
+		excludes = append(excludes, "!"+filepath.ToSlash(filepath.Clean(dockerfile)), "!.dockerignore")
+	}
+
+	return archive.TarWithOptions(dir, &archive.TarOptions{
+		ExcludePatterns: excludes,
+		ChownOpts:       &archive.ChownOpts{UID: 0, GID: 0},
+	})
+}
+
+func readDockerignoreInternal(dir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(dir, ".dockerignore"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open .dockerignore: %w", err)
+	}
+	defer f.Close()
+
+	excludes, err := ignorefile.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .dockerignore: %w", err)
+	}
+	return excludes, nil
+}
+
+// DecodeBuildStream reads the JSON message stream of an image build and passes the progress
+// to emit. BuildKit trace messages are decoded into step, status and log events, output of the
+// classic builder becomes log events. It returns the ID of the built image, or the error the
+// daemon reported for the build.
+func DecodeBuildStream(r io.Reader, emit func(imagebuild.ProgressEvent)) (string, error) {
+	dec := json.NewDecoder(r)
+	var imageID string
+
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return imageID, nil
+			}
+			return imageID, fmt.Errorf("failed to read build output: %w", err)
+		}
+
+		if msg.Error != nil {
+			return imageID, errors.New(msg.Error.Message)
+		}
+		if msg.ErrorMessage != "" {
+			return imageID, errors.New(msg.ErrorMessage)
+		}
+
+		switch {
+		case msg.ID == buildKitTraceID && msg.Aux != nil:
+			decodeBuildKitTraceInternal(*msg.Aux, emit)
+		case msg.Aux != nil:
+			// Both builders report the image ID in an aux message, BuildKit with the
+			// moby.image.id ID and the classic builder without one.
+			if msg.ID == "" || msg.ID == imageIDAuxID {
+				var result struct {
+					ID string `json:"ID"`
+				}
+				if json.Unmarshal(*msg.Aux, &result) == nil && result.ID != "" {
+					imageID = result.ID
+				}
+			}
+		case msg.Stream != "":
+			if line := strings.TrimRight(msg.Stream, "\r\n"); line != "" {
+				emit(imagebuild.ProgressEvent{Type: imagebuild.EventLog, Message: line, Timestamp: time.Now()})
+			}
+		case msg.Status != "":
+			ev := imagebuild.ProgressEvent{Type: imagebuild.EventStatus, Step: msg.ID, Name: msg.Status, Timestamp: time.Now()}
+			if msg.Progress != nil {
+				ev.Current = msg.Progress.Current
+				ev.Total = msg.Progress.Total
+			}
+			emit(ev)
+		}
+	}
+}
+
+func decodeBuildKitTraceInternal(aux json.RawMessage, emit func(imagebuild.ProgressEvent)) {
+	// The trace is a base64 encoded StatusResponse protobuf inside a JSON string.
+	var dt []byte
+	if err := json.Unmarshal(aux, &dt); err != nil {
+		return
+	}
+	var resp controlapi.StatusResponse
+	if err := resp.UnmarshalVT(dt); err != nil {
+		return
+	}
+
+	now := time.Now()
+	for _, v := range resp.GetVertexes() {
+		emit(imagebuild.ProgressEvent{
+			Type:      imagebuild.EventStep,
+			Step:      v.GetDigest(),
+			Name:      v.GetName(),
+			Cached:    v.GetCached(),
+			Started:   protoTimeInternal(v.GetStarted()),
+			Completed: protoTimeInternal(v.GetCompleted()),
+			Error:     v.GetError(),
+			Timestamp: now,
+		})
+	}
+	for _, st := range resp.GetStatuses() {
+		emit(imagebuild.ProgressEvent{
+			Type:      imagebuild.EventStatus,
+			Step:      st.GetVertex(),
+			Name:      st.GetID(),
+			Current:   st.GetCurrent(),
+			Total:     st.GetTotal(),
+			Started:   protoTimeInternal(st.GetStarted()),
+			Completed: protoTimeInternal(st.GetCompleted()),
+			Timestamp: now,
+		})
+	}
+	for _, l := range resp.GetLogs() {
+		if msg := strings.TrimRight(string(l.GetMsg()), "\r\n"); msg != "" {
+			emit(imagebuild.ProgressEvent{Type: imagebuild.EventLog, Step: l.GetVertex(), Message: msg, Timestamp: now})
+		}
+	}
+	for _, w := range resp.GetWarnings() {
+		emit(imagebuild.ProgressEvent{Type: imagebuild.EventLog, Step: w.GetVertex(), Message: "WARNING: " + string(w.GetShort()), Timestamp: now})
+	}
+}
+
+func protoTimeInternal(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
@@ -0,0 +1,137 @@
+package docker
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/getarcaneapp/arcane/types/imagebuild"
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildContextTar(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Dockerfile":         "FROM scratch\n",
+		"app/main.go":        "package main\n",
+		"node_modules/a.js":  "x",
+		"secrets.env":        "TOKEN=1",
+		".dockerignore":      "node_modules\n*.env\nDockerfile\n",
+		"docs/keep/readme":   "doc",
+		"docs/skip/readme":   "doc",
+		"docs/.dockerignore": "ignored, only the root file counts",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	rc, err := BuildContextTar(dir, "Dockerfile")
+	require.NoError(t, err)
+	defer rc.Close()
+
+	var names []string
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		if hdr.Typeflag == tar.TypeReg {
+			names = append(names, hdr.Name)
+			assert.Equal(t, 0, hdr.Uid)
+		}
+	}
+	sort.Strings(names)
+
+	assert.Equal(t, []string{".dockerignore", "Dockerfile", "app/main.go", "docs/.dockerignore", "docs/keep/readme", "docs/skip/readme"}, names)
+}
+
+func TestBuildContextTarRejectsDockerfileOutsideContext(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := BuildContextTar(dir, "../Dockerfile")
+	require.Error(t, err)
+
+	_, err = BuildContextTar(dir, "Dockerfile")
+	require.Error(t, err)
+
+	_, err = BuildContextTar(filepath.Join(dir, "missing"), "")
+	require.Error(t, err)
+}
+
+func buildKitTraceLineInternal(t *testing.T, resp *controlapi.StatusResponse) string {
+	t.Helper()
+	dt, err := resp.MarshalVT()
+	require.NoError(t, err)
+	aux, err := json.Marshal(dt)
+	require.NoError(t, err)
+	line, err := json.Marshal(map[string]any{"id": "moby.buildkit.trace", "aux": json.RawMessage(aux)})
+	require.NoError(t, err)
+	return string(line)
+}
+
+func TestDecodeBuildStreamBuildKit(t *testing.T) {
+	trace := buildKitTraceLineInternal(t, &controlapi.StatusResponse{
+		Vertexes: []*controlapi.Vertex{{Digest: "sha256:step1", Name: "[1/2] FROM docker.io/library/alpine", Cached: true}},
+		Statuses: []*controlapi.VertexStatus{{ID: "extracting", Vertex: "sha256:step1", Current: 10, Total: 20}},
+		Logs:     []*controlapi.VertexLog{{Vertex: "sha256:step2", Msg: []byte("added 12 packages\n")}},
+	})
+	stream := strings.Join([]string{
+		trace,
+		`{"id":"moby.image.id","aux":{"ID":"sha256:abc"}}`,
+	}, "\n")
+
+	var events []imagebuild.ProgressEvent
+	imageID, err := DecodeBuildStream(strings.NewReader(stream), func(ev imagebuild.ProgressEvent) {
+		events = append(events, ev)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:abc", imageID)
+
+	require.Len(t, events, 3)
+	assert.Equal(t, imagebuild.EventStep, events[0].Type)
+	assert.Equal(t, "sha256:step1", events[0].Step)
+	assert.True(t, events[0].Cached)
+	assert.Nil(t, events[0].Started)
+	assert.Equal(t, imagebuild.EventStatus, events[1].Type)
+	assert.Equal(t, int64(20), events[1].Total)
+	assert.Equal(t, imagebuild.EventLog, events[2].Type)
+	assert.Equal(t, "added 12 packages", events[2].Message)
+}
+
+func TestDecodeBuildStreamClassicBuilder(t *testing.T) {
+	stream := `{"stream":"Step 1/2 : FROM alpine\n"}
+{"status":"Pulling fs layer","id":"a1b2","progressDetail":{"current":5,"total":10}}
+{"aux":{"ID":"sha256:def"}}
+{"stream":"Successfully built def\n"}`
+
+	var events []imagebuild.ProgressEvent
+	imageID, err := DecodeBuildStream(strings.NewReader(stream), func(ev imagebuild.ProgressEvent) {
+		events = append(events, ev)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:def", imageID)
+	require.Len(t, events, 3)
+	assert.Equal(t, "Step 1/2 : FROM alpine", events[0].Message)
+	assert.Equal(t, "a1b2", events[1].Step)
+	assert.Equal(t, int64(5), events[1].Current)
+}
+
+func TestDecodeBuildStreamError(t *testing.T) {
+	stream := `{"stream":"Step 1/2 : RUN false\n"}
+{"errorDetail":{"code":1,"message":"The command '/bin/sh -c false' returned a non-zero code: 1"},"error":"The command '/bin/sh -c false' returned a non-zero code: 1"}`
+
+	_, err := DecodeBuildStream(strings.NewReader(stream), func(imagebuild.ProgressEvent) {})
+	require.EqualError(t, err, "The command '/bin/sh -c false' returned a non-zero code: 1")
+}
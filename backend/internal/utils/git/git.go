@@ -2,6 +2,8 @@ package git
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
@@ -21,6 +23,8 @@ import (
 	"github.com/gofrs/flock"
 	gossh "golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/getarcaneapp/arcane/backend/internal/utils/version"
 )
 
 // Client handles git operations
@@ -261,6 +265,224 @@ func (c *Client) Clone(ctx context.Context, url, branch string, auth AuthConfig)
 	return tmpDir, nil
 }
 
+// mirrorDir returns the persistent checkout directory for the refs a selector fetches
+func (c *Client) mirrorDir(url string, ref RefSelector) string {
+	workDir := c.workDir
+	if workDir == "" {
+		workDir = os.TempDir()
+	}
+	sum := sha256.Sum256([]byte(url + "\x00" + ref.mirrorKey()))
+	return filepath.Join(workDir, "mirrors", hex.EncodeToString(sum[:12]))
+}
+
+// Checkout is a mirror checked out at the commit a RefSelector resolved to
+type Checkout struct {
+	Dir string
+	// Ref is the branch or tag the selector resolved to, or the commit SHA for commit selectors.
+	Ref    string
+	Commit string
+}
+
+// Mirror returns a persistent checkout of the commit ref resolves to. The checkout is kept
+// under the work directory and only fetched incrementally on subsequent calls. It is
+// locked until the returned release function is called, and must not be modified by the
+// caller.
+func (c *Client) Mirror(ctx context.Context, url string, ref RefSelector, auth AuthConfig) (*Checkout, func(), error) {
+	if err := ref.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+	}
+
+	dir := c.mirrorDir(url, ref)
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create mirror dir: %w", err)
+	}
+
+	fileLock := flock.New(dir + ".lock")
+	locked, err := fileLock.TryLockContext(ctx, 250*time.Millisecond)
+	if err != nil || !locked {
+		return nil, nil, fmt.Errorf("failed to lock repository mirror: %w", err)
+	}
+	release := func() {
+		_ = fileLock.Unlock()
+	}
+
+	authMethod, err := c.getAuth(auth)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	checkout, err := c.updateMirror(ctx, dir, url, ref, authMethod)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	return checkout, release, nil
+}
+
+// updateMirror fetches the refs of a selector into a mirror, recreating the mirror when it
+// is missing or unusable, and checks out the commit the selector resolves to
+func (c *Client) updateMirror(ctx context.Context, dir, url string, ref RefSelector, authMethod transport.AuthMethod) (*Checkout, error) {
+	repo, err := git.PlainOpen(dir)
+	if err == nil {
+		remote, remoteErr := repo.Remote("origin")
+		if remoteErr != nil || len(remote.Config().URLs) == 0 || remote.Config().URLs[0] != url {
+			err = fmt.Errorf("mirror remote does not match")
+		}
+	}
+	if err != nil {
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, fmt.Errorf("failed to reset repository mirror: %w", err)
+		}
+		if repo, err = git.PlainInit(dir, false); err != nil {
+			return nil, fmt.Errorf("failed to create repository mirror: %w", err)
+		}
+		if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{url}}); err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, fmt.Errorf("failed to create repository mirror: %w", err)
+		}
+	}
+
+	// Commits don't move, so a pinned commit that is already present needs no fetch.
+	var resolved *Checkout
+	if ref.Type == RefTypeCommit {
+		resolved, _ = resolveRef(repo, ref)
+	}
+	if resolved == nil {
+		fetchOptions := &git.FetchOptions{
+			RemoteName: "origin",
+			RefSpecs:   ref.refSpecs(),
+			Auth:       authMethod,
+			Force:      true,
+			Prune:      true,
+		}
+		if err := repo.FetchContext(ctx, fetchOptions); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return nil, fmt.Errorf("failed to fetch repository: %w", err)
+		}
+		if resolved, err = resolveRef(repo, ref); err != nil {
+			return nil, err
+		}
+	}
+
+	commit := plumbing.NewHash(resolved.Commit)
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, commit)); err != nil {
+		return nil, fmt.Errorf("failed to check out %s: %w", resolved.Commit, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open worktree: %w", err)
+	}
+	if err := worktree.Reset(&git.ResetOptions{Commit: commit, Mode: git.HardReset}); err != nil {
+		return nil, fmt.Errorf("failed to check out %s: %w", resolved.Commit, err)
+	}
+	if err := worktree.Clean(&git.CleanOptions{Dir: true}); err != nil {
+		return nil, fmt.Errorf("failed to clean worktree: %w", err)
+	}
+
+	resolved.Dir = dir
+	return resolved, nil
+}
+
+// resolveRef finds the commit a selector points at among the refs fetched into repo
+func resolveRef(repo *git.Repository, ref RefSelector) (*Checkout, error) {
+	switch ref.Type {
+	case RefTypeTag, RefTypeSemver:
+		return resolveTag(repo, ref)
+	case RefTypeCommit:
+		hash, err := repo.ResolveRevision(plumbing.Revision(ref.Pattern))
+		if err != nil {
+			return nil, fmt.Errorf("commit %s not found: %w", ref.Pattern, err)
+		}
+		if _, err := repo.CommitObject(*hash); err != nil {
+			return nil, fmt.Errorf("commit %s not found: %w", ref.Pattern, err)
+		}
+		return &Checkout{Ref: hash.String(), Commit: hash.String()}, nil
+	default:
+		branchRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", ref.Branch), true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve branch %s: %w", ref.Branch, err)
+		}
+		return &Checkout{Ref: ref.Branch, Commit: branchRef.Hash().String()}, nil
+	}
+}
+
+// resolveTag picks the tag a tag or semver selector resolves to: the highest semantic
+// version among the matching tags, or the one pointing at the newest commit when none of
+// them is a semantic version
+func resolveTag(repo *git.Repository, ref RefSelector) (*Checkout, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	var best *Checkout
+	var bestIsVersion bool
+	var bestTime time.Time
+	err = tags.ForEach(func(tagRef *plumbing.Reference) error {
+		name := tagRef.Name().Short()
+		if !ref.matchesTag(name) {
+			return nil
+		}
+
+		// Annotated tags point at a tag object rather than the commit.
+		commitHash := tagRef.Hash()
+		if tagObject, err := repo.TagObject(commitHash); err == nil {
+			commit, err := tagObject.Commit()
+			if err != nil {
+				return nil //nolint:nilerr // tags of trees or blobs can't be checked out
+			}
+			commitHash = commit.Hash
+		}
+		commit, err := repo.CommitObject(commitHash)
+		if err != nil {
+			return nil //nolint:nilerr // same as above
+		}
+
+		_, isVersion := version.Parse(name)
+		better := best == nil
+		if !better && (isVersion || bestIsVersion) {
+			better = version.Compare(name, best.Ref) > 0
+		} else if !better {
+			better = commit.Committer.When.After(bestTime)
+		}
+		if better {
+			best = &Checkout{Ref: name, Commit: commitHash.String()}
+			bestIsVersion, bestTime = isVersion, commit.Committer.When
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no tag matches %s", ref)
+	}
+	return best, nil
+}
+
+// RemoveMirror deletes the persistent checkout used for a selector, if any
+func (c *Client) RemoveMirror(ctx context.Context, url string, ref RefSelector) error {
+	dir := c.mirrorDir(url, ref)
+
+	fileLock := flock.New(dir + ".lock")
+	locked, err := fileLock.TryLockContext(ctx, 250*time.Millisecond)
+	if err != nil || !locked {
+		return fmt.Errorf("failed to lock repository mirror: %w", err)
+	}
+	defer fileLock.Unlock() //nolint:errcheck
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove repository mirror: %w", err)
+	}
+	return nil
+}
+
 // GetCurrentCommit returns the HEAD commit hash of a cloned repository
 func (c *Client) GetCurrentCommit(ctx context.Context, repoPath string) (string, error) {
 	if err := ctx.Err(); err != nil {
@@ -279,13 +501,14 @@ func (c *Client) GetCurrentCommit(ctx context.Context, repoPath string) (string,
 	return ref.Hash().String(), nil
 }
 
-// BranchInfo holds information about a git branch
+// BranchInfo holds information about a git branch or tag
 type BranchInfo struct {
 	Name      string
 	IsDefault bool
+	IsTag     bool
 }
 
-// ListBranches lists all branches in a remote repository
+// ListBranches lists all branches in a remote repository, followed by its tags
 func (c *Client) ListBranches(ctx context.Context, url string, auth AuthConfig) ([]BranchInfo, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -328,30 +551,43 @@ func (c *Client) ListBranches(ctx context.Context, url string, auth AuthConfig)
 		}
 	}
 
-	// Collect all branches
+	// Collect all branches and tags
 	seen := make(map[string]bool)
 	for _, ref := range refs {
-		if ref.Name().IsBranch() {
-			branchName := ref.Name().Short()
-			if seen[branchName] {
-				continue
-			}
-			seen[branchName] = true
-
-			branches = append(branches, BranchInfo{
-				Name:      branchName,
-				IsDefault: branchName == defaultBranch,
-			})
+		isTag := ref.Name().IsTag()
+		if !ref.Name().IsBranch() && !isTag {
+			continue
 		}
+		// Skip the peeled entries (v1.0^{}) listed for annotated tags
+		name := strings.TrimSuffix(ref.Name().Short(), "^{}")
+		key := ref.Name().String()
+		if isTag {
+			key = "refs/tags/" + name
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		branches = append(branches, BranchInfo{
+			Name:      name,
+			IsDefault: !isTag && name == defaultBranch,
+			IsTag:     isTag,
+		})
 	}
 
-	// Sort branches with default first
+	// Sort branches with default first, then tags with the newest version first
 	sort.Slice(branches, func(i, j int) bool {
-		if branches[i].IsDefault {
-			return true
+		if branches[i].IsTag != branches[j].IsTag {
+			return !branches[i].IsTag
+		}
+		if branches[i].IsDefault != branches[j].IsDefault {
+			return branches[i].IsDefault
 		}
-		if branches[j].IsDefault {
-			return false
+		if branches[i].IsTag {
+			if cmp := version.Compare(branches[i].Name, branches[j].Name); cmp != 0 {
+				return cmp > 0
+			}
 		}
 		return branches[i].Name < branches[j].Name
 	})
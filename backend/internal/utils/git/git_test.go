@@ -1,13 +1,19 @@
 package git
 
 import (
+	"context"
 	"net"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	gossh "golang.org/x/crypto/ssh"
 )
 
@@ -339,3 +345,166 @@ func generateTestPublicKeyVariant(t *testing.T) gossh.PublicKey {
 	}
 	return key
 }
+
+func TestMirrorDir(t *testing.T) {
+	client := NewClient("/data/git")
+
+	dir := client.mirrorDir("https://example.com/repo.git", BranchRef("main"))
+	if filepath.Dir(dir) != filepath.Join("/data/git", "mirrors") {
+		t.Errorf("expected mirror under work dir, got %s", dir)
+	}
+	if dir != client.mirrorDir("https://example.com/repo.git", BranchRef("main")) {
+		t.Error("expected mirror dir to be stable")
+	}
+	if dir == client.mirrorDir("https://example.com/repo.git", BranchRef("develop")) {
+		t.Error("expected different branches to use different mirrors")
+	}
+	if dir == client.mirrorDir("https://example.com/other.git", BranchRef("main")) {
+		t.Error("expected different repositories to use different mirrors")
+	}
+}
+
+func TestMirror(t *testing.T) {
+	// The file transport shells out to git-upload-pack.
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	ctx := context.Background()
+	srcDir := t.TempDir()
+	src, err := git.PlainInitWithOptions(srcDir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	if err != nil {
+		t.Fatalf("failed to init source repo: %v", err)
+	}
+	first := commitTestFile(t, src, srcDir, "compose.yaml", "services: {}\n")
+
+	client := NewClient(t.TempDir())
+	checkout, release, err := client.Mirror(ctx, srcDir, BranchRef("main"), AuthConfig{})
+	if err != nil {
+		t.Fatalf("Mirror() error = %v", err)
+	}
+	path := checkout.Dir
+	if got, _ := client.GetCurrentCommit(ctx, path); got != first {
+		t.Errorf("expected commit %s, got %s", first, got)
+	}
+	if checkout.Commit != first || checkout.Ref != "main" {
+		t.Errorf("expected checkout of main at %s, got %+v", first, checkout)
+	}
+	// Stray files in the checkout are discarded on the next update.
+	if err := os.WriteFile(filepath.Join(path, "stray.txt"), []byte("x"), 0600); err != nil {
+		t.Fatalf("failed to write stray file: %v", err)
+	}
+	release()
+
+	second := commitTestFile(t, src, srcDir, "compose.yaml", "services:\n  web:\n    image: nginx\n")
+
+	checkout2, release2, err := client.Mirror(ctx, srcDir, BranchRef("main"), AuthConfig{})
+	if err != nil {
+		t.Fatalf("Mirror() second call error = %v", err)
+	}
+	defer release2()
+	path2 := checkout2.Dir
+
+	if path2 != path {
+		t.Errorf("expected mirror to be reused, got %s and %s", path, path2)
+	}
+	if got, _ := client.GetCurrentCommit(ctx, path2); got != second {
+		t.Errorf("expected commit %s after fetch, got %s", second, got)
+	}
+	if content, _ := client.ReadFile(ctx, path2, "compose.yaml"); !strings.Contains(content, "nginx") {
+		t.Errorf("expected updated compose file, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(path2, "stray.txt")); !os.IsNotExist(err) {
+		t.Error("expected stray file to be removed")
+	}
+}
+
+func TestMirrorRefSelectors(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	ctx := context.Background()
+	srcDir := t.TempDir()
+	src, err := git.PlainInitWithOptions(srcDir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	if err != nil {
+		t.Fatalf("failed to init source repo: %v", err)
+	}
+
+	tag := func(name, commit string) {
+		t.Helper()
+		if _, err := src.CreateTag(name, plumbing.NewHash(commit), nil); err != nil {
+			t.Fatalf("failed to create tag %s: %v", name, err)
+		}
+	}
+	v1 := commitTestFile(t, src, srcDir, "compose.yaml", "# v1.0.0\n")
+	tag("v1.0.0", v1)
+	v19 := commitTestFile(t, src, srcDir, "compose.yaml", "# v1.9.0\n")
+	tag("v1.9.0", v19)
+	v110 := commitTestFile(t, src, srcDir, "compose.yaml", "# v1.10.0\n")
+	tag("v1.10.0", v110)
+	v2 := commitTestFile(t, src, srcDir, "compose.yaml", "# v2.0.0\n")
+	tag("v2.0.0", v2)
+	head := commitTestFile(t, src, srcDir, "compose.yaml", "# main\n")
+
+	tests := []struct {
+		name       string
+		ref        RefSelector
+		wantRef    string
+		wantCommit string
+	}{
+		{name: "branch", ref: BranchRef("main"), wantRef: "main", wantCommit: head},
+		{name: "tag pattern", ref: RefSelector{Type: RefTypeTag, Pattern: "v1.*"}, wantRef: "v1.10.0", wantCommit: v110},
+		{name: "semver", ref: RefSelector{Type: RefTypeSemver, Pattern: ">=1.0, <1.10"}, wantRef: "v1.9.0", wantCommit: v19},
+		{name: "semver caret", ref: RefSelector{Type: RefTypeSemver, Pattern: "^2"}, wantRef: "v2.0.0", wantCommit: v2},
+		{name: "commit", ref: RefSelector{Type: RefTypeCommit, Branch: "main", Pattern: v1[:12]}, wantRef: v1, wantCommit: v1},
+	}
+
+	client := NewClient(t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkout, release, err := client.Mirror(ctx, srcDir, tt.ref, AuthConfig{})
+			if err != nil {
+				t.Fatalf("Mirror() error = %v", err)
+			}
+			defer release()
+
+			if checkout.Ref != tt.wantRef || checkout.Commit != tt.wantCommit {
+				t.Errorf("Mirror() resolved %s at %s, want %s at %s", checkout.Ref, checkout.Commit, tt.wantRef, tt.wantCommit)
+			}
+			if got, _ := client.GetCurrentCommit(ctx, checkout.Dir); got != tt.wantCommit {
+				t.Errorf("expected HEAD at %s, got %s", tt.wantCommit, got)
+			}
+		})
+	}
+
+	if _, _, err := client.Mirror(ctx, srcDir, RefSelector{Type: RefTypeTag, Pattern: "v3.*"}, AuthConfig{}); err == nil {
+		t.Error("expected an error when no tag matches")
+	}
+}
+
+func commitTestFile(t *testing.T, repo *git.Repository, dir, name, content string) string {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to open worktree: %v", err)
+	}
+	if _, err := worktree.Add(name); err != nil {
+		t.Fatalf("failed to add %s: %v", name, err)
+	}
+	hash, err := worktree.Commit("update "+name, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	return hash.String()
+}
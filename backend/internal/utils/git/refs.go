@@ -0,0 +1,172 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/getarcaneapp/arcane/backend/internal/utils/version"
+)
+
+// RefType selects how a sync picks the commit it checks out
+type RefType string
+
+const (
+	RefTypeBranch RefType = "branch" // head of a branch
+	RefTypeTag    RefType = "tag"    // newest tag matching a glob pattern, e.g. v1.*
+	RefTypeSemver RefType = "semver" // highest tag satisfying a semver constraint, e.g. ^1.2
+	RefTypeCommit RefType = "commit" // a fixed commit SHA
+)
+
+var commitSHARe = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// RefSelector describes which commit of a repository to check out
+type RefSelector struct {
+	Type RefType
+	// Branch is the branch to track for branch selectors. For commit selectors it is the
+	// branch the commit is fetched from; when empty all branches are fetched.
+	Branch string
+	// Pattern is the tag glob, semver constraint or commit SHA, depending on Type.
+	Pattern string
+}
+
+// BranchRef returns a selector tracking the head of branch
+func BranchRef(branch string) RefSelector {
+	return RefSelector{Type: RefTypeBranch, Branch: branch}
+}
+
+// Validate checks that the selector is complete and its pattern parses
+func (r RefSelector) Validate() error {
+	switch r.Type {
+	case RefTypeBranch, "":
+		if r.Branch == "" {
+			return errors.New("branch is required")
+		}
+	case RefTypeTag:
+		if r.Pattern == "" {
+			return errors.New("tag pattern is required")
+		}
+		if _, err := path.Match(r.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid tag pattern %q: %w", r.Pattern, err)
+		}
+	case RefTypeSemver:
+		if _, err := ParseSemverConstraint(r.Pattern); err != nil {
+			return err
+		}
+	case RefTypeCommit:
+		if !commitSHARe.MatchString(r.Pattern) {
+			return fmt.Errorf("invalid commit SHA %q", r.Pattern)
+		}
+	default:
+		return fmt.Errorf("unknown ref type %q", r.Type)
+	}
+	return nil
+}
+
+// String describes the selector for messages, e.g. "tag v1.*"
+func (r RefSelector) String() string {
+	switch r.Type {
+	case RefTypeTag:
+		return "tag " + r.Pattern
+	case RefTypeSemver:
+		return "semver " + r.Pattern
+	case RefTypeCommit:
+		return "commit " + r.Pattern
+	default:
+		return "branch " + r.Branch
+	}
+}
+
+// MatchesPush reports whether a push of the full ref name can change what the selector
+// resolves to. Commit selectors never move.
+func (r RefSelector) MatchesPush(ref string) bool {
+	switch r.Type {
+	case RefTypeTag, RefTypeSemver:
+		name, ok := strings.CutPrefix(ref, "refs/tags/")
+		return ok && r.matchesTag(name)
+	case RefTypeCommit:
+		return false
+	default:
+		return ref == plumbing.NewBranchReferenceName(r.Branch).String()
+	}
+}
+
+// matchesTag reports whether a tag is a candidate for tag and semver selectors
+func (r RefSelector) matchesTag(name string) bool {
+	switch r.Type {
+	case RefTypeTag:
+		ok, err := path.Match(r.Pattern, name)
+		return err == nil && ok
+	case RefTypeSemver:
+		constraint, err := ParseSemverConstraint(r.Pattern)
+		return err == nil && constraint.Matches(name)
+	default:
+		return false
+	}
+}
+
+// SharesMirror reports whether two selectors on the same repository use the same mirror
+func (r RefSelector) SharesMirror(other RefSelector) bool {
+	return r.mirrorKey() == other.mirrorKey()
+}
+
+// mirrorKey identifies the refs a mirror fetches, so selectors that fetch the same refs
+// share a mirror
+func (r RefSelector) mirrorKey() string {
+	switch r.Type {
+	case RefTypeTag, RefTypeSemver:
+		return "refs/tags/*"
+	case RefTypeCommit:
+		if r.Branch == "" {
+			return "refs/heads/*"
+		}
+		return r.Branch
+	default:
+		return r.Branch
+	}
+}
+
+// refSpecs returns the refspecs a mirror fetches for the selector
+func (r RefSelector) refSpecs() []config.RefSpec {
+	switch key := r.mirrorKey(); key {
+	case "refs/tags/*":
+		return []config.RefSpec{"+refs/tags/*:refs/tags/*"}
+	case "refs/heads/*":
+		return []config.RefSpec{"+refs/heads/*:refs/remotes/origin/*"}
+	default:
+		return []config.RefSpec{config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", key, key))}
+	}
+}
+
+// SemverConstraint is a parsed version constraint such as ">=1.2, <2", "^1.4" or
+// "~1.2.3 || 2.x". Prerelease tags only match constraints that name a prerelease, so "^1.2"
+// doesn't pick up 1.3.0-rc.1.
+type SemverConstraint struct {
+	constraints *semver.Constraints
+}
+
+// ParseSemverConstraint parses a semver constraint
+func ParseSemverConstraint(s string) (*SemverConstraint, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("semver constraint is required")
+	}
+	constraints, err := semver.NewConstraint(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid semver constraint %q: %w", s, err)
+	}
+	return &SemverConstraint{constraints: constraints}, nil
+}
+
+// Matches reports whether a version or tag name (with or without v prefix) satisfies the
+// constraint
+func (c *SemverConstraint) Matches(tag string) bool {
+	v, ok := version.Parse(tag)
+	return ok && c.constraints.Check(v)
+}
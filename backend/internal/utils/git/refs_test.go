@@ -0,0 +1,103 @@
+package git
+
+import "testing"
+
+func TestSemverConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matches    []string
+		rejects    []string
+	}{
+		{constraint: ">=1.2.0, <2", matches: []string{"v1.2.0", "1.9.9"}, rejects: []string{"v1.1.9", "v2.0.0"}},
+		{constraint: ">= 1.2 < 2", matches: []string{"v1.2.0"}, rejects: []string{"v2.0.0"}},
+		{constraint: "^1.4", matches: []string{"v1.4.0", "v1.99.1"}, rejects: []string{"v1.3.9", "v2.0.0"}},
+		{constraint: "^0.3.1", matches: []string{"v0.3.1", "v0.3.9"}, rejects: []string{"v0.4.0"}},
+		{constraint: "^0.0.3", matches: []string{"v0.0.3"}, rejects: []string{"v0.0.4"}},
+		{constraint: "~1.2.3", matches: []string{"v1.2.3", "v1.2.9"}, rejects: []string{"v1.3.0"}},
+		{constraint: "~1", matches: []string{"v1.0.0", "v1.9.0"}, rejects: []string{"v2.0.0"}},
+		{constraint: "1.2.x", matches: []string{"v1.2.0", "v1.2.7"}, rejects: []string{"v1.3.0"}},
+		{constraint: "1.2.3", matches: []string{"v1.2.3", "1.2.3"}, rejects: []string{"v1.2.4"}},
+		{constraint: ">1.2", matches: []string{"v1.3.0"}, rejects: []string{"v1.2.5"}},
+		{constraint: "<=1.2", matches: []string{"v1.2.5"}, rejects: []string{"v1.3.0"}},
+		{constraint: "1.x || >=3", matches: []string{"v1.5.0", "v3.1.0"}, rejects: []string{"v2.0.0"}},
+		{constraint: "*", matches: []string{"v0.0.1", "v10.0.0"}, rejects: []string{"latest", "v1.0.0-rc.1"}},
+		{constraint: ">=1.0.0-rc.1", matches: []string{"v1.0.0-rc.2", "v1.0.0"}, rejects: []string{"v1.0.0-beta.1"}},
+		{constraint: "^1", matches: []string{"v1.5.0"}, rejects: []string{"v1.6.0-rc.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			constraint, err := ParseSemverConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseSemverConstraint() error = %v", err)
+			}
+			for _, v := range tt.matches {
+				if !constraint.Matches(v) {
+					t.Errorf("expected %s to match %s", v, tt.constraint)
+				}
+			}
+			for _, v := range tt.rejects {
+				if constraint.Matches(v) {
+					t.Errorf("expected %s not to match %s", v, tt.constraint)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSemverConstraintInvalid(t *testing.T) {
+	for _, constraint := range []string{"", "abc", ">=1.2.3.4", ">=1.0 || ", "1.2 <<3"} {
+		if _, err := ParseSemverConstraint(constraint); err == nil {
+			t.Errorf("expected ParseSemverConstraint(%q) to fail", constraint)
+		}
+	}
+}
+
+func TestRefSelectorValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     RefSelector
+		wantErr bool
+	}{
+		{name: "branch", ref: BranchRef("main")},
+		{name: "branch missing", ref: BranchRef(""), wantErr: true},
+		{name: "tag", ref: RefSelector{Type: RefTypeTag, Pattern: "v1.*"}},
+		{name: "tag bad pattern", ref: RefSelector{Type: RefTypeTag, Pattern: "v1.["}, wantErr: true},
+		{name: "semver", ref: RefSelector{Type: RefTypeSemver, Pattern: "^1.2"}},
+		{name: "semver bad", ref: RefSelector{Type: RefTypeSemver, Pattern: "latest"}, wantErr: true},
+		{name: "commit", ref: RefSelector{Type: RefTypeCommit, Pattern: "0123abc"}},
+		{name: "commit bad", ref: RefSelector{Type: RefTypeCommit, Pattern: "main"}, wantErr: true},
+		{name: "unknown type", ref: RefSelector{Type: "release", Pattern: "x"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.ref.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefSelectorMatchesPush(t *testing.T) {
+	tests := []struct {
+		ref  RefSelector
+		push string
+		want bool
+	}{
+		{ref: BranchRef("main"), push: "refs/heads/main", want: true},
+		{ref: BranchRef("main"), push: "refs/tags/main", want: false},
+		{ref: RefSelector{Type: RefTypeTag, Pattern: "v1.*"}, push: "refs/tags/v1.4.0", want: true},
+		{ref: RefSelector{Type: RefTypeTag, Pattern: "v1.*"}, push: "refs/tags/v2.0.0", want: false},
+		{ref: RefSelector{Type: RefTypeTag, Pattern: "v1.*"}, push: "refs/heads/v1.x", want: false},
+		{ref: RefSelector{Type: RefTypeSemver, Pattern: "^1"}, push: "refs/tags/1.2.0", want: true},
+		{ref: RefSelector{Type: RefTypeSemver, Pattern: "^1"}, push: "refs/tags/v2.0.0", want: false},
+		{ref: RefSelector{Type: RefTypeCommit, Branch: "main", Pattern: "0123abc"}, push: "refs/heads/main", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.ref.MatchesPush(tt.push); got != tt.want {
+			t.Errorf("%s MatchesPush(%q) = %v, want %v", tt.ref, tt.push, got, tt.want)
+		}
+	}
+}
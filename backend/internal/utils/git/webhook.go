@@ -0,0 +1,209 @@
+package git
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookProvider identifies the git host that sent a webhook.
+type WebhookProvider string
+
+const (
+	WebhookProviderGitHub WebhookProvider = "github"
+	WebhookProviderGitea  WebhookProvider = "gitea"
+	WebhookProviderGitLab WebhookProvider = "gitlab"
+)
+
+var (
+	// ErrWebhookSignatureMissing is returned when a request carries none of the supported signature headers.
+	ErrWebhookSignatureMissing = errors.New("webhook signature missing")
+	// ErrWebhookSignatureInvalid is returned when the signature does not match the payload.
+	ErrWebhookSignatureInvalid = errors.New("webhook signature invalid")
+	// ErrWebhookTimestampInvalid is returned when a signed timestamp is missing or too far from now.
+	ErrWebhookTimestampInvalid = errors.New("webhook timestamp invalid or too old")
+)
+
+// webhookTimestampTolerance is how far a signed webhook timestamp may be from now, which
+// bounds how long a captured delivery can be replayed.
+const webhookTimestampTolerance = 5 * time.Minute
+
+// WebhookHeaders holds the provider specific headers used to authenticate and classify a webhook.
+type WebhookHeaders struct {
+	// GitHub
+	HubSignature256 string // X-Hub-Signature-256: sha256=<hex>
+	GitHubEvent     string // X-GitHub-Event
+
+	// Gitea / Forgejo / Gogs
+	GiteaSignature string // X-Gitea-Signature: <hex>
+	GogsSignature  string // X-Gogs-Signature: <hex>
+	GiteaEvent     string // X-Gitea-Event
+	GogsEvent      string // X-Gogs-Event
+
+	// GitLab
+	GitlabToken      string // X-Gitlab-Token: <secret>
+	GitlabEvent      string // X-Gitlab-Event
+	WebhookID        string // webhook-id (GitLab signing tokens, Standard Webhooks)
+	WebhookTime      string // webhook-timestamp
+	WebhookSignature string // webhook-signature: v1,<base64>
+}
+
+// PushEvent is the subset of a push payload needed to decide whether a sync should run.
+type PushEvent struct {
+	Provider WebhookProvider
+	// Event is the provider's event name (push, ping, Push Hook, ...).
+	Event string
+	// IsPush reports whether the event describes pushed commits.
+	IsPush bool
+	// Ref is the full ref that was pushed, e.g. refs/heads/main.
+	Ref string
+	// Branch is Ref with the refs/heads/ prefix removed. Empty for tag pushes.
+	Branch string
+	// After is the commit the ref points to after the push.
+	After string
+}
+
+// VerifyWebhook checks the request against secret using whichever signature scheme the
+// sending provider uses, and returns that provider.
+func VerifyWebhook(h WebhookHeaders, body []byte, secret string) (WebhookProvider, error) {
+	if secret == "" {
+		return "", ErrWebhookSignatureInvalid
+	}
+
+	switch {
+	case h.HubSignature256 != "":
+		sig, ok := strings.CutPrefix(h.HubSignature256, "sha256=")
+		if !ok || !hmacHexEqual(body, secret, sig) {
+			return WebhookProviderGitHub, ErrWebhookSignatureInvalid
+		}
+		return WebhookProviderGitHub, nil
+
+	case h.GiteaSignature != "" || h.GogsSignature != "":
+		sig := h.GiteaSignature
+		if sig == "" {
+			sig = h.GogsSignature
+		}
+		if !hmacHexEqual(body, secret, sig) {
+			return WebhookProviderGitea, ErrWebhookSignatureInvalid
+		}
+		return WebhookProviderGitea, nil
+
+	case h.WebhookSignature != "":
+		if !verifyStandardWebhook(h, body, secret) {
+			return WebhookProviderGitLab, ErrWebhookSignatureInvalid
+		}
+		if !webhookTimestampFresh(h.WebhookTime, time.Now()) {
+			return WebhookProviderGitLab, ErrWebhookTimestampInvalid
+		}
+		return WebhookProviderGitLab, nil
+
+	case h.GitlabToken != "":
+		if subtle.ConstantTimeCompare([]byte(h.GitlabToken), []byte(secret)) != 1 {
+			return WebhookProviderGitLab, ErrWebhookSignatureInvalid
+		}
+		return WebhookProviderGitLab, nil
+	}
+
+	return "", ErrWebhookSignatureMissing
+}
+
+// ParsePushEvent extracts the pushed ref from a verified webhook payload.
+func ParsePushEvent(provider WebhookProvider, h WebhookHeaders, body []byte) (*PushEvent, error) {
+	evt := &PushEvent{Provider: provider}
+
+	switch provider {
+	case WebhookProviderGitHub:
+		evt.Event = h.GitHubEvent
+		evt.IsPush = h.GitHubEvent == "push"
+	case WebhookProviderGitea:
+		evt.Event = h.GiteaEvent
+		if evt.Event == "" {
+			evt.Event = h.GogsEvent
+		}
+		evt.IsPush = evt.Event == "push"
+	case WebhookProviderGitLab:
+		evt.Event = h.GitlabEvent
+		evt.IsPush = h.GitlabEvent == "Push Hook"
+	}
+
+	if !evt.IsPush {
+		return evt, nil
+	}
+
+	var payload struct {
+		Ref   string `json:"ref"`
+		After string `json:"after"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse push payload: %w", err)
+	}
+
+	evt.Ref = payload.Ref
+	evt.After = payload.After
+	evt.Branch, _ = strings.CutPrefix(payload.Ref, "refs/heads/")
+	if evt.Branch == payload.Ref {
+		evt.Branch = ""
+	}
+	return evt, nil
+}
+
+func hmacHexEqual(body []byte, secret, signature string) bool {
+	want, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), want)
+}
+
+// webhookTimestampFresh reports whether a Standard Webhooks timestamp (Unix seconds) is within
+// webhookTimestampTolerance of now.
+func webhookTimestampFresh(timestamp string, now time.Time) bool {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return false
+	}
+	diff := now.Sub(time.Unix(seconds, 0))
+	return diff <= webhookTimestampTolerance && diff >= -webhookTimestampTolerance
+}
+
+// verifyStandardWebhook checks a Standard Webhooks signature, which GitLab sends when the
+// hook is configured with a signing token. The header may hold several space separated
+// "v1,<base64>" signatures; any match is accepted.
+func verifyStandardWebhook(h WebhookHeaders, body []byte, secret string) bool {
+	key := []byte(secret)
+	if raw, ok := strings.CutPrefix(secret, "whsec_"); ok {
+		if decoded, err := base64.StdEncoding.DecodeString(raw); err == nil {
+			key = decoded
+		}
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(h.WebhookID + "." + h.WebhookTime + "."))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	for _, part := range strings.Fields(h.WebhookSignature) {
+		version, sig, ok := strings.Cut(part, ",")
+		if !ok || version != "v1" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(sig)
+		if err != nil {
+			continue
+		}
+		if hmac.Equal(decoded, expected) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package git
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testWebhookSecret = "s3cr3t"
+
+var testPushBody = []byte(`{"ref":"refs/heads/main","after":"0123456789abcdef"}`)
+
+func signHex(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	validSig := signHex(testPushBody, testWebhookSecret)
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	future := strconv.FormatInt(time.Now().Add(10*time.Minute).Unix(), 10)
+	signStandard := func(timestamp string) string {
+		mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+		mac.Write([]byte("msg_1." + timestamp + "."))
+		mac.Write(testPushBody)
+		return "v1," + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	stdSig := signStandard(now)
+
+	tests := []struct {
+		name         string
+		headers      WebhookHeaders
+		secret       string
+		wantProvider WebhookProvider
+		wantErr      error
+	}{
+		{
+			name:         "github valid",
+			headers:      WebhookHeaders{HubSignature256: "sha256=" + validSig},
+			secret:       testWebhookSecret,
+			wantProvider: WebhookProviderGitHub,
+		},
+		{
+			name:         "github wrong secret",
+			headers:      WebhookHeaders{HubSignature256: "sha256=" + signHex(testPushBody, "other")},
+			secret:       testWebhookSecret,
+			wantProvider: WebhookProviderGitHub,
+			wantErr:      ErrWebhookSignatureInvalid,
+		},
+		{
+			name:         "github missing prefix",
+			headers:      WebhookHeaders{HubSignature256: validSig},
+			secret:       testWebhookSecret,
+			wantProvider: WebhookProviderGitHub,
+			wantErr:      ErrWebhookSignatureInvalid,
+		},
+		{
+			name:         "gitea valid",
+			headers:      WebhookHeaders{GiteaSignature: validSig},
+			secret:       testWebhookSecret,
+			wantProvider: WebhookProviderGitea,
+		},
+		{
+			name:         "gogs valid",
+			headers:      WebhookHeaders{GogsSignature: validSig},
+			secret:       testWebhookSecret,
+			wantProvider: WebhookProviderGitea,
+		},
+		{
+			name:         "gitea not hex",
+			headers:      WebhookHeaders{GiteaSignature: "zz"},
+			secret:       testWebhookSecret,
+			wantProvider: WebhookProviderGitea,
+			wantErr:      ErrWebhookSignatureInvalid,
+		},
+		{
+			name:         "gitlab token valid",
+			headers:      WebhookHeaders{GitlabToken: testWebhookSecret},
+			secret:       testWebhookSecret,
+			wantProvider: WebhookProviderGitLab,
+		},
+		{
+			name:         "gitlab token invalid",
+			headers:      WebhookHeaders{GitlabToken: "nope"},
+			secret:       testWebhookSecret,
+			wantProvider: WebhookProviderGitLab,
+			wantErr:      ErrWebhookSignatureInvalid,
+		},
+		{
+			name:         "gitlab signing token valid",
+			headers:      WebhookHeaders{WebhookID: "msg_1", WebhookTime: now, WebhookSignature: "v1,bm9wZQ== " + stdSig},
+			secret:       testWebhookSecret,
+			wantProvider: WebhookProviderGitLab,
+		},
+		{
+			name:         "gitlab signing token tampered timestamp",
+			headers:      WebhookHeaders{WebhookID: "msg_1", WebhookTime: stale, WebhookSignature: stdSig},
+			secret:       testWebhookSecret,
+			wantProvider: WebhookProviderGitLab,
+			wantErr:      ErrWebhookSignatureInvalid,
+		},
+		{
+			name:         "gitlab signing token replayed",
+			headers:      WebhookHeaders{WebhookID: "msg_1", WebhookTime: stale, WebhookSignature: signStandard(stale)},
+			secret:       testWebhookSecret,
+			wantProvider: WebhookProviderGitLab,
+			wantErr:      ErrWebhookTimestampInvalid,
+		},
+		{
+			name:         "gitlab signing token from the future",
+			headers:      WebhookHeaders{WebhookID: "msg_1", WebhookTime: future, WebhookSignature: signStandard(future)},
+			secret:       testWebhookSecret,
+			wantProvider: WebhookProviderGitLab,
+			wantErr:      ErrWebhookTimestampInvalid,
+		},
+		{
+			name:    "no signature",
+			headers: WebhookHeaders{GitHubEvent: "push"},
+			secret:  testWebhookSecret,
+			wantErr: ErrWebhookSignatureMissing,
+		},
+		{
+			name:    "empty secret never verifies",
+			headers: WebhookHeaders{GitlabToken: ""},
+			secret:  "",
+			wantErr: ErrWebhookSignatureInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := VerifyWebhook(tt.headers, testPushBody, tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if provider != tt.wantProvider {
+				t.Errorf("expected provider %q, got %q", tt.wantProvider, provider)
+			}
+		})
+	}
+}
+
+func TestParsePushEvent(t *testing.T) {
+	t.Run("github push", func(t *testing.T) {
+		evt, err := ParsePushEvent(WebhookProviderGitHub, WebhookHeaders{GitHubEvent: "push"}, testPushBody)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !evt.IsPush || evt.Branch != "main" || evt.After != "0123456789abcdef" {
+			t.Errorf("unexpected event: %+v", evt)
+		}
+	})
+
+	t.Run("github ping is not a push", func(t *testing.T) {
+		evt, err := ParsePushEvent(WebhookProviderGitHub, WebhookHeaders{GitHubEvent: "ping"}, []byte(`{"zen":"hi"}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if evt.IsPush {
+			t.Errorf("expected ping to be ignored, got %+v", evt)
+		}
+	})
+
+	t.Run("gitlab push hook", func(t *testing.T) {
+		evt, err := ParsePushEvent(WebhookProviderGitLab, WebhookHeaders{GitlabEvent: "Push Hook"}, []byte(`{"ref":"refs/heads/feature/x"}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !evt.IsPush || evt.Branch != "feature/x" {
+			t.Errorf("unexpected event: %+v", evt)
+		}
+	})
+
+	t.Run("gogs push", func(t *testing.T) {
+		evt, err := ParsePushEvent(WebhookProviderGitea, WebhookHeaders{GogsEvent: "push"}, testPushBody)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !evt.IsPush || evt.Event != "push" || evt.Branch != "main" {
+			t.Errorf("unexpected event: %+v", evt)
+		}
+	})
+
+	t.Run("tag push has no branch", func(t *testing.T) {
+		evt, err := ParsePushEvent(WebhookProviderGitea, WebhookHeaders{GiteaEvent: "push"}, []byte(`{"ref":"refs/tags/v1.0.0"}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if evt.Branch != "" || evt.Ref != "refs/tags/v1.0.0" {
+			t.Errorf("unexpected event: %+v", evt)
+		}
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		if _, err := ParsePushEvent(WebhookProviderGitea, WebhookHeaders{GiteaEvent: "push"}, []byte(`not json`)); err == nil {
+			t.Error("expected error for invalid payload")
+		}
+	})
+}
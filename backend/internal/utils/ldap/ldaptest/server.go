@@ -0,0 +1,475 @@
+// Package ldaptest provides an in-process directory server that stands in for an
+// OpenLDAP or glauth container in tests. It supports simple binds, searches and StartTLS.
+package ldaptest
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Entry is a directory entry. Entries with a Password can bind.
+type Entry struct {
+	DN         string
+	Password   string
+	Attributes map[string][]string
+}
+
+// Server is a directory server listening on the loopback interface.
+type Server struct {
+	// URL is the ldap:// URL of the plain listener, which supports StartTLS.
+	URL string
+	// TLSURL is the ldaps:// URL of the TLS listener.
+	TLSURL string
+
+	entries   []Entry
+	tlsConfig *tls.Config
+	cert      *x509.Certificate
+
+	listeners []net.Listener
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+// NewServer starts a server with the given entries.
+func NewServer(entries ...Entry) (*Server, error) {
+	tlsConfig, cert, err := selfSignedInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	secure, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	if err != nil {
+		_ = plain.Close()
+		return nil, err
+	}
+
+	s := &Server{
+		URL:       "ldap://" + plain.Addr().String(),
+		TLSURL:    "ldaps://" + secure.Addr().String(),
+		entries:   entries,
+		tlsConfig: tlsConfig,
+		cert:      cert,
+		listeners: []net.Listener{plain, secure},
+		conns:     map[net.Conn]struct{}{},
+	}
+	for _, l := range s.listeners {
+		s.wg.Add(1)
+		go s.acceptInternal(l)
+	}
+	return s, nil
+}
+
+// CertPool returns a pool that trusts the server certificate.
+func (s *Server) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(s.cert)
+	return pool
+}
+
+// Close stops the listeners and closes open connections.
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+	for _, l := range s.listeners {
+		_ = l.Close()
+	}
+	for c := range s.conns {
+		_ = c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) acceptInternal(l net.Listener) {
+	defer s.wg.Done()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveInternal(conn)
+		}()
+	}
+}
+
+// startTLSOID is the name of the StartTLS extended operation.
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// handshakeTimeout bounds the TLS handshake after StartTLS.
+const handshakeTimeout = 10 * time.Second
+
+type session struct {
+	conn   net.Conn
+	w      *bufio.Writer
+	bindDN string
+	bound  bool
+}
+
+func (s *Server) serveInternal(conn net.Conn) {
+	sess := &session{conn: conn, w: bufio.NewWriter(conn)}
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, sess.conn)
+		s.mu.Unlock()
+		_ = sess.conn.Close()
+	}()
+
+	for {
+		msg, err := ber.ReadPacket(sess.conn)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+		id, err := ber.ParseInt64(msg.Children[0].Data.Bytes())
+		if err != nil {
+			return
+		}
+		op := msg.Children[1]
+		if op.ClassType != ber.ClassApplication {
+			return
+		}
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			s.bindInternal(sess, id, op)
+		case ldap.ApplicationSearchRequest:
+			s.searchInternal(sess, id, op)
+		case ldap.ApplicationExtendedRequest:
+			if !s.startTLSInternal(sess, id, op) {
+				return
+			}
+		default:
+			// Unbind and unsupported operations end the session.
+			return
+		}
+		if err := sess.w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) bindInternal(sess *session, id int64, op *ber.Packet) {
+	sess.bound = false
+	if len(op.Children) != 3 || op.Children[2].ClassType != ber.ClassContext || op.Children[2].Tag != 0 {
+		writeResultInternal(sess, id, ldap.ApplicationBindResponse, ldap.LDAPResultProtocolError, "only simple binds are supported")
+		return
+	}
+	dn, password := stringInternal(op.Children[1]), stringInternal(op.Children[2])
+
+	for _, e := range s.entries {
+		if strings.EqualFold(e.DN, dn) && e.Password != "" && e.Password == password {
+			sess.bindDN, sess.bound = e.DN, true
+			writeResultInternal(sess, id, ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+			return
+		}
+	}
+	writeResultInternal(sess, id, ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials, "invalid credentials")
+}
+
+func (s *Server) searchInternal(sess *session, id int64, op *ber.Packet) {
+	if !sess.bound {
+		writeResultInternal(sess, id, ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights, "bind required")
+		return
+	}
+	if len(op.Children) != 8 {
+		writeResultInternal(sess, id, ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError, "malformed search")
+		return
+	}
+	baseDN := stringInternal(op.Children[0])
+	scope, _ := ber.ParseInt64(op.Children[1].Data.Bytes())
+	sizeLimit, _ := ber.ParseInt64(op.Children[3].Data.Bytes())
+	filter := op.Children[6]
+	var attrs []string
+	for _, a := range op.Children[7].Children {
+		attrs = append(attrs, stringInternal(a))
+	}
+
+	sent := int64(0)
+	for _, e := range s.entries {
+		if !inScopeInternal(e.DN, baseDN, int(scope)) || !matchInternal(e, filter) {
+			continue
+		}
+		if sizeLimit > 0 && sent >= sizeLimit {
+			writeResultInternal(sess, id, ldap.ApplicationSearchResultDone, ldap.LDAPResultSizeLimitExceeded, "")
+			return
+		}
+		writeMessageInternal(sess, id, entryPacketInternal(e, attrs))
+		sent++
+	}
+	writeResultInternal(sess, id, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess, "")
+}
+
+func (s *Server) startTLSInternal(sess *session, id int64, op *ber.Packet) bool {
+	if len(op.Children) == 0 || stringInternal(op.Children[0]) != startTLSOID {
+		writeResultInternal(sess, id, ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError, "unsupported extended operation")
+		return true
+	}
+	if _, ok := sess.conn.(*tls.Conn); ok {
+		writeResultInternal(sess, id, ldap.ApplicationExtendedResponse, ldap.LDAPResultOperationsError, "TLS already established")
+		return true
+	}
+	writeResultInternal(sess, id, ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess, "")
+	if err := sess.w.Flush(); err != nil {
+		return false
+	}
+
+	tlsConn := tls.Server(sess.conn, s.tlsConfig)
+	_ = tlsConn.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err := tlsConn.Handshake(); err != nil {
+		return false
+	}
+	_ = tlsConn.SetDeadline(time.Time{})
+
+	s.mu.Lock()
+	delete(s.conns, sess.conn)
+	s.conns[tlsConn] = struct{}{}
+	s.mu.Unlock()
+	sess.conn, sess.w = tlsConn, bufio.NewWriter(tlsConn)
+	return true
+}
+
+func inScopeInternal(dn, baseDN string, scope int) bool {
+	dn, baseDN = normalizeDNInternal(dn), normalizeDNInternal(baseDN)
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return dn == baseDN
+	case ldap.ScopeSingleLevel:
+		_, parent, ok := strings.Cut(dn, ",")
+		return ok && parent == baseDN
+	default:
+		return baseDN == "" || dn == baseDN || strings.HasSuffix(dn, ","+baseDN)
+	}
+}
+
+func normalizeDNInternal(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, p := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(p))
+	}
+	return strings.Join(parts, ",")
+}
+
+func valuesInternal(e Entry, attr string) []string {
+	if strings.EqualFold(attr, "dn") || strings.EqualFold(attr, "distinguishedName") {
+		return []string{e.DN}
+	}
+	for k, v := range e.Attributes {
+		if strings.EqualFold(k, attr) {
+			return v
+		}
+	}
+	return nil
+}
+
+// matchInternal evaluates an encoded filter with case-insensitive string matching, which is
+// how the usual user and group attributes compare.
+func matchInternal(e Entry, f *ber.Packet) bool {
+	switch f.Tag {
+	case ldap.FilterAnd:
+		for _, c := range f.Children {
+			if !matchInternal(e, c) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, c := range f.Children {
+			if matchInternal(e, c) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return len(f.Children) == 1 && !matchInternal(e, f.Children[0])
+	case ldap.FilterPresent:
+		return len(valuesInternal(e, stringInternal(f))) > 0
+	case ldap.FilterSubstrings:
+		if len(f.Children) != 2 {
+			return false
+		}
+		for _, v := range valuesInternal(e, stringInternal(f.Children[0])) {
+			if matchSubstringsInternal(strings.ToLower(v), f.Children[1].Children) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterExtensibleMatch:
+		// Extensible matches (e.g. Active Directory's LDAP_MATCHING_RULE_IN_CHAIN) compare
+		// as equality here.
+		var attr, value string
+		for _, c := range f.Children {
+			switch c.Tag {
+			case ldap.MatchingRuleAssertionType:
+				attr = stringInternal(c)
+			case ldap.MatchingRuleAssertionMatchValue:
+				value = stringInternal(c)
+			}
+		}
+		return containsFoldInternal(valuesInternal(e, attr), value)
+	}
+
+	// Equality, approximate and ordering matches have an attribute and a value.
+	if len(f.Children) != 2 {
+		return false
+	}
+	attr, value := stringInternal(f.Children[0]), stringInternal(f.Children[1])
+	switch f.Tag {
+	case ldap.FilterGreaterOrEqual, ldap.FilterLessOrEqual:
+		for _, v := range valuesInternal(e, attr) {
+			c := strings.Compare(strings.ToLower(v), strings.ToLower(value))
+			if (f.Tag == ldap.FilterGreaterOrEqual && c >= 0) || (f.Tag == ldap.FilterLessOrEqual && c <= 0) {
+				return true
+			}
+		}
+		return false
+	default:
+		return containsFoldInternal(valuesInternal(e, attr), value)
+	}
+}
+
+func matchSubstringsInternal(v string, parts []*ber.Packet) bool {
+	for _, p := range parts {
+		s := strings.ToLower(stringInternal(p))
+		switch p.Tag {
+		case ldap.FilterSubstringsInitial:
+			if !strings.HasPrefix(v, s) {
+				return false
+			}
+			v = v[len(s):]
+		case ldap.FilterSubstringsAny:
+			i := strings.Index(v, s)
+			if i < 0 {
+				return false
+			}
+			v = v[i+len(s):]
+		case ldap.FilterSubstringsFinal:
+			if !strings.HasSuffix(v, s) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func entryPacketInternal(e Entry, attrs []string) *ber.Packet {
+	all := len(attrs) == 0 || containsFoldInternal(attrs, "*")
+
+	list := ber.NewSequence("attributes")
+	for name, values := range e.Attributes {
+		if !all && !containsFoldInternal(attrs, name) {
+			continue
+		}
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "values")
+		for _, v := range values {
+			set.AppendChild(octetStringInternal(v))
+		}
+		attr := ber.NewSequence("attribute")
+		attr.AppendChild(octetStringInternal(name))
+		attr.AppendChild(set)
+		list.AppendChild(attr)
+	}
+
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "search result entry")
+	op.AppendChild(octetStringInternal(e.DN))
+	op.AppendChild(list)
+	return op
+}
+
+func containsFoldInternal(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringInternal returns the content of a primitive packet, whatever its class.
+func stringInternal(p *ber.Packet) string {
+	return string(p.Data.Bytes())
+}
+
+func octetStringInternal(s string) *ber.Packet {
+	return ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, s, "")
+}
+
+func writeResultInternal(sess *session, id int64, tag ber.Tag, code uint16, message string) {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "resultCode"))
+	op.AppendChild(octetStringInternal(""))
+	op.AppendChild(octetStringInternal(message))
+	writeMessageInternal(sess, id, op)
+}
+
+func writeMessageInternal(sess *session, id int64, op *ber.Packet) {
+	msg := ber.NewSequence("message")
+	msg.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "messageID"))
+	msg.AppendChild(op)
+	_, _ = sess.w.Write(msg.Bytes())
+}
+
+func selfSignedInternal() (*tls.Config, *x509.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "ldaptest"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, errors.New("ldaptest: failed to parse generated certificate")
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}},
+		MinVersion:   tls.VersionTLS12,
+	}, cert, nil
+}
@@ -0,0 +1,108 @@
+package ldaptest
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	srv, err := NewServer(
+		Entry{DN: "cn=admin,dc=example,dc=com", Password: "admin-secret"},
+		Entry{
+			DN:       "uid=jane,ou=people,dc=example,dc=com",
+			Password: "jane-secret",
+			Attributes: map[string][]string{
+				"objectClass": {"inetOrgPerson"},
+				"uid":         {"jane"},
+				"mail":        {"jane@example.com"},
+				"memberOf":    {"cn=admins,ou=groups,dc=example,dc=com"},
+			},
+		},
+		Entry{
+			DN:         "uid=john,ou=people,dc=example,dc=com",
+			Attributes: map[string][]string{"objectClass": {"inetOrgPerson"}, "uid": {"john"}},
+		},
+	)
+	require.NoError(t, err)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func searchInternal(conn *ldap.Conn, baseDN string, scope, sizeLimit int, filter string, attrs ...string) ([]*ldap.Entry, error) {
+	res, err := conn.Search(ldap.NewSearchRequest(baseDN, scope, ldap.NeverDerefAliases, sizeLimit, 0, false, filter, attrs, nil))
+	if res == nil {
+		return nil, err
+	}
+	return res.Entries, err
+}
+
+func TestServer_BindAndSearch(t *testing.T) {
+	srv := newTestServer(t)
+	conn, err := ldap.DialURL(srv.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = searchInternal(conn, "dc=example,dc=com", ldap.ScopeWholeSubtree, 0, "(uid=*)")
+	assert.True(t, ldap.IsErrorWithCode(err, ldap.LDAPResultInsufficientAccessRights))
+
+	assert.True(t, ldap.IsErrorWithCode(conn.Bind("cn=admin,dc=example,dc=com", "wrong"), ldap.LDAPResultInvalidCredentials))
+	require.NoError(t, conn.Bind("cn=admin,dc=example,dc=com", "admin-secret"))
+
+	entries, err := searchInternal(conn, "ou=people,dc=example,dc=com", ldap.ScopeWholeSubtree, 0,
+		"(&(objectClass=inetOrgPerson)(uid=JANE))", "mail", "memberOf")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "uid=jane,ou=people,dc=example,dc=com", entries[0].DN)
+	assert.Equal(t, "jane@example.com", entries[0].GetEqualFoldAttributeValue("MAIL"))
+	assert.Equal(t, []string{"cn=admins,ou=groups,dc=example,dc=com"}, entries[0].GetEqualFoldAttributeValues("memberof"))
+	assert.Empty(t, entries[0].GetEqualFoldAttributeValues("uid"))
+
+	entries, err = searchInternal(conn, "ou=people,dc=example,dc=com", ldap.ScopeSingleLevel, 0, "(uid=j*)")
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	_, err = searchInternal(conn, "dc=example,dc=com", ldap.ScopeWholeSubtree, 1, "(uid=*)")
+	assert.True(t, ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded))
+
+	// Escaped filter metacharacters match literally.
+	entries, err = searchInternal(conn, "dc=example,dc=com", ldap.ScopeWholeSubtree, 0, "(uid="+ldap.EscapeFilter("*")+")")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	entries, err = searchInternal(conn, "dc=example,dc=com", ldap.ScopeWholeSubtree, 0,
+		"(|(!(objectClass=inetOrgPerson))(memberOf:1.2.840.113556.1.4.1941:=cn=admins,ou=groups,dc=example,dc=com))")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// Entries without a password can't bind.
+	assert.True(t, ldap.IsErrorWithCode(conn.Bind("uid=john,ou=people,dc=example,dc=com", "x"), ldap.LDAPResultInvalidCredentials))
+}
+
+func TestServer_TLS(t *testing.T) {
+	srv := newTestServer(t)
+	trusted := &tls.Config{RootCAs: srv.CertPool(), ServerName: "127.0.0.1", MinVersion: tls.VersionTLS12}
+
+	conn, err := ldap.DialURL(srv.URL)
+	require.NoError(t, err)
+	_, isTLS := conn.TLSConnectionState()
+	assert.False(t, isTLS)
+	require.NoError(t, conn.StartTLS(trusted))
+	_, isTLS = conn.TLSConnectionState()
+	assert.True(t, isTLS)
+	require.NoError(t, conn.Bind("uid=jane,ou=people,dc=example,dc=com", "jane-secret"))
+	require.NoError(t, conn.Close())
+
+	conn, err = ldap.DialURL(srv.TLSURL, ldap.DialWithTLSConfig(trusted))
+	require.NoError(t, err)
+	require.NoError(t, conn.Bind("uid=jane,ou=people,dc=example,dc=com", "jane-secret"))
+	require.NoError(t, conn.Close())
+
+	// The self-signed certificate isn't trusted by default.
+	_, err = ldap.DialURL(srv.TLSURL)
+	require.Error(t, err)
+}
@@ -0,0 +1,182 @@
+// Package rbac defines the permissions of environment resources, the built-in roles and the
+// mapping from API routes to the permission they require.
+//
+// A permission is written "resource:action", e.g. "containers:restart". Either part may be
+// the wildcard "*", so "containers:*" grants every container action and "*:read" grants
+// read access to every resource.
+package rbac
+
+import (
+	"slices"
+	"strings"
+)
+
+// Wildcard matches any resource or action.
+const Wildcard = "*"
+
+// Resources of an environment that permissions are granted on.
+const (
+	ResourceContainers      = "containers"
+	ResourceImages          = "images"
+	ResourceProjects        = "projects"
+	ResourceVolumes         = "volumes"
+	ResourceNetworks        = "networks"
+	ResourceBuilds          = "builds"
+	ResourceGitOps          = "gitops"
+	ResourceVulnerabilities = "vulnerabilities"
+	ResourceUpdater         = "updater"
+	ResourceJobs            = "jobs"
+	ResourceSystem          = "system"
+	ResourceRegistries      = "registries"
+	ResourceNotifications   = "notifications"
+	ResourceSettings        = "settings"
+)
+
+// Common actions. Resources define further actions of their own in Catalog.
+const (
+	ActionRead   = "read"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Built-in role names.
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleDeployer = "deployer"
+	RoleAdmin    = "admin"
+
+	// RoleUser is the role users were given before roles had permissions. It grants what
+	// deployer grants.
+	RoleUser = "user"
+)
+
+// ResourceActions lists the actions of a resource.
+type ResourceActions struct {
+	Resource string
+	Actions  []string
+}
+
+// Catalog is every resource with the actions that can be granted on it.
+var Catalog = []ResourceActions{
+	{ResourceContainers, []string{ActionRead, ActionCreate, "start", "stop", "restart", ActionUpdate, "exec", ActionDelete}},
+	{ResourceImages, []string{ActionRead, "pull", "tag", "push", ActionDelete, "prune"}},
+	{ResourceProjects, []string{ActionRead, ActionCreate, ActionUpdate, "deploy", "restart", "stop", ActionDelete}},
+	{ResourceVolumes, []string{ActionRead, ActionCreate, ActionUpdate, "backup", "restore", ActionDelete, "prune"}},
+	{ResourceNetworks, []string{ActionRead, ActionCreate, ActionDelete, "prune"}},
+	{ResourceBuilds, []string{ActionRead, ActionCreate, "cancel"}},
+	{ResourceGitOps, []string{ActionRead, ActionCreate, ActionUpdate, "sync", ActionDelete}},
+	{ResourceVulnerabilities, []string{ActionRead, "scan", "ignore"}},
+	{ResourceUpdater, []string{ActionRead, "run"}},
+	{ResourceJobs, []string{ActionRead, ActionUpdate, "run"}},
+	{ResourceSystem, []string{ActionRead, "prune", "upgrade"}},
+	{ResourceRegistries, []string{ActionRead, ActionCreate, ActionUpdate, ActionDelete}},
+	{ResourceNotifications, []string{ActionRead, ActionUpdate, "test"}},
+	{ResourceSettings, []string{ActionUpdate}},
+}
+
+// workloadResources are the resources a non-admin could use before roles had permissions.
+var workloadResources = []string{
+	ResourceContainers, ResourceImages, ResourceProjects, ResourceVolumes, ResourceNetworks,
+	ResourceBuilds, ResourceGitOps, ResourceVulnerabilities, ResourceUpdater, ResourceJobs, ResourceSystem,
+}
+
+// BuiltInRoles maps the built-in role names to their permissions.
+var BuiltInRoles = map[string][]string{
+	RoleViewer: readPermissionsInternal(),
+	RoleOperator: append(readPermissionsInternal(),
+		"containers:start", "containers:stop", "containers:restart",
+		"projects:restart", "projects:stop",
+	),
+	RoleDeployer: append(readPermissionsInternal(),
+		"containers:*", "images:*", "projects:*", "volumes:*", "networks:*",
+		"builds:*", "gitops:*", "vulnerabilities:*", "updater:*", "jobs:run",
+	),
+	RoleAdmin: {Wildcard + ":" + Wildcard},
+}
+
+// BuiltInRoleDescriptions describes the built-in roles.
+var BuiltInRoleDescriptions = map[string]string{
+	RoleViewer:   "Read-only access to containers, images, projects and other workloads",
+	RoleOperator: "Viewer access plus starting, stopping and restarting containers and projects",
+	RoleDeployer: "Deploy and manage projects, containers, images, volumes, networks and builds",
+	RoleAdmin:    "Full access, including users, roles and settings",
+}
+
+func readPermissionsInternal() []string {
+	perms := make([]string, 0, len(workloadResources))
+	for _, r := range workloadResources {
+		perms = append(perms, r+":"+ActionRead)
+	}
+	return perms
+}
+
+// IsBuiltInRole reports whether name is a built-in role, including the legacy user role.
+func IsBuiltInRole(name string) bool {
+	_, ok := BuiltInRoles[name]
+	return ok || name == RoleUser
+}
+
+// BuiltInPermissions returns the permissions of a built-in role.
+func BuiltInPermissions(name string) ([]string, bool) {
+	if name == RoleUser {
+		name = RoleDeployer
+	}
+	perms, ok := BuiltInRoles[name]
+	return perms, ok
+}
+
+// Permission joins a resource and an action.
+func Permission(resource, action string) string {
+	return resource + ":" + action
+}
+
+// Grants reports whether the granted permission includes required.
+func Grants(granted, required string) bool {
+	if granted == Wildcard {
+		return true
+	}
+	gRes, gAct, ok := strings.Cut(granted, ":")
+	if !ok {
+		return false
+	}
+	rRes, rAct, ok := strings.Cut(required, ":")
+	if !ok {
+		return false
+	}
+	return (gRes == Wildcard || gRes == rRes) && (gAct == Wildcard || gAct == rAct)
+}
+
+// GrantsAny reports whether any of the granted permissions includes required.
+func GrantsAny(granted []string, required string) bool {
+	return slices.ContainsFunc(granted, func(g string) bool { return Grants(g, required) })
+}
+
+// ValidatePermission reports whether p is a permission of Catalog, allowing wildcards.
+func ValidatePermission(p string) bool {
+	if p == Wildcard {
+		return true
+	}
+	res, act, ok := strings.Cut(p, ":")
+	if !ok || res == "" || act == "" {
+		return false
+	}
+	if res == Wildcard {
+		if act == Wildcard {
+			return true
+		}
+		for _, ra := range Catalog {
+			if slices.Contains(ra.Actions, act) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, ra := range Catalog {
+		if ra.Resource == res {
+			return act == Wildcard || slices.Contains(ra.Actions, act)
+		}
+	}
+	return false
+}
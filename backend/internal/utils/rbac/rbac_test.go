@@ -0,0 +1,82 @@
+package rbac
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredPermission(t *testing.T) {
+	tests := []struct {
+		method string
+		route  string
+		want   string
+	}{
+		{http.MethodGet, "/environments/{id}/containers", "containers:read"},
+		{http.MethodPost, "/environments/{id}/containers", "containers:create"},
+		{http.MethodPost, "/environments/{id}/containers/{containerId}/restart", "containers:restart"},
+		{http.MethodDelete, "/environments/{id}/containers/{containerId}", "containers:delete"},
+		{http.MethodPost, "/api/environments/:id/projects/:projectId/up", "projects:deploy"},
+		{http.MethodDelete, "/environments/{id}/projects/{projectId}/destroy", "projects:delete"},
+		{http.MethodPost, "/environments/{id}/images/upload", "images:pull"},
+		{http.MethodPost, "/environments/{id}/images/{imageId}/vulnerabilities/scan", "vulnerabilities:scan"},
+		{http.MethodPost, "/environments/{id}/image-updates/check-all", "images:read"},
+		{http.MethodPost, "/environments/{id}/volumes/{volumeName}/backups/upload", "volumes:backup"},
+		{http.MethodPost, "/environments/{id}/volumes/{volumeName}/browse/upload", "volumes:update"},
+		{http.MethodPost, "/environments/{id}/system/containers/stop-all", "containers:stop"},
+		{http.MethodPost, "/environments/{id}/gitops-syncs/{syncId}/drift", "gitops:read"},
+		{http.MethodPut, "/environments/{id}/settings", "settings:update"},
+		{http.MethodGet, "/api/environments/:id/ws/containers/:containerId/logs", "containers:read"},
+		{http.MethodGet, "/api/environments/:id/ws/containers/:containerId/terminal", "containers:exec"},
+		{http.MethodGet, "/api/environments/:id/ws/builds/:buildId/progress", "builds:read"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.route, func(t *testing.T) {
+			got, scoped, err := RequiredPermission(tt.method, tt.route)
+			require.NoError(t, err)
+			assert.True(t, scoped)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, route := range []string{"/users", "/environments/{id}", "/environments/{id}/heartbeat", "/environments/{id}/settings"} {
+		_, scoped, err := RequiredPermission(http.MethodGet, route)
+		require.NoError(t, err, route)
+		assert.False(t, scoped, route)
+	}
+
+	for _, route := range []string{"/environments/{id}/unknown", "/environments/{id}/ws", "/api/environments/:id/ws/unknown/:x"} {
+		_, scoped, err := RequiredPermission(http.MethodPost, route)
+		require.ErrorIs(t, err, ErrUnmappedRoute, route)
+		assert.True(t, scoped, route)
+	}
+}
+
+func TestGrants(t *testing.T) {
+	assert.True(t, Grants("*", "containers:restart"))
+	assert.True(t, Grants("*:*", "containers:restart"))
+	assert.True(t, Grants("containers:*", "containers:restart"))
+	assert.True(t, Grants("*:read", "images:read"))
+	assert.False(t, Grants("*:read", "images:pull"))
+	assert.False(t, Grants("containers:restart", "containers:stop"))
+
+	viewer, _ := BuiltInPermissions(RoleViewer)
+	assert.True(t, GrantsAny(viewer, "projects:read"))
+	assert.False(t, GrantsAny(viewer, "notifications:read"))
+
+	legacy, ok := BuiltInPermissions(RoleUser)
+	assert.True(t, ok)
+	assert.True(t, GrantsAny(legacy, "projects:deploy"))
+	assert.False(t, GrantsAny(legacy, "settings:update"))
+}
+
+func TestValidatePermission(t *testing.T) {
+	for _, p := range []string{"*", "*:*", "containers:*", "*:restart", "projects:deploy"} {
+		assert.True(t, ValidatePermission(p), p)
+	}
+	for _, p := range []string{"", "containers", "containers:fly", "*:fly", "unknown:read", ":read"} {
+		assert.False(t, ValidatePermission(p), p)
+	}
+}
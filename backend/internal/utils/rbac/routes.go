@@ -0,0 +1,210 @@
+package rbac
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrUnmappedRoute is returned for environment routes that have no permission mapped, which
+// only admins may call.
+var ErrUnmappedRoute = errors.New("route has no mapped permission")
+
+// routeResources maps the path segment after /environments/{id} to its resource.
+var routeResources = map[string]string{
+	"containers":       ResourceContainers,
+	"images":           ResourceImages,
+	"image-updates":    ResourceImages,
+	"projects":         ResourceProjects,
+	"volumes":          ResourceVolumes,
+	"networks":         ResourceNetworks,
+	"builds":           ResourceBuilds,
+	"gitops-syncs":     ResourceGitOps,
+	"vulnerabilities":  ResourceVulnerabilities,
+	"updater":          ResourceUpdater,
+	"jobs":             ResourceJobs,
+	"job-schedules":    ResourceJobs,
+	"system":           ResourceSystem,
+	"registry-mirrors": ResourceRegistries,
+	"notifications":    ResourceNotifications,
+	"settings":         ResourceSettings,
+}
+
+// managementRoutes are the segments after /environments/{id} of routes that manage the
+// environment itself rather than its resources. Their handlers check access.
+var managementRoutes = map[string]bool{
+	"test":        true,
+	"heartbeat":   true,
+	"agent":       true,
+	"sync":        true,
+	"deployment":  true,
+	"version":     true,
+	"permissions": true,
+}
+
+// routeVerbs maps the last literal path segment of a mutating route, optionally prefixed with
+// the literal segment before it, to its action. A value with a colon names a permission of
+// another resource.
+var routeVerbs = map[string]map[string]string{
+	ResourceContainers: {
+		"start":   "start",
+		"stop":    "stop",
+		"restart": "restart",
+		"update":  ActionUpdate,
+	},
+	ResourceImages: {
+		"pull":        "pull",
+		"upload":      "pull",
+		"tag":         "tag",
+		"push":        "push",
+		"prune":       "prune",
+		"check":       ActionRead,
+		"check-all":   ActionRead,
+		"check-batch": ActionRead,
+		"summaries":   Permission(ResourceVulnerabilities, ActionRead),
+		"scan":        Permission(ResourceVulnerabilities, "scan"),
+	},
+	ResourceProjects: {
+		"up":       "deploy",
+		"redeploy": "deploy",
+		"pull":     "deploy",
+		"rollback": "deploy",
+		"down":     "stop",
+		"restart":  "restart",
+		"destroy":  ActionDelete,
+		"includes": ActionUpdate,
+	},
+	ResourceVolumes: {
+		"prune":          "prune",
+		"backups":        "backup",
+		"backups/upload": "backup",
+		"restore":        "restore",
+		"restore-files":  "restore",
+		"browse":         ActionUpdate,
+		"browse/upload":  ActionUpdate,
+		"mkdir":          ActionUpdate,
+	},
+	ResourceNetworks: {
+		"prune": "prune",
+	},
+	ResourceBuilds: {
+		"cancel": "cancel",
+	},
+	ResourceGitOps: {
+		"sync":    "sync",
+		"drift":   ActionRead,
+		"import":  ActionCreate,
+		"webhook": ActionUpdate,
+	},
+	ResourceVulnerabilities: {
+		"ignore": "ignore",
+	},
+	ResourceUpdater: {
+		"run": "run",
+	},
+	ResourceJobs: {
+		"run": "run",
+	},
+	ResourceSystem: {
+		"prune":         "prune",
+		"upgrade":       "upgrade",
+		"convert":       ActionRead,
+		"start-all":     Permission(ResourceContainers, "start"),
+		"start-stopped": Permission(ResourceContainers, "start"),
+		"stop-all":      Permission(ResourceContainers, "stop"),
+	},
+	ResourceNotifications: {
+		"test":     "test",
+		"settings": ActionUpdate,
+		"apprise":  ActionUpdate,
+	},
+}
+
+// streamVerbs maps the last segment of WebSocket routes that do more than read.
+var streamVerbs = map[string]string{
+	"terminal": Permission(ResourceContainers, "exec"),
+}
+
+// RequiredPermission returns the permission a request to an environment route needs. The
+// route is the registered pattern, with parameters written as {name} or :name, with or
+// without the /api prefix. scoped is false for routes outside an environment's resources,
+// which need no permission. Environment routes without a mapping return ErrUnmappedRoute.
+func RequiredPermission(method, route string) (permission string, scoped bool, err error) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(route, "/api"), "/"), "/")
+	if len(segments) < 3 || segments[0] != "environments" || !isParamInternal(segments[1]) {
+		return "", false, nil
+	}
+	rest := segments[2:]
+	if managementRoutes[rest[0]] {
+		return "", false, nil
+	}
+
+	stream := false
+	if rest[0] == "ws" {
+		stream = true
+		rest = rest[1:]
+		if len(rest) == 0 {
+			return "", true, ErrUnmappedRoute
+		}
+	}
+
+	resource, ok := routeResources[rest[0]]
+	if !ok {
+		return "", true, ErrUnmappedRoute
+	}
+
+	var literals []string
+	for _, s := range rest[1:] {
+		if !isParamInternal(s) {
+			literals = append(literals, s)
+		}
+	}
+
+	if stream {
+		if len(literals) > 0 {
+			if perm, ok := streamVerbs[literals[len(literals)-1]]; ok {
+				return perm, true, nil
+			}
+		}
+		return Permission(resource, ActionRead), true, nil
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		if resource == ResourceSettings {
+			// Every user reads the settings; non-admins only get the public ones.
+			return "", false, nil
+		}
+		return Permission(resource, ActionRead), true, nil
+	}
+
+	if n := len(literals); n > 0 {
+		verbs := routeVerbs[resource]
+		action, ok := "", false
+		if n > 1 {
+			action, ok = verbs[literals[n-2]+"/"+literals[n-1]]
+		}
+		if !ok {
+			action, ok = verbs[literals[n-1]]
+		}
+		if ok {
+			if strings.Contains(action, ":") {
+				return action, true, nil
+			}
+			return Permission(resource, action), true, nil
+		}
+	}
+
+	switch method {
+	case http.MethodPost:
+		return Permission(resource, ActionCreate), true, nil
+	case http.MethodDelete:
+		return Permission(resource, ActionDelete), true, nil
+	default:
+		return Permission(resource, ActionUpdate), true, nil
+	}
+}
+
+func isParamInternal(segment string) bool {
+	return strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "{")
+}
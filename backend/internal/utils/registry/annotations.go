@@ -0,0 +1,193 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// OCI annotations that tie an image to the code it was built from. Build tools set them both
+// as manifest annotations and as labels in the image config.
+const (
+	AnnotationSource   = "org.opencontainers.image.source"
+	AnnotationVersion  = "org.opencontainers.image.version"
+	AnnotationRevision = "org.opencontainers.image.revision"
+)
+
+// maxManifestSize bounds the manifests and config blobs read while looking up annotations.
+const maxManifestSize = 4 << 20
+
+// ImageOrigin is where an image says it was built from.
+type ImageOrigin struct {
+	Source   string
+	Version  string
+	Revision string
+}
+
+// Empty reports whether the image carried none of the origin annotations.
+func (o *ImageOrigin) Empty() bool {
+	return o.Source == "" && o.Version == "" && o.Revision == ""
+}
+
+func (o *ImageOrigin) fill(values map[string]string) {
+	if o.Source == "" {
+		o.Source = strings.TrimSpace(values[AnnotationSource])
+	}
+	if o.Version == "" {
+		o.Version = strings.TrimSpace(values[AnnotationVersion])
+	}
+	if o.Revision == "" {
+		o.Revision = strings.TrimSpace(values[AnnotationRevision])
+	}
+}
+
+type manifestDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations"`
+	Platform    *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+		Variant      string `json:"variant"`
+	} `json:"platform"`
+}
+
+type manifestDocument struct {
+	MediaType   string               `json:"mediaType"`
+	Manifests   []manifestDescriptor `json:"manifests"`
+	Config      *manifestDescriptor  `json:"config"`
+	Layers      []manifestDescriptor `json:"layers"`
+	Annotations map[string]string    `json:"annotations"`
+}
+
+// GetImageOrigin reads the source, version and revision annotations of the image at reference,
+// a tag or digest. The image config labels are preferred, manifest and index annotations fill
+// in what they lack. Multi-platform images are read for the platform Arcane runs on.
+func (c *Client) GetImageOrigin(ctx context.Context, registry, repository, reference, token string) (*ImageOrigin, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	base := fmt.Sprintf("%s/v2/%s", c.GetRegistryURL(registry), repository)
+
+	doc, err := c.getManifestDocumentInternal(ctx, base+"/manifests/"+reference, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var fallbacks []map[string]string
+	if len(doc.Manifests) > 0 {
+		fallbacks = append(fallbacks, doc.Annotations)
+		desc := selectPlatformManifest(doc.Manifests)
+		if desc == nil {
+			return nil, fmt.Errorf("no image manifest found in index")
+		}
+		doc, err = c.getManifestDocumentInternal(ctx, base+"/manifests/"+desc.Digest, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	origin := &ImageOrigin{}
+	if doc.Config != nil && doc.Config.Digest != "" {
+		body, err := c.getInternal(ctx, base+"/blobs/"+doc.Config.Digest, token, "application/json")
+		if err != nil {
+			return nil, fmt.Errorf("get image config: %w", err)
+		}
+		var cfg struct {
+			Config struct {
+				Labels map[string]string `json:"Labels"`
+			} `json:"config"`
+		}
+		if err := json.Unmarshal(body, &cfg); err != nil {
+			return nil, fmt.Errorf("decode image config: %w", err)
+		}
+		origin.fill(cfg.Config.Labels)
+	}
+
+	origin.fill(doc.Annotations)
+	for _, annotations := range fallbacks {
+		origin.fill(annotations)
+	}
+	return origin, nil
+}
+
+// selectPlatformManifest picks the manifest for the local platform from an index, falling back
+// to the first linux image. Attestation manifests are skipped.
+func selectPlatformManifest(manifests []manifestDescriptor) *manifestDescriptor {
+	var fallback *manifestDescriptor
+	for i := range manifests {
+		m := &manifests[i]
+		if m.Platform == nil || m.Platform.OS == "unknown" {
+			continue
+		}
+		if m.Platform.OS == "linux" && m.Platform.Architecture == runtime.GOARCH {
+			return m
+		}
+		if fallback == nil && m.Platform.OS == "linux" {
+			fallback = m
+		}
+	}
+	return fallback
+}
+
+// manifestAcceptTypes are the manifest and index media types Arcane understands.
+var manifestAcceptTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+func (c *Client) getManifestDocumentInternal(ctx context.Context, url, token string) (*manifestDocument, error) {
+	doc, _, err := c.getManifestWithBodyInternal(ctx, url, token)
+	return doc, err
+}
+
+func (c *Client) getManifestWithBodyInternal(ctx context.Context, url, token string) (*manifestDocument, []byte, error) {
+	body, err := c.getInternal(ctx, url, token, manifestAcceptTypes...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get manifest: %w", err)
+	}
+	var doc manifestDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	return &doc, body, nil
+}
+
+func (c *Client) getInternal(ctx context.Context, url, token string, accept ...string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range accept {
+		req.Header.Add("Accept", a)
+	}
+	req.Header.Set("User-Agent", "Arcane")
+	if ah := buildAuthHeader(token); ah != "" {
+		req.Header.Set("Authorization", ah)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		if h := getHeaderCI(resp.Header, ChallengeHeader); h != "" {
+			return nil, fmt.Errorf("unauthorized: %s", h)
+		}
+		return nil, fmt.Errorf("request failed with status: 401")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed with status: %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+}
@@ -106,6 +106,15 @@ func (c *Client) CheckAuth(ctx context.Context, registry string) (string, error)
 }
 
 func (c *Client) GetTokenMulti(ctx context.Context, authURL string, repositories []string, creds *Credentials) (string, error) {
+	scopes := make([]string, 0, len(repositories))
+	for _, repo := range repositories {
+		scopes = append(scopes, fmt.Sprintf("repository:%s:pull", repo))
+	}
+	return c.GetTokenForScopes(ctx, authURL, scopes, creds)
+}
+
+// GetTokenForScopes requests a bearer token for raw scope strings such as "registry:catalog:*".
+func (c *Client) GetTokenForScopes(ctx context.Context, authURL string, scopes []string, creds *Credentials) (string, error) {
 	parsed, err := url.Parse(authURL)
 	if err != nil {
 		return "", fmt.Errorf("invalid auth url: %w", err)
@@ -114,8 +123,8 @@ func (c *Client) GetTokenMulti(ctx context.Context, authURL string, repositories
 	if q.Get("service") == "" {
 		q.Set("service", c.getServiceName(authURL))
 	}
-	for _, repo := range repositories {
-		q.Add("scope", fmt.Sprintf("repository:%s:pull", repo))
+	for _, scope := range scopes {
+		q.Add("scope", scope)
 	}
 	parsed.RawQuery = q.Encode()
 
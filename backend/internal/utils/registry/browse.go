@@ -0,0 +1,214 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// maxCatalogPages bounds how many pages of a registry catalog are fetched.
+const maxCatalogPages = 20
+
+// CatalogScope is the token scope needed to list a registry's repositories.
+const CatalogScope = "registry:catalog:*"
+
+type catalogList struct {
+	Repositories []string `json:"repositories"`
+}
+
+// ManifestDetails describes the manifest a tag points to. Single-platform images have one
+// platform entry, multi-platform images one per image in the index.
+type ManifestDetails struct {
+	Digest    string
+	MediaType string
+	Platforms []PlatformDetails
+}
+
+// PlatformDetails describes one image of a manifest.
+type PlatformDetails struct {
+	Digest       string
+	OS           string
+	Architecture string
+	Variant      string
+	Size         int64
+	Created      *time.Time
+	Labels       map[string]string
+}
+
+// NormalizeRepository adds the implicit "library/" namespace to official Docker Hub images.
+func NormalizeRepository(registry, repository string) string {
+	return normalizeRepositoryForDockerIO(registry, repository)
+}
+
+// RepositoryScope is the token scope needed to pull from repository.
+func RepositoryScope(registry, repository string) string {
+	return fmt.Sprintf("repository:%s:pull", NormalizeRepository(registry, repository))
+}
+
+// GetBrowseToken returns an authorization value for the given scopes: a bearer token when the
+// registry issues them, basic credentials when it does not. An empty value means anonymous.
+func (c *Client) GetBrowseToken(ctx context.Context, registry string, scopes []string, creds *Credentials) (string, error) {
+	authURL, err := c.CheckAuth(ctx, registry)
+	if err != nil {
+		return "", fmt.Errorf("check registry auth: %w", err)
+	}
+
+	if authURL != "" {
+		tok, err := c.GetTokenForScopes(ctx, authURL, scopes, creds)
+		if err != nil {
+			return "", err
+		}
+		return tok, nil
+	}
+
+	if creds != nil && creds.Username != "" && creds.Token != "" {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(creds.Username+":"+creds.Token)), nil
+	}
+	return "", nil
+}
+
+// ListRepositories returns the repositories in the registry catalog, following pagination links.
+// Registries that disable the catalog API (Docker Hub among them) return an error.
+func (c *Client) ListRepositories(ctx context.Context, registry, token string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	base := c.GetRegistryURL(registry)
+	next := base + "/v2/_catalog?n=1000"
+
+	var repos []string
+	for page := 0; next != "" && page < maxCatalogPages; page++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", "Arcane")
+		if ah := buildAuthHeader(token); ah != "" {
+			req.Header.Set("Authorization", ah)
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("catalog request failed with status: %d", resp.StatusCode)
+		}
+
+		var list catalogList
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode catalog: %w", err)
+		}
+		repos = append(repos, list.Repositories...)
+
+		next, err = nextPageURL(base, getHeaderCI(resp.Header, "Link"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return repos, nil
+}
+
+// GetManifestDetails resolves reference, a tag or digest, and reads the platform, size, creation
+// date and labels of every image it points to. Attestation manifests in an index are skipped.
+func (c *Client) GetManifestDetails(ctx context.Context, registry, repository, reference, token string) (*ManifestDetails, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	repository = NormalizeRepository(registry, repository)
+	base := fmt.Sprintf("%s/v2/%s", c.GetRegistryURL(registry), repository)
+
+	doc, body, err := c.getManifestWithBodyInternal(ctx, base+"/manifests/"+reference, token)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &ManifestDetails{
+		Digest:    manifestDigest(body),
+		MediaType: doc.MediaType,
+	}
+
+	if len(doc.Manifests) == 0 {
+		platform, err := c.getPlatformDetailsInternal(ctx, base, token, details.Digest, doc)
+		if err != nil {
+			return nil, err
+		}
+		details.Platforms = []PlatformDetails{*platform}
+		return details, nil
+	}
+
+	for _, desc := range doc.Manifests {
+		if desc.Platform == nil || desc.Platform.OS == "unknown" {
+			continue
+		}
+		child, err := c.getManifestDocumentInternal(ctx, base+"/manifests/"+desc.Digest, token)
+		if err != nil {
+			return nil, err
+		}
+		platform, err := c.getPlatformDetailsInternal(ctx, base, token, desc.Digest, child)
+		if err != nil {
+			return nil, err
+		}
+		// The index entry is authoritative for the platform, the config may omit the variant.
+		platform.OS = desc.Platform.OS
+		platform.Architecture = desc.Platform.Architecture
+		if desc.Platform.Variant != "" {
+			platform.Variant = desc.Platform.Variant
+		}
+		details.Platforms = append(details.Platforms, *platform)
+	}
+
+	return details, nil
+}
+
+// getPlatformDetailsInternal reads the config blob of an image manifest. The reported size is
+// the compressed size of the config and layers, which is what a pull downloads.
+func (c *Client) getPlatformDetailsInternal(ctx context.Context, base, token, digest string, doc *manifestDocument) (*PlatformDetails, error) {
+	platform := &PlatformDetails{Digest: digest}
+	for _, layer := range doc.Layers {
+		platform.Size += layer.Size
+	}
+	if doc.Config == nil || doc.Config.Digest == "" {
+		return platform, nil
+	}
+	platform.Size += doc.Config.Size
+
+	body, err := c.getInternal(ctx, base+"/blobs/"+doc.Config.Digest, token, "application/json")
+	if err != nil {
+		return nil, fmt.Errorf("get image config: %w", err)
+	}
+	var cfg struct {
+		Created      *time.Time `json:"created"`
+		OS           string     `json:"os"`
+		Architecture string     `json:"architecture"`
+		Variant      string     `json:"variant"`
+		Config       struct {
+			Labels map[string]string `json:"Labels"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(body, &cfg); err != nil {
+		return nil, fmt.Errorf("decode image config: %w", err)
+	}
+
+	platform.OS = cfg.OS
+	platform.Architecture = cfg.Architecture
+	platform.Variant = cfg.Variant
+	platform.Created = cfg.Created
+	platform.Labels = cfg.Config.Labels
+	return platform, nil
+}
+
+func manifestDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
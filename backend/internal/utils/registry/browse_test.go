@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestListRepositoriesFollowsPagination(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/_catalog" {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Fatalf("unexpected authorization %q", got)
+		}
+		if r.URL.Query().Get("last") == "" {
+			w.Header().Set("Link", `</v2/_catalog?last=b&n=1000>; rel="next"`)
+			_ = json.NewEncoder(w).Encode(catalogList{Repositories: []string{"a", "b"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(catalogList{Repositories: []string{"c"}})
+	}))
+	defer srv.Close()
+
+	repos, err := NewClient().ListRepositories(context.Background(), srv.URL, "tok")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !slices.Equal(repos, []string{"a", "b", "c"}) {
+		t.Fatalf("got %v", repos)
+	}
+}
+
+func TestGetManifestDetailsFromIndex(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/org/app/manifests/1.0":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"mediaType": "application/vnd.oci.image.index.v1+json",
+				"manifests": []map[string]any{
+					{"digest": "sha256:amd", "platform": map[string]string{"os": "linux", "architecture": "amd64"}},
+					{"digest": "sha256:arm", "platform": map[string]string{"os": "linux", "architecture": "arm", "variant": "v7"}},
+					{"digest": "sha256:att", "platform": map[string]string{"os": "unknown", "architecture": "unknown"}},
+				},
+			})
+		case "/v2/org/app/manifests/sha256:amd", "/v2/org/app/manifests/sha256:arm":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"mediaType": "application/vnd.oci.image.manifest.v1+json",
+				"config":    map[string]any{"digest": "sha256:cfg", "size": 100},
+				"layers":    []map[string]any{{"digest": "sha256:l1", "size": 1000}, {"digest": "sha256:l2", "size": 24}},
+			})
+		case "/v2/org/app/blobs/sha256:cfg":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"created":      "2024-05-01T12:00:00Z",
+				"os":           "linux",
+				"architecture": "amd64",
+				"config":       map[string]any{"Labels": map[string]string{"maintainer": "org"}},
+			})
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	details, err := NewClient().GetManifestDetails(context.Background(), srv.URL, "org/app", "1.0", "")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if details.MediaType != "application/vnd.oci.image.index.v1+json" || details.Digest == "" {
+		t.Fatalf("unexpected manifest %+v", details)
+	}
+	if len(details.Platforms) != 2 {
+		t.Fatalf("expected 2 platforms, got %+v", details.Platforms)
+	}
+
+	arm := details.Platforms[1]
+	if arm.Digest != "sha256:arm" || arm.Architecture != "arm" || arm.Variant != "v7" {
+		t.Fatalf("unexpected arm platform %+v", arm)
+	}
+	if arm.Size != 1124 {
+		t.Fatalf("size %d want 1124", arm.Size)
+	}
+	if arm.Created == nil || arm.Created.Year() != 2024 || arm.Labels["maintainer"] != "org" {
+		t.Fatalf("unexpected config details %+v", arm)
+	}
+}
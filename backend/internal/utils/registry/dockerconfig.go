@@ -0,0 +1,273 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dockerHubConfigKey is the key the docker CLI stores Docker Hub credentials under.
+const dockerHubConfigKey = "https://index.docker.io/v1/"
+
+// credentialHelperPrefix is prepended to credsStore/credHelpers names to find the helper binary.
+const credentialHelperPrefix = "docker-credential-"
+
+// credentialHelperTimeout bounds a single credential helper call, so a helper waiting on a
+// locked keychain or a prompt can't hold up registry requests.
+var credentialHelperTimeout = 10 * time.Second
+
+// identityTokenUsername is returned by credential helpers in place of a username when the secret is an identity token.
+const identityTokenUsername = "<token>"
+
+// DockerConfig is the subset of a docker CLI config.json that holds registry credentials.
+type DockerConfig struct {
+	Auths       map[string]DockerAuthEntry `json:"auths"`
+	CredsStore  string                     `json:"credsStore,omitempty"`
+	CredHelpers map[string]string          `json:"credHelpers,omitempty"`
+}
+
+// DockerAuthEntry is a single entry of the "auths" section.
+type DockerAuthEntry struct {
+	Auth          string `json:"auth,omitempty"`
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// DockerCredential is a resolved username/secret pair for a registry host.
+type DockerCredential struct {
+	Host     string
+	Username string
+	Secret   string
+}
+
+// credentialHelperResponse is the payload written by "docker-credential-* get".
+type credentialHelperResponse struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// DefaultDockerConfigPath returns the config.json location the docker CLI would use,
+// honoring DOCKER_CONFIG.
+func DefaultDockerConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// LoadDockerConfig reads and parses a docker CLI config file. A directory path is
+// treated like DOCKER_CONFIG and resolved to the config.json inside it.
+func LoadDockerConfig(path string) (*DockerConfig, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "config.json")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read docker config: %w", err)
+	}
+
+	var cfg DockerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse docker config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// NormalizeDockerConfigHost maps a config.json key to the bare registry host used
+// elsewhere in Arcane. Docker Hub keys collapse to "docker.io".
+func NormalizeDockerConfigHost(key string) string {
+	h := normalizeHost(key)
+	if slash := strings.Index(h, "/"); slash != -1 {
+		h = h[:slash]
+	}
+	switch h {
+	case DefaultRegistryDomain, DefaultRegistry, DefaultRegistryHost:
+		return DefaultRegistryDomain
+	}
+	return h
+}
+
+// Credentials resolves every registry credential reachable from the config: inline
+// "auths" entries, per-registry credHelpers and, if configured, everything listed
+// by the credsStore. Identity tokens are skipped since they cannot be used as a
+// password. Failures for individual hosts are collected and returned alongside the
+// credentials that could be resolved.
+func (c *DockerConfig) Credentials(ctx context.Context) ([]DockerCredential, error) {
+	byHost := make(map[string]DockerCredential)
+	var errs []error
+
+	add := func(cred *DockerCredential) {
+		if cred == nil || cred.Username == "" || cred.Secret == "" {
+			return
+		}
+		if _, exists := byHost[cred.Host]; !exists {
+			byHost[cred.Host] = *cred
+		}
+	}
+
+	// Per-registry helpers take precedence over everything else, like in the docker CLI.
+	for key, helper := range c.CredHelpers {
+		cred, err := getFromCredentialHelper(ctx, helper, key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		add(cred)
+	}
+
+	if c.CredsStore != "" {
+		keys, err := listCredentialHelper(ctx, c.CredsStore)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		// Entries in "auths" are only markers when a store is configured, but they
+		// still name hosts worth asking the store about.
+		for key := range c.Auths {
+			keys = append(keys, key)
+		}
+		for _, key := range keys {
+			if _, exists := byHost[NormalizeDockerConfigHost(key)]; exists {
+				continue
+			}
+			cred, err := getFromCredentialHelper(ctx, c.CredsStore, key)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			add(cred)
+		}
+	}
+
+	for key, entry := range c.Auths {
+		cred, err := entry.credential(key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		add(cred)
+	}
+
+	out := make([]DockerCredential, 0, len(byHost))
+	for _, cred := range byHost {
+		out = append(out, cred)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Host < out[j].Host })
+
+	return out, errors.Join(errs...)
+}
+
+// credential decodes an inline "auths" entry. Entries without a username/password
+// (for example identity-token-only entries) yield nil.
+func (e DockerAuthEntry) credential(key string) (*DockerCredential, error) {
+	username, password := e.Username, e.Password
+	if e.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(e.Auth))
+		if err != nil {
+			return nil, fmt.Errorf("decode auth for %s: %w", key, err)
+		}
+		user, pass, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid auth for %s: expected username:password", key)
+		}
+		username, password = user, pass
+	}
+	if username == "" || password == "" {
+		return nil, nil
+	}
+	return &DockerCredential{
+		Host:     NormalizeDockerConfigHost(key),
+		Username: username,
+		Secret:   password,
+	}, nil
+}
+
+// getFromCredentialHelper runs "docker-credential-<helper> get" for serverURL.
+// A helper reporting that it has no credentials for the host yields nil.
+func getFromCredentialHelper(ctx context.Context, helper, serverURL string) (*DockerCredential, error) {
+	out, err := runCredentialHelper(ctx, helper, "get", serverURL)
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "credentials not found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var resp credentialHelperResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("parse %s%s output for %s: %w", credentialHelperPrefix, helper, serverURL, err)
+	}
+	if resp.Username == identityTokenUsername {
+		return nil, nil
+	}
+
+	return &DockerCredential{
+		Host:     NormalizeDockerConfigHost(serverURL),
+		Username: resp.Username,
+		Secret:   resp.Secret,
+	}, nil
+}
+
+// listCredentialHelper runs "docker-credential-<helper> list" and returns the server URLs it knows about.
+func listCredentialHelper(ctx context.Context, helper string) ([]string, error) {
+	out, err := runCredentialHelper(ctx, helper, "list", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries map[string]string
+	if err := json.Unmarshal(out, &entries); err != nil {
+		return nil, fmt.Errorf("parse %s%s list output: %w", credentialHelperPrefix, helper, err)
+	}
+
+	keys := make([]string, 0, len(entries))
+	for serverURL := range entries {
+		keys = append(keys, serverURL)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// runCredentialHelper implements the docker-credential-helpers exec protocol: the
+// action is the only argument and the payload, if any, is written to stdin.
+func runCredentialHelper(ctx context.Context, helper, action, input string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, credentialHelperTimeout)
+	defer cancel()
+
+	name := credentialHelperPrefix + helper
+	cmd := exec.CommandContext(ctx, name, action)
+	cmd.Stdin = strings.NewReader(input)
+	// Don't wait on output pipes held open by processes the helper left behind.
+	cmd.WaitDelay = time.Second
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stdout.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stderr.String())
+		}
+		if msg != "" {
+			return nil, fmt.Errorf("%s %s: %w: %s", name, action, err, msg)
+		}
+		return nil, fmt.Errorf("%s %s: %w", name, action, err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,160 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func writeDockerConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func installCredentialHelper(t *testing.T, name, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper scripts require a POSIX shell")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, credentialHelperPrefix+name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil { //nolint:gosec // test helper must be executable
+		t.Fatalf("write helper: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestDockerConfigInlineAuths(t *testing.T) {
+	t.Parallel()
+	auth := base64.StdEncoding.EncodeToString([]byte("alice:s3cret:with-colon"))
+	path := writeDockerConfig(t, t.TempDir(), `{
+		"auths": {
+			"https://index.docker.io/v1/": {"auth": "`+auth+`"},
+			"ghcr.io": {"username": "bob", "password": "pat"},
+			"registry.example.com": {"identitytoken": "refresh"}
+		}
+	}`)
+
+	cfg, err := LoadDockerConfig(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	creds, err := cfg.Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("credentials: %v", err)
+	}
+	if len(creds) != 2 {
+		t.Fatalf("expected 2 credentials, got %d: %+v", len(creds), creds)
+	}
+	if creds[0].Host != "docker.io" || creds[0].Username != "alice" || creds[0].Secret != "s3cret:with-colon" {
+		t.Fatalf("unexpected docker hub credential: %+v", creds[0])
+	}
+	if creds[1].Host != "ghcr.io" || creds[1].Username != "bob" || creds[1].Secret != "pat" {
+		t.Fatalf("unexpected ghcr credential: %+v", creds[1])
+	}
+}
+
+func TestLoadDockerConfigFromDirectory(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeDockerConfig(t, dir, `{"auths": {"quay.io": {"username": "u", "password": "p"}}}`)
+
+	cfg, err := LoadDockerConfig(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if _, ok := cfg.Auths["quay.io"]; !ok {
+		t.Fatalf("expected quay.io entry, got %+v", cfg.Auths)
+	}
+}
+
+func TestDockerConfigCredentialHelpers(t *testing.T) {
+	installCredentialHelper(t, "fake", `
+case "$1" in
+  list)
+    echo '{"https://index.docker.io/v1/":"hubuser","registry.example.com":"robot"}'
+    ;;
+  get)
+    read server
+    case "$server" in
+      https://index.docker.io/v1/) echo '{"ServerURL":"https://index.docker.io/v1/","Username":"hubuser","Secret":"hubpass"}' ;;
+      registry.example.com) echo '{"ServerURL":"registry.example.com","Username":"<token>","Secret":"identity"}' ;;
+      ghcr.io) echo '{"ServerURL":"ghcr.io","Username":"helper","Secret":"from-helper"}' ;;
+      *) echo "credentials not found in native keychain"; exit 1 ;;
+    esac
+    ;;
+esac
+`)
+
+	path := writeDockerConfig(t, t.TempDir(), `{
+		"auths": {
+			"ghcr.io": {"username": "inline", "password": "ignored"},
+			"quay.io": {}
+		},
+		"credsStore": "fake",
+		"credHelpers": {"ghcr.io": "fake"}
+	}`)
+
+	cfg, err := LoadDockerConfig(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	creds, err := cfg.Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("credentials: %v", err)
+	}
+
+	got := make(map[string]DockerCredential, len(creds))
+	for _, c := range creds {
+		got[c.Host] = c
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 credentials, got %+v", creds)
+	}
+	if c := got["docker.io"]; c.Username != "hubuser" || c.Secret != "hubpass" {
+		t.Fatalf("unexpected docker hub credential: %+v", c)
+	}
+	if c := got["ghcr.io"]; c.Username != "helper" || c.Secret != "from-helper" {
+		t.Fatalf("credHelpers entry should win over inline auth: %+v", c)
+	}
+}
+
+func TestDockerConfigMissingHelper(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	cfg := &DockerConfig{
+		Auths:       map[string]DockerAuthEntry{"quay.io": {Username: "u", Password: "p"}},
+		CredHelpers: map[string]string{"ghcr.io": "does-not-exist"},
+	}
+
+	creds, err := cfg.Credentials(context.Background())
+	if err == nil {
+		t.Fatalf("expected error for missing helper")
+	}
+	if len(creds) != 1 || creds[0].Host != "quay.io" {
+		t.Fatalf("expected inline credential despite helper failure, got %+v", creds)
+	}
+}
+
+func TestDockerConfigHangingHelperTimesOut(t *testing.T) {
+	installCredentialHelper(t, "hang", "sleep 30\n")
+	orig := credentialHelperTimeout
+	credentialHelperTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { credentialHelperTimeout = orig })
+
+	cfg := &DockerConfig{CredHelpers: map[string]string{"ghcr.io": "hang"}}
+	start := time.Now()
+	if _, err := cfg.Credentials(context.Background()); err == nil {
+		t.Fatalf("expected error for hanging helper")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("hanging helper was not cut off, took %s", elapsed)
+	}
+}
@@ -3,10 +3,13 @@ package registry
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"runtime"
+	"sync/atomic"
 	"testing"
 )
 
@@ -86,3 +89,148 @@ func TestGetLatestDigestLowerCaseHeader(t *testing.T) {
 		t.Fatalf("digest %q", d)
 	}
 }
+
+func TestListTagsFollowsPagination(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/library/postgres/tags/list" {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Fatalf("authorization %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Query().Get("last") == "" {
+			w.Header().Set("Link", `</v2/library/postgres/tags/list?last=16.1&n=1000>; rel="next"`)
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "library/postgres", "tags": []string{"16.0", "16.1"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "library/postgres", "tags": []string{"16.2", "latest"}})
+	}))
+	defer srv.Close()
+
+	c := NewClient()
+	tags, err := c.ListTags(context.Background(), srv.URL, "library/postgres", "tok")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if fmt.Sprint(tags) != "[16.0 16.1 16.2 latest]" {
+		t.Fatalf("tags %v", tags)
+	}
+}
+
+func TestListTagsReportsIncompleteList(t *testing.T) {
+	t.Parallel()
+	var pages atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := pages.Add(1)
+		w.Header().Set("Link", fmt.Sprintf(`</v2/library/postgres/tags/list?last=%d&n=1000>; rel="next"`, page))
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "library/postgres", "tags": []string{fmt.Sprint(page)}})
+	}))
+	defer srv.Close()
+
+	c := NewClient()
+	tags, err := c.ListTags(context.Background(), srv.URL, "library/postgres", "tok")
+	if !errors.Is(err, ErrTagListIncomplete) {
+		t.Fatalf("err %v, want %v", err, ErrTagListIncomplete)
+	}
+	if len(tags) != maxTagPages || pages.Load() != maxTagPages {
+		t.Fatalf("got %d tags from %d pages, want %d", len(tags), pages.Load(), maxTagPages)
+	}
+}
+
+func TestListTagsRefusesCrossHostPagination(t *testing.T) {
+	t.Parallel()
+	leaked := false
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Get("Authorization") != ""
+		_ = json.NewEncoder(w).Encode(map[string]any{"tags": []string{"evil"}})
+	}))
+	defer other.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", "<"+other.URL+`/v2/library/postgres/tags/list?last=16.1>; rel="next"`)
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "library/postgres", "tags": []string{"16.0"}})
+	}))
+	defer srv.Close()
+
+	c := NewClient()
+	if _, err := c.ListTags(context.Background(), srv.URL, "library/postgres", "tok"); err == nil {
+		t.Fatal("expected an error for a pagination link to another host")
+	}
+	if leaked {
+		t.Fatal("credentials were sent to another host")
+	}
+}
+
+func TestNewerTag(t *testing.T) {
+	t.Parallel()
+	tags := []string{"latest", "15.6", "16.1", "16.2", "16.4", "17.0", "16.4-alpine", "16.5-alpine", "16.2.1", "v16.9", "16.10", "18beta1"}
+
+	tests := []struct {
+		current string
+		policy  TagPolicy
+		want    string
+	}{
+		{current: "16.2", policy: TagPolicyNone, want: ""},
+		{current: "16.2", policy: TagPolicyPatch, want: ""},
+		{current: "16.2", policy: TagPolicyMinor, want: "16.10"},
+		{current: "16.2", policy: TagPolicyMajor, want: "17.0"},
+		{current: "16.4-alpine", policy: TagPolicyMajor, want: "16.5-alpine"},
+		{current: "16.2.0", policy: TagPolicyPatch, want: "16.2.1"},
+		{current: "17.0", policy: TagPolicyMajor, want: ""},
+		{current: "latest", policy: TagPolicyMajor, want: ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := NewerTag(tt.current, tags, tt.policy)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("NewerTag(%q, %s) = %q, %v; want %q", tt.current, tt.policy, got, ok, tt.want)
+		}
+	}
+
+	if ParseTagPolicy(" Minor ") != TagPolicyMinor || ParseTagPolicy("bogus") != TagPolicyNone {
+		t.Errorf("ParseTagPolicy did not normalize policies")
+	}
+}
+
+func TestGetImageOriginFromIndex(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/org/app/manifests/1.2.0":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"mediaType":   "application/vnd.oci.image.index.v1+json",
+				"annotations": map[string]string{AnnotationRevision: "from-index"},
+				"manifests": []map[string]any{
+					{"digest": "sha256:att", "platform": map[string]string{"os": "unknown", "architecture": "unknown"}},
+					{"digest": "sha256:img", "platform": map[string]string{"os": "linux", "architecture": runtime.GOARCH}},
+				},
+			})
+		case "/v2/org/app/manifests/sha256:img":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"mediaType":   "application/vnd.oci.image.manifest.v1+json",
+				"config":      map[string]string{"digest": "sha256:cfg"},
+				"annotations": map[string]string{AnnotationVersion: "from-manifest"},
+			})
+		case "/v2/org/app/blobs/sha256:cfg":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"config": map[string]any{"Labels": map[string]string{
+					AnnotationSource:  "https://github.com/org/app",
+					AnnotationVersion: "1.2.0",
+				}},
+			})
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient()
+	origin, err := c.GetImageOrigin(context.Background(), srv.URL, "org/app", "1.2.0", "")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	want := ImageOrigin{Source: "https://github.com/org/app", Version: "1.2.0", Revision: "from-index"}
+	if *origin != want {
+		t.Fatalf("origin %+v want %+v", *origin, want)
+	}
+}
@@ -0,0 +1,95 @@
+package registry
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/getarcaneapp/arcane/backend/internal/utils/version"
+)
+
+// TagPolicy limits which newer version tags count as an update for a pinned tag.
+type TagPolicy string
+
+const (
+	TagPolicyNone  TagPolicy = "none"  // only digest updates of the same tag
+	TagPolicyPatch TagPolicy = "patch" // 1.2.3 -> 1.2.x
+	TagPolicyMinor TagPolicy = "minor" // 1.2.3 -> 1.x
+	TagPolicyMajor TagPolicy = "major" // any newer version
+)
+
+// ParseTagPolicy returns the policy named by s, defaulting to TagPolicyNone.
+func ParseTagPolicy(s string) TagPolicy {
+	switch p := TagPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case TagPolicyPatch, TagPolicyMinor, TagPolicyMajor:
+		return p
+	default:
+		return TagPolicyNone
+	}
+}
+
+// tagVersion is a version tag with the shape it was written in. Tags only compare with tags of
+// the same shape: the same "v" prefix, number of parts and suffix, so that 16.2-alpine is only
+// followed by other -alpine tags.
+type tagVersion struct {
+	*semver.Version
+	prefix string
+	parts  int
+}
+
+func parseTagVersion(tag string) (tagVersion, bool) {
+	v, ok := version.Parse(tag)
+	if !ok {
+		return tagVersion{}, false
+	}
+	numbers, _, _ := strings.Cut(strings.TrimPrefix(tag, "v"), "-")
+	numbers, _, _ = strings.Cut(numbers, "+")
+	tv := tagVersion{Version: v, parts: strings.Count(numbers, ".") + 1}
+	if strings.HasPrefix(tag, "v") {
+		tv.prefix = "v"
+	}
+	return tv, true
+}
+
+func (v tagVersion) sameShape(o tagVersion) bool {
+	return v.prefix == o.prefix && v.parts == o.parts && v.Prerelease() == o.Prerelease() && v.Metadata() == o.Metadata()
+}
+
+// allows reports whether policy permits moving from v to the newer version o.
+func (v tagVersion) allows(o tagVersion, policy TagPolicy) bool {
+	switch policy {
+	case TagPolicyMajor:
+		return true
+	case TagPolicyMinor:
+		return v.parts >= 2 && o.Major() == v.Major()
+	case TagPolicyPatch:
+		return v.parts == 3 && o.Major() == v.Major() && o.Minor() == v.Minor()
+	default:
+		return false
+	}
+}
+
+// IsVersionTag reports whether tag looks like a version that NewerTag can compare.
+func IsVersionTag(tag string) bool {
+	_, ok := parseTagVersion(tag)
+	return ok
+}
+
+// NewerTag returns the highest tag in tags that is a newer version of current and allowed by
+// policy. Tags that aren't versions, such as latest, never have a newer tag.
+func NewerTag(current string, tags []string, policy TagPolicy) (string, bool) {
+	cur, ok := parseTagVersion(current)
+	if !ok || policy == TagPolicyNone {
+		return "", false
+	}
+
+	best, bestTag := cur, ""
+	for _, tag := range tags {
+		v, ok := parseTagVersion(tag)
+		if !ok || !cur.sameShape(v) || v.Compare(best.Version) <= 0 || !cur.allows(v, policy) {
+			continue
+		}
+		best, bestTag = v, tag
+	}
+	return bestTag, bestTag != ""
+}
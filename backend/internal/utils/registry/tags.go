@@ -0,0 +1,115 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxTagPages bounds how many pages of a tag list are fetched, some repositories have
+// tens of thousands of tags.
+const maxTagPages = 20
+
+// ErrTagListIncomplete is returned by ListTags, together with the tags fetched so far, when the
+// repository has more pages of tags than maxTagPages.
+var ErrTagListIncomplete = errors.New("tag list is incomplete")
+
+type tagList struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// ListTags returns the tags of a repository, following the registry's pagination links. Tags
+// are usually listed in lexical order, so when the list is cut off at maxTagPages the newest
+// versions may be missing; the partial list is then returned with ErrTagListIncomplete.
+func (c *Client) ListTags(ctx context.Context, registry, repository, token string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	base := c.GetRegistryURL(registry)
+	next := fmt.Sprintf("%s/v2/%s/tags/list?n=1000", base, repository)
+
+	var tags []string
+	for page := 0; next != "" && page < maxTagPages; page++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", "Arcane")
+		if ah := buildAuthHeader(token); ah != "" {
+			req.Header.Set("Authorization", ah)
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized {
+			h := getHeaderCI(resp.Header, ChallengeHeader)
+			resp.Body.Close()
+			if h != "" {
+				return nil, fmt.Errorf("unauthorized: %s", h)
+			}
+			return nil, fmt.Errorf("tag list request failed with status: 401")
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("tag list request failed with status: %d", resp.StatusCode)
+		}
+
+		var list tagList
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode tag list: %w", err)
+		}
+		tags = append(tags, list.Tags...)
+
+		next, err = nextPageURL(base, getHeaderCI(resp.Header, "Link"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if next != "" {
+		return tags, fmt.Errorf("%w: %s has more than %d pages of tags", ErrTagListIncomplete, repository, maxTagPages)
+	}
+	return tags, nil
+}
+
+// nextPageURL resolves the target of a `Link: <...>; rel="next"` header against the registry URL.
+// Links to another scheme or host are refused, since the next request carries the registry
+// credentials.
+func nextPageURL(base, link string) (string, error) {
+	if link == "" {
+		return "", nil
+	}
+	for part := range strings.SplitSeq(link, ",") {
+		target, params, ok := strings.Cut(part, ";")
+		if !ok || !strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+			continue
+		}
+		target = strings.Trim(strings.TrimSpace(target), "<>")
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(target)
+		if err != nil {
+			return "", fmt.Errorf("invalid pagination link %q: %w", target, err)
+		}
+		resolved := baseURL.ResolveReference(ref)
+		if !strings.EqualFold(resolved.Scheme, baseURL.Scheme) || !strings.EqualFold(resolved.Host, baseURL.Host) {
+			return "", fmt.Errorf("pagination link %q leaves the registry %s", target, baseURL.Host)
+		}
+		return resolved.String(), nil
+	}
+	return "", nil
+}
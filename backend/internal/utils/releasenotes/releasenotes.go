@@ -0,0 +1,214 @@
+// Package releasenotes finds the release notes of an image version in the repository its
+// org.opencontainers.image.source annotation points to.
+package releasenotes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultGitHubAPIURL = "https://api.github.com"
+	requestTimeout      = 15 * time.Second
+
+	// MaxBodyLength bounds the release notes body kept for an update.
+	MaxBodyLength = 8 << 10
+)
+
+// Notes are the release notes of one version. URL is always set, Body only when the source
+// repository has a release for the version.
+type Notes struct {
+	URL  string
+	Body string
+}
+
+// Resolver looks up release notes on GitHub and GitLab.
+type Resolver struct {
+	http         *http.Client
+	githubAPIURL string
+}
+
+func NewResolver() *Resolver {
+	return &Resolver{
+		http:         &http.Client{Timeout: requestTimeout},
+		githubAPIURL: defaultGitHubAPIURL,
+	}
+}
+
+// Resolve returns the release notes of version, or failing that of the commit revision, in the
+// source repository. Releases are only looked up on github.com, gitlab.com and the self-hosted
+// GitLab instances in gitlabHosts, as the source comes from the image and could point anywhere.
+// Sources on other hosts resolve to a link without any request being made.
+func (r *Resolver) Resolve(ctx context.Context, source, version, revision string, gitlabHosts []string) (*Notes, error) {
+	repoURL, err := normalizeSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	host := strings.ToLower(repoURL.Host)
+	path := strings.Trim(repoURL.Path, "/")
+	web := "https://" + repoURL.Host + "/" + path
+
+	lookupGitLab := host == "gitlab.com" || slices.ContainsFunc(gitlabHosts, func(h string) bool {
+		return strings.EqualFold(strings.TrimSpace(h), host)
+	})
+	gitlab := lookupGitLab || strings.HasPrefix(host, "gitlab.")
+
+	var notes *Notes
+	switch {
+	case host == "github.com":
+		notes, err = r.resolveGitHubInternal(ctx, path, version)
+	case lookupGitLab:
+		notes, err = r.resolveGitLabInternal(ctx, "https://"+repoURL.Host+"/api/v4", path, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if notes != nil {
+		return notes, nil
+	}
+
+	// No release for the version, link to the revision or the repository instead.
+	if revision != "" {
+		switch {
+		case host == "github.com":
+			return &Notes{URL: web + "/commit/" + url.PathEscape(revision)}, nil
+		case gitlab:
+			return &Notes{URL: web + "/-/commit/" + url.PathEscape(revision)}, nil
+		}
+	}
+	return &Notes{URL: web}, nil
+}
+
+// normalizeSource turns the forms found in source annotations, such as "git@github.com:o/r.git"
+// or "https://github.com/o/r/tree/main", into the URL of the repository.
+func normalizeSource(source string) (*url.URL, error) {
+	s := strings.TrimSpace(source)
+	if s == "" {
+		return nil, fmt.Errorf("empty source")
+	}
+	if rest, ok := strings.CutPrefix(s, "git@"); ok {
+		s = "https://" + strings.Replace(rest, ":", "/", 1)
+	}
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("parse source %q: %w", source, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("source %q has no host", source)
+	}
+
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if i := strings.Index(path, "/-/"); i >= 0 {
+		path = path[:i]
+	}
+	if strings.EqualFold(u.Host, "github.com") {
+		// Only owner/repo identify the repository, the rest is a file or branch view.
+		if parts := strings.SplitN(path, "/", 3); len(parts) >= 2 {
+			path = parts[0] + "/" + parts[1]
+		}
+	}
+	if path == "" {
+		return nil, fmt.Errorf("source %q has no repository path", source)
+	}
+
+	return &url.URL{Scheme: "https", Host: u.Host, Path: "/" + path}, nil
+}
+
+// tagCandidates returns the tags a release of version may be published under.
+func tagCandidates(version string) []string {
+	v := strings.TrimSpace(version)
+	if v == "" {
+		return nil
+	}
+	if trimmed, ok := strings.CutPrefix(v, "v"); ok {
+		return []string{v, trimmed}
+	}
+	return []string{v, "v" + v}
+}
+
+func (r *Resolver) resolveGitHubInternal(ctx context.Context, repo, version string) (*Notes, error) {
+	for _, tag := range tagCandidates(version) {
+		var release struct {
+			HTMLURL string `json:"html_url"`
+			Body    string `json:"body"`
+		}
+		endpoint := fmt.Sprintf("%s/repos/%s/releases/tags/%s", r.githubAPIURL, repo, url.PathEscape(tag))
+		found, err := r.getJSONInternal(ctx, endpoint, &release)
+		if err != nil {
+			return nil, err
+		}
+		if found && release.HTMLURL != "" {
+			return &Notes{URL: release.HTMLURL, Body: truncate(release.Body)}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *Resolver) resolveGitLabInternal(ctx context.Context, apiURL, project, version string) (*Notes, error) {
+	for _, tag := range tagCandidates(version) {
+		var release struct {
+			Description string `json:"description"`
+			Links       struct {
+				Self string `json:"self"`
+			} `json:"_links"`
+		}
+		endpoint := fmt.Sprintf("%s/projects/%s/releases/%s", apiURL, url.PathEscape(project), url.PathEscape(tag))
+		found, err := r.getJSONInternal(ctx, endpoint, &release)
+		if err != nil {
+			return nil, err
+		}
+		if found && release.Links.Self != "" {
+			return &Notes{URL: release.Links.Self, Body: truncate(release.Description)}, nil
+		}
+	}
+	return nil, nil
+}
+
+// getJSONInternal decodes the response of a GET request into dest. A 404 is reported as not
+// found rather than as an error.
+func (r *Resolver) getJSONInternal(ctx context.Context, endpoint string, dest any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Arcane")
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("release lookup returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(dest); err != nil {
+		return false, fmt.Errorf("decode release: %w", err)
+	}
+	return true, nil
+}
+
+func truncate(body string) string {
+	body = strings.TrimSpace(body)
+	if len(body) <= MaxBodyLength {
+		return body
+	}
+	// Drop a multi-byte character cut in half.
+	return strings.ToValidUTF8(body[:MaxBodyLength], "") + "…"
+}
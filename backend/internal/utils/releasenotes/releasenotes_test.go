@@ -0,0 +1,128 @@
+package releasenotes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSource(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"https://github.com/org/app":                 "https://github.com/org/app",
+		"https://github.com/org/app.git":             "https://github.com/org/app",
+		"https://github.com/org/app/tree/main/cmd":   "https://github.com/org/app",
+		"git@github.com:org/app.git":                 "https://github.com/org/app",
+		"gitlab.com/group/sub/app":                   "https://gitlab.com/group/sub/app",
+		"https://gitlab.com/group/app/-/tree/master": "https://gitlab.com/group/app",
+	}
+	for in, want := range tests {
+		got, err := normalizeSource(in)
+		if err != nil {
+			t.Fatalf("normalizeSource(%q) err: %v", in, err)
+		}
+		if got.String() != want {
+			t.Errorf("normalizeSource(%q) = %q, want %q", in, got.String(), want)
+		}
+	}
+
+	if _, err := normalizeSource("https://github.com"); err == nil {
+		t.Error("expected an error for a source without repository")
+	}
+}
+
+func TestResolveGitHub(t *testing.T) {
+	t.Parallel()
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path != "/repos/org/app/releases/tags/v1.2.0" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"html_url": "https://github.com/org/app/releases/tag/v1.2.0",
+			"body":     "## Fixes\n- things",
+		})
+	}))
+	defer srv.Close()
+
+	r := NewResolver()
+	r.githubAPIURL = srv.URL
+
+	notes, err := r.Resolve(context.Background(), "https://github.com/org/app", "1.2.0", "abc123", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if notes.URL != "https://github.com/org/app/releases/tag/v1.2.0" || notes.Body != "## Fixes\n- things" {
+		t.Fatalf("notes %+v", notes)
+	}
+	if strings.Join(paths, ",") != "/repos/org/app/releases/tags/1.2.0,/repos/org/app/releases/tags/v1.2.0" {
+		t.Fatalf("paths %v", paths)
+	}
+
+	notes, err = r.Resolve(context.Background(), "https://github.com/org/app", "2.0.0", "abc123", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if notes.URL != "https://github.com/org/app/commit/abc123" || notes.Body != "" {
+		t.Fatalf("fallback notes %+v", notes)
+	}
+}
+
+func TestResolveGitLabOnlyLooksUpAllowedHosts(t *testing.T) {
+	t.Parallel()
+	var paths []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fapp/releases/v1.2.0" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"description": "Fixes",
+			"_links":      map[string]string{"self": "https://gitlab.example.com/group/app/-/releases/v1.2.0"},
+		})
+	}))
+	defer srv.Close()
+
+	r := NewResolver()
+	r.http = srv.Client()
+	host := strings.TrimPrefix(srv.URL, "https://")
+	source := "https://" + host + "/group/app"
+
+	// Hosts that aren't allowed only get a link.
+	notes, err := r.Resolve(context.Background(), source, "1.2.0", "abc123", []string{"gitlab.example.com"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if notes.URL != source || notes.Body != "" || len(paths) != 0 {
+		t.Fatalf("notes %+v, paths %v", notes, paths)
+	}
+
+	notes, err = r.Resolve(context.Background(), source, "1.2.0", "abc123", []string{"gitlab.example.com", " " + host})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if notes.URL != "https://gitlab.example.com/group/app/-/releases/v1.2.0" || notes.Body != "Fixes" {
+		t.Fatalf("notes %+v", notes)
+	}
+	if strings.Join(paths, ",") != "/api/v4/projects/group%2Fapp/releases/1.2.0,/api/v4/projects/group%2Fapp/releases/v1.2.0" {
+		t.Fatalf("paths %v", paths)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+	body := strings.Repeat("é", MaxBodyLength)
+	got := truncate(body)
+	if !strings.HasSuffix(got, "…") || len(got) > MaxBodyLength+len("…") {
+		t.Fatalf("truncate length %d", len(got))
+	}
+	if strings.ContainsRune(strings.TrimSuffix(got, "…"), '�') {
+		t.Fatal("truncate split a character")
+	}
+}
@@ -0,0 +1,478 @@
+// Package sops decrypts dotenv and YAML files encrypted with SOPS using age keys.
+//
+// Only what is needed to read such files is implemented: the data key is decrypted with
+// one of the given age identities and every ENC[...] value is opened with AES-256-GCM,
+// which authenticates each value together with its key path. The file level MAC, which
+// covers every value and their order, is verified like SOPS does so that values can't be
+// added, removed or changed without the data key.
+package sops
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/goccy/go-yaml"
+)
+
+// Format is the SOPS store format of a file.
+type Format string
+
+const (
+	FormatDotenv Format = "dotenv"
+	FormatYAML   Format = "yaml"
+)
+
+const (
+	// metadataKey is the top-level key SOPS stores its metadata under in YAML files.
+	metadataKey = "sops"
+	// dotenvMetadataPrefix prefixes the flattened metadata keys in dotenv files.
+	dotenvMetadataPrefix = "sops_"
+)
+
+var encryptedValueRe = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.+),tag:(.+),type:(.+)\]$`)
+
+var (
+	ErrNoMatchingIdentity = errors.New("no age identity matches the recipients the file is encrypted for")
+	ErrMacMismatch        = errors.New("file MAC does not match its content, the file may have been tampered with")
+)
+
+// FormatForPath returns the format SOPS uses for a file based on its name.
+func FormatForPath(path string) (Format, bool) {
+	base := strings.ToLower(filepath.Base(path))
+	switch {
+	case base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env"):
+		return FormatDotenv, true
+	case strings.HasSuffix(base, ".yaml") || strings.HasSuffix(base, ".yml"):
+		return FormatYAML, true
+	default:
+		return "", false
+	}
+}
+
+// IsEncrypted reports whether content is a SOPS encrypted file in the given format.
+func IsEncrypted(content string, format Format) bool {
+	switch format {
+	case FormatDotenv:
+		for line := range strings.SplitSeq(content, "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), dotenvMetadataPrefix+"mac=") {
+				return true
+			}
+		}
+		return false
+	case FormatYAML:
+		if !strings.Contains(content, metadataKey+":") {
+			return false
+		}
+		var doc struct {
+			Sops *struct {
+				Mac string `yaml:"mac"`
+			} `yaml:"sops"`
+		}
+		if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+			return false
+		}
+		return doc.Sops != nil && doc.Sops.Mac != ""
+	default:
+		return false
+	}
+}
+
+// ParseAgeKey parses an age key file, as written by age-keygen, and returns its
+// identities together with the matching public recipients.
+func ParseAgeKey(key string) ([]age.Identity, []string, error) {
+	identities, err := age.ParseIdentities(strings.NewReader(key))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid age key: %w", err)
+	}
+
+	recipients := make([]string, 0, len(identities))
+	for _, identity := range identities {
+		if x25519, ok := identity.(*age.X25519Identity); ok {
+			recipients = append(recipients, x25519.Recipient().String())
+		}
+	}
+	return identities, recipients, nil
+}
+
+// Decrypt returns the plaintext of a SOPS encrypted file, without its SOPS metadata.
+func Decrypt(content string, format Format, identities []age.Identity) (string, error) {
+	switch format {
+	case FormatDotenv:
+		return decryptDotenv(content, identities)
+	case FormatYAML:
+		return decryptYAML(content, identities)
+	default:
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// ageStanza is a copy of the data key encrypted for a single age recipient.
+type ageStanza struct {
+	Recipient string `yaml:"recipient"`
+	Enc       string `yaml:"enc"`
+}
+
+func decryptDotenv(content string, identities []age.Identity) (string, error) {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+
+	// SOPS flattens its metadata into keys such as sops_age__list_0__map_enc.
+	stanzas := map[string]*ageStanza{}
+	var meta macMetadata
+	for _, line := range lines {
+		key, value, ok := strings.Cut(line, "=")
+		switch key {
+		case dotenvMetadataPrefix + "mac":
+			meta.Mac = value
+		case dotenvMetadataPrefix + "lastmodified":
+			meta.LastModified = value
+		case dotenvMetadataPrefix + "mac_only_encrypted":
+			meta.MacOnlyEncrypted, _ = strconv.ParseBool(value)
+		}
+		if !ok || !strings.HasPrefix(key, dotenvMetadataPrefix+"age__list_") {
+			continue
+		}
+		index, field, ok := strings.Cut(strings.TrimPrefix(key, dotenvMetadataPrefix+"age__list_"), "__map_")
+		if !ok {
+			continue
+		}
+		stanza := stanzas[index]
+		if stanza == nil {
+			stanza = &ageStanza{}
+			stanzas[index] = stanza
+		}
+		switch field {
+		case "recipient":
+			stanza.Recipient = value
+		case "enc":
+			stanza.Enc = strings.ReplaceAll(value, `\n`, "\n")
+		}
+	}
+
+	indexes := make([]string, 0, len(stanzas))
+	for index := range stanzas {
+		indexes = append(indexes, index)
+	}
+	sort.Strings(indexes)
+	ordered := make([]ageStanza, 0, len(stanzas))
+	for _, index := range indexes {
+		ordered = append(ordered, *stanzas[index])
+	}
+
+	dataKey, err := decryptDataKey(ordered, identities)
+	if err != nil {
+		return "", err
+	}
+
+	mac := newMacHasher(meta.MacOnlyEncrypted)
+	var out strings.Builder
+	for _, line := range lines {
+		if comment, ok := strings.CutPrefix(line, "#"); ok {
+			if encryptedValueRe.MatchString(comment) {
+				// Comments are bound to the path of their parent, which is the root here.
+				plain, err := decryptValue(comment, dataKey, ":")
+				if err != nil {
+					return "", fmt.Errorf("failed to decrypt comment: %w", err)
+				}
+				comment = fmt.Sprint(plain)
+			}
+			out.WriteString("#" + comment + "\n")
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			out.WriteString(line + "\n")
+			continue
+		}
+		if strings.HasPrefix(key, dotenvMetadataPrefix) {
+			continue
+		}
+		if encryptedValueRe.MatchString(value) {
+			plain, err := decryptValue(value, dataKey, key+":")
+			if err != nil {
+				return "", fmt.Errorf("failed to decrypt %s: %w", key, err)
+			}
+			if err := mac.add(plain, true); err != nil {
+				return "", err
+			}
+			value = strings.ReplaceAll(fmt.Sprint(plain), "\n", `\n`)
+		} else {
+			// SOPS reads escaped newlines in plain values before hashing them.
+			if err := mac.add(strings.ReplaceAll(value, `\n`, "\n"), false); err != nil {
+				return "", err
+			}
+		}
+		out.WriteString(key + "=" + value + "\n")
+	}
+
+	if err := mac.verify(meta, dataKey); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func decryptYAML(content string, identities []age.Identity) (string, error) {
+	var root yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions([]byte(content), &root, yaml.UseOrderedMap()); err != nil {
+		return "", fmt.Errorf("failed to parse yaml: %w", err)
+	}
+
+	var metadata struct {
+		Age              []ageStanza `yaml:"age"`
+		Mac              string      `yaml:"mac"`
+		LastModified     string      `yaml:"lastmodified"`
+		MacOnlyEncrypted bool        `yaml:"mac_only_encrypted"`
+	}
+	data := make(yaml.MapSlice, 0, len(root))
+	found := false
+	for _, item := range root {
+		if key, _ := item.Key.(string); key == metadataKey {
+			raw, err := yaml.Marshal(item.Value)
+			if err != nil {
+				return "", fmt.Errorf("failed to read sops metadata: %w", err)
+			}
+			if err := yaml.Unmarshal(raw, &metadata); err != nil {
+				return "", fmt.Errorf("failed to read sops metadata: %w", err)
+			}
+			found = true
+			continue
+		}
+		data = append(data, item)
+	}
+	if !found {
+		return "", errors.New("sops metadata not found")
+	}
+
+	dataKey, err := decryptDataKey(metadata.Age, identities)
+	if err != nil {
+		return "", err
+	}
+
+	mac := newMacHasher(metadata.MacOnlyEncrypted)
+	decrypted, err := decryptTree(data, dataKey, nil, mac)
+	if err != nil {
+		return "", err
+	}
+	meta := macMetadata{Mac: metadata.Mac, LastModified: metadata.LastModified, MacOnlyEncrypted: metadata.MacOnlyEncrypted}
+	if err := mac.verify(meta, dataKey); err != nil {
+		return "", err
+	}
+
+	out, err := yaml.Marshal(decrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode yaml: %w", err)
+	}
+	return string(out), nil
+}
+
+// decryptTree walks a YAML document and decrypts every encrypted value, adding each value to
+// the MAC in document order. SOPS binds each value to the path of map keys leading to it;
+// list items share their parent's path.
+func decryptTree(node any, dataKey []byte, path []string, mac *macHasher) (any, error) {
+	switch v := node.(type) {
+	case yaml.MapSlice:
+		out := make(yaml.MapSlice, 0, len(v))
+		for _, item := range v {
+			value, err := decryptTree(item.Value, dataKey, append(path[:len(path):len(path)], fmt.Sprint(item.Key)), mac)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, yaml.MapItem{Key: item.Key, Value: value})
+		}
+		return out, nil
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, item := range v {
+			value, err := decryptTree(item, dataKey, path, mac)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, value)
+		}
+		return out, nil
+	case nil:
+		return nil, nil
+	case string:
+		if !encryptedValueRe.MatchString(v) {
+			return v, mac.add(v, false)
+		}
+		value, err := decryptValue(v, dataKey, strings.Join(path, ":")+":")
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", strings.Join(path, "."), err)
+		}
+		return value, mac.add(value, true)
+	default:
+		return v, mac.add(v, false)
+	}
+}
+
+// macMetadata holds the metadata needed to verify a file's MAC.
+type macMetadata struct {
+	Mac              string
+	LastModified     string
+	MacOnlyEncrypted bool
+}
+
+// macOnlyEncryptedInitialization seeds the MAC of files with mac_only_encrypted set, so that
+// it always differs from the MAC of the same file without the setting.
+var macOnlyEncryptedInitialization = []byte{
+	0x8a, 0x3f, 0xd2, 0xad, 0x54, 0xce, 0x66, 0x52, 0x7b, 0x10, 0x34, 0xf3, 0xd1, 0x47, 0xbe, 0x0b,
+	0x0b, 0x97, 0x5b, 0x3b, 0xf4, 0x4f, 0x72, 0xc6, 0xfd, 0xad, 0xec, 0x81, 0x76, 0xf2, 0x7d, 0x69,
+}
+
+// macHasher computes the MAC SOPS stores for a file: a SHA-512 over the plaintext of every
+// value in document order, or only of encrypted values when mac_only_encrypted is set.
+// Comments are never included.
+type macHasher struct {
+	hash          hash.Hash
+	onlyEncrypted bool
+}
+
+func newMacHasher(onlyEncrypted bool) *macHasher {
+	h := sha512.New()
+	if onlyEncrypted {
+		h.Write(macOnlyEncryptedInitialization)
+	}
+	return &macHasher{hash: h, onlyEncrypted: onlyEncrypted}
+}
+
+func (m *macHasher) add(value any, encrypted bool) error {
+	if m.onlyEncrypted && !encrypted {
+		return nil
+	}
+
+	var b []byte
+	switch v := value.(type) {
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	case int:
+		b = []byte(strconv.Itoa(v))
+	case int64:
+		b = []byte(strconv.FormatInt(v, 10))
+	case uint64:
+		b = []byte(strconv.FormatUint(v, 10))
+	case float64:
+		b = []byte(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		// SOPS hashes booleans the way Python, which it was first written in, prints them.
+		b = []byte("False")
+		if v {
+			b = []byte("True")
+		}
+	default:
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+	m.hash.Write(b)
+	return nil
+}
+
+// verify decrypts the stored MAC, which is bound to the last modified timestamp, and compares
+// it with the computed one.
+func (m *macHasher) verify(meta macMetadata, dataKey []byte) error {
+	if meta.Mac == "" {
+		return errors.New("file has no MAC")
+	}
+	lastModified, err := time.Parse(time.RFC3339, meta.LastModified)
+	if err != nil {
+		return fmt.Errorf("invalid lastmodified timestamp: %w", err)
+	}
+
+	stored, err := decryptValue(meta.Mac, dataKey, lastModified.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt MAC: %w", err)
+	}
+	storedMac, ok := stored.(string)
+	computed := fmt.Sprintf("%X", m.hash.Sum(nil))
+	if !ok || subtle.ConstantTimeCompare([]byte(storedMac), []byte(computed)) != 1 {
+		return ErrMacMismatch
+	}
+	return nil
+}
+
+// decryptDataKey recovers the file's data key from the first stanza one of the identities
+// can open.
+func decryptDataKey(stanzas []ageStanza, identities []age.Identity) ([]byte, error) {
+	if len(stanzas) == 0 {
+		return nil, errors.New("file is not encrypted with age")
+	}
+
+	for _, stanza := range stanzas {
+		r, err := age.Decrypt(armor.NewReader(strings.NewReader(stanza.Enc)), identities...)
+		if err != nil {
+			var noMatch *age.NoIdentityMatchError
+			if errors.As(err, &noMatch) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to decrypt data key for %s: %w", stanza.Recipient, err)
+		}
+		dataKey, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt data key for %s: %w", stanza.Recipient, err)
+		}
+		return dataKey, nil
+	}
+	return nil, ErrNoMatchingIdentity
+}
+
+func decryptValue(value string, dataKey []byte, additionalData string) (any, error) {
+	matches := encryptedValueRe.FindStringSubmatch(value)
+	if matches == nil {
+		return nil, errors.New("malformed encrypted value")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(matches[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid data: %w", err)
+	}
+	iv, err := base64.StdEncoding.DecodeString(matches[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid iv: %w", err)
+	}
+	tag, err := base64.StdEncoding.DecodeString(matches[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag: %w", err)
+	}
+
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	switch valueType := matches[4]; valueType {
+	case "str", "bytes", "comment":
+		return string(plaintext), nil
+	case "int":
+		return strconv.Atoi(string(plaintext))
+	case "float":
+		return strconv.ParseFloat(string(plaintext), 64)
+	case "bool":
+		return strconv.ParseBool(string(plaintext))
+	default:
+		return nil, fmt.Errorf("unknown value type %q", valueType)
+	}
+}
@@ -0,0 +1,213 @@
+package sops
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/goccy/go-yaml"
+)
+
+// The files in testdata were encrypted by the sops CLI (3.13.2) for the age key in
+// testdata/age.key, and the *.decrypted.* files are what "sops decrypt" returns for them:
+//
+//	sops encrypt --age <recipient> --input-type dotenv --output-type dotenv secrets.env
+//	sops encrypt --config mac-only-encrypted.sops.yaml --input-type dotenv --output-type dotenv secrets.env
+//	sops encrypt --age <recipient> --encrypted-regex '^(API_TOKEN|WORKERS|command)$' compose.yaml
+
+func readTestdata(t *testing.T, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", name, err)
+	}
+	return string(content)
+}
+
+func testIdentities(t *testing.T) []age.Identity {
+	t.Helper()
+	identities, _, err := ParseAgeKey(readTestdata(t, "age.key"))
+	if err != nil {
+		t.Fatalf("ParseAgeKey() returned error: %v", err)
+	}
+	return identities
+}
+
+func TestDecryptDotenv(t *testing.T) {
+	identities := testIdentities(t)
+	want := readTestdata(t, "secrets.decrypted.env")
+
+	for _, name := range []string{"secrets.env", "mac-only.env"} {
+		content := readTestdata(t, name)
+		if !IsEncrypted(content, FormatDotenv) {
+			t.Fatalf("IsEncrypted(%s) = false, want true", name)
+		}
+
+		got, err := Decrypt(content, FormatDotenv, identities)
+		if err != nil {
+			t.Fatalf("Decrypt(%s) returned error: %v", name, err)
+		}
+		if got != want {
+			t.Errorf("Decrypt(%s) =\n%s\nwant\n%s", name, got, want)
+		}
+	}
+}
+
+func TestDecryptYAML(t *testing.T) {
+	content := readTestdata(t, "compose.yaml")
+	if !IsEncrypted(content, FormatYAML) {
+		t.Fatal("IsEncrypted() = false, want true")
+	}
+
+	got, err := Decrypt(content, FormatYAML, testIdentities(t))
+	if err != nil {
+		t.Fatalf("Decrypt() returned error: %v", err)
+	}
+
+	// The indentation differs from the sops CLI output, the document must not.
+	var gotDoc, wantDoc any
+	if err := yaml.Unmarshal([]byte(got), &gotDoc); err != nil {
+		t.Fatalf("Decrypt() returned invalid YAML: %v\n%s", err, got)
+	}
+	if err := yaml.Unmarshal([]byte(readTestdata(t, "compose.decrypted.yaml")), &wantDoc); err != nil {
+		t.Fatal(err)
+	}
+	gotYAML, _ := yaml.Marshal(gotDoc)
+	wantYAML, _ := yaml.Marshal(wantDoc)
+	if string(gotYAML) != string(wantYAML) {
+		t.Errorf("Decrypt() =\n%s\nwant\n%s", gotYAML, wantYAML)
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	content := readTestdata(t, "secrets.env")
+
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt(content, FormatDotenv, []age.Identity{other}); !errors.Is(err, ErrNoMatchingIdentity) {
+		t.Errorf("Decrypt() with wrong identity error = %v, want %v", err, ErrNoMatchingIdentity)
+	}
+
+	// A value moved to another key fails authentication.
+	moved := strings.Replace(content, "DB_PASSWORD=", "OTHER=", 1)
+	if _, err := Decrypt(moved, FormatDotenv, testIdentities(t)); err == nil {
+		t.Error("Decrypt() of a value moved to another key succeeded, want error")
+	}
+}
+
+func TestDecryptDetectsTampering(t *testing.T) {
+	identities := testIdentities(t)
+	content := readTestdata(t, "secrets.env")
+	lines := strings.SplitAfter(content, "\n")
+	password, cert := lines[1], lines[2]
+
+	tampered := map[string]string{
+		"changed plain value": strings.Replace(content, "LOG_LEVEL_unencrypted=debug", "LOG_LEVEL_unencrypted=trace", 1),
+		"added plain value":   strings.Replace(content, "LOG_LEVEL_unencrypted=debug\n", "LOG_LEVEL_unencrypted=debug\nEXTRA=1\n", 1),
+		"removed value":       strings.Replace(content, cert, "", 1),
+		"reordered values":    strings.Replace(content, password+cert, cert+password, 1),
+	}
+	for name, content := range tampered {
+		if _, err := Decrypt(content, FormatDotenv, identities); !errors.Is(err, ErrMacMismatch) {
+			t.Errorf("Decrypt() with %s error = %v, want %v", name, err, ErrMacMismatch)
+		}
+	}
+
+	// The MAC is bound to the last modified timestamp and can't be dropped.
+	var lastModified, mac string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "sops_lastmodified=") {
+			lastModified = line
+		}
+		if strings.HasPrefix(line, "sops_mac=") {
+			mac = line
+		}
+	}
+	if _, err := Decrypt(strings.Replace(content, lastModified, "sops_lastmodified=2024-02-01T00:00:00Z\n", 1), FormatDotenv, identities); err == nil {
+		t.Error("Decrypt() with a changed lastmodified succeeded, want error")
+	}
+	if _, err := Decrypt(strings.Replace(content, mac, "sops_mac=\n", 1), FormatDotenv, identities); err == nil {
+		t.Error("Decrypt() without a MAC succeeded, want error")
+	}
+
+	// With mac_only_encrypted, plain values can change without invalidating the MAC.
+	macOnly := readTestdata(t, "mac-only.env")
+	if _, err := Decrypt(strings.Replace(macOnly, "LOG_LEVEL_unencrypted=debug", "LOG_LEVEL_unencrypted=trace", 1), FormatDotenv, identities); err != nil {
+		t.Errorf("Decrypt() with mac_only_encrypted returned error: %v", err)
+	}
+	macOnlyLines := strings.SplitAfter(macOnly, "\n")
+	if _, err := Decrypt(strings.Replace(macOnly, macOnlyLines[2], "", 1), FormatDotenv, identities); !errors.Is(err, ErrMacMismatch) {
+		t.Errorf("Decrypt() with mac_only_encrypted and a removed value error = %v, want %v", err, ErrMacMismatch)
+	}
+
+	// YAML values are hashed in document order, including plain and non-string ones.
+	compose := readTestdata(t, "compose.yaml")
+	for name, content := range map[string]string{
+		"changed plain value": strings.Replace(compose, "image: nginx:1.27", "image: nginx:1.28", 1),
+		"changed plain bool":  strings.Replace(compose, "DEBUG: true", "DEBUG: false", 1),
+		"added plain value":   strings.Replace(compose, "        command:\n", "        restart: always\n        command:\n", 1),
+	} {
+		if _, err := Decrypt(content, FormatYAML, identities); !errors.Is(err, ErrMacMismatch) {
+			t.Errorf("Decrypt() of yaml with %s error = %v, want %v", name, err, ErrMacMismatch)
+		}
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	tests := []struct {
+		content string
+		format  Format
+		want    bool
+	}{
+		{content: "FOO=bar\n", format: FormatDotenv, want: false},
+		{content: "FOO=bar\nsops_mac=ENC[...]\n", format: FormatDotenv, want: true},
+		{content: "services:\n  web:\n    image: nginx\n", format: FormatYAML, want: false},
+		{content: "x-sops: {}\nservices: {}\n", format: FormatYAML, want: false},
+		{content: "foo: bar\nsops:\n  mac: ENC[...]\n", format: FormatYAML, want: true},
+	}
+
+	for _, tt := range tests {
+		if got := IsEncrypted(tt.content, tt.format); got != tt.want {
+			t.Errorf("IsEncrypted(%q, %s) = %v, want %v", tt.content, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	tests := map[string]Format{
+		".env":                 "dotenv",
+		"config/.env.prod":     "dotenv",
+		"secrets.env":          "dotenv",
+		"compose.yaml":         "yaml",
+		"stack/secrets.yml":    "yaml",
+		"html/index.html":      "",
+		"config/settings.json": "",
+	}
+
+	for path, want := range tests {
+		got, _ := FormatForPath(path)
+		if got != want {
+			t.Errorf("FormatForPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestParseAgeKey(t *testing.T) {
+	identities, recipients, err := ParseAgeKey(readTestdata(t, "age.key"))
+	if err != nil {
+		t.Fatalf("ParseAgeKey() returned error: %v", err)
+	}
+	want := "age13n2vh2ter8pdrvanz2m7xl47p7yjldhekv3s9kawss9vrurn24nqtuvwxr"
+	if len(identities) != 1 || len(recipients) != 1 || recipients[0] != want {
+		t.Errorf("ParseAgeKey() = %v, %v", identities, recipients)
+	}
+
+	if _, _, err := ParseAgeKey("not a key"); err == nil {
+		t.Error("ParseAgeKey() with invalid key succeeded, want error")
+	}
+}
@@ -0,0 +1,35 @@
+// Package version parses the version numbers used in git and image tags, so that GitOps ref
+// selectors and image tag updates order versions the same way.
+package version
+
+import (
+	"github.com/Masterminds/semver/v3"
+)
+
+// Parse parses a tag name such as "v1.2.3", "1.2" or "16.4-alpine" as a semantic version,
+// filling in missing parts with zeros. It returns false for tags that aren't versions, such
+// as "latest".
+func Parse(tag string) (*semver.Version, bool) {
+	v, err := semver.NewVersion(tag)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// Compare orders two tag names by version. Tags that aren't versions sort before those that
+// are and compare equal to each other.
+func Compare(a, b string) int {
+	va, okA := Parse(a)
+	vb, okB := Parse(b)
+	switch {
+	case okA && okB:
+		return va.Compare(vb)
+	case okA:
+		return 1
+	case okB:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,41 @@
+package version
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := map[string]string{
+		"v1.2.3":      "1.2.3",
+		"1.2":         "1.2.0",
+		"16":          "16.0.0",
+		"16.4-alpine": "16.4.0-alpine",
+		"latest":      "",
+		"18beta1":     "",
+		"1.2.3.4":     "",
+	}
+
+	for tag, want := range tests {
+		v, ok := Parse(tag)
+		if ok != (want != "") || (ok && v.String() != want) {
+			t.Errorf("Parse(%q) = %v, %v; want %q", tag, v, ok, want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "v1.10.0", b: "v1.9.0", want: 1},
+		{a: "1.2", b: "v1.2.0", want: 0},
+		{a: "1.0.0-rc.1", b: "1.0.0", want: -1},
+		{a: "latest", b: "0.0.1", want: -1},
+		{a: "main", b: "latest", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
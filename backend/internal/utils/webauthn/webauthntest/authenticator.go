@@ -0,0 +1,203 @@
+// Package webauthntest provides a software authenticator for testing WebAuthn ceremonies
+// without a browser or security key.
+package webauthntest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Authenticator is an in-memory platform authenticator creating ES256 passkeys. Setting
+// UserVerification to false makes it skip user verification, like a security key without a
+// PIN.
+type Authenticator struct {
+	Origin           string
+	UserVerification bool
+	AAGUID           [16]byte
+	credentials      []*credential
+}
+
+type credential struct {
+	id         []byte
+	rpID       string
+	userHandle []byte
+	key        *ecdsa.PrivateKey
+	signCount  uint32
+}
+
+// Registration is the response of a registration ceremony.
+type Registration struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AttestationObject []byte
+}
+
+// Assertion is the response of an authentication ceremony.
+type Assertion struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+	UserHandle        []byte
+}
+
+// New returns an authenticator that reports origin as the calling page.
+func New(origin string) *Authenticator {
+	return &Authenticator{Origin: origin, UserVerification: true}
+}
+
+// Create makes a new discoverable credential for rpID and userHandle, with "none" attestation.
+func (a *Authenticator) Create(rpID string, userHandle, challenge []byte) (*Registration, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	cred := &credential{id: id, rpID: rpID, userHandle: userHandle, key: key}
+	a.credentials = append(a.credentials, cred)
+
+	pub, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	coseKey := encodeCBOR(map[int64]any{1: int64(2), 3: int64(-7), -1: int64(1), -2: pub[1:33], -3: pub[33:]})
+
+	attested := append([]byte(nil), a.AAGUID[:]...)
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(id)))
+	attested = append(attested, id...)
+	attested = append(attested, coseKey...)
+
+	authData := a.authenticatorDataInternal(rpID, 0x40, 0, attested)
+	return &Registration{
+		CredentialID:      id,
+		ClientDataJSON:    a.clientDataInternal("webauthn.create", challenge),
+		AttestationObject: encodeCBOR(map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": authData}),
+	}, nil
+}
+
+// Get signs challenge with the credential for rpID. A nil credentialID picks the first
+// discoverable credential, like a passkey prompt without an allow list.
+func (a *Authenticator) Get(rpID string, credentialID, challenge []byte) (*Assertion, error) {
+	var cred *credential
+	for _, c := range a.credentials {
+		if c.rpID == rpID && (credentialID == nil || string(c.id) == string(credentialID)) {
+			cred = c
+			break
+		}
+	}
+	if cred == nil {
+		return nil, errors.New("no matching credential")
+	}
+
+	cred.signCount++
+	authData := a.authenticatorDataInternal(rpID, 0, cred.signCount, nil)
+	clientDataJSON := a.clientDataInternal("webauthn.get", challenge)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, cred.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Assertion{
+		CredentialID:      cred.id,
+		ClientDataJSON:    clientDataJSON,
+		AuthenticatorData: authData,
+		Signature:         sig,
+		UserHandle:        cred.userHandle,
+	}, nil
+}
+
+func (a *Authenticator) clientDataInternal(typ string, challenge []byte) []byte {
+	b, _ := json.Marshal(map[string]any{
+		"type":        typ,
+		"challenge":   base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":      a.Origin,
+		"crossOrigin": false,
+	})
+	return b
+}
+
+func (a *Authenticator) authenticatorDataInternal(rpID string, flags byte, signCount uint32, attested []byte) []byte {
+	// User present, backup eligible and backed up, like a synced passkey.
+	flags |= 0x01 | 0x08 | 0x10
+	if a.UserVerification {
+		flags |= 0x04
+	}
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	b := append([]byte(nil), rpIDHash[:]...)
+	b = append(b, flags)
+	b = binary.BigEndian.AppendUint32(b, signCount)
+	return append(b, attested...)
+}
+
+// encodeCBOR encodes the values the authenticator produces using canonical CBOR.
+func encodeCBOR(v any) []byte {
+	switch v := v.(type) {
+	case int64:
+		if v < 0 {
+			return cborHeader(1, uint64(-1-v))
+		}
+		return cborHeader(0, uint64(v))
+	case []byte:
+		return append(cborHeader(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHeader(3, uint64(len(v))), v...)
+	case map[int64]any:
+		entries := make([][2][]byte, 0, len(v))
+		for k, val := range v {
+			entries = append(entries, [2][]byte{encodeCBOR(k), encodeCBOR(val)})
+		}
+		return cborMap(entries)
+	case map[string]any:
+		entries := make([][2][]byte, 0, len(v))
+		for k, val := range v {
+			entries = append(entries, [2][]byte{encodeCBOR(k), encodeCBOR(val)})
+		}
+		return cborMap(entries)
+	}
+	panic(fmt.Sprintf("webauthntest: cannot encode %T", v))
+}
+
+func cborMap(entries [][2][]byte) []byte {
+	// Canonical CBOR sorts keys by length first, then bytewise.
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i][0], entries[j][0]
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return string(a) < string(b)
+	})
+	out := cborHeader(5, uint64(len(entries)))
+	for _, e := range entries {
+		out = append(out, e[0]...)
+		out = append(out, e[1]...)
+	}
+	return out
+}
+
+func cborHeader(major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return []byte{m | byte(n)}
+	case n <= 0xff:
+		return []byte{m | 24, byte(n)}
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{m | 25}, uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{m | 26}, uint32(n))
+	}
+	return binary.BigEndian.AppendUint64([]byte{m | 27}, n)
+}
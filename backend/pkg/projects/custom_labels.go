@@ -22,6 +22,7 @@ const (
 	arcaneIconKey  = "icon"
 	arcaneIconsKey = "icons"
 	arcaneURLsKey  = "urls"
+	arcaneUpdater  = "updater"
 )
 
 // ArcaneComposeMetadata represents Arcane-specific configuration extracted from a Compose file.
@@ -32,6 +33,9 @@ type ArcaneComposeMetadata struct {
 	ProjectURLS []string
 	// ServiceIcons maps service names to their respective icon identifiers or URLs.
 	ServiceIcons map[string]string
+	// UpdatePolicy holds the project wide auto-update policy from x-arcane.updater, keyed like
+	// the com.getarcaneapp.arcane.updater.* labels without their prefix.
+	UpdatePolicy map[string]string
 }
 
 // ParseArcaneComposeMetadata reads a Docker Compose file and extracts Arcane-specific metadata.
@@ -103,6 +107,7 @@ func extractArcaneComposeMetadata(project *composetypes.Project) ArcaneComposeMe
 
 	if arcaneBlock, ok := project.Extensions[arcaneBlockKey]; ok {
 		meta.ProjectIconURL, meta.ProjectURLS = parseArcaneBlock(arcaneBlock)
+		meta.UpdatePolicy = parseArcaneUpdaterBlock(arcaneBlock)
 	}
 
 	for name, svc := range project.Services {
@@ -133,6 +138,22 @@ func parseArcaneBlock(block any) (string, []string) {
 	return icon, urls
 }
 
+func parseArcaneUpdaterBlock(block any) map[string]string {
+	arcaneBlock, ok := utils.AsStringMap(block)
+	if !ok {
+		return nil
+	}
+	updater, ok := utils.AsStringMap(arcaneBlock[arcaneUpdater])
+	if !ok {
+		return nil
+	}
+	policy := make(map[string]string, len(updater))
+	for k, v := range updater {
+		policy[k] = utils.ToString(v)
+	}
+	return policy
+}
+
 func mergeArcaneComposeMetadata(target *ArcaneComposeMetadata, source ArcaneComposeMetadata) {
 	if target == nil {
 		return
@@ -144,6 +165,10 @@ func mergeArcaneComposeMetadata(target *ArcaneComposeMetadata, source ArcaneComp
 
 	target.ProjectURLS = utils.UniqueNonEmptyStrings(append(target.ProjectURLS, source.ProjectURLS...))
 
+	if target.UpdatePolicy == nil {
+		target.UpdatePolicy = source.UpdatePolicy
+	}
+
 	if target.ServiceIcons == nil {
 		target.ServiceIcons = map[string]string{}
 	}
@@ -58,3 +58,23 @@ services:
 	require.Equal(t, "https://example.com/icon.png", meta.ProjectIconURL)
 	require.Equal(t, []string{"https://example.com/docs"}, meta.ProjectURLS)
 }
+
+func TestParseArcaneComposeMetadata_UpdatePolicy(t *testing.T) {
+	tempDir := t.TempDir()
+
+	composeContent := `services:
+  db:
+    image: postgres:16
+x-arcane:
+  updater:
+    window: sun 03:00-05:00
+    types: digest
+    min-age: 3d
+`
+	composePath := filepath.Join(tempDir, "compose.yaml")
+	require.NoError(t, os.WriteFile(composePath, []byte(composeContent), 0o600))
+
+	meta, err := ParseArcaneComposeMetadata(context.Background(), composePath)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"window": "sun 03:00-05:00", "types": "digest", "min-age": "3d"}, meta.UpdatePolicy)
+}
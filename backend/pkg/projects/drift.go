@@ -0,0 +1,95 @@
+package projects
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	composetypes "github.com/compose-spec/compose-go/v2/types"
+)
+
+// ContainerState is the part of a container's configuration that drift detection compares
+// with the compose service it was created from.
+type ContainerState struct {
+	Name    string
+	Image   string // image reference the container was created with
+	ImageID string // ID of the image the container runs
+	Env     []string
+	Labels  map[string]string
+}
+
+// ServiceDrift lists how a running container differs from its compose service.
+type ServiceDrift struct {
+	Service   string
+	Container string
+	Reasons   []string
+}
+
+// DetectServiceDrift compares a container with the compose service it belongs to and
+// returns a reason for every difference in image, environment or labels. imageID is the
+// ID the service's image reference currently resolves to locally, or empty when unknown.
+//
+// Only values declared by the service are compared: variables and labels the image or
+// compose itself adds to the container are not reported.
+func DetectServiceDrift(svc composetypes.ServiceConfig, imageID string, ctr ContainerState) []string {
+	var reasons []string
+
+	switch {
+	case svc.Image != "" && ctr.Image != svc.Image:
+		reasons = append(reasons, fmt.Sprintf("image is %s, expected %s", ctr.Image, svc.Image))
+	case imageID != "" && ctr.ImageID != "" && ctr.ImageID != imageID:
+		reasons = append(reasons, fmt.Sprintf("runs image %s, but %s is %s", shortImageID(ctr.ImageID), svc.Image, shortImageID(imageID)))
+	}
+
+	env := make(map[string]string, len(ctr.Env))
+	for _, kv := range ctr.Env {
+		k, v, _ := strings.Cut(kv, "=")
+		env[k] = v
+	}
+	keys := make([]string, 0, len(svc.Environment))
+	for k := range svc.Environment {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		want := svc.Environment[k]
+		if want == nil {
+			// Unresolved variables are taken from the host environment at deploy time.
+			continue
+		}
+		// Values aren't included, they often hold secrets.
+		got, ok := env[k]
+		switch {
+		case !ok:
+			reasons = append(reasons, fmt.Sprintf("environment variable %s is not set", k))
+		case got != *want:
+			reasons = append(reasons, fmt.Sprintf("environment variable %s differs", k))
+		}
+	}
+
+	keys = keys[:0]
+	for k := range svc.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		got, ok := ctr.Labels[k]
+		switch {
+		case !ok:
+			reasons = append(reasons, fmt.Sprintf("label %s is missing", k))
+		case got != svc.Labels[k]:
+			reasons = append(reasons, fmt.Sprintf("label %s is %q, expected %q", k, got, svc.Labels[k]))
+		}
+	}
+
+	return reasons
+}
+
+// shortImageID returns the 12 character form docker prints for image IDs.
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,81 @@
+package projects
+
+import (
+	"reflect"
+	"testing"
+
+	composetypes "github.com/compose-spec/compose-go/v2/types"
+)
+
+func TestDetectServiceDrift(t *testing.T) {
+	level := "info"
+	token := "s3cret"
+	svc := composetypes.ServiceConfig{
+		Name:  "web",
+		Image: "nginx:1.27",
+		Environment: composetypes.MappingWithEquals{
+			"LOG_LEVEL": &level,
+			"API_TOKEN": &token,
+			"HOST_VAR":  nil,
+		},
+		Labels: composetypes.Labels{
+			"traefik.enable": "true",
+		},
+	}
+	inSync := ContainerState{
+		Name:    "stack-web-1",
+		Image:   "nginx:1.27",
+		ImageID: "sha256:aaaaaaaaaaaaaaaa",
+		Env:     []string{"PATH=/usr/bin", "LOG_LEVEL=info", "API_TOKEN=s3cret"},
+		Labels: map[string]string{
+			"traefik.enable":             "true",
+			"com.docker.compose.project": "stack",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		imageID string
+		mutate  func(*ContainerState)
+		want    []string
+	}{
+		{name: "in sync", imageID: "sha256:aaaaaaaaaaaaaaaa"},
+		{name: "unknown local image", imageID: ""},
+		{
+			name:    "image reference",
+			imageID: "sha256:aaaaaaaaaaaaaaaa",
+			mutate:  func(c *ContainerState) { c.Image = "nginx:1.25" },
+			want:    []string{"image is nginx:1.25, expected nginx:1.27"},
+		},
+		{
+			name:    "image digest",
+			imageID: "sha256:bbbbbbbbbbbbbbbb",
+			want:    []string{"runs image aaaaaaaaaaaa, but nginx:1.27 is bbbbbbbbbbbb"},
+		},
+		{
+			name:    "environment",
+			imageID: "sha256:aaaaaaaaaaaaaaaa",
+			mutate:  func(c *ContainerState) { c.Env = []string{"LOG_LEVEL=debug"} },
+			want:    []string{"environment variable API_TOKEN is not set", "environment variable LOG_LEVEL differs"},
+		},
+		{
+			name:    "labels",
+			imageID: "sha256:aaaaaaaaaaaaaaaa",
+			mutate:  func(c *ContainerState) { c.Labels = map[string]string{"traefik.enable": "false"} },
+			want:    []string{`label traefik.enable is "false", expected "true"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctr := inSync
+			if tt.mutate != nil {
+				tt.mutate(&ctr)
+			}
+			got := DetectServiceDrift(svc, tt.imageID, ctr)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectServiceDrift() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package projects
+
+import (
+	"regexp"
+	"strings"
+
+	ref "go.podman.io/image/v5/docker/reference"
+)
+
+var imageLineRe = regexp.MustCompile(`^(\s*image:\s*)(["']?)([^"'\s#]+)(["']?)(\s*(?:#.*)?)$`)
+
+// BumpImageTag rewrites the image: entries of a compose file that use repository at oldTag to
+// use newTag instead, and returns the new content with the number of entries changed. The
+// file is edited line by line so comments and formatting are kept. Images given through
+// variables or pinned by digest are left alone.
+func BumpImageTag(content, repository, oldTag, newTag string) (string, int) {
+	repoNamed, err := ref.ParseNormalizedNamed(repository)
+	if err != nil {
+		return content, 0
+	}
+
+	lines := strings.Split(content, "\n")
+	changed := 0
+	for i, line := range lines {
+		m := imageLineRe.FindStringSubmatch(strings.TrimSuffix(line, "\r"))
+		if m == nil || strings.Contains(m[3], "$") || m[2] != m[4] {
+			continue
+		}
+
+		named, err := ref.ParseNormalizedNamed(m[3])
+		if err != nil || named.Name() != repoNamed.Name() {
+			continue
+		}
+		if _, ok := named.(ref.Digested); ok {
+			continue
+		}
+		tagged, ok := named.(ref.NamedTagged)
+		if !ok || tagged.Tag() != oldTag {
+			continue
+		}
+
+		image := strings.TrimSuffix(m[3], ":"+oldTag) + ":" + newTag
+		newLine := m[1] + m[2] + image + m[4] + m[5]
+		if strings.HasSuffix(line, "\r") {
+			newLine += "\r"
+		}
+		lines[i] = newLine
+		changed++
+	}
+
+	if changed == 0 {
+		return content, 0
+	}
+	return strings.Join(lines, "\n"), changed
+}
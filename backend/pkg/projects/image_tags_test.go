@@ -0,0 +1,46 @@
+package projects
+
+import "testing"
+
+func TestBumpImageTag(t *testing.T) {
+	content := `services:
+  db:
+    image: postgres:16.2 # pinned
+  replica:
+    image: "docker.io/library/postgres:16.2"
+  other:
+    image: postgres:15.6
+  digest:
+    image: postgres:16.2@sha256:0000000000000000000000000000000000000000000000000000000000000000
+  templated:
+    image: postgres:${PG_TAG:-16.2}
+  ghcr:
+    image: ghcr.io/acme/postgres:16.2
+`
+	want := `services:
+  db:
+    image: postgres:16.4 # pinned
+  replica:
+    image: "docker.io/library/postgres:16.4"
+  other:
+    image: postgres:15.6
+  digest:
+    image: postgres:16.2@sha256:0000000000000000000000000000000000000000000000000000000000000000
+  templated:
+    image: postgres:${PG_TAG:-16.2}
+  ghcr:
+    image: ghcr.io/acme/postgres:16.2
+`
+
+	got, changed := BumpImageTag(content, "postgres", "16.2", "16.4")
+	if changed != 2 {
+		t.Errorf("changed = %d, want 2", changed)
+	}
+	if got != want {
+		t.Errorf("BumpImageTag() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, changed := BumpImageTag(content, "mysql", "16.2", "16.4"); changed != 0 {
+		t.Errorf("changed = %d for unrelated repository", changed)
+	}
+}
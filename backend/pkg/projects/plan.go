@@ -0,0 +1,119 @@
+package projects
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	composetypes "github.com/compose-spec/compose-go/v2/types"
+)
+
+// ServiceAction describes what applying a compose change does to a service.
+type ServiceAction string
+
+const (
+	ServiceActionCreate    ServiceAction = "create"
+	ServiceActionRecreate  ServiceAction = "recreate"
+	ServiceActionRemove    ServiceAction = "remove"
+	ServiceActionUnchanged ServiceAction = "unchanged"
+)
+
+// ServiceChange is the planned action for a single service.
+type ServiceChange struct {
+	Name   string
+	Action ServiceAction
+	Image  string
+}
+
+// projectDirPlaceholder replaces the working directory when hashing service configs.
+const projectDirPlaceholder = "${ARCANE_PROJECT_DIR}"
+
+// PlanServiceChanges compares the services of the currently deployed compose project with
+// a desired one and returns the action compose would take for each service, sorted by name.
+// current may be nil when the project doesn't exist yet.
+//
+// Paths under currentDir and desiredDir are treated as equal, so a project loaded from a
+// different location (e.g. a git checkout) compares cleanly against the one on disk.
+func PlanServiceChanges(current *composetypes.Project, currentDir string, desired *composetypes.Project, desiredDir string) ([]ServiceChange, error) {
+	currentServices := composetypes.Services{}
+	if current != nil {
+		currentServices = current.Services
+	}
+	desiredServices := composetypes.Services{}
+	if desired != nil {
+		desiredServices = desired.Services
+	}
+
+	changes := make([]ServiceChange, 0, len(desiredServices)+len(currentServices))
+	for name, svc := range desiredServices {
+		change := ServiceChange{Name: name, Image: svc.Image}
+
+		existing, ok := currentServices[name]
+		if !ok {
+			change.Action = ServiceActionCreate
+			changes = append(changes, change)
+			continue
+		}
+
+		desiredHash, err := serviceConfigHash(svc, desiredDir)
+		if err != nil {
+			return nil, fmt.Errorf("hash service %s: %w", name, err)
+		}
+		currentHash, err := serviceConfigHash(existing, currentDir)
+		if err != nil {
+			return nil, fmt.Errorf("hash service %s: %w", name, err)
+		}
+
+		change.Action = ServiceActionUnchanged
+		if desiredHash != currentHash {
+			change.Action = ServiceActionRecreate
+		}
+		changes = append(changes, change)
+	}
+
+	for name, svc := range currentServices {
+		if _, ok := desiredServices[name]; !ok {
+			changes = append(changes, ServiceChange{Name: name, Action: ServiceActionRemove, Image: svc.Image})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes, nil
+}
+
+// serviceConfigHash hashes the parts of a service config that cause compose to recreate
+// its containers, mirroring the fields compose itself leaves out of its config hash.
+func serviceConfigHash(svc composetypes.ServiceConfig, workdir string) (string, error) {
+	svc.Build = nil
+	svc.PullPolicy = ""
+	svc.Scale = nil
+	svc.DependsOn = nil
+	svc.Profiles = nil
+	svc.CustomLabels = nil
+	if svc.Deploy != nil {
+		deploy := *svc.Deploy
+		deploy.Replicas = nil
+		svc.Deploy = &deploy
+	}
+
+	data, err := json.Marshal(svc)
+	if err != nil {
+		return "", err
+	}
+
+	if workdir != "" {
+		quoted, err := json.Marshal(workdir)
+		if err != nil {
+			return "", err
+		}
+		// Strip the surrounding quotes to get the path as it appears inside JSON strings.
+		escaped := quoted[1 : len(quoted)-1]
+		data = bytes.ReplaceAll(data, escaped, []byte(projectDirPlaceholder))
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,84 @@
+package projects
+
+import (
+	"reflect"
+	"testing"
+
+	composetypes "github.com/compose-spec/compose-go/v2/types"
+)
+
+func TestPlanServiceChanges(t *testing.T) {
+	scale := 2
+	current := &composetypes.Project{
+		Services: composetypes.Services{
+			"web": {
+				Name:  "web",
+				Image: "nginx:1.25",
+			},
+			"db": {
+				Name:  "db",
+				Image: "postgres:16",
+				Volumes: []composetypes.ServiceVolumeConfig{
+					{Type: "bind", Source: "/app/data/projects/stack/init", Target: "/docker-entrypoint-initdb.d"},
+				},
+			},
+			"cache": {
+				Name:  "cache",
+				Image: "redis:7",
+			},
+		},
+	}
+	desired := &composetypes.Project{
+		Services: composetypes.Services{
+			"web": {
+				Name:  "web",
+				Image: "nginx:1.27",
+			},
+			"db": {
+				Name:  "db",
+				Image: "postgres:16",
+				Volumes: []composetypes.ServiceVolumeConfig{
+					{Type: "bind", Source: "/app/data/git/mirrors/abc/stack/init", Target: "/docker-entrypoint-initdb.d"},
+				},
+				Scale: &scale,
+			},
+			"worker": {
+				Name:  "worker",
+				Image: "busybox",
+			},
+		},
+	}
+
+	changes, err := PlanServiceChanges(current, "/app/data/projects/stack", desired, "/app/data/git/mirrors/abc/stack")
+	if err != nil {
+		t.Fatalf("PlanServiceChanges() returned error: %v", err)
+	}
+
+	want := []ServiceChange{
+		{Name: "cache", Action: ServiceActionRemove, Image: "redis:7"},
+		{Name: "db", Action: ServiceActionUnchanged, Image: "postgres:16"},
+		{Name: "web", Action: ServiceActionRecreate, Image: "nginx:1.27"},
+		{Name: "worker", Action: ServiceActionCreate, Image: "busybox"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("PlanServiceChanges() =\n%v\nwant\n%v", changes, want)
+	}
+}
+
+func TestPlanServiceChangesNewProject(t *testing.T) {
+	desired := &composetypes.Project{
+		Services: composetypes.Services{
+			"web": {Name: "web", Image: "nginx"},
+		},
+	}
+
+	changes, err := PlanServiceChanges(nil, "", desired, "/tmp/stack")
+	if err != nil {
+		t.Fatalf("PlanServiceChanges() returned error: %v", err)
+	}
+
+	want := []ServiceChange{{Name: "web", Action: ServiceActionCreate, Image: "nginx"}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("PlanServiceChanges() = %v, want %v", changes, want)
+	}
+}
@@ -0,0 +1,199 @@
+package projects
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/getarcaneapp/arcane/backend/pkg/utils"
+	"github.com/goccy/go-yaml"
+)
+
+// ParseFileReferences returns the local files and directories a compose file depends on:
+// include files, env_file entries, extends files, build contexts, relative bind mount
+// sources and file based configs/secrets. Includes and extends files are followed
+// recursively, with their own references resolved relative to their directory as Docker
+// Compose does.
+//
+// Paths are cleaned and relative to the directory of composeFilePath; "." means the whole
+// directory is referenced, e.g. by a build context. Absolute paths, remote build contexts
+// and paths containing variable interpolation are skipped because they can't be resolved
+// without the runtime environment.
+func ParseFileReferences(composeFilePath string) ([]string, error) {
+	baseDir := filepath.Dir(composeFilePath)
+	seen := map[string]struct{}{}
+	visited := map[string]struct{}{}
+
+	if err := collectFileReferencesInternal(composeFilePath, baseDir, seen, visited); err != nil {
+		return nil, err
+	}
+
+	refs := make([]string, 0, len(seen))
+	for ref := range seen {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs, nil
+}
+
+func collectFileReferencesInternal(composeFilePath, baseDir string, seen, visited map[string]struct{}) error {
+	absPath, err := filepath.Abs(composeFilePath)
+	if err != nil {
+		return fmt.Errorf("resolve compose file path: %w", err)
+	}
+	if _, ok := visited[absPath]; ok {
+		return nil
+	}
+	visited[absPath] = struct{}{}
+
+	content, err := os.ReadFile(composeFilePath)
+	if err != nil {
+		return fmt.Errorf("read compose file: %w", err)
+	}
+
+	composeData := map[string]any{}
+	if err := yaml.Unmarshal(content, &composeData); err != nil {
+		return fmt.Errorf("parse compose file: %w", err)
+	}
+
+	fileDir := filepath.Dir(composeFilePath)
+	add := func(candidate string) (string, bool) {
+		rel, ok := relativeReference(baseDir, fileDir, candidate)
+		if ok {
+			seen[rel] = struct{}{}
+		}
+		return rel, ok
+	}
+	follow := func(candidate string) error {
+		rel, ok := add(candidate)
+		if !ok {
+			return nil
+		}
+		nested := filepath.Join(baseDir, rel)
+		if info, err := os.Stat(nested); err != nil || !info.Mode().IsRegular() {
+			return nil //nolint:nilerr // missing files are reported when the project is loaded
+		}
+		return collectFileReferencesInternal(nested, baseDir, seen, visited)
+	}
+
+	includePaths, err := parseIncludePaths(composeFilePath)
+	if err != nil {
+		return err
+	}
+	for _, p := range includePaths {
+		if err := follow(p); err != nil {
+			return err
+		}
+	}
+	if items, ok := composeData["include"].([]any); ok {
+		for _, item := range items {
+			if m, ok := utils.AsStringMap(item); ok {
+				for _, envFile := range utils.Collect(m["env_file"], utils.ToString) {
+					add(envFile)
+				}
+			}
+		}
+	}
+
+	services, _ := utils.AsStringMap(composeData["services"])
+	for _, raw := range services {
+		service, ok := utils.AsStringMap(raw)
+		if !ok {
+			continue
+		}
+
+		for _, entry := range utils.Collect(service["env_file"], envFilePath) {
+			add(entry)
+		}
+
+		if extends, ok := utils.AsStringMap(service["extends"]); ok {
+			if file := utils.ToString(extends["file"]); file != "" {
+				if err := follow(file); err != nil {
+					return err
+				}
+			}
+		}
+
+		addBuildReferences(service["build"], add)
+
+		for _, volume := range utils.Collect(service["volumes"], bindMountSource) {
+			add(volume)
+		}
+	}
+
+	for _, section := range []string{"configs", "secrets"} {
+		entries, _ := utils.AsStringMap(composeData[section])
+		for _, raw := range entries {
+			if entry, ok := utils.AsStringMap(raw); ok {
+				add(utils.ToString(entry["file"]))
+			}
+		}
+	}
+
+	return nil
+}
+
+// relativeReference resolves candidate against fileDir and returns it relative to baseDir.
+func relativeReference(baseDir, fileDir, candidate string) (string, bool) {
+	candidate = strings.TrimSpace(candidate)
+	if candidate == "" || filepath.IsAbs(candidate) || strings.HasPrefix(candidate, "~") || strings.Contains(candidate, "$") {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(baseDir, filepath.Join(fileDir, candidate))
+	if err != nil {
+		return "", false
+	}
+	return rel, true
+}
+
+func envFilePath(v any) string {
+	if m, ok := utils.AsStringMap(v); ok {
+		return utils.ToString(m["path"])
+	}
+	return utils.ToString(v)
+}
+
+// bindMountSource returns the source of a volume entry when it is a relative bind mount.
+func bindMountSource(v any) string {
+	if m, ok := utils.AsStringMap(v); ok {
+		if utils.ToString(m["type"]) != "bind" {
+			return ""
+		}
+		return utils.ToString(m["source"])
+	}
+
+	// Short syntax: only sources starting with "." are host paths, anything else is a
+	// named volume.
+	source, _, _ := strings.Cut(utils.ToString(v), ":")
+	if !strings.HasPrefix(source, ".") {
+		return ""
+	}
+	return source
+}
+
+func addBuildReferences(build any, add func(string) (string, bool)) {
+	var buildContext, dockerfile string
+	if m, ok := utils.AsStringMap(build); ok {
+		buildContext = utils.ToString(m["context"])
+		dockerfile = utils.ToString(m["dockerfile"])
+	} else {
+		buildContext = utils.ToString(build)
+	}
+	if buildContext == "" && dockerfile == "" {
+		return
+	}
+	if strings.Contains(buildContext, "://") || strings.HasPrefix(buildContext, "git@") {
+		return
+	}
+	if buildContext == "" {
+		buildContext = "."
+	}
+
+	add(buildContext)
+	if dockerfile != "" && !filepath.IsAbs(dockerfile) {
+		add(filepath.Join(buildContext, dockerfile))
+	}
+}
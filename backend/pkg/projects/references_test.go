@@ -0,0 +1,120 @@
+package projects
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseFileReferences(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", rel, err)
+		}
+	}
+
+	writeFile("stack/compose.yaml", `
+include:
+  - db/compose.yaml
+services:
+  web:
+    image: nginx
+    env_file:
+      - web.env
+      - path: optional.env
+        required: false
+    volumes:
+      - ./html:/usr/share/nginx/html:ro
+      - data:/data
+      - /var/run/docker.sock:/var/run/docker.sock
+      - type: bind
+        source: ./conf/nginx.conf
+        target: /etc/nginx/nginx.conf
+  app:
+    build:
+      context: ./app
+      dockerfile: Dockerfile.prod
+    extends:
+      file: common.yaml
+      service: base
+  remote:
+    build: https://github.com/example/repo.git
+  templated:
+    image: alpine
+    env_file: ${ENV_FILE}
+configs:
+  settings:
+    file: ./config/settings.json
+  external:
+    external: true
+secrets:
+  token:
+    file: ../secrets/token.txt
+volumes:
+  data: {}
+`)
+	writeFile("stack/db/compose.yaml", `
+services:
+  db:
+    image: postgres
+    env_file: db.env
+    volumes:
+      - ./init:/docker-entrypoint-initdb.d
+`)
+	writeFile("stack/common.yaml", `
+services:
+  base:
+    env_file: ./common.env
+`)
+
+	refs, err := ParseFileReferences(filepath.Join(dir, "stack", "compose.yaml"))
+	if err != nil {
+		t.Fatalf("ParseFileReferences() returned error: %v", err)
+	}
+
+	want := []string{
+		filepath.Join("..", "secrets", "token.txt"),
+		"app",
+		filepath.Join("app", "Dockerfile.prod"),
+		"common.env",
+		"common.yaml",
+		filepath.Join("conf", "nginx.conf"),
+		filepath.Join("config", "settings.json"),
+		filepath.Join("db", "compose.yaml"),
+		filepath.Join("db", "db.env"),
+		filepath.Join("db", "init"),
+		"html",
+		"optional.env",
+		"web.env",
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("ParseFileReferences() =\n%v\nwant\n%v", refs, want)
+	}
+}
+
+func TestParseFileReferencesIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("include:\n  - b.yaml\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("include:\n  - a.yaml\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := ParseFileReferences(filepath.Join(dir, "a.yaml"))
+	if err != nil {
+		t.Fatalf("ParseFileReferences() returned error: %v", err)
+	}
+	if want := []string{"a.yaml", "b.yaml"}; !reflect.DeepEqual(refs, want) {
+		t.Errorf("ParseFileReferences() = %v, want %v", refs, want)
+	}
+}
@@ -59,7 +59,7 @@ func (j *AutoUpdateJob) Run(ctx context.Context) {
 
 	slog.InfoContext(ctx, "auto-update run started")
 
-	result, err := j.updaterService.ApplyPending(ctx, false)
+	result, err := j.updaterService.ApplyPending(ctx, false, nil)
 	if err != nil {
 		slog.ErrorContext(ctx, "auto-update run failed", "err", err)
 		return
@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/getarcaneapp/arcane/backend/internal/services"
+)
+
+type GitOpsDriftJob struct {
+	syncService     *services.GitOpsSyncService
+	settingsService *services.SettingsService
+}
+
+func NewGitOpsDriftJob(syncService *services.GitOpsSyncService, settingsService *services.SettingsService) *GitOpsDriftJob {
+	return &GitOpsDriftJob{
+		syncService:     syncService,
+		settingsService: settingsService,
+	}
+}
+
+func (j *GitOpsDriftJob) Name() string {
+	return "gitops-drift"
+}
+
+func (j *GitOpsDriftJob) Schedule(ctx context.Context) string {
+	return j.settingsService.GetStringSetting(ctx, "gitopsDriftInterval", "0 */10 * * * *")
+}
+
+func (j *GitOpsDriftJob) Run(ctx context.Context) {
+	enabled := j.settingsService.GetBoolSetting(ctx, "gitopsSyncEnabled", true)
+	if !enabled {
+		slog.DebugContext(ctx, "GitOps sync disabled; skipping drift check")
+		return
+	}
+
+	slog.InfoContext(ctx, "GitOps drift check started")
+
+	if err := j.syncService.CheckDriftAll(ctx); err != nil {
+		slog.ErrorContext(ctx, "GitOps drift check failed", "err", err)
+		return
+	}
+
+	slog.InfoContext(ctx, "GitOps drift check completed")
+}
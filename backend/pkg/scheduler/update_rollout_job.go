@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/getarcaneapp/arcane/backend/internal/services"
+)
+
+type UpdateRolloutJob struct {
+	rolloutService  *services.UpdateRolloutService
+	settingsService *services.SettingsService
+}
+
+func NewUpdateRolloutJob(rolloutService *services.UpdateRolloutService, settingsService *services.SettingsService) *UpdateRolloutJob {
+	return &UpdateRolloutJob{
+		rolloutService:  rolloutService,
+		settingsService: settingsService,
+	}
+}
+
+func (j *UpdateRolloutJob) Name() string {
+	return "update-rollout"
+}
+
+func (j *UpdateRolloutJob) Schedule(ctx context.Context) string {
+	return j.settingsService.GetStringSetting(ctx, "updateRolloutInterval", "0 * * * * *")
+}
+
+func (j *UpdateRolloutJob) Run(ctx context.Context) {
+	if err := j.rolloutService.ProcessDue(ctx); err != nil {
+		slog.ErrorContext(ctx, "Update rollout run failed", "err", err)
+	}
+}
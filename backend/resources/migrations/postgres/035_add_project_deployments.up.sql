@@ -0,0 +1,22 @@
+-- Add project_deployments table for storing per-deploy snapshots used by rollbacks
+CREATE TABLE IF NOT EXISTS project_deployments (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    revision INTEGER NOT NULL,
+    action TEXT NOT NULL DEFAULT 'deploy',
+    status TEXT NOT NULL,
+    error TEXT,
+    compose_content TEXT NOT NULL,
+    env_content TEXT,
+    include_files JSONB,
+    image_digests JSONB,
+    source_deployment_id TEXT,
+    user_id TEXT,
+    username TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_deployments_project_id ON project_deployments(project_id);
+CREATE INDEX IF NOT EXISTS idx_project_deployments_project_revision ON project_deployments(project_id, revision);
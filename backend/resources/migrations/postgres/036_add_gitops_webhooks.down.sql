@@ -0,0 +1,2 @@
+ALTER TABLE gitops_syncs DROP COLUMN IF EXISTS webhook_secret;
+ALTER TABLE gitops_syncs DROP COLUMN IF EXISTS webhook_enabled;
@@ -0,0 +1 @@
+ALTER TABLE gitops_syncs DROP COLUMN IF EXISTS last_sync_hash;
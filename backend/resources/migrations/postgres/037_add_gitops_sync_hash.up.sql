@@ -0,0 +1,2 @@
+-- Hash of the files applied by the last successful sync, used to skip no-op syncs
+ALTER TABLE gitops_syncs ADD COLUMN IF NOT EXISTS last_sync_hash TEXT;
@@ -0,0 +1,2 @@
+-- Files besides the compose file and .env that a sync materialized in its project
+ALTER TABLE gitops_syncs ADD COLUMN IF NOT EXISTS synced_files JSONB;
@@ -0,0 +1,2 @@
+ALTER TABLE gitops_syncs DROP COLUMN IF EXISTS sops_age_recipients;
+ALTER TABLE gitops_syncs DROP COLUMN IF EXISTS sops_age_key;
@@ -0,0 +1,3 @@
+ALTER TABLE gitops_syncs DROP COLUMN IF EXISTS last_sync_ref;
+ALTER TABLE gitops_syncs DROP COLUMN IF EXISTS ref;
+ALTER TABLE gitops_syncs DROP COLUMN IF EXISTS ref_type;
@@ -0,0 +1,4 @@
+-- ref selection for GitOps syncs: branch head, tag pattern, semver constraint or commit
+ALTER TABLE gitops_syncs ADD COLUMN IF NOT EXISTS ref_type TEXT NOT NULL DEFAULT 'branch';
+ALTER TABLE gitops_syncs ADD COLUMN IF NOT EXISTS ref TEXT NOT NULL DEFAULT '';
+ALTER TABLE gitops_syncs ADD COLUMN IF NOT EXISTS last_sync_ref TEXT;
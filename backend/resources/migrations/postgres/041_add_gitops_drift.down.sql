@@ -0,0 +1,2 @@
+ALTER TABLE gitops_syncs DROP COLUMN IF EXISTS drift_reasons;
+ALTER TABLE gitops_syncs DROP COLUMN IF EXISTS drift_checked_at;
@@ -0,0 +1,3 @@
+-- result of the last drift check between a GitOps sync, its project and the running containers
+ALTER TABLE gitops_syncs ADD COLUMN IF NOT EXISTS drift_checked_at TIMESTAMPTZ;
+ALTER TABLE gitops_syncs ADD COLUMN IF NOT EXISTS drift_reasons JSONB;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS update_rollouts (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    stages JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'idle',
+    current_stage INTEGER NOT NULL DEFAULT 0,
+    next_stage_at TIMESTAMPTZ,
+    stage_started_at TIMESTAMPTZ,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    last_error TEXT,
+    results JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_update_rollouts_status ON update_rollouts(status);
@@ -0,0 +1,5 @@
+ALTER TABLE image_updates DROP COLUMN IF EXISTS release_notes;
+ALTER TABLE image_updates DROP COLUMN IF EXISTS release_notes_url;
+ALTER TABLE image_updates DROP COLUMN IF EXISTS release_revision;
+ALTER TABLE image_updates DROP COLUMN IF EXISTS release_version;
+ALTER TABLE image_updates DROP COLUMN IF EXISTS release_source;
@@ -0,0 +1,6 @@
+-- where the latest image was built from and its release notes
+ALTER TABLE image_updates ADD COLUMN IF NOT EXISTS release_source TEXT;
+ALTER TABLE image_updates ADD COLUMN IF NOT EXISTS release_version TEXT;
+ALTER TABLE image_updates ADD COLUMN IF NOT EXISTS release_revision TEXT;
+ALTER TABLE image_updates ADD COLUMN IF NOT EXISTS release_notes_url TEXT;
+ALTER TABLE image_updates ADD COLUMN IF NOT EXISTS release_notes TEXT;
@@ -0,0 +1,17 @@
+-- per-environment registry mirrors and pull-through caches tried before the upstream registry
+CREATE TABLE IF NOT EXISTS registry_mirrors (
+    id TEXT PRIMARY KEY,
+    registry TEXT NOT NULL,
+    mirror_url TEXT NOT NULL,
+    repository_pattern TEXT NOT NULL DEFAULT '',
+    description TEXT,
+    priority INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    fallback_to_upstream BOOLEAN NOT NULL DEFAULT TRUE,
+    last_used_at TIMESTAMPTZ,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_registry_mirrors_registry ON registry_mirrors(registry);
@@ -0,0 +1,4 @@
+ALTER TABLE api_keys DROP COLUMN IF EXISTS allowed_cidrs;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS allowed_projects;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS allowed_environments;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS scopes;
@@ -0,0 +1,5 @@
+-- permissions, environment/project allow-lists and source networks an API key is limited to
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes TEXT;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS allowed_environments TEXT;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS allowed_projects TEXT;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS allowed_cidrs TEXT;
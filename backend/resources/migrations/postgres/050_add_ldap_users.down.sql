@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_ldap_dn_unique;
+ALTER TABLE users DROP COLUMN IF EXISTS ldap_dn;
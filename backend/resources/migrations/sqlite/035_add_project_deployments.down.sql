@@ -0,0 +1,2 @@
+-- Drop project_deployments table
+DROP TABLE IF EXISTS project_deployments;
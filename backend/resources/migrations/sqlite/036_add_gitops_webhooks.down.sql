@@ -0,0 +1,2 @@
+ALTER TABLE gitops_syncs DROP COLUMN webhook_secret;
+ALTER TABLE gitops_syncs DROP COLUMN webhook_enabled;
@@ -0,0 +1,3 @@
+-- Per-sync webhook secret so pushes can trigger a sync immediately
+ALTER TABLE gitops_syncs ADD COLUMN webhook_enabled BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE gitops_syncs ADD COLUMN webhook_secret TEXT;
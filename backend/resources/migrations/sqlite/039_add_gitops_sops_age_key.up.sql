@@ -0,0 +1,3 @@
+-- age private key used to decrypt SOPS encrypted files during sync, encrypted at rest
+ALTER TABLE gitops_syncs ADD COLUMN sops_age_key TEXT;
+ALTER TABLE gitops_syncs ADD COLUMN sops_age_recipients TEXT;
@@ -0,0 +1,3 @@
+ALTER TABLE gitops_syncs DROP COLUMN last_sync_ref;
+ALTER TABLE gitops_syncs DROP COLUMN ref;
+ALTER TABLE gitops_syncs DROP COLUMN ref_type;
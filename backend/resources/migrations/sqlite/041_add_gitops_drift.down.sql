@@ -0,0 +1,2 @@
+ALTER TABLE gitops_syncs DROP COLUMN drift_reasons;
+ALTER TABLE gitops_syncs DROP COLUMN drift_checked_at;
@@ -0,0 +1,3 @@
+-- result of the last drift check between a GitOps sync, its project and the running containers
+ALTER TABLE gitops_syncs ADD COLUMN drift_checked_at DATETIME;
+ALTER TABLE gitops_syncs ADD COLUMN drift_reasons TEXT;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS update_rollouts (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    stages TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'idle',
+    current_stage INTEGER NOT NULL DEFAULT 0,
+    next_stage_at DATETIME,
+    stage_started_at DATETIME,
+    started_at DATETIME,
+    finished_at DATETIME,
+    last_error TEXT,
+    results TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_update_rollouts_status ON update_rollouts(status);
@@ -0,0 +1,2 @@
+ALTER TABLE gitops_syncs DROP COLUMN build_images;
+DROP TABLE IF EXISTS image_builds;
@@ -0,0 +1,29 @@
+-- image builds from project directories and git repositories
+CREATE TABLE IF NOT EXISTS image_builds (
+    id TEXT PRIMARY KEY,
+    source TEXT NOT NULL,
+    project_id TEXT,
+    repository_id TEXT,
+    git_ops_sync_id TEXT,
+    ref TEXT NOT NULL DEFAULT '',
+    "commit" TEXT,
+    context_path TEXT NOT NULL DEFAULT '',
+    services TEXT,
+    tags TEXT,
+    target TEXT NOT NULL DEFAULT '',
+    "trigger" TEXT NOT NULL DEFAULT 'manual',
+    status TEXT NOT NULL,
+    error TEXT,
+    log TEXT NOT NULL DEFAULT '',
+    user_id TEXT,
+    username TEXT,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_image_builds_started_at ON image_builds(started_at);
+CREATE INDEX IF NOT EXISTS idx_image_builds_project_id ON image_builds(project_id);
+
+ALTER TABLE gitops_syncs ADD COLUMN build_images BOOLEAN NOT NULL DEFAULT false;
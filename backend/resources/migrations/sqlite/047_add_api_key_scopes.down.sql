@@ -0,0 +1,4 @@
+ALTER TABLE api_keys DROP COLUMN allowed_cidrs;
+ALTER TABLE api_keys DROP COLUMN allowed_projects;
+ALTER TABLE api_keys DROP COLUMN allowed_environments;
+ALTER TABLE api_keys DROP COLUMN scopes;
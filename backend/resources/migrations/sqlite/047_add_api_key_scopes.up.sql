@@ -0,0 +1,5 @@
+-- permissions, environment/project allow-lists and source networks an API key is limited to
+ALTER TABLE api_keys ADD COLUMN scopes TEXT;
+ALTER TABLE api_keys ADD COLUMN allowed_environments TEXT;
+ALTER TABLE api_keys ADD COLUMN allowed_projects TEXT;
+ALTER TABLE api_keys ADD COLUMN allowed_cidrs TEXT;
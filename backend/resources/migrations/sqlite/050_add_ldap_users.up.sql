@@ -0,0 +1,6 @@
+-- Distinguished name of users provisioned from an LDAP directory
+ALTER TABLE users ADD COLUMN ldap_dn TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_ldap_dn_unique
+ON users (ldap_dn)
+WHERE ldap_dn IS NOT NULL;
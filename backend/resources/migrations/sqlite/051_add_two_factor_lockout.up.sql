@@ -0,0 +1,3 @@
+-- Failed two-factor codes in a row, and the lockout they triggered
+ALTER TABLE users ADD COLUMN totp_failed_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN totp_locked_until DATETIME;
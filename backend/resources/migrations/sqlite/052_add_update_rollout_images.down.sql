@@ -0,0 +1 @@
+ALTER TABLE update_rollouts DROP COLUMN images;
@@ -0,0 +1,2 @@
+-- Image updates applied by the first stage of a rollout, the only ones later stages apply
+ALTER TABLE update_rollouts ADD COLUMN images TEXT;
@@ -56,6 +56,8 @@ type ArcaneApiEndpoints struct {
 	ImagesPruneEndpoint  string
 	ImagesCountsEndpoint string
 	ImagesUploadEndpoint string
+	ImageTagEndpoint     string
+	ImagePushEndpoint    string
 
 	// Image Updates
 	ImageUpdatesCheckEndpoint      string
@@ -79,16 +81,18 @@ type ArcaneApiEndpoints struct {
 	VolumeUsageEndpoint   string
 
 	// Projects (Stacks)
-	ProjectsEndpoint        string
-	ProjectEndpoint         string
-	ProjectsCountsEndpoint  string
-	ProjectDestroyEndpoint  string
-	ProjectUpEndpoint       string
-	ProjectDownEndpoint     string
-	ProjectRestartEndpoint  string
-	ProjectRedeployEndpoint string
-	ProjectPullEndpoint     string
-	ProjectIncludesEndpoint string
+	ProjectsEndpoint           string
+	ProjectEndpoint            string
+	ProjectsCountsEndpoint     string
+	ProjectDestroyEndpoint     string
+	ProjectUpEndpoint          string
+	ProjectDownEndpoint        string
+	ProjectRestartEndpoint     string
+	ProjectRedeployEndpoint    string
+	ProjectPullEndpoint        string
+	ProjectIncludesEndpoint    string
+	ProjectDeploymentsEndpoint string
+	ProjectRollbackEndpoint    string
 
 	// System
 	SystemPruneEndpoint                  string
@@ -127,6 +131,9 @@ type ArcaneApiEndpoints struct {
 	ContainerRegistryEndpoint         string
 	ContainerRegistrySyncEndpoint     string
 	ContainerRegistryTestEndpoint     string
+	ContainerRegistryReposEndpoint    string
+	ContainerRegistryTagsEndpoint     string
+	ContainerRegistryManifestEndpoint string
 	EnvironmentSyncRegistriesEndpoint string
 
 	// Events
@@ -216,6 +223,8 @@ var Endpoints = ArcaneApiEndpoints{
 	ImagesPruneEndpoint:  "/api/environments/%s/images/prune",
 	ImagesCountsEndpoint: "/api/environments/%s/images/counts",
 	ImagesUploadEndpoint: "/api/environments/%s/images/upload",
+	ImageTagEndpoint:     "/api/environments/%s/images/%s/tag",
+	ImagePushEndpoint:    "/api/environments/%s/images/%s/push",
 
 	// Image Updates
 	ImageUpdatesCheckEndpoint:      "/api/environments/%s/image-updates/check",
@@ -239,16 +248,18 @@ var Endpoints = ArcaneApiEndpoints{
 	VolumeUsageEndpoint:   "/api/environments/%s/volumes/%s/usage",
 
 	// Projects (Stacks)
-	ProjectsEndpoint:        "/api/environments/%s/projects",
-	ProjectEndpoint:         "/api/environments/%s/projects/%s",
-	ProjectsCountsEndpoint:  "/api/environments/%s/projects/counts",
-	ProjectDestroyEndpoint:  "/api/environments/%s/projects/%s/destroy",
-	ProjectUpEndpoint:       "/api/environments/%s/projects/%s/up",
-	ProjectDownEndpoint:     "/api/environments/%s/projects/%s/down",
-	ProjectRestartEndpoint:  "/api/environments/%s/projects/%s/restart",
-	ProjectRedeployEndpoint: "/api/environments/%s/projects/%s/redeploy",
-	ProjectPullEndpoint:     "/api/environments/%s/projects/%s/pull",
-	ProjectIncludesEndpoint: "/api/environments/%s/projects/%s/includes",
+	ProjectsEndpoint:           "/api/environments/%s/projects",
+	ProjectEndpoint:            "/api/environments/%s/projects/%s",
+	ProjectsCountsEndpoint:     "/api/environments/%s/projects/counts",
+	ProjectDestroyEndpoint:     "/api/environments/%s/projects/%s/destroy",
+	ProjectUpEndpoint:          "/api/environments/%s/projects/%s/up",
+	ProjectDownEndpoint:        "/api/environments/%s/projects/%s/down",
+	ProjectRestartEndpoint:     "/api/environments/%s/projects/%s/restart",
+	ProjectRedeployEndpoint:    "/api/environments/%s/projects/%s/redeploy",
+	ProjectPullEndpoint:        "/api/environments/%s/projects/%s/pull",
+	ProjectIncludesEndpoint:    "/api/environments/%s/projects/%s/includes",
+	ProjectDeploymentsEndpoint: "/api/environments/%s/projects/%s/deployments",
+	ProjectRollbackEndpoint:    "/api/environments/%s/projects/%s/rollback",
 
 	// System
 	SystemPruneEndpoint:                  "/api/environments/%s/system/prune",
@@ -287,6 +298,9 @@ var Endpoints = ArcaneApiEndpoints{
 	ContainerRegistryEndpoint:         "/api/container-registries/%s",
 	ContainerRegistrySyncEndpoint:     "/api/container-registries/sync",
 	ContainerRegistryTestEndpoint:     "/api/container-registries/%s/test",
+	ContainerRegistryReposEndpoint:    "/api/container-registries/%s/repositories",
+	ContainerRegistryTagsEndpoint:     "/api/container-registries/%s/tags",
+	ContainerRegistryManifestEndpoint: "/api/container-registries/%s/manifests",
 	EnvironmentSyncRegistriesEndpoint: "/api/environments/%s/sync-registries",
 
 	// Events
@@ -399,6 +413,12 @@ func (e ArcaneApiEndpoints) ImagesCounts(envID string) string {
 func (e ArcaneApiEndpoints) ImagesUpload(envID string) string {
 	return fmt.Sprintf(e.ImagesUploadEndpoint, envID)
 }
+func (e ArcaneApiEndpoints) ImageTag(envID, imageID string) string {
+	return fmt.Sprintf(e.ImageTagEndpoint, envID, imageID)
+}
+func (e ArcaneApiEndpoints) ImagePush(envID, imageID string) string {
+	return fmt.Sprintf(e.ImagePushEndpoint, envID, imageID)
+}
 
 // Image Update endpoints
 func (e ArcaneApiEndpoints) ImageUpdatesCheck(envID string) string {
@@ -482,6 +502,12 @@ func (e ArcaneApiEndpoints) ProjectPull(envID, projectID string) string {
 func (e ArcaneApiEndpoints) ProjectIncludes(envID, projectID string) string {
 	return fmt.Sprintf(e.ProjectIncludesEndpoint, envID, projectID)
 }
+func (e ArcaneApiEndpoints) ProjectDeployments(envID, projectID string) string {
+	return fmt.Sprintf(e.ProjectDeploymentsEndpoint, envID, projectID)
+}
+func (e ArcaneApiEndpoints) ProjectRollback(envID, projectID string) string {
+	return fmt.Sprintf(e.ProjectRollbackEndpoint, envID, projectID)
+}
 
 // System endpoints
 func (e ArcaneApiEndpoints) SystemPrune(envID string) string {
@@ -562,6 +588,15 @@ func (e ArcaneApiEndpoints) ContainerRegistrySync() string { return e.ContainerR
 func (e ArcaneApiEndpoints) ContainerRegistryTest(id string) string {
 	return fmt.Sprintf(e.ContainerRegistryTestEndpoint, id)
 }
+func (e ArcaneApiEndpoints) ContainerRegistryRepos(id string) string {
+	return fmt.Sprintf(e.ContainerRegistryReposEndpoint, id)
+}
+func (e ArcaneApiEndpoints) ContainerRegistryTags(id string) string {
+	return fmt.Sprintf(e.ContainerRegistryTagsEndpoint, id)
+}
+func (e ArcaneApiEndpoints) ContainerRegistryManifest(id string) string {
+	return fmt.Sprintf(e.ContainerRegistryManifestEndpoint, id)
+}
 func (e ArcaneApiEndpoints) EnvironmentSyncRegistries(envID string) string {
 	return fmt.Sprintf(e.EnvironmentSyncRegistriesEndpoint, envID)
 }
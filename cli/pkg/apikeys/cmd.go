@@ -95,6 +95,10 @@ var createCmd = &cobra.Command{
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		description, _ := cmd.Flags().GetString("description")
+		scopes, _ := cmd.Flags().GetStringSlice("scope")
+		environments, _ := cmd.Flags().GetStringSlice("environment")
+		projects, _ := cmd.Flags().GetStringSlice("project")
+		cidrs, _ := cmd.Flags().GetStringSlice("allow-cidr")
 
 		c, err := client.NewFromConfig()
 		if err != nil {
@@ -103,6 +107,12 @@ var createCmd = &cobra.Command{
 
 		createReq := apikey.CreateApiKey{
 			Name: args[0],
+			Restrictions: apikey.Restrictions{
+				Scopes:              scopes,
+				AllowedEnvironments: environments,
+				AllowedProjects:     projects,
+				AllowedCIDRs:        cidrs,
+			},
 		}
 		if description != "" {
 			createReq.Description = &description
@@ -245,6 +255,18 @@ var getCmd = &cobra.Command{
 		if result.Data.ExpiresAt != nil {
 			output.KeyValue("Expires", result.Data.ExpiresAt.Format("2006-01-02 15:04"))
 		}
+		if len(result.Data.Scopes) > 0 {
+			output.KeyValue("Scopes", strings.Join(result.Data.Scopes, ", "))
+		}
+		if len(result.Data.AllowedEnvironments) > 0 {
+			output.KeyValue("Environments", strings.Join(result.Data.AllowedEnvironments, ", "))
+		}
+		if len(result.Data.AllowedProjects) > 0 {
+			output.KeyValue("Projects", strings.Join(result.Data.AllowedProjects, ", "))
+		}
+		if len(result.Data.AllowedCIDRs) > 0 {
+			output.KeyValue("Allowed Networks", strings.Join(result.Data.AllowedCIDRs, ", "))
+		}
 		return nil
 	},
 }
@@ -262,6 +284,10 @@ func init() {
 	// Create command flags
 	createCmd.Flags().StringP("description", "d", "", "Description for the API key")
 	createCmd.Flags().String("expires-at", "", "Expiration date (ISO 8601 format)")
+	createCmd.Flags().StringSlice("scope", nil, "Limit the key to a permission, e.g. projects:deploy (repeatable)")
+	createCmd.Flags().StringSlice("environment", nil, "Limit the key to an environment ID (repeatable)")
+	createCmd.Flags().StringSlice("project", nil, "Limit the key to a project ID (repeatable)")
+	createCmd.Flags().StringSlice("allow-cidr", nil, "Only accept the key from this network, e.g. 10.0.0.0/8 (repeatable)")
 	createCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
 
 	// Get command flags
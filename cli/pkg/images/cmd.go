@@ -1,14 +1,16 @@
 // Package images provides CLI commands for managing Docker images on Arcane servers.
 //
 // This package implements the "arcane images" command group, which includes
-// subcommands for listing, inspecting, pulling, removing, pruning, and uploading
-// Docker images.
+// subcommands for listing, inspecting, pulling, tagging, pushing, removing,
+// pruning, and uploading Docker images.
 //
 // # Available Commands
 //
 //   - list: List all images with optional filtering and pagination
 //   - get: Get detailed information about a specific image
 //   - pull: Pull an image from a container registry
+//   - tag: Add a new reference to an image
+//   - push: Push an image to a container registry
 //   - remove: Remove an image from the server
 //   - prune: Remove unused images to reclaim disk space
 //   - counts: Display image usage statistics
@@ -22,6 +24,10 @@
 //	# Pull an image
 //	arcane images pull nginx:latest
 //
+//	# Promote a local image to a private registry
+//	arcane images tag myapp:dev registry.example.com/team/myapp:1.0
+//	arcane images push registry.example.com/team/myapp:1.0
+//
 //	# Get image details
 //	arcane images get sha256:abc123...
 //
@@ -460,6 +466,203 @@ var imagesPullCmd = &cobra.Command{
 	},
 }
 
+var imagesTagCmd = &cobra.Command{
+	Use:          "tag <image-id|name> <target>",
+	Short:        "Add a new reference to an image",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.GetLogger()
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		imageID, err := resolveImageID(cmd.Context(), c, args[0], false)
+		if err != nil {
+			return err
+		}
+		path := types.Endpoints.ImageTag(c.EnvID(), imageID)
+
+		log.Debugf("Tagging image at: %s", path)
+
+		resp, err := c.Post(cmd.Context(), path, image.TagRequest{Target: args[1]})
+		if err != nil {
+			return fmt.Errorf("failed to tag image: %w", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		log.Debugf("Response body: %s", string(body))
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("failed to tag image (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			fmt.Println(string(body))
+			return nil
+		}
+
+		var result struct {
+			Success bool            `json:"success"`
+			Data    image.TagResult `json:"data"`
+		}
+
+		if err := json.Unmarshal(body, &result); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		output.Success("Tagged image as %s", result.Data.Reference)
+
+		return nil
+	},
+}
+
+var (
+	pushTag string
+)
+
+var imagesPushCmd = &cobra.Command{
+	Use:   "push <image-id|name>",
+	Short: "Push an image to a registry",
+	Long: `Push an image to its registry using the registry credentials stored in Arcane.
+
+When the image is given by name, that tag is pushed. When it is given by ID and has
+several tags, choose one with --tag.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := logger.GetLogger()
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		// Pushing large images can take a long time
+		c.SetTimeout(30 * time.Minute)
+
+		identifier := strings.TrimSpace(args[0])
+		imageID, err := resolveImageID(cmd.Context(), c, identifier, false)
+		if err != nil {
+			return err
+		}
+
+		tag := pushTag
+		if tag == "" && !isImageIDReference(identifier, imageID) {
+			tag = identifier
+		}
+
+		path := types.Endpoints.ImagePush(c.EnvID(), imageID)
+
+		log.Debugf("Pushing image to: %s", path)
+
+		resp, err := c.Post(cmd.Context(), path, image.PushRequest{Tag: tag})
+		if err != nil {
+			return fmt.Errorf("failed to push image: %w", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(resp.Body)
+			return fmt.Errorf("failed to push image (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+
+		// Stream the response
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			_, err = io.Copy(cmd.OutOrStdout(), resp.Body)
+			if err != nil {
+				return fmt.Errorf("failed to read push stream: %w", err)
+			}
+			return nil
+		}
+
+		output.Info("Pushing image: %s", identifier)
+
+		decoder := json.NewDecoder(resp.Body)
+		var bar *progressbar.ProgressBar
+		var currentID string
+
+		for {
+			var event struct {
+				Status         string `json:"status"`
+				Error          string `json:"error"`
+				ID             string `json:"id"`
+				ProgressDetail struct {
+					Current int64 `json:"current"`
+					Total   int64 `json:"total"`
+				} `json:"progressDetail"`
+			}
+
+			if err := decoder.Decode(&event); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return fmt.Errorf("failed to decode stream: %w", err)
+			}
+
+			if event.Error != "" {
+				return fmt.Errorf("push error: %s", event.Error)
+			}
+
+			if event.Status == "Pushing" && event.ProgressDetail.Total > 0 {
+				if bar == nil || currentID != event.ID {
+					if bar != nil {
+						_ = bar.Finish()
+						fmt.Println()
+					}
+					currentID = event.ID
+					bar = progressbar.NewOptions64(
+						event.ProgressDetail.Total,
+						progressbar.OptionSetDescription(fmt.Sprintf("Pushing %s", event.ID)),
+						progressbar.OptionSetWriter(os.Stdout),
+						progressbar.OptionShowBytes(true),
+						progressbar.OptionSetWidth(15),
+						progressbar.OptionThrottle(65*time.Millisecond),
+						progressbar.OptionShowCount(),
+						progressbar.OptionOnCompletion(func() {
+							fmt.Println()
+						}),
+						progressbar.OptionSpinnerType(14),
+						progressbar.OptionFullWidth(),
+						progressbar.OptionSetTheme(progressbar.Theme{
+							Saucer:        "=",
+							SaucerHead:    ">",
+							SaucerPadding: " ",
+							BarStart:      "[",
+							BarEnd:        "]",
+						}),
+					)
+				}
+				_ = bar.Set64(event.ProgressDetail.Current)
+			} else {
+				if bar != nil && event.ID == currentID && event.Status == "Pushed" {
+					_ = bar.Finish()
+					fmt.Println()
+					bar = nil
+					currentID = ""
+				}
+
+				if event.Status != "Pushing" && event.Status != "" {
+					if event.ID != "" {
+						fmt.Printf("%s: %s\n", event.ID, event.Status)
+					} else {
+						fmt.Printf("%s\n", event.Status)
+					}
+				}
+			}
+		}
+
+		output.Success("Image pushed successfully")
+
+		return nil
+	},
+}
+
 var (
 	pruneDangling bool
 )
@@ -747,6 +950,11 @@ func init() {
 
 	ImagesCmd.AddCommand(imagesPullCmd)
 
+	ImagesCmd.AddCommand(imagesTagCmd)
+
+	ImagesCmd.AddCommand(imagesPushCmd)
+	imagesPushCmd.Flags().StringVarP(&pushTag, "tag", "t", "", "Tag of the image to push, when it has several")
+
 	ImagesCmd.AddCommand(imagesPruneCmd)
 	imagesPruneCmd.Flags().BoolVar(&pruneDangling, "dangling", false, "Only remove dangling images")
 
@@ -798,6 +1006,13 @@ func resolveImageID(ctx context.Context, c *client.Client, identifier string, al
 	return "", fmt.Errorf("image %q not found; use the image ID or run `arcane images list`", trimmed)
 }
 
+// isImageIDReference reports whether identifier addresses the image by (a prefix of) its ID
+// rather than by one of its tags.
+func isImageIDReference(identifier, imageID string) bool {
+	id := strings.TrimPrefix(strings.TrimSpace(identifier), "sha256:")
+	return id != "" && strings.HasPrefix(strings.TrimPrefix(imageID, "sha256:"), id)
+}
+
 func resolveImageByID(ctx context.Context, c *client.Client, identifier string) (string, bool, error) {
 	resp, err := c.Get(ctx, types.Endpoints.Image(c.EnvID(), identifier))
 	if err != nil {
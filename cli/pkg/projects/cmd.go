@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,9 +21,10 @@ import (
 )
 
 var (
-	limitFlag  int
-	forceFlag  bool
-	jsonOutput bool
+	limitFlag      int
+	forceFlag      bool
+	jsonOutput     bool
+	rollbackToFlag string
 )
 
 const maxPromptOptions = 20
@@ -325,6 +327,118 @@ var pullCmd = &cobra.Command{
 	},
 }
 
+var historyCmd = &cobra.Command{
+	Use:          "history <project-id|name>",
+	Aliases:      []string{"deployments"},
+	Short:        "Show project deployment history",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		resolved, _, err := resolveProject(cmd.Context(), c, args[0], false)
+		if err != nil {
+			return err
+		}
+
+		deployments, err := listProjectDeployments(cmd.Context(), c, resolved.ID)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			resultBytes, err := json.MarshalIndent(deployments, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(resultBytes))
+			return nil
+		}
+
+		if len(deployments) == 0 {
+			output.Info("No deployments recorded for project %s", resolved.Name)
+			return nil
+		}
+
+		headers := []string{"REVISION", "ID", "ACTION", "STATUS", "USER", "CREATED"}
+		rows := make([][]string, len(deployments))
+		for i, d := range deployments {
+			user := ""
+			if d.Username != nil {
+				user = *d.Username
+			}
+			rows[i] = []string{
+				fmt.Sprintf("%d", d.Revision),
+				d.ID,
+				d.Action,
+				d.Status,
+				user,
+				d.CreatedAt,
+			}
+		}
+
+		output.Table(headers, rows)
+		return nil
+	},
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:          "rollback <project-id|name>",
+	Short:        "Roll back project to a previous deployment",
+	Long:         "Restore the compose file, .env, include files and image digests recorded for a previous deployment and redeploy. Without --to, the last successful deployment before the current one is used.",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		resolved, _, err := resolveProject(cmd.Context(), c, args[0], false)
+		if err != nil {
+			return err
+		}
+
+		req := project.Rollback{}
+		if target := strings.TrimSpace(rollbackToFlag); target != "" {
+			deploymentID, err := resolveDeploymentID(cmd.Context(), c, resolved.ID, target)
+			if err != nil {
+				return err
+			}
+			req.DeploymentID = deploymentID
+		}
+
+		// Rolling back redeploys the project and may pull images
+		c.SetTimeout(30 * time.Minute)
+
+		resp, err := c.Post(cmd.Context(), types.Endpoints.ProjectRollback(c.EnvID(), resolved.ID), req)
+		if err != nil {
+			return fmt.Errorf("failed to roll back project: %w", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		var result base.ApiResponse[project.Deployment]
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		if jsonOutput {
+			resultBytes, err := json.MarshalIndent(result.Data, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(resultBytes))
+			return nil
+		}
+
+		output.Success("Project %s rolled back to revision %d", resolved.Name, result.Data.Revision)
+		return nil
+	},
+}
+
 var countsCmd = &cobra.Command{
 	Use:          "counts",
 	Short:        "Get project counts",
@@ -373,6 +487,8 @@ func init() {
 	ProjectsCmd.AddCommand(pullCmd)
 	ProjectsCmd.AddCommand(countsCmd)
 	ProjectsCmd.AddCommand(destroyCmd)
+	ProjectsCmd.AddCommand(historyCmd)
+	ProjectsCmd.AddCommand(rollbackCmd)
 
 	// List command flags
 	listCmd.Flags().IntVarP(&limitFlag, "limit", "n", 20, "Number of projects to show")
@@ -387,6 +503,46 @@ func init() {
 	// Destroy command flags
 	destroyCmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Force destroy without confirmation")
 	destroyCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+
+	// History command flags
+	historyCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+
+	// Rollback command flags
+	rollbackCmd.Flags().StringVar(&rollbackToFlag, "to", "", "Deployment ID or revision number to roll back to")
+	rollbackCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+}
+
+func listProjectDeployments(ctx context.Context, c *client.Client, projectID string) ([]project.Deployment, error) {
+	resp, err := c.Get(ctx, types.Endpoints.ProjectDeployments(c.EnvID(), projectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var result base.ApiResponse[[]project.Deployment]
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return result.Data, nil
+}
+
+// resolveDeploymentID accepts either a deployment ID or a revision number.
+func resolveDeploymentID(ctx context.Context, c *client.Client, projectID, target string) (string, error) {
+	revision, convErr := strconv.Atoi(strings.TrimPrefix(target, "#"))
+	if convErr != nil {
+		return target, nil
+	}
+
+	deployments, err := listProjectDeployments(ctx, c, projectID)
+	if err != nil {
+		return "", err
+	}
+	for _, d := range deployments {
+		if d.Revision == revision {
+			return d.ID, nil
+		}
+	}
+	return "", fmt.Errorf("deployment revision %d not found", revision)
 }
 
 func resolveProject(ctx context.Context, c *client.Client, identifier string, allowPrompt bool) (*project.Details, bool, error) {
@@ -1,9 +1,14 @@
 package registries
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/getarcaneapp/arcane/cli/internal/client"
 	"github.com/getarcaneapp/arcane/cli/internal/output"
@@ -11,12 +16,14 @@ import (
 	"github.com/getarcaneapp/arcane/types/base"
 	"github.com/getarcaneapp/arcane/types/containerregistry"
 	"github.com/spf13/cobra"
+	"go.withmatt.com/size"
 )
 
 var (
 	limitFlag  int
 	forceFlag  bool
 	jsonOutput bool
+	searchFlag string
 )
 
 var RegistriesCmd = &cobra.Command{
@@ -186,11 +193,285 @@ var deleteCmd = &cobra.Command{
 	},
 }
 
+var reposCmd = &cobra.Command{
+	Use:          "repos <registry-id>",
+	Aliases:      []string{"repositories", "catalog"},
+	Short:        "List repositories in a registry",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		path := types.Endpoints.ContainerRegistryRepos(args[0])
+		if term := strings.TrimSpace(searchFlag); term != "" {
+			path = fmt.Sprintf("%s?search=%s", path, url.QueryEscape(term))
+		}
+
+		var result containerregistry.RepositoryList
+		if err := getRegistryData(cmd.Context(), c, path, &result); err != nil {
+			return fmt.Errorf("failed to list repositories: %w", err)
+		}
+
+		if jsonOutput {
+			return printJSON(result)
+		}
+
+		if len(result.Repositories) == 0 {
+			output.Info("No repositories found")
+			return nil
+		}
+
+		rows := make([][]string, len(result.Repositories))
+		for i, repo := range result.Repositories {
+			rows[i] = []string{repo}
+		}
+		output.Table([]string{"REPOSITORY"}, rows)
+		fmt.Printf("\nTotal: %d repositories\n", len(result.Repositories))
+		return nil
+	},
+}
+
+var tagsCmd = &cobra.Command{
+	Use:          "tags <registry-id> <repository>",
+	Short:        "List tags of a repository in a registry",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		path := fmt.Sprintf("%s?repository=%s", types.Endpoints.ContainerRegistryTags(args[0]), url.QueryEscape(args[1]))
+
+		var result containerregistry.TagList
+		if err := getRegistryData(cmd.Context(), c, path, &result); err != nil {
+			return fmt.Errorf("failed to list tags: %w", err)
+		}
+
+		if jsonOutput {
+			return printJSON(result)
+		}
+
+		if len(result.Tags) == 0 {
+			output.Info("No tags found for %s", result.Repository)
+			return nil
+		}
+
+		tags := append([]string(nil), result.Tags...)
+		sort.Strings(tags)
+		rows := make([][]string, len(tags))
+		for i, tag := range tags {
+			rows[i] = []string{tag}
+		}
+		output.Table([]string{"TAG"}, rows)
+		fmt.Printf("\nTotal: %d tags\n", len(tags))
+		return nil
+	},
+}
+
+var inspectCmd = &cobra.Command{
+	Use:          "inspect <registry-id> <repository>[:tag|@digest]",
+	Short:        "Show the platforms, sizes and labels of a tag",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		manifest, err := getManifest(cmd.Context(), c, args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			return printJSON(manifest)
+		}
+
+		output.Header("%s", manifest.ImageRef)
+		output.KeyValue("Digest", manifest.Digest)
+		if manifest.MediaType != "" {
+			output.KeyValue("Media Type", manifest.MediaType)
+		}
+		fmt.Println()
+
+		headers := []string{"PLATFORM", "DIGEST", "SIZE", "CREATED"}
+		rows := make([][]string, len(manifest.Platforms))
+		for i, p := range manifest.Platforms {
+			platform := p.OS + "/" + p.Architecture
+			if p.Variant != "" {
+				platform += "/" + p.Variant
+			}
+			created := ""
+			if p.Created != nil {
+				created = p.Created.Local().Format(time.RFC3339)
+			}
+			rows[i] = []string{platform, p.Digest, size.Capacity(p.Size).String(), created}
+		}
+		output.Table(headers, rows)
+
+		if len(manifest.Platforms) > 0 && len(manifest.Platforms[0].Labels) > 0 {
+			fmt.Println()
+			output.Header("Labels")
+			labels := manifest.Platforms[0].Labels
+			keys := make([]string, 0, len(labels))
+			for k := range labels {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				output.KeyValue(k, labels[k])
+			}
+		}
+		return nil
+	},
+}
+
+var pullCmd = &cobra.Command{
+	Use:          "pull <registry-id> <repository>[:tag|@digest]",
+	Short:        "Pull an image from a registry into the current environment",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		manifest, err := getManifest(cmd.Context(), c, args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		// Pulling large images can take a long time
+		c.SetTimeout(30 * time.Minute)
+
+		resp, err := c.Post(cmd.Context(), types.Endpoints.ImagesPull(c.EnvID()), map[string]interface{}{
+			"imageName": manifest.ImageRef,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to pull image: %w", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(resp.Body)
+			return fmt.Errorf("failed to pull image (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+
+		if jsonOutput {
+			if _, err := io.Copy(cmd.OutOrStdout(), resp.Body); err != nil {
+				return fmt.Errorf("failed to read pull stream: %w", err)
+			}
+			return nil
+		}
+
+		output.Info("Pulling image: %s", manifest.ImageRef)
+
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var event struct {
+				Status string `json:"status"`
+				Error  string `json:"error"`
+				ID     string `json:"id"`
+			}
+			if err := decoder.Decode(&event); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return fmt.Errorf("failed to decode stream: %w", err)
+			}
+			if event.Error != "" {
+				return fmt.Errorf("pull error: %s", event.Error)
+			}
+			// Per-layer progress is noisy, only report layer completion and the final status.
+			if event.ID != "" && event.Status != "Pull complete" && event.Status != "Already exists" {
+				continue
+			}
+			if event.ID != "" {
+				fmt.Printf("%s: %s\n", event.ID, event.Status)
+			} else if event.Status != "" {
+				fmt.Println(event.Status)
+			}
+		}
+
+		output.Success("Image %s pulled successfully", manifest.ImageRef)
+		return nil
+	},
+}
+
+// splitImageReference splits repository[:tag|@digest] into repository and reference,
+// defaulting to the latest tag.
+func splitImageReference(s string) (string, string) {
+	if repo, digest, ok := strings.Cut(s, "@"); ok {
+		return repo, digest
+	}
+	if i := strings.LastIndex(s, ":"); i > strings.LastIndex(s, "/") {
+		return s[:i], s[i+1:]
+	}
+	return s, "latest"
+}
+
+func getManifest(ctx context.Context, c *client.Client, registryID, image string) (*containerregistry.Manifest, error) {
+	repository, reference := splitImageReference(image)
+	path := fmt.Sprintf("%s?repository=%s&reference=%s",
+		types.Endpoints.ContainerRegistryManifest(registryID), url.QueryEscape(repository), url.QueryEscape(reference))
+
+	var manifest containerregistry.Manifest
+	if err := getRegistryData(ctx, c, path, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to get manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// getRegistryData fetches a browse endpoint and decodes its data into out. Registry errors
+// (for example a disabled catalog) are passed through from the API.
+func getRegistryData[T any](ctx context.Context, c *client.Client, path string, out *T) error {
+	resp, err := c.Get(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result base.ApiResponse[T]
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	*out = result.Data
+	return nil
+}
+
+func printJSON(v any) error {
+	resultBytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Println(string(resultBytes))
+	return nil
+}
+
 func init() {
 	RegistriesCmd.AddCommand(listCmd)
 	RegistriesCmd.AddCommand(syncCmd)
 	RegistriesCmd.AddCommand(testCmd)
 	RegistriesCmd.AddCommand(deleteCmd)
+	RegistriesCmd.AddCommand(reposCmd)
+	RegistriesCmd.AddCommand(tagsCmd)
+	RegistriesCmd.AddCommand(inspectCmd)
+	RegistriesCmd.AddCommand(pullCmd)
 
 	listCmd.Flags().IntVarP(&limitFlag, "limit", "n", 20, "Number of registries to show")
 	listCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
@@ -200,4 +481,10 @@ func init() {
 
 	deleteCmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Force deletion without confirmation")
 	deleteCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+
+	reposCmd.Flags().StringVarP(&searchFlag, "search", "s", "", "Only show repositories containing this text")
+	reposCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	tagsCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	inspectCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	pullCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output raw pull stream as JSON")
 }
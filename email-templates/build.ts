@@ -70,6 +70,19 @@ async function buildTemplateFile(Component: any, templateName: string, isPlainTe
     );
   }
 
+  // Release notes are only known for some images, render their row only when there is a link
+  if (isPlainText) {
+    normalized = normalized.replace(
+      /Release Notes:\n\n[\s\S]*?\n\n-+\n/g,
+      '{{if .ReleaseNotesURL}}$&{{end}}'
+    );
+  } else {
+    normalized = normalized.replace(
+      /<hr[^>]*\/><table(?:(?!<\/table>)[\s\S])*?Release Notes:[\s\S]*?<\/table>/g,
+      '{{if .ReleaseNotesURL}}$&{{end}}'
+    );
+  }
+
   // Enforce line length: prefer tag boundaries, never spaces
   const maxLen = isPlainText ? 78 : 998; // RFC-safe
   const safe = tagAwareWrap(normalized, maxLen);
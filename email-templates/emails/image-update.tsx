@@ -1,4 +1,4 @@
-import { Column, Hr, Row, Section, Text } from '@react-email/components';
+import { Column, Hr, Link, Row, Section, Text } from '@react-email/components';
 import { BaseTemplate } from '../components/base-template';
 import CardHeader from '../components/card-header';
 import { sharedPreviewProps, sharedTemplateProps } from '../props';
@@ -11,6 +11,7 @@ interface ImageUpdateEmailProps {
   updateType: string;
   currentDigest: string;
   latestDigest: string;
+  releaseNotesURL?: string;
   checkTime: string;
 }
 
@@ -22,6 +23,7 @@ export const ImageUpdateEmail = ({
   updateType,
   currentDigest,
   latestDigest,
+  releaseNotesURL,
   checkTime,
 }: ImageUpdateEmailProps) => {
   const truncateDigest = (digest: string) => {
@@ -108,6 +110,24 @@ export const ImageUpdateEmail = ({
           </>
         )}
 
+        {releaseNotesURL && (
+          <>
+            <Hr style={dividerStyle} />
+            <Row style={infoRowStyle}>
+              <Column style={labelColumnStyle}>
+                <Text style={labelStyle}>Release Notes:</Text>
+              </Column>
+              <Column>
+                <Text style={valueStyle}>
+                  <Link href={releaseNotesURL} style={linkStyle}>
+                    {releaseNotesURL}
+                  </Link>
+                </Text>
+              </Column>
+            </Row>
+          </>
+        )}
+
         {checkTime && (
           <>
             <Hr style={dividerStyle} />
@@ -181,6 +201,11 @@ const digestStyle = {
   margin: '8px 0',
 };
 
+const linkStyle = {
+  color: '#a78bfa',
+  textDecoration: 'none',
+};
+
 const statusUpdateStyle = {
   fontSize: '14px',
   fontWeight: '600' as const,
@@ -214,6 +239,7 @@ ImageUpdateEmail.TemplateProps = {
   updateType: '{{.UpdateType}}',
   currentDigest: '{{.CurrentDigest}}',
   latestDigest: '{{.LatestDigest}}',
+  releaseNotesURL: '{{.ReleaseNotesURL}}',
   checkTime: '{{.CheckTime}}',
 };
 
@@ -224,5 +250,6 @@ ImageUpdateEmail.PreviewProps = {
   updateType: 'digest',
   currentDigest: 'sha256:abc123def456789012345678901234567890',
   latestDigest: 'sha256:xyz789ghi012345678901234567890123456',
+  releaseNotesURL: 'https://github.com/nginx/nginx/releases/tag/release-1.29.2',
   checkTime: '2025-10-18 15:30:00 UTC',
 };
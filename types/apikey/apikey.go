@@ -7,6 +7,7 @@ type CreateApiKey struct {
 	Name        string     `json:"name" minLength:"1" maxLength:"255" doc:"Name of the API key" example:"My API Key"`
 	Description *string    `json:"description,omitempty" maxLength:"1000" doc:"Optional description of the API key"`
 	ExpiresAt   *time.Time `json:"expiresAt,omitempty" doc:"Optional expiration date for the API key"`
+	Restrictions
 }
 
 // ApiKey represents an API key without the secret.
@@ -20,6 +21,7 @@ type ApiKey struct {
 	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty" doc:"Last time the API key was used"`
 	CreatedAt   time.Time  `json:"createdAt" doc:"Creation timestamp"`
 	UpdatedAt   *time.Time `json:"updatedAt,omitempty" doc:"Last update timestamp"`
+	Restrictions
 }
 
 // ApiKeyCreatedDto represents a newly created API key with the full secret.
@@ -33,4 +35,16 @@ type UpdateApiKey struct {
 	Name        *string    `json:"name,omitempty" maxLength:"255" doc:"New name for the API key"`
 	Description *string    `json:"description,omitempty" maxLength:"1000" doc:"New description for the API key"`
 	ExpiresAt   *time.Time `json:"expiresAt,omitempty" doc:"New expiration date for the API key"`
+	// Restrictions replace the restrictions of the key. A nil list keeps the current value and
+	// an empty list removes the restriction.
+	Restrictions
+}
+
+// Restrictions limit what an API key can do. A key without restrictions carries the full
+// privileges of its owner.
+type Restrictions struct {
+	Scopes              []string `json:"scopes,omitempty" doc:"Permissions the key is limited to, e.g. projects:deploy or containers:read" example:"[\"projects:deploy\"]"`
+	AllowedEnvironments []string `json:"allowedEnvironments,omitempty" doc:"IDs of the environments the key can access"`
+	AllowedProjects     []string `json:"allowedProjects,omitempty" doc:"IDs of the projects the key can access"`
+	AllowedCIDRs        []string `json:"allowedCidrs,omitempty" doc:"Networks, in CIDR notation, requests with the key must come from" example:"[\"10.0.0.0/8\"]"`
 }
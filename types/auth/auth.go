@@ -23,12 +23,56 @@ type PasswordChange struct {
 	NewPassword     string `json:"newPassword" minLength:"8" doc:"New password for the user"`
 }
 
-// LoginResponse represents the successful login response data.
+// LoginResponse represents the successful login response data. When the user has to pass a
+// second factor only TwoFactor is set.
 type LoginResponse struct {
-	Token        string    `json:"token" doc:"JWT access token"`
-	RefreshToken string    `json:"refreshToken" doc:"Refresh token for obtaining new access tokens"`
-	ExpiresAt    time.Time `json:"expiresAt" doc:"Expiration time of the access token"`
-	User         user.User `json:"user" doc:"Authenticated user information"`
+	Token         string              `json:"token" doc:"JWT access token"`
+	RefreshToken  string              `json:"refreshToken" doc:"Refresh token for obtaining new access tokens"`
+	ExpiresAt     time.Time           `json:"expiresAt" doc:"Expiration time of the access token"`
+	User          user.User           `json:"user" doc:"Authenticated user information"`
+	TwoFactor     *TwoFactorChallenge `json:"twoFactor,omitempty" doc:"Second step required to complete the login"`
+	RecoveryCodes []string            `json:"recoveryCodes,omitempty" doc:"Recovery codes, returned once when two-factor authentication was set up during login"`
+}
+
+// TwoFactorChallenge is the second login step for users with two-factor authentication.
+type TwoFactorChallenge struct {
+	ChallengeToken string    `json:"challengeToken" doc:"Token identifying the pending login"`
+	ExpiresAt      time.Time `json:"expiresAt" doc:"Expiration time of the challenge"`
+	SetupRequired  bool      `json:"setupRequired" doc:"Whether the user has to set up an authenticator app before continuing"`
+}
+
+// TwoFactorVerify represents the request body completing a login with a second factor.
+type TwoFactorVerify struct {
+	ChallengeToken string `json:"challengeToken" minLength:"1" doc:"Challenge token returned by the login"`
+	Code           string `json:"code" minLength:"1" maxLength:"32" doc:"Authenticator app code or recovery code" example:"123456"`
+}
+
+// TwoFactorChallengeSetup represents the request body starting authenticator setup during a login.
+type TwoFactorChallengeSetup struct {
+	ChallengeToken string `json:"challengeToken" minLength:"1" doc:"Challenge token returned by the login"`
+}
+
+// TotpSetup holds the secret of an authenticator app that is being set up.
+type TotpSetup struct {
+	Secret          string `json:"secret" doc:"Base32 encoded secret for manual entry"`
+	ProvisioningURI string `json:"provisioningUri" doc:"otpauth:// URI to render as a QR code"`
+}
+
+// TwoFactorCode represents a request body confirming an action with a second factor.
+type TwoFactorCode struct {
+	Code string `json:"code" minLength:"1" maxLength:"32" doc:"Authenticator app code or recovery code" example:"123456"`
+}
+
+// RecoveryCodes are one-time codes that replace an authenticator app code.
+type RecoveryCodes struct {
+	Codes []string `json:"codes" doc:"Recovery codes; they are only shown once"`
+}
+
+// TwoFactorStatus describes the two-factor authentication of the current user.
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled" doc:"Whether an authenticator app is set up"`
+	Required               bool `json:"required" doc:"Whether two-factor authentication is required for local accounts"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining" doc:"Number of unused recovery codes"`
 }
 
 // TokenRefreshResponse represents the successful token refresh response data.
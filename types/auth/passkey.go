@@ -0,0 +1,132 @@
+package auth
+
+import "time"
+
+// Passkey is a WebAuthn credential registered by a user.
+type Passkey struct {
+	ID         string     `json:"id" doc:"Unique identifier of the passkey"`
+	Name       string     `json:"name" doc:"Name of the passkey"`
+	BackedUp   bool       `json:"backedUp" doc:"Whether the passkey is synced to other devices"`
+	Transports []string   `json:"transports,omitempty" doc:"Transports the authenticator supports"`
+	CreatedAt  time.Time  `json:"createdAt" doc:"Date and time the passkey was registered"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" doc:"Date and time the passkey was last used to sign in"`
+}
+
+// RenamePasskey represents the request body renaming a passkey.
+type RenamePasskey struct {
+	Name string `json:"name" minLength:"1" maxLength:"64" doc:"New name of the passkey"`
+}
+
+// PasskeyRelyingParty identifies the application to the authenticator.
+type PasskeyRelyingParty struct {
+	ID   string `json:"id" doc:"Relying party ID (host name of the application)"`
+	Name string `json:"name" doc:"Relying party name"`
+}
+
+// PasskeyUser identifies the user a passkey is created for.
+type PasskeyUser struct {
+	ID          string `json:"id" doc:"Base64url encoded user handle"`
+	Name        string `json:"name" doc:"Username"`
+	DisplayName string `json:"displayName" doc:"Display name"`
+}
+
+// PasskeyCredentialParameter is an accepted credential algorithm.
+type PasskeyCredentialParameter struct {
+	Type string `json:"type" doc:"Credential type" example:"public-key"`
+	Alg  int64  `json:"alg" doc:"COSE algorithm identifier" example:"-7"`
+}
+
+// PasskeyCredentialDescriptor identifies an existing credential.
+type PasskeyCredentialDescriptor struct {
+	Type       string   `json:"type" doc:"Credential type" example:"public-key"`
+	ID         string   `json:"id" doc:"Base64url encoded credential ID"`
+	Transports []string `json:"transports,omitempty" doc:"Transports the authenticator supports"`
+}
+
+// PasskeyAuthenticatorSelection states the authenticator requirements of a registration.
+type PasskeyAuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey" doc:"Whether a discoverable credential is required"`
+	RequireResidentKey bool   `json:"requireResidentKey" doc:"Legacy form of residentKey"`
+	UserVerification   string `json:"userVerification" doc:"User verification requirement"`
+}
+
+// PasskeyCreationOptions are the options for navigator.credentials.create, in the JSON form
+// PublicKeyCredential.parseCreationOptionsFromJSON accepts.
+type PasskeyCreationOptions struct {
+	RP                     PasskeyRelyingParty           `json:"rp"`
+	User                   PasskeyUser                   `json:"user"`
+	Challenge              string                        `json:"challenge" doc:"Base64url encoded challenge"`
+	PubKeyCredParams       []PasskeyCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int                           `json:"timeout" doc:"Ceremony timeout in milliseconds"`
+	ExcludeCredentials     []PasskeyCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection PasskeyAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                        `json:"attestation"`
+}
+
+// PasskeyRequestOptions are the options for navigator.credentials.get, in the JSON form
+// PublicKeyCredential.parseRequestOptionsFromJSON accepts.
+type PasskeyRequestOptions struct {
+	RPID             string                        `json:"rpId"`
+	Challenge        string                        `json:"challenge" doc:"Base64url encoded challenge"`
+	Timeout          int                           `json:"timeout" doc:"Ceremony timeout in milliseconds"`
+	AllowCredentials []PasskeyCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                        `json:"userVerification"`
+}
+
+// PasskeyRegistrationOptions starts a passkey registration.
+type PasskeyRegistrationOptions struct {
+	SessionID string                 `json:"sessionId" doc:"Identifier of the ceremony, sent back with the credential"`
+	PublicKey PasskeyCreationOptions `json:"publicKey"`
+}
+
+// PasskeyLoginOptions starts a passkey login.
+type PasskeyLoginOptions struct {
+	SessionID string                `json:"sessionId" doc:"Identifier of the ceremony, sent back with the credential"`
+	PublicKey PasskeyRequestOptions `json:"publicKey"`
+}
+
+// PasskeyAttestationResponse is the authenticator response of a registration.
+type PasskeyAttestationResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON" minLength:"1" doc:"Base64url encoded client data"`
+	AttestationObject string   `json:"attestationObject" minLength:"1" doc:"Base64url encoded attestation object"`
+	Transports        []string `json:"transports,omitempty" doc:"Transports the authenticator supports"`
+}
+
+// PasskeyRegistrationCredential is the credential returned by navigator.credentials.create,
+// as serialized by PublicKeyCredential.toJSON.
+type PasskeyRegistrationCredential struct {
+	ID       string                     `json:"id" minLength:"1" doc:"Base64url encoded credential ID"`
+	RawID    string                     `json:"rawId,omitempty" doc:"Base64url encoded credential ID"`
+	Type     string                     `json:"type" enum:"public-key" doc:"Credential type"`
+	Response PasskeyAttestationResponse `json:"response"`
+}
+
+// FinishPasskeyRegistration represents the request body completing a passkey registration.
+type FinishPasskeyRegistration struct {
+	SessionID  string                        `json:"sessionId" minLength:"1" doc:"Identifier returned when the registration started"`
+	Name       string                        `json:"name,omitempty" maxLength:"64" doc:"Name of the passkey"`
+	Credential PasskeyRegistrationCredential `json:"credential"`
+}
+
+// PasskeyAssertionResponse is the authenticator response of a login.
+type PasskeyAssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON" minLength:"1" doc:"Base64url encoded client data"`
+	AuthenticatorData string `json:"authenticatorData" minLength:"1" doc:"Base64url encoded authenticator data"`
+	Signature         string `json:"signature" minLength:"1" doc:"Base64url encoded signature"`
+	UserHandle        string `json:"userHandle,omitempty" doc:"Base64url encoded user handle"`
+}
+
+// PasskeyAssertionCredential is the credential returned by navigator.credentials.get, as
+// serialized by PublicKeyCredential.toJSON.
+type PasskeyAssertionCredential struct {
+	ID       string                   `json:"id" minLength:"1" doc:"Base64url encoded credential ID"`
+	RawID    string                   `json:"rawId,omitempty" doc:"Base64url encoded credential ID"`
+	Type     string                   `json:"type" enum:"public-key" doc:"Credential type"`
+	Response PasskeyAssertionResponse `json:"response"`
+}
+
+// FinishPasskeyLogin represents the request body completing a passkey login.
+type FinishPasskeyLogin struct {
+	SessionID  string                     `json:"sessionId" minLength:"1" doc:"Identifier returned when the login started"`
+	Credential PasskeyAssertionCredential `json:"credential"`
+}
@@ -0,0 +1,111 @@
+package containerregistry
+
+import "time"
+
+type RepositoryList struct {
+	// RegistryID is the ID of the browsed container registry.
+	//
+	// Required: true
+	RegistryID string `json:"registryId"`
+
+	// Repositories in the registry catalog.
+	//
+	// Required: true
+	Repositories []string `json:"repositories"`
+}
+
+type TagList struct {
+	// RegistryID is the ID of the browsed container registry.
+	//
+	// Required: true
+	RegistryID string `json:"registryId"`
+
+	// Repository the tags belong to.
+	//
+	// Required: true
+	Repository string `json:"repository"`
+
+	// Tags of the repository.
+	//
+	// Required: true
+	Tags []string `json:"tags"`
+
+	// Incomplete indicates that the repository has more tags than were listed.
+	//
+	// Required: false
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+type Manifest struct {
+	// RegistryID is the ID of the browsed container registry.
+	//
+	// Required: true
+	RegistryID string `json:"registryId"`
+
+	// Repository the manifest belongs to.
+	//
+	// Required: true
+	Repository string `json:"repository"`
+
+	// Reference is the tag or digest that was resolved.
+	//
+	// Required: true
+	Reference string `json:"reference"`
+
+	// ImageRef is the full image reference to pull, e.g. registry.example.com/org/app:1.0.
+	//
+	// Required: true
+	ImageRef string `json:"imageRef"`
+
+	// Digest of the manifest or manifest list.
+	//
+	// Required: true
+	Digest string `json:"digest"`
+
+	// MediaType of the manifest or manifest list.
+	//
+	// Required: false
+	MediaType string `json:"mediaType,omitempty"`
+
+	// Platforms are the images the reference points to, one per platform.
+	//
+	// Required: true
+	Platforms []ManifestPlatform `json:"platforms"`
+}
+
+type ManifestPlatform struct {
+	// Digest of the platform image manifest.
+	//
+	// Required: true
+	Digest string `json:"digest"`
+
+	// OS of the image.
+	//
+	// Required: false
+	OS string `json:"os,omitempty"`
+
+	// Architecture of the image.
+	//
+	// Required: false
+	Architecture string `json:"architecture,omitempty"`
+
+	// Variant of the architecture, e.g. v7 for arm.
+	//
+	// Required: false
+	Variant string `json:"variant,omitempty"`
+
+	// Size is the compressed size of the image config and layers in bytes.
+	//
+	// Required: true
+	Size int64 `json:"size"`
+
+	// Created is when the image was built.
+	//
+	// Required: false
+	Created *time.Time `json:"created,omitempty"`
+
+	// Labels of the image config.
+	//
+	// Required: false
+	Labels map[string]string `json:"labels,omitempty"`
+}
@@ -82,11 +82,22 @@ type GitOpsSync struct {
 	// Required: false
 	Repository *GitRepository `json:"repository,omitempty"`
 
-	// Branch to sync from.
+	// Branch to sync from. For commit refs it is the branch the commit is fetched from.
 	//
 	// Required: true
 	Branch string `json:"branch"`
 
+	// RefType selects what the sync checks out: branch, tag, semver or commit.
+	//
+	// Required: true
+	RefType string `json:"refType"`
+
+	// Ref is the tag glob pattern (e.g. v1.*), semver constraint (e.g. ^1.2) or commit SHA,
+	// depending on RefType. Unused for branch syncs.
+	//
+	// Required: false
+	Ref string `json:"ref,omitempty"`
+
 	// ComposePath is the path to the docker-compose file in the repository.
 	//
 	// Required: true
@@ -132,6 +143,39 @@ type GitOpsSync struct {
 	// Required: false
 	LastSyncCommit *string `json:"lastSyncCommit,omitempty"`
 
+	// LastSyncRef is the branch or tag the last successful sync resolved to.
+	//
+	// Required: false
+	LastSyncRef *string `json:"lastSyncRef,omitempty"`
+
+	// DriftCheckedAt is the date and time of the last drift check.
+	//
+	// Required: false
+	DriftCheckedAt *time.Time `json:"driftCheckedAt,omitempty"`
+
+	// DriftReasons explains why the project no longer matches the sync. Set while
+	// LastSyncStatus is drifted.
+	//
+	// Required: false
+	DriftReasons []string `json:"driftReasons,omitempty"`
+
+	// WebhookEnabled indicates if pushes to the repository can trigger the sync via webhook.
+	//
+	// Required: true
+	WebhookEnabled bool `json:"webhookEnabled"`
+
+	// BuildImages indicates if services with a build section are built from the repository
+	// before the project is deployed.
+	//
+	// Required: true
+	BuildImages bool `json:"buildImages"`
+
+	// SopsAgeRecipients are the age public keys of the key used to decrypt SOPS encrypted
+	// files. Encrypt files in the repository for one of these recipients.
+	//
+	// Required: false
+	SopsAgeRecipients []string `json:"sopsAgeRecipients,omitempty"`
+
 	// CreatedAt is the date and time at which the sync was created.
 	//
 	// Required: true
@@ -254,10 +298,22 @@ type CreateSyncRequest struct {
 	// Required: true
 	RepositoryID string `json:"repositoryId" binding:"required"`
 
-	// Branch to sync from.
+	// Branch to sync from. Required for branch syncs; for commit syncs it limits the fetch
+	// to this branch.
 	//
-	// Required: true
-	Branch string `json:"branch" binding:"required"`
+	// Required: false
+	Branch string `json:"branch,omitempty"`
+
+	// RefType selects what the sync checks out: branch (default), tag, semver or commit.
+	//
+	// Required: false
+	RefType string `json:"refType,omitempty"`
+
+	// Ref is the tag glob pattern (e.g. v1.*), semver constraint (e.g. >=1.2, <2) or commit
+	// SHA, depending on RefType.
+	//
+	// Required: false
+	Ref string `json:"ref,omitempty"`
 
 	// ComposePath is the path to the docker-compose file in the repository.
 	//
@@ -280,6 +336,18 @@ type CreateSyncRequest struct {
 	//
 	// Required: false
 	SyncInterval *int `json:"syncInterval,omitempty"`
+
+	// BuildImages indicates if services with a build section are built from the repository
+	// before the project is deployed.
+	//
+	// Required: false
+	BuildImages *bool `json:"buildImages,omitempty"`
+
+	// SopsAgeKey is an age private key (AGE-SECRET-KEY-...) used to decrypt SOPS encrypted
+	// .env and YAML files during sync.
+	//
+	// Required: false
+	SopsAgeKey string `json:"sopsAgeKey,omitempty"`
 }
 
 // UpdateSyncRequest represents the request to update a gitops sync.
@@ -299,6 +367,16 @@ type UpdateSyncRequest struct {
 	// Required: false
 	Branch *string `json:"branch,omitempty"`
 
+	// RefType selects what the sync checks out: branch, tag, semver or commit.
+	//
+	// Required: false
+	RefType *string `json:"refType,omitempty"`
+
+	// Ref is the tag glob pattern, semver constraint or commit SHA, depending on RefType.
+	//
+	// Required: false
+	Ref *string `json:"ref,omitempty"`
+
 	// ComposePath is the path to the docker-compose file in the repository.
 	//
 	// Required: false
@@ -318,6 +396,18 @@ type UpdateSyncRequest struct {
 	//
 	// Required: false
 	SyncInterval *int `json:"syncInterval,omitempty"`
+
+	// BuildImages indicates if services with a build section are built from the repository
+	// before the project is deployed.
+	//
+	// Required: false
+	BuildImages *bool `json:"buildImages,omitempty"`
+
+	// SopsAgeKey is an age private key (AGE-SECRET-KEY-...) used to decrypt SOPS encrypted
+	// .env and YAML files during sync. An empty string removes the key.
+	//
+	// Required: false
+	SopsAgeKey *string `json:"sopsAgeKey,omitempty"`
 }
 
 // SyncResult represents the result of a sync operation.
@@ -343,6 +433,188 @@ type SyncResult struct {
 	SyncedAt time.Time `json:"syncedAt"`
 }
 
+// EnableWebhookRequest represents the request to enable the push webhook of a sync.
+type EnableWebhookRequest struct {
+	// Secret used to verify webhook deliveries. A random secret is generated when empty.
+	//
+	// Required: false
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookInfo describes the push webhook of a sync.
+type WebhookInfo struct {
+	// Enabled indicates if the webhook accepts deliveries.
+	//
+	// Required: true
+	Enabled bool `json:"enabled"`
+
+	// Path is the webhook endpoint, relative to the Arcane server URL.
+	//
+	// Required: true
+	Path string `json:"path"`
+
+	// Secret is the webhook secret. It is only returned when the webhook is enabled.
+	//
+	// Required: false
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookResult represents the outcome of a webhook delivery.
+type WebhookResult struct {
+	// Triggered indicates if the delivery started a sync.
+	//
+	// Required: true
+	Triggered bool `json:"triggered"`
+
+	// Message contains a human-readable explanation of the outcome.
+	//
+	// Required: true
+	Message string `json:"message"`
+
+	// Provider is the git host the delivery was recognised as (github, gitea, gitlab).
+	//
+	// Required: false
+	Provider string `json:"provider,omitempty"`
+
+	// Branch is the branch that was pushed.
+	//
+	// Required: false
+	Branch string `json:"branch,omitempty"`
+
+	// Commit is the commit the branch points to after the push.
+	//
+	// Required: false
+	Commit string `json:"commit,omitempty"`
+}
+
+// SyncDiff is a preview of what a sync would change, computed without applying it.
+type SyncDiff struct {
+	// Commit is the commit hash the preview was computed from.
+	//
+	// Required: true
+	Commit string `json:"commit"`
+
+	// HasChanges indicates if applying the sync would change any file or service.
+	//
+	// Required: true
+	HasChanges bool `json:"hasChanges"`
+
+	// Diff is a unified diff of the project files on disk against the repository.
+	//
+	// Required: true
+	Diff string `json:"diff"`
+
+	// Files lists the files that would be added, modified or removed.
+	//
+	// Required: true
+	Files []SyncFileChange `json:"files"`
+
+	// Services lists the action compose would take for each service.
+	//
+	// Required: true
+	Services []SyncServicePlan `json:"services"`
+
+	// Warnings contains problems that limited the preview, such as a compose file that failed to load.
+	//
+	// Required: false
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// SyncFileChange describes a single file changed by a sync.
+type SyncFileChange struct {
+	// Path is the file path relative to the project directory.
+	//
+	// Required: true
+	Path string `json:"path"`
+
+	// Status is one of added, modified or removed.
+	//
+	// Required: true
+	Status string `json:"status"`
+}
+
+// SyncServicePlan describes what a sync would do to a single service.
+type SyncServicePlan struct {
+	// Name of the service.
+	//
+	// Required: true
+	Name string `json:"name"`
+
+	// Action is one of create, recreate, remove or unchanged.
+	//
+	// Required: true
+	Action string `json:"action"`
+
+	// Image is the image the service would run.
+	//
+	// Required: false
+	Image string `json:"image,omitempty"`
+}
+
+// DriftReport is the result of comparing a sync's project with its repository and with the
+// containers it runs.
+type DriftReport struct {
+	// Drifted indicates if the project was changed outside of the sync.
+	//
+	// Required: true
+	Drifted bool `json:"drifted"`
+
+	// Commit is the repository commit the project was compared against.
+	//
+	// Required: true
+	Commit string `json:"commit"`
+
+	// SourceChanged indicates if the repository has changes the last sync hasn't applied yet.
+	// Those are not drift and are applied by the next sync.
+	//
+	// Required: true
+	SourceChanged bool `json:"sourceChanged"`
+
+	// Files lists the project files that were edited outside of the sync.
+	//
+	// Required: true
+	Files []SyncFileChange `json:"files"`
+
+	// Containers lists the running containers that no longer match the project.
+	//
+	// Required: true
+	Containers []ContainerDrift `json:"containers"`
+
+	// Reasons summarizes all differences found.
+	//
+	// Required: true
+	Reasons []string `json:"reasons"`
+
+	// Warnings contains problems that limited the check, such as containers that couldn't
+	// be inspected.
+	//
+	// Required: false
+	Warnings []string `json:"warnings,omitempty"`
+
+	// CheckedAt is the date and time of the check.
+	//
+	// Required: true
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// ContainerDrift describes how a running container differs from its compose service.
+type ContainerDrift struct {
+	// Service is the compose service the container belongs to.
+	//
+	// Required: true
+	Service string `json:"service"`
+
+	// Container is the container name.
+	//
+	// Required: true
+	Container string `json:"container"`
+
+	// Reasons lists the differences, e.g. a changed image or environment variable.
+	//
+	// Required: true
+	Reasons []string `json:"reasons"`
+}
+
 // FileTreeNodeType represents the type of a file tree node.
 type FileTreeNodeType string
 
@@ -413,6 +685,11 @@ type BranchInfo struct {
 	//
 	// Required: true
 	IsDefault bool `json:"isDefault"`
+
+	// IsTag indicates if this is a tag rather than a branch.
+	//
+	// Required: true
+	IsTag bool `json:"isTag"`
 }
 
 // BranchesResponse represents the response for listing repository branches.
@@ -530,6 +807,16 @@ type SyncStatus struct {
 	//
 	// Required: false
 	LastSyncCommit *string `json:"lastSyncCommit,omitempty"`
+
+	// DriftCheckedAt is the date and time of the last drift check.
+	//
+	// Required: false
+	DriftCheckedAt *time.Time `json:"driftCheckedAt,omitempty"`
+
+	// DriftReasons explains why the project no longer matches the sync, if it drifted.
+	//
+	// Required: false
+	DriftReasons []string `json:"driftReasons,omitempty"`
 }
 
 // ImportGitOpsSyncRequest represents the request to import gitops syncs.
@@ -75,6 +75,31 @@ type UpdateInfo struct {
 	//
 	// Required: false
 	UsedCredential bool `json:"usedCredential,omitempty"`
+
+	// ReleaseSource is the source repository of the latest image, from its OCI annotations.
+	//
+	// Required: false
+	ReleaseSource string `json:"releaseSource,omitempty"`
+
+	// ReleaseVersion is the version of the latest image, from its OCI annotations.
+	//
+	// Required: false
+	ReleaseVersion string `json:"releaseVersion,omitempty"`
+
+	// ReleaseRevision is the source revision of the latest image, from its OCI annotations.
+	//
+	// Required: false
+	ReleaseRevision string `json:"releaseRevision,omitempty"`
+
+	// ReleaseNotesURL links to the release notes of the latest image, or to its source.
+	//
+	// Required: false
+	ReleaseNotesURL string `json:"releaseNotesUrl,omitempty"`
+
+	// ReleaseNotes is the body of the release notes, when the source repository has them.
+	//
+	// Required: false
+	ReleaseNotes string `json:"releaseNotes,omitempty"`
 }
 
 type Summary struct {
@@ -332,6 +357,31 @@ type PullOptions struct {
 	Credentials []containerregistry.Credential `json:"credentials,omitempty"`
 }
 
+// TagRequest is the request body for tagging an image.
+type TagRequest struct {
+	// Target is the new reference for the image. A reference without a tag is tagged latest.
+	//
+	// Required: true
+	Target string `json:"target" minLength:"1" doc:"New reference for the image (e.g., registry.example.com/team/app:1.0)"`
+}
+
+// TagResult is the result of tagging an image.
+type TagResult struct {
+	// Reference is the normalized reference the image was tagged with.
+	//
+	// Required: true
+	Reference string `json:"reference"`
+}
+
+// PushRequest is the request body for pushing an image.
+type PushRequest struct {
+	// Tag of the image to push. Required when the image has several tags and is not
+	// addressed by one of them.
+	//
+	// Required: false
+	Tag string `json:"tag,omitempty" doc:"Tag of the image to push (e.g., registry.example.com/team/app:1.0)"`
+}
+
 // GetFullImageName returns the image name with tag.
 func (p PullOptions) GetFullImageName() string {
 	if p.Tag != "" && p.Tag != "latest" {
@@ -0,0 +1,281 @@
+package imagebuild
+
+import "time"
+
+// Build sources.
+const (
+	SourceProject = "project"
+	SourceGit     = "git"
+)
+
+// Build triggers.
+const (
+	TriggerManual = "manual"
+	TriggerDeploy = "deploy"
+	TriggerGitOps = "gitops"
+)
+
+// Build statuses.
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCanceled  = "canceled"
+)
+
+// Progress event types.
+const (
+	EventStep   = "step"
+	EventStatus = "status"
+	EventLog    = "log"
+	EventResult = "result"
+)
+
+// BuildRequest is the request body for starting an image build.
+//
+// A build uses either a project or a git repository as its source. Without a ContextPath,
+// the compose services of the project (or of ComposePath in the repository) that have a
+// build section are built and tagged with their image name. With a ContextPath, that
+// directory is built and tagged with Tags.
+type BuildRequest struct {
+	// ProjectID of the project to build from.
+	//
+	// Required: false
+	ProjectID string `json:"projectId,omitempty"`
+
+	// Services limits a compose build to these services.
+	//
+	// Required: false
+	Services []string `json:"services,omitempty"`
+
+	// RepositoryID of the git repository to build from. Its stored credentials are used to clone it.
+	//
+	// Required: false
+	RepositoryID string `json:"repositoryId,omitempty"`
+
+	// Ref is the branch of the repository to build. Defaults to the default branch.
+	//
+	// Required: false
+	Ref string `json:"ref,omitempty"`
+
+	// ComposePath is the compose file in the repository whose services are built.
+	//
+	// Required: false
+	ComposePath string `json:"composePath,omitempty"`
+
+	// ContextPath is the build context directory, relative to the project or repository root.
+	//
+	// Required: false
+	ContextPath string `json:"contextPath,omitempty"`
+
+	// Dockerfile path relative to the build context. Defaults to Dockerfile.
+	//
+	// Required: false
+	Dockerfile string `json:"dockerfile,omitempty"`
+
+	// Tags of the built image. Required when building a context directory.
+	//
+	// Required: false
+	Tags []string `json:"tags,omitempty"`
+
+	// BuildArgs are passed to the build and override build args of compose services.
+	//
+	// Required: false
+	BuildArgs map[string]string `json:"buildArgs,omitempty"`
+
+	// Target is the build stage to build.
+	//
+	// Required: false
+	Target string `json:"target,omitempty"`
+
+	// CacheFrom are images used as cache sources.
+	//
+	// Required: false
+	CacheFrom []string `json:"cacheFrom,omitempty"`
+
+	// NoCache disables the build cache.
+	//
+	// Required: false
+	NoCache bool `json:"noCache,omitempty"`
+
+	// Pull always pulls newer versions of the base images.
+	//
+	// Required: false
+	Pull bool `json:"pull,omitempty"`
+
+	// Platform to build for, e.g. linux/arm64.
+	//
+	// Required: false
+	Platform string `json:"platform,omitempty"`
+}
+
+// Build is an image build and its outcome.
+type Build struct {
+	// ID of the build.
+	//
+	// Required: true
+	ID string `json:"id"`
+
+	// Source of the build context: project or git.
+	//
+	// Required: true
+	Source string `json:"source"`
+
+	// ProjectID of the project the build used.
+	//
+	// Required: false
+	ProjectID *string `json:"projectId,omitempty"`
+
+	// RepositoryID of the git repository the build used.
+	//
+	// Required: false
+	RepositoryID *string `json:"repositoryId,omitempty"`
+
+	// GitOpsSyncID of the sync that triggered the build.
+	//
+	// Required: false
+	GitOpsSyncID *string `json:"gitOpsSyncId,omitempty"`
+
+	// Ref is the branch that was built.
+	//
+	// Required: false
+	Ref string `json:"ref,omitempty"`
+
+	// Commit that was built.
+	//
+	// Required: false
+	Commit *string `json:"commit,omitempty"`
+
+	// ContextPath is the build context directory.
+	//
+	// Required: false
+	ContextPath string `json:"contextPath,omitempty"`
+
+	// Services are the compose services that were built.
+	//
+	// Required: false
+	Services []string `json:"services,omitempty"`
+
+	// Tags of the built images.
+	//
+	// Required: false
+	Tags []string `json:"tags,omitempty"`
+
+	// Target is the build stage that was built.
+	//
+	// Required: false
+	Target string `json:"target,omitempty"`
+
+	// Trigger that started the build: manual, deploy or gitops.
+	//
+	// Required: true
+	Trigger string `json:"trigger"`
+
+	// Status of the build: running, succeeded, failed or canceled.
+	//
+	// Required: true
+	Status string `json:"status"`
+
+	// Error of a failed build.
+	//
+	// Required: false
+	Error *string `json:"error,omitempty"`
+
+	// Log is the plain text build output, truncated to its last part for long builds.
+	//
+	// Required: false
+	Log string `json:"log,omitempty"`
+
+	// Username of the user who started the build.
+	//
+	// Required: false
+	Username *string `json:"username,omitempty"`
+
+	// StartedAt is when the build started.
+	//
+	// Required: true
+	StartedAt time.Time `json:"startedAt"`
+
+	// FinishedAt is when the build finished.
+	//
+	// Required: false
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// ProgressEvent is one message of the build progress stream.
+type ProgressEvent struct {
+	// BuildID of the build the event belongs to.
+	//
+	// Required: true
+	BuildID string `json:"buildId"`
+
+	// Type of the event: step, status, log or result.
+	//
+	// Required: true
+	Type string `json:"type"`
+
+	// Service is the compose service being built.
+	//
+	// Required: false
+	Service string `json:"service,omitempty"`
+
+	// Step identifies the build step (BuildKit vertex) the event belongs to.
+	//
+	// Required: false
+	Step string `json:"step,omitempty"`
+
+	// Name of the step or status, e.g. "[2/5] RUN npm ci".
+	//
+	// Required: false
+	Name string `json:"name,omitempty"`
+
+	// Cached indicates the step was served from the build cache.
+	//
+	// Required: false
+	Cached bool `json:"cached,omitempty"`
+
+	// Started is when the step started.
+	//
+	// Required: false
+	Started *time.Time `json:"started,omitempty"`
+
+	// Completed is when the step completed.
+	//
+	// Required: false
+	Completed *time.Time `json:"completed,omitempty"`
+
+	// Current progress of a status, e.g. bytes transferred.
+	//
+	// Required: false
+	Current int64 `json:"current,omitempty"`
+
+	// Total of a status, when known.
+	//
+	// Required: false
+	Total int64 `json:"total,omitempty"`
+
+	// Message is build output of a log event.
+	//
+	// Required: false
+	Message string `json:"message,omitempty"`
+
+	// Error of a failed step or build.
+	//
+	// Required: false
+	Error string `json:"error,omitempty"`
+
+	// Status of the build, set on result events.
+	//
+	// Required: false
+	Status string `json:"status,omitempty"`
+
+	// ImageID of the image built for Service, set on result events.
+	//
+	// Required: false
+	ImageID string `json:"imageId,omitempty"`
+
+	// Timestamp of the event.
+	//
+	// Required: true
+	Timestamp time.Time `json:"timestamp"`
+}
@@ -71,6 +71,31 @@ type Response struct {
 	//
 	// Required: false
 	UsedCredential bool `json:"usedCredential,omitempty"`
+
+	// ReleaseSource is the source repository of the latest image, from its OCI annotations.
+	//
+	// Required: false
+	ReleaseSource string `json:"releaseSource,omitempty"`
+
+	// ReleaseVersion is the version of the latest image, from its OCI annotations.
+	//
+	// Required: false
+	ReleaseVersion string `json:"releaseVersion,omitempty"`
+
+	// ReleaseRevision is the source revision of the latest image, from its OCI annotations.
+	//
+	// Required: false
+	ReleaseRevision string `json:"releaseRevision,omitempty"`
+
+	// ReleaseNotesURL links to the release notes of the latest image, or to its source.
+	//
+	// Required: false
+	ReleaseNotesURL string `json:"releaseNotesUrl,omitempty"`
+
+	// ReleaseNotes is the body of the release notes, when the source repository has them.
+	//
+	// Required: false
+	ReleaseNotes string `json:"releaseNotes,omitempty"`
 }
 
 type Summary struct {
@@ -89,6 +114,11 @@ type Summary struct {
 	// Required: true
 	DigestUpdates int `json:"digestUpdates"`
 
+	// TagUpdates is the number of images with a newer version tag.
+	//
+	// Required: true
+	TagUpdates int `json:"tagUpdates"`
+
 	// ErrorsCount is the number of errors encountered during the check.
 	//
 	// Required: true
@@ -14,6 +14,8 @@ type Config struct {
 	PollingInterval            string `json:"pollingInterval"`
 	ScheduledPruneInterval     string `json:"scheduledPruneInterval"`
 	GitopsSyncInterval         string `json:"gitopsSyncInterval"`
+	GitopsDriftInterval        string `json:"gitopsDriftInterval"`
+	UpdateRolloutInterval      string `json:"updateRolloutInterval"`
 	VulnerabilityScanInterval  string `json:"vulnerabilityScanInterval"`
 }
 
@@ -28,6 +30,8 @@ type Update struct {
 	PollingInterval            *string `json:"pollingInterval,omitempty"`
 	ScheduledPruneInterval     *string `json:"scheduledPruneInterval,omitempty"`
 	GitopsSyncInterval         *string `json:"gitopsSyncInterval,omitempty"`
+	GitopsDriftInterval        *string `json:"gitopsDriftInterval,omitempty"`
+	UpdateRolloutInterval      *string `json:"updateRolloutInterval,omitempty"`
 	VulnerabilityScanInterval  *string `json:"vulnerabilityScanInterval,omitempty"`
 }
 
@@ -142,6 +142,35 @@ var jobMetadataRegistry = map[string]JobMetadata{
 			},
 		},
 	},
+	"gitops-drift": {
+		ID:             "gitops-drift",
+		Name:           "GitOps Drift Detection",
+		Description:    "Checks GitOps managed projects for changes made outside of git",
+		Category:       "sync",
+		SettingsKey:    "gitopsDriftInterval",
+		EnabledKey:     "gitopsSyncEnabled",
+		ManagerOnly:    false,
+		IsContinuous:   false,
+		CanRunManually: true,
+		Prerequisites: []JobPrerequisiteMetadata{
+			{
+				SettingKey:  "gitopsSyncEnabled",
+				Label:       "GitOps sync enabled",
+				SettingsURL: "/settings/gitops",
+			},
+		},
+	},
+	"update-rollout": {
+		ID:             "update-rollout",
+		Name:           "Update Rollouts",
+		Description:    "Promotes staged image update rollouts to their next group of environments",
+		Category:       "updates",
+		SettingsKey:    "updateRolloutInterval",
+		ManagerOnly:    true,
+		IsContinuous:   false,
+		CanRunManually: true,
+		Prerequisites:  []JobPrerequisiteMetadata{},
+	},
 	"filesystem-watcher": {
 		ID:             "filesystem-watcher",
 		Name:           "Filesystem Watcher",
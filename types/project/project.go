@@ -319,3 +319,80 @@ type ImagePullRequest struct {
 	// Required: false
 	Credentials []containerregistry.Credential `json:"credentials,omitempty"`
 }
+
+// Deployment is a recorded snapshot of a single project deploy.
+type Deployment struct {
+	// ID is the unique identifier of the deployment.
+	//
+	// Required: true
+	ID string `json:"id"`
+
+	// ProjectID is the ID of the project that was deployed.
+	//
+	// Required: true
+	ProjectID string `json:"projectId"`
+
+	// Revision is the per-project, monotonically increasing deployment number.
+	//
+	// Required: true
+	Revision int `json:"revision"`
+
+	// Action is what triggered the deployment (deploy or rollback).
+	//
+	// Required: true
+	Action string `json:"action"`
+
+	// Status is the outcome of the deployment (succeeded or failed).
+	//
+	// Required: true
+	Status string `json:"status"`
+
+	// Error is the deployment error message, if the deployment failed.
+	//
+	// Required: false
+	Error *string `json:"error,omitempty"`
+
+	// ComposeContent is the Docker Compose file content that was deployed.
+	//
+	// Required: false
+	ComposeContent string `json:"composeContent,omitempty"`
+
+	// EnvContent is the environment file content that was deployed.
+	//
+	// Required: false
+	EnvContent *string `json:"envContent,omitempty"`
+
+	// IncludeFiles maps include file paths (relative to the project) to their content.
+	//
+	// Required: false
+	IncludeFiles map[string]string `json:"includeFiles,omitempty"`
+
+	// ImageDigests maps the image references in the compose file to the digests they resolved to.
+	//
+	// Required: false
+	ImageDigests map[string]string `json:"imageDigests,omitempty"`
+
+	// SourceDeploymentID is the deployment that was restored, for rollbacks.
+	//
+	// Required: false
+	SourceDeploymentID *string `json:"sourceDeploymentId,omitempty"`
+
+	// Username is the user who triggered the deployment.
+	//
+	// Required: false
+	Username *string `json:"username,omitempty"`
+
+	// CreatedAt is the date and time when the deployment was recorded.
+	//
+	// Required: true
+	CreatedAt string `json:"createdAt"`
+}
+
+// Rollback is used to roll a project back to a previous deployment.
+type Rollback struct {
+	// DeploymentID is the deployment to restore. When empty, the last successful
+	// deployment before the current one is used.
+	//
+	// Required: false
+	DeploymentID string `json:"deploymentId,omitempty"`
+}
@@ -0,0 +1,142 @@
+package registrymirror
+
+import "time"
+
+// RegistryMirror is a mirror or pull-through cache used for pulls from an upstream registry.
+type RegistryMirror struct {
+	// ID of the mirror.
+	//
+	// Required: true
+	ID string `json:"id"`
+
+	// Registry is the upstream registry host the mirror serves, e.g. docker.io or ghcr.io.
+	//
+	// Required: true
+	Registry string `json:"registry"`
+
+	// MirrorURL is the mirror host with an optional path prefix, e.g. harbor.local/dockerhub-proxy.
+	//
+	// Required: true
+	MirrorURL string `json:"mirrorUrl"`
+
+	// RepositoryPattern limits the mirror to upstream repositories matching this glob, e.g. library/*.
+	//
+	// Required: false
+	RepositoryPattern string `json:"repositoryPattern,omitempty"`
+
+	// Description of the mirror.
+	//
+	// Required: false
+	Description *string `json:"description,omitempty"`
+
+	// Priority orders mirrors of the same registry, lower values are tried first.
+	//
+	// Required: true
+	Priority int `json:"priority"`
+
+	// Enabled indicates if pulls go through the mirror.
+	//
+	// Required: true
+	Enabled bool `json:"enabled"`
+
+	// FallbackToUpstream indicates if a failed mirror pull is retried against the upstream registry.
+	//
+	// Required: true
+	FallbackToUpstream bool `json:"fallbackToUpstream"`
+
+	// LastUsedAt is when the mirror last served a pull.
+	//
+	// Required: false
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+
+	// LastError is the error of the last failed pull through the mirror.
+	//
+	// Required: false
+	LastError *string `json:"lastError,omitempty"`
+
+	// CreatedAt is the date and time at which the mirror was created.
+	//
+	// Required: true
+	CreatedAt time.Time `json:"createdAt"`
+
+	// UpdatedAt is the date and time at which the mirror was last updated.
+	//
+	// Required: false
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// CreateRequest is the request body for creating a registry mirror.
+type CreateRequest struct {
+	// Registry is the upstream registry host, e.g. docker.io.
+	//
+	// Required: true
+	Registry string `json:"registry" minLength:"1"`
+
+	// MirrorURL is the mirror host with an optional path prefix.
+	//
+	// Required: true
+	MirrorURL string `json:"mirrorUrl" minLength:"1"`
+
+	// RepositoryPattern limits the mirror to matching upstream repositories.
+	//
+	// Required: false
+	RepositoryPattern string `json:"repositoryPattern,omitempty"`
+
+	// Description of the mirror.
+	//
+	// Required: false
+	Description *string `json:"description,omitempty"`
+
+	// Priority orders mirrors of the same registry, lower values are tried first.
+	//
+	// Required: false
+	Priority int `json:"priority,omitempty"`
+
+	// Enabled indicates if pulls go through the mirror. Defaults to true.
+	//
+	// Required: false
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// FallbackToUpstream indicates if a failed mirror pull is retried upstream. Defaults to true.
+	//
+	// Required: false
+	FallbackToUpstream *bool `json:"fallbackToUpstream,omitempty"`
+}
+
+// UpdateRequest is the request body for updating a registry mirror.
+type UpdateRequest struct {
+	// Registry is the upstream registry host.
+	//
+	// Required: false
+	Registry *string `json:"registry,omitempty"`
+
+	// MirrorURL is the mirror host with an optional path prefix.
+	//
+	// Required: false
+	MirrorURL *string `json:"mirrorUrl,omitempty"`
+
+	// RepositoryPattern limits the mirror to matching upstream repositories.
+	//
+	// Required: false
+	RepositoryPattern *string `json:"repositoryPattern,omitempty"`
+
+	// Description of the mirror.
+	//
+	// Required: false
+	Description *string `json:"description,omitempty"`
+
+	// Priority orders mirrors of the same registry.
+	//
+	// Required: false
+	Priority *int `json:"priority,omitempty"`
+
+	// Enabled indicates if pulls go through the mirror.
+	//
+	// Required: false
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// FallbackToUpstream indicates if a failed mirror pull is retried upstream.
+	//
+	// Required: false
+	FallbackToUpstream *bool `json:"fallbackToUpstream,omitempty"`
+}
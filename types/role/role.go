@@ -0,0 +1,141 @@
+package role
+
+import "time"
+
+// Role is a named set of permissions. Permissions are written "resource:action", e.g.
+// "containers:restart", and either part may be the wildcard "*".
+type Role struct {
+	// ID of the role. Built-in roles use their name as ID.
+	//
+	// Required: true
+	ID string `json:"id"`
+
+	// Name of the role.
+	//
+	// Required: true
+	Name string `json:"name"`
+
+	// Description of the role.
+	//
+	// Required: false
+	Description *string `json:"description,omitempty"`
+
+	// Permissions granted by the role.
+	//
+	// Required: true
+	Permissions []string `json:"permissions"`
+
+	// BuiltIn indicates the role is one of viewer, operator, deployer or admin and can't be changed.
+	//
+	// Required: true
+	BuiltIn bool `json:"builtIn"`
+
+	// CreatedAt is the date and time at which the role was created.
+	//
+	// Required: false
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+
+	// UpdatedAt is the date and time at which the role was last updated.
+	//
+	// Required: false
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// CreateRequest is the request body for creating a custom role.
+type CreateRequest struct {
+	// Name of the role. It can't be changed later.
+	//
+	// Required: true
+	Name string `json:"name" minLength:"1" maxLength:"64" pattern:"^[a-z0-9][a-z0-9_-]*$" doc:"Name of the role" example:"contractor"`
+
+	// Description of the role.
+	//
+	// Required: false
+	Description *string `json:"description,omitempty" doc:"Description of the role"`
+
+	// Permissions granted by the role.
+	//
+	// Required: true
+	Permissions []string `json:"permissions" minItems:"1" doc:"Permissions granted by the role" example:"[\"containers:read\", \"containers:restart\"]"`
+}
+
+// UpdateRequest is the request body for updating a custom role.
+type UpdateRequest struct {
+	// Description of the role.
+	//
+	// Required: false
+	Description *string `json:"description,omitempty" doc:"Description of the role"`
+
+	// Permissions replace the permissions of the role.
+	//
+	// Required: false
+	Permissions []string `json:"permissions,omitempty" doc:"Permissions granted by the role"`
+}
+
+// ResourcePermissions lists the actions that can be granted on a resource.
+type ResourcePermissions struct {
+	// Resource is the resource type, e.g. containers.
+	//
+	// Required: true
+	Resource string `json:"resource"`
+
+	// Actions that can be granted on the resource.
+	//
+	// Required: true
+	Actions []string `json:"actions"`
+}
+
+// Assignment grants a role to a user in one environment, or in all environments when
+// EnvironmentID is empty. With a ProjectID it only applies to that project.
+type Assignment struct {
+	// ID of the assignment.
+	//
+	// Required: false
+	ID string `json:"id,omitempty"`
+
+	// Role is the name of the assigned role.
+	//
+	// Required: true
+	Role string `json:"role" minLength:"1" doc:"Name of the assigned role"`
+
+	// EnvironmentID limits the assignment to an environment.
+	//
+	// Required: false
+	EnvironmentID *string `json:"environmentId,omitempty" doc:"Environment the role applies to; all environments when empty"`
+
+	// ProjectID limits the assignment to a project of the environment.
+	//
+	// Required: false
+	ProjectID *string `json:"projectId,omitempty" doc:"Project the role applies to; requires an environment"`
+}
+
+// SetAssignmentsRequest replaces the role assignments of a user.
+type SetAssignmentsRequest struct {
+	// Assignments of the user.
+	//
+	// Required: true
+	Assignments []Assignment `json:"assignments" doc:"Role assignments of the user"`
+}
+
+// EffectivePermissions are the permissions the current user has in an environment.
+type EffectivePermissions struct {
+	// EnvironmentID the permissions apply to.
+	//
+	// Required: true
+	EnvironmentID string `json:"environmentId"`
+
+	// ProjectID the permissions apply to, when requested for a project.
+	//
+	// Required: false
+	ProjectID string `json:"projectId,omitempty"`
+
+	// Admin indicates the user has every permission.
+	//
+	// Required: true
+	Admin bool `json:"admin"`
+
+	// Permissions granted to the user.
+	//
+	// Required: true
+	Permissions []string `json:"permissions"`
+}
@@ -0,0 +1,159 @@
+package rollout
+
+import "time"
+
+// Stage is one step of a rollout. All environments of a stage are updated together.
+type Stage struct {
+	// Name of the stage, e.g. "staging".
+	//
+	// Required: false
+	Name string `json:"name,omitempty"`
+
+	// EnvironmentIDs are the environments updated in this stage.
+	//
+	// Required: true
+	EnvironmentIDs []string `json:"environmentIds" minItems:"1"`
+
+	// SoakMinutes is how long the stage must stay healthy before the next stage starts.
+	//
+	// Required: false
+	SoakMinutes int `json:"soakMinutes" minimum:"0"`
+}
+
+// StageResult is the outcome of running the updater on one environment of a stage.
+type StageResult struct {
+	// Stage is the index of the stage.
+	//
+	// Required: true
+	Stage int `json:"stage"`
+
+	// EnvironmentID of the environment that was updated.
+	//
+	// Required: true
+	EnvironmentID string `json:"environmentId"`
+
+	// Updated is the number of containers that were updated.
+	//
+	// Required: true
+	Updated int `json:"updated"`
+
+	// Failed is the number of containers that failed to update.
+	//
+	// Required: true
+	Failed int `json:"failed"`
+
+	// RolledBack is the number of containers that were rolled back after a failed health check.
+	//
+	// Required: true
+	RolledBack int `json:"rolledBack"`
+
+	// Error is set when the updater could not be run on the environment.
+	//
+	// Required: false
+	Error string `json:"error,omitempty"`
+
+	// FinishedAt is when the updater run on the environment finished.
+	//
+	// Required: true
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// Rollout is a staged rollout of image updates across environments.
+type Rollout struct {
+	// ID of the rollout.
+	//
+	// Required: true
+	ID string `json:"id"`
+
+	// Name of the rollout.
+	//
+	// Required: true
+	Name string `json:"name"`
+
+	// Stages are run in order, each one after the previous stage stayed healthy for its soak time.
+	//
+	// Required: true
+	Stages []Stage `json:"stages"`
+
+	// Status of the rollout (idle, running, soaking, completed, failed, cancelled).
+	//
+	// Required: true
+	Status string `json:"status"`
+
+	// CurrentStage is the index of the stage being run or soaked.
+	//
+	// Required: true
+	CurrentStage int `json:"currentStage"`
+
+	// NextStageAt is when the current stage is checked and the next one promoted.
+	//
+	// Required: false
+	NextStageAt *time.Time `json:"nextStageAt,omitempty"`
+
+	// StageStartedAt is when the current stage started.
+	//
+	// Required: false
+	StageStartedAt *time.Time `json:"stageStartedAt,omitempty"`
+
+	// StartedAt is when the rollout was last started.
+	//
+	// Required: false
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+
+	// FinishedAt is when the rollout last completed, failed or was cancelled.
+	//
+	// Required: false
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+
+	// LastError explains why the rollout failed.
+	//
+	// Required: false
+	LastError *string `json:"lastError,omitempty"`
+
+	// Results of the updater runs of the current rollout.
+	//
+	// Required: false
+	Results []StageResult `json:"results,omitempty"`
+
+	// Images are the image updates the first stage applied, as references pinned to their
+	// digest. Later stages apply only these.
+	//
+	// Required: false
+	Images []string `json:"images,omitempty"`
+
+	// CreatedAt is the date and time at which the rollout was created.
+	//
+	// Required: true
+	CreatedAt time.Time `json:"createdAt"`
+
+	// UpdatedAt is the date and time at which the rollout was last updated.
+	//
+	// Required: false
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// CreateRequest is the request body for creating a rollout.
+type CreateRequest struct {
+	// Name of the rollout.
+	//
+	// Required: true
+	Name string `json:"name" minLength:"1"`
+
+	// Stages of the rollout.
+	//
+	// Required: true
+	Stages []Stage `json:"stages" minItems:"1"`
+}
+
+// UpdateRequest is the request body for updating a rollout.
+type UpdateRequest struct {
+	// Name of the rollout.
+	//
+	// Required: false
+	Name *string `json:"name,omitempty"`
+
+	// Stages of the rollout.
+	//
+	// Required: false
+	Stages []Stage `json:"stages,omitempty"`
+}
@@ -52,6 +52,31 @@ type Update struct {
 	// Required: false
 	AutoUpdateInterval *string `json:"autoUpdateInterval,omitempty"`
 
+	// AutoUpdateHealthTimeout is how long, in seconds, to wait for an updated container to become healthy before rolling it back.
+	//
+	// Required: false
+	AutoUpdateHealthTimeout *string `json:"autoUpdateHealthTimeout,omitempty"`
+
+	// AutoUpdateMinUptime is how long, in seconds, an updated container without a healthcheck must keep running.
+	//
+	// Required: false
+	AutoUpdateMinUptime *string `json:"autoUpdateMinUptime,omitempty"`
+
+	// AutoUpdateBumpComposeTags indicates if version tags applied by auto-update are written back into project compose files.
+	//
+	// Required: false
+	AutoUpdateBumpComposeTags *string `json:"autoUpdateBumpComposeTags,omitempty"`
+
+	// ReleaseNotesGitLabHosts is a comma-separated list of self-hosted GitLab hosts release notes are looked up on.
+	//
+	// Required: false
+	ReleaseNotesGitLabHosts *string `json:"releaseNotesGitLabHosts,omitempty"`
+
+	// ImageTagUpdatePolicy limits which newer version tags count as updates ("none" | "patch" | "minor" | "major").
+	//
+	// Required: false
+	ImageTagUpdatePolicy *string `json:"imageTagUpdatePolicy,omitempty"`
+
 	// PollingEnabled indicates if polling is enabled.
 	//
 	// Required: false
@@ -147,6 +172,11 @@ type Update struct {
 	// Required: false
 	DockerHost *string `json:"dockerHost,omitempty"`
 
+	// DockerConfigPath is the path to a docker config.json used as an additional source of registry credentials.
+	//
+	// Required: false
+	DockerConfigPath *string `json:"dockerConfigPath,omitempty"`
+
 	// AccentColor is the UI accent color.
 	//
 	// Required: false
@@ -177,6 +207,11 @@ type Update struct {
 	// Required: false
 	AuthPasswordPolicy *string `json:"authPasswordPolicy,omitempty"`
 
+	// AuthRequireTwoFactor requires every local account to sign in with a TOTP code.
+	//
+	// Required: false
+	AuthRequireTwoFactor *string `json:"authRequireTwoFactor,omitempty"`
+
 	// TrivyImage overrides the container image used for vulnerability scans.
 	//
 	// Required: false
@@ -237,6 +272,76 @@ type Update struct {
 	// Required: false
 	OidcProviderLogoUrl *string `json:"oidcProviderLogoUrl,omitempty"`
 
+	// LdapEnabled indicates if LDAP authentication is enabled.
+	//
+	// Required: false
+	LdapEnabled *string `json:"ldapEnabled,omitempty"`
+
+	// LdapUrl is the ldap:// or ldaps:// URL of the directory server.
+	//
+	// Required: false
+	LdapUrl *string `json:"ldapUrl,omitempty"`
+
+	// LdapStartTls indicates if ldap:// connections should be upgraded with StartTLS.
+	//
+	// Required: false
+	LdapStartTls *string `json:"ldapStartTls,omitempty"`
+
+	// LdapSkipTlsVerify indicates if TLS verification should be skipped for LDAP.
+	//
+	// Required: false
+	LdapSkipTlsVerify *string `json:"ldapSkipTlsVerify,omitempty"`
+
+	// LdapBindDn is the DN of the service account used to search for users.
+	//
+	// Required: false
+	LdapBindDn *string `json:"ldapBindDn,omitempty"`
+
+	// LdapBindPassword is the password of the LDAP service account.
+	//
+	// Required: false
+	LdapBindPassword *string `json:"ldapBindPassword,omitempty"`
+
+	// LdapBaseDn is the base DN for user and group searches.
+	//
+	// Required: false
+	LdapBaseDn *string `json:"ldapBaseDn,omitempty"`
+
+	// LdapUserFilter is the filter used to find a user, with {username} as placeholder.
+	//
+	// Required: false
+	LdapUserFilter *string `json:"ldapUserFilter,omitempty"`
+
+	// LdapUsernameAttribute is the LDAP attribute holding the username.
+	//
+	// Required: false
+	LdapUsernameAttribute *string `json:"ldapUsernameAttribute,omitempty"`
+
+	// LdapEmailAttribute is the LDAP attribute holding the email address.
+	//
+	// Required: false
+	LdapEmailAttribute *string `json:"ldapEmailAttribute,omitempty"`
+
+	// LdapDisplayNameAttribute is the LDAP attribute holding the display name.
+	//
+	// Required: false
+	LdapDisplayNameAttribute *string `json:"ldapDisplayNameAttribute,omitempty"`
+
+	// LdapGroupAttribute is the user attribute listing group DNs, e.g. memberOf.
+	//
+	// Required: false
+	LdapGroupAttribute *string `json:"ldapGroupAttribute,omitempty"`
+
+	// LdapGroupFilter is an optional filter used to find the groups of a user, with {dn} and {username} as placeholders.
+	//
+	// Required: false
+	LdapGroupFilter *string `json:"ldapGroupFilter,omitempty"`
+
+	// LdapGroupRoleMapping is a JSON object mapping LDAP groups to Arcane roles.
+	//
+	// Required: false
+	LdapGroupRoleMapping *string `json:"ldapGroupRoleMapping,omitempty"`
+
 	// MobileNavigationMode is the navigation mode for mobile devices.
 	//
 	// Required: false
@@ -9,6 +9,7 @@ const (
 	WSKindContainerStats = "container_stats"
 	WSKindContainerExec  = "container_exec"
 	WSKindSystemStats    = "system_stats"
+	WSKindImageBuild     = "image_build"
 )
 
 // WebSocketConnectionInfo describes a single active WebSocket connection.
@@ -50,4 +51,6 @@ type WebSocketMetricsSnapshot struct {
 	ContainerExec int64 `json:"containerExec"`
 	// SystemStats is the number of active system-stats streams.
 	SystemStats int64 `json:"systemStats"`
+	// ImageBuilds is the number of active image build progress streams.
+	ImageBuilds int64 `json:"imageBuilds"`
 }
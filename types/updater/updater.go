@@ -12,6 +12,15 @@ type Options struct {
 
 	// DryRun performs a dry run without applying updates
 	DryRun bool `json:"dryRun,omitempty"`
+
+	// Background starts the run in the background and returns its RunID right away. The
+	// updater status reports the result once the run finishes.
+	Background bool `json:"background,omitempty"`
+
+	// Images limits the run to updates to these images, given as new image references pinned
+	// to a digest ("nginx:1.27@sha256:..."). Pinned images are pulled by digest, so every
+	// environment of a rollout gets the image its first stage was tested with.
+	Images []string `json:"images,omitempty"`
 }
 
 // ResourceResult represents the result of an update operation on a single resource.
@@ -31,7 +40,7 @@ type ResourceResult struct {
 	// Required: true
 	ResourceType string `json:"resourceType"`
 
-	// Status is the current status ("checked" | "updated" | "skipped" | "failed" | "up_to_date" | "update_available").
+	// Status is the current status ("checked" | "updated" | "skipped" | "failed" | "rolled_back" | "up_to_date" | "update_available").
 	//
 	// Required: true
 	Status string `json:"status"`
@@ -56,6 +65,11 @@ type ResourceResult struct {
 	// Required: false
 	NewImages map[string]string `json:"newImages,omitempty"`
 
+	// Digest is the repository digest of the image pulled for an image update.
+	//
+	// Required: false
+	Digest string `json:"digest,omitempty"`
+
 	// Error contains any error message encountered during the update.
 	//
 	// Required: false
@@ -94,6 +108,11 @@ type Result struct {
 	// Required: true
 	Failed int `json:"failed"`
 
+	// RolledBack is the number of containers that were rolled back after failing their health check.
+	//
+	// Required: true
+	RolledBack int `json:"rolledBack"`
+
 	// StartTime is the time when the update operation started.
 	//
 	// Required: false
@@ -113,6 +132,11 @@ type Result struct {
 	//
 	// Required: true
 	Items []ResourceResult `json:"items"`
+
+	// RunID identifies a run started in the background.
+	//
+	// Required: false
+	RunID string `json:"runId,omitempty"`
 }
 
 // Status represents the current status of the updater.
@@ -136,4 +160,24 @@ type Status struct {
 	//
 	// Required: true
 	ProjectIds []string `json:"projectIds"`
+
+	// Running indicates if a run started in the background is still in progress.
+	//
+	// Required: true
+	Running bool `json:"running"`
+
+	// RunID identifies the last run started in the background.
+	//
+	// Required: false
+	RunID string `json:"runId,omitempty"`
+
+	// LastRun is the result of the last run started in the background, once it finished.
+	//
+	// Required: false
+	LastRun *Result `json:"lastRun,omitempty"`
+
+	// LastRunError is the error the last run started in the background failed with.
+	//
+	// Required: false
+	LastRunError string `json:"lastRunError,omitempty"`
 }
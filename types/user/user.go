@@ -27,8 +27,10 @@ type User struct {
 	Email                  *string  `json:"email,omitempty" doc:"Email address of the user" example:"john@example.com"`
 	Roles                  []string `json:"roles" doc:"Roles assigned to the user" example:"[\"user\", \"admin\"]"`
 	OidcSubjectId          *string  `json:"oidcSubjectId,omitempty" doc:"OIDC subject identifier for SSO users"`
+	LdapDn                 *string  `json:"ldapDn,omitempty" doc:"Distinguished name for LDAP directory users"`
 	Locale                 *string  `json:"locale,omitempty" doc:"Locale preference of the user" example:"en-US"`
 	CreatedAt              string   `json:"createdAt,omitempty" doc:"Date and time when the user was created"`
 	UpdatedAt              string   `json:"updatedAt,omitempty" doc:"Date and time when the user was last updated"`
 	RequiresPasswordChange bool     `json:"requiresPasswordChange" doc:"Whether the user must change their password"`
+	TotpEnabled            bool     `json:"totpEnabled" doc:"Whether the user signs in with an authenticator app code"`
 }